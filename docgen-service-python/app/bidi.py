@@ -0,0 +1,73 @@
+"""
+Right-to-left support for Arabic documents.
+
+Word lays out a paragraph by its direction (w:bidi) and shapes each run by whether it is
+marked right-to-left (w:rtl). An Arabic paragraph with an English citation inside it needs
+both: an RTL paragraph whose Arabic runs are RTL and whose Latin runs are not, so the
+citation keeps its own reading order. Paragraphs with no Arabic at all, like English
+reference entries, stay left-to-right even in an Arabic document.
+"""
+import re
+
+from docx.oxml import OxmlElement
+from docx.oxml.ns import qn
+
+# Arabic, Arabic Supplement, Arabic Extended-A and the presentation forms
+ARABIC = "\u0600-\u06ff\u0750-\u077f\u08a0-\u08ff\ufb50-\ufdff\ufe70-\ufeff"
+# An Arabic span, taking in the spaces and punctuation between Arabic words
+ARABIC_SPAN = re.compile(rf"[{ARABIC}](?:[{ARABIC}\s،؛؟.,:()\-]*[{ARABIC}])?")
+
+
+def has_arabic(text: str) -> bool:
+    return re.search(f"[{ARABIC}]", text) is not None
+
+
+def _set_on(parent, tag: str, val: str = None):
+    element = parent.find(qn(tag))
+    if element is None:
+        element = OxmlElement(tag)
+        parent.append(element)
+    if val is not None:
+        element.set(qn("w:val"), val)
+
+
+def set_style_fonts(style, complex_font: str, size_pt: float = None):
+    """Sets the font a style uses for complex scripts such as Arabic, and its size."""
+    rpr = style.element.get_or_add_rPr()
+    rpr.get_or_add_rFonts().set(qn("w:cs"), complex_font)
+    if size_pt:
+        _set_on(rpr, "w:szCs", str(int(size_pt * 2))) # Half-points
+
+
+def set_paragraph_direction(paragraph, rtl: bool):
+    """
+    Sets a paragraph's base direction. Paragraphs without an explicit alignment start on
+    the side their direction reads from, so RTL text lines up on the right.
+    """
+    _set_on(paragraph._p.get_or_add_pPr(), "w:bidi", "1" if rtl else "0")
+
+
+def add_runs(paragraph, text: str):
+    """Adds text as runs, marking the Arabic spans right-to-left."""
+    pos = 0
+    for match in ARABIC_SPAN.finditer(text):
+        if match.start() > pos:
+            paragraph.add_run(text[pos:match.start()])
+        run = paragraph.add_run(match.group())
+        _set_on(run._r.get_or_add_rPr(), "w:rtl")
+        pos = match.end()
+    if pos < len(text):
+        paragraph.add_run(text[pos:])
+
+
+def write(paragraph, text: str, rtl_document: bool):
+    """
+    Writes text into an empty paragraph. In an RTL document the paragraph takes the
+    direction of its content: RTL when it has any Arabic, LTR otherwise.
+    """
+    if not rtl_document:
+        paragraph.add_run(text)
+        return paragraph
+    add_runs(paragraph, text)
+    set_paragraph_direction(paragraph, has_arabic(text))
+    return paragraph
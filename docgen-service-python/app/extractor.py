@@ -0,0 +1,103 @@
+from io import BytesIO
+import logging
+import re
+
+from docx import Document
+from docx.table import Table
+from pypdf import PdfReader
+from pypdf.errors import PdfReadError
+
+logger = logging.getLogger(__name__)
+
+
+class UnreadablePDFError(Exception):
+    """The upload is not a PDF pypdf can read, or it is encrypted."""
+
+
+class UnreadableDOCXError(Exception):
+    """The upload is not a DOCX python-docx can open."""
+
+
+def extract_pdf_text(data: bytes) -> tuple[str, int]:
+    """
+    Extracts the text layer of a PDF, page by page.
+    Returns the text, with pages separated by blank lines, and the page count.
+    Scanned PDFs without a text layer yield empty text rather than an error.
+    """
+    try:
+        reader = PdfReader(BytesIO(data))
+        if reader.is_encrypted:
+            raise UnreadablePDFError("PDF is encrypted")
+        pages = []
+        for number, page in enumerate(reader.pages, start=1):
+            try:
+                pages.append((page.extract_text() or "").strip())
+            except Exception as e:  # One bad page shouldn't lose the rest of the paper
+                logger.warning(f"Could not extract text from page {number}: {e}")
+                pages.append("")
+    except PdfReadError as e:
+        raise UnreadablePDFError(str(e)) from e
+    return "\n\n".join(p for p in pages if p), len(pages)
+
+
+def extract_docx_text(data: bytes) -> str:
+    """
+    Extracts the text of a DOCX: its paragraphs, then the text of its tables row by row.
+    Guidelines often put margin and font rules in tables, so those are kept.
+    """
+    try:
+        document = Document(BytesIO(data))
+    except Exception as e:  # python-docx raises a range of errors for non-DOCX archives
+        raise UnreadableDOCXError(str(e)) from e
+    parts = [p.text.strip() for p in document.paragraphs if p.text.strip()]
+    for table in document.tables:
+        for row in table.rows:
+            cells = [cell.text.strip() for cell in row.cells if cell.text.strip()]
+            if cells:
+                parts.append(" | ".join(cells))
+    return "\n\n".join(parts)
+
+
+HEADING_STYLE = re.compile(r"^Heading (\d)$")
+
+
+def _heading_level(paragraph) -> int:
+    """
+    The heading level of a paragraph, 0 for body text. Custom styles based on a built-in
+    heading, as thesis templates often define, count as that heading.
+    """
+    style = paragraph.style
+    while style is not None:
+        match = HEADING_STYLE.match(style.name or "")
+        if match:
+            return int(match.group(1))
+        style = style.base_style
+    return 0
+
+
+def extract_docx_outline(data: bytes) -> tuple[str, list[dict]]:
+    """
+    Reads a DOCX as an outline for importing a thesis: its Title-style paragraph, and its
+    paragraphs in document order with their heading level (0 for body text). Table rows
+    become body paragraphs with their cells joined by " | ".
+    """
+    try:
+        document = Document(BytesIO(data))
+    except Exception as e:  # python-docx raises a range of errors for non-DOCX archives
+        raise UnreadableDOCXError(str(e)) from e
+    title, blocks = "", []
+    for item in document.iter_inner_content():
+        if isinstance(item, Table):
+            for row in item.rows:
+                cells = [cell.text.strip() for cell in row.cells if cell.text.strip()]
+                if cells:
+                    blocks.append({"text": " | ".join(cells), "level": 0})
+            continue
+        text = item.text.strip()
+        if not text:
+            continue
+        if item.style is not None and item.style.name == "Title" and not title:
+            title = text
+            continue
+        blocks.append({"text": text, "level": _heading_level(item)})
+    return title, blocks
@@ -0,0 +1,128 @@
+"""
+Front matter of a thesis document: the pages before the first chapter.
+
+Which pages appear, and in what order, comes from the formatting options ("front_matter"),
+so a university template can ask for a declaration or a table of contents. Their text
+comes from the project: the student's name from their profile, and the degree, supervisor,
+dedication and so on from the project's front matter.
+"""
+import datetime
+
+from docx.enum.text import WD_ALIGN_PARAGRAPH
+from docx.oxml import OxmlElement
+from docx.oxml.ns import qn
+
+from .bidi import write
+from .models import DocumentGenerationRequest, FrontMatterData
+
+# Pages when the formatting options name none
+DEFAULT_PAGES = ["title_page", "table_of_contents", "list_of_figures", "list_of_tables"]
+
+# Word field instructions of the listing pages: the table of contents collects headings,
+# the lists collect the paragraphs in their caption style
+LISTING_FIELDS = {
+    "table_of_contents": ('contents', 'TOC \\o "1-3" \\h \\z \\u'),
+    "list_of_figures": ('list_of_figures', 'TOC \\h \\z \\t "Figure Caption,1"'),
+    "list_of_tables": ('list_of_tables', 'TOC \\h \\z \\t "Table Caption,1"'),
+}
+
+
+def add_front_matter(doc, data: DocumentGenerationRequest, labels: dict, entries: dict, rtl: bool = False):
+    """
+    Adds the front-matter pages in order, each ending with a page break. A dedication or
+    acknowledgments page the student has not written is skipped, as is a list of figures
+    or tables in a document that has none. entries holds the headings and captions each
+    listing page shows until Word updates it.
+    """
+    pages = data.formatting_options.get("front_matter") or DEFAULT_PAGES
+    front = data.front_matter or FrontMatterData()
+    for page in pages:
+        if page == "title_page":
+            add_title_page(doc, data, front, labels, rtl)
+        elif page == "declaration":
+            write(doc.add_heading(level=1), labels['declaration'], rtl)
+            text = front.declaration or labels['declaration_text']
+            text = text.replace("{student}", data.student_name or "").replace("{title}", data.research_title)
+            text = text.replace("{institution}", data.university_name or "")
+            add_paragraphs(doc, text, rtl)
+        elif page == "dedication" and front.dedication:
+            for _ in range(6): # Dedications sit a third of the way down the page
+                doc.add_paragraph()
+            for line in front.dedication.split('\n'):
+                if line.strip():
+                    paragraph = write(doc.add_paragraph(), line.strip(), rtl)
+                    paragraph.alignment = WD_ALIGN_PARAGRAPH.CENTER
+                    for run in paragraph.runs:
+                        run.italic = True
+        elif page == "acknowledgments" and front.acknowledgments:
+            write(doc.add_heading(level=1), labels['acknowledgments'], rtl)
+            add_paragraphs(doc, front.acknowledgments, rtl)
+        elif page in LISTING_FIELDS and (page == "table_of_contents" or entries.get(page)):
+            label, instruction = LISTING_FIELDS[page]
+            write(doc.add_heading(level=1), labels[label], rtl)
+            add_field(doc, instruction, entries.get(page) or [labels['update_field']], rtl)
+        else:
+            continue
+        doc.add_page_break()
+
+
+def add_title_page(doc, data: DocumentGenerationRequest, front: FrontMatterData, labels: dict, rtl: bool = False):
+    """Adds the title, the degree statement, the student, supervisor and institution, and the year."""
+    def centered(text: str):
+        write(doc.add_paragraph(), text, rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+
+    write(doc.add_heading(level=0), data.research_title, rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+    doc.add_paragraph() # Spacer
+    if front.degree:
+        centered(f"{labels['submitted_for']} {front.degree}")
+        doc.add_paragraph()
+    if data.student_name:
+        centered(f"{labels['by']}: {data.student_name}")
+    if data.specialization:
+        centered(f"{labels['specialization']}: {data.specialization}")
+    if front.supervisor:
+        centered(f"{labels['supervisor']}: {front.supervisor}")
+    if data.university_name:
+        centered(f"{labels['institution']}: {data.university_name}")
+    doc.add_paragraph()
+    centered(str(datetime.date.today().year))
+
+
+def add_paragraphs(doc, text: str, rtl: bool = False):
+    for line in text.split('\n'):
+        if line.strip():
+            write(doc.add_paragraph(), line.strip(), rtl)
+
+
+def add_field(doc, instruction: str, entries: list, rtl: bool = False):
+    """
+    Adds a Word field, such as a table of contents, whose result shows the entries one per
+    paragraph. python-docx cannot lay out pages, so the field is marked for Word to update,
+    adding page numbers and links, when the document is opened.
+    """
+    paragraphs = [doc.add_paragraph() for _ in entries]
+    add_field_char(paragraphs[0], 'begin')
+    run = paragraphs[0].add_run()
+    instr = OxmlElement('w:instrText')
+    instr.set(qn('xml:space'), 'preserve')
+    instr.text = instruction
+    run._r.append(instr)
+    add_field_char(paragraphs[0], 'separate')
+    for paragraph, entry in zip(paragraphs, entries):
+        write(paragraph, entry, rtl)
+    add_field_char(paragraphs[-1], 'end')
+
+    settings = doc.settings.element
+    if settings.find(qn('w:updateFields')) is None: # Word asks to update the fields on opening
+        update = OxmlElement('w:updateFields')
+        update.set(qn('w:val'), 'true')
+        settings.append(update)
+
+
+def add_field_char(paragraph, kind: str):
+    run = paragraph.add_run()
+    char = OxmlElement('w:fldChar')
+    char.set(qn('w:fldCharType'), kind)
+    if kind == 'begin':
+        char.set(qn('w:dirty'), 'true')
+    run._r.append(char)
@@ -1,12 +1,249 @@
+import base64
+from io import BytesIO
+import re
+
 from docx import Document
-from docx.shared import Pt, Inches
+from docx.shared import Pt, Inches, Cm
 from docx.enum.text import WD_ALIGN_PARAGRAPH
 from docx.enum.style import WD_STYLE_TYPE
+from docx.oxml import OxmlElement
+from docx.oxml.ns import qn
 import logging
-from .models import DocumentGenerationRequest, ChapterData, ReferenceData
+from .models import DocumentGenerationRequest, ChapterData, ReferenceData, FigureData, AppendixData, TimelineData
+from .bidi import set_style_fonts, write
+from .front_matter import add_front_matter
 
 logger = logging.getLogger(__name__)
 
+# Labels used when the request does not carry them in the document's language
+DEFAULT_LABELS = {
+    "by": "By",
+    "specialization": "Specialization",
+    "institution": "Institution",
+    "references": "References",
+    "appendix": "Appendix",
+    "timeline": "Research Timeline",
+    "task": "Task",
+    "milestones": "Milestones",
+    "submitted_to": "Prepared for submission to",
+    "submitted_for": "A thesis submitted in partial fulfilment of the requirements for the degree of",
+    "supervisor": "Supervisor",
+    "declaration": "Declaration",
+    "declaration_text": "I, {student}, declare that this thesis, titled \"{title}\", is my own work. Where I have consulted the work of others, it is clearly attributed. This work has not been submitted for any other degree or qualification at {institution} or any other institution.",
+    "acknowledgments": "Acknowledgments",
+    "contents": "Table of Contents",
+    "list_of_figures": "List of Figures",
+    "list_of_tables": "List of Tables",
+    "update_field": "Right-click and choose Update Field to build this table.",
+    "ai_share_notice": "AI-generated content: about {percent}% of the text below was written by AI.",
+    "ai_notice": "AI-generated content: the text below was written by AI.",
+}
+
+# Page sizes in centimetres (width, height)
+PAPER_SIZES = {"A4": (21.0, 29.7), "Letter": (21.59, 27.94)}
+
+
+def apply_page_setup(doc, options: dict):
+    """Applies paper size and margins from the formatting options to every section."""
+    paper = PAPER_SIZES.get(options.get("paper_size", ""))
+    margins = {
+        "top_margin": options.get("margin_top_cm"),
+        "bottom_margin": options.get("margin_bottom_cm"),
+        "left_margin": options.get("margin_left_cm"),
+        "right_margin": options.get("margin_right_cm"),
+    }
+    for section in doc.sections:
+        if paper:
+            section.page_width, section.page_height = Cm(paper[0]), Cm(paper[1])
+        for attr, value in margins.items():
+            if value:
+                setattr(section, attr, Cm(value))
+
+
+def chapter_heading(title_format: str, number: int, title: str, numbering: str = "arabic") -> str:
+    """
+    Formats a chapter heading, e.g. "CHAPTER {number}: {title}", with the number in arabic
+    or roman numerals. Falls back to the plain title when no format is set or it has no
+    placeholders.
+    """
+    if "{title}" not in title_format and "{number}" not in title_format:
+        return title
+    numeral = roman_numeral(number) if numbering == "roman" else str(number)
+    return title_format.replace("{number}", numeral).replace("{title}", title)
+
+
+def roman_numeral(number: int) -> str:
+    numerals = [(1000, "M"), (900, "CM"), (500, "D"), (400, "CD"), (100, "C"), (90, "XC"),
+                (50, "L"), (40, "XL"), (10, "X"), (9, "IX"), (5, "V"), (4, "IV"), (1, "I")]
+    out = ""
+    for value, numeral in numerals:
+        while number >= value:
+            out += numeral
+            number -= value
+    return out
+
+
+# Caption styles, collected by the list of figures and the list of tables
+FIGURE_CAPTION_STYLE = "Figure Caption"
+TABLE_CAPTION_STYLE = "Table Caption"
+
+
+def listed_entries(data: DocumentGenerationRequest, labels: dict) -> dict:
+    """
+    The entries of the table of contents and the lists of figures and tables, in document
+    order, shown in their fields until Word updates them with page numbers.
+    """
+    title_format = data.formatting_options.get("chapter_title_format", "")
+    numbering = data.formatting_options.get("chapter_numbering", "arabic")
+    contents = []
+    for n, ch in enumerate(data.chapters, start=1):
+        contents.append(chapter_heading(title_format, n, ch.title, numbering))
+        # The contents field lists Heading 1 to 3, which sections of level 1 and 2 are written in
+        contents += [f"{sec.number} {sec.title}" for sec in ch.sections or [] if sec.level < 3]
+    if data.timeline and data.timeline.tasks:
+        contents.append(labels['timeline'])
+    if data.references:
+        contents.append(labels['references'])
+    contents += [f"{labels['appendix']} {a.letter}: {a.title}" for a in data.appendices or []]
+    figures = [f for ch in data.chapters for f in ch.figures or []]
+    return {
+        "table_of_contents": contents,
+        "list_of_figures": [f"{f.label}: {f.caption}" for f in figures if f.kind == "figure" and f.image_base64],
+        "list_of_tables": [f"{f.label}: {f.caption}" for f in figures if f.kind == "table" and f.rows],
+    }
+
+
+def add_caption_styles(doc):
+    """
+    Adds the styles figure and table captions are written in, which the lists of figures
+    and tables collect. Captions keep the numbering the backend gave them, so Word's own
+    caption numbering is not used.
+    """
+    base = doc.styles['Caption'] if 'Caption' in doc.styles else doc.styles['Normal']
+    for name in (FIGURE_CAPTION_STYLE, TABLE_CAPTION_STYLE):
+        if name not in doc.styles:
+            style = doc.styles.add_style(name, WD_STYLE_TYPE.PARAGRAPH)
+            style.base_style = base
+
+
+# A line of chapter content that places a figure or table
+FIGURE_MARKER = re.compile(r"^\[\[(figure|table):[0-9a-f-]{36}\]\]$")
+NUMERIC_CELL = re.compile(r"^[-+]?\d[\d,.]*%?$")
+
+
+def add_figure(doc, figure: FigureData, rtl: bool = False):
+    """Adds a figure with its caption below, or a table with its caption above, as is usual in theses."""
+    caption = f"{figure.label}: {figure.caption}"
+    if figure.kind == "figure" and figure.image_base64:
+        doc.add_picture(BytesIO(base64.b64decode(figure.image_base64)), width=Inches(5.5))
+        doc.paragraphs[-1].alignment = WD_ALIGN_PARAGRAPH.CENTER
+        write(caption_paragraph(doc, FIGURE_CAPTION_STYLE), caption, rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+    elif figure.kind == "table" and figure.rows:
+        write(caption_paragraph(doc, TABLE_CAPTION_STYLE), caption, rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+        table = doc.add_table(rows=len(figure.rows), cols=len(figure.rows[0]))
+        table.style = 'Table Grid'
+        if rtl: # Columns run right to left
+            table._tbl.tblPr.append(OxmlElement('w:bidiVisual'))
+        header = OxmlElement('w:tblHeader') # Header row repeats on each page of a long table
+        table.rows[0]._tr.get_or_add_trPr().append(header)
+        for r, row in enumerate(figure.rows):
+            for c, text in enumerate(row):
+                paragraph = write(table.cell(r, c).paragraphs[0], text, rtl)
+                if r == 0: # Header row
+                    for run in paragraph.runs:
+                        run.bold = True
+                elif NUMERIC_CELL.match(text.strip()): # Figures centred, as in statistical tables
+                    paragraph.alignment = WD_ALIGN_PARAGRAPH.CENTER
+        doc.add_paragraph() # Spacer after the table
+
+
+def add_content(doc, content: str, figures: dict, rtl: bool = False):
+    """
+    Adds a chapter's or section's paragraphs, placing the figures and tables their markers
+    name. Figures placed are removed from figures, so the rest can follow the chapter.
+    """
+    # Split content into paragraphs. Assume content might have newlines.
+    for para_text in content.split('\n'):
+        para_text = para_text.strip()
+        if para_text in figures:
+            add_figure(doc, figures.pop(para_text), rtl)
+        elif FIGURE_MARKER.match(para_text):
+            logger.warning(f"Dropping marker of a missing figure or table: {para_text}")
+        elif para_text: # Add paragraph if not empty
+            write(doc.add_paragraph(), para_text, rtl)
+
+
+def add_ai_notice(doc, text: str, rtl: bool = False):
+    """Adds the note marking AI-generated text under a heading, small and in italics."""
+    paragraph = write(doc.add_paragraph(), text, rtl)
+    for run in paragraph.runs:
+        run.italic = True
+        run.font.size = Pt(9)
+
+
+def caption_paragraph(doc, style: str):
+    """A paragraph for a caption, in its style when the document has it (articles do not)."""
+    return doc.add_paragraph(style=style if style in doc.styles else None)
+
+
+def add_appendix(doc, appendix: AppendixData, labels: dict, rtl: bool = False):
+    """Adds an appendix on a new page: its heading, its text, then its image if any."""
+    doc.add_page_break()
+    write(doc.add_heading(level=1), f"{labels['appendix']} {appendix.letter}: {appendix.title}", rtl)
+    for para_text in (appendix.content or "").split('\n'):
+        if para_text.strip():
+            write(doc.add_paragraph(), para_text.strip(), rtl)
+    if appendix.image_base64:
+        doc.add_picture(BytesIO(base64.b64decode(appendix.image_base64)), width=Inches(6))
+        doc.paragraphs[-1].alignment = WD_ALIGN_PARAGRAPH.CENTER
+
+
+def timeline_months(start_date: str, end_date: str) -> list:
+    """Lists the months a timeline spans as YYYY-MM, the columns of its Gantt chart."""
+    year, month = int(start_date[:4]), int(start_date[5:7])
+    months = []
+    while f"{year:04d}-{month:02d}" <= end_date[:7]:
+        months.append(f"{year:04d}-{month:02d}")
+        year, month = (year + 1, 1) if month == 12 else (year, month + 1)
+    return months
+
+
+def shade_cell(cell, fill: str):
+    """Fills a table cell with a solid colour, given as hex RGB."""
+    shading = OxmlElement('w:shd')
+    shading.set(qn('w:val'), 'clear')
+    shading.set(qn('w:fill'), fill)
+    cell._tc.get_or_add_tcPr().append(shading)
+
+
+def add_timeline(doc, timeline: TimelineData, labels: dict, rtl: bool = False):
+    """
+    Adds the research timeline on a new page as a Gantt chart: a row per task and a column
+    per month, shaded where the task runs, followed by the milestones.
+    """
+    doc.add_page_break()
+    write(doc.add_heading(level=1), labels['timeline'], rtl)
+    months = timeline_months(timeline.start_date, timeline.end_date)
+    table = doc.add_table(rows=len(timeline.tasks) + 1, cols=len(months) + 1)
+    table.style = 'Table Grid'
+    if rtl:
+        table._tbl.tblPr.append(OxmlElement('w:bidiVisual'))
+    for run in write(table.cell(0, 0).paragraphs[0], labels['task'], rtl).runs:
+        run.bold = True
+    for c, month in enumerate(months, start=1):
+        paragraph = table.cell(0, c).paragraphs[0]
+        paragraph.add_run(month).font.size = Pt(7)
+    for r, task in enumerate(timeline.tasks, start=1):
+        write(table.cell(r, 0).paragraphs[0], task.title, rtl)
+        for c, month in enumerate(months, start=1):
+            if task.start_date[:7] <= month <= task.end_date[:7]:
+                shade_cell(table.cell(r, c), '4F81BD')
+    if timeline.milestones:
+        write(doc.add_heading(level=2), labels['milestones'], rtl)
+        for milestone in timeline.milestones:
+            write(doc.add_paragraph(style='List Paragraph'), f"{milestone.date}: {milestone.title}", rtl)
+
+
 def create_research_document(data: DocumentGenerationRequest, output_path: str) -> str:
     """
     Generates a Word document from the provided research data and saves it.
@@ -26,35 +263,32 @@ def create_research_document(data: DocumentGenerationRequest, output_path: str)
         paragraph_format = style.paragraph_format
         paragraph_format.line_spacing = data.formatting_options.get("line_spacing", 1.5) # 1.5 lines
 
-        # --- Title Page (Very Basic) ---
-        doc.add_heading(data.research_title, level=0).alignment = WD_ALIGN_PARAGRAPH.CENTER
-        doc.add_paragraph() # Spacer
-        doc.add_paragraph(f"By: {data.student_name}").alignment = WD_ALIGN_PARAGRAPH.CENTER
-        doc.add_paragraph(f"Specialization: {data.specialization}").alignment = WD_ALIGN_PARAGRAPH.CENTER
-        doc.add_paragraph(f"Institution: {data.university_name}").alignment = WD_ALIGN_PARAGRAPH.CENTER
-        doc.add_page_break()
-
-        # --- Table of Contents (Placeholder - python-docx doesn't auto-generate fully dynamic ToC easily) ---
-        # You might need to instruct users to "Update Field" in Word.
-        # Or use more advanced techniques or libraries if a fully automated ToC is critical for MVP.
-        # For a very simple placeholder:
-        # toc_heading = doc.add_heading('Table of Contents', level=1)
-        # toc_paragraph = doc.add_paragraph()
-        # run = toc_paragraph.add_run()
-        # fldChar = OxmlElement('w:fldChar')
-        # fldChar.set(qn('w:fldCharType'), 'begin')
-        # run._r.append(fldChar)
-        # run = toc_paragraph.add_run()
-        # instrText = OxmlElement('w:instrText')
-        # instrText.set(qn('xml:space'), 'preserve')
-        # instrText.text = 'TOC \\o "1-3" \\h \\z \\u' # Word field code for ToC
-        # run._r.append(instrText)
-        # run = toc_paragraph.add_run()
-        # fldChar = OxmlElement('w:fldChar')
-        # fldChar.set(qn('w:fldCharType'), 'end')
-        # run._r.append(fldChar)
-        # doc.add_page_break()
-        logger.info("Skipping Table of Contents generation for MVP simplicity.")
+        apply_page_setup(doc, data.formatting_options)
+
+        labels = {**DEFAULT_LABELS, **(data.labels or {})}
+        rtl = data.direction == "rtl"
+        article = data.layout == "article"
+        if rtl:
+            complex_font = data.formatting_options.get("font_family_complex", "Simplified Arabic")
+            set_style_fonts(style, complex_font, data.formatting_options.get("font_size_main", 12))
+            for name in ("Title", "Heading 1", "Heading 2", "List Paragraph"):
+                set_style_fonts(doc.styles[name], complex_font)
+
+        if article:
+            # A manuscript runs on from its title block, with sections rather than chapters
+            write(doc.add_heading(level=0), data.research_title, rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+            doc.add_paragraph() # Spacer
+            if data.student_name:
+                write(doc.add_paragraph(), f"{labels['by']}: {data.student_name}", rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+            write(doc.add_paragraph(), f"{labels['specialization']}: {data.specialization}", rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+            write(doc.add_paragraph(), f"{labels['institution']}: {data.university_name}", rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+            if data.journal:
+                write(doc.add_paragraph(), f"{labels['submitted_to']}: {data.journal}", rtl).alignment = WD_ALIGN_PARAGRAPH.CENTER
+            doc.add_paragraph()
+        else:
+            # --- Title page, declaration, table of contents... as the template lists them ---
+            add_caption_styles(doc)
+            add_front_matter(doc, data, labels, listed_entries(data, labels), rtl)
 
 
         # --- Chapters ---
@@ -67,34 +301,57 @@ def create_research_document(data: DocumentGenerationRequest, output_path: str)
         #     heading1_style.font.size = Pt(16)
 
 
-        for chapter in data.chapters:
+        title_format = "" if article else data.formatting_options.get("chapter_title_format", "")
+        numbering = data.formatting_options.get("chapter_numbering", "arabic")
+        for number, chapter in enumerate(data.chapters, start=1):
             logger.info(f"Adding chapter: {chapter.title}")
-            doc.add_heading(chapter.title, level=1) # Use built-in Heading 1
-            # Split content into paragraphs. Assume content might have newlines.
-            paragraphs = chapter.content.split('\n')
-            for para_text in paragraphs:
-                if para_text.strip(): # Add paragraph if not empty
-                    doc.add_paragraph(para_text.strip())
+            write(doc.add_heading(level=1), chapter_heading(title_format, number, chapter.title, numbering), rtl) # Use built-in Heading 1
+            if chapter.ai_share: # Watermarked exports mark AI-generated text
+                percent = min(max(1, round(chapter.ai_share * 100)), 100)
+                notice = labels['ai_notice'] if percent == 100 else labels['ai_share_notice'].replace("{percent}", str(percent))
+                add_ai_notice(doc, notice, rtl)
+            figures = {f.marker: f for f in chapter.figures or []}
+            add_content(doc, chapter.content, figures, rtl)
+            for section in chapter.sections or []: # Numbered under the chapter: 2.1, 2.1.1...
+                write(doc.add_heading(level=section.level + 1), f"{section.number} {section.title}", rtl)
+                if section.ai_generated:
+                    add_ai_notice(doc, labels['ai_notice'], rtl)
+                add_content(doc, section.content, figures, rtl)
+            for figure in figures.values(): # Not placed by a marker; they follow the text
+                add_figure(doc, figure, rtl)
             doc.add_paragraph() # Spacer after chapter content
 
+        # --- Research timeline after the chapters, if the project includes it ---
+        if data.timeline and data.timeline.tasks:
+            logger.info("Adding research timeline")
+            add_timeline(doc, data.timeline, labels, rtl)
+
         # --- References Section (Basic APA style example) ---
         if data.references:
             logger.info("Adding References section")
-            doc.add_heading('References', level=1)
-            # Sort references alphabetically if needed (complex for full APA)
-            for ref in data.references:
+            citation_style = data.formatting_options.get("citation_style", "APA")
+            numbered = citation_style == "IEEE" # IEEE lists sources by number in order of citation
+            write(doc.add_heading(level=1), labels['references'], rtl)
+            references = data.references if numbered else sorted(data.references, key=lambda r: (r.citation_apa or "").lower())
+            for number, ref in enumerate(references, start=1):
                 if ref.citation_apa:
                     # Add hanging indent for references (common in APA)
                     p = doc.add_paragraph(style='List Paragraph') # Or a custom reference style
                     p.paragraph_format.left_indent = Inches(0.0)
                     p.paragraph_format.first_line_indent = Inches(-0.5) # Negative for hanging
-                    p.add_run(ref.citation_apa)
+                    write(p, f"[{number}] {ref.citation_apa}" if numbered else ref.citation_apa, rtl)
                 else:
                     # Fallback if only partial data
                     doc.add_paragraph(f"Reference data missing for a source.", style='List Paragraph')
 
+        # --- Appendices, each on its own page after the references ---
+        for appendix in data.appendices or []:
+            logger.info(f"Adding Appendix {appendix.letter}")
+            add_appendix(doc, appendix, labels, rtl)
+
 
-        file_name = f"project_{data.project_id}_{data.research_title.replace(' ', '_')[:30]}.docx"
+        suffix = "_article" if article else ""
+        file_name = f"project_{data.project_id}_{data.research_title.replace(' ', '_')[:30]}{suffix}.docx"
         full_output_path = f"{output_path}/{file_name}"
         doc.save(full_output_path)
         logger.info(f"Document saved to {full_output_path}")
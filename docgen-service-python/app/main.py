@@ -1,11 +1,16 @@
-from fastapi import FastAPI, HTTPException, BackgroundTasks, status
+from fastapi import FastAPI, HTTPException, BackgroundTasks, Depends, Header, Request, status
 from fastapi.responses import FileResponse, JSONResponse
+import hmac
 import logging
 import os
+from typing import Optional
 import uuid # For filename generation if needed directly here
 
-from .models import DocumentGenerationRequest, DocumentGenerationResponse
+from .models import DocumentGenerationRequest, DocumentGenerationResponse, OutlineExtractionResponse, PosterGenerationRequest, PresentationGenerationRequest, TextExtractionResponse
 from .generator import create_research_document
+from .poster import create_poster
+from .presentation import create_defense_presentation
+from .extractor import UnreadableDOCXError, UnreadablePDFError, extract_docx_outline, extract_docx_text, extract_pdf_text
 
 # Configure logging
 logging.basicConfig(level=logging.INFO)
@@ -17,6 +22,23 @@ app = FastAPI(
     version="0.1.0"
 )
 
+# Shared secret the Go backend sends as a bearer token (PYTHON_DOCGEN_SECRET there).
+# When unset the service accepts unauthenticated requests, which is only meant for local development.
+SHARED_SECRET = os.getenv("DOCGEN_SHARED_SECRET", "")
+if not SHARED_SECRET:
+    logger.warning("DOCGEN_SHARED_SECRET is not set; requests are not authenticated")
+
+
+def _token_matches(authorization: Optional[str]) -> bool:
+    scheme, _, token = (authorization or "").partition(" ")
+    return scheme.lower() == "bearer" and hmac.compare_digest(token.strip(), SHARED_SECRET)
+
+
+def require_shared_secret(authorization: Optional[str] = Header(default=None)):
+    if SHARED_SECRET and not _token_matches(authorization):
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid or missing credentials.")
+
+
 # Ensure output directory exists
 OUTPUT_DIR = os.getenv("DOCGEN_OUTPUT_DIR", "./generated_documents")
 if not os.path.exists(OUTPUT_DIR):
@@ -24,7 +46,8 @@ if not os.path.exists(OUTPUT_DIR):
     logger.info(f"Created output directory: {OUTPUT_DIR}")
 
 
-@app.post("/generate-document", response_model=DocumentGenerationResponse, status_code=status.HTTP_202_ACCEPTED)
+@app.post("/generate-document", response_model=DocumentGenerationResponse, status_code=status.HTTP_202_ACCEPTED,
+          dependencies=[Depends(require_shared_secret)])
 async def generate_document_endpoint(request_data: DocumentGenerationRequest, background_tasks: BackgroundTasks):
     """
     Accepts research data and initiates document generation.
@@ -57,9 +80,93 @@ async def generate_document_endpoint(request_data: DocumentGenerationRequest, ba
         raise HTTPException(status_code=status.HTTP_500_INTERNAL_SERVER_ERROR, detail=f"Document generation failed: {str(e)}")
 
 
+@app.post("/generate-presentation", response_model=DocumentGenerationResponse, dependencies=[Depends(require_shared_secret)])
+async def generate_presentation_endpoint(request_data: PresentationGenerationRequest):
+    """
+    Renders a defense presentation from slides the Go backend wrote with the AI.
+    Like documents, the file is saved to the output directory for the Go backend to serve.
+    """
+    logger.info(f"Received presentation generation request for project ID: {request_data.project_id} ({len(request_data.slides)} slides)")
+    try:
+        file_name, file_path = create_defense_presentation(request_data, OUTPUT_DIR)
+    except Exception as e:
+        logger.error(f"Failed to generate presentation for project {request_data.project_id}: {e}", exc_info=True)
+        raise HTTPException(status_code=status.HTTP_500_INTERNAL_SERVER_ERROR, detail=f"Presentation generation failed: {str(e)}")
+    logger.info(f"Presentation {file_name} generated for project {request_data.project_id}. Stored at {file_path}")
+    return DocumentGenerationResponse(
+        project_id=request_data.project_id,
+        file_name=file_name,
+        message="Presentation generated. Go backend should update DB.",
+    )
+
+
+@app.post("/generate-poster", response_model=DocumentGenerationResponse, dependencies=[Depends(require_shared_secret)])
+async def generate_poster_endpoint(request_data: PosterGenerationRequest):
+    """
+    Lays out an A0 or A1 poster from content the Go backend prepared, as a one-slide PPTX
+    saved to the output directory for the Go backend to serve.
+    """
+    logger.info(f"Received poster generation request for project ID: {request_data.project_id} ({request_data.size}, {request_data.template})")
+    try:
+        file_name, file_path = create_poster(request_data, OUTPUT_DIR)
+    except Exception as e:
+        logger.error(f"Failed to generate poster for project {request_data.project_id}: {e}", exc_info=True)
+        raise HTTPException(status_code=status.HTTP_500_INTERNAL_SERVER_ERROR, detail=f"Poster generation failed: {str(e)}")
+    logger.info(f"Poster {file_name} generated for project {request_data.project_id}. Stored at {file_path}")
+    return DocumentGenerationResponse(
+        project_id=request_data.project_id,
+        file_name=file_name,
+        message="Poster generated. Go backend should update DB.",
+    )
+
+
+DOCX_MIME_TYPE = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+PPTX_MIME_TYPE = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+
+
+@app.post("/extract-text", response_model=TextExtractionResponse, dependencies=[Depends(require_shared_secret)])
+async def extract_text_endpoint(request: Request):
+    """
+    Extracts the text of a PDF or DOCX sent as the raw request body, with the Content-Type
+    saying which. The Go backend calls this for source PDFs and formatting guidelines
+    students upload to a project. DOCX files have no fixed pages, so their page_count is 0.
+    """
+    data = await request.body()
+    if not data:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail="Request body must be a PDF or DOCX.")
+    content_type = request.headers.get("content-type", "application/pdf").split(";")[0].strip()
+    try:
+        if content_type == DOCX_MIME_TYPE:
+            text, page_count = extract_docx_text(data), 0
+        else:
+            text, page_count = extract_pdf_text(data)
+    except (UnreadablePDFError, UnreadableDOCXError) as e:
+        # 422 tells the caller retrying will not help
+        raise HTTPException(status_code=status.HTTP_422_UNPROCESSABLE_ENTITY, detail=f"Could not read file: {e}")
+    logger.info(f"Extracted {len(text)} characters from a {content_type} file ({page_count} pages)")
+    return TextExtractionResponse(text=text, page_count=page_count)
+
+
+@app.post("/extract-outline", response_model=OutlineExtractionResponse, dependencies=[Depends(require_shared_secret)])
+async def extract_outline_endpoint(request: Request):
+    """
+    Reads a DOCX sent as the raw request body as an outline of headings and paragraphs.
+    The Go backend splits it into chapters and references when a student imports a thesis.
+    """
+    data = await request.body()
+    if not data:
+        raise HTTPException(status_code=status.HTTP_400_BAD_REQUEST, detail="Request body must be a DOCX.")
+    try:
+        title, blocks = extract_docx_outline(data)
+    except UnreadableDOCXError as e:
+        raise HTTPException(status_code=status.HTTP_422_UNPROCESSABLE_ENTITY, detail=f"Could not read file: {e}")
+    logger.info(f"Extracted an outline of {len(blocks)} paragraphs from a DOCX file")
+    return OutlineExtractionResponse(title=title, blocks=blocks)
+
+
 # This endpoint is more for direct testing of the Python service or if Go service pulls the file.
 # In the planned architecture, Go service updates its DB with file_path and serves the download.
-@app.get("/download/{file_name}")
+@app.get("/download/{file_name}", dependencies=[Depends(require_shared_secret)])
 async def download_generated_document(file_name: str):
     file_path = os.path.join(OUTPUT_DIR, file_name)
     if not os.path.exists(file_path):
@@ -67,11 +174,16 @@ async def download_generated_document(file_name: str):
         raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail="File not found.")
     
     logger.info(f"Serving file for download: {file_name}")
-    return FileResponse(path=file_path, filename=file_name, media_type='application/vnd.openxmlformats-officedocument.wordprocessingml.document')
+    media_type = PPTX_MIME_TYPE if file_name.endswith(".pptx") else DOCX_MIME_TYPE
+    return FileResponse(path=file_path, filename=file_name, media_type=media_type)
 
 
 @app.get("/health")
-async def health_check():
+async def health_check(authorization: Optional[str] = Header(default=None)):
+    # Open to probes without credentials, but a caller that sends credentials
+    # gets them checked, so the Go backend can detect a mismatched secret at startup
+    if authorization is not None and SHARED_SECRET and not _token_matches(authorization):
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid credentials.")
     return {"status": "healthy"}
 
 if __name__ == "__main__":
@@ -2,28 +2,127 @@ from pydantic import BaseModel, Field
 from typing import List, Optional, Dict, Any
 import uuid
 
+class FigureData(BaseModel):
+    kind: str = Field(..., description="figure or table")
+    label: str = Field(..., description="e.g., Figure 2.1")
+    caption: str
+    marker: str = Field(..., description="Line of chapter content the figure or table replaces")
+    image_base64: Optional[str] = None # Figures
+    rows: Optional[List[List[str]]] = None # Tables, the first row the header
+
+class SectionData(BaseModel):
+    number: str = Field(..., description="e.g., 2.1.3")
+    level: int = Field(1, ge=1, le=3, description="1 for 2.1, 2 for 2.1.1, 3 for 2.1.1.1")
+    title: str
+    content: str = ""
+    ai_generated: bool = False # Marked as AI-generated when exports are watermarked
+
 class ChapterData(BaseModel):
     type: str = Field(..., description="e.g., introduction, literature_review")
     title: str = Field(..., description="Title of the chapter")
     content: str = Field(..., description="Full content of the chapter")
+    figures: Optional[List[FigureData]] = []
+    sections: Optional[List[SectionData]] = [] # Sub-chapters, after the chapter's own content
+    ai_share: Optional[float] = None # Share of the text AI generated, 0 to 1; set when exports are watermarked
 
 class ReferenceData(BaseModel):
     citation_apa: Optional[str] = None # Assuming we primarily use APA for now
     # Add other fields if needed by docx (e.g., full reference details for different styles)
 
+class AppendixData(BaseModel):
+    letter: str = Field(..., description="A, B, ...")
+    title: str
+    content: Optional[str] = ""
+    image_base64: Optional[str] = None
+
+class TimelineTask(BaseModel):
+    title: str
+    start_date: str # YYYY-MM-DD, inclusive
+    end_date: str
+
+class TimelineMilestone(BaseModel):
+    title: str
+    date: str
+
+class TimelineData(BaseModel):
+    start_date: str
+    end_date: str
+    tasks: List[TimelineTask]
+    milestones: Optional[List[TimelineMilestone]] = []
+
+class FrontMatterData(BaseModel):
+    degree: Optional[str] = ""
+    supervisor: Optional[str] = ""
+    declaration: Optional[str] = "" # Replaces the standard declaration
+    dedication: Optional[str] = ""
+    acknowledgments: Optional[str] = ""
+
 class DocumentGenerationRequest(BaseModel):
     project_id: uuid.UUID
     research_title: str
-    student_name: Optional[str] = "A. Student" # Placeholder
+    student_name: Optional[str] = "" # From the profile; left off the title page when empty
     university_name: Optional[str] = "University of Example"
     specialization: Optional[str] = "Field of Study"
     chapters: List[ChapterData]
     references: Optional[List[ReferenceData]] = []
+    appendices: Optional[List[AppendixData]] = []
+    timeline: Optional[TimelineData] = None # Rendered as a Gantt chart after the chapters
     formatting_options: Optional[Dict[str, Any]] = {} # e.g., {"citation_style": "APA", "font": "Times New Roman"}
+    direction: Optional[str] = "ltr" # rtl for Arabic documents
+    labels: Optional[Dict[str, str]] = {} # Fixed strings in the document's language, e.g. {"references": "المراجع"}
+    layout: Optional[str] = "thesis" # or article, for a journal manuscript condensed from the thesis
+    journal: Optional[str] = None # Journal an article is prepared for
+    front_matter: Optional[FrontMatterData] = None # Pages listed in formatting_options["front_matter"]
+
+class SlideData(BaseModel):
+    title: str
+    bullets: List[str] = []
+    notes: Optional[str] = None # Speaker notes
+
+class PresentationGenerationRequest(BaseModel):
+    project_id: uuid.UUID
+    research_title: str
+    student_name: Optional[str] = "A. Student"
+    university_name: Optional[str] = "University of Example"
+    specialization: Optional[str] = "Field of Study"
+    slides: List[SlideData] # Follow the title slide, in order
+    direction: Optional[str] = "ltr"
+    labels: Optional[Dict[str, str]] = {}
+
+class PosterSection(BaseModel):
+    heading: str
+    bullets: List[str] = []
+
+class PosterGenerationRequest(BaseModel):
+    project_id: uuid.UUID
+    research_title: str
+    student_name: Optional[str] = "A. Student"
+    university_name: Optional[str] = "University of Example"
+    specialization: Optional[str] = "Field of Study"
+    abstract: Optional[str] = ""
+    sections: List[PosterSection] = [] # Between the abstract and the conclusions
+    conclusions: List[str] = []
+    figures: Optional[List[FigureData]] = [] # Key figures, placed after the sections
+    size: Optional[str] = "A0" # A0 or A1
+    template: Optional[str] = "three_column" # three_column, two_column or landscape
+    direction: Optional[str] = "ltr"
+    labels: Optional[Dict[str, str]] = {}
 
 class DocumentGenerationResponse(BaseModel):
     project_id: uuid.UUID
     file_name: str
     message: str
     # file_content_base64: Optional[str] = None # If returning file directly, not recommended for large files
-    # Instead, the service will save the file and Go backend will fetch it or provide a download link.
\ No newline at end of file
+    # Instead, the service will save the file and Go backend will fetch it or provide a download link.
+
+class TextExtractionResponse(BaseModel):
+    text: str
+    page_count: int
+
+class OutlineBlock(BaseModel):
+    text: str
+    level: int # Heading level, 0 for body text
+
+class OutlineExtractionResponse(BaseModel):
+    title: str # The document's Title-style paragraph, if any
+    blocks: List[OutlineBlock]
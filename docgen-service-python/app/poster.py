@@ -0,0 +1,195 @@
+"""
+Academic posters. The Go backend has the AI condense the thesis into an abstract, a few
+bulleted sections and conclusions, and picks the key figures; this module lays them out
+on a single A0 or A1 PowerPoint slide, which prints as the poster.
+
+Layout templates differ in orientation and column count. Blocks are flowed down the
+columns in order, moving to the next column when one is full; text heights are estimated,
+since PowerPoint only measures text when it renders.
+"""
+import base64
+from io import BytesIO
+import logging
+
+from PIL import Image
+from pptx import Presentation
+from pptx.dml.color import RGBColor
+from pptx.enum.shapes import MSO_SHAPE
+from pptx.enum.text import PP_ALIGN
+from pptx.util import Cm, Pt
+
+from .bidi import has_arabic
+from .models import FigureData, PosterGenerationRequest
+
+logger = logging.getLogger(__name__)
+
+# Portrait sizes in centimetres (width, height)
+POSTER_SIZES = {"A0": (84.1, 118.9), "A1": (59.4, 84.1)}
+
+# Layout templates: orientation and number of columns
+POSTER_TEMPLATES = {
+    "three_column": {"landscape": False, "columns": 3},
+    "two_column": {"landscape": False, "columns": 2},
+    "landscape": {"landscape": True, "columns": 4},
+}
+
+DEFAULT_LABELS = {
+    "by": "By",
+    "abstract": "Abstract",
+    "conclusions": "Conclusions",
+}
+
+# Sizes at A0, scaled down for smaller posters
+TITLE_PT = 80
+AUTHOR_PT = 40
+HEADING_PT = 44
+BODY_PT = 28
+CAPTION_PT = 22
+
+HEADER_COLOR = RGBColor(0x1F, 0x3A, 0x5F)
+MARGIN_CM = 2.0
+GUTTER_CM = 1.5
+BLOCK_GAP_CM = 1.2
+
+
+def text_height_cm(text: str, width_cm: float, size_pt: float) -> float:
+    """Estimates the height of wrapped text: average glyphs are about half as wide as tall."""
+    chars_per_line = max(1, int(width_cm / (size_pt * 0.5 * 0.0353)))
+    lines = sum(max(1, -(-len(line) // chars_per_line)) for line in text.split("\n"))
+    return lines * size_pt * 1.25 * 0.0353
+
+
+def set_direction(paragraph, rtl: bool):
+    if rtl and has_arabic(paragraph.text):
+        paragraph.alignment = PP_ALIGN.RIGHT
+        paragraph._p.get_or_add_pPr().set("rtl", "1")
+
+
+def add_text(slide, left, top, width, height, paragraphs, rtl: bool):
+    """Adds a text box of (text, size_pt, bold, color) paragraphs."""
+    box = slide.shapes.add_textbox(Cm(left), Cm(top), Cm(width), Cm(height))
+    frame = box.text_frame
+    frame.word_wrap = True
+    for i, (text, size_pt, bold, color) in enumerate(paragraphs):
+        paragraph = frame.paragraphs[0] if i == 0 else frame.add_paragraph()
+        paragraph.text = text
+        paragraph.font.size = Pt(size_pt)
+        paragraph.font.bold = bold
+        if color is not None:
+            paragraph.font.color.rgb = color
+        set_direction(paragraph, rtl)
+    return box
+
+
+class Block:
+    """A unit of poster content kept together in one column."""
+
+    def __init__(self, heading: str, lines=None, figure: FigureData = None):
+        self.heading = heading
+        self.lines = lines or []
+        self.figure = figure
+        self.image = None
+        if figure and figure.image_base64:
+            self.image = base64.b64decode(figure.image_base64)
+
+    def height(self, width_cm: float, scale: float) -> float:
+        height = 0.0
+        if self.heading:
+            height += text_height_cm(self.heading, width_cm, HEADING_PT * scale) + 0.4
+        for line in self.lines:
+            height += text_height_cm(line, width_cm, BODY_PT * scale) + 0.2
+        if self.image:
+            w, h = Image.open(BytesIO(self.image)).size
+            height += width_cm * h / w + 0.4
+            height += text_height_cm(self.caption(), width_cm, CAPTION_PT * scale)
+        return height
+
+    def caption(self) -> str:
+        return f"{self.figure.label}: {self.figure.caption}" if self.figure else ""
+
+    def render(self, slide, left, top, width, scale, rtl: bool):
+        y = top
+        if self.heading:
+            h = text_height_cm(self.heading, width, HEADING_PT * scale) + 0.4
+            add_text(slide, left, y, width, h, [(self.heading, HEADING_PT * scale, True, HEADER_COLOR)], rtl)
+            y += h
+        if self.lines:
+            paragraphs = [(line, BODY_PT * scale, False, None) for line in self.lines]
+            h = sum(text_height_cm(line, width, BODY_PT * scale) + 0.2 for line in self.lines)
+            add_text(slide, left, y, width, h, paragraphs, rtl)
+            y += h
+        if self.image:
+            w, h = Image.open(BytesIO(self.image)).size
+            picture_height = width * h / w
+            slide.shapes.add_picture(BytesIO(self.image), Cm(left), Cm(y), width=Cm(width))
+            y += picture_height + 0.2
+            add_text(slide, left, y, width, text_height_cm(self.caption(), width, CAPTION_PT * scale),
+                     [(self.caption(), CAPTION_PT * scale, False, None)], rtl)
+
+
+def create_poster(data: PosterGenerationRequest, output_path: str) -> tuple:
+    """
+    Generates a one-slide PowerPoint poster from the request and saves it.
+    Returns the name and path of the generated file.
+    """
+    template = POSTER_TEMPLATES.get(data.template, POSTER_TEMPLATES["three_column"])
+    width, height = POSTER_SIZES.get(data.size, POSTER_SIZES["A0"])
+    if template["landscape"]:
+        width, height = height, width
+    scale = POSTER_SIZES.get(data.size, POSTER_SIZES["A0"])[0] / POSTER_SIZES["A0"][0]
+    labels = {**DEFAULT_LABELS, **(data.labels or {})}
+    rtl = data.direction == "rtl"
+
+    prs = Presentation()
+    prs.slide_width, prs.slide_height = Cm(width), Cm(height)
+    slide = prs.slides.add_slide(prs.slide_layouts[6]) # Blank
+
+    # Header band with the title and author
+    header_height = MARGIN_CM + text_height_cm(data.research_title, width - 2 * MARGIN_CM, TITLE_PT * scale) + 5 * scale
+    band = slide.shapes.add_shape(MSO_SHAPE.RECTANGLE, 0, 0, Cm(width), Cm(header_height))
+    band.fill.solid()
+    band.fill.fore_color.rgb = HEADER_COLOR
+    band.line.fill.background()
+    byline = f"{labels['by']}: {data.student_name}"
+    if data.university_name:
+        byline += f" | {data.university_name}"
+    white = RGBColor(0xFF, 0xFF, 0xFF)
+    title = add_text(slide, MARGIN_CM, MARGIN_CM / 2, width - 2 * MARGIN_CM, header_height - MARGIN_CM,
+                     [(data.research_title, TITLE_PT * scale, True, white), (byline, AUTHOR_PT * scale, False, white)], rtl)
+    for paragraph in title.text_frame.paragraphs:
+        if not rtl:
+            paragraph.alignment = PP_ALIGN.CENTER
+
+    blocks = []
+    if data.abstract:
+        blocks.append(Block(labels["abstract"], [data.abstract]))
+    for section in data.sections:
+        blocks.append(Block(section.heading, [f"• {b}" for b in section.bullets]))
+    for figure in data.figures or []:
+        if figure.image_base64:
+            blocks.append(Block("", figure=figure))
+    if data.conclusions:
+        blocks.append(Block(labels["conclusions"], [f"• {c}" for c in data.conclusions]))
+
+    # Flow the blocks down the columns; right to left for Arabic posters
+    columns = template["columns"]
+    column_width = (width - 2 * MARGIN_CM - (columns - 1) * GUTTER_CM) / columns
+    top = header_height + MARGIN_CM
+    bottom = height - MARGIN_CM
+    column, y = 0, top
+    for block in blocks:
+        block_height = block.height(column_width, scale)
+        if y > top and y + block_height > bottom and column < columns - 1:
+            column, y = column + 1, top
+        if y + block_height > bottom:
+            logger.warning(f"Poster content overflows the last column of a {data.size} {data.template} poster")
+        index = columns - 1 - column if rtl else column
+        left = MARGIN_CM + index * (column_width + GUTTER_CM)
+        block.render(slide, left, y, column_width, scale, rtl)
+        y += block_height + BLOCK_GAP_CM
+
+    file_name = f"project_{data.project_id}_{data.research_title.replace(' ', '_')[:30]}_poster.pptx"
+    full_output_path = f"{output_path}/{file_name}"
+    prs.save(full_output_path)
+    logger.info(f"Poster saved to {full_output_path}")
+    return file_name, full_output_path
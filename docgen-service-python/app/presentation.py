@@ -0,0 +1,77 @@
+"""
+Defense presentations. The Go backend has the AI write the slides from the thesis chapters;
+this module lays them out as a widescreen PowerPoint deck behind a title slide.
+"""
+import logging
+
+from pptx import Presentation
+from pptx.enum.text import PP_ALIGN
+from pptx.util import Inches, Pt
+
+from .bidi import has_arabic
+from .models import PresentationGenerationRequest
+
+logger = logging.getLogger(__name__)
+
+# Layouts of the default template
+TITLE_LAYOUT = 0
+CONTENT_LAYOUT = 1
+
+DEFAULT_LABELS = {
+    "by": "By",
+    "specialization": "Specialization",
+    "institution": "Institution",
+}
+
+
+def set_direction(text_frame, rtl: bool):
+    """Right-aligns and marks right-to-left the Arabic paragraphs of an RTL deck."""
+    if not rtl:
+        return
+    for paragraph in text_frame.paragraphs:
+        if has_arabic(paragraph.text):
+            paragraph.alignment = PP_ALIGN.RIGHT
+            paragraph._p.get_or_add_pPr().set("rtl", "1")
+
+
+def create_defense_presentation(data: PresentationGenerationRequest, output_path: str) -> tuple:
+    """
+    Generates a PowerPoint deck from the slides in the request and saves it.
+    Returns the name and path of the generated file.
+    """
+    prs = Presentation()
+    prs.slide_width, prs.slide_height = Inches(13.333), Inches(7.5) # 16:9
+    labels = {**DEFAULT_LABELS, **(data.labels or {})}
+    rtl = data.direction == "rtl"
+
+    title_slide = prs.slides.add_slide(prs.slide_layouts[TITLE_LAYOUT])
+    title_slide.shapes.title.text = data.research_title
+    set_direction(title_slide.shapes.title.text_frame, rtl)
+    subtitle = title_slide.placeholders[1].text_frame
+    subtitle.text = f"{labels['by']}: {data.student_name}"
+    for line in (f"{labels['specialization']}: {data.specialization}", f"{labels['institution']}: {data.university_name}"):
+        subtitle.add_paragraph().text = line
+    set_direction(subtitle, rtl)
+
+    for slide_data in data.slides:
+        logger.info(f"Adding slide: {slide_data.title}")
+        slide = prs.slides.add_slide(prs.slide_layouts[CONTENT_LAYOUT])
+        slide.shapes.title.text = slide_data.title
+        set_direction(slide.shapes.title.text_frame, rtl)
+        body = slide.placeholders[1]
+        body.left, body.top, body.width, body.height = Inches(0.8), Inches(1.6), Inches(11.7), Inches(5.4)
+        text_frame = body.text_frame
+        text_frame.word_wrap = True
+        for i, bullet in enumerate(slide_data.bullets):
+            paragraph = text_frame.paragraphs[0] if i == 0 else text_frame.add_paragraph()
+            paragraph.text = bullet
+            paragraph.font.size = Pt(24 if len(slide_data.bullets) <= 4 else 20)
+        set_direction(text_frame, rtl)
+        if slide_data.notes:
+            slide.notes_slide.notes_text_frame.text = slide_data.notes
+
+    file_name = f"project_{data.project_id}_{data.research_title.replace(' ', '_')[:30]}_defense.pptx"
+    full_output_path = f"{output_path}/{file_name}"
+    prs.save(full_output_path)
+    logger.info(f"Presentation saved to {full_output_path}")
+    return file_name, full_output_path
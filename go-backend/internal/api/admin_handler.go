@@ -0,0 +1,190 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/flags"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultJobLimit = 50
+	maxJobLimit     = 500
+)
+
+// adminMiddleware guards operator endpoints with the shared ADMIN_API_TOKEN
+func adminMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := strings.Fields(c.GetHeader(authorizationHeaderKey))
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+			response.Unauthorized(c, "admin token is not provided")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(fields[1]), []byte(adminToken)) != 1 {
+			response.Forbidden(c, "invalid admin token")
+			return
+		}
+		c.Next()
+	}
+}
+
+// listFeatureFlags returns the effective value of every flag, for clients to adapt their UI
+func (s *Server) listFeatureFlags(c *gin.Context) {
+	enabled := make(map[string]bool)
+	for _, f := range s.flags.List() {
+		enabled[f.Name] = f.Enabled
+	}
+	response.Ok(c, enabled)
+}
+
+func (s *Server) adminListFeatureFlags(c *gin.Context) {
+	response.Ok(c, s.flags.List())
+}
+
+func (s *Server) adminSetFeatureFlag(c *gin.Context) {
+	var req apimodels.SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	flag, err := s.flags.Set(c.Request.Context(), c.Param("name"), *req.Enabled)
+	if err != nil {
+		if errors.Is(err, flags.ErrUnknownFlag) {
+			response.NotFound(c, flags.ErrUnknownFlag.Error())
+			return
+		}
+		s.logger.Error("Failed to set feature flag", "flag", c.Param("name"), "error", err)
+		response.InternalServerError(c, "Failed to set feature flag", err)
+		return
+	}
+	response.Ok(c, flag, "Feature flag updated")
+}
+
+func (s *Server) adminResetFeatureFlag(c *gin.Context) {
+	flag, err := s.flags.Reset(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		if errors.Is(err, flags.ErrUnknownFlag) {
+			response.NotFound(c, flags.ErrUnknownFlag.Error())
+			return
+		}
+		s.logger.Error("Failed to reset feature flag", "flag", c.Param("name"), "error", err)
+		response.InternalServerError(c, "Failed to reset feature flag", err)
+		return
+	}
+	response.Ok(c, flag, "Feature flag override removed")
+}
+
+// adminListLogLevels returns the default level and every component override.
+// Components without an entry inherit from their parent ("services.ai" from "services").
+func (s *Server) adminListLogLevels(c *gin.Context) {
+	response.Ok(c, s.logger.Levels().All())
+}
+
+func (s *Server) adminSetLogLevel(c *gin.Context) {
+	var req apimodels.SetLogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	level, err := applogger.ParseLevel(req.Level)
+	if err != nil {
+		response.BadRequest(c, "Invalid log level", err.Error())
+		return
+	}
+	component := c.Param("component")
+	if err := s.logger.Levels().Set(component, level); err != nil {
+		response.BadRequest(c, "Invalid component", err.Error())
+		return
+	}
+	s.logger.Warn("Log level changed", "target", component, "level", req.Level)
+	response.Ok(c, s.logger.Levels().All(), "Log level updated")
+}
+
+func (s *Server) adminResetLogLevel(c *gin.Context) {
+	component := c.Param("component")
+	if component == applogger.DefaultComponent {
+		response.BadRequest(c, "The default level can be changed but not reset")
+		return
+	}
+	s.logger.Levels().Reset(component)
+	s.logger.Warn("Log level override removed", "target", component)
+	response.Ok(c, s.logger.Levels().All(), "Log level override removed")
+}
+
+// adminListJobs returns the newest background jobs, optionally filtered by status and kind
+func (s *Server) adminListJobs(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", jobs.StatusPending, jobs.StatusRunning, jobs.StatusCompleted, jobs.StatusDead:
+	default:
+		response.BadRequest(c, "status must be pending, running, completed or dead")
+		return
+	}
+	limit := defaultJobLimit
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxJobLimit {
+			response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxJobLimit))
+			return
+		}
+	}
+	list, err := s.jobs.List(c.Request.Context(), status, c.Query("kind"), limit)
+	if err != nil {
+		s.logger.Error("Failed to list jobs", "error", err)
+		response.InternalServerError(c, "Failed to list jobs", err)
+		return
+	}
+	response.Ok(c, list)
+}
+
+func (s *Server) adminGetJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID format")
+		return
+	}
+	job, err := s.jobs.Get(c.Request.Context(), jobID)
+	if err != nil {
+		s.respondJobError(c, "get job", err)
+		return
+	}
+	response.Ok(c, job)
+}
+
+func (s *Server) adminRequeueJob(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID format")
+		return
+	}
+	job, err := s.jobs.Requeue(c.Request.Context(), jobID)
+	if err != nil {
+		s.respondJobError(c, "requeue job", err)
+		return
+	}
+	s.logger.Warn("Job requeued", "jobID", jobID, "kind", job.Kind)
+	response.Ok(c, job, "Job requeued")
+}
+
+func (s *Server) respondJobError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, jobs.ErrJobRunning):
+		response.Conflict(c, "The job is running; wait for it to finish or fail", nil)
+	default:
+		s.logger.Error("Failed to "+action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
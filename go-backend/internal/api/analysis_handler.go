@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) interpretAnalysis(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.InterpretAnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	interpretation, err := s.researchService.InterpretAnalysis(c.Request.Context(), authPayload.UserID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrIntegrityAcknowledgmentRequired) {
+			s.respondIntegrityAcknowledgmentRequired(c)
+			return
+		}
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			response.PaymentRequired(c, err.Error())
+			return
+		}
+		var quotaErr *services.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			s.respondQuotaExceeded(c, quotaErr)
+			return
+		}
+		s.logger.Error("Failed to interpret analysis output", "userID", authPayload.UserID, "error", err)
+		response.InternalServerError(c, "Failed to interpret analysis output", err)
+		return
+	}
+	response.Ok(c, interpretation, "Analysis interpreted successfully")
+}
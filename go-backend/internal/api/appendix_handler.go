@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondAppendixError maps appendix errors to responses
+func (s *Server) respondAppendixError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrAppendixNotFound),
+		errors.Is(err, services.ErrAppendixNoFile):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrEmptyAfterSanitizing):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Appendix request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listProjectAppendices(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	appendices, err := s.researchService.ListAppendices(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondAppendixError(c, "list appendices", err)
+		return
+	}
+	response.Ok(c, appendices, "Appendices retrieved successfully")
+}
+
+func (s *Server) createProjectAppendix(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateAppendixRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	appendix, err := s.researchService.CreateAppendix(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondAppendixError(c, "create appendix", err)
+		return
+	}
+	response.Created(c, appendix, "Appendix created successfully")
+}
+
+func (s *Server) updateProjectAppendix(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	appendixID, ok := uuidParam(c, "appendix_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateAppendixRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	appendix, err := s.researchService.UpdateAppendix(c.Request.Context(), projectID, appendixID, authPayload.UserID, req)
+	if err != nil {
+		s.respondAppendixError(c, "update appendix", err)
+		return
+	}
+	response.Ok(c, appendix, "Appendix updated successfully")
+}
+
+func (s *Server) deleteProjectAppendix(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	appendixID, ok := uuidParam(c, "appendix_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteAppendix(c.Request.Context(), projectID, appendixID, authPayload.UserID); err != nil {
+		s.respondAppendixError(c, "delete appendix", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) uploadAppendixFile(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	appendixID, ok := uuidParam(c, "appendix_id")
+	if !ok {
+		return
+	}
+	_, file, ok := s.formFile(c, "an image")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	appendix, err := s.researchService.SetAppendixFile(c.Request.Context(), projectID, appendixID, authPayload.UserID, file)
+	if err != nil {
+		s.respondAppendixError(c, "attach appendix file", err)
+		return
+	}
+	response.Ok(c, appendix, "Appendix file attached successfully")
+}
+
+func (s *Server) getAppendixFile(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	appendixID, ok := uuidParam(c, "appendix_id")
+	if !ok {
+		return
+	}
+	filePath, mimeType, err := s.researchService.AppendixFile(c.Request.Context(), projectID, appendixID, authPayload.UserID)
+	if err != nil {
+		s.respondAppendixError(c, "get appendix file", err)
+		return
+	}
+	s.serveStoredFile(c, filePath, mimeType, "")
+}
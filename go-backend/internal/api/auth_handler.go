@@ -141,3 +141,52 @@ func (s *Server) clearAuthCookies(c *gin.Context) {
 	c.SetCookie("access_token", "", -1, "/", "", false, true)
 	c.SetCookie("refresh_token", "", -1, "/api/v1/auth/refresh-token", "", false, true)
 }
+
+// createGuest starts a trial: a guest account that expires with its sandbox project
+// after GUEST_TTL, within the limits of the guest plan
+func (s *Server) createGuest(c *gin.Context) {
+	var req models.CreateGuestRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	guest, err := s.authService.CreateGuest(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to start trial", err)
+		return
+	}
+	// The guest is purged on expiry even if its project never gets created
+	project, err := s.researchService.CreateGuestProject(c.Request.Context(), guest.User.ID, req)
+	if err != nil {
+		s.logger.Error("Failed to create guest project", "userID", guest.User.ID, "error", err)
+		response.InternalServerError(c, "Failed to start trial", err)
+		return
+	}
+	guest.Project = models.ToProjectResponse(project)
+	response.Created(c, guest, "Trial started")
+}
+
+// registerGuest turns the calling guest into a registered user, keeping its projects
+func (s *Server) registerGuest(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req models.RegisterUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	loginResp, err := s.authService.ClaimGuest(c.Request.Context(), authPayload.UserID, req, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserAlreadyExists),
+			errors.Is(err, services.ErrNotGuest):
+			response.RespondError(c, http.StatusConflict, err.Error())
+		default:
+			s.logger.Error("Guest registration service error", "userID", authPayload.UserID, "error", err)
+			response.InternalServerError(c, "Failed to register user", err)
+		}
+		return
+	}
+	response.Ok(c, loginResp, "User registered successfully")
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStripeWebhookBytes bounds the body read from Stripe; real events are a few kilobytes
+const maxStripeWebhookBytes = 1 << 20
+
+// respondBillingError maps billing errors to responses
+func (s *Server) respondBillingError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrNoBillingAccount):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrAlreadySubscribed):
+		response.Conflict(c, err.Error(), nil)
+	case errors.Is(err, services.ErrPlanNotPurchasable):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrGuestAccount):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Billing request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listBillingPlans(c *gin.Context) {
+	response.Ok(c, s.billing.Plans(), "Plans retrieved successfully")
+}
+
+func (s *Server) getSubscription(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	sub, err := s.billing.Subscription(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondBillingError(c, "get subscription", err)
+		return
+	}
+	response.Ok(c, sub, "Subscription retrieved successfully")
+}
+
+func (s *Server) createCheckoutSession(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	url, err := s.billing.CreateCheckoutSession(c.Request.Context(), authPayload.UserID, req.Plan)
+	if err != nil {
+		s.respondBillingError(c, "create checkout session", err)
+		return
+	}
+	response.Created(c, apimodels.BillingSessionResponse{URL: url}, "Checkout session created")
+}
+
+func (s *Server) createBillingPortalSession(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	url, err := s.billing.CreatePortalSession(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondBillingError(c, "create billing portal session", err)
+		return
+	}
+	response.Created(c, apimodels.BillingSessionResponse{URL: url}, "Billing portal session created")
+}
+
+// stripeWebhook applies subscription changes sent by Stripe. Any non-2xx response makes
+// Stripe retry the event, so only malformed or unsigned requests are rejected outright.
+func (s *Server) stripeWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxStripeWebhookBytes))
+	if err != nil {
+		response.BadRequest(c, "Could not read request body")
+		return
+	}
+	err = s.billing.HandleStripeWebhook(c.Request.Context(), payload, c.GetHeader("Stripe-Signature"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStripeEvent) {
+			s.logger.Warn("Rejected Stripe webhook", "error", err)
+			response.BadRequest(c, err.Error())
+			return
+		}
+		s.logger.Error("Failed to handle Stripe webhook", "error", err)
+		response.InternalServerError(c, "Failed to handle webhook", err)
+		return
+	}
+	response.NoContent(c)
+}
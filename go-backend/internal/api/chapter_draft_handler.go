@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondChapterDraftError maps chapter draft errors to responses
+func (s *Server) respondChapterDraftError(c *gin.Context, action string, err error) {
+	var conflictErr *services.DraftConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		// The draft was saved; the client merges it with the chapter before saving that
+		response.Conflict(c, err.Error(), conflictErr.Draft)
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrChapterDraftNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Chapter draft request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) saveChapterDraft(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateChapterDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	draft, err := s.researchService.SaveChapterDraft(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondChapterDraftError(c, "save chapter draft", err)
+		return
+	}
+	response.Ok(c, draft)
+}
+
+func (s *Server) getChapterDraft(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	draft, err := s.researchService.GetChapterDraft(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterDraftError(c, "get chapter draft", err)
+		return
+	}
+	response.Ok(c, draft)
+}
+
+func (s *Server) discardChapterDraft(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DiscardChapterDraft(c.Request.Context(), projectID, chapterID, authPayload.UserID); err != nil {
+		s.respondChapterDraftError(c, "discard chapter draft", err)
+		return
+	}
+	response.NoContent(c)
+}
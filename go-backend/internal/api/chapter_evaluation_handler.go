@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondChapterEvaluationError maps chapter evaluation errors to responses
+func (s *Server) respondChapterEvaluationError(c *gin.Context, action string, err error) {
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrChapterEvaluationNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidRubric),
+		errors.Is(err, services.ErrNothingToEvaluate):
+		response.BadRequest(c, err.Error())
+	case errors.As(err, &quotaErr):
+		s.respondQuotaExceeded(c, quotaErr)
+	default:
+		s.logger.Error("Chapter evaluation request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// evaluateChapter has the AI score a chapter against a rubric; the body is optional
+func (s *Server) evaluateChapter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.EvaluateChapterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	evaluation, err := s.researchService.EvaluateChapter(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondChapterEvaluationError(c, "evaluate chapter", err)
+		return
+	}
+	response.Created(c, evaluation, "Chapter evaluated successfully")
+}
+
+func (s *Server) listChapterEvaluations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	evaluations, err := s.researchService.ListChapterEvaluations(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterEvaluationError(c, "list chapter evaluations", err)
+		return
+	}
+	response.Ok(c, evaluations)
+}
+
+func (s *Server) getChapterEvaluation(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	evaluationID, ok := uuidParam(c, "evaluation_id")
+	if !ok {
+		return
+	}
+	evaluation, err := s.researchService.GetChapterEvaluation(c.Request.Context(), projectID, chapterID, evaluationID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterEvaluationError(c, "get chapter evaluation", err)
+		return
+	}
+	response.Ok(c, evaluation)
+}
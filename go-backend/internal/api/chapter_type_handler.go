@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondChapterTypeError maps chapter type registry errors to responses
+func (s *Server) respondChapterTypeError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrChapterTypeNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole), errors.Is(err, services.ErrNotOrganizationAdmin):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidChapterTypeKey), errors.Is(err, services.ErrEmptyAfterSanitizing):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrChapterTypeExists),
+		errors.Is(err, services.ErrReservedChapterTypeKey),
+		errors.Is(err, services.ErrChapterTypeInUse):
+		response.Conflict(c, err.Error(), nil)
+	default:
+		s.logger.Error("Chapter type request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func toChapterTypeResponses(types []sqlc.ChapterType) []apimodels.ChapterTypeResponse {
+	out := make([]apimodels.ChapterTypeResponse, 0, len(types))
+	for _, t := range types {
+		out = append(out, apimodels.ToChapterTypeResponse(t))
+	}
+	return out
+}
+
+func (s *Server) listProjectChapterTypes(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	types, err := s.researchService.ListProjectChapterTypes(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterTypeError(c, "retrieve chapter types", err)
+		return
+	}
+	response.Ok(c, toChapterTypeResponses(types))
+}
+
+func (s *Server) createProjectChapterType(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateChapterTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	chapterType, err := s.researchService.CreateProjectChapterType(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondChapterTypeError(c, "create chapter type", err)
+		return
+	}
+	response.Created(c, apimodels.ToChapterTypeResponse(chapterType), "Chapter type created successfully")
+}
+
+func (s *Server) deleteProjectChapterType(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteProjectChapterType(c.Request.Context(), projectID, authPayload.UserID, c.Param("key")); err != nil {
+		s.respondChapterTypeError(c, "delete chapter type", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listOrganizationChapterTypes(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	types, err := s.orgService.ListChapterTypes(c.Request.Context(), orgID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterTypeError(c, "retrieve chapter types", err)
+		return
+	}
+	response.Ok(c, toChapterTypeResponses(types))
+}
+
+func (s *Server) createOrganizationChapterType(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateChapterTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	chapterType, err := s.orgService.CreateChapterType(c.Request.Context(), orgID, authPayload.UserID, req)
+	if err != nil {
+		s.respondChapterTypeError(c, "create chapter type", err)
+		return
+	}
+	response.Created(c, apimodels.ToChapterTypeResponse(chapterType), "Chapter type created successfully")
+}
+
+func (s *Server) updateOrganizationChapterType(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateChapterTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	chapterType, err := s.orgService.UpdateChapterType(c.Request.Context(), orgID, authPayload.UserID, c.Param("key"), req)
+	if err != nil {
+		s.respondChapterTypeError(c, "update chapter type", err)
+		return
+	}
+	response.Ok(c, apimodels.ToChapterTypeResponse(chapterType), "Chapter type updated successfully")
+}
+
+func (s *Server) deleteOrganizationChapterType(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	if err := s.orgService.DeleteChapterType(c.Request.Context(), orgID, authPayload.UserID, c.Param("key")); err != nil {
+		s.respondChapterTypeError(c, "delete chapter type", err)
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondChapterVersionError maps chapter version errors to responses
+func (s *Server) respondChapterVersionError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrChapterVersionNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidDiffGranularity):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Chapter version request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// versionParam reads a chapter version number from the path
+func versionParam(c *gin.Context, name string) (int32, bool) {
+	v, err := strconv.ParseInt(c.Param(name), 10, 32)
+	if err != nil || v < 1 {
+		response.BadRequest(c, "Invalid "+name+" version")
+		return 0, false
+	}
+	return int32(v), true
+}
+
+func (s *Server) listChapterVersions(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	versions, err := s.researchService.ListChapterVersions(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterVersionError(c, "list chapter versions", err)
+		return
+	}
+	response.Ok(c, versions)
+}
+
+// diffChapterVersions compares versions a and b of a chapter; ?granularity= is line
+// (the default) or word
+func (s *Server) diffChapterVersions(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	from, ok := versionParam(c, "a")
+	if !ok {
+		return
+	}
+	to, ok := versionParam(c, "b")
+	if !ok {
+		return
+	}
+	granularity := c.DefaultQuery("granularity", services.DiffLines)
+	diff, err := s.researchService.DiffChapterVersions(c.Request.Context(), projectID, chapterID, authPayload.UserID, from, to, granularity)
+	if err != nil {
+		s.respondChapterVersionError(c, "diff chapter versions", err)
+		return
+	}
+	response.Ok(c, diff)
+}
+
+// getChapterProvenance reports which parts of a chapter AI generation wrote
+func (s *Server) getChapterProvenance(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	report, err := s.researchService.ChapterProvenance(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterVersionError(c, "get chapter provenance", err)
+		return
+	}
+	response.Ok(c, report)
+}
+
+// detectAIContent scores a chapter for how likely an AI detector is to flag it
+func (s *Server) detectAIContent(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.AIDetectionRequest
+	// The body is optional; without it the saved chapter is scored
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	report, err := s.researchService.DetectAIContent(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondChapterVersionError(c, "score chapter for AI content", err)
+		return
+	}
+	response.Ok(c, report)
+}
+
+// getChapterMetrics reports a chapter's readability, sentence lengths, passive voice and
+// hedging
+func (s *Server) getChapterMetrics(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	metrics, err := s.researchService.ChapterMetrics(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondChapterVersionError(c, "get chapter metrics", err)
+		return
+	}
+	response.Ok(c, metrics)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) listChapterCitations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	citations, err := s.researchService.ChapterCitations(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrChapterNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrInsufficientRole):
+			response.Forbidden(c, err.Error())
+		default:
+			s.logger.Error("Failed to list chapter citations", "chapterID", chapterID, "error", err)
+			response.InternalServerError(c, "Failed to list citations", err)
+		}
+		return
+	}
+	response.Ok(c, citations)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondCollaboratorError maps project sharing errors to responses
+func (s *Server) respondCollaboratorError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrCollaboratorNotFound),
+		errors.Is(err, services.ErrNoUserWithEmail):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrShareWithOwner):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Collaborator request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listProjectCollaborators(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	collaborators, err := s.researchService.ListCollaborators(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondCollaboratorError(c, "list collaborators", err)
+		return
+	}
+	resp := make([]apimodels.CollaboratorResponse, len(collaborators))
+	for i, collaborator := range collaborators {
+		resp[i] = apimodels.ToCollaboratorResponse(collaborator)
+	}
+	response.Ok(c, resp, "Collaborators retrieved successfully")
+}
+
+func (s *Server) addProjectCollaborator(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.AddCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	collaborator, err := s.researchService.AddCollaborator(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondCollaboratorError(c, "add collaborator", err)
+		return
+	}
+	response.Created(c, apimodels.ToCollaboratorResponse(collaborator), "Project shared successfully")
+}
+
+func (s *Server) removeProjectCollaborator(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	collaboratorID, ok := uuidParam(c, "user_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.RemoveCollaborator(c.Request.Context(), projectID, collaboratorID, authPayload.UserID); err != nil {
+		s.respondCollaboratorError(c, "remove collaborator", err)
+		return
+	}
+	response.NoContent(c)
+}
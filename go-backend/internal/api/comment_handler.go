@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondCommentError maps comment errors to responses
+func (s *Server) respondCommentError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrCommentThreadNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidAnchor), errors.Is(err, services.ErrEmptyAfterSanitizing):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Comment request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// openCommentCounts returns open thread counts per chapter for a project the user can read.
+// Counts are decoration, so a failure is logged and reads carry on with zeros.
+func (s *Server) openCommentCounts(c *gin.Context, projectID uuid.UUID) map[uuid.UUID]int64 {
+	counts, err := s.researchService.OpenCommentCounts(c.Request.Context(), projectID)
+	if err != nil {
+		s.logger.Error("Failed to count open comments", "projectID", projectID, "error", err)
+	}
+	return counts
+}
+
+func (s *Server) listChapterComments(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+
+	var resolved *bool
+	switch c.DefaultQuery("status", "all") {
+	case "open":
+		resolved = new(bool)
+	case "resolved":
+		resolved = new(bool)
+		*resolved = true
+	case "all":
+	default:
+		response.BadRequest(c, "status must be one of open, resolved, all")
+		return
+	}
+
+	threads, err := s.researchService.ListCommentThreads(c.Request.Context(), projectID, chapterID, authPayload.UserID, resolved)
+	if err != nil {
+		s.respondCommentError(c, "list comments", err)
+		return
+	}
+	response.Ok(c, threads, "Comments retrieved successfully")
+}
+
+func (s *Server) createCommentThread(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateCommentThreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	thread, err := s.researchService.CreateCommentThread(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondCommentError(c, "create comment", err)
+		return
+	}
+	response.Created(c, thread, "Comment created successfully")
+}
+
+func (s *Server) replyToCommentThread(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	threadID, ok := uuidParam(c, "thread_id")
+	if !ok {
+		return
+	}
+	var req apimodels.AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	comment, err := s.researchService.AddComment(c.Request.Context(), projectID, threadID, authPayload.UserID, req)
+	if err != nil {
+		s.respondCommentError(c, "add comment", err)
+		return
+	}
+	response.Created(c, comment, "Reply added successfully")
+}
+
+func (s *Server) resolveCommentThread(c *gin.Context) {
+	s.setCommentThreadResolved(c, true)
+}
+
+func (s *Server) reopenCommentThread(c *gin.Context) {
+	s.setCommentThreadResolved(c, false)
+}
+
+func (s *Server) setCommentThreadResolved(c *gin.Context, resolved bool) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	threadID, ok := uuidParam(c, "thread_id")
+	if !ok {
+		return
+	}
+	thread, err := s.researchService.SetCommentThreadResolved(c.Request.Context(), projectID, threadID, authPayload.UserID, resolved)
+	if err != nil {
+		s.respondCommentError(c, "update comment thread", err)
+		return
+	}
+	// Comments are not reloaded; clients already have them and only the state changed
+	response.Ok(c, apimodels.ToCommentThreadResponse(thread, nil), "Comment thread updated successfully")
+}
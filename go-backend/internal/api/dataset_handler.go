@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDatasetError maps dataset errors to responses
+func (s *Server) respondDatasetError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrDatasetNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidDataset):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Dataset request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) uploadProjectDataset(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	header, file, ok := s.formFile(c, "a CSV")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	dataset, err := s.researchService.UploadDataset(c.Request.Context(), projectID, authPayload.UserID, header.Filename, file)
+	if err != nil {
+		s.respondDatasetError(c, "upload dataset", err)
+		return
+	}
+	response.Created(c, apimodels.ToDatasetResponse(dataset), "Dataset uploaded successfully")
+}
+
+func (s *Server) listProjectDatasets(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	datasets, err := s.researchService.ListDatasets(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondDatasetError(c, "list datasets", err)
+		return
+	}
+	resp := make([]apimodels.DatasetResponse, len(datasets))
+	for i, d := range datasets {
+		resp[i] = apimodels.ToDatasetResponse(d)
+	}
+	response.Ok(c, resp, "Datasets retrieved successfully")
+}
+
+func (s *Server) getProjectDataset(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	datasetID, ok := uuidParam(c, "dataset_id")
+	if !ok {
+		return
+	}
+	dataset, err := s.researchService.GetDataset(c.Request.Context(), projectID, datasetID, authPayload.UserID)
+	if err != nil {
+		s.respondDatasetError(c, "get dataset", err)
+		return
+	}
+	response.Ok(c, apimodels.ToDatasetResponse(dataset), "Dataset retrieved successfully")
+}
+
+func (s *Server) deleteProjectDataset(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	datasetID, ok := uuidParam(c, "dataset_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteDataset(c.Request.Context(), projectID, datasetID, authPayload.UserID); err != nil {
+		s.respondDatasetError(c, "delete dataset", err)
+		return
+	}
+	response.NoContent(c)
+}
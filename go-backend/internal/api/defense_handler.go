@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDefenseError maps viva preparation errors to responses
+func (s *Server) respondDefenseError(c *gin.Context, action string, err error) {
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrNoThesisContent):
+		response.BadRequest(c, err.Error())
+	case errors.As(err, &quotaErr):
+		s.respondQuotaExceeded(c, quotaErr)
+	default:
+		s.logger.Error("Defense request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// generateDefenseQuestions prepares likely viva questions from the thesis; the body is optional
+func (s *Server) generateDefenseQuestions(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateDefenseQuestionsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	questions, err := s.researchService.GenerateDefenseQuestions(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondDefenseError(c, "generate defense questions", err)
+		return
+	}
+	response.Ok(c, questions)
+}
@@ -0,0 +1,20 @@
+// Package docs holds the OpenAPI description of the HTTP API.
+// openapi.json is generated from the route table and the request/response
+// structs in internal/models; regenerate it after changing either.
+package docs
+
+import _ "embed"
+
+//go:generate go run ./gen -out openapi.json
+
+// Spec is the generated OpenAPI 3 document
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Title and Version are written into the generated document's info block
+const (
+	Title    = "Research Service API"
+	Version  = "1.0.0"
+	BasePath = "/api/v1"
+)
@@ -0,0 +1,24 @@
+// Command gen writes the OpenAPI document for the HTTP API.
+// Run through `go generate ./internal/api/docs`.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/docs"
+)
+
+func main() {
+	out := flag.String("out", "openapi.json", "output file")
+	flag.Parse()
+
+	spec, err := docs.Build(docs.Title, docs.Version, docs.BasePath, docs.Operations)
+	if err != nil {
+		log.Fatalf("build spec: %v", err)
+	}
+	if err := os.WriteFile(*out, append(spec, '\n'), 0o644); err != nil {
+		log.Fatalf("write spec: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package docs
+
+import (
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/flags"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	"github.com/shawgichan/research-service/go-backend/internal/models"
+)
+
+// Operations is the annotated route table used to generate openapi.json.
+// Keep it in sync with Server.setupRoutes when adding or changing endpoints,
+// then run `go generate ./internal/api/docs`.
+var Operations = []Operation{
+	// Auth
+	{Method: http.MethodPost, Path: "/auth/register", Tag: "auth", Summary: "Register a new user", Status: http.StatusCreated, Request: models.RegisterUserRequest{}, Response: models.LoginUserResponse{}},
+	{Method: http.MethodPost, Path: "/auth/login", Tag: "auth", Summary: "Log in with email and password", Request: models.LoginUserRequest{}, Response: models.LoginUserResponse{}},
+	{Method: http.MethodPost, Path: "/auth/refresh-token", Tag: "auth", Summary: "Exchange a refresh token for a new access token", Request: models.RefreshTokenRequest{}, Response: models.LoginUserResponse{}},
+	{Method: http.MethodPost, Path: "/auth/logout", Tag: "auth", Summary: "Invalidate the session for a refresh token", Auth: true, Request: models.RefreshTokenRequest{}},
+	{Method: http.MethodPost, Path: "/auth/guest", Tag: "auth", Summary: "Start a trial as a guest with an expiring sandbox project (GUEST_MODE_ENABLED)", Status: http.StatusCreated, Request: models.CreateGuestRequest{}, Response: models.GuestSessionResponse{}},
+	{Method: http.MethodPost, Path: "/auth/guest/register", Tag: "auth", Summary: "Register the calling guest, keeping its projects", Auth: true, Request: models.RegisterUserRequest{}, Response: models.LoginUserResponse{}},
+
+	// Users
+	{Method: http.MethodGet, Path: "/flags", Tag: "flags", Summary: "Effective feature flags by name", Auth: true, Response: map[string]bool{}},
+	{Method: http.MethodGet, Path: "/admin/flags", Tag: "admin", Summary: "List feature flags with their source (requires ADMIN_API_TOKEN)", Auth: true, Response: flags.Flag{}, List: true},
+	{Method: http.MethodPut, Path: "/admin/flags/{name}", Tag: "admin", Summary: "Override a feature flag at runtime", Auth: true, Request: models.SetFeatureFlagRequest{}, Response: flags.Flag{}},
+	{Method: http.MethodDelete, Path: "/admin/flags/{name}", Tag: "admin", Summary: "Remove a feature flag override", Auth: true, Response: flags.Flag{}},
+	{Method: http.MethodGet, Path: "/admin/log-levels", Tag: "admin", Summary: "Log level per component, including the default", Auth: true, Response: map[string]string{}},
+	{Method: http.MethodPut, Path: "/admin/log-levels/{component}", Tag: "admin", Summary: "Set a component's log level (e.g. services.ai), or the default", Auth: true, Request: models.SetLogLevelRequest{}, Response: map[string]string{}},
+	{Method: http.MethodDelete, Path: "/admin/log-levels/{component}", Tag: "admin", Summary: "Remove a component's log level so it inherits again", Auth: true, Response: map[string]string{}},
+	{Method: http.MethodGet, Path: "/admin/jobs", Tag: "admin", Summary: "Newest background jobs, e.g. status=dead for those out of attempts", Auth: true, Response: jobs.Job{}, List: true,
+		Query: []Param{
+			{Name: "status", Type: "string", Description: "pending, running, completed or dead"},
+			{Name: "kind", Type: "string", Description: "Job kind, e.g. documents.generate"},
+			{Name: "limit", Type: "integer", Description: "Maximum number of jobs (1-500, default 50)"},
+		}},
+	{Method: http.MethodGet, Path: "/admin/jobs/{job_id}", Tag: "admin", Summary: "Get a background job with its last error", Auth: true, Response: jobs.Job{}},
+	{Method: http.MethodPost, Path: "/admin/jobs/{job_id}/requeue", Tag: "admin", Summary: "Run a job again from its first attempt; 409 while it is running", Auth: true, Response: jobs.Job{}},
+	{Method: http.MethodGet, Path: "/users/me", Tag: "users", Summary: "Get the authenticated user", Auth: true, Response: models.UserResponse{}},
+	{Method: http.MethodGet, Path: "/users/me/quota", Tag: "users", Summary: "Your plan's quotas and usage this billing period; actions past a quota answer 402, or 429 with Retry-After when no plan has more", Auth: true, Response: models.QuotaResponse{}},
+	{Method: http.MethodGet, Path: "/users/me/integrity-policy", Tag: "users", Summary: "The deployment's academic-integrity policy and whether you have acknowledged its current version; where acknowledgment is required, AI generation answers 428 until you do", Auth: true, Response: models.IntegrityPolicyResponse{}},
+	{Method: http.MethodGet, Path: "/users/me/generation-preferences", Tag: "users", Summary: "Your generation preferences (temperature, reading level, US or UK English, verbosity) and, with the defaults filled in, those generation uses", Auth: true, Response: models.GenerationPreferencesResponse{}},
+	{Method: http.MethodPut, Path: "/users/me/generation-preferences", Tag: "users", Summary: "Replace your generation preferences, applied to the chapters, sections, summaries, articles and interpretations you generate; fields left out take the defaults", Auth: true, Request: models.GenerationPreferences{}, Response: models.GenerationPreferencesResponse{}},
+	{Method: http.MethodPost, Path: "/users/me/integrity-policy/acknowledge", Tag: "users", Summary: "Acknowledge the current version of the academic-integrity policy", Auth: true, Response: models.IntegrityPolicyResponse{}},
+
+	// AI writing tools
+	{Method: http.MethodPost, Path: "/ai/interpret-analysis", Tag: "ai", Summary: "Interpret pasted SPSS, R or Stata output as an APA-style results paragraph with significance and effect sizes; counts as an AI generation", Auth: true, Request: models.InterpretAnalysisRequest{}, Response: models.AnalysisInterpretationResponse{}},
+	{Method: http.MethodGet, Path: "/papers/search", Tag: "papers", Summary: "Search Semantic Scholar for papers, with filters and paging", Auth: true, Response: models.SearchPapersResponse{},
+		Query: []Param{
+			{Name: "q", Type: "string", Required: true, Description: "Search terms"},
+			{Name: "limit", Type: "integer", Description: "Results per page (1-100, default 10)"},
+			{Name: "offset", Type: "integer", Description: "Results to skip; offset + limit may not exceed 1000"},
+			{Name: "year", Type: "string", Description: "A year or range: 2019, 2016-2020, 2010- or -2015"},
+			{Name: "venue", Type: "string", Description: "Only papers from this venue; repeat to match any of several"},
+			{Name: "min_citation_count", Type: "integer", Description: "Only papers cited at least this often"},
+			{Name: "open_access_only", Type: "boolean", Description: "Only papers with a freely available PDF"},
+			{Name: "project_id", Type: "string", Description: "Re-rank the page by semantic similarity to this project's title and description (needs embeddings)"},
+		}},
+
+	// Billing (only mounted when STRIPE_SECRET_KEY is set; Stripe posts events to /webhooks/stripe)
+	{Method: http.MethodGet, Path: "/billing/plans", Tag: "billing", Summary: "Plans with their limits", Auth: true, Response: models.PlanResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/billing/subscription", Tag: "billing", Summary: "Your plan, its limits and this month's usage", Auth: true, Response: models.SubscriptionResponse{}},
+	{Method: http.MethodPost, Path: "/billing/checkout", Tag: "billing", Summary: "Start a Stripe Checkout for a paid plan; redirect the user to the returned URL", Auth: true, Status: http.StatusCreated, Request: models.CheckoutRequest{}, Response: models.BillingSessionResponse{}},
+	{Method: http.MethodPost, Path: "/billing/portal", Tag: "billing", Summary: "Open the Stripe customer portal to change plans, update payment details or cancel", Auth: true, Status: http.StatusCreated, Response: models.BillingSessionResponse{}},
+
+	// Notifications
+	{Method: http.MethodGet, Path: "/users/me/notifications", Tag: "notifications", Summary: "Newest in-app notifications with the unread count", Auth: true, Response: models.NotificationListResponse{},
+		Query: []Param{
+			{Name: "unread", Type: "boolean", Description: "Set to true to return only unread notifications"},
+			{Name: "limit", Type: "integer", Description: "Maximum number of notifications (1-100, default 50)"},
+		}},
+	{Method: http.MethodPost, Path: "/users/me/notifications/{notification_id}/read", Tag: "notifications", Summary: "Mark a notification as read", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/users/me/notifications/read-all", Tag: "notifications", Summary: "Mark every notification as read", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/users/me/notification-preferences", Tag: "notifications", Summary: "In-app and email settings for every notification type", Auth: true, Response: models.NotificationPreferenceResponse{}, List: true},
+	{Method: http.MethodPut, Path: "/users/me/notification-preferences/{type}", Tag: "notifications", Summary: "Turn a notification type on or off per channel", Auth: true, Request: models.UpdateNotificationPreferenceRequest{}, Response: models.NotificationPreferenceResponse{}},
+
+	// Projects
+	{Method: http.MethodPost, Path: "/projects", Tag: "projects", Summary: "Create a research project", Auth: true, Status: http.StatusCreated, Request: models.CreateProjectRequest{}, Response: models.ProjectResponse{}},
+	{Method: http.MethodGet, Path: "/projects", Tag: "projects", Summary: "List projects the user owns, advises or can access through an organization or sharing", Auth: true, Response: models.ProjectResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/import/docx", Tag: "projects", Summary: "Create a project from a thesis in a DOCX: chapters are split at its top-level headings and its bibliography becomes references", Auth: true, Status: http.StatusCreated, FileField: "file", Response: models.ImportThesisResponse{},
+		FormFields: []Param{
+			{Name: "title", Type: "string", Description: "Project title; defaults to the document's title, or its file name"},
+			{Name: "specialization", Type: "string", Description: "Defaults to General"},
+			{Name: "university", Type: "string"},
+			{Name: "language", Type: "string", Description: "Language chapters are generated in, en by default"},
+		}},
+	{Method: http.MethodPost, Path: "/projects/import/latex", Tag: "projects", Summary: "Create a project from a ZIP of LaTeX sources: chapters are split at \\chapter, or \\section when there is none, \\input files are followed and .bib entries become references", Auth: true, Status: http.StatusCreated, FileField: "file", Response: models.ImportThesisResponse{},
+		FormFields: []Param{
+			{Name: "title", Type: "string", Description: "Project title; defaults to \\title, or the archive's file name"},
+			{Name: "specialization", Type: "string", Description: "Defaults to General"},
+			{Name: "university", Type: "string"},
+			{Name: "language", Type: "string", Description: "Language chapters are generated in, en by default"},
+		}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}", Tag: "projects", Summary: "Get a project with its chapters", Auth: true, Response: models.ProjectResponse{},
+		Query: []Param{
+			{Name: "include_content", Type: "boolean", Description: "Set to false to omit chapter content"},
+			{Name: "include_html", Type: "boolean", Description: "Set to true to add each chapter's content rendered as HTML"},
+		}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}", Tag: "projects", Summary: "Update a project", Auth: true, Request: models.UpdateProjectRequest{}, Response: models.ProjectResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}", Tag: "projects", Summary: "Delete a project", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/collaborators", Tag: "projects", Summary: "List users the project is shared with", Auth: true, Response: models.CollaboratorResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/collaborators", Tag: "projects", Summary: "Share the project with a registered user, or change their role (owner only)", Auth: true, Status: http.StatusCreated, Request: models.AddCollaboratorRequest{}, Response: models.CollaboratorResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/collaborators/{user_id}", Tag: "projects", Summary: "Stop sharing with a user, or leave with your own user ID", Auth: true, Status: http.StatusNoContent},
+
+	// Webhooks
+	{Method: http.MethodGet, Path: "/projects/{project_id}/webhooks", Tag: "webhooks", Summary: "List the project's webhooks (owner only)", Auth: true, Response: models.WebhookResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/webhooks", Tag: "webhooks", Summary: "Register a URL for chapter.generated, document.completed, document.failed, reference.added, reference.deleted or review.decided; the response carries the signing secret once", Auth: true, Status: http.StatusCreated, Request: models.CreateWebhookRequest{}, Response: models.WebhookResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/webhooks/{webhook_id}", Tag: "webhooks", Summary: "Change a webhook's URL, events or active state", Auth: true, Request: models.UpdateWebhookRequest{}, Response: models.WebhookResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/webhooks/{webhook_id}", Tag: "webhooks", Summary: "Delete a webhook and its delivery log", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/webhooks/{webhook_id}/deliveries", Tag: "webhooks", Summary: "The webhook's 50 most recent deliveries with their outcome, newest first", Auth: true, Response: models.WebhookDeliveryResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", Tag: "webhooks", Summary: "Send a delivery again right away", Auth: true, Response: models.WebhookDeliveryResponse{}},
+
+	// Chapters
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters", Tag: "chapters", Summary: "Create a chapter", Auth: true, Status: http.StatusCreated, Request: models.CreateChapterRequest{}, Response: models.ChapterResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters", Tag: "chapters", Summary: "List chapters of a project", Auth: true, Response: models.ChapterResponse{}, List: true,
+		Query: []Param{
+			{Name: "fields", Type: "string", Description: "Comma-separated subset of chapter fields to return"},
+			{Name: "include_content", Type: "boolean", Description: "Set to false to omit chapter content"},
+			{Name: "include_html", Type: "boolean", Description: "Set to true to add each chapter's content rendered as HTML"},
+		}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/bulk", Tag: "chapters", Summary: "Create or update several chapters in one transaction", Auth: true, Request: models.BulkChaptersRequest{}, Response: models.ChapterResponse{}, List: true},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/chapters/reorder", Tag: "chapters", Summary: "Set the order of the project's chapters, which generated documents follow", Auth: true, Request: models.ReorderChaptersRequest{}, Response: models.ChapterResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapter-types", Tag: "chapters", Summary: "List the chapter types the project's chapters can have", Auth: true, Response: models.ChapterTypeResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapter-types", Tag: "chapters", Summary: "Add a chapter type for this project only, such as a theoretical framework", Auth: true, Status: http.StatusCreated, Request: models.CreateChapterTypeRequest{}, Response: models.ChapterTypeResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/chapter-types/{key}", Tag: "chapters", Summary: "Remove a type added for the project, once no chapter has it", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}", Tag: "chapters", Summary: "Get a chapter (supports If-None-Match)", Auth: true, Response: models.ChapterResponse{},
+		Query: []Param{{Name: "include_html", Type: "boolean", Description: "Set to true to add the content rendered as HTML"}}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/chapters/{chapter_id}", Tag: "chapters", Summary: "Update a chapter (send version or If-Match; 409 with the latest chapter if stale)", Auth: true, Request: models.UpdateChapterRequest{}, Response: models.ChapterResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/chapters/{chapter_id}", Tag: "chapters", Summary: "Move a chapter to the trash", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/sections", Tag: "chapters", Summary: "List a chapter's sections in order", Auth: true, Response: models.SectionResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/sections", Tag: "chapters", Summary: "Add a section after the chapter's others; documents number it under the chapter by level", Auth: true, Status: http.StatusCreated, Request: models.CreateSectionRequest{}, Response: models.SectionResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/chapters/{chapter_id}/sections/reorder", Tag: "chapters", Summary: "Set the order of a chapter's sections", Auth: true, Request: models.ReorderSectionsRequest{}, Response: models.SectionResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/sections/{section_id}", Tag: "chapters", Summary: "Get a section", Auth: true, Response: models.SectionResponse{},
+		Query: []Param{{Name: "include_html", Type: "boolean", Description: "Set to true to add the content rendered as HTML"}}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/chapters/{chapter_id}/sections/{section_id}", Tag: "chapters", Summary: "Update a section", Auth: true, Request: models.UpdateSectionRequest{}, Response: models.SectionResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/chapters/{chapter_id}/sections/{section_id}", Tag: "chapters", Summary: "Delete a section", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/sections/{section_id}/generate", Tag: "chapters", Summary: "Regenerate one section with AI, leaving the rest of the chapter as it is; the body is optional. When the AI call fails the section is retried in the background and 202 is returned; the user is notified of the outcome", Auth: true, Request: models.GenerateSectionRequest{}, Response: models.SectionResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/draft", Tag: "chapters", Summary: "Get your autosaved draft of a chapter", Auth: true, Response: models.ChapterDraftResponse{}},
+	{Method: http.MethodPatch, Path: "/projects/{project_id}/chapters/{chapter_id}/draft", Tag: "chapters", Summary: "Autosave edits to your draft without touching the chapter; 409 with the saved draft if someone else saved the chapter since base_version", Auth: true, Request: models.UpdateChapterDraftRequest{}, Response: models.ChapterDraftResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/chapters/{chapter_id}/draft", Tag: "chapters", Summary: "Discard your draft of a chapter; saving the chapter discards it too", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/versions", Tag: "chapters", Summary: "List the saved versions of a chapter, newest first", Auth: true, Response: models.ChapterVersionResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/versions/{a}/diff/{b}", Tag: "chapters", Summary: "What changed between two versions of a chapter, as runs of kept, inserted and deleted text", Auth: true, Response: models.ChapterDiffResponse{},
+		Query: []Param{{Name: "granularity", Type: "string", Description: "line (default) or word"}}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/provenance", Tag: "chapters", Summary: "Which parts of a chapter AI generation wrote and which its editors wrote, for integrity disclosures", Auth: true, Response: models.ChapterProvenanceResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/ai-detection", Tag: "chapters", Summary: "Estimate how likely AI detectors are to flag a chapter, highlighting the paragraphs to revise; the body is optional", Auth: true, Request: models.AIDetectionRequest{}, Response: models.AIDetectionResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/metrics", Tag: "chapters", Summary: "Readability (Flesch-Kincaid), sentence-length distribution, passive-voice ratio and hedging counts of a chapter, recomputed on save", Auth: true, Response: models.ChapterMetricsResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/evaluations", Tag: "chapters", Summary: "Have the AI score the chapter against a rubric, with per-criterion scores and improvement suggestions; the body is optional and the default rubric covers argument clarity, citation density, structure and originality of synthesis", Auth: true, Status: http.StatusCreated, Request: models.EvaluateChapterRequest{}, Response: models.ChapterEvaluationResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/evaluations", Tag: "chapters", Summary: "Evaluations of the chapter, newest first", Auth: true, Response: models.ChapterEvaluationResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/evaluations/{evaluation_id}", Tag: "chapters", Summary: "One evaluation of the chapter", Auth: true, Response: models.ChapterEvaluationResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/generations", Tag: "chapters", Summary: "The AI calls made for the chapter, newest first: prompt and its hash, model and parameters, truncated response, tokens, latency and outcome", Auth: true, Response: models.GenerationResponse{}, List: true,
+		Query: []Param{{Name: "limit", Type: "integer", Description: "Calls to return (1-100, default 50)"}}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/generate-content", Tag: "chapters", Summary: "Generate chapter content with AI. When the AI call fails the chapter is retried in the background and 202 is returned; the user is notified of the outcome", Auth: true, Response: models.ChapterResponse{},
+		Query: []Param{
+			{Name: "reference_tag", Type: "string", Description: "Literature review only: review just the references with this tag (methodology, seminal, recent or contradictory)"},
+			{Name: "regenerate", Type: "boolean", Description: "Write a new draft instead of reusing the reply to an identical request made moments ago; false by default"},
+		}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/generate-all", Tag: "chapters", Summary: "Queue the generation of every chapter in one background run, one after another in dependency order (literature review, introduction, methodology, results, the other chapters, then the executive summary). Approved chapters and those in review are skipped; a chapter that fails does not stop the run. Poll the run for each chapter's progress; each chapter's generation events are also pushed over /ws/projects/{project_id}. One run at a time per project; refused for institutional projects where the academic-integrity policy blocks batch generation", Auth: true, Status: http.StatusAccepted, Response: models.GenerationBatchResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/generate-all/{batch_id}", Tag: "chapters", Summary: "Get a generate-all run with the status of each of its chapters", Auth: true, Response: models.GenerationBatchResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/progress", Tag: "chapters", Summary: "Words written against chapter word targets, with chapters per status", Auth: true, Response: models.ProjectProgressResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/generate-defense-questions", Tag: "defense", Summary: "Read the thesis and prepare the questions examiners are likely to ask at the viva, grouped by chapter, with suggested answer outlines; the body is optional", Auth: true, Request: models.GenerateDefenseQuestionsRequest{}, Response: models.DefenseQuestionsResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/generate-executive-summary", Tag: "chapters", Summary: "Write a 2-3 page executive summary of the project for its supervisors or a funding body, saved as the project's executive_summary chapter and left out of the thesis document; the body is optional", Auth: true, Request: models.GenerateExecutiveSummaryRequest{}, Response: models.ChapterResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/timeline/generate", Tag: "timeline", Summary: "Generate a research timeline (tasks, durations, dependencies) from the chapters and milestones, replacing any earlier one", Auth: true, Request: models.GenerateTimelineRequest{}, Response: models.TimelineResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/timeline", Tag: "timeline", Summary: "Get the project's research timeline", Auth: true, Response: models.TimelineResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/timeline", Tag: "timeline", Summary: "Set whether generated documents include the timeline as a Gantt chart", Auth: true, Request: models.UpdateTimelineRequest{}, Response: models.TimelineResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/timeline", Tag: "timeline", Summary: "Delete the project's research timeline", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/methodology", Tag: "chapters", Summary: "Get the project's methodology questionnaire answers", Auth: true, Response: models.MethodologyResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/methodology", Tag: "chapters", Summary: "Answer the methodology questionnaire (design, population, instruments, analysis); methodology generation writes the chapter from it", Auth: true, Request: models.MethodologyAnswers{}, Response: models.MethodologyResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/questionnaires", Tag: "questionnaires", Summary: "List the project's questionnaires", Auth: true, Response: models.QuestionnaireResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/questionnaires", Tag: "questionnaires", Summary: "Create a questionnaire", Auth: true, Status: http.StatusCreated, Request: models.CreateQuestionnaireRequest{}, Response: models.QuestionnaireResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/questionnaires/{questionnaire_id}", Tag: "questionnaires", Summary: "Get a questionnaire with its sections and items", Auth: true, Response: models.QuestionnaireResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/questionnaires/{questionnaire_id}", Tag: "questionnaires", Summary: "Update a questionnaire; sections, when sent, replace the existing ones", Auth: true, Request: models.UpdateQuestionnaireRequest{}, Response: models.QuestionnaireResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/questionnaires/{questionnaire_id}", Tag: "questionnaires", Summary: "Delete a questionnaire", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/questionnaires/{questionnaire_id}/generate-items", Tag: "questionnaires", Summary: "Generate items for the research questions with AI, appended as a new section", Auth: true, Request: models.GenerateQuestionnaireItemsRequest{}, Response: models.QuestionnaireResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/datasets", Tag: "datasets", Summary: "Upload a CSV of study results (comma, semicolon or tab separated, with a header row); descriptive statistics are computed per column and the file is not kept", Auth: true, Status: http.StatusCreated, FileField: "file", Response: models.DatasetResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/datasets", Tag: "datasets", Summary: "List the project's datasets; generating the results chapter narrates them and adds their tables", Auth: true, Response: models.DatasetResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/datasets/{dataset_id}", Tag: "datasets", Summary: "Get a dataset with the statistics of its columns", Auth: true, Response: models.DatasetResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/datasets/{dataset_id}", Tag: "datasets", Summary: "Delete a dataset and the results tables generated from it", Auth: true, Status: http.StatusNoContent},
+
+	// Reviews
+	{Method: http.MethodPut, Path: "/projects/{project_id}/advisor", Tag: "reviews", Summary: "Assign a registered user as the project's advisor (owner only)", Auth: true, Request: models.SetAdvisorRequest{}, Response: models.ProjectResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/advisor", Tag: "reviews", Summary: "Unassign the project's advisor", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/submit", Tag: "reviews", Summary: "Submit a chapter to the advisor; its status is in_review until they decide", Auth: true, Status: http.StatusCreated, Request: models.SubmitChapterRequest{}, Response: models.ReviewResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/review", Tag: "reviews", Summary: "Approve the pending review or request changes (advisor only)", Auth: true, Request: models.ReviewDecisionRequest{}, Response: models.ReviewResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/reviews", Tag: "reviews", Summary: "Review history of a chapter, newest first", Auth: true, Response: models.ReviewResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/reviews/queue", Tag: "reviews", Summary: "Pending reviews of the projects you advise, oldest first", Auth: true, Response: models.ReviewQueueItemResponse{}, List: true},
+
+	// Comments
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/comments", Tag: "comments", Summary: "List comment threads of a chapter with their comments", Auth: true, Response: models.CommentThreadResponse{}, List: true,
+		Query: []Param{{Name: "status", Type: "string", Description: "open, resolved or all (default)"}}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/comments", Tag: "comments", Summary: "Start a thread anchored to a character range or section heading (comment role or above)", Auth: true, Status: http.StatusCreated, Request: models.CreateCommentThreadRequest{}, Response: models.CommentThreadResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/comments/{thread_id}/replies", Tag: "comments", Summary: "Reply to a comment thread", Auth: true, Status: http.StatusCreated, Request: models.AddCommentRequest{}, Response: models.CommentResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/comments/{thread_id}/resolve", Tag: "comments", Summary: "Resolve a comment thread", Auth: true, Response: models.CommentThreadResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/comments/{thread_id}/reopen", Tag: "comments", Summary: "Reopen a resolved comment thread", Auth: true, Response: models.CommentThreadResponse{}},
+
+	// Figures and tables
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/figures", Tag: "figures", Summary: "List a chapter's figures and tables in document order", Auth: true, Response: models.FigureResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/figures", Tag: "figures", Summary: "Add a figure from a PNG or JPEG image", Auth: true, Status: http.StatusCreated, FileField: "file", Response: models.FigureResponse{},
+		FormFields: []Param{
+			{Name: "caption", Type: "string", Required: true, Description: "Caption shown under the figure"},
+			{Name: "position", Type: "integer", Description: "Order among the chapter's figures and tables (default 0)"},
+		}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/chapters/{chapter_id}/tables", Tag: "figures", Summary: "Add a table", Auth: true, Status: http.StatusCreated, Request: models.CreateTableRequest{}, Response: models.FigureResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/chapters/{chapter_id}/figures/{figure_id}", Tag: "figures", Summary: "Update a figure or table's caption, position or table rows", Auth: true, Request: models.UpdateFigureRequest{}, Response: models.FigureResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/chapters/{chapter_id}/figures/{figure_id}", Tag: "figures", Summary: "Delete a figure or table", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/figures/{figure_id}/image", Tag: "figures", Summary: "Download a figure's image", Auth: true, RawResponse: true},
+
+	// References
+	{Method: http.MethodPost, Path: "/projects/{project_id}/references", Tag: "references", Summary: "Add a reference; 200 when it duplicates one the project has by DOI, or title and year, and was merged into it", Auth: true, Status: http.StatusCreated, Request: models.CreateReferenceRequest{}, Response: models.ReferenceResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references", Tag: "references", Summary: "List references of a project with their tags", Auth: true, Response: models.ReferenceResponse{}, List: true,
+		Query: []Param{{Name: "tag", Type: "string", Description: "Only references with this tag; repeat to match any of several"}}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/recommended-papers", Tag: "references", Summary: "Semantic Scholar papers like the project's references that it does not have yet; 409 when no reference has a DOI or Semantic Scholar ID", Auth: true, Response: models.PaperResponse{}, List: true,
+		Query: []Param{{Name: "limit", Type: "integer", Description: "Papers to return (1-100, default 20)"}}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/references/import-papers", Tag: "references", Summary: "Add Semantic Scholar papers as references, skipping those already imported and merging those matching other references", Auth: true, Status: http.StatusCreated, Request: models.ImportPapersRequest{}, Response: models.ImportPapersResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/export", Tag: "references", Summary: "Download references for a reference manager as CSL-JSON or Zotero RDF; tags become keywords", Auth: true, RawResponse: true,
+		Query: []Param{
+			{Name: "format", Type: "string", Description: "csl-json (default) or zotero-rdf"},
+			{Name: "tag", Type: "string", Description: "Only references with this tag; repeat to match any of several"},
+		}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/similar", Tag: "references", Summary: "Find references semantically related to a passage", Auth: true, Response: models.SimilarReferenceResponse{}, List: true,
+		Query: []Param{
+			{Name: "text", Type: "string", Required: true, Description: "Passage being written (up to 8000 characters)"},
+			{Name: "limit", Type: "integer", Description: "Maximum number of results (1-50, default 10)"},
+		}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/references/{reference_id}", Tag: "references", Summary: "Move a reference to the trash", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/{reference_id}/pdf", Tag: "references", Summary: "Download the open access PDF of a paper imported from Semantic Scholar; 409 while it is still downloading", Auth: true, RawResponse: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/{reference_id}/cited-papers", Tag: "references", Summary: "Papers a saved reference cites, from Semantic Scholar; import them by paper_id", Auth: true, Response: models.CitationGraphResponse{}, Query: citationGraphParams},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/{reference_id}/citing-papers", Tag: "references", Summary: "Papers citing a saved reference, from Semantic Scholar; import them by paper_id", Auth: true, Response: models.CitationGraphResponse{}, Query: citationGraphParams},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/references/{reference_id}/notes", Tag: "references", Summary: "Get your notes on a reference and the passages highlighted in it", Auth: true, Response: models.ReferenceNotesResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/references/{reference_id}/notes", Tag: "references", Summary: "Replace the notes on a reference; notes and highlights guide literature review generation", Auth: true, Request: models.UpdateReferenceNotesRequest{}, Response: models.ReferenceNotesResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/references/{reference_id}/tags", Tag: "references", Summary: "Replace the tags on a reference (methodology, seminal, recent, contradictory)", Auth: true, Request: models.UpdateReferenceTagsRequest{}, Response: models.ReferenceResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/references/{reference_id}/highlights", Tag: "references", Summary: "Highlight a passage of a reference", Auth: true, Status: http.StatusCreated, Request: models.CreateReferenceHighlightRequest{}, Response: models.ReferenceHighlightResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/references/{reference_id}/highlights/{highlight_id}", Tag: "references", Summary: "Delete a highlight", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/chapters/{chapter_id}/citations", Tag: "references", Summary: "List the (Author, Year) citations in a chapter with the references they resolve to", Auth: true, Response: models.CitationResponse{}, List: true},
+
+	// Uploads
+	{Method: http.MethodPost, Path: "/projects/{project_id}/uploads", Tag: "uploads", Summary: "Upload a source PDF (up to UPLOAD_MAX_SIZE_MB); its text is extracted in the background and used when generating the literature review", Auth: true, Status: http.StatusAccepted, FileField: "file", Response: models.UploadResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/uploads", Tag: "uploads", Summary: "List the project's uploads, newest first", Auth: true, Response: models.UploadResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/uploads/{upload_id}", Tag: "uploads", Summary: "Get an upload with its extracted text", Auth: true, Response: models.UploadResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/uploads/{upload_id}", Tag: "uploads", Summary: "Delete an upload and its file", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/upload-sessions", Tag: "uploads", Summary: "Start a resumable upload of a source PDF (up to UPLOAD_RESUMABLE_MAX_SIZE_MB), sent in chunks; Location names the session", Auth: true, Status: http.StatusCreated, Request: models.CreateUploadSessionRequest{}, Response: models.UploadSessionResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/upload-sessions/{session_id}", Tag: "uploads", Summary: "Get an upload session; offset (also in Upload-Offset) is where to resume, and upload_id the upload once completed", Auth: true, Response: models.UploadSessionResponse{}},
+	{Method: http.MethodPatch, Path: "/projects/{project_id}/upload-sessions/{session_id}", Tag: "uploads", Summary: "Send the next chunk as the raw request body, starting at the Upload-Offset header, optionally with Upload-Checksum: sha256 <base64>; 409 when the offset is stale, 202 once the last chunk queues assembly", Auth: true, Response: models.UploadSessionResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/upload-sessions/{session_id}", Tag: "uploads", Summary: "Abandon an upload session and its chunks", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/appendices", Tag: "appendices", Summary: "List the project's appendices in lettering order", Auth: true, Response: models.AppendixResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/appendices", Tag: "appendices", Summary: "Add an appendix", Auth: true, Status: http.StatusCreated, Request: models.CreateAppendixRequest{}, Response: models.AppendixResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/appendices/{appendix_id}", Tag: "appendices", Summary: "Update an appendix's title, content or position", Auth: true, Request: models.UpdateAppendixRequest{}, Response: models.AppendixResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/appendices/{appendix_id}", Tag: "appendices", Summary: "Delete an appendix and its file", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/appendices/{appendix_id}/file", Tag: "appendices", Summary: "Attach a PNG or JPEG image to an appendix, replacing any earlier one", Auth: true, FileField: "file", Response: models.AppendixResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/appendices/{appendix_id}/file", Tag: "appendices", Summary: "Download an appendix's image", Auth: true, RawResponse: true},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/guideline", Tag: "formatting", Summary: "Upload the faculty's formatting guideline (PDF or DOCX), replacing any earlier one; its rules are extracted in the background and become the project's formatting options", Auth: true, Status: http.StatusAccepted, FileField: "file", Response: models.GuidelineResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/guideline", Tag: "formatting", Summary: "Get the project's guideline with its extraction status and rules", Auth: true, Response: models.GuidelineResponse{}},
+	{Method: http.MethodDelete, Path: "/projects/{project_id}/guideline", Tag: "formatting", Summary: "Delete the guideline; formatting options already taken from it are kept", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/formatting", Tag: "formatting", Summary: "Get the formatting options the project's documents are generated with, defaults included", Auth: true, Response: models.FormattingOptions{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/formatting", Tag: "formatting", Summary: "Replace the project's formatting options (font, size, spacing, margins, chapter headings, citation style...), as a guideline upload does; fields left out take the defaults, and every later export uses them", Auth: true, Request: models.FormattingOptions{}, Response: models.FormattingOptions{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/generation-preferences", Tag: "projects", Summary: "Get the project's generation preferences and those your generations in it use: the project's over your own, then the defaults", Auth: true, Response: models.GenerationPreferencesResponse{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/generation-preferences", Tag: "projects", Summary: "Replace the project's generation preferences, which apply over each member's own; fields left out fall back to them. Requires the edit role", Auth: true, Request: models.GenerationPreferences{}, Response: models.GenerationPreferencesResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/front-matter", Tag: "formatting", Summary: "Get the project's front-matter text: degree, supervisor, declaration, dedication and acknowledgments", Auth: true, Response: models.FrontMatter{}},
+	{Method: http.MethodPut, Path: "/projects/{project_id}/front-matter", Tag: "formatting", Summary: "Replace the project's front-matter text; the front_matter formatting option chooses which pages the document includes, and the student's name comes from the owner's profile", Auth: true, Request: models.FrontMatter{}, Response: models.FrontMatter{}},
+
+	// Documents
+	{Method: http.MethodGet, Path: "/projects/{project_id}/trash", Tag: "trash", Summary: "List deleted chapters and references", Auth: true, Response: models.TrashResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/trash/chapters/{chapter_id}/restore", Tag: "trash", Summary: "Restore a deleted chapter", Auth: true, Response: models.ChapterResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/trash/references/{reference_id}/restore", Tag: "trash", Summary: "Restore a deleted reference", Auth: true, Response: models.ReferenceResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/search", Tag: "search", Summary: "Full-text search across chapters and references", Auth: true, Response: models.SearchResultResponse{}, List: true,
+		Query: []Param{
+			{Name: "q", Type: "string", Required: true, Description: "Search terms; supports \"quoted phrases\", -exclusions and or"},
+			{Name: "limit", Type: "integer", Description: "Maximum number of results (1-50, default 20)"},
+		}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/documents/generate", Tag: "documents", Summary: "Queue generation of the thesis document; it is ready when its status is completed (progress is pushed over /ws/projects/{project_id}). Requires the edit role", Auth: true, Status: http.StatusAccepted, Response: models.GeneratedDocumentResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/documents/presentation", Tag: "documents", Summary: "Queue a PPTX defense presentation (problem, methods, findings, conclusions) written by the AI from the chapters; download it like the thesis document once completed. Requires the edit role", Auth: true, Status: http.StatusAccepted, Response: models.GeneratedDocumentResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/documents/poster", Tag: "documents", Summary: "Queue an A0 or A1 academic poster (abstract, key sections, figures, conclusions) as a one-slide PPTX in one of the layout templates; the body is optional. Requires the edit role", Auth: true, Status: http.StatusAccepted, Request: models.GeneratePosterRequest{}, Response: models.GeneratedDocumentResponse{}},
+	{Method: http.MethodPost, Path: "/projects/{project_id}/documents/article", Tag: "documents", Summary: "Queue a journal article condensed from the thesis: IMRaD sections written one by one from the chapters they cover, an abstract, and the references cited, as DOCX; the body is optional. Requires the edit role", Auth: true, Status: http.StatusAccepted, Request: models.GenerateArticleRequest{}, Response: models.GeneratedDocumentResponse{}},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/documents/{document_id}/download", Tag: "documents", Summary: "Download a generated document", Auth: true, RawResponse: true},
+	{Method: http.MethodGet, Path: "/projects/{project_id}/download-all", Tag: "documents", Summary: "Download the project as a ZIP archive: the latest generated document, chapters as Markdown, references as CSL-JSON and the uploaded source PDFs", Auth: true, RawResponse: true},
+
+	// Organizations
+	{Method: http.MethodPost, Path: "/organizations", Tag: "organizations", Summary: "Create an organization; you become its owner", Auth: true, Status: http.StatusCreated, Request: models.CreateOrganizationRequest{}, Response: models.OrganizationResponse{}},
+	{Method: http.MethodGet, Path: "/organizations", Tag: "organizations", Summary: "List organizations you belong to", Auth: true, Response: models.OrganizationResponse{}, List: true},
+	{Method: http.MethodGet, Path: "/organizations/{organization_id}", Tag: "organizations", Summary: "Get an organization", Auth: true, Response: models.OrganizationResponse{}},
+	{Method: http.MethodGet, Path: "/organizations/{organization_id}/members", Tag: "organizations", Summary: "List members", Auth: true, Response: models.OrganizationMemberResponse{}, List: true},
+	{Method: http.MethodDelete, Path: "/organizations/{organization_id}/members/{user_id}", Tag: "organizations", Summary: "Remove a member, or leave with your own user ID", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/organizations/{organization_id}/projects", Tag: "organizations", Summary: "List projects shared with the organization", Auth: true, Response: models.ProjectResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/organizations/{organization_id}/invitations", Tag: "organizations", Summary: "Invite someone by email; the invitation token is emailed to them (owners and admins)", Auth: true, Status: http.StatusCreated, Request: models.InviteMemberRequest{}, Response: models.InvitationResponse{}},
+	{Method: http.MethodGet, Path: "/organizations/{organization_id}/invitations", Tag: "organizations", Summary: "List pending invitations (owners and admins)", Auth: true, Response: models.InvitationResponse{}, List: true},
+	{Method: http.MethodDelete, Path: "/organizations/{organization_id}/invitations/{invitation_id}", Tag: "organizations", Summary: "Revoke a pending invitation", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/organizations/{organization_id}/chapter-types", Tag: "organizations", Summary: "List the built-in chapter types and the organization's own", Auth: true, Response: models.ChapterTypeResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/organizations/{organization_id}/chapter-types", Tag: "organizations", Summary: "Add a chapter type the organization's projects can use (owners and admins)", Auth: true, Status: http.StatusCreated, Request: models.CreateChapterTypeRequest{}, Response: models.ChapterTypeResponse{}},
+	{Method: http.MethodPut, Path: "/organizations/{organization_id}/chapter-types/{key}", Tag: "organizations", Summary: "Edit one of the organization's chapter types (owners and admins)", Auth: true, Request: models.UpdateChapterTypeRequest{}, Response: models.ChapterTypeResponse{}},
+	{Method: http.MethodDelete, Path: "/organizations/{organization_id}/chapter-types/{key}", Tag: "organizations", Summary: "Remove a chapter type no chapter has any more (owners and admins)", Auth: true, Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/invitations", Tag: "organizations", Summary: "List invitations addressed to you", Auth: true, Response: models.InvitationResponse{}, List: true},
+	{Method: http.MethodPost, Path: "/invitations/{invitation_id}/accept", Tag: "organizations", Summary: "Accept an invitation with the token emailed to the invitee and join the organization", Auth: true, Request: models.AcceptInvitationRequest{}, Response: models.OrganizationResponse{}},
+	{Method: http.MethodDelete, Path: "/invitations/{invitation_id}", Tag: "organizations", Summary: "Decline an invitation", Auth: true, Status: http.StatusNoContent},
+}
+
+// citationGraphParams page the citation graph endpoints
+var citationGraphParams = []Param{
+	{Name: "limit", Type: "integer", Description: "Papers per page (1-1000, default 20)"},
+	{Name: "offset", Type: "integer", Description: "Papers to skip"},
+}
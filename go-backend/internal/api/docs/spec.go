@@ -0,0 +1,368 @@
+package docs
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation describes one HTTP endpoint for the generated OpenAPI document.
+// Request and Response hold zero values of the models used by the handler;
+// their schemas are derived from the json and binding struct tags.
+type Operation struct {
+	Method      string
+	Path        string // OpenAPI style, e.g. /projects/{project_id}
+	Tag         string
+	Summary     string
+	Auth        bool
+	Status      int // Success status code, defaults to 200
+	Query       []Param
+	Request     any
+	FileField   string  // Request is multipart/form-data carrying a file in this field
+	FormFields  []Param // Other fields of a multipart request
+	Response    any
+	List        bool // Response is an array of Response
+	RawResponse bool // Response is returned as-is instead of inside the success envelope
+}
+
+// Param is a query string or form parameter
+type Param struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type document struct {
+	OpenAPI    string                    `json:"openapi"`
+	Info       map[string]string         `json:"info"`
+	Servers    []map[string]string       `json:"servers"`
+	Paths      map[string]map[string]any `json:"paths"`
+	Components map[string]map[string]any `json:"components"`
+}
+
+type builder struct {
+	schemas map[string]any
+}
+
+// Build renders the OpenAPI 3 document for the given operations
+func Build(title, version, basePath string, ops []Operation) ([]byte, error) {
+	b := &builder{schemas: map[string]any{}}
+	b.schemas["ErrorResponse"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"error":   map[string]any{"type": "string"},
+			"details": map[string]any{"type": "array", "items": map[string]any{}},
+		},
+		"required": []string{"error"},
+	}
+
+	paths := map[string]map[string]any{}
+	for _, op := range ops {
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = b.operation(op)
+	}
+
+	doc := document{
+		OpenAPI: "3.0.3",
+		Info:    map[string]string{"title": title, "version": version},
+		Servers: []map[string]string{{"url": basePath}},
+		Paths:   paths,
+		Components: map[string]map[string]any{
+			"schemas": b.schemas,
+			"securitySchemes": {
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "PASETO"},
+			},
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (b *builder) operation(op Operation) map[string]any {
+	out := map[string]any{
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+		"operationId": operationID(op),
+	}
+
+	var params []any
+	for _, name := range pathParams(op.Path) {
+		schema := map[string]any{"type": "string"}
+		if strings.HasSuffix(name, "_id") {
+			schema["format"] = "uuid"
+		}
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": schema,
+		})
+	}
+	for _, q := range op.Query {
+		params = append(params, map[string]any{
+			"name": q.Name, "in": "query", "description": q.Description, "required": q.Required,
+			"schema": map[string]any{"type": q.Type},
+		})
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	if op.Request != nil {
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": b.schemaFor(reflect.TypeOf(op.Request))},
+			},
+		}
+	}
+	if op.FileField != "" {
+		properties := map[string]any{op.FileField: map[string]any{"type": "string", "format": "binary"}}
+		required := []string{op.FileField}
+		for _, f := range op.FormFields {
+			properties[f.Name] = map[string]any{"type": f.Type, "description": f.Description}
+			if f.Required {
+				required = append(required, f.Name)
+			}
+		}
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"multipart/form-data": map[string]any{"schema": map[string]any{
+					"type":       "object",
+					"properties": properties,
+					"required":   required,
+				}},
+			},
+		}
+	}
+	if op.Auth {
+		out["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	if status != http.StatusNoContent {
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": b.responseSchema(op)},
+		}
+	}
+	errRef := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": ref("ErrorResponse")},
+		},
+	}
+	out["responses"] = map[string]any{
+		strconv.Itoa(status): success,
+		"default":            errRef,
+	}
+	return out
+}
+
+func (b *builder) responseSchema(op Operation) map[string]any {
+	var data map[string]any
+	if op.Response != nil {
+		data = b.schemaFor(reflect.TypeOf(op.Response))
+		if op.List {
+			data = map[string]any{"type": "array", "items": data}
+		}
+	}
+	if op.RawResponse {
+		if data == nil {
+			return map[string]any{"type": "object"}
+		}
+		return data
+	}
+	props := map[string]any{
+		"success": map[string]any{"type": "boolean"},
+		"message": map[string]any{"type": "string"},
+	}
+	if data != nil {
+		props["data"] = data
+	}
+	return map[string]any{"type": "object", "properties": props, "required": []string{"success"}}
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *builder) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	case rawJSONType:
+		return map[string]any{} // Any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[name]; !ok {
+			b.schemas[name] = map[string]any{} // Placeholder guards against recursive types
+			b.schemas[name] = b.structSchema(t)
+		}
+		return ref(name)
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *builder) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			// encoding/json promotes the fields of untagged embedded structs
+			embedded := b.structSchema(f.Type)
+			for k, v := range embedded["properties"].(map[string]any) {
+				props[k] = v
+			}
+			if req, ok := embedded["required"].([]string); ok {
+				required = append(required, req...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		schema := b.schemaFor(f.Type)
+		if _, isRef := schema["$ref"]; !isRef {
+			applyBinding(schema, f.Tag.Get("binding"))
+		}
+		if desc := f.Tag.Get("doc"); desc != "" {
+			if _, isRef := schema["$ref"]; isRef {
+				schema = map[string]any{"allOf": []any{schema}, "description": desc}
+			} else {
+				schema["description"] = desc
+			}
+		}
+		props[name] = schema
+
+		if hasRule(f.Tag.Get("binding"), "required") && !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+// applyBinding maps the go-playground/validator rules we use onto schema keywords
+func applyBinding(schema map[string]any, binding string) {
+	if binding == "" {
+		return
+	}
+	isString := schema["type"] == "string"
+	isArray := schema["type"] == "array"
+	for _, rule := range strings.Split(binding, ",") {
+		key, val, _ := strings.Cut(rule, "=")
+		switch key {
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "oneof":
+			schema["enum"] = strings.Fields(val)
+		case "max", "min", "lte", "gte":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				continue
+			}
+			upper := key == "max" || key == "lte"
+			switch {
+			case isString && upper:
+				schema["maxLength"] = n
+			case isString:
+				schema["minLength"] = n
+			case isArray && upper:
+				schema["maxItems"] = n
+			case isArray:
+				schema["minItems"] = n
+			case upper:
+				schema["maximum"] = n
+			default:
+				schema["minimum"] = n
+			}
+		}
+	}
+}
+
+func hasRule(binding, rule string) bool {
+	for _, r := range strings.Split(binding, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func pathParams(path string) []string {
+	var out []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			out = append(out, strings.Trim(seg, "{}"))
+		}
+	}
+	return out
+}
+
+func operationID(op Operation) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(op.Method))
+	for _, seg := range strings.Split(op.Path, "/") {
+		seg = strings.Trim(seg, "{}")
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '_' || r == '-' }) {
+			sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return sb.String()
+}
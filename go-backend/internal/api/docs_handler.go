@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/docs"
+
+	"github.com/gin-gonic/gin"
+)
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>Research Service API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css" />
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>`
+
+func (s *Server) openAPISpecHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.Spec)
+}
+
+func (s *Server) apiDocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// generateExecutiveSummary writes the project's executive summary chapter from the
+// others; the body is optional
+func (s *Server) generateExecutiveSummary(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateExecutiveSummaryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	chapter, err := s.researchService.GenerateExecutiveSummary(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		var quotaErr *services.QuotaExceededError
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrInsufficientRole):
+			response.Forbidden(c, err.Error())
+		case errors.Is(err, services.ErrNoThesisContent):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, services.ErrChapterInReview):
+			response.Conflict(c, err.Error(), nil)
+		case errors.Is(err, services.ErrIntegrityAcknowledgmentRequired):
+			s.respondIntegrityAcknowledgmentRequired(c)
+		case errors.Is(err, services.ErrQuotaExceeded):
+			response.PaymentRequired(c, err.Error())
+		case errors.As(err, &quotaErr):
+			s.respondQuotaExceeded(c, quotaErr)
+		default:
+			s.logger.Error("Failed to generate executive summary", "projectID", projectID, "error", err)
+			response.InternalServerError(c, "Failed to generate executive summary", err)
+		}
+		return
+	}
+	response.Ok(c, apimodels.ToChapterResponse(chapter), "Executive summary generated")
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondFigureError maps figure and table errors to responses
+func (s *Server) respondFigureError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrFigureNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidTable),
+		errors.Is(err, services.ErrNotATable):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Figure request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listChapterFigures(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	figures, err := s.researchService.ListFigures(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondFigureError(c, "list figures", err)
+		return
+	}
+	response.Ok(c, figures, "Figures retrieved successfully")
+}
+
+func (s *Server) addChapterFigure(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	_, file, ok := s.formFile(c, "an image")
+	if !ok {
+		return
+	}
+	defer file.Close()
+	caption := strings.TrimSpace(c.PostForm("caption"))
+	if caption == "" || len(caption) > 1000 {
+		response.BadRequest(c, "caption is required and at most 1000 characters")
+		return
+	}
+	var position int32
+	if v := c.PostForm("position"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			response.BadRequest(c, "position must be a non-negative integer")
+			return
+		}
+		position = int32(n)
+	}
+
+	figure, err := s.researchService.AddFigure(c.Request.Context(), projectID, chapterID, authPayload.UserID, caption, position, file)
+	if err != nil {
+		s.respondFigureError(c, "add figure", err)
+		return
+	}
+	response.Created(c, figure, "Figure added successfully")
+}
+
+func (s *Server) addChapterTable(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	table, err := s.researchService.AddTable(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondFigureError(c, "add table", err)
+		return
+	}
+	response.Created(c, table, "Table added successfully")
+}
+
+func (s *Server) updateChapterFigure(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	figureID, ok := uuidParam(c, "figure_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateFigureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	figure, err := s.researchService.UpdateFigure(c.Request.Context(), projectID, chapterID, figureID, authPayload.UserID, req)
+	if err != nil {
+		s.respondFigureError(c, "update figure", err)
+		return
+	}
+	response.Ok(c, figure, "Figure updated successfully")
+}
+
+func (s *Server) deleteChapterFigure(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	figureID, ok := uuidParam(c, "figure_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteFigure(c.Request.Context(), projectID, chapterID, figureID, authPayload.UserID); err != nil {
+		s.respondFigureError(c, "delete figure", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) getChapterFigureImage(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	figureID, ok := uuidParam(c, "figure_id")
+	if !ok {
+		return
+	}
+	filePath, mimeType, err := s.researchService.FigureImage(c.Request.Context(), projectID, chapterID, figureID, authPayload.UserID)
+	if err != nil {
+		s.respondFigureError(c, "get figure image", err)
+		return
+	}
+	s.serveStoredFile(c, filePath, mimeType, "")
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondGenerationBatchError maps "generate all chapters" errors to responses
+func (s *Server) respondGenerationBatchError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrGenerationBatchNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole),
+		errors.Is(err, services.ErrBatchGenerationBlocked):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrGenerationBatchRunning),
+		errors.Is(err, services.ErrNothingToGenerate):
+		response.Conflict(c, err.Error(), nil)
+	case errors.Is(err, services.ErrIntegrityAcknowledgmentRequired):
+		s.respondIntegrityAcknowledgmentRequired(c)
+	default:
+		s.logger.Error("Generate-all request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// generateAllChapters queues the generation of every chapter of the project
+func (s *Server) generateAllChapters(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	batch, err := s.researchService.GenerateAllChapters(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGenerationBatchError(c, "queue chapter generation", err)
+		return
+	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGenerationBatchResponse(batch), "Chapter generation queued")
+}
+
+// getGenerationBatch reports a generate-all run and the progress of each of its chapters
+func (s *Server) getGenerationBatch(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	batchID, ok := uuidParam(c, "batch_id")
+	if !ok {
+		return
+	}
+	batch, err := s.researchService.GetGenerationBatch(c.Request.Context(), projectID, batchID, authPayload.UserID)
+	if err != nil {
+		s.respondGenerationBatchError(c, "get generation run", err)
+		return
+	}
+	response.Ok(c, apimodels.ToGenerationBatchResponse(batch))
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultGenerationLimit = 50
+	maxGenerationLimit     = 100
+)
+
+// respondGenerationLogError maps generation history errors to responses
+func (s *Server) respondGenerationLogError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Generation history request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// listChapterGenerations returns the AI calls made for a chapter, newest first
+func (s *Server) listChapterGenerations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	limit := defaultGenerationLimit
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxGenerationLimit {
+			response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxGenerationLimit))
+			return
+		}
+	}
+
+	generations, err := s.researchService.ListChapterGenerations(c.Request.Context(), projectID, chapterID, authPayload.UserID, int32(limit))
+	if err != nil {
+		s.respondGenerationLogError(c, "list generations", err)
+		return
+	}
+	resp := make([]apimodels.GenerationResponse, len(generations))
+	for i, g := range generations {
+		resp[i] = apimodels.ToGenerationResponse(g)
+	}
+	response.Ok(c, resp)
+}
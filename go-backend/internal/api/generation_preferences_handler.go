@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondGenerationPreferencesError maps generation preference errors to responses
+func (s *Server) respondGenerationPreferencesError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Generation preferences request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) getUserGenerationPreferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	prefs, err := s.researchService.GetUserGenerationPreferences(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondGenerationPreferencesError(c, "retrieve generation preferences", err)
+		return
+	}
+	response.Ok(c, prefs)
+}
+
+func (s *Server) updateUserGenerationPreferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.GenerationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	prefs, err := s.researchService.UpdateUserGenerationPreferences(c.Request.Context(), authPayload.UserID, req)
+	if err != nil {
+		s.respondGenerationPreferencesError(c, "update generation preferences", err)
+		return
+	}
+	response.Ok(c, prefs, "Generation preferences updated")
+}
+
+func (s *Server) getProjectGenerationPreferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	prefs, err := s.researchService.GetProjectGenerationPreferences(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGenerationPreferencesError(c, "retrieve generation preferences", err)
+		return
+	}
+	response.Ok(c, prefs)
+}
+
+func (s *Server) updateProjectGenerationPreferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	prefs, err := s.researchService.UpdateProjectGenerationPreferences(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondGenerationPreferencesError(c, "update generation preferences", err)
+		return
+	}
+	response.Ok(c, prefs, "Generation preferences updated")
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"github.com/shawgichan/research-service/go-backend/internal/graph"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLMaxComplexity bounds how much nesting a single query can ask for
+const graphQLMaxComplexity = 200
+
+// newGraphQLHandler builds the gqlgen server and adapts it to gin. It must be
+// mounted behind authMiddleware, as resolvers read the user ID from the context.
+func (s *Server) newGraphQLHandler() gin.HandlerFunc {
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
+		Resolvers: &graph.Resolver{
+			ResearchService: s.researchService,
+			Logger:          s.logger,
+		},
+	}))
+	srv.Use(extension.FixedComplexityLimit(graphQLMaxComplexity))
+
+	return func(c *gin.Context) {
+		authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		ctx := graph.WithUserID(c.Request.Context(), authPayload.UserID)
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+func (s *Server) graphQLPlaygroundHandler(c *gin.Context) {
+	playground.Handler("Research Service GraphQL", "/api/v1/graphql").ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondGuidelineError maps formatting guideline errors to responses
+func (s *Server) respondGuidelineError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrGuidelineNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Guideline request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) uploadProjectGuideline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	header, file, ok := s.formFile(c, "a PDF or DOCX")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	guideline, err := s.researchService.UploadGuideline(c.Request.Context(), projectID, authPayload.UserID, header.Filename, file)
+	if err != nil {
+		s.respondGuidelineError(c, "upload guideline", err)
+		return
+	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGuidelineResponse(guideline), "Guideline uploaded, rule extraction queued")
+}
+
+func (s *Server) getProjectGuideline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	guideline, err := s.researchService.GetGuideline(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGuidelineError(c, "get guideline", err)
+		return
+	}
+	response.Ok(c, apimodels.ToGuidelineResponse(guideline), "Guideline retrieved successfully")
+}
+
+func (s *Server) deleteProjectGuideline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteGuideline(c.Request.Context(), projectID, authPayload.UserID); err != nil {
+		s.respondGuidelineError(c, "delete guideline", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) getProjectFormatting(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	opts, err := s.researchService.GetFormattingOptions(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGuidelineError(c, "get formatting options", err)
+		return
+	}
+	response.Ok(c, opts)
+}
+
+func (s *Server) updateProjectFormatting(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.FormattingOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	opts, err := s.researchService.UpdateFormattingOptions(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondGuidelineError(c, "update formatting options", err)
+		return
+	}
+	response.Ok(c, opts, "Formatting options updated")
+}
+
+func (s *Server) getProjectFrontMatter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	fm, err := s.researchService.GetFrontMatter(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGuidelineError(c, "get front matter", err)
+		return
+	}
+	response.Ok(c, fm)
+}
+
+func (s *Server) updateProjectFrontMatter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.FrontMatter
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	fm, err := s.researchService.UpdateFrontMatter(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondGuidelineError(c, "update front matter", err)
+		return
+	}
+	response.Ok(c, fm, "Front matter updated")
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessCheckTimeout = 3 * time.Second
+
+type dependencyStatus struct {
+	Status    string `json:"status"` // "up" or "down"
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type readinessCheck struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// livenessHandler only reports that the process is serving requests
+func (s *Server) livenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readinessHandler checks every dependency the API needs to serve traffic.
+// Any failing dependency makes the whole instance not ready (503). The circuit
+// breakers of the outside services are reported alongside, without affecting it.
+func (s *Server) readinessHandler(c *gin.Context) {
+	checks := []readinessCheck{
+		{name: "database", fn: s.store.Ping},
+		{name: "ai_provider", fn: s.aiService.CheckAPIKey},
+	}
+	if s.config.ReadinessCheckDocGen {
+		checks = append(checks, readinessCheck{name: "docgen", fn: s.researchService.PingDocGen})
+	}
+
+	results := make(map[string]dependencyStatus, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check readinessCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.fn(ctx)
+			result := dependencyStatus{Status: "up", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = "down"
+				result.Error = err.Error()
+				s.logger.Warn("Readiness check failed", "dependency", check.name, "error", err)
+			}
+
+			mu.Lock()
+			results[check.name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	status, code := "ready", http.StatusOK
+	for _, r := range results {
+		if r.Status != "up" {
+			status, code = "not_ready", http.StatusServiceUnavailable
+			break
+		}
+	}
+	circuits := make(map[string]breaker.Status)
+	for _, b := range append(s.aiService.Circuits(), s.researchService.Circuits()...) {
+		circuits[b.Name()] = b.Status()
+	}
+	c.JSON(code, gin.H{"status": status, "checks": results, "circuits": circuits})
+}
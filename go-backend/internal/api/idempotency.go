@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// IdempotencyKeyTTL is how long an idempotency key is honoured; older keys may be purged
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// bodyCaptureWriter tees the response body so it can be stored for replays
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware makes a POST safe to retry when the client sends an Idempotency-Key.
+// The first request with a key runs normally and its response is stored; retries with the same
+// key and the same request replay the stored response instead of re-running the handler.
+// Must be mounted after authMiddleware since keys are scoped per user.
+func (s *Server) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.BadRequest(c, "Idempotency-Key is too long")
+			return
+		}
+
+		authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+		userID := pgtype.UUID{Bytes: authPayload.UserID, Valid: true}
+		ctx := db.WithPrimary(c.Request.Context()) // The key row we race on must be read from the primary
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.BadRequest(c, "Could not read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashRequest(c.Request.Method, c.Request.URL.Path, body)
+
+		record, err := s.store.CreateIdempotencyKey(ctx, sqlc.CreateIdempotencyKeyParams{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestMethod:  c.Request.Method,
+			RequestPath:    c.Request.URL.Path,
+			RequestHash:    requestHash,
+		})
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			// Key already used by this user
+			existing, getErr := s.store.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+			if getErr != nil {
+				s.logger.Error("Failed to load idempotency key", "userID", authPayload.UserID, "error", getErr)
+				response.InternalServerError(c, "Failed to process Idempotency-Key", getErr)
+				return
+			}
+			if time.Since(existing.CreatedAt.Time) > IdempotencyKeyTTL {
+				// Stale key: forget it and ask the client to retry as a fresh request
+				_ = s.store.DeleteIdempotencyKey(ctx, existing.ID)
+				response.RespondError(c, http.StatusConflict, "Idempotency-Key has expired, please retry")
+				return
+			}
+			s.replayIdempotentResponse(c, existing, requestHash)
+			return
+		}
+		if err != nil {
+			s.logger.Error("Failed to store idempotency key", "userID", authPayload.UserID, "error", err)
+			response.InternalServerError(c, "Failed to process Idempotency-Key", err)
+			return
+		}
+
+		// The outcome is stored even when the client has gone away, and the key is released
+		// unless it was stored, the handler panicking included, so the client can retry with it
+		writeCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := s.store.DeleteIdempotencyKey(writeCtx, record.ID); err != nil {
+				s.logger.Error("Failed to release idempotency key", "keyID", record.ID, "error", err)
+			}
+		}()
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return // Let the client retry failed requests with the same key
+		}
+
+		responseBody := writer.body.Bytes()
+		sum := sha256.Sum256(responseBody)
+		_, err = s.store.CompleteIdempotencyKey(writeCtx, sqlc.CompleteIdempotencyKeyParams{
+			ID:             record.ID,
+			ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: true},
+			ResponseBody:   responseBody,
+			ResponseHash:   pgtype.Text{String: hex.EncodeToString(sum[:]), Valid: true},
+		})
+		if err != nil {
+			s.logger.Error("Failed to store idempotent response", "keyID", record.ID, "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+func (s *Server) replayIdempotentResponse(c *gin.Context, record sqlc.IdempotencyKey, requestHash string) {
+	if record.RequestHash != requestHash {
+		response.RespondError(c, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if !record.CompletedAt.Valid {
+		response.RespondError(c, http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+		return
+	}
+
+	s.logger.Info("Replaying idempotent response", "keyID", record.ID, "path", record.RequestPath)
+	c.Header(idempotencyReplayedHeader, "true")
+	c.Data(int(record.ResponseStatus.Int32), "application/json; charset=utf-8", record.ResponseBody)
+	c.Abort()
+}
+
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
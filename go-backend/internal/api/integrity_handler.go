@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getIntegrityPolicy(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	policy, err := s.researchService.IntegrityPolicy(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get integrity policy", "userID", authPayload.UserID, "error", err)
+		response.InternalServerError(c, "Failed to retrieve integrity policy", err)
+		return
+	}
+	response.Ok(c, policy)
+}
+
+func (s *Server) acknowledgeIntegrityPolicy(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	policy, err := s.researchService.AcknowledgeIntegrityPolicy(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.logger.Error("Failed to acknowledge integrity policy", "userID", authPayload.UserID, "error", err)
+		response.InternalServerError(c, "Failed to acknowledge integrity policy", err)
+		return
+	}
+	response.Ok(c, policy, "Integrity policy acknowledged")
+}
+
+// respondIntegrityAcknowledgmentRequired answers 428 to generation before the user has
+// acknowledged the integrity policy
+func (s *Server) respondIntegrityAcknowledgmentRequired(c *gin.Context) {
+	response.RespondError(c, http.StatusPreconditionRequired, services.ErrIntegrityAcknowledgmentRequired.Error(),
+		"Acknowledge it with POST /api/v1/users/me/integrity-policy/acknowledge")
+}
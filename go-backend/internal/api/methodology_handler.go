@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondMethodologyError maps methodology questionnaire errors to responses
+func (s *Server) respondMethodologyError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrMethodologyNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	default:
+		s.logger.Error("Methodology request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) getProjectMethodology(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	methodology, err := s.researchService.GetMethodology(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondMethodologyError(c, "get methodology", err)
+		return
+	}
+	response.Ok(c, apimodels.ToMethodologyResponse(methodology), "Methodology retrieved successfully")
+}
+
+func (s *Server) saveProjectMethodology(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.MethodologyAnswers
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	methodology, err := s.researchService.SaveMethodology(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondMethodologyError(c, "save methodology", err)
+		return
+	}
+	response.Ok(c, apimodels.ToMethodologyResponse(methodology), "Methodology saved successfully")
+}
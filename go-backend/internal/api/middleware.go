@@ -3,13 +3,19 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/ratelimit"
 	"github.com/shawgichan/research-service/go-backend/internal/token"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
@@ -54,3 +60,87 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+const (
+	requestIDHeaderKey = "X-Request-ID"
+	requestIDKey       = "request_id"
+)
+
+// requestIDMiddleware propagates the caller's X-Request-ID or generates a new one,
+// exposing it on the gin context and echoing it back in the response headers
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeaderKey)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		c.Set(requestIDKey, requestID)
+		c.Header(requestIDHeaderKey, requestID)
+		c.Next()
+	}
+}
+
+// accessLogMiddleware logs one structured line per request once the handler chain has finished
+func accessLogMiddleware(logger *applogger.AppLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path // Not the query string, which may carry tokens, codes or emails
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", path,
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"request_id", c.GetString(requestIDKey),
+			"bytes", c.Writer.Size(),
+		}
+		// The payload is only present once authMiddleware has run for the route
+		if payload, ok := c.Get(authorizationPayloadKey); ok {
+			if p, ok := payload.(*token.Payload); ok {
+				attrs = append(attrs, "userID", p.UserID)
+			}
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("HTTP request", attrs...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("HTTP request", attrs...)
+		default:
+			logger.Info("HTTP request", attrs...)
+		}
+	}
+}
+
+// rateLimitMiddleware applies a per-IP token bucket and sets the X-RateLimit-* headers.
+// If the limiter backend is unavailable the request is let through rather than failing the API.
+func rateLimitMiddleware(limiter ratelimit.Limiter, logger *applogger.AppLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := limiter.Allow(c.Request.Context(), "ip:"+c.ClientIP())
+		if err != nil {
+			logger.Error("Rate limiter unavailable, allowing request", "clientIP", c.ClientIP(), "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(res.ResetAfter.Seconds()))))
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			logger.Warn("Rate limit exceeded", "clientIP", c.ClientIP(), "path", c.Request.URL.Path)
+			response.TooManyRequests(c, "rate limit exceeded, please retry later")
+			return
+		}
+		c.Next()
+	}
+}
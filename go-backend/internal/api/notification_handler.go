@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 100
+)
+
+// respondNotificationError maps notification errors to responses
+func (s *Server) respondNotificationError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound),
+		errors.Is(err, services.ErrUnknownNotificationType):
+		response.NotFound(c, err.Error())
+	default:
+		s.logger.Error("Notification request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listNotifications(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	unreadOnly, err := parseBoolQuery(c, "unread", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	limit := defaultNotificationLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxNotificationLimit {
+			response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxNotificationLimit))
+			return
+		}
+	}
+
+	notifications, unread, err := s.notifications.ListNotifications(c.Request.Context(), authPayload.UserID, unreadOnly, limit)
+	if err != nil {
+		s.respondNotificationError(c, "list notifications", err)
+		return
+	}
+	resp := apimodels.NotificationListResponse{
+		Notifications: make([]apimodels.NotificationResponse, len(notifications)),
+		UnreadCount:   unread,
+	}
+	for i, n := range notifications {
+		resp.Notifications[i] = apimodels.ToNotificationResponse(n)
+	}
+	response.Ok(c, resp, "Notifications retrieved successfully")
+}
+
+func (s *Server) markNotificationRead(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	notificationID, ok := uuidParam(c, "notification_id")
+	if !ok {
+		return
+	}
+	if err := s.notifications.MarkRead(c.Request.Context(), authPayload.UserID, notificationID); err != nil {
+		s.respondNotificationError(c, "mark notification read", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) markAllNotificationsRead(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := s.notifications.MarkAllRead(c.Request.Context(), authPayload.UserID); err != nil {
+		s.respondNotificationError(c, "mark notifications read", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listNotificationPreferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	prefs, err := s.notifications.Preferences(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondNotificationError(c, "list notification preferences", err)
+		return
+	}
+	response.Ok(c, prefs, "Notification preferences retrieved successfully")
+}
+
+func (s *Server) updateNotificationPreference(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	pref, err := s.notifications.SetPreference(c.Request.Context(), authPayload.UserID, c.Param("type"), req)
+	if err != nil {
+		s.respondNotificationError(c, "update notification preference", err)
+		return
+	}
+	response.Ok(c, pref, "Notification preference updated successfully")
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondOrganizationError maps OrganizationService errors to responses
+func (s *Server) respondOrganizationError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrInvitationNotFound),
+		errors.Is(err, services.ErrMemberNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrNotOrganizationAdmin):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrAlreadyMember), errors.Is(err, services.ErrLastOwner):
+		response.Conflict(c, err.Error(), nil)
+	default:
+		s.logger.Error("Organization request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// uuidParam parses a UUID path parameter, responding 400 when it is malformed
+func uuidParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "Invalid "+name+" format")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (s *Server) createOrganization(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	org, err := s.orgService.CreateOrganization(c.Request.Context(), authPayload.UserID, req)
+	if err != nil {
+		s.respondOrganizationError(c, "create organization", err)
+		return
+	}
+	response.Created(c, apimodels.ToOrganizationResponse(org, services.OrgRoleOwner), "Organization created successfully")
+}
+
+func (s *Server) listOrganizations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgs, err := s.orgService.ListUserOrganizations(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve organizations", err)
+		return
+	}
+	orgResponses := make([]apimodels.OrganizationResponse, 0, len(orgs))
+	for _, o := range orgs {
+		orgResponses = append(orgResponses, apimodels.OrganizationResponse{
+			ID:        o.ID.Bytes,
+			Name:      o.Name,
+			Role:      o.Role,
+			CreatedAt: o.CreatedAt.Time,
+		})
+	}
+	response.Ok(c, orgResponses)
+}
+
+func (s *Server) getOrganization(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	org, role, err := s.orgService.GetOrganization(c.Request.Context(), orgID, authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve organization", err)
+		return
+	}
+	response.Ok(c, apimodels.ToOrganizationResponse(org, role))
+}
+
+func (s *Server) listOrganizationMembers(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	members, err := s.orgService.ListMembers(c.Request.Context(), orgID, authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve members", err)
+		return
+	}
+	memberResponses := make([]apimodels.OrganizationMemberResponse, 0, len(members))
+	for _, m := range members {
+		memberResponses = append(memberResponses, apimodels.ToOrganizationMemberResponse(m))
+	}
+	response.Ok(c, memberResponses)
+}
+
+func (s *Server) removeOrganizationMember(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	memberID, ok := uuidParam(c, "user_id")
+	if !ok {
+		return
+	}
+	if err := s.orgService.RemoveMember(c.Request.Context(), orgID, memberID, authPayload.UserID); err != nil {
+		s.respondOrganizationError(c, "remove member", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listOrganizationProjects(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	projects, err := s.orgService.ListProjects(c.Request.Context(), orgID, authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve organization projects", err)
+		return
+	}
+	projectResponses := make([]apimodels.ProjectResponse, 0, len(projects))
+	for _, p := range projects {
+		projectResponses = append(projectResponses, apimodels.ToProjectResponse(p))
+	}
+	response.Ok(c, projectResponses)
+}
+
+func (s *Server) inviteOrganizationMember(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	var req apimodels.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	invitation, invitationToken, err := s.orgService.InviteMember(c.Request.Context(), orgID, authPayload.UserID, req)
+	if err != nil {
+		s.respondOrganizationError(c, "invite member", err)
+		return
+	}
+	resp := apimodels.ToInvitationResponse(invitation)
+	resp.Token = invitationToken
+	response.Created(c, resp, "Invitation sent")
+}
+
+func (s *Server) listOrganizationInvitations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	invitations, err := s.orgService.ListInvitations(c.Request.Context(), orgID, authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve invitations", err)
+		return
+	}
+	invitationResponses := make([]apimodels.InvitationResponse, 0, len(invitations))
+	for _, inv := range invitations {
+		invitationResponses = append(invitationResponses, apimodels.ToInvitationResponse(inv))
+	}
+	response.Ok(c, invitationResponses)
+}
+
+func (s *Server) revokeOrganizationInvitation(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgID, ok := uuidParam(c, "organization_id")
+	if !ok {
+		return
+	}
+	invitationID, ok := uuidParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+	if err := s.orgService.RevokeInvitation(c.Request.Context(), orgID, invitationID, authPayload.UserID); err != nil {
+		s.respondOrganizationError(c, "revoke invitation", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listMyInvitations(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitations, err := s.orgService.ListMyInvitations(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve invitations", err)
+		return
+	}
+	invitationResponses := make([]apimodels.InvitationResponse, 0, len(invitations))
+	for _, inv := range invitations {
+		invitationResponses = append(invitationResponses, apimodels.InvitationResponse{
+			ID:               inv.ID.Bytes,
+			OrganizationID:   inv.OrganizationID.Bytes,
+			OrganizationName: inv.OrganizationName,
+			Email:            inv.Email,
+			Role:             inv.Role,
+			ExpiresAt:        inv.ExpiresAt.Time,
+			CreatedAt:        inv.CreatedAt.Time,
+		})
+	}
+	response.Ok(c, invitationResponses)
+}
+
+func (s *Server) acceptInvitation(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitationID, ok := uuidParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+	var req apimodels.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	member, err := s.orgService.AcceptInvitation(c.Request.Context(), invitationID, authPayload.UserID, req.Token)
+	if err != nil {
+		s.respondOrganizationError(c, "accept invitation", err)
+		return
+	}
+	org, role, err := s.orgService.GetOrganization(c.Request.Context(), member.OrganizationID.Bytes, authPayload.UserID)
+	if err != nil {
+		s.respondOrganizationError(c, "retrieve organization", err)
+		return
+	}
+	response.Ok(c, apimodels.ToOrganizationResponse(org, role), "Invitation accepted")
+}
+
+func (s *Server) declineInvitation(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitationID, ok := uuidParam(c, "invitation_id")
+	if !ok {
+		return
+	}
+	if err := s.orgService.DeclineInvitation(c.Request.Context(), invitationID, authPayload.UserID); err != nil {
+		s.respondOrganizationError(c, "decline invitation", err)
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondPaperError maps Semantic Scholar, paper import and paper PDF errors to responses
+func (s *Server) respondPaperError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrReferenceNotFound),
+		errors.Is(err, services.ErrReferencePDFNotFound),
+		errors.Is(err, services.ErrPaperNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrReferencePDFPending),
+		errors.Is(err, services.ErrNoRecommendationSeeds),
+		errors.Is(err, services.ErrReferenceNotOnScholar):
+		response.Conflict(c, err.Error(), nil)
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidPaperSearch):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrScholarRateLimited):
+		response.RespondError(c, http.StatusServiceUnavailable, err.Error())
+	default:
+		s.logger.Error("Paper request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) searchPapers(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	var req apimodels.SearchPapersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid search parameters", err.Error())
+		return
+	}
+	results, err := s.researchService.SearchPapers(c.Request.Context(), authPayload.UserID, req)
+	if err != nil {
+		s.respondPaperError(c, "search papers", err)
+		return
+	}
+	response.Ok(c, results)
+}
+
+func (s *Server) recommendedPapers(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.RecommendedPapersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters", err.Error())
+		return
+	}
+	papers, err := s.researchService.RecommendPapers(c.Request.Context(), projectID, authPayload.UserID, req.Limit)
+	if err != nil {
+		s.respondPaperError(c, "recommend papers", err)
+		return
+	}
+	response.Ok(c, papers)
+}
+
+// citedPapers lists the papers a saved reference cites
+func (s *Server) citedPapers(c *gin.Context) {
+	s.paperCitationGraph(c, services.CitationGraphReferences)
+}
+
+// citingPapers lists the papers citing a saved reference
+func (s *Server) citingPapers(c *gin.Context) {
+	s.paperCitationGraph(c, services.CitationGraphCitations)
+}
+
+func (s *Server) paperCitationGraph(c *gin.Context, direction string) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CitationGraphRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters", err.Error())
+		return
+	}
+	graph, err := s.researchService.PaperCitationGraph(c.Request.Context(), projectID, referenceID, authPayload.UserID, direction, req)
+	if err != nil {
+		s.respondPaperError(c, "get citation graph", err)
+		return
+	}
+	response.Ok(c, graph)
+}
+
+func (s *Server) importPapers(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.ImportPapersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	result, err := s.researchService.ImportPapers(c.Request.Context(), projectID, authPayload.UserID, req.PaperIDs)
+	if err != nil {
+		s.respondPaperError(c, "import papers", err)
+		return
+	}
+	response.Created(c, result, "Papers imported successfully")
+}
+
+func (s *Server) getReferencePDF(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	filePath, fileName, err := s.researchService.ReferencePDF(c.Request.Context(), projectID, referenceID, authPayload.UserID)
+	if err != nil {
+		s.respondPaperError(c, "get reference PDF", err)
+		return
+	}
+	s.serveStoredFile(c, filePath, "application/pdf", fileName)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondQuestionnaireError maps questionnaire errors to responses
+func (s *Server) respondQuestionnaireError(c *gin.Context, action string, err error) {
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrQuestionnaireNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidQuestionnaire):
+		response.BadRequest(c, err.Error())
+	case errors.As(err, &quotaErr):
+		s.respondQuotaExceeded(c, quotaErr)
+	default:
+		s.logger.Error("Questionnaire request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listProjectQuestionnaires(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	questionnaires, err := s.researchService.ListQuestionnaires(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondQuestionnaireError(c, "list questionnaires", err)
+		return
+	}
+	resp := make([]apimodels.QuestionnaireResponse, len(questionnaires))
+	for i, q := range questionnaires {
+		resp[i] = apimodels.ToQuestionnaireResponse(q)
+	}
+	response.Ok(c, resp, "Questionnaires retrieved successfully")
+}
+
+func (s *Server) createProjectQuestionnaire(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	questionnaire, err := s.researchService.CreateQuestionnaire(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondQuestionnaireError(c, "create questionnaire", err)
+		return
+	}
+	response.Created(c, apimodels.ToQuestionnaireResponse(questionnaire), "Questionnaire created successfully")
+}
+
+func (s *Server) getProjectQuestionnaire(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	questionnaireID, ok := uuidParam(c, "questionnaire_id")
+	if !ok {
+		return
+	}
+	questionnaire, err := s.researchService.GetQuestionnaire(c.Request.Context(), projectID, questionnaireID, authPayload.UserID)
+	if err != nil {
+		s.respondQuestionnaireError(c, "get questionnaire", err)
+		return
+	}
+	response.Ok(c, apimodels.ToQuestionnaireResponse(questionnaire), "Questionnaire retrieved successfully")
+}
+
+func (s *Server) updateProjectQuestionnaire(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	questionnaireID, ok := uuidParam(c, "questionnaire_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	questionnaire, err := s.researchService.UpdateQuestionnaire(c.Request.Context(), projectID, questionnaireID, authPayload.UserID, req)
+	if err != nil {
+		s.respondQuestionnaireError(c, "update questionnaire", err)
+		return
+	}
+	response.Ok(c, apimodels.ToQuestionnaireResponse(questionnaire), "Questionnaire updated successfully")
+}
+
+func (s *Server) deleteProjectQuestionnaire(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	questionnaireID, ok := uuidParam(c, "questionnaire_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteQuestionnaire(c.Request.Context(), projectID, questionnaireID, authPayload.UserID); err != nil {
+		s.respondQuestionnaireError(c, "delete questionnaire", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) generateQuestionnaireItems(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	questionnaireID, ok := uuidParam(c, "questionnaire_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateQuestionnaireItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	questionnaire, err := s.researchService.GenerateQuestionnaireItems(c.Request.Context(), projectID, questionnaireID, authPayload.UserID, req)
+	if err != nil {
+		s.respondQuestionnaireError(c, "generate questionnaire items", err)
+		return
+	}
+	response.Ok(c, apimodels.ToQuestionnaireResponse(questionnaire), "Questionnaire items generated successfully")
+}
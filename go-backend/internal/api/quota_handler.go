@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getQuota(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	quota, err := s.quotas.Usage(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get quota usage", "userID", authPayload.UserID, "error", err)
+		response.InternalServerError(c, "Failed to retrieve quota usage", err)
+		return
+	}
+	response.Ok(c, quota, "Quota usage retrieved successfully")
+}
+
+// respondQuotaExceeded answers 402 when a higher plan has a larger quota, and 429 with
+// Retry-After set to the period's reset otherwise
+func (s *Server) respondQuotaExceeded(c *gin.Context, err *services.QuotaExceededError) {
+	status := http.StatusPaymentRequired
+	if !err.Upgradable {
+		status = http.StatusTooManyRequests
+		c.Header("Retry-After", strconv.Itoa(int(max(time.Until(err.ResetsAt).Seconds(), 0))+1))
+	}
+	response.QuotaExceeded(c, status, err.Error(), apimodels.QuotaExceededResponse{
+		Plan:               err.Plan,
+		QuotaUsageResponse: apimodels.NewQuotaUsageResponse(err.Resource, err.Limit, err.Used),
+		ResetsAt:           err.ResetsAt,
+		Upgradable:         err.Upgradable,
+	})
+}
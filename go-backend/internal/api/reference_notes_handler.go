@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondReferenceNotesError maps reference note, highlight, tag and export errors to responses
+func (s *Server) respondReferenceNotesError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrReferenceNotFound),
+		errors.Is(err, services.ErrHighlightNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidReferenceTag),
+		errors.Is(err, services.ErrInvalidExportFormat),
+		errors.Is(err, services.ErrEmptyAfterSanitizing):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Reference notes request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) getReferenceNotes(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	notes, err := s.researchService.GetReferenceNotes(c.Request.Context(), projectID, referenceID, authPayload.UserID)
+	if err != nil {
+		s.respondReferenceNotesError(c, "get reference notes", err)
+		return
+	}
+	response.Ok(c, notes, "Reference notes retrieved successfully")
+}
+
+func (s *Server) updateReferenceNotes(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateReferenceNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	notes, err := s.researchService.UpdateReferenceNotes(c.Request.Context(), projectID, referenceID, authPayload.UserID, req.Notes)
+	if err != nil {
+		s.respondReferenceNotesError(c, "update reference notes", err)
+		return
+	}
+	response.Ok(c, notes, "Reference notes updated successfully")
+}
+
+func (s *Server) addReferenceHighlight(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateReferenceHighlightRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	highlight, err := s.researchService.AddReferenceHighlight(c.Request.Context(), projectID, referenceID, authPayload.UserID, req)
+	if err != nil {
+		s.respondReferenceNotesError(c, "add highlight", err)
+		return
+	}
+	response.Created(c, apimodels.ToReferenceHighlightResponse(highlight), "Highlight added successfully")
+}
+
+func (s *Server) deleteReferenceHighlight(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	highlightID, ok := uuidParam(c, "highlight_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteReferenceHighlight(c.Request.Context(), projectID, referenceID, highlightID, authPayload.UserID); err != nil {
+		s.respondReferenceNotesError(c, "delete highlight", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) updateReferenceTags(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	referenceID, ok := uuidParam(c, "reference_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateReferenceTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	ref, err := s.researchService.SetReferenceTags(c.Request.Context(), projectID, referenceID, authPayload.UserID, req.Tags)
+	if err != nil {
+		s.respondReferenceNotesError(c, "update reference tags", err)
+		return
+	}
+	response.Ok(c, ref, "Reference tags updated successfully")
+}
+
+// exportReferences downloads the project's references for a reference manager.
+// ?format= is csl-json (the default) or zotero-rdf; ?tag= narrows them as in the listing.
+func (s *Server) exportReferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", services.ReferenceExportCSLJSON)
+	export, err := s.researchService.ExportReferences(c.Request.Context(), projectID, authPayload.UserID, format, c.QueryArray("tag"))
+	if err != nil {
+		s.respondReferenceNotesError(c, "export references", err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", export.FileName))
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
@@ -5,13 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os" // For file download (example)
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
 	apimodels "github.com/shawgichan/research-service/go-backend/internal/models" // API request/response models
 	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
 	"github.com/shawgichan/research-service/go-backend/internal/token"
 
 	"github.com/gin-gonic/gin"
@@ -31,6 +39,19 @@ func (s *Server) createProject(c *gin.Context) {
 
 	project, err := s.researchService.CreateProject(c.Request.Context(), authPayload.UserID, req)
 	if err != nil {
+		var quotaErr *services.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			s.respondQuotaExceeded(c, quotaErr)
+			return
+		}
+		if errors.Is(err, services.ErrOrganizationNotFound) {
+			response.NotFound(c, services.ErrOrganizationNotFound.Error())
+			return
+		}
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			response.PaymentRequired(c, err.Error())
+			return
+		}
 		s.logger.Error("Failed to create project", "userID", authPayload.UserID, "title", req.Title, "error", err)
 		response.InternalServerError(c, "Failed to create project", err)
 		return
@@ -48,7 +69,7 @@ func (s *Server) getProject(c *gin.Context) {
 		return
 	}
 
-	project, err := s.researchService.GetUserProjectByID(c.Request.Context(), projectID, authPayload.UserID)
+	project, role, err := s.researchService.GetProjectWithRole(c.Request.Context(), projectID, authPayload.UserID)
 	if err != nil {
 		if errors.Is(err, services.ErrProjectNotFound) {
 			s.logger.Info("Project not found or access denied for getProject", "projectID", projectID, "userID", authPayload.UserID)
@@ -60,20 +81,39 @@ func (s *Server) getProject(c *gin.Context) {
 		return
 	}
 
+	// Chapter content can be large; ?include_content=false returns chapter metadata only
+	includeContent, err := parseBoolQuery(c, "include_content", true)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	includeHTML, err := parseBoolQuery(c, "include_html", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
 	// Optionally load chapters and references for the single project view
 	chapters, err := s.researchService.GetProjectChapters(c.Request.Context(), project.ID.Bytes, authPayload.UserID)
 	if err != nil {
 		s.logger.Error("Failed to get chapters for project view", "projectID", project.ID, "error", err)
 		// Don't fail the whole request, just log and continue without chapters
 	}
+	openComments := s.openCommentCounts(c, project.ID.Bytes)
 	var chapterResponses []apimodels.ChapterResponse
 	for _, ch := range chapters {
-		chapterResponses = append(chapterResponses, apimodels.ToChapterResponse(ch))
+		chapterResp := apimodels.ToChapterResponseWithOptions(ch, includeContent)
+		chapterResp.OpenComments = openComments[ch.ID.Bytes]
+		if includeHTML {
+			chapterResp.ContentHTML = markdown.ToHTML(ch.Content.String)
+		}
+		chapterResponses = append(chapterResponses, chapterResp)
 	}
 
 	projectResp := apimodels.ToProjectResponse(project)
+	projectResp.AccessRole = role
 	projectResp.Chapters = chapterResponses
-	response.Ok(c, projectResp)
+	response.OkWithETag(c, response.WeakETag(projectETag(project, chapters), commentCountsETag(openComments), c.Request.URL.RawQuery), projectResp)
 }
 
 func (s *Server) listUserProjects(c *gin.Context) {
@@ -112,6 +152,10 @@ func (s *Server) updateProject(c *gin.Context) {
 
 	updatedProject, err := s.researchService.UpdateProject(c.Request.Context(), projectID, authPayload.UserID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) {
 			s.logger.Info("Project not found or access denied for updateProject", "projectID", projectID, "userID", authPayload.UserID)
 			response.NotFound(c, services.ErrProjectNotFound.Error())
@@ -171,6 +215,10 @@ func (s *Server) createChapter(c *gin.Context) {
 
 	chapter, err := s.researchService.CreateChapter(c.Request.Context(), authPayload.UserID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) {
 			response.NotFound(c, services.ErrProjectNotFound.Error())
 			return
@@ -179,6 +227,10 @@ func (s *Server) createChapter(c *gin.Context) {
 			response.RespondError(c, http.StatusConflict, services.ErrChapterAlreadyExists.Error())
 			return
 		}
+		if errors.Is(err, services.ErrEmptyAfterSanitizing) || errors.Is(err, services.ErrUnknownChapterType) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		s.logger.Error("Failed to create chapter", "projectID", req.ProjectID, "type", req.Type, "error", err)
 		response.InternalServerError(c, "Failed to create chapter", err)
 		return
@@ -186,6 +238,87 @@ func (s *Server) createChapter(c *gin.Context) {
 	response.Created(c, apimodels.ToChapterResponse(chapter), "Chapter created successfully")
 }
 
+func (s *Server) bulkSaveChapters(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		s.logger.Warn("Invalid project ID format in bulkSaveChapters", "projectID", projectIDStr, "error", err)
+		response.BadRequest(c, "Invalid project ID in path")
+		return
+	}
+
+	var req apimodels.BulkChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Warn("Invalid bulk chapters request", "projectID", projectID, "error", err)
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+
+	chapters, err := s.researchService.BulkUpsertChapters(c.Request.Context(), projectID, authPayload.UserID, req.Chapters)
+	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
+		if errors.Is(err, services.ErrProjectNotFound) {
+			response.NotFound(c, services.ErrProjectNotFound.Error())
+			return
+		}
+		if errors.Is(err, services.ErrInvalidBulkChapters) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrChapterInReview) || errors.Is(err, services.ErrReviewIsRequired) {
+			response.Conflict(c, err.Error(), nil)
+			return
+		}
+		s.logger.Error("Failed to bulk save chapters", "projectID", projectID, "error", err)
+		response.InternalServerError(c, "Failed to save chapters", err)
+		return
+	}
+
+	chapterResponses := make([]apimodels.ChapterResponse, 0, len(chapters))
+	for _, ch := range chapters {
+		chapterResponses = append(chapterResponses, apimodels.ToChapterResponse(ch))
+	}
+	response.Ok(c, chapterResponses, "Chapters saved successfully")
+}
+
+func (s *Server) reorderChapters(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.ReorderChaptersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+
+	chapters, err := s.researchService.ReorderChapters(c.Request.Context(), projectID, authPayload.UserID, req.ChapterIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInsufficientRole):
+			response.Forbidden(c, err.Error())
+		case errors.Is(err, services.ErrProjectNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrInvalidChapterOrder):
+			response.BadRequest(c, err.Error())
+		default:
+			s.logger.Error("Failed to reorder chapters", "projectID", projectID, "error", err)
+			response.InternalServerError(c, "Failed to reorder chapters", err)
+		}
+		return
+	}
+	chapterResponses := make([]apimodels.ChapterResponse, 0, len(chapters))
+	for _, ch := range chapters {
+		chapterResponses = append(chapterResponses, apimodels.ToChapterResponseWithOptions(ch, false))
+	}
+	response.Ok(c, chapterResponses, "Chapters reordered successfully")
+}
+
 func (s *Server) listProjectChapters(c *gin.Context) {
 	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
 	projectIDStr := c.Param("project_id")
@@ -196,6 +329,29 @@ func (s *Server) listProjectChapters(c *gin.Context) {
 		return
 	}
 
+	// Sparse fieldsets: ?fields=id,title,status and/or ?include_content=false
+	fields, err := parseFieldsQuery(c, apimodels.ChapterFields)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	includeContent, err := parseBoolQuery(c, "include_content", true)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	includeHTML, err := parseBoolQuery(c, "include_html", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if len(fields) > 0 && !slices.Contains(fields, "content") {
+		includeContent = false // Don't pay for content we're going to drop anyway
+	}
+	if len(fields) > 0 && !slices.Contains(fields, "content_html") {
+		includeHTML = false
+	}
+
 	chapters, err := s.researchService.GetProjectChapters(c.Request.Context(), projectID, authPayload.UserID)
 	if err != nil {
 		if errors.Is(err, services.ErrProjectNotFound) {
@@ -207,11 +363,92 @@ func (s *Server) listProjectChapters(c *gin.Context) {
 		return
 	}
 
-	var chapterResponses []apimodels.ChapterResponse
+	openComments := s.openCommentCounts(c, projectID)
+	etag := response.WeakETag(chaptersETag(chapters), commentCountsETag(openComments), c.Request.URL.RawQuery)
+	if len(fields) == 0 {
+		chapterResponses := make([]apimodels.ChapterResponse, 0, len(chapters))
+		for _, ch := range chapters {
+			chapterResp := apimodels.ToChapterResponseWithOptions(ch, includeContent)
+			chapterResp.OpenComments = openComments[ch.ID.Bytes]
+			if includeHTML {
+				chapterResp.ContentHTML = markdown.ToHTML(ch.Content.String)
+			}
+			chapterResponses = append(chapterResponses, chapterResp)
+		}
+		response.OkWithETag(c, etag, chapterResponses)
+		return
+	}
+
+	sparse := make([]map[string]interface{}, 0, len(chapters))
 	for _, ch := range chapters {
-		chapterResponses = append(chapterResponses, apimodels.ToChapterResponse(ch))
+		chapterResp := apimodels.ToChapterResponseWithOptions(ch, includeContent)
+		chapterResp.OpenComments = openComments[ch.ID.Bytes]
+		if includeHTML {
+			chapterResp.ContentHTML = markdown.ToHTML(ch.Content.String)
+		}
+		selected, err := apimodels.SelectFields(chapterResp, fields)
+		if err != nil {
+			response.InternalServerError(c, "Failed to render chapters", err)
+			return
+		}
+		sparse = append(sparse, selected)
+	}
+	response.OkWithETag(c, etag, sparse)
+}
+
+func (s *Server) getProjectProgress(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	progress, err := s.researchService.GetProjectProgress(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		s.logger.Error("Failed to get project progress", "projectID", projectID, "error", err)
+		response.InternalServerError(c, "Failed to retrieve project progress", err)
+		return
+	}
+	response.Ok(c, progress, "Project progress retrieved successfully")
+}
+
+func (s *Server) getChapter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, errP := uuid.Parse(projectIDStr)
+	chapterIDStr := c.Param("chapter_id")
+	chapterID, errC := uuid.Parse(chapterIDStr)
+
+	if errP != nil || errC != nil {
+		s.logger.Warn("Invalid project/chapter ID format in getChapter", "projectID", projectIDStr, "chapterID", chapterIDStr)
+		response.BadRequest(c, "Invalid project or chapter ID format")
+		return
+	}
+	includeHTML, err := parseBoolQuery(c, "include_html", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	chapter, err := s.researchService.GetChapterByID(c.Request.Context(), chapterID, projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
+			response.NotFound(c, "Chapter or project not found, or access denied.")
+			return
+		}
+		s.logger.Error("Failed to get chapter", "chapterID", chapterID, "error", err)
+		response.InternalServerError(c, "Failed to retrieve chapter", err)
+		return
+	}
+	chapterResp := apimodels.ToChapterResponse(chapter)
+	chapterResp.OpenComments = s.openCommentCounts(c, projectID)[chapterID]
+	if includeHTML {
+		chapterResp.ContentHTML = markdown.ToHTML(chapter.Content.String)
 	}
-	response.Ok(c, chapterResponses)
+	response.OkWithETag(c, chapterViewETag(chapter, chapterResp.OpenComments), chapterResp)
 }
 
 func (s *Server) updateChapter(c *gin.Context) {
@@ -234,20 +471,63 @@ func (s *Server) updateChapter(c *gin.Context) {
 		return
 	}
 
+	// If-Match (an ETag from GET chapter) is an alternative to sending version in the body
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" && req.Version == nil {
+		current, err := s.researchService.GetChapterByID(c.Request.Context(), chapterID, projectID, authPayload.UserID)
+		if err != nil {
+			if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
+				response.NotFound(c, "Chapter or project not found, or access denied.")
+				return
+			}
+			response.InternalServerError(c, "Failed to retrieve chapter", err)
+			return
+		}
+		if !chapterIfMatch(ifMatch, current) {
+			s.respondChapterConflict(c, current)
+			return
+		}
+		req.Version = &current.Version
+	}
+
 	updatedChapter, err := s.researchService.UpdateChapter(c.Request.Context(), chapterID, projectID, authPayload.UserID, req)
 	if err != nil {
+		var conflictErr *services.ChapterConflictError
+		if errors.As(err, &conflictErr) {
+			s.respondChapterConflict(c, conflictErr.Latest)
+			return
+		}
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
 			s.logger.Info("Chapter/Project not found or access denied for updateChapter", "chapterID", chapterID, "projectID", projectID)
 			response.NotFound(c, "Chapter or project not found, or access denied.")
 			return
 		}
+		if errors.Is(err, services.ErrChapterInReview) || errors.Is(err, services.ErrReviewIsRequired) {
+			response.Conflict(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrEmptyAfterSanitizing) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		s.logger.Error("Failed to update chapter", "chapterID", chapterID, "error", err)
 		response.InternalServerError(c, "Failed to update chapter", err)
 		return
 	}
+	c.Header("ETag", chapterETag(updatedChapter))
 	response.Ok(c, apimodels.ToChapterResponse(updatedChapter), "Chapter updated successfully")
 }
 
+// respondChapterConflict answers 409 with the latest chapter so the client can merge and retry
+func (s *Server) respondChapterConflict(c *gin.Context, latest sqlc.Chapter) {
+	s.logger.Info("Rejected stale chapter update", "chapterID", uuid.UUID(latest.ID.Bytes), "currentVersion", latest.Version)
+	c.Header("ETag", chapterETag(latest))
+	response.Conflict(c, services.ErrChapterConflict.Error(), apimodels.ToChapterResponse(latest))
+}
+
 func (s *Server) generateChapterContentHandler(c *gin.Context) {
 	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
 	projectIDStr := c.Param("project_id")
@@ -274,12 +554,54 @@ func (s *Server) generateChapterContentHandler(c *gin.Context) {
 		response.InternalServerError(c, "Failed to retrieve chapter", err)
 		return
 	}
-	chapter, err := s.researchService.GenerateChapterContent(c.Request.Context(), projectID, chapterID, authPayload.UserID, chapterCheck.Type)
+	// ?reference_tag= limits a literature review to the references with that tag
+	gen := services.GenerationOptions{ReferenceTag: c.Query("reference_tag")}
+	// ?regenerate=true asks for a new draft rather than a reply reused from moments ago
+	if gen.Regenerate, err = parseBoolQuery(c, "regenerate", false); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if gen.ReferenceTag != "" && chapterCheck.Type != "literature_review" {
+		response.BadRequest(c, "reference_tag only applies to literature review chapters")
+		return
+	}
+	chapter, err := s.researchService.GenerateChapterContent(c.Request.Context(), projectID, chapterID, authPayload.UserID, chapterCheck.Type, gen)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReferenceTag) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
 			response.NotFound(c, "Chapter or project not found for content generation.")
 			return
 		}
+		if errors.Is(err, services.ErrIntegrityAcknowledgmentRequired) {
+			s.respondIntegrityAcknowledgmentRequired(c)
+			return
+		}
+		if errors.Is(err, services.ErrGenerationRetrying) {
+			response.RespondError(c, http.StatusAccepted, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrChapterInReview) || errors.Is(err, services.ErrReviewIsRequired) ||
+			errors.Is(err, services.ErrNoResultsData) || errors.Is(err, services.ErrNoTaggedReferences) ||
+			errors.Is(err, services.ErrUnknownChapterType) {
+			response.Conflict(c, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			response.PaymentRequired(c, err.Error())
+			return
+		}
+		var quotaErr *services.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			s.respondQuotaExceeded(c, quotaErr)
+			return
+		}
 		s.logger.Error("Failed to generate chapter content", "chapterID", chapterID, "type", chapterCheck.Type, "error", err)
 		response.InternalServerError(c, fmt.Sprintf("Failed to generate content for %s", chapterCheck.Type), err)
 		return
@@ -287,6 +609,302 @@ func (s *Server) generateChapterContentHandler(c *gin.Context) {
 	response.Ok(c, apimodels.ToChapterResponse(chapter), fmt.Sprintf("%s content generated successfully", chapterCheck.Type))
 }
 
+// --- Trash Handlers ---
+
+func (s *Server) deleteChapter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, errP := uuid.Parse(c.Param("project_id"))
+	chapterID, errC := uuid.Parse(c.Param("chapter_id"))
+	if errP != nil || errC != nil {
+		response.BadRequest(c, "Invalid project or chapter ID format")
+		return
+	}
+
+	err := s.researchService.DeleteChapter(c.Request.Context(), chapterID, projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
+		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
+			response.NotFound(c, "Chapter or project not found, or access denied.")
+			return
+		}
+		s.logger.Error("Failed to delete chapter", "chapterID", chapterID, "error", err)
+		response.InternalServerError(c, "Failed to delete chapter", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listProjectTrash(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		s.logger.Warn("Invalid project ID format in listProjectTrash", "projectID", projectIDStr, "error", err)
+		response.BadRequest(c, "Invalid project ID format")
+		return
+	}
+
+	chapters, references, err := s.researchService.GetProjectTrash(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			response.NotFound(c, services.ErrProjectNotFound.Error())
+			return
+		}
+		s.logger.Error("Failed to list project trash", "projectID", projectID, "error", err)
+		response.InternalServerError(c, "Failed to list trash", err)
+		return
+	}
+
+	trash := apimodels.TrashResponse{
+		Chapters:   make([]apimodels.ChapterResponse, 0, len(chapters)),
+		References: make([]apimodels.ReferenceResponse, 0, len(references)),
+	}
+	for _, ch := range chapters {
+		trash.Chapters = append(trash.Chapters, apimodels.ToChapterResponseWithOptions(ch, false))
+	}
+	for _, ref := range references {
+		trash.References = append(trash.References, apimodels.ToReferenceResponse(ref))
+	}
+	response.Ok(c, trash)
+}
+
+func (s *Server) restoreChapter(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, errP := uuid.Parse(c.Param("project_id"))
+	chapterID, errC := uuid.Parse(c.Param("chapter_id"))
+	if errP != nil || errC != nil {
+		response.BadRequest(c, "Invalid project or chapter ID format")
+		return
+	}
+
+	chapter, err := s.researchService.RestoreChapter(c.Request.Context(), chapterID, projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
+		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
+			response.NotFound(c, "Chapter not found in trash, or access denied.")
+			return
+		}
+		if errors.Is(err, services.ErrChapterAlreadyExists) {
+			response.RespondError(c, http.StatusConflict, services.ErrChapterAlreadyExists.Error())
+			return
+		}
+		s.logger.Error("Failed to restore chapter", "chapterID", chapterID, "error", err)
+		response.InternalServerError(c, "Failed to restore chapter", err)
+		return
+	}
+	response.Ok(c, apimodels.ToChapterResponse(chapter), "Chapter restored successfully")
+}
+
+func (s *Server) restoreReference(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, errP := uuid.Parse(c.Param("project_id"))
+	referenceID, errR := uuid.Parse(c.Param("reference_id"))
+	if errP != nil || errR != nil {
+		response.BadRequest(c, "Invalid project or reference ID format")
+		return
+	}
+
+	ref, err := s.researchService.RestoreReference(c.Request.Context(), referenceID, projectID, authPayload.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
+		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrReferenceNotFound) {
+			response.NotFound(c, "Reference not found in trash, or access denied.")
+			return
+		}
+		s.logger.Error("Failed to restore reference", "referenceID", referenceID, "error", err)
+		response.InternalServerError(c, "Failed to restore reference", err)
+		return
+	}
+	response.Ok(c, apimodels.ToReferenceResponse(ref), "Reference restored successfully")
+}
+
+// --- Search Handlers ---
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+func (s *Server) searchProject(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		s.logger.Warn("Invalid project ID format in searchProject", "projectID", projectIDStr, "error", err)
+		response.BadRequest(c, "Invalid project ID format")
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		response.BadRequest(c, "Query parameter q is required")
+		return
+	}
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+	}
+
+	results, err := s.researchService.SearchProject(c.Request.Context(), projectID, authPayload.UserID, query, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			response.NotFound(c, services.ErrProjectNotFound.Error())
+			return
+		}
+		s.logger.Error("Failed to search project", "projectID", projectID, "error", err)
+		response.InternalServerError(c, "Failed to search project", err)
+		return
+	}
+	response.Ok(c, results)
+}
+
+const (
+	defaultSimilarLimit  = 10
+	maxSimilarTextLength = 8000
+)
+
+func (s *Server) similarReferences(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		s.logger.Warn("Invalid project ID format in similarReferences", "projectID", projectIDStr, "error", err)
+		response.BadRequest(c, "Invalid project ID format")
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("text"))
+	if text == "" {
+		response.BadRequest(c, "Query parameter text is required")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxSimilarTextLength {
+		response.BadRequest(c, fmt.Sprintf("text must be at most %d characters", maxSimilarTextLength))
+		return
+	}
+	limit := defaultSimilarLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			response.BadRequest(c, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+	}
+
+	results, err := s.researchService.SimilarReferences(c.Request.Context(), projectID, authPayload.UserID, text, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound):
+			response.NotFound(c, services.ErrProjectNotFound.Error())
+		case errors.Is(err, services.ErrEmbeddingsDisabled):
+			response.RespondError(c, http.StatusServiceUnavailable, "Semantic search is not available")
+		default:
+			s.logger.Error("Failed to find similar references", "projectID", projectID, "error", err)
+			response.InternalServerError(c, "Failed to find similar references", err)
+		}
+		return
+	}
+	response.Ok(c, results)
+}
+
+// --- Query helpers ---
+
+// parseFieldsQuery parses ?fields=a,b,c and rejects names not in allowed
+func parseFieldsQuery(c *gin.Context, allowed []string) ([]string, error) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !slices.Contains(allowed, f) {
+			return nil, fmt.Errorf("unknown field %q, allowed: %s", f, strings.Join(allowed, ","))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func parseBoolQuery(c *gin.Context, name string, defaultValue bool) (bool, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %q", name, raw)
+	}
+	return v, nil
+}
+
+// --- ETag helpers ---
+
+// chaptersETag changes whenever a chapter is added, removed or updated (updated_at is bumped on every write)
+func chaptersETag(chapters []sqlc.Chapter) string {
+	parts := make([]string, 0, len(chapters)*2)
+	for _, ch := range chapters {
+		parts = append(parts, uuid.UUID(ch.ID.Bytes).String(), ch.UpdatedAt.Time.Format(time.RFC3339Nano))
+	}
+	return response.WeakETag(parts...)
+}
+
+func chapterETag(chapter sqlc.Chapter) string {
+	return chaptersETag([]sqlc.Chapter{chapter})
+}
+
+// chapterViewETag is the ETag of GET chapter: the chapter's own with its open comment count
+// appended, so opening or resolving a comment invalidates it
+func chapterViewETag(chapter sqlc.Chapter, openComments int64) string {
+	return strings.TrimSuffix(chapterETag(chapter), `"`) + "." + strconv.FormatInt(openComments, 10) + `"`
+}
+
+// chapterIfMatch reports whether an If-Match header names the chapter as it is now. The
+// comment count in ETags from GET chapter is ignored; a new comment is no reason to
+// reject an edit.
+func chapterIfMatch(header string, chapter sqlc.Chapter) bool {
+	if response.ETagMatches(header, chapterETag(chapter)) {
+		return true
+	}
+	prefix := strings.TrimSuffix(strings.TrimPrefix(chapterETag(chapter), "W/"), `"`) + "."
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.HasPrefix(strings.TrimPrefix(strings.TrimSpace(candidate), "W/"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentCountsETag folds open comment counts into list ETags, so resolving a thread invalidates them
+func commentCountsETag(counts map[uuid.UUID]int64) string {
+	parts := make([]string, 0, len(counts))
+	for id, n := range counts {
+		parts = append(parts, id.String()+"="+strconv.FormatInt(n, 10))
+	}
+	sort.Strings(parts)
+	return response.WeakETag(parts...)
+}
+
+func projectETag(project sqlc.ResearchProject, chapters []sqlc.Chapter) string {
+	return response.WeakETag(uuid.UUID(project.ID.Bytes).String(), project.UpdatedAt.Time.Format(time.RFC3339Nano), chaptersETag(chapters))
+}
+
 // --- Reference Handlers ---
 func (s *Server) createReference(c *gin.Context) {
 	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
@@ -304,8 +922,12 @@ func (s *Server) createReference(c *gin.Context) {
 	}
 	req.ProjectID = projectID // Ensure project ID from path is used
 
-	ref, err := s.researchService.CreateReference(c.Request.Context(), authPayload.UserID, req)
+	ref, merged, err := s.researchService.CreateReference(c.Request.Context(), authPayload.UserID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) {
 			response.NotFound(c, services.ErrProjectNotFound.Error())
 			return
@@ -314,6 +936,10 @@ func (s *Server) createReference(c *gin.Context) {
 		response.InternalServerError(c, "Failed to create reference", err)
 		return
 	}
+	if merged {
+		response.Ok(c, apimodels.ToReferenceResponse(ref), "Reference merged into an existing duplicate")
+		return
+	}
 	response.Created(c, apimodels.ToReferenceResponse(ref), "Reference created successfully")
 }
 
@@ -326,8 +952,13 @@ func (s *Server) listProjectReferences(c *gin.Context) {
 		return
 	}
 
-	refs, err := s.researchService.GetProjectReferences(c.Request.Context(), projectID, authPayload.UserID)
+	// ?tag= may repeat; references carrying any of the tags are listed
+	refs, err := s.researchService.ListReferences(c.Request.Context(), projectID, authPayload.UserID, c.QueryArray("tag"))
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReferenceTag) {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) {
 			response.NotFound(c, services.ErrProjectNotFound.Error())
 			return
@@ -336,12 +967,7 @@ func (s *Server) listProjectReferences(c *gin.Context) {
 		response.InternalServerError(c, "Failed to retrieve references", err)
 		return
 	}
-
-	var refResponses []apimodels.ReferenceResponse
-	for _, r := range refs {
-		refResponses = append(refResponses, apimodels.ToReferenceResponse(r))
-	}
-	response.Ok(c, refResponses)
+	response.Ok(c, refs)
 }
 
 func (s *Server) deleteReference(c *gin.Context) {
@@ -358,6 +984,10 @@ func (s *Server) deleteReference(c *gin.Context) {
 
 	err := s.researchService.DeleteReference(c.Request.Context(), referenceID, projectID, authPayload.UserID)
 	if err != nil {
+		if errors.Is(err, services.ErrInsufficientRole) {
+			response.Forbidden(c, services.ErrInsufficientRole.Error())
+			return
+		}
 		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrReferenceNotFound) {
 			response.NotFound(c, "Project or reference not found, or access denied.")
 			return
@@ -389,72 +1019,162 @@ func (s *Server) generateDocumentHandler(c *gin.Context) {
 
 	doc, err := s.researchService.GenerateDocument(c.Request.Context(), projectID, authPayload.UserID)
 	if err != nil {
-		if errors.Is(err, services.ErrProjectNotFound) {
-			response.NotFound(c, services.ErrProjectNotFound.Error())
-			return
-		}
-		s.logger.Error("Failed to initiate document generation", "projectID", projectID, "error", err)
-		response.InternalServerError(c, "Failed to generate document", err)
+		s.respondGenerateDocumentError(c, projectID, "document", err)
 		return
 	}
-	response.Ok(c, apimodels.ToGeneratedDocumentResponse(doc), "Document generation initiated")
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGeneratedDocumentResponse(doc), "Document generation queued")
 }
 
-func (s *Server) downloadDocumentHandler(c *gin.Context) {
-	_ = c.MustGet(authorizationPayloadKey).(*token.Payload)
-	projectIDStr := c.Param("project_id") // Not strictly needed if documentID is globally unique and has projectID
-	projectID, errP := uuid.Parse(projectIDStr)
-	_ = projectID // To avoid unused variable error
-	documentIDStr := c.Param("document_id")
-	documentID, errD := uuid.Parse(documentIDStr)
-
-	if errP != nil || errD != nil {
-		response.BadRequest(c, "Invalid project or document ID format")
+// generatePresentationHandler queues a PPTX defense presentation written from the chapters
+func (s *Server) generatePresentationHandler(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	doc, err := s.researchService.GeneratePresentation(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondGenerateDocumentError(c, projectID, "presentation", err)
 		return
 	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGeneratedDocumentResponse(doc), "Presentation generation queued")
+}
 
-	doc, err := s.store.GetGeneratedDocumentByID(c.Request.Context(), pgtype.UUID{Bytes: documentID, Valid: true})
+// generatePosterHandler queues an A0 or A1 poster from the chapters; the body is optional
+func (s *Server) generatePosterHandler(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GeneratePosterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	doc, err := s.researchService.GeneratePoster(c.Request.Context(), projectID, authPayload.UserID, req)
 	if err != nil {
-		if errors.Is(err, services.ErrDocumentNotFound) {
-			response.NotFound(c, services.ErrDocumentNotFound.Error())
+		s.respondGenerateDocumentError(c, projectID, "poster", err)
+		return
+	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGeneratedDocumentResponse(doc), "Poster generation queued")
+}
+
+// generateArticleHandler queues a journal article condensed from the thesis; the body is optional
+func (s *Server) generateArticleHandler(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateArticleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
 			return
 		}
-		s.logger.Error("Failed to get document for download", "documentID", documentID, "error", err)
-		response.InternalServerError(c, "Could not retrieve document", err)
+	}
+	doc, err := s.researchService.GenerateArticle(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondGenerateDocumentError(c, projectID, "article", err)
 		return
 	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToGeneratedDocumentResponse(doc), "Article generation queued")
+}
 
-	if doc.Status.String != "completed" { // Assuming status is pgtype.Text or sql.NullString
-		response.RespondError(c, http.StatusAccepted, "Document is still processing or failed generation.")
+// respondGenerateDocumentError maps errors queueing a document of the given kind to responses
+func (s *Server) respondGenerateDocumentError(c *gin.Context, projectID uuid.UUID, kind string, err error) {
+	if errors.Is(err, services.ErrProjectNotFound) {
+		response.NotFound(c, services.ErrProjectNotFound.Error())
 		return
 	}
+	if errors.Is(err, services.ErrInsufficientRole) {
+		response.Forbidden(c, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrNoThesisContent) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrIntegrityAcknowledgmentRequired) {
+		s.respondIntegrityAcknowledgmentRequired(c)
+		return
+	}
+	var quotaErr *services.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		s.respondQuotaExceeded(c, quotaErr)
+		return
+	}
+	s.logger.Error("Failed to initiate "+kind+" generation", "projectID", projectID, "error", err)
+	response.InternalServerError(c, "Failed to generate "+kind, err)
+}
 
-	// This is a placeholder for actual file serving.
-	// In a real app, doc.FilePath would point to a location in S3, local disk, etc.
-	// You would then stream this file.
-	// For local disk (example only, not for production without security):
-	filePath := doc.FilePath // This might be an absolute path or relative to a base dir
+// downloadDocumentHandler streams a completed document through the API or, with
+// presigned downloads, redirects to a short-lived link to it in object storage
+func (s *Server) downloadDocumentHandler(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	documentID, ok := uuidParam(c, "document_id")
+	if !ok {
+		return
+	}
 
-	// Check if file exists - basic check
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		s.logger.Error("Document file not found on disk", "filePath", filePath, "documentID", doc.ID)
-		response.NotFound(c, "Document file not found on server.")
+	download, err := s.researchService.DownloadDocument(c.Request.Context(), projectID, documentID, authPayload.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrDocumentNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrInsufficientRole):
+			response.Forbidden(c, err.Error())
+		case errors.Is(err, services.ErrDocumentNotReady):
+			response.RespondError(c, http.StatusAccepted, err.Error())
+		default:
+			s.logger.Error("Failed to get document for download", "documentID", documentID, "error", err)
+			response.InternalServerError(c, "Could not retrieve document", err)
+		}
 		return
 	}
 
-	// Set headers for download
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Transfer-Encoding", "binary")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", doc.FileName))
-	if doc.MimeType.Valid {
-		c.Header("Content-Type", doc.MimeType.String)
+	if download.URL != "" {
+		c.Redirect(http.StatusFound, download.URL)
 	} else {
-		c.Header("Content-Type", "application/octet-stream") // Generic fallback
+		defer download.File.Close()
+		sendStoredFile(c, download.File, download.Document.MimeType.String, download.Document.FileName)
 	}
-	if doc.FileSize.Valid {
-		c.Header("Content-Length", fmt.Sprintf("%d", doc.FileSize.Int64))
+	s.logger.Info("Document downloaded", "documentID", documentID, "fileName", download.Document.FileName, "presigned", download.URL != "")
+}
+
+// downloadProjectBundle streams the project as a ZIP archive. The archive is written as
+// it is built, so an error partway through can only cut it short.
+func (s *Server) downloadProjectBundle(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	bundle, err := s.researchService.ProjectBundle(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProjectNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, services.ErrInsufficientRole):
+			response.Forbidden(c, err.Error())
+		default:
+			s.logger.Error("Failed to gather project bundle", "projectID", projectID, "error", err)
+			response.InternalServerError(c, "Could not prepare the download", err)
+		}
+		return
 	}
 
-	c.File(filePath)
-	s.logger.Info("Document downloaded", "documentID", doc.ID, "fileName", doc.FileName)
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", storage.AttachmentDisposition(bundle.FileName))
+	c.Status(http.StatusOK)
+	if err := bundle.Write(c.Request.Context(), c.Writer); err != nil {
+		s.logger.Error("Project bundle cut short", "projectID", projectID, "error", err)
+	}
 }
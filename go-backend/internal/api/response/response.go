@@ -1,7 +1,16 @@
 package response
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
 
 	"github.com/gin-gonic/gin"
 	// Alias to avoid clash if any
@@ -47,14 +56,92 @@ func NotFound(c *gin.Context, message string) {
 	RespondError(c, http.StatusNotFound, message)
 }
 
+// Conflict reports a lost update, returning the current state of the resource as data
+func Conflict(c *gin.Context, message string, latest interface{}) {
+	c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": message, "data": latest})
+}
+
+// PaymentRequired reports that the action needs a higher subscription plan
+func PaymentRequired(c *gin.Context, message string) {
+	RespondError(c, http.StatusPaymentRequired, message)
+}
+
+func TooManyRequests(c *gin.Context, message string) {
+	RespondError(c, http.StatusTooManyRequests, message)
+}
+
+// QuotaExceeded reports an action that would go past a usage quota, returning the quota's usage as data.
+// status is 402 when upgrading the plan would help and 429 when only waiting for the reset will.
+func QuotaExceeded(c *gin.Context, status int, message string, quota interface{}) {
+	c.AbortWithStatusJSON(status, gin.H{"error": message, "data": quota})
+}
+
+// FileRejected reports an uploaded file that failed validation, returning as data a
+// machine-readable code and the limit it broke
+func FileRejected(c *gin.Context, status int, message string, details interface{}) {
+	c.AbortWithStatusJSON(status, gin.H{"error": message, "data": details})
+}
+
 func InternalServerError(c *gin.Context, message string, err error) {
 	// Log the internal error
 	if err != nil {
 		c.Error(err) // Gin will handle logging this if middleware is set up
 	}
+	// A service behind an open circuit is down, not broken here; say so and when to retry
+	var open *breaker.OpenError
+	if errors.As(err, &open) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(open.RetryAfter.Seconds()))))
+		RespondError(c, http.StatusServiceUnavailable, open.Error())
+		return
+	}
+	// Work that ran past its deadline, such as an AI generation
+	if errors.Is(err, context.DeadlineExceeded) {
+		RespondError(c, http.StatusGatewayTimeout, message+": timed out")
+		return
+	}
 	RespondError(c, http.StatusInternalServerError, message)
 }
 
+// WeakETag builds a weak validator from the parts that identify a representation,
+// typically IDs and updated_at timestamps of every entity in the payload
+func WeakETag(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+}
+
+// ETagMatches implements the weak comparison used for If-None-Match and If-Match
+func ETagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// OkWithETag sets the ETag header and answers 304 Not Modified when the client
+// already holds this representation, otherwise it behaves like Ok
+func OkWithETag(c *gin.Context, etag string, data interface{}, message ...string) {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if ETagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	Ok(c, data, message...)
+}
+
 // Specific success responses
 func Ok(c *gin.Context, data interface{}, message ...string) {
 	RespondSuccess(c, http.StatusOK, data, message...)
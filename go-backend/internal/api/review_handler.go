@@ -0,0 +1,152 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondReviewError maps review workflow errors to responses
+func (s *Server) respondReviewError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrNoUserWithEmail):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole), errors.Is(err, services.ErrNotAdvisor):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrNoAdvisor),
+		errors.Is(err, services.ErrChapterInReview),
+		errors.Is(err, services.ErrNoPendingReview):
+		response.Conflict(c, err.Error(), nil)
+	case errors.Is(err, services.ErrAdvisorIsOwner), errors.Is(err, services.ErrEmptyChapter):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Review request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) setProjectAdvisor(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.SetAdvisorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	project, err := s.researchService.SetAdvisor(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondReviewError(c, "set advisor", err)
+		return
+	}
+	projectResp := apimodels.ToProjectResponse(project)
+	projectResp.AccessRole = services.ProjectRoleOwner
+	response.Ok(c, projectResp, "Advisor assigned successfully")
+}
+
+func (s *Server) removeProjectAdvisor(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.RemoveAdvisor(c.Request.Context(), projectID, authPayload.UserID); err != nil {
+		s.respondReviewError(c, "remove advisor", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) submitChapterForReview(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.SubmitChapterRequest
+	// The note is optional, so an empty body is fine
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	review, err := s.researchService.SubmitChapterForReview(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondReviewError(c, "submit chapter for review", err)
+		return
+	}
+	response.Created(c, apimodels.ToReviewResponse(review), "Chapter submitted for review")
+}
+
+func (s *Server) decideChapterReview(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	var req apimodels.ReviewDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	review, err := s.researchService.DecideReview(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondReviewError(c, "record review decision", err)
+		return
+	}
+	response.Ok(c, apimodels.ToReviewResponse(review), "Review decision recorded")
+}
+
+func (s *Server) listChapterReviews(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	chapterID, ok := uuidParam(c, "chapter_id")
+	if !ok {
+		return
+	}
+	reviews, err := s.researchService.ListChapterReviews(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondReviewError(c, "list reviews", err)
+		return
+	}
+	resp := make([]apimodels.ReviewResponse, len(reviews))
+	for i, r := range reviews {
+		resp[i] = apimodels.ToReviewResponse(r)
+	}
+	response.Ok(c, resp, "Reviews retrieved successfully")
+}
+
+func (s *Server) reviewQueue(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	queue, err := s.researchService.ReviewQueue(c.Request.Context(), authPayload.UserID)
+	if err != nil {
+		s.respondReviewError(c, "load review queue", err)
+		return
+	}
+	resp := make([]apimodels.ReviewQueueItemResponse, len(queue))
+	for i, item := range queue {
+		resp[i] = apimodels.ToReviewQueueItemResponse(item)
+	}
+	response.Ok(c, resp, "Review queue retrieved successfully")
+}
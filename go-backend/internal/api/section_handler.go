@@ -0,0 +1,206 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// respondSectionError maps chapter section errors to responses
+func (s *Server) respondSectionError(c *gin.Context, action string, err error) {
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrSectionNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrEmptyAfterSanitizing), errors.Is(err, services.ErrInvalidSectionOrder):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrChapterInReview):
+		response.Conflict(c, err.Error(), nil)
+	case errors.Is(err, services.ErrIntegrityAcknowledgmentRequired):
+		s.respondIntegrityAcknowledgmentRequired(c)
+	case errors.Is(err, services.ErrGenerationRetrying):
+		response.RespondError(c, http.StatusAccepted, err.Error())
+	case errors.Is(err, services.ErrQuotaExceeded):
+		response.PaymentRequired(c, err.Error())
+	case errors.As(err, &quotaErr):
+		s.respondQuotaExceeded(c, quotaErr)
+	default:
+		s.logger.Error("Section request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// sectionParams reads the project and chapter IDs of a section route
+func sectionParams(c *gin.Context) (projectID, chapterID uuid.UUID, ok bool) {
+	if projectID, ok = uuidParam(c, "project_id"); !ok {
+		return
+	}
+	chapterID, ok = uuidParam(c, "chapter_id")
+	return
+}
+
+func toSectionResponses(sections []sqlc.ChapterSection) []apimodels.SectionResponse {
+	out := make([]apimodels.SectionResponse, 0, len(sections))
+	for _, sec := range sections {
+		out = append(out, apimodels.ToSectionResponse(sec))
+	}
+	return out
+}
+
+func (s *Server) listSections(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	sections, err := s.researchService.ListSections(c.Request.Context(), projectID, chapterID, authPayload.UserID)
+	if err != nil {
+		s.respondSectionError(c, "retrieve sections", err)
+		return
+	}
+	response.Ok(c, toSectionResponses(sections))
+}
+
+func (s *Server) createSection(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	var req apimodels.CreateSectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	section, err := s.researchService.CreateSection(c.Request.Context(), projectID, chapterID, authPayload.UserID, req)
+	if err != nil {
+		s.respondSectionError(c, "create section", err)
+		return
+	}
+	response.Created(c, apimodels.ToSectionResponse(section), "Section created successfully")
+}
+
+func (s *Server) reorderSections(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	var req apimodels.ReorderSectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	sections, err := s.researchService.ReorderSections(c.Request.Context(), projectID, chapterID, authPayload.UserID, req.SectionIDs)
+	if err != nil {
+		s.respondSectionError(c, "reorder sections", err)
+		return
+	}
+	response.Ok(c, toSectionResponses(sections), "Sections reordered successfully")
+}
+
+func (s *Server) getSection(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	sectionID, ok := uuidParam(c, "section_id")
+	if !ok {
+		return
+	}
+	includeHTML, err := parseBoolQuery(c, "include_html", false)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	section, err := s.researchService.GetSection(c.Request.Context(), projectID, chapterID, sectionID, authPayload.UserID)
+	if err != nil {
+		s.respondSectionError(c, "retrieve section", err)
+		return
+	}
+	resp := apimodels.ToSectionResponse(section)
+	if includeHTML {
+		resp.ContentHTML = markdown.ToHTML(section.Content)
+	}
+	response.Ok(c, resp)
+}
+
+func (s *Server) updateSection(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	sectionID, ok := uuidParam(c, "section_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateSectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	section, err := s.researchService.UpdateSection(c.Request.Context(), projectID, chapterID, sectionID, authPayload.UserID, req)
+	if err != nil {
+		s.respondSectionError(c, "update section", err)
+		return
+	}
+	response.Ok(c, apimodels.ToSectionResponse(section), "Section updated successfully")
+}
+
+func (s *Server) deleteSection(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	sectionID, ok := uuidParam(c, "section_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteSection(c.Request.Context(), projectID, chapterID, sectionID, authPayload.UserID); err != nil {
+		s.respondSectionError(c, "delete section", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) generateSection(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, chapterID, ok := sectionParams(c)
+	if !ok {
+		return
+	}
+	sectionID, ok := uuidParam(c, "section_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateSectionRequest
+	// The body is optional; without it the section is written from its title and chapter
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request payload", err.Error())
+			return
+		}
+	}
+	section, err := s.researchService.GenerateSection(c.Request.Context(), projectID, chapterID, sectionID, authPayload.UserID, req)
+	if err != nil {
+		s.respondSectionError(c, "generate section", err)
+		return
+	}
+	response.Ok(c, apimodels.ToSectionResponse(section), "Section content generated successfully")
+}
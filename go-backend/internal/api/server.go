@@ -1,16 +1,22 @@
 package api
 
 import (
+	"strings"
 	"time"
 
 	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/flags"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
 	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/ratelimit"
 	"github.com/shawgichan/research-service/go-backend/internal/services"
 	"github.com/shawgichan/research-service/go-backend/internal/token"
 	"github.com/shawgichan/research-service/go-backend/internal/util"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
@@ -18,8 +24,16 @@ type Server struct {
 	store           db.Store
 	authService     *services.AuthService
 	researchService *services.ResearchService
+	orgService      *services.OrganizationService
+	notifications   *services.NotificationService
+	billing         *services.BillingService
+	quotas          *services.QuotaService
+	jobs            *jobs.Queue
 	aiService       *services.AIService
 	tokenMaker      token.Maker
+	rateLimiter     ratelimit.Limiter
+	eventBus        *events.Bus
+	flags           *flags.Flags
 	logger          *applogger.AppLogger
 	Router          *gin.Engine
 }
@@ -29,8 +43,16 @@ func NewServer(
 	store db.Store,
 	authService *services.AuthService,
 	researchService *services.ResearchService,
+	orgService *services.OrganizationService,
+	notificationService *services.NotificationService,
+	billingService *services.BillingService,
+	quotaService *services.QuotaService,
+	jobQueue *jobs.Queue,
 	aiService *services.AIService,
 	tokenMaker token.Maker,
+	rateLimiter ratelimit.Limiter,
+	eventBus *events.Bus,
+	featureFlags *flags.Flags,
 	logger *applogger.AppLogger,
 ) *Server {
 	server := &Server{
@@ -38,17 +60,34 @@ func NewServer(
 		store:           store,
 		authService:     authService,
 		researchService: researchService,
+		orgService:      orgService,
+		notifications:   notificationService,
+		billing:         billingService,
+		quotas:          quotaService,
+		jobs:            jobQueue,
 		aiService:       aiService,
 		tokenMaker:      tokenMaker,
+		rateLimiter:     rateLimiter,
+		eventBus:        eventBus,
+		flags:           featureFlags,
 		logger:          logger,
 	}
 
 	router := gin.New() // Use gin.New() for more control over middleware
 
+	// Client IPs, which rate limiting is keyed on, only come from headers that can't be spoofed
+	if err := router.SetTrustedProxies(config.TrustedProxyList()); err != nil { // Validated with the config
+		logger.Error("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+	router.TrustedPlatform = trustedPlatformHeader(config.TrustedPlatform)
+
 	// Global Middleware
-	router.Use(gin.Recovery()) // Recover from any panics
-	// Custom logger middleware can be added here if Gin's default is not sufficient
-	router.Use(CORSMiddleware()) // CORS
+	router.Use(requestIDMiddleware())                      // X-Request-ID propagation
+	router.Use(accessLogMiddleware(logger))                // Structured access log (outside Recovery so panics are logged as 500s)
+	router.Use(gin.Recovery())                             // Recover from any panics
+	router.Use(otelgin.Middleware(config.OTelServiceName)) // Server span per request
+	router.Use(CORSMiddleware())                           // CORS
 
 	server.Router = router
 	server.setupRoutes()
@@ -58,10 +97,22 @@ func NewServer(
 func (s *Server) setupRoutes() {
 	router := s.Router
 
-	// Health check
-	router.GET("/health", s.healthCheckHandler)
+	// Health checks
+	router.GET("/health", s.livenessHandler) // Kept for existing probes
+	router.GET("/healthz", s.livenessHandler)
+	router.GET("/readyz", s.readinessHandler)
+
+	// Generation progress events (browsers send the access token as a subprotocol, see wsBearerProtocol)
+	router.GET("/ws/projects/:project_id", wsAccessTokenMiddleware(), authMiddleware(s.tokenMaker), s.projectEventsWebSocket)
 
 	v1 := router.Group("/api/v1")
+	if s.config.RateLimitEnabled {
+		v1.Use(rateLimitMiddleware(s.rateLimiter, s.logger))
+	}
+
+	// API documentation (spec generated from internal/api/docs)
+	v1.GET("/openapi.json", s.openAPISpecHandler)
+	v1.GET("/docs", s.apiDocsHandler)
 
 	// Authentication routes
 	authRoutes := v1.Group("/auth")
@@ -69,6 +120,9 @@ func (s *Server) setupRoutes() {
 		authRoutes.POST("/register", s.registerUser)
 		authRoutes.POST("/login", s.loginUser)
 		authRoutes.POST("/refresh-token", s.refreshToken)
+		if s.config.GuestModeEnabled {
+			authRoutes.POST("/guest", s.createGuest)
+		}
 		// Logout needs to be authenticated to identify the session to invalidate
 		// authRoutes.POST("/logout", authMiddleware(s.tokenMaker), s.logoutUser)
 	}
@@ -79,10 +133,39 @@ func (s *Server) setupRoutes() {
 	// Logout (needs to be authenticated to know which session to end)
 	authRequired.POST("/auth/logout", s.logoutUser)
 
+	// Registering a guest keeps its projects
+	authRequired.POST("/auth/guest/register", s.registerGuest)
+
+	// Effective feature flags, so clients can adapt their UI
+	authRequired.GET("/flags", s.listFeatureFlags)
+
 	// User routes
 	userRoutes := v1.Group("/users").Use(authMiddleware(s.tokenMaker))
 	{
 		userRoutes.GET("/me", s.getCurrentUser)
+		userRoutes.GET("/me/quota", s.getQuota) // Usage against the plan's quotas this billing period
+		userRoutes.GET("/me/integrity-policy", s.getIntegrityPolicy)
+		userRoutes.POST("/me/integrity-policy/acknowledge", s.acknowledgeIntegrityPolicy)
+		// Temperature, reading level, English variant and verbosity for everything the user generates
+		userRoutes.GET("/me/generation-preferences", s.getUserGenerationPreferences)
+		userRoutes.PUT("/me/generation-preferences", s.updateUserGenerationPreferences)
+		userRoutes.GET("/me/notifications", s.listNotifications)
+		userRoutes.POST("/me/notifications/read-all", s.markAllNotificationsRead)
+		userRoutes.POST("/me/notifications/:notification_id/read", s.markNotificationRead)
+		userRoutes.GET("/me/notification-preferences", s.listNotificationPreferences)
+		userRoutes.PUT("/me/notification-preferences/:type", s.updateNotificationPreference)
+	}
+
+	// AI writing tools not tied to a project; they count against the user's own plan
+	aiRoutes := v1.Group("/ai").Use(authMiddleware(s.tokenMaker))
+	{
+		aiRoutes.POST("/interpret-analysis", s.idempotencyMiddleware(), s.interpretAnalysis)
+	}
+
+	// Literature search on Semantic Scholar
+	paperRoutes := v1.Group("/papers").Use(authMiddleware(s.tokenMaker))
+	{
+		paperRoutes.GET("/search", s.searchPapers)
 	}
 
 	// Project routes
@@ -90,25 +173,262 @@ func (s *Server) setupRoutes() {
 	{
 		projectRoutes.POST("", s.createProject)
 		projectRoutes.GET("", s.listUserProjects)
+		projectRoutes.POST("/import/docx", s.importThesisDOCX) // A thesis already under way
+		projectRoutes.POST("/import/latex", s.importThesisLaTeX)
 		projectRoutes.GET("/:project_id", s.getProject)
 		projectRoutes.PUT("/:project_id", s.updateProject)
 		projectRoutes.DELETE("/:project_id", s.deleteProject)
 
+		// Sharing with individual users (read, comment or edit)
+		projectRoutes.GET("/:project_id/collaborators", s.listProjectCollaborators)
+		projectRoutes.POST("/:project_id/collaborators", s.addProjectCollaborator)
+		projectRoutes.DELETE("/:project_id/collaborators/:user_id", s.removeProjectCollaborator) // Also used to leave
+
+		// Outbound webhooks (owner only)
+		projectRoutes.GET("/:project_id/webhooks", s.listProjectWebhooks)
+		projectRoutes.POST("/:project_id/webhooks", s.createProjectWebhook)
+		projectRoutes.PUT("/:project_id/webhooks/:webhook_id", s.updateProjectWebhook)
+		projectRoutes.DELETE("/:project_id/webhooks/:webhook_id", s.deleteProjectWebhook)
+		projectRoutes.GET("/:project_id/webhooks/:webhook_id/deliveries", s.listWebhookDeliveries)
+		projectRoutes.POST("/:project_id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", s.redeliverWebhook)
+
+		// Advisor review workflow: submit -> approve or request changes
+		projectRoutes.PUT("/:project_id/advisor", s.setProjectAdvisor)
+		projectRoutes.DELETE("/:project_id/advisor", s.removeProjectAdvisor)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/submit", s.submitChapterForReview)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/review", s.decideChapterReview)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/reviews", s.listChapterReviews) // Audit trail
+
 		// Nested Chapter routes under projects
 		projectRoutes.POST("/:project_id/chapters", s.createChapter)
 		projectRoutes.GET("/:project_id/chapters", s.listProjectChapters)
+		projectRoutes.POST("/:project_id/chapters/bulk", s.bulkSaveChapters)
+		projectRoutes.PUT("/:project_id/chapters/reorder", s.reorderChapters) // Order of chapters in the thesis
+		// Chapter types: built-in, the organization's, and those added for the project
+		// (a theoretical framework, a case study...), generated from each chapter's custom prompt
+		projectRoutes.GET("/:project_id/chapter-types", s.listProjectChapterTypes)
+		projectRoutes.POST("/:project_id/chapter-types", s.createProjectChapterType)
+		projectRoutes.DELETE("/:project_id/chapter-types/:key", s.deleteProjectChapterType)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id", s.getChapter)
 		projectRoutes.PUT("/:project_id/chapters/:chapter_id", s.updateChapter)
-		projectRoutes.POST("/:project_id/chapters/:chapter_id/generate-content", s.generateChapterContentHandler)
-		// DELETE chapter: projectRoutes.DELETE("/:project_id/chapters/:chapter_id", s.deleteChapter)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/generate-content", s.idempotencyMiddleware(), s.generateChapterContentHandler)
+		projectRoutes.DELETE("/:project_id/chapters/:chapter_id", s.deleteChapter) // Moves to trash
+		// Every chapter generated in one background run, in dependency order; approved chapters are kept
+		projectRoutes.POST("/:project_id/generate-all", s.idempotencyMiddleware(), s.generateAllChapters)
+		projectRoutes.GET("/:project_id/generate-all/:batch_id", s.getGenerationBatch) // Progress of each chapter
+
+		// Sections: sub-chapters numbered under their chapter (2.1, 2.1.1...) in generated documents
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/sections", s.listSections)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/sections", s.createSection)
+		projectRoutes.PUT("/:project_id/chapters/:chapter_id/sections/reorder", s.reorderSections)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/sections/:section_id", s.getSection)
+		projectRoutes.PUT("/:project_id/chapters/:chapter_id/sections/:section_id", s.updateSection)
+		projectRoutes.DELETE("/:project_id/chapters/:chapter_id/sections/:section_id", s.deleteSection)
+		// Rewrites one section without regenerating (and paying for) the whole chapter
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/sections/:section_id/generate", s.idempotencyMiddleware(), s.generateSection)
+
+		// Autosaved drafts, one per editor, kept apart from the chapter until it is saved
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/draft", s.getChapterDraft)
+		projectRoutes.PATCH("/:project_id/chapters/:chapter_id/draft", s.saveChapterDraft)
+		projectRoutes.DELETE("/:project_id/chapters/:chapter_id/draft", s.discardChapterDraft)
+
+		// Version history, recorded on every save; diffs show what a regeneration changed
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/versions", s.listChapterVersions)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/versions/:a/diff/:b", s.diffChapterVersions)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/provenance", s.getChapterProvenance) // AI-written vs human-written text
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/ai-detection", s.detectAIContent)   // What AI detectors are likely to flag
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/metrics", s.getChapterMetrics)       // Readability, passive voice and hedging
+		// AI evaluation against a rubric, kept with the version evaluated
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/evaluations", s.idempotencyMiddleware(), s.evaluateChapter)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/evaluations", s.listChapterEvaluations)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/evaluations/:evaluation_id", s.getChapterEvaluation)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/generations", s.listChapterGenerations)
+
+		// Words written against chapter word targets
+		projectRoutes.GET("/:project_id/progress", s.getProjectProgress)
+
+		// Viva preparation from the written thesis
+		projectRoutes.POST("/:project_id/generate-defense-questions", s.idempotencyMiddleware(), s.generateDefenseQuestions)
+		projectRoutes.POST("/:project_id/generate-executive-summary", s.idempotencyMiddleware(), s.generateExecutiveSummary)
+
+		// Research timeline planned from the chapters and milestones, optionally rendered in documents
+		projectRoutes.POST("/:project_id/timeline/generate", s.idempotencyMiddleware(), s.generateProjectTimeline)
+		projectRoutes.GET("/:project_id/timeline", s.getProjectTimeline)
+		projectRoutes.PUT("/:project_id/timeline", s.updateProjectTimeline)
+		projectRoutes.DELETE("/:project_id/timeline", s.deleteProjectTimeline)
+
+		// Methodology questionnaire; methodology generation writes the chapter from the answers
+		projectRoutes.GET("/:project_id/methodology", s.getProjectMethodology)
+		projectRoutes.PUT("/:project_id/methodology", s.saveProjectMethodology)
+
+		// Questionnaires (sections of likert, choice and open items); included ones become appendices
+		projectRoutes.GET("/:project_id/questionnaires", s.listProjectQuestionnaires)
+		projectRoutes.POST("/:project_id/questionnaires", s.createProjectQuestionnaire)
+		projectRoutes.GET("/:project_id/questionnaires/:questionnaire_id", s.getProjectQuestionnaire)
+		projectRoutes.PUT("/:project_id/questionnaires/:questionnaire_id", s.updateProjectQuestionnaire)
+		projectRoutes.DELETE("/:project_id/questionnaires/:questionnaire_id", s.deleteProjectQuestionnaire)
+		projectRoutes.POST("/:project_id/questionnaires/:questionnaire_id/generate-items", s.idempotencyMiddleware(), s.generateQuestionnaireItems)
+
+		// Datasets (CSV study results); generating the results chapter narrates their statistics
+		projectRoutes.POST("/:project_id/datasets", s.uploadProjectDataset)
+		projectRoutes.GET("/:project_id/datasets", s.listProjectDatasets)
+		projectRoutes.GET("/:project_id/datasets/:dataset_id", s.getProjectDataset)
+		projectRoutes.DELETE("/:project_id/datasets/:dataset_id", s.deleteProjectDataset)
+
+		// Comment threads anchored to chapter text (creating needs the comment role)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/comments", s.listChapterComments)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/comments", s.createCommentThread)
+		projectRoutes.POST("/:project_id/comments/:thread_id/replies", s.replyToCommentThread)
+		projectRoutes.POST("/:project_id/comments/:thread_id/resolve", s.resolveCommentThread)
+		projectRoutes.POST("/:project_id/comments/:thread_id/reopen", s.reopenCommentThread)
+
+		// Figures and tables placed in chapters by [[figure:<id>]] / [[table:<id>]] markers
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/figures", s.listChapterFigures)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/figures", s.addChapterFigure)
+		projectRoutes.POST("/:project_id/chapters/:chapter_id/tables", s.addChapterTable)
+		projectRoutes.PUT("/:project_id/chapters/:chapter_id/figures/:figure_id", s.updateChapterFigure)
+		projectRoutes.DELETE("/:project_id/chapters/:chapter_id/figures/:figure_id", s.deleteChapterFigure)
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/figures/:figure_id/image", s.getChapterFigureImage)
 
 		// Nested Reference routes under projects
 		projectRoutes.POST("/:project_id/references", s.createReference)
 		projectRoutes.GET("/:project_id/references", s.listProjectReferences)
-		projectRoutes.DELETE("/:project_id/references/:reference_id", s.deleteReference)
+		projectRoutes.GET("/:project_id/references/export", s.exportReferences)                // CSL-JSON or Zotero RDF
+		projectRoutes.GET("/:project_id/references/similar", s.similarReferences)              // Semantic search via embeddings
+		projectRoutes.GET("/:project_id/recommended-papers", s.recommendedPapers)              // Like the project's references, from Semantic Scholar
+		projectRoutes.POST("/:project_id/references/import-papers", s.importPapers)            // From Semantic Scholar, by paper ID
+		projectRoutes.DELETE("/:project_id/references/:reference_id", s.deleteReference)       // Moves to trash
+		projectRoutes.GET("/:project_id/references/:reference_id/pdf", s.getReferencePDF)      // Open access PDF of an imported paper
+		projectRoutes.GET("/:project_id/references/:reference_id/cited-papers", s.citedPapers) // Citation graph via Semantic Scholar
+		projectRoutes.GET("/:project_id/references/:reference_id/citing-papers", s.citingPapers)
+		projectRoutes.GET("/:project_id/references/:reference_id/notes", s.getReferenceNotes)
+		projectRoutes.PUT("/:project_id/references/:reference_id/notes", s.updateReferenceNotes)
+		projectRoutes.PUT("/:project_id/references/:reference_id/tags", s.updateReferenceTags)
+		projectRoutes.POST("/:project_id/references/:reference_id/highlights", s.addReferenceHighlight)
+		projectRoutes.DELETE("/:project_id/references/:reference_id/highlights/:highlight_id", s.deleteReferenceHighlight)
+
+		// In-text (Author, Year) citations linked to the references they cite
+		projectRoutes.GET("/:project_id/chapters/:chapter_id/citations", s.listChapterCitations)
+
+		// Source PDFs; their extracted text feeds literature review generation
+		projectRoutes.POST("/:project_id/uploads", s.uploadProjectFile)
+		projectRoutes.GET("/:project_id/uploads", s.listProjectUploads)
+		projectRoutes.GET("/:project_id/uploads/:upload_id", s.getProjectUpload)
+		projectRoutes.DELETE("/:project_id/uploads/:upload_id", s.deleteProjectUpload)
+		// Resumable uploads: the file is sent in chunks, PATCHed at Upload-Offset, and
+		// becomes an upload once the last one arrives
+		projectRoutes.POST("/:project_id/upload-sessions", s.createUploadSession)
+		projectRoutes.GET("/:project_id/upload-sessions/:session_id", s.getUploadSession)
+		projectRoutes.PATCH("/:project_id/upload-sessions/:session_id", s.appendUploadChunk)
+		projectRoutes.DELETE("/:project_id/upload-sessions/:session_id", s.deleteUploadSession)
+
+		// Appendices, lettered by position after the references
+		projectRoutes.GET("/:project_id/appendices", s.listProjectAppendices)
+		projectRoutes.POST("/:project_id/appendices", s.createProjectAppendix)
+		projectRoutes.PUT("/:project_id/appendices/:appendix_id", s.updateProjectAppendix)
+		projectRoutes.DELETE("/:project_id/appendices/:appendix_id", s.deleteProjectAppendix)
+		projectRoutes.PUT("/:project_id/appendices/:appendix_id/file", s.uploadAppendixFile)
+		projectRoutes.GET("/:project_id/appendices/:appendix_id/file", s.getAppendixFile)
+
+		// Faculty formatting guideline; the rules read from it format generated documents
+		projectRoutes.POST("/:project_id/guideline", s.uploadProjectGuideline)
+		projectRoutes.GET("/:project_id/guideline", s.getProjectGuideline)
+		projectRoutes.DELETE("/:project_id/guideline", s.deleteProjectGuideline)
+		// Fonts, spacing, margins, headings and citation style of every document generated
+		projectRoutes.GET("/:project_id/formatting", s.getProjectFormatting)
+		projectRoutes.PUT("/:project_id/formatting", s.updateProjectFormatting)
+		// Generation preferences for the project, over each member's own
+		projectRoutes.GET("/:project_id/generation-preferences", s.getProjectGenerationPreferences)
+		projectRoutes.PUT("/:project_id/generation-preferences", s.updateProjectGenerationPreferences)
+		// Degree, supervisor, dedication... for the front-matter pages the formatting options list
+		projectRoutes.GET("/:project_id/front-matter", s.getProjectFrontMatter)
+		projectRoutes.PUT("/:project_id/front-matter", s.updateProjectFrontMatter)
+
+		// Trash (soft-deleted chapters and references, purged after TRASH_RETENTION)
+		projectRoutes.GET("/:project_id/trash", s.listProjectTrash)
+		projectRoutes.POST("/:project_id/trash/chapters/:chapter_id/restore", s.restoreChapter)
+		projectRoutes.POST("/:project_id/trash/references/:reference_id/restore", s.restoreReference)
+
+		// Full-text search across chapters and references
+		projectRoutes.GET("/:project_id/search", s.searchProject)
 
 		// Nested Document routes
-		projectRoutes.POST("/:project_id/documents/generate", s.generateDocumentHandler)
-		projectRoutes.GET("/:project_id/documents/:document_id/download", s.downloadDocumentHandler) // This would need file serving
+		projectRoutes.POST("/:project_id/documents/generate", s.idempotencyMiddleware(), s.generateDocumentHandler)
+		projectRoutes.POST("/:project_id/documents/presentation", s.idempotencyMiddleware(), s.generatePresentationHandler) // Defense slides as PPTX
+		projectRoutes.POST("/:project_id/documents/poster", s.idempotencyMiddleware(), s.generatePosterHandler)             // One-slide A0/A1 PPTX poster
+		projectRoutes.POST("/:project_id/documents/article", s.idempotencyMiddleware(), s.generateArticleHandler)           // IMRaD manuscript as DOCX
+		projectRoutes.GET("/:project_id/documents/:document_id/download", s.downloadDocumentHandler)
+		projectRoutes.GET("/:project_id/download-all", s.downloadProjectBundle) // ZIP of the latest document, chapters, references and sources
+	}
+
+	// Organizations: shared spaces whose members can all work on the organization's projects
+	orgRoutes := v1.Group("/organizations").Use(authMiddleware(s.tokenMaker))
+	{
+		orgRoutes.POST("", s.createOrganization)
+		orgRoutes.GET("", s.listOrganizations)
+		orgRoutes.GET("/:organization_id", s.getOrganization)
+		orgRoutes.GET("/:organization_id/members", s.listOrganizationMembers)
+		orgRoutes.DELETE("/:organization_id/members/:user_id", s.removeOrganizationMember) // Also used to leave
+		orgRoutes.GET("/:organization_id/projects", s.listOrganizationProjects)
+		orgRoutes.POST("/:organization_id/invitations", s.inviteOrganizationMember)
+		orgRoutes.GET("/:organization_id/invitations", s.listOrganizationInvitations)
+		orgRoutes.DELETE("/:organization_id/invitations/:invitation_id", s.revokeOrganizationInvitation)
+		// Chapter types the organization's projects can use besides the built-in ones
+		orgRoutes.GET("/:organization_id/chapter-types", s.listOrganizationChapterTypes)
+		orgRoutes.POST("/:organization_id/chapter-types", s.createOrganizationChapterType)
+		orgRoutes.PUT("/:organization_id/chapter-types/:key", s.updateOrganizationChapterType)
+		orgRoutes.DELETE("/:organization_id/chapter-types/:key", s.deleteOrganizationChapterType)
+	}
+
+	// Pending reviews of the projects the current user advises
+	authRequired.GET("/reviews/queue", s.reviewQueue)
+
+	// Invitations addressed to the current user
+	invitationRoutes := v1.Group("/invitations").Use(authMiddleware(s.tokenMaker))
+	{
+		invitationRoutes.GET("", s.listMyInvitations)
+		invitationRoutes.POST("/:invitation_id/accept", s.acceptInvitation)
+		invitationRoutes.DELETE("/:invitation_id", s.declineInvitation)
+	}
+
+	// Billing, only mounted when Stripe is configured. Stripe's webhook is signed rather than
+	// authenticated and sits outside /api/v1 so rate limiting can't drop events.
+	if s.billing.Enabled() {
+		billingRoutes := v1.Group("/billing").Use(authMiddleware(s.tokenMaker))
+		{
+			billingRoutes.GET("/plans", s.listBillingPlans)
+			billingRoutes.GET("/subscription", s.getSubscription)
+			billingRoutes.POST("/checkout", s.createCheckoutSession)
+			billingRoutes.POST("/portal", s.createBillingPortalSession)
+		}
+		router.POST("/webhooks/stripe", s.stripeWebhook)
+	}
+
+	// Operator endpoints, only mounted when ADMIN_API_TOKEN is set
+	if s.config.AdminAPIToken != "" {
+		adminRoutes := v1.Group("/admin").Use(adminMiddleware(s.config.AdminAPIToken))
+		{
+			adminRoutes.GET("/flags", s.adminListFeatureFlags)
+			adminRoutes.PUT("/flags/:name", s.adminSetFeatureFlag)
+			adminRoutes.DELETE("/flags/:name", s.adminResetFeatureFlag) // Back to env/default value
+			adminRoutes.GET("/log-levels", s.adminListLogLevels)
+			adminRoutes.PUT("/log-levels/:component", s.adminSetLogLevel)
+			adminRoutes.DELETE("/log-levels/:component", s.adminResetLogLevel) // Inherit from the parent component again
+			adminRoutes.GET("/jobs", s.adminListJobs)
+			adminRoutes.GET("/jobs/:job_id", s.adminGetJob)
+			adminRoutes.POST("/jobs/:job_id/requeue", s.adminRequeueJob) // Typically a dead job once its cause is fixed
+		}
+	}
+
+	// GraphQL (optional, read-only view over the same services)
+	if s.config.GraphQLEnabled {
+		graphqlHandler := s.newGraphQLHandler()
+		graphqlRoutes := v1.Group("/graphql")
+		graphqlRoutes.POST("", authMiddleware(s.tokenMaker), graphqlHandler)
+		graphqlRoutes.GET("", authMiddleware(s.tokenMaker), graphqlHandler)
+		if s.config.Environment == "development" {
+			graphqlRoutes.GET("/playground", s.graphQLPlaygroundHandler)
+		}
 	}
 }
 
@@ -118,13 +438,20 @@ func CORSMiddleware() gin.HandlerFunc {
 		// AllowOrigins:     []string{"http://localhost:3000", "https://your-frontend-domain.com"},
 		AllowAllOrigins:  true, // For development; be more restrictive in production
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "If-None-Match", "If-Match", requestIDHeaderKey, idempotencyKeyHeader, uploadOffsetHeader, uploadChecksumHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", requestIDHeaderKey, idempotencyReplayedHeader, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "Location", uploadOffsetHeader, uploadLengthHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	})
 }
 
-func (s *Server) healthCheckHandler(c *gin.Context) {
-	c.JSON(200, gin.H{"status": "ok"})
+// trustedPlatformHeader resolves TRUSTED_PLATFORM's shorthands to the header they set
+func trustedPlatformHeader(platform string) string {
+	switch strings.ToLower(platform) {
+	case "cloudflare":
+		return gin.PlatformCloudflare
+	case "google":
+		return gin.PlatformGoogleAppEngine
+	}
+	return platform
 }
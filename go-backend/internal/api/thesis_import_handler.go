@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// importThesisDOCX creates a project from a Word document: chapters are split at its
+// headings and its bibliography becomes references
+func (s *Server) importThesisDOCX(c *gin.Context) {
+	s.importThesis(c, "a DOCX", s.researchService.ImportDOCX)
+}
+
+// importThesisLaTeX creates a project from a ZIP of LaTeX sources: chapters are split at
+// \chapter, or \section, and the .bib entries become references
+func (s *Server) importThesisLaTeX(c *gin.Context) {
+	s.importThesis(c, "a ZIP of .tex and .bib files", s.researchService.ImportLaTeX)
+}
+
+type thesisImporter func(ctx context.Context, userID uuid.UUID, fileName string, r io.Reader, req apimodels.ImportThesisRequest) (services.ThesisImport, error)
+
+func (s *Server) importThesis(c *gin.Context, what string, importer thesisImporter) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	header, file, ok := s.formFile(c, what)
+	if !ok {
+		return
+	}
+	defer file.Close()
+	var req apimodels.ImportThesisRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, "Invalid form fields", err.Error())
+		return
+	}
+
+	imported, err := importer(c.Request.Context(), authPayload.UserID, header.Filename, file, req)
+	if err != nil {
+		if respondFileRejected(c, err) {
+			return
+		}
+		var quotaErr *services.QuotaExceededError
+		switch {
+		case errors.As(err, &quotaErr):
+			s.respondQuotaExceeded(c, quotaErr)
+		case errors.Is(err, services.ErrQuotaExceeded):
+			response.PaymentRequired(c, err.Error())
+		case errors.Is(err, services.ErrImportUnreadable),
+			errors.Is(err, services.ErrImportNoChapters),
+			errors.Is(err, services.ErrLaTeXNoDocument):
+			response.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		default:
+			s.logger.Error("Failed to import thesis", "userID", authPayload.UserID, "error", err)
+			response.InternalServerError(c, "Failed to import thesis", err)
+		}
+		return
+	}
+
+	resp := apimodels.ImportThesisResponse{
+		Project:    apimodels.ToProjectResponse(imported.Project),
+		Chapters:   make([]apimodels.ChapterResponse, len(imported.Chapters)),
+		References: make([]apimodels.ReferenceResponse, len(imported.References)),
+		Skipped:    imported.Skipped,
+	}
+	for i, chapter := range imported.Chapters {
+		resp.Chapters[i] = apimodels.ToChapterResponseWithOptions(chapter, false)
+	}
+	for i, ref := range imported.References {
+		resp.References[i] = apimodels.ToReferenceResponse(ref)
+	}
+	response.Created(c, resp, "Thesis imported successfully")
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondTimelineError maps research timeline errors to responses
+func (s *Server) respondTimelineError(c *gin.Context, action string, err error) {
+	var quotaErr *services.QuotaExceededError
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrTimelineNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidTimeline):
+		response.BadRequest(c, err.Error())
+	case errors.As(err, &quotaErr):
+		s.respondQuotaExceeded(c, quotaErr)
+	default:
+		s.logger.Error("Timeline request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) generateProjectTimeline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.GenerateTimelineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	timeline, err := s.researchService.GenerateTimeline(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondTimelineError(c, "generate timeline", err)
+		return
+	}
+	response.Ok(c, apimodels.ToTimelineResponse(timeline), "Timeline generated successfully")
+}
+
+func (s *Server) getProjectTimeline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	timeline, err := s.researchService.GetTimeline(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondTimelineError(c, "get timeline", err)
+		return
+	}
+	response.Ok(c, apimodels.ToTimelineResponse(timeline), "Timeline retrieved successfully")
+}
+
+func (s *Server) updateProjectTimeline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateTimelineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	timeline, err := s.researchService.SetTimelineInDocument(c.Request.Context(), projectID, authPayload.UserID, *req.IncludeInDocument)
+	if err != nil {
+		s.respondTimelineError(c, "update timeline", err)
+		return
+	}
+	response.Ok(c, apimodels.ToTimelineResponse(timeline), "Timeline updated successfully")
+}
+
+func (s *Server) deleteProjectTimeline(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteTimeline(c.Request.Context(), projectID, authPayload.UserID); err != nil {
+		s.respondTimelineError(c, "delete timeline", err)
+		return
+	}
+	response.NoContent(c)
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead allows for the form's boundaries and headers on top of the file itself
+const multipartOverhead = 1 << 20
+
+// formFile reads the file field of a multipart upload, cutting the body off past the
+// upload size limit. When there is no usable file it answers the request itself and
+// returns false; what names the expected content for the message.
+func (s *Server) formFile(c *gin.Context, what string) (*multipart.FileHeader, multipart.File, bool) {
+	maxSize := int64(s.config.UploadMaxSizeMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondFileRejected(c, services.NewUploadTooLargeError(maxSize))
+			return nil, nil, false
+		}
+		response.BadRequest(c, "Request must be multipart/form-data with "+what+" in the file field", err.Error())
+		return nil, nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		s.logger.Error("Failed to open uploaded file", "error", err)
+		response.InternalServerError(c, "Could not read the uploaded file", err)
+		return nil, nil, false
+	}
+	return header, file, true
+}
+
+// respondFileRejected answers for an upload that failed validation, the same way for
+// every file-accepting endpoint. It returns false for any other error.
+func respondFileRejected(c *gin.Context, err error) bool {
+	var uploadErr *services.UploadError
+	if !errors.As(err, &uploadErr) {
+		return false
+	}
+	status := http.StatusUnprocessableEntity // Empty, or too many pages
+	switch uploadErr.Code {
+	case services.UploadCodeTooLarge:
+		status = http.StatusRequestEntityTooLarge
+	case services.UploadCodeUnsupportedType:
+		status = http.StatusUnsupportedMediaType
+	}
+	response.FileRejected(c, status, uploadErr.Error(), uploadErr)
+	return true
+}
+
+// respondUploadError maps upload errors to responses
+func (s *Server) respondUploadError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrUploadNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrUploadExists):
+		response.RespondError(c, http.StatusConflict, err.Error())
+	default:
+		s.logger.Error("Upload request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// serveStoredFile streams a stored file to the client, as an attachment named
+// attachmentName when one is given
+func (s *Server) serveStoredFile(c *gin.Context, ref, mimeType, attachmentName string) {
+	file, err := s.researchService.OpenFile(c.Request.Context(), ref)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.logger.Error("Stored file is missing", "file", ref)
+			response.NotFound(c, "File not found")
+			return
+		}
+		s.logger.Error("Failed to open stored file", "file", ref, "error", err)
+		response.InternalServerError(c, "Could not read file", err)
+		return
+	}
+	defer file.Close()
+	sendStoredFile(c, file, mimeType, attachmentName)
+}
+
+func sendStoredFile(c *gin.Context, file *storage.Object, mimeType, attachmentName string) {
+	if mimeType == "" {
+		mimeType = file.ContentType
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	headers := map[string]string{}
+	if attachmentName != "" {
+		headers["Content-Disposition"] = storage.AttachmentDisposition(attachmentName)
+	}
+	c.DataFromReader(http.StatusOK, file.Size, mimeType, file, headers)
+}
+
+func (s *Server) uploadProjectFile(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	header, file, ok := s.formFile(c, "a PDF")
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	upload, err := s.researchService.UploadPDF(c.Request.Context(), projectID, authPayload.UserID, header.Filename, file)
+	if err != nil {
+		s.respondUploadError(c, "upload file", err)
+		return
+	}
+	response.RespondSuccess(c, http.StatusAccepted, apimodels.ToUploadResponse(upload), "File uploaded, text extraction queued")
+}
+
+func (s *Server) listProjectUploads(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	uploads, err := s.researchService.ListUploads(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondUploadError(c, "list uploads", err)
+		return
+	}
+	resp := make([]apimodels.UploadResponse, len(uploads))
+	for i, u := range uploads {
+		resp[i] = apimodels.ToUploadResponse(u)
+	}
+	response.Ok(c, resp, "Uploads retrieved successfully")
+}
+
+func (s *Server) getProjectUpload(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	uploadID, ok := uuidParam(c, "upload_id")
+	if !ok {
+		return
+	}
+	upload, text, err := s.researchService.GetUpload(c.Request.Context(), uploadID, projectID, authPayload.UserID)
+	if err != nil {
+		s.respondUploadError(c, "get upload", err)
+		return
+	}
+	resp := apimodels.ToUploadResponse(upload)
+	resp.Text = text
+	response.Ok(c, resp, "Upload retrieved successfully")
+}
+
+func (s *Server) deleteProjectUpload(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	uploadID, ok := uuidParam(c, "upload_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteUpload(c.Request.Context(), uploadID, projectID, authPayload.UserID); err != nil {
+		s.respondUploadError(c, "delete upload", err)
+		return
+	}
+	response.NoContent(c)
+}
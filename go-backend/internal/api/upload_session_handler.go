@@ -0,0 +1,161 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Headers of resumable uploads, named as in the tus protocol
+const (
+	uploadOffsetHeader   = "Upload-Offset"   // Where a chunk starts, and how much has been received
+	uploadLengthHeader   = "Upload-Length"   // Size of the whole file
+	uploadChecksumHeader = "Upload-Checksum" // "sha256 <base64 digest>" of a chunk
+)
+
+// respondUploadSessionError maps upload session errors to responses
+func (s *Server) respondUploadSessionError(c *gin.Context, action string, err error) {
+	if respondFileRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrUploadSessionNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrUploadOffsetMismatch),
+		errors.Is(err, services.ErrUploadSessionClosed):
+		response.RespondError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrUploadChunkTooLarge):
+		response.RespondError(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrUploadChunkChecksum):
+		response.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, services.ErrUploadChunkPastEnd),
+		errors.Is(err, services.ErrUploadChunkIncomplete):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Upload session request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+// uploadSessionResponse sets the tus headers alongside the session in the body
+func (s *Server) uploadSessionResponse(c *gin.Context, session sqlc.UploadSession) apimodels.UploadSessionResponse {
+	c.Header(uploadOffsetHeader, strconv.FormatInt(session.ReceivedSize, 10))
+	c.Header(uploadLengthHeader, strconv.FormatInt(session.TotalSize, 10))
+	return apimodels.ToUploadSessionResponse(session, int64(s.config.UploadChunkMaxSizeMB)<<20)
+}
+
+func (s *Server) createUploadSession(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateUploadSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	session, err := s.researchService.CreateUploadSession(c.Request.Context(), projectID, authPayload.UserID, req.FileName, req.Size, strings.ToLower(req.SHA256))
+	if err != nil {
+		s.respondUploadSessionError(c, "create upload session", err)
+		return
+	}
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+uuid.UUID(session.ID.Bytes).String())
+	response.Created(c, s.uploadSessionResponse(c, session), "Upload session created")
+}
+
+func (s *Server) getUploadSession(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	sessionID, ok := uuidParam(c, "session_id")
+	if !ok {
+		return
+	}
+	session, err := s.researchService.GetUploadSession(c.Request.Context(), sessionID, projectID, authPayload.UserID)
+	if err != nil {
+		s.respondUploadSessionError(c, "get upload session", err)
+		return
+	}
+	response.Ok(c, s.uploadSessionResponse(c, session), "Upload session retrieved successfully")
+}
+
+// appendUploadChunk takes the raw bytes of the next chunk as the request body, starting
+// at Upload-Offset. A 409 means the offset is stale; fetch the session to resume.
+func (s *Server) appendUploadChunk(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	sessionID, ok := uuidParam(c, "session_id")
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader(uploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		response.BadRequest(c, uploadOffsetHeader+" must be the byte offset the chunk starts at")
+		return
+	}
+	if c.Request.ContentLength < 0 {
+		response.RespondError(c, http.StatusLengthRequired, "Chunks must be sent with a Content-Length")
+		return
+	}
+	var checksum []byte
+	if value := c.GetHeader(uploadChecksumHeader); value != "" {
+		algorithm, digest, _ := strings.Cut(value, " ")
+		sum, err := base64.StdEncoding.DecodeString(digest)
+		if algorithm != "sha256" || err != nil || len(sum) != sha256.Size {
+			response.BadRequest(c, uploadChecksumHeader+" must be \"sha256 <base64 digest>\"")
+			return
+		}
+		checksum = sum
+	}
+
+	maxChunkSize := int64(s.config.UploadChunkMaxSizeMB) << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxChunkSize)
+	session, err := s.researchService.AppendUploadChunk(c.Request.Context(), sessionID, projectID, authPayload.UserID, offset, c.Request.ContentLength, c.Request.Body, checksum)
+	if err != nil {
+		s.respondUploadSessionError(c, "upload chunk", err)
+		return
+	}
+	if session.Status == services.UploadSessionAssembling {
+		response.RespondSuccess(c, http.StatusAccepted, s.uploadSessionResponse(c, session), "File received, assembly queued")
+		return
+	}
+	response.Ok(c, s.uploadSessionResponse(c, session), "Chunk received")
+}
+
+func (s *Server) deleteUploadSession(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	sessionID, ok := uuidParam(c, "session_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteUploadSession(c.Request.Context(), sessionID, projectID, authPayload.UserID); err != nil {
+		s.respondUploadSessionError(c, "delete upload session", err)
+		return
+	}
+	response.NoContent(c)
+}
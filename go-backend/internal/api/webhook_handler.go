@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWebhookError maps webhook errors to responses
+func (s *Server) respondWebhookError(c *gin.Context, action string, err error) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrWebhookNotFound),
+		errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidWebhookURL),
+		errors.Is(err, services.ErrUnknownWebhookEvent):
+		response.BadRequest(c, err.Error())
+	default:
+		s.logger.Error("Webhook request failed", "action", action, "error", err)
+		response.InternalServerError(c, "Failed to "+action, err)
+	}
+}
+
+func (s *Server) listProjectWebhooks(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	hooks, err := s.researchService.ListWebhooks(c.Request.Context(), projectID, authPayload.UserID)
+	if err != nil {
+		s.respondWebhookError(c, "list webhooks", err)
+		return
+	}
+	resp := make([]apimodels.WebhookResponse, len(hooks))
+	for i, hook := range hooks {
+		resp[i] = apimodels.ToWebhookResponse(hook)
+	}
+	response.Ok(c, resp, "Webhooks retrieved successfully")
+}
+
+func (s *Server) createProjectWebhook(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	var req apimodels.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	hook, err := s.researchService.CreateWebhook(c.Request.Context(), projectID, authPayload.UserID, req)
+	if err != nil {
+		s.respondWebhookError(c, "create webhook", err)
+		return
+	}
+	resp := apimodels.ToWebhookResponse(hook)
+	resp.Secret = hook.Secret // Shown once, so the receiver can verify signatures
+	response.Created(c, resp, "Webhook created successfully")
+}
+
+func (s *Server) updateProjectWebhook(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	webhookID, ok := uuidParam(c, "webhook_id")
+	if !ok {
+		return
+	}
+	var req apimodels.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request payload", err.Error())
+		return
+	}
+	hook, err := s.researchService.UpdateWebhook(c.Request.Context(), projectID, webhookID, authPayload.UserID, req)
+	if err != nil {
+		s.respondWebhookError(c, "update webhook", err)
+		return
+	}
+	response.Ok(c, apimodels.ToWebhookResponse(hook), "Webhook updated successfully")
+}
+
+func (s *Server) deleteProjectWebhook(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	webhookID, ok := uuidParam(c, "webhook_id")
+	if !ok {
+		return
+	}
+	if err := s.researchService.DeleteWebhook(c.Request.Context(), projectID, webhookID, authPayload.UserID); err != nil {
+		s.respondWebhookError(c, "delete webhook", err)
+		return
+	}
+	response.NoContent(c)
+}
+
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	webhookID, ok := uuidParam(c, "webhook_id")
+	if !ok {
+		return
+	}
+	deliveries, err := s.researchService.ListWebhookDeliveries(c.Request.Context(), projectID, webhookID, authPayload.UserID)
+	if err != nil {
+		s.respondWebhookError(c, "list webhook deliveries", err)
+		return
+	}
+	resp := make([]apimodels.WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = apimodels.ToWebhookDeliveryResponse(d)
+	}
+	response.Ok(c, resp, "Webhook deliveries retrieved successfully")
+}
+
+func (s *Server) redeliverWebhook(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectID, ok := uuidParam(c, "project_id")
+	if !ok {
+		return
+	}
+	webhookID, ok := uuidParam(c, "webhook_id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := uuidParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+	delivery, err := s.researchService.RedeliverWebhook(c.Request.Context(), projectID, webhookID, deliveryID, authPayload.UserID)
+	if err != nil {
+		s.respondWebhookError(c, "redeliver webhook", err)
+		return
+	}
+	response.Ok(c, apimodels.ToWebhookDeliveryResponse(delivery), "Webhook delivery queued")
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/api/response"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = (wsPongTimeout * 9) / 10
+
+	// Browsers can't set headers on WebSocket upgrades, so they send the access token as
+	// a subprotocol instead: new WebSocket(url, ["bearer", token]). Query strings would
+	// leave tokens in access logs.
+	wsBearerProtocol = "bearer"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{wsBearerProtocol}, // Never echoes the token back
+	// Matches the permissive CORS policy; tighten together with CORSMiddleware
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsAccessTokenMiddleware copies the token of a "bearer, <token>" Sec-WebSocket-Protocol
+// header into the Authorization header, so authMiddleware can be reused for WebSocket upgrades
+func wsAccessTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(authorizationHeaderKey) == "" {
+			if protocols := websocket.Subprotocols(c.Request); len(protocols) == 2 && protocols[0] == wsBearerProtocol {
+				c.Request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+protocols[1])
+			}
+		}
+		c.Next()
+	}
+}
+
+// projectEventsWebSocket streams generation progress events for a project.
+// The connection is server-push only; client messages other than control frames are ignored.
+func (s *Server) projectEventsWebSocket(c *gin.Context) {
+	authPayload := c.MustGet(authorizationPayloadKey).(*token.Payload)
+	projectIDStr := c.Param("project_id")
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		s.logger.Warn("Invalid project ID format in projectEventsWebSocket", "projectID", projectIDStr, "error", err)
+		response.BadRequest(c, "Invalid project ID format")
+		return
+	}
+
+	// Check ownership before upgrading so the client gets a proper HTTP error
+	if _, err := s.researchService.GetUserProjectByID(c.Request.Context(), projectID, authPayload.UserID); err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			response.NotFound(c, services.ErrProjectNotFound.Error())
+			return
+		}
+		response.InternalServerError(c, "Failed to retrieve project", err)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an HTTP error response
+		s.logger.Warn("WebSocket upgrade failed", "projectID", projectID, "error", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.eventBus.Subscribe(projectID)
+	defer unsubscribe()
+	s.logger.Info("WebSocket subscribed to project events", "projectID", projectID, "userID", authPayload.UserID)
+
+	// Reader: handles pongs and notices when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				s.logger.Warn("WebSocket write failed", "projectID", projectID, "error", err)
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
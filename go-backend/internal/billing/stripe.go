@@ -0,0 +1,179 @@
+// Package billing calls the Stripe API over plain HTTP and verifies Stripe webhooks
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+)
+
+const (
+	defaultBaseURL = "https://api.stripe.com"
+	// Pinned so response shapes don't change with the account's default version
+	apiVersion = "2024-06-20"
+)
+
+// Client is a minimal Stripe API client covering customers, Checkout, the customer portal and subscriptions
+type Client struct {
+	secretKey string
+	baseURL   string
+	client    *http.Client
+}
+
+func NewClient(secretKey string) *Client {
+	return &Client{
+		secretKey: secretKey,
+		baseURL:   defaultBaseURL,
+		client:    telemetry.NewHTTPClient(&http.Client{Timeout: 30 * time.Second}),
+	}
+}
+
+// Error is an error response from the Stripe API
+type Error struct {
+	StatusCode int
+	Type       string `json:"type"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("stripe: %s (status %d, type %s)", e.Message, e.StatusCode, e.Type)
+}
+
+type Customer struct {
+	ID string `json:"id"`
+}
+
+type CheckoutSession struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+type PortalSession struct {
+	URL string `json:"url"`
+}
+
+type Subscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// PriceID returns the price of the subscription's first item
+func (s Subscription) PriceID() string {
+	if len(s.Items.Data) == 0 {
+		return ""
+	}
+	return s.Items.Data[0].Price.ID
+}
+
+// CheckoutParams describes a subscription Checkout session for an existing customer
+type CheckoutParams struct {
+	CustomerID        string
+	PriceID           string
+	ClientReferenceID string
+	SuccessURL        string
+	CancelURL         string
+	Metadata          map[string]string // Copied onto the subscription
+}
+
+func (c *Client) CreateCustomer(ctx context.Context, email string, metadata map[string]string) (Customer, error) {
+	form := url.Values{"email": {email}}
+	setMetadata(form, "metadata", metadata)
+	var customer Customer
+	err := c.do(ctx, http.MethodPost, "/v1/customers", form, &customer)
+	return customer, err
+}
+
+func (c *Client) CreateCheckoutSession(ctx context.Context, p CheckoutParams) (CheckoutSession, error) {
+	form := url.Values{
+		"mode":                    {"subscription"},
+		"customer":                {p.CustomerID},
+		"line_items[0][price]":    {p.PriceID},
+		"line_items[0][quantity]": {"1"},
+		"client_reference_id":     {p.ClientReferenceID},
+		"success_url":             {p.SuccessURL},
+		"cancel_url":              {p.CancelURL},
+	}
+	setMetadata(form, "subscription_data[metadata]", p.Metadata)
+	var session CheckoutSession
+	err := c.do(ctx, http.MethodPost, "/v1/checkout/sessions", form, &session)
+	return session, err
+}
+
+// CreatePortalSession opens Stripe's customer portal, where customers change plans, update cards and cancel
+func (c *Client) CreatePortalSession(ctx context.Context, customerID, returnURL string) (PortalSession, error) {
+	form := url.Values{"customer": {customerID}, "return_url": {returnURL}}
+	var session PortalSession
+	err := c.do(ctx, http.MethodPost, "/v1/billing_portal/sessions", form, &session)
+	return session, err
+}
+
+func (c *Client) GetSubscription(ctx context.Context, id string) (Subscription, error) {
+	var sub Subscription
+	err := c.do(ctx, http.MethodGet, "/v1/subscriptions/"+url.PathEscape(id), nil, &sub)
+	return sub, err
+}
+
+func setMetadata(form url.Values, prefix string, metadata map[string]string) {
+	for k, v := range metadata {
+		form.Set(prefix+"["+k+"]", v)
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, form url.Values, out any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create stripe request: %w", err)
+	}
+	req.SetBasicAuth(c.secretKey, "")
+	req.Header.Set("Stripe-Version", apiVersion)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read stripe response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var envelope struct {
+			Error Error `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &envelope); err != nil || envelope.Error.Message == "" {
+			return &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		}
+		envelope.Error.StatusCode = resp.StatusCode
+		return &envelope.Error
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package billing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// webhookTolerance is how old a signed webhook may be before it is treated as a replay
+const webhookTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid stripe signature")
+
+// Event is a Stripe webhook event. Data.Object holds the resource the event is about.
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// ParseWebhook checks the Stripe-Signature header of payload against the endpoint's
+// signing secret and decodes the event
+func ParseWebhook(payload []byte, header, secret string, now time.Time) (Event, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			timestamp = v
+		case "v1":
+			signatures = append(signatures, v)
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return Event{}, ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(unix, 0)) > webhookTolerance {
+		return Event{}, fmt.Errorf("%w: timestamp is too old", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	valid := false
+	for _, sig := range signatures {
+		if decoded, err := hex.DecodeString(sig); err == nil && hmac.Equal(decoded, expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("failed to decode stripe event: %w", err)
+	}
+	return event, nil
+}
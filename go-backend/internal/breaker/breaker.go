@@ -0,0 +1,167 @@
+// Package breaker fails calls to a downstream service fast while it is down. After
+// FailureThreshold failures in a row the circuit opens and calls fail with ErrOpen
+// without being made; once OpenTimeout has passed a single call is let through to probe
+// the service, closing the circuit again when it succeeds.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Circuit states
+const (
+	StateClosed   = "closed"    // Calls go through
+	StateOpen     = "open"      // Calls fail fast
+	StateHalfOpen = "half_open" // One probe call is in flight
+)
+
+// ErrOpen matches the OpenError of a call refused while the circuit is open
+var ErrOpen = errors.New("service is unavailable")
+
+// OpenError is returned for a call refused while the circuit is open
+type OpenError struct {
+	Name       string        // The service
+	RetryAfter time.Duration // Until the circuit lets a probe through
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable after repeated failures, retry in %s", e.Name, e.RetryAfter.Round(time.Second))
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// Config tunes when a circuit opens and how long it stays open
+type Config struct {
+	FailureThreshold int           // Consecutive failures that open the circuit
+	OpenTimeout      time.Duration // How long it stays open before a probe
+}
+
+// Status is a circuit's state, for health checks
+type Status struct {
+	State     string     `json:"state" doc:"closed, open or half_open"`
+	Failures  int        `json:"failures" doc:"Consecutive failures"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Breaker is the circuit for one downstream service. It is safe for concurrent use.
+type Breaker struct {
+	name string
+	cfg  Config
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openedAt  time.Time
+	lastError string
+}
+
+func New(name string, cfg Config) *Breaker {
+	return &Breaker{name: name, cfg: cfg, state: StateClosed}
+}
+
+// Name is the service the circuit guards
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may be made now, failing with an OpenError while the
+// circuit is open. Each allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		wait := b.cfg.OpenTimeout - time.Since(b.openedAt)
+		if wait > 0 {
+			return &OpenError{Name: b.name, RetryAfter: wait}
+		}
+		b.state = StateHalfOpen
+		return nil
+	case StateHalfOpen:
+		return &OpenError{Name: b.name, RetryAfter: time.Second} // Once the probe is answered
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call: nil for a success, the error for a
+// failure of the service
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.state = StateClosed
+		b.failures = 0
+		b.lastError = ""
+		return
+	}
+	b.failures++
+	b.lastError = err.Error()
+	if b.state == StateHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Status returns the circuit's current state
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := Status{State: b.state, Failures: b.failures, LastError: b.lastError}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		st.OpenedAt = &openedAt
+	}
+	return st
+}
+
+// Transport guards an HTTP transport, nil for the default one, with the circuit. Errors
+// reaching the service and 5xx responses count as failures; calls the caller cancelled
+// count as neither.
+func (b *Breaker) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{breaker: b, base: base}
+}
+
+type transport struct {
+	breaker *Breaker
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		t.breaker.release()
+	case err != nil:
+		t.breaker.Record(err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.Record(fmt.Errorf("%s responded with status %d", t.breaker.name, resp.StatusCode))
+	default:
+		t.breaker.Record(nil)
+	}
+	return resp, err
+}
+
+// release gives back a call that says nothing about the service's health, so a probe
+// cancelled by its caller lets the next call probe instead
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.state = StateOpen
+		b.openedAt = time.Now().Add(-b.cfg.OpenTimeout)
+	}
+}
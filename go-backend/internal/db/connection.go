@@ -2,22 +2,55 @@ package db
 
 import (
 	"context"
+	"fmt"
+	"time"
 	// "database/sql" // Not needed if using pgxpool directly with sqlc
 
+	"github.com/exaring/otelpgx"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib" // SQL driver
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
 )
 
-func ConnectDB(databaseURL string) (*pgxpool.Pool, error) {
+// PoolConfig tunes the pgx connection pool. Zero values keep the pgx defaults.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
+
+// ConnectDB opens and pings a pool. Queries are logged through logger at debug level.
+func ConnectDB(databaseURL string, poolConfig PoolConfig, logger *applogger.AppLogger) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	// You can set connection pool parameters here if needed
-	// config.MaxConns = 10
-	// config.MinConns = 2
-	// config.MaxConnLifetime = time.Hour
+	// Emit a span per query (a no-op until a tracer provider is registered) and a debug log line
+	config.ConnConfig.Tracer = multitracer.New(otelpgx.NewTracer(), &queryLogger{logger: logger})
+
+	if poolConfig.MaxConns > 0 {
+		config.MaxConns = poolConfig.MaxConns
+	}
+	if poolConfig.MinConns > 0 {
+		config.MinConns = poolConfig.MinConns
+	}
+	if config.MinConns > config.MaxConns {
+		return nil, fmt.Errorf("DB_MIN_CONNS (%d) must not exceed DB_MAX_CONNS (%d)", config.MinConns, config.MaxConns)
+	}
+	if poolConfig.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = poolConfig.MaxConnLifetime
+	}
+	if poolConfig.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = poolConfig.MaxConnIdleTime
+	}
+	if poolConfig.HealthCheckPeriod > 0 {
+		config.HealthCheckPeriod = poolConfig.HealthCheckPeriod
+	}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
@@ -0,0 +1,17 @@
+-- Idempotency keys for expensive POST operations (AI generation, document generation)
+CREATE TABLE idempotency_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_method VARCHAR(10) NOT NULL,
+    request_path TEXT NOT NULL,
+    request_hash VARCHAR(64) NOT NULL, -- sha256 of method + path + body
+    response_status INTEGER, -- NULL while the original request is still in flight
+    response_body BYTEA,
+    response_hash VARCHAR(64),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
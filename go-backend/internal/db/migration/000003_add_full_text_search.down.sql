@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_references_search_vector;
+DROP INDEX IF EXISTS idx_chapters_search_vector;
+ALTER TABLE "references" DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE chapters DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE "references" DROP COLUMN IF EXISTS abstract;
@@ -0,0 +1,17 @@
+-- Abstracts are searchable alongside reference titles
+ALTER TABLE "references" ADD COLUMN abstract TEXT;
+
+-- Weighted search vectors: titles rank above body text
+ALTER TABLE chapters ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(content, '')), 'B')
+) STORED;
+
+ALTER TABLE "references" ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(abstract, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(authors, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_chapters_search_vector ON chapters USING GIN (search_vector);
+CREATE INDEX idx_references_search_vector ON "references" USING GIN (search_vector);
@@ -0,0 +1,2 @@
+-- Version counter for optimistic locking of chapter updates
+ALTER TABLE chapters ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
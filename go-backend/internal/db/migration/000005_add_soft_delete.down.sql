@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_references_deleted_at;
+DROP INDEX IF EXISTS idx_chapters_deleted_at;
+
+-- Trashed rows would violate the restored constraint, so they are purged
+DELETE FROM chapters WHERE deleted_at IS NOT NULL;
+DELETE FROM "references" WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_chapters_project_id_type_active;
+ALTER TABLE chapters ADD CONSTRAINT chapters_project_id_type_key UNIQUE (project_id, type);
+
+ALTER TABLE "references" DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE chapters DROP COLUMN IF EXISTS deleted_at;
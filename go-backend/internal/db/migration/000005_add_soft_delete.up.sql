@@ -0,0 +1,10 @@
+-- Soft delete: rows stay in the trash until restored or purged
+ALTER TABLE chapters ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE "references" ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- A trashed chapter must not block creating a new chapter of the same type
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS chapters_project_id_type_key;
+CREATE UNIQUE INDEX idx_chapters_project_id_type_active ON chapters(project_id, type) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_chapters_deleted_at ON chapters(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_references_deleted_at ON "references"(deleted_at) WHERE deleted_at IS NOT NULL;
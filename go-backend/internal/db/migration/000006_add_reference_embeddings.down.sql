@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reference_embeddings;
+-- The vector extension is left installed; other database objects may depend on it
@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS vector;
+
+-- One embedding per reference, computed from its title and abstract.
+-- Kept out of "references" so SELECT * queries don't drag the vectors along.
+CREATE TABLE reference_embeddings (
+    reference_id UUID PRIMARY KEY REFERENCES "references"(id) ON DELETE CASCADE,
+    model VARCHAR(100) NOT NULL,
+    embedding vector(1536) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reference_embeddings_embedding ON reference_embeddings
+    USING hnsw (embedding vector_cosine_ops);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notify_generated_documents_status ON generated_documents;
+DROP TRIGGER IF EXISTS notify_chapters_status ON chapters;
+DROP FUNCTION IF EXISTS notify_status_change();
@@ -0,0 +1,39 @@
+-- Broadcast chapter and document status changes on the research_events channel,
+-- so every API process can push them to WebSocket clients regardless of which
+-- process (or worker) made the change. Payloads mirror events.Event.
+CREATE OR REPLACE FUNCTION notify_status_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    payload JSON;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NEW;
+    END IF;
+
+    IF TG_TABLE_NAME = 'chapters' THEN
+        payload := json_build_object(
+            'type', 'chapter.status_changed',
+            'project_id', NEW.project_id,
+            'chapter_id', NEW.id,
+            'status', NEW.status,
+            'timestamp', NOW()
+        );
+    ELSE
+        payload := json_build_object(
+            'type', 'document.status_changed',
+            'project_id', NEW.project_id,
+            'document_id', NEW.id,
+            'status', NEW.status,
+            'timestamp', NOW()
+        );
+    END IF;
+
+    PERFORM pg_notify('research_events', payload::text);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER notify_chapters_status AFTER INSERT OR UPDATE OF status ON chapters
+    FOR EACH ROW EXECUTE FUNCTION notify_status_change();
+CREATE TRIGGER notify_generated_documents_status AFTER INSERT OR UPDATE OF status ON generated_documents
+    FOR EACH ROW EXECUTE FUNCTION notify_status_change();
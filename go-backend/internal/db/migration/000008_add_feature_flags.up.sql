@@ -0,0 +1,6 @@
+-- Runtime overrides for feature flags; flags without a row use their env/default value
+CREATE TABLE feature_flags (
+    name VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
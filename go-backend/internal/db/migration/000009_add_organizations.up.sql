@@ -0,0 +1,37 @@
+-- Organizations let research groups and supervision teams share projects.
+-- Every member of an organization can work on its projects; owners and admins manage membership.
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user_id ON organization_members(user_id);
+
+-- Invitations are matched to the invitee by email, so people can be invited before they register
+CREATE TABLE organization_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('admin', 'member')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_organization_invitations_org_email ON organization_invitations(organization_id, lower(email));
+CREATE INDEX idx_organization_invitations_email ON organization_invitations(lower(email));
+
+-- A project stays owned by its creator; organization_id additionally shares it with the organization
+ALTER TABLE research_projects ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+CREATE INDEX idx_research_projects_organization_id ON research_projects(organization_id) WHERE organization_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP FUNCTION IF EXISTS project_access_role(UUID, UUID);
+DROP TABLE IF EXISTS project_collaborators;
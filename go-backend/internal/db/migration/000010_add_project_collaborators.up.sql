@@ -0,0 +1,28 @@
+-- Per-project sharing with individual users
+CREATE TABLE project_collaborators (
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('read', 'comment', 'edit')),
+    added_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_collaborators_user_id ON project_collaborators(user_id);
+
+-- project_access_role returns the strongest role a user has on a project:
+-- owner, edit (organization members and editors), comment, read, or NULL for no access.
+-- Queries use it so access rules live in one place. The body refers to the
+-- arguments as $1 and $2 because their names match column names.
+CREATE FUNCTION project_access_role(project_id UUID, user_id UUID) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN p.user_id = $2 THEN 'owner'
+        WHEN p.organization_id IS NOT NULL AND EXISTS (
+            SELECT 1 FROM organization_members m WHERE m.organization_id = p.organization_id AND m.user_id = $2
+        ) THEN 'edit'
+        ELSE (SELECT c.role FROM project_collaborators c WHERE c.project_id = p.id AND c.user_id = $2)
+    END
+    FROM research_projects p
+    WHERE p.id = $1;
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,35 @@
+-- Comment threads anchored to a character range or a section heading of a chapter.
+-- quoted_text keeps the anchored passage so threads still make sense after edits.
+CREATE TABLE comment_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    anchor_start INTEGER,
+    anchor_end INTEGER,
+    anchor_heading TEXT,
+    quoted_text TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMPTZ,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (
+        (anchor_start IS NOT NULL AND anchor_end IS NOT NULL AND anchor_start >= 0 AND anchor_start < anchor_end)
+        OR (anchor_start IS NULL AND anchor_end IS NULL AND anchor_heading IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_comment_threads_chapter_id ON comment_threads(chapter_id);
+CREATE INDEX idx_comment_threads_open ON comment_threads(project_id, chapter_id) WHERE resolved_at IS NULL;
+
+CREATE TABLE comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    thread_id UUID NOT NULL REFERENCES comment_threads(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_thread_id ON comments(thread_id, created_at);
+
+CREATE TRIGGER update_comment_threads_updated_at BEFORE UPDATE ON comment_threads FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,21 @@
+CREATE OR REPLACE FUNCTION project_access_role(project_id UUID, user_id UUID) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN p.user_id = $2 THEN 'owner'
+        WHEN p.organization_id IS NOT NULL AND EXISTS (
+            SELECT 1 FROM organization_members m WHERE m.organization_id = p.organization_id AND m.user_id = $2
+        ) THEN 'edit'
+        ELSE (SELECT c.role FROM project_collaborators c WHERE c.project_id = p.id AND c.user_id = $2)
+    END
+    FROM research_projects p
+    WHERE p.id = $1;
+$$ LANGUAGE SQL STABLE;
+
+DROP TABLE IF EXISTS chapter_reviews;
+
+UPDATE chapters SET status = 'draft' WHERE status IN ('in_review', 'changes_requested');
+ALTER TABLE chapters DROP CONSTRAINT chapters_status_check;
+ALTER TABLE chapters ADD CONSTRAINT chapters_status_check
+    CHECK (status IN ('draft', 'generated', 'approved', 'rejected'));
+
+DROP INDEX IF EXISTS idx_research_projects_advisor_id;
+ALTER TABLE research_projects DROP COLUMN IF EXISTS advisor_id;
@@ -0,0 +1,41 @@
+-- Advisor review workflow: students submit chapters, the project's advisor
+-- approves them or requests changes. Each submission is kept as an audit trail.
+ALTER TABLE research_projects ADD COLUMN advisor_id UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_research_projects_advisor_id ON research_projects(advisor_id) WHERE advisor_id IS NOT NULL;
+
+ALTER TABLE chapters DROP CONSTRAINT chapters_status_check;
+ALTER TABLE chapters ADD CONSTRAINT chapters_status_check
+    CHECK (status IN ('draft', 'generated', 'in_review', 'changes_requested', 'approved', 'rejected'));
+
+CREATE TABLE chapter_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    chapter_version INTEGER NOT NULL, -- Version that was submitted
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'changes_requested')),
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    submission_note TEXT NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT NOT NULL DEFAULT '',
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_chapter_reviews_chapter_id ON chapter_reviews(chapter_id, submitted_at DESC);
+-- At most one open review per chapter
+CREATE UNIQUE INDEX idx_chapter_reviews_pending ON chapter_reviews(chapter_id) WHERE status = 'pending';
+
+-- Advisors can read and comment on the projects they advise
+CREATE OR REPLACE FUNCTION project_access_role(project_id UUID, user_id UUID) RETURNS TEXT AS $$
+    SELECT CASE
+        WHEN p.user_id = $2 THEN 'owner'
+        WHEN p.organization_id IS NOT NULL AND EXISTS (
+            SELECT 1 FROM organization_members m WHERE m.organization_id = p.organization_id AND m.user_id = $2
+        ) THEN 'edit'
+        WHEN EXISTS (SELECT 1 FROM project_collaborators c WHERE c.project_id = p.id AND c.user_id = $2 AND c.role = 'edit') THEN 'edit'
+        WHEN p.advisor_id = $2 THEN 'comment'
+        ELSE (SELECT c.role FROM project_collaborators c WHERE c.project_id = p.id AND c.user_id = $2)
+    END
+    FROM research_projects p
+    WHERE p.id = $1;
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,32 @@
+-- Notifications shown in the app and, depending on the user's preferences, sent by email.
+-- A row with in_app = false only exists to be emailed.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    project_id UUID REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID REFERENCES chapters(id) ON DELETE CASCADE,
+    document_id UUID REFERENCES generated_documents(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    read_at TIMESTAMPTZ,
+    email_status VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (email_status IN ('none', 'pending', 'sent', 'failed')),
+    email_attempts INTEGER NOT NULL DEFAULT 0,
+    email_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC) WHERE in_app;
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE in_app AND read_at IS NULL;
+CREATE INDEX idx_notifications_email_pending ON notifications(created_at) WHERE email_status = 'pending';
+
+-- Per-type overrides; types without a row use the defaults in NotificationService
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, type)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS billing_usage;
+DROP TABLE IF EXISTS stripe_events;
+DROP TABLE IF EXISTS subscriptions;
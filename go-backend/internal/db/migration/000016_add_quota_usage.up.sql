@@ -0,0 +1,9 @@
+-- Usage counted against plan quotas, per billing period. Subscribers' periods start on their
+-- billing day, so period_start is a timestamp rather than the first of the month.
+CREATE TABLE quota_usage (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start TIMESTAMPTZ NOT NULL,
+    resource VARCHAR(30) NOT NULL,
+    used BIGINT NOT NULL DEFAULT 0 CHECK (used >= 0),
+    PRIMARY KEY (user_id, period_start, resource)
+);
@@ -0,0 +1,21 @@
+-- Background jobs run by internal/jobs. A failed run is retried with backoff until
+-- max_attempts, then the job is left dead for an operator to inspect and requeue.
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- When a pending job is due; while running, when its lease runs out
+    unique_key VARCHAR(200), -- Enqueueing with a key that is already taken is a no-op, e.g. one periodic run per interval
+    last_error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_due ON jobs(run_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_jobs_created_at ON jobs(created_at DESC);
+CREATE UNIQUE INDEX idx_jobs_unique_key ON jobs(unique_key) WHERE unique_key IS NOT NULL;
+CREATE TRIGGER update_jobs_updated_at BEFORE UPDATE ON jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
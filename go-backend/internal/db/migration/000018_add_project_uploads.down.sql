@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_upload_texts;
+DROP TABLE IF EXISTS project_uploads;
@@ -0,0 +1,26 @@
+-- Source PDFs students upload to a project. Text is extracted in the background by the
+-- docgen service and kept apart in project_upload_texts so listing uploads stays cheap.
+CREATE TABLE project_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    file_size BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    extraction_status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (extraction_status IN ('pending', 'completed', 'failed')),
+    extraction_error TEXT NOT NULL DEFAULT '',
+    page_count INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_uploads_project_id ON project_uploads(project_id, created_at);
+CREATE UNIQUE INDEX idx_project_uploads_sha256 ON project_uploads(project_id, sha256); -- The same file once per project
+CREATE TRIGGER update_project_uploads_updated_at BEFORE UPDATE ON project_uploads FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE project_upload_texts (
+    upload_id UUID PRIMARY KEY REFERENCES project_uploads(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
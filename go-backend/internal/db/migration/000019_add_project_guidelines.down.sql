@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_formatting;
+DROP TABLE IF EXISTS project_guidelines;
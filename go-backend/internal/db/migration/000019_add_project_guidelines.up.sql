@@ -0,0 +1,25 @@
+-- A project's faculty formatting guideline (PDF or DOCX). Its text is extracted by the
+-- docgen service and the AI turns it into structured rules; uploading again replaces it.
+CREATE TABLE project_guidelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL UNIQUE REFERENCES research_projects(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    file_size BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    rules JSONB NOT NULL DEFAULT '{}', -- The formatting options extracted from the guideline
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_project_guidelines_updated_at BEFORE UPDATE ON project_guidelines FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Formatting options used when generating a project's document; missing keys fall back to the defaults
+CREATE TABLE project_formatting (
+    project_id UUID PRIMARY KEY REFERENCES research_projects(id) ON DELETE CASCADE,
+    options JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
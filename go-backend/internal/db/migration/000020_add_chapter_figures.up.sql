@@ -0,0 +1,21 @@
+-- Figures and tables of a chapter. Chapter content places one with a [[figure:<id>]] or
+-- [[table:<id>]] marker on its own line; numbering follows position within the chapter
+-- and is prefixed with the chapter's number in the generated document.
+CREATE TABLE chapter_figures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('figure', 'table')),
+    caption TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    file_path TEXT NOT NULL DEFAULT '', -- Image of a figure
+    mime_type VARCHAR(100) NOT NULL DEFAULT '',
+    table_data JSONB NOT NULL DEFAULT '[]', -- Rows of cells of a table, the first row its header
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chapter_figures_chapter_id ON chapter_figures(chapter_id, position, created_at);
+CREATE INDEX idx_chapter_figures_project_id ON chapter_figures(project_id);
+CREATE TRIGGER update_chapter_figures_updated_at BEFORE UPDATE ON chapter_figures FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
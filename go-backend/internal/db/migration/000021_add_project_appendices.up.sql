@@ -0,0 +1,17 @@
+-- Appendices follow the references in the generated document, lettered A, B, ... by
+-- position. Each has text content, an image (a scanned form, a diagram) or both.
+CREATE TABLE project_appendices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    file_path TEXT NOT NULL DEFAULT '',
+    mime_type VARCHAR(100) NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_appendices_project_id ON project_appendices(project_id, position, created_at);
+CREATE TRIGGER update_project_appendices_updated_at BEFORE UPDATE ON project_appendices FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
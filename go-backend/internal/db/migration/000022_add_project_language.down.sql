@@ -0,0 +1 @@
+ALTER TABLE research_projects DROP COLUMN IF EXISTS language;
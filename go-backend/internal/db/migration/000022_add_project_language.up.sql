@@ -0,0 +1,2 @@
+-- Language chapters are generated in; citations stay in the language of their source
+ALTER TABLE research_projects ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT 'en';
@@ -0,0 +1,2 @@
+-- Words a chapter should reach; generation aims for it and project progress is measured against it
+ALTER TABLE chapters ADD COLUMN target_word_count INTEGER CHECK (target_word_count > 0);
@@ -0,0 +1,17 @@
+-- A project's research timeline: tasks with durations and dependencies, generated from
+-- the chapter structure and dated between the start and the submission deadline
+CREATE TABLE project_timelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL UNIQUE REFERENCES research_projects(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    milestones JSONB NOT NULL DEFAULT '[]', -- Fixed dates the student gave, e.g. a proposal defense
+    tasks JSONB NOT NULL DEFAULT '[]',
+    include_in_document BOOLEAN NOT NULL DEFAULT FALSE,
+    generated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date > start_date)
+);
+
+CREATE TRIGGER update_project_timelines_updated_at BEFORE UPDATE ON project_timelines FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
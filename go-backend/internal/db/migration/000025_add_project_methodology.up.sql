@@ -0,0 +1,8 @@
+-- Answers to the methodology questionnaire (design, population, instruments, analysis);
+-- methodology generation writes the chapter from them
+CREATE TABLE project_methodology (
+    project_id UUID PRIMARY KEY REFERENCES research_projects(id) ON DELETE CASCADE,
+    answers JSONB NOT NULL DEFAULT '{}',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
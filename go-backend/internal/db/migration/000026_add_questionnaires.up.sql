@@ -0,0 +1,17 @@
+-- Survey instruments of a project. Sections hold the items: likert, choice, yes/no or
+-- open questions, with their scale labels or choices. Questionnaires included in the
+-- document are rendered as appendices after the project's own.
+CREATE TABLE questionnaires (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    instructions TEXT NOT NULL DEFAULT '',
+    sections JSONB NOT NULL DEFAULT '[]',
+    include_in_document BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_questionnaires_project_id ON questionnaires(project_id, created_at);
+CREATE TRIGGER update_questionnaires_updated_at BEFORE UPDATE ON questionnaires FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
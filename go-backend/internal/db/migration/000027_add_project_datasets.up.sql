@@ -0,0 +1,18 @@
+-- Study results uploaded as CSV. Only the descriptive statistics computed at upload are
+-- kept, one entry per column. Generating the results chapter narrates them and adds
+-- their tables to the chapter; table_ids tracks those tables so a later generation
+-- replaces them instead of adding more.
+CREATE TABLE project_datasets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    row_count INTEGER NOT NULL,
+    columns JSONB NOT NULL DEFAULT '[]',
+    table_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_datasets_project_id ON project_datasets(project_id, created_at);
+CREATE TRIGGER update_project_datasets_updated_at BEFORE UPDATE ON project_datasets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reference_highlights;
+DROP TABLE IF EXISTS reference_notes;
@@ -0,0 +1,27 @@
+-- Students' notes on why a reference matters, and passages highlighted from it. Both are
+-- passed to literature review generation so the synthesis follows the student's reading.
+CREATE TABLE reference_notes (
+    reference_id UUID PRIMARY KEY REFERENCES "references"(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    notes TEXT NOT NULL DEFAULT '',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reference_notes_project_id ON reference_notes(project_id);
+CREATE TRIGGER update_reference_notes_updated_at BEFORE UPDATE ON reference_notes FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE reference_highlights (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reference_id UUID NOT NULL REFERENCES "references"(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    page INTEGER, -- Where the passage is in the paper, if known
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reference_highlights_reference_id ON reference_highlights(reference_id, created_at);
+CREATE INDEX idx_reference_highlights_project_id ON reference_highlights(project_id);
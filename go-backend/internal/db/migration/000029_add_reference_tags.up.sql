@@ -0,0 +1,11 @@
+-- Tags sorting a project's references by the role they play in the thesis. A literature
+-- review can be regenerated from only the references carrying one tag.
+CREATE TABLE reference_tags (
+    reference_id UUID NOT NULL REFERENCES "references"(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    tag VARCHAR(30) NOT NULL CHECK (tag IN ('methodology', 'seminal', 'recent', 'contradictory')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (reference_id, tag)
+);
+
+CREATE INDEX idx_reference_tags_project_id ON reference_tags(project_id, tag);
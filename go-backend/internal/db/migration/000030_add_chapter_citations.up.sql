@@ -0,0 +1,18 @@
+-- In-text (Author, Year) citations found in chapter content, linked to the references
+-- they cite. Rebuilt from the content whenever it is read at a newer chapter version.
+CREATE TABLE chapter_citations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    reference_id UUID REFERENCES "references"(id) ON DELETE SET NULL, -- NULL until a reference matches
+    marker TEXT NOT NULL, -- As written, e.g. Smith et al., 2020
+    author TEXT NOT NULL,
+    year INTEGER NOT NULL,
+    position INTEGER NOT NULL, -- Byte offset of the marker in the content
+    chapter_version INTEGER NOT NULL, -- Version of the content the marker was parsed from
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (chapter_id, position)
+);
+
+CREATE INDEX idx_chapter_citations_reference_id ON chapter_citations(reference_id);
+CREATE INDEX idx_chapter_citations_project_id ON chapter_citations(project_id);
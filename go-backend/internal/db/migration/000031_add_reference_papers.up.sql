@@ -0,0 +1,10 @@
+-- Semantic Scholar papers imported as references, so a paper is not imported twice and
+-- its open access PDF can be fetched later
+CREATE TABLE reference_papers (
+    reference_id UUID PRIMARY KEY REFERENCES "references"(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    paper_id VARCHAR(64) NOT NULL, -- Semantic Scholar paperId
+    open_access_pdf_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (project_id, paper_id)
+);
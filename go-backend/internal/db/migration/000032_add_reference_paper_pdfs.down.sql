@@ -0,0 +1,5 @@
+ALTER TABLE reference_papers
+    DROP COLUMN IF EXISTS pdf_size,
+    DROP COLUMN IF EXISTS pdf_path,
+    DROP COLUMN IF EXISTS pdf_error,
+    DROP COLUMN IF EXISTS pdf_status;
@@ -0,0 +1,6 @@
+-- Open access PDFs of imported papers, downloaded in the background
+ALTER TABLE reference_papers
+    ADD COLUMN pdf_status VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (pdf_status IN ('none', 'pending', 'stored', 'failed')),
+    ADD COLUMN pdf_error TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pdf_path TEXT NOT NULL DEFAULT '',
+    ADD COLUMN pdf_size BIGINT;
@@ -0,0 +1,13 @@
+-- Full text of imported papers' PDFs, in passages ranked against the thesis topic when a
+-- literature review is generated
+CREATE TABLE reference_paper_chunks (
+    reference_id UUID NOT NULL REFERENCES "references"(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', content)) STORED,
+    PRIMARY KEY (reference_id, position)
+);
+
+CREATE INDEX idx_reference_paper_chunks_project_id ON reference_paper_chunks(project_id);
+CREATE INDEX idx_reference_paper_chunks_search_vector ON reference_paper_chunks USING GIN (search_vector);
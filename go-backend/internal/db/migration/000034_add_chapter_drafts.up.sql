@@ -0,0 +1,12 @@
+-- Autosaved edits of a chapter, one per editor, kept apart from the saved content until
+-- the editor saves the chapter. NULL title or content means unchanged from the chapter.
+CREATE TABLE chapter_drafts (
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    title VARCHAR(300),
+    content TEXT,
+    base_version INTEGER NOT NULL, -- Chapter version the edits started from
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chapter_id, user_id)
+);
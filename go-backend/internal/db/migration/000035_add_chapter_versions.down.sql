@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS record_chapters_version ON chapters;
+DROP FUNCTION IF EXISTS record_chapter_version();
+DROP TABLE IF EXISTS chapter_versions;
@@ -0,0 +1,41 @@
+-- Chapter version history: a snapshot of every saved version, written by trigger so that
+-- edits, generation, bulk saves and review decisions are all recorded
+CREATE TABLE chapter_versions (
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    title VARCHAR(300) NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    word_count INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(50) NOT NULL DEFAULT 'draft', -- 'generated' marks an AI (re)generation
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chapter_id, version)
+);
+
+CREATE OR REPLACE FUNCTION record_chapter_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.version = OLD.version
+        AND NEW.title IS NOT DISTINCT FROM OLD.title
+        AND NEW.content IS NOT DISTINCT FROM OLD.content
+        AND NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NEW;
+    END IF;
+
+    -- An update that keeps the version overwrites that version's snapshot
+    INSERT INTO chapter_versions (chapter_id, project_id, version, title, content, word_count, status)
+    VALUES (NEW.id, NEW.project_id, NEW.version, NEW.title, coalesce(NEW.content, ''), coalesce(NEW.word_count, 0), coalesce(NEW.status, 'draft'))
+    ON CONFLICT (chapter_id, version) DO UPDATE
+    SET title = EXCLUDED.title, content = EXCLUDED.content, word_count = EXCLUDED.word_count,
+        status = EXCLUDED.status, created_at = NOW();
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_chapters_version AFTER INSERT OR UPDATE ON chapters
+    FOR EACH ROW EXECUTE FUNCTION record_chapter_version();
+
+-- Existing chapters start their history at their current version
+INSERT INTO chapter_versions (chapter_id, project_id, version, title, content, word_count, status, created_at)
+SELECT id, project_id, version, title, coalesce(content, ''), coalesce(word_count, 0), coalesce(status, 'draft'), coalesce(updated_at, NOW())
+FROM chapters;
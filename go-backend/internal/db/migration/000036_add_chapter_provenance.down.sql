@@ -0,0 +1 @@
+DROP TABLE IF EXISTS chapter_provenance;
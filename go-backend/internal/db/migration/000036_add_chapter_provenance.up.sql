@@ -0,0 +1,11 @@
+-- Which parts of a chapter's content AI generation wrote and which its editors wrote,
+-- updated on every save for academic-integrity disclosure. spans holds
+-- [{"origin": "ai"|"human"|"unknown", "start": n, "end": n}] byte ranges of the content
+-- at version.
+CREATE TABLE chapter_provenance (
+    chapter_id UUID PRIMARY KEY REFERENCES chapters(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    spans JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Readability and academic-tone metrics of each chapter's content, recomputed on save
+CREATE TABLE chapter_metrics (
+    chapter_id UUID PRIMARY KEY REFERENCES chapters(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL, -- Chapter version the metrics describe
+    metrics JSONB NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
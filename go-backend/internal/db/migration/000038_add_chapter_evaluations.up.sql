@@ -0,0 +1,17 @@
+-- AI evaluations of a chapter against a rubric. rubric holds the criteria scored
+-- [{"key", "name", "description", "weight"}] and criteria the result per criterion
+-- [{"key", "score", "justification", "suggestions"}], against the chapter at
+-- chapter_version.
+CREATE TABLE chapter_evaluations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    chapter_version INTEGER NOT NULL,
+    rubric JSONB NOT NULL,
+    criteria JSONB NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    evaluated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_chapter_evaluations_chapter_id ON chapter_evaluations(chapter_id, created_at DESC);
@@ -0,0 +1,4 @@
+DELETE FROM chapters WHERE type = 'executive_summary';
+ALTER TABLE chapters DROP CONSTRAINT chapters_type_check;
+ALTER TABLE chapters ADD CONSTRAINT chapters_type_check
+    CHECK (type IN ('introduction', 'literature_review', 'methodology', 'results', 'conclusion'));
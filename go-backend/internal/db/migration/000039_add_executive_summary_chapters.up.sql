@@ -0,0 +1,4 @@
+-- Executive summaries are stored as a chapter of their own type, outside the thesis
+ALTER TABLE chapters DROP CONSTRAINT chapters_type_check;
+ALTER TABLE chapters ADD CONSTRAINT chapters_type_check
+    CHECK (type IN ('introduction', 'literature_review', 'methodology', 'results', 'conclusion', 'executive_summary'));
@@ -0,0 +1,7 @@
+-- Personal front matter of a project's thesis document (degree, supervisor, declaration,
+-- dedication, acknowledgments). Which pages are included is a formatting option.
+CREATE TABLE project_front_matter (
+    project_id UUID PRIMARY KEY REFERENCES research_projects(id) ON DELETE CASCADE,
+    content JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE generated_documents DROP COLUMN IF EXISTS storage_key;
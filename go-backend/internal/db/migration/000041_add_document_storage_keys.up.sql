@@ -0,0 +1,3 @@
+-- Object key of a generated document in the configured storage backend. Documents
+-- generated before object storage have none and are served from file_path.
+ALTER TABLE generated_documents ADD COLUMN storage_key TEXT;
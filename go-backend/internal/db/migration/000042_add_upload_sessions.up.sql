@@ -0,0 +1,29 @@
+-- Resumable uploads of large source PDFs. The client sends the file in chunks at
+-- increasing offsets; each chunk is stored as its own object until the last one
+-- arrives and the file is assembled into a project upload.
+CREATE TABLE upload_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    total_size BIGINT NOT NULL CHECK (total_size > 0),
+    received_size BIGINT NOT NULL DEFAULT 0,
+    sha256 VARCHAR(64) NOT NULL DEFAULT '', -- Of the whole file, when the client gave it
+    status VARCHAR(20) NOT NULL DEFAULT 'receiving' CHECK (status IN ('receiving', 'assembling', 'completed', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    upload_id UUID REFERENCES project_uploads(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_upload_sessions_expires_at ON upload_sessions(expires_at);
+CREATE TRIGGER update_upload_sessions_updated_at BEFORE UPDATE ON upload_sessions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE upload_session_chunks (
+    session_id UUID NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    byte_offset BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    PRIMARY KEY (session_id, byte_offset)
+);
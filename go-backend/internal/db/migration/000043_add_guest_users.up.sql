@@ -0,0 +1,4 @@
+-- Trial accounts made without registering. A guest and its sandbox project are deleted
+-- once guest_expires_at passes, unless the guest registers first; NULL for everyone else.
+ALTER TABLE users ADD COLUMN guest_expires_at TIMESTAMPTZ;
+CREATE INDEX idx_users_guest_expires_at ON users(guest_expires_at) WHERE guest_expires_at IS NOT NULL;
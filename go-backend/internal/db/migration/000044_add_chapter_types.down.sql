@@ -0,0 +1,5 @@
+DELETE FROM chapters
+WHERE type NOT IN ('introduction', 'literature_review', 'methodology', 'results', 'conclusion', 'executive_summary');
+ALTER TABLE chapters ADD CONSTRAINT chapters_type_check
+    CHECK (type IN ('introduction', 'literature_review', 'methodology', 'results', 'conclusion', 'executive_summary'));
+DROP TABLE IF EXISTS chapter_types;
@@ -0,0 +1,25 @@
+-- Chapter types a project's chapters may have. The built-in ones have no organization;
+-- an organization adds its own for the templates its institution uses.
+CREATE TABLE chapter_types (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL CHECK (key ~ '^[a-z][a-z0-9_]*$'),
+    label VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX idx_chapter_types_builtin_key ON chapter_types(key) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX idx_chapter_types_organization_key ON chapter_types(organization_id, key) WHERE organization_id IS NOT NULL;
+
+INSERT INTO chapter_types (key, label, position) VALUES
+    ('introduction', 'Introduction', 1),
+    ('literature_review', 'Literature Review', 2),
+    ('methodology', 'Methodology', 3),
+    ('results', 'Results', 4),
+    ('conclusion', 'Conclusion', 5);
+
+-- Types are now checked against the registry when chapters are saved
+ALTER TABLE chapters DROP CONSTRAINT chapters_type_check;
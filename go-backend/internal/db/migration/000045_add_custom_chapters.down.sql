@@ -0,0 +1,11 @@
+ALTER TABLE chapters DROP COLUMN IF EXISTS custom_prompt;
+
+DELETE FROM chapters c
+USING chapter_types t
+WHERE t.project_id = c.project_id AND t.key = c.type;
+DELETE FROM chapter_types WHERE project_id IS NOT NULL;
+DROP INDEX IF EXISTS idx_chapter_types_project_key;
+DROP INDEX IF EXISTS idx_chapter_types_builtin_key;
+CREATE UNIQUE INDEX idx_chapter_types_builtin_key ON chapter_types(key) WHERE organization_id IS NULL;
+ALTER TABLE chapter_types DROP CONSTRAINT IF EXISTS chapter_types_single_owner;
+ALTER TABLE chapter_types DROP COLUMN IF EXISTS project_id;
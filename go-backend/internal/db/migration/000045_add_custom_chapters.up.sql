@@ -0,0 +1,10 @@
+-- Chapter types a project adds for itself, such as a theoretical framework or a case
+-- study, next to the built-in and organization ones
+ALTER TABLE chapter_types ADD COLUMN project_id UUID REFERENCES research_projects(id) ON DELETE CASCADE;
+ALTER TABLE chapter_types ADD CONSTRAINT chapter_types_single_owner CHECK (organization_id IS NULL OR project_id IS NULL);
+DROP INDEX idx_chapter_types_builtin_key;
+CREATE UNIQUE INDEX idx_chapter_types_builtin_key ON chapter_types(key) WHERE organization_id IS NULL AND project_id IS NULL;
+CREATE UNIQUE INDEX idx_chapter_types_project_key ON chapter_types(project_id, key) WHERE project_id IS NOT NULL;
+
+-- The student's own brief for generating the chapter; empty to use the type's default
+ALTER TABLE chapters ADD COLUMN custom_prompt TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_chapters_project_position;
+ALTER TABLE chapters DROP COLUMN IF EXISTS position;
@@ -0,0 +1,23 @@
+-- Chapters' order in the thesis, set by the reorder endpoint; new chapters go last.
+-- Existing chapters keep the order they were listed in by type.
+ALTER TABLE chapters ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+UPDATE chapters c
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (
+        PARTITION BY project_id
+        ORDER BY CASE type
+            WHEN 'introduction' THEN 1
+            WHEN 'literature_review' THEN 2
+            WHEN 'methodology' THEN 3
+            WHEN 'results' THEN 4
+            WHEN 'conclusion' THEN 5
+            ELSE 6
+        END, created_at
+    ) AS position
+    FROM chapters
+) ordered
+WHERE c.id = ordered.id;
+
+CREATE INDEX idx_chapters_project_position ON chapters(project_id, position);
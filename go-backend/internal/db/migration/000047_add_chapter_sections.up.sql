@@ -0,0 +1,16 @@
+-- Subsections of a chapter, written and regenerated on their own and numbered under
+-- their chapter in generated documents (1.1, 1.1.1...). They follow the chapter's content.
+CREATE TABLE chapter_sections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    title VARCHAR(300) NOT NULL,
+    level INT NOT NULL DEFAULT 1 CHECK (level BETWEEN 1 AND 3), -- 1 for 1.1, 2 for 1.1.1...
+    position INT NOT NULL,
+    content TEXT NOT NULL DEFAULT '',
+    word_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_chapter_sections_chapter_position ON chapter_sections(chapter_id, position);
+CREATE INDEX idx_chapter_sections_project_id ON chapter_sections(project_id);
@@ -0,0 +1,2 @@
+ALTER TABLE chapter_sections DROP COLUMN IF EXISTS generated_at;
+DROP TABLE IF EXISTS integrity_acknowledgments;
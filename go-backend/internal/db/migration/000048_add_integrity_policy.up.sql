@@ -0,0 +1,11 @@
+-- Users' acknowledgments of the deployment's academic-integrity policy, one per policy
+-- version; generation can be made to require one for the current version.
+CREATE TABLE integrity_acknowledgments (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    policy_version VARCHAR(50) NOT NULL,
+    acknowledged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, policy_version)
+);
+
+-- When the AI last wrote the section, so exports can mark AI-generated sections
+ALTER TABLE chapter_sections ADD COLUMN generated_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_generation_preferences;
+DROP TABLE IF EXISTS user_generation_preferences;
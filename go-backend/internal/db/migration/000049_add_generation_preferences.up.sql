@@ -0,0 +1,14 @@
+-- Generation preferences (temperature, reading level, English variant, verbosity) a user
+-- sets for everything they generate, and a project sets over them; missing keys fall back
+-- to the user's and then the defaults
+CREATE TABLE user_generation_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    preferences JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE project_generation_preferences (
+    project_id UUID PRIMARY KEY REFERENCES research_projects(id) ON DELETE CASCADE,
+    preferences JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,24 @@
+-- Every AI call made for a user or project: what was asked, with which model and
+-- parameters, and what came back, so users and support can see what produced a text.
+-- Responses are truncated; calls for a chapter are listed with it.
+CREATE TABLE generations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    project_id UUID REFERENCES research_projects(id) ON DELETE CASCADE,
+    chapter_id UUID REFERENCES chapters(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL, -- What was generated, e.g. literature_review, section, defense_questions
+    model VARCHAR(100) NOT NULL,
+    temperature DOUBLE PRECISION NOT NULL,
+    max_tokens INT NOT NULL,
+    prompt_hash VARCHAR(64) NOT NULL, -- SHA-256 of the messages sent
+    prompt JSONB NOT NULL, -- The messages sent, each cut to a length limit
+    response TEXT NOT NULL DEFAULT '',
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    completion_tokens INT NOT NULL DEFAULT 0,
+    latency_ms INT NOT NULL,
+    outcome VARCHAR(20) NOT NULL, -- success, error or leak (a reply that repeated its instructions)
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_generations_chapter_created ON generations(chapter_id, created_at DESC);
+CREATE INDEX idx_generations_project_created ON generations(project_id, created_at DESC);
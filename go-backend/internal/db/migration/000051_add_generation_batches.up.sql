@@ -0,0 +1,15 @@
+-- "Generate all chapters" runs: a project's chapters generated one after another in the
+-- background, in dependency order, with the progress of each chapter
+CREATE TABLE generation_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES research_projects(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL, -- Who started the run
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, running, completed or failed
+    chapters JSONB NOT NULL DEFAULT '[]', -- Each chapter's progress, in generation order
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+-- One run at a time per project
+CREATE UNIQUE INDEX idx_generation_batches_active ON generation_batches(project_id) WHERE status IN ('pending', 'running');
@@ -0,0 +1 @@
+ALTER TABLE organization_invitations DROP COLUMN IF EXISTS token_hash;
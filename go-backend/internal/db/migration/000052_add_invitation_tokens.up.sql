@@ -0,0 +1,3 @@
+-- An invitation is accepted with a random token emailed to the invitee; only its hash is kept.
+-- Invitations from before this migration have none and must be sent again.
+ALTER TABLE organization_invitations ADD COLUMN token_hash TEXT;
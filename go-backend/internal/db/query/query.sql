@@ -21,92 +21,168 @@ RETURNING *;
 
 -- name: CreateResearchProject :one
 INSERT INTO research_projects (
-    user_id, title, specialization, university, description
+    user_id, title, specialization, university, description, organization_id, language
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
+-- Projects are accessible to their owner, to members of the organization they
+-- belong to and to collaborators; project_access_role (migration 000010) decides the role
+
 -- name: GetUserResearchProjects :many
 SELECT * FROM research_projects
-WHERE user_id = $1
+WHERE research_projects.user_id = $1
+    OR organization_id IN (SELECT organization_id FROM organization_members WHERE organization_members.user_id = $1)
+    OR id IN (SELECT project_id FROM project_collaborators WHERE project_collaborators.user_id = $1)
+    OR advisor_id = $1
 ORDER BY created_at DESC;
 
--- name: GetResearchProjectByID :one
-SELECT * FROM research_projects
-WHERE id = $1 AND user_id = $2 LIMIT 1;
+-- name: GetProjectWithAccess :one
+SELECT sqlc.embed(research_projects), project_access_role(research_projects.id, @user_id)::text AS access_role
+FROM research_projects
+WHERE research_projects.id = @id AND project_access_role(research_projects.id, @user_id) IS NOT NULL
+LIMIT 1;
 
 -- name: UpdateResearchProject :one
 UPDATE research_projects
-SET title = $2, specialization = $3, university = $4, description = $5, status = $6, updated_at = NOW()
-WHERE id = $1 AND user_id = $7
+SET title = $2, specialization = $3, university = $4, description = $5, status = $6, language = $8, updated_at = NOW()
+WHERE id = $1 AND project_access_role(id, $7) IN ('owner', 'edit')
 RETURNING *;
 
 -- name: UpdateResearchProjectStatus :one
 UPDATE research_projects
 SET status = $2, updated_at = NOW()
-WHERE id = $1 AND user_id = $3
+WHERE id = $1 AND project_access_role(id, $3) IN ('owner', 'edit')
 RETURNING *;
 
--- name: DeleteResearchProject :exec
+-- name: DeleteResearchProject :execrows
+-- Only the owner, or an owner/admin of the project's organization, may delete it
 DELETE FROM research_projects
-WHERE id = $1 AND user_id = $2;
+WHERE id = $1 AND (research_projects.user_id = $2
+    OR organization_id IN (SELECT organization_id FROM organization_members WHERE organization_members.user_id = $2 AND role IN ('owner', 'admin')));
 
 -- name: CreateChapter :one
 INSERT INTO chapters (
-    project_id, type, title, content, word_count
+    project_id, type, title, content, word_count, target_word_count, custom_prompt, position
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM chapters WHERE project_id = $1) -- Last
 ) RETURNING *;
 
 -- name: GetChapterByID :one
 SELECT * FROM chapters
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetChaptersByProjectID :many
 SELECT * FROM chapters
-WHERE project_id = $1
-ORDER BY
-    CASE type
-        WHEN 'introduction' THEN 1
-        WHEN 'literature_review' THEN 2
-        WHEN 'methodology' THEN 3
-        WHEN 'results' THEN 4
-        WHEN 'conclusion' THEN 5
-        ELSE 6
-    END;
+WHERE project_id = $1 AND deleted_at IS NULL
+ORDER BY position, created_at;
+
+-- name: GetProjectChapterByID :one
+SELECT * FROM chapters
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: GetChapterByProjectIDAndType :one
 SELECT * FROM chapters
-WHERE project_id = $1 AND type = $2 LIMIT 1;
+WHERE project_id = $1 AND type = $2 AND deleted_at IS NULL LIMIT 1;
 
 -- name: UpdateChapter :one
+-- expected_version is optional; when set the update only applies if nobody else saved in between.
+-- custom_prompt is kept when NULL.
+UPDATE chapters
+SET title = @title, content = @content, word_count = @word_count, status = @status, target_word_count = @target_word_count,
+    custom_prompt = COALESCE(sqlc.narg('custom_prompt'), custom_prompt), version = version + 1, updated_at = NOW()
+WHERE chapters.id = @id AND deleted_at IS NULL
+    AND project_id = @project_id AND project_access_role(@project_id, @user_id) IN ('owner', 'edit') -- ensure user can edit project
+    AND (sqlc.narg('expected_version')::int IS NULL OR version = sqlc.narg('expected_version')::int)
+RETURNING *;
+
+-- name: DeleteChapter :execrows
+-- Soft delete: the chapter moves to the project's trash
+UPDATE chapters
+SET deleted_at = NOW()
+WHERE chapters.id = $1 AND deleted_at IS NULL
+    AND project_id = $2 AND project_access_role($2, $3) IN ('owner', 'edit');
+
+-- name: ListTrashedChapters :many
+SELECT * FROM chapters
+WHERE project_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: RestoreChapter :one
 UPDATE chapters
-SET title = $2, content = $3, word_count = $4, status = $5, updated_at = NOW()
-WHERE chapters.id = $1 AND project_id = (SELECT project_id FROM research_projects WHERE research_projects.id = $6 AND user_id = $7) -- ensure user owns project
+SET deleted_at = NULL
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NOT NULL
 RETURNING *;
 
--- name: DeleteChapter :exec
+-- name: PurgeTrashedChapters :execrows
 DELETE FROM chapters
-WHERE chapters.id = $1 AND project_id = (SELECT project_id FROM research_projects WHERE research_projects.id = $2 AND user_id = $3);
+WHERE deleted_at IS NOT NULL AND deleted_at < $1;
 
 
 -- name: CreateReference :one
 INSERT INTO "references" ( -- Quoted
-    project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla
+    project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, abstract
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 ) RETURNING *;
 
 -- name: GetReferencesByProjectID :many
 SELECT * FROM "references" -- Quoted
-WHERE project_id = $1
+WHERE project_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
--- name: DeleteReference :exec
-DELETE FROM "references" -- Quoted
-WHERE id = $1 AND project_id = $2;
+-- name: DeleteReference :execrows
+-- Soft delete: the reference moves to the project's trash
+UPDATE "references" -- Quoted
+SET deleted_at = NOW()
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
 -- Ensure user owns project for delete if needed, or handled at service layer
 
+-- name: ListTrashedReferences :many
+SELECT * FROM "references"
+WHERE project_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: RestoreReference :one
+UPDATE "references"
+SET deleted_at = NULL
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeTrashedReferences :execrows
+DELETE FROM "references"
+WHERE deleted_at IS NOT NULL AND deleted_at < $1;
+
+-- name: UpsertReferenceEmbedding :exec
+INSERT INTO reference_embeddings (reference_id, model, embedding)
+VALUES (@reference_id, @model, @embedding::vector)
+ON CONFLICT (reference_id) DO UPDATE
+SET model = EXCLUDED.model, embedding = EXCLUDED.embedding, created_at = NOW();
+
+-- name: GetReferencesForEmbedding :many
+SELECT id, title, abstract FROM "references"
+WHERE id = ANY(@ids::uuid[]) AND deleted_at IS NULL;
+
+-- name: ListReferencesMissingEmbedding :many
+-- References without an embedding for the current model, oldest first
+SELECT r.id, r.title, r.abstract
+FROM "references" r
+LEFT JOIN reference_embeddings e ON e.reference_id = r.id AND e.model = @model
+WHERE r.deleted_at IS NULL AND e.reference_id IS NULL
+ORDER BY r.created_at
+LIMIT @result_limit;
+
+-- name: SimilarProjectReferences :many
+SELECT r.id, r.project_id, r.title, r.authors, r.journal, r.publication_year, r.doi, r.url,
+    r.citation_apa, r.citation_mla, r.abstract, r.created_at,
+    (1 - (e.embedding <=> @embedding::vector))::real AS similarity
+FROM "references" r
+JOIN reference_embeddings e ON e.reference_id = r.id AND e.model = @model
+WHERE r.project_id = @project_id AND r.deleted_at IS NULL
+ORDER BY e.embedding <=> @embedding::vector
+LIMIT @result_limit;
+
 -- name: CreateSession :one
 INSERT INTO sessions (
     id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at
@@ -152,10 +228,1232 @@ RETURNING *;
 
 -- name: UpdateGeneratedDocument :one
 UPDATE generated_documents
-SET file_name = $2, file_path = $3, file_size = $4, mime_type = $5, status = $6
+SET file_name = $2, file_path = $3, file_size = $4, mime_type = $5, status = $6, storage_key = $7
 WHERE id = $1
 RETURNING *;
 
 -- name: DeleteGeneratedDocument :exec
 DELETE FROM generated_documents
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: CreateIdempotencyKey :one
+-- Returns no rows when the key already exists for the user
+INSERT INTO idempotency_keys (
+    user_id, idempotency_key, request_method, request_path, request_hash
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: CompleteIdempotencyKey :one
+UPDATE idempotency_keys
+SET response_status = $2, response_body = $3, response_hash = $4, completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE id = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE created_at < $1;
+
+-- name: SearchProjectChapters :many
+-- Matches are delimited with STX and ETX, not HTML: the text is user content and is escaped first
+SELECT id, type, title,
+    ts_rank(search_vector, websearch_to_tsquery('english', @query::text))::real AS rank,
+    ts_headline('english', coalesce(content, ''), websearch_to_tsquery('english', @query::text),
+        E'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=\x02, StopSel=\x03')::text AS snippet
+FROM chapters
+WHERE project_id = @project_id AND deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', @query::text)
+ORDER BY rank DESC
+LIMIT @result_limit;
+
+-- name: SearchProjectReferences :many
+SELECT id, title,
+    ts_rank(search_vector, websearch_to_tsquery('english', @query::text))::real AS rank,
+    ts_headline('english', coalesce(abstract, title), websearch_to_tsquery('english', @query::text),
+        E'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=\x02, StopSel=\x03')::text AS snippet
+FROM "references"
+WHERE project_id = @project_id AND deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', @query::text)
+ORDER BY rank DESC
+LIMIT @result_limit;
+
+-- name: ListFeatureFlags :many
+SELECT * FROM feature_flags
+ORDER BY name;
+
+-- name: UpsertFeatureFlag :one
+INSERT INTO feature_flags (name, enabled)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE
+SET enabled = EXCLUDED.enabled, updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteFeatureFlag :exec
+DELETE FROM feature_flags
+WHERE name = $1;
+
+-- name: CreateOrganization :one
+INSERT INTO organizations (name, created_by)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetOrganizationByID :one
+SELECT * FROM organizations
+WHERE id = $1 LIMIT 1;
+
+-- name: ListUserOrganizations :many
+SELECT organizations.*, organization_members.role
+FROM organizations
+JOIN organization_members ON organization_members.organization_id = organizations.id
+WHERE organization_members.user_id = $1
+ORDER BY organizations.name;
+
+-- name: AddOrganizationMember :one
+-- An existing member keeps their current role
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, user_id) DO UPDATE SET role = organization_members.role
+RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members
+WHERE organization_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT organization_members.*, users.email, users.first_name, users.last_name
+FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+ORDER BY organization_members.created_at;
+
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_members
+WHERE organization_id = $1 AND role = 'owner';
+
+-- name: UpsertOrganizationInvitation :one
+-- Inviting the same email again refreshes the role, expiry and token
+INSERT INTO organization_invitations (organization_id, email, role, invited_by, expires_at, token_hash)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organization_id, lower(email)) DO UPDATE
+SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, expires_at = EXCLUDED.expires_at, token_hash = EXCLUDED.token_hash, created_at = NOW()
+RETURNING *;
+
+-- name: GetOrganizationInvitation :one
+SELECT * FROM organization_invitations
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOrganizationInvitations :many
+SELECT * FROM organization_invitations
+WHERE organization_id = $1 AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: ListInvitationsForEmail :many
+SELECT organization_invitations.*, organizations.name AS organization_name
+FROM organization_invitations
+JOIN organizations ON organizations.id = organization_invitations.organization_id
+WHERE lower(organization_invitations.email) = lower(@email::text) AND organization_invitations.expires_at > NOW()
+ORDER BY organization_invitations.created_at DESC;
+
+-- name: DeleteOrganizationInvitation :exec
+DELETE FROM organization_invitations
+WHERE id = $1;
+
+-- name: ListOrganizationProjects :many
+SELECT * FROM research_projects
+WHERE organization_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpsertProjectCollaborator :one
+INSERT INTO project_collaborators (project_id, user_id, role, added_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, user_id) DO UPDATE
+SET role = EXCLUDED.role, updated_at = NOW()
+RETURNING *;
+
+-- name: ListProjectCollaborators :many
+SELECT project_collaborators.*, users.email, users.first_name, users.last_name
+FROM project_collaborators
+JOIN users ON users.id = project_collaborators.user_id
+WHERE project_collaborators.project_id = $1
+ORDER BY project_collaborators.created_at;
+
+-- name: DeleteProjectCollaborator :execrows
+DELETE FROM project_collaborators
+WHERE project_id = $1 AND user_id = $2;
+
+-- name: CreateCommentThread :one
+INSERT INTO comment_threads (
+    project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetCommentThread :one
+SELECT * FROM comment_threads
+WHERE id = $1 AND project_id = $2 LIMIT 1;
+
+-- name: ListChapterCommentThreads :many
+SELECT * FROM comment_threads
+WHERE chapter_id = $1
+    AND (sqlc.narg('resolved')::boolean IS NULL OR (resolved_at IS NOT NULL) = sqlc.narg('resolved')::boolean)
+ORDER BY anchor_start NULLS LAST, created_at;
+
+-- name: SetCommentThreadResolved :one
+UPDATE comment_threads
+SET resolved_at = CASE WHEN @resolved::boolean THEN NOW() END,
+    resolved_by = CASE WHEN @resolved::boolean THEN sqlc.narg('resolved_by')::uuid END
+WHERE id = @id AND project_id = @project_id
+RETURNING *;
+
+-- name: CreateComment :one
+INSERT INTO comments (thread_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListCommentThreadParticipants :many
+SELECT DISTINCT user_id FROM comments
+WHERE thread_id = $1 AND user_id IS NOT NULL;
+
+-- name: ListChapterComments :many
+SELECT comments.*, users.first_name, users.last_name
+FROM comments
+JOIN comment_threads ON comment_threads.id = comments.thread_id
+LEFT JOIN users ON users.id = comments.user_id
+WHERE comment_threads.chapter_id = $1
+ORDER BY comments.created_at;
+
+-- name: CountOpenCommentThreads :many
+SELECT chapter_id, COUNT(*) AS open_count
+FROM comment_threads
+WHERE project_id = $1 AND resolved_at IS NULL
+GROUP BY chapter_id;
+
+-- name: SetProjectAdvisor :one
+UPDATE research_projects
+SET advisor_id = sqlc.narg('advisor_id')
+WHERE id = @id
+RETURNING *;
+
+-- name: SetChapterStatus :one
+-- Used by the review workflow; bumps version so concurrent edits based on the old status conflict
+UPDATE chapters
+SET status = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: CreateChapterReview :one
+INSERT INTO chapter_reviews (project_id, chapter_id, chapter_version, submitted_by, submission_note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPendingChapterReview :one
+SELECT * FROM chapter_reviews
+WHERE chapter_id = $1 AND status = 'pending'
+LIMIT 1
+FOR UPDATE;
+
+-- name: DecideChapterReview :one
+UPDATE chapter_reviews
+SET status = $2, reviewed_by = $3, decision_note = $4, decided_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ListChapterReviews :many
+SELECT * FROM chapter_reviews
+WHERE chapter_id = $1
+ORDER BY submitted_at DESC;
+
+-- name: ListReviewQueue :many
+SELECT sqlc.embed(chapter_reviews), chapters.title AS chapter_title, chapters.type AS chapter_type, research_projects.title AS project_title
+FROM chapter_reviews
+JOIN research_projects ON research_projects.id = chapter_reviews.project_id
+JOIN chapters ON chapters.id = chapter_reviews.chapter_id
+WHERE research_projects.advisor_id = $1 AND chapter_reviews.status = 'pending' AND chapters.deleted_at IS NULL
+ORDER BY chapter_reviews.submitted_at;
+
+-- name: CreateNotification :one
+INSERT INTO notifications (
+    user_id, type, project_id, chapter_id, document_id, title, body, in_app, email_status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListUserNotifications :many
+SELECT * FROM notifications
+WHERE user_id = @user_id AND in_app
+    AND (NOT @unread_only::boolean OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT @row_limit;
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1 AND in_app AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2 AND in_app;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND in_app AND read_at IS NULL;
+
+-- name: ClaimPendingNotificationEmails :many
+-- Locks a batch for the calling transaction; other workers skip it
+SELECT notifications.*, users.email, users.first_name
+FROM notifications
+JOIN users ON users.id = notifications.user_id
+WHERE notifications.email_status = 'pending'
+ORDER BY notifications.created_at
+LIMIT $1
+FOR UPDATE OF notifications SKIP LOCKED;
+
+-- name: UpdateNotificationEmailStatus :exec
+UPDATE notifications
+SET email_status = $2, email_attempts = email_attempts + 1,
+    email_sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+WHERE id = $1;
+
+-- name: ListNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (user_id, type, in_app, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, type) DO UPDATE
+SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, updated_at = NOW()
+RETURNING *;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (project_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = $1 AND project_id = $2;
+
+-- name: ListProjectWebhooks :many
+SELECT * FROM webhooks
+WHERE project_id = $1
+ORDER BY created_at;
+
+-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = $3, events = $4, active = $5
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND project_id = $2;
+
+-- name: ListActiveWebhooksForEvent :many
+SELECT * FROM webhooks
+WHERE project_id = @project_id AND active
+    AND (cardinality(events) = 0 OR @event::text = ANY(events));
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, webhook_id, event, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = @webhook_id
+ORDER BY created_at DESC
+LIMIT @row_limit;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases a batch by moving next_attempt_at to leased_until, so other workers skip it
+-- without a transaction being held open while the HTTP requests run
+UPDATE webhook_deliveries
+SET next_attempt_at = @leased_until
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id
+    AND webhook_deliveries.id IN (
+        SELECT due.id FROM webhook_deliveries due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+        ORDER BY due.next_attempt_at
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING sqlc.embed(webhook_deliveries), webhooks.url, webhooks.secret;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = @status, attempts = attempts + 1, next_attempt_at = @next_attempt_at,
+    response_status = @response_status, last_error = @last_error, duration_ms = @duration_ms,
+    delivered_at = CASE WHEN @status = 'succeeded' THEN NOW() END
+WHERE id = @id;
+
+-- name: RetryWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', next_attempt_at = NOW()
+WHERE id = $1 AND webhook_id = $2
+RETURNING *;
+
+-- name: GetSubscription :one
+SELECT * FROM subscriptions
+WHERE user_id = $1;
+
+-- name: CreateBillingCustomer :one
+-- Keeps the existing customer when two checkouts race
+INSERT INTO subscriptions (user_id, stripe_customer_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET user_id = subscriptions.user_id
+RETURNING *;
+
+-- name: UpdateSubscriptionFromStripe :one
+UPDATE subscriptions
+SET stripe_subscription_id = $2, plan = $3, status = $4, current_period_end = $5, cancel_at_period_end = $6
+WHERE stripe_customer_id = $1
+RETURNING *;
+
+-- name: RecordStripeEvent :execrows
+INSERT INTO stripe_events (id, type)
+VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: CountOwnedProjects :one
+SELECT COUNT(*) FROM research_projects
+WHERE user_id = $1;
+
+-- name: ReserveUsage :one
+-- Returns no row when the limit is already reached
+INSERT INTO billing_usage (user_id, period_start, resource, used)
+VALUES (@user_id, @period_start, @resource, 1)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = billing_usage.used + 1
+WHERE billing_usage.used < @usage_limit::int
+RETURNING used;
+
+-- name: ReleaseUsage :exec
+UPDATE billing_usage
+SET used = used - 1
+WHERE user_id = $1 AND period_start = $2 AND resource = $3 AND used > 0;
+
+-- name: ListUsage :many
+SELECT resource, used FROM billing_usage
+WHERE user_id = $1 AND period_start = $2;
+
+-- name: ReserveQuota :one
+-- Returns no row when the amount would take usage past the limit
+INSERT INTO quota_usage (user_id, period_start, resource, used)
+VALUES (@user_id, @period_start, @resource, @amount)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = quota_usage.used + EXCLUDED.used
+WHERE quota_usage.used + EXCLUDED.used <= @quota_limit::bigint
+RETURNING used;
+
+-- name: AddQuotaUsage :exec
+-- Records usage known only after the fact, e.g. words generated, even past the limit
+INSERT INTO quota_usage (user_id, period_start, resource, used)
+VALUES (@user_id, @period_start, @resource, @amount)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = quota_usage.used + EXCLUDED.used;
+
+-- name: ReleaseQuota :exec
+UPDATE quota_usage
+SET used = GREATEST(used - @amount, 0)
+WHERE user_id = @user_id AND period_start = @period_start AND resource = @resource;
+
+-- name: ListQuotaUsage :many
+SELECT resource, used FROM quota_usage
+WHERE user_id = $1 AND period_start = $2;
+
+-- name: EnqueueJob :one
+-- Returns no row when unique_key is already taken
+INSERT INTO jobs (kind, payload, max_attempts, run_at, unique_key)
+VALUES (@kind, @payload, @max_attempts, @run_at, sqlc.narg('unique_key'))
+ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- name: ClaimDueJobs :many
+-- Leases a batch by marking it running until leased_until. A running job whose
+-- lease ran out, because its worker died, is claimed again.
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, run_at = @leased_until
+WHERE id IN (
+    SELECT due.id FROM jobs due
+    WHERE due.status IN ('pending', 'running') AND due.run_at <= NOW()
+        AND due.kind = ANY(@kinds::text[])
+    ORDER BY due.run_at
+    LIMIT @batch_size
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+-- Matching attempts stops a worker whose lease ran out from overwriting a newer run
+UPDATE jobs
+SET status = 'completed', last_error = '', completed_at = NOW()
+WHERE id = @id AND attempts = @attempts AND status = 'running';
+
+-- name: FailJob :exec
+UPDATE jobs
+SET status = @status, run_at = @run_at, last_error = @last_error
+WHERE id = @id AND attempts = @attempts AND status = 'running';
+
+-- name: GetJob :one
+SELECT * FROM jobs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListJobs :many
+SELECT * FROM jobs
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+    AND (sqlc.narg('kind')::text IS NULL OR kind = sqlc.narg('kind')::text)
+ORDER BY created_at DESC
+LIMIT @row_limit;
+
+-- name: RequeueJob :one
+-- Starts a job over from its first attempt; running jobs are left alone
+UPDATE jobs
+SET status = 'pending', attempts = 0, run_at = NOW(), last_error = '', completed_at = NULL
+WHERE id = $1 AND status <> 'running'
+RETURNING *;
+
+-- name: DeleteCompletedJobs :execrows
+DELETE FROM jobs
+WHERE status = 'completed' AND completed_at < $1;
+
+-- name: CreateProjectUpload :one
+INSERT INTO project_uploads (id, project_id, uploaded_by, file_name, file_path, file_size, sha256)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetProjectUpload :one
+SELECT * FROM project_uploads
+WHERE id = $1 AND project_id = $2;
+
+-- name: GetUpload :one
+SELECT * FROM project_uploads
+WHERE id = $1 LIMIT 1;
+
+-- name: ListProjectUploads :many
+SELECT * FROM project_uploads
+WHERE project_id = $1
+ORDER BY created_at DESC;
+
+-- name: DeleteProjectUpload :one
+DELETE FROM project_uploads
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: SaveUploadText :exec
+INSERT INTO project_upload_texts (upload_id, content)
+VALUES ($1, $2)
+ON CONFLICT (upload_id) DO UPDATE SET content = EXCLUDED.content;
+
+-- name: GetUploadText :one
+SELECT content FROM project_upload_texts
+WHERE upload_id = $1;
+
+-- name: SetUploadExtraction :exec
+UPDATE project_uploads
+SET extraction_status = @extraction_status, extraction_error = @extraction_error, page_count = @page_count
+WHERE id = @id;
+
+-- name: ListProjectSourceTexts :many
+-- Extracted text of a project's uploads, oldest first, for AI generation
+SELECT project_uploads.id, project_uploads.file_name, project_upload_texts.content
+FROM project_uploads
+JOIN project_upload_texts ON project_upload_texts.upload_id = project_uploads.id
+WHERE project_uploads.project_id = $1 AND project_uploads.extraction_status = 'completed'
+ORDER BY project_uploads.created_at;
+
+-- name: GetProjectGuideline :one
+SELECT * FROM project_guidelines
+WHERE project_id = $1;
+
+-- name: GetGuideline :one
+SELECT * FROM project_guidelines
+WHERE id = $1 LIMIT 1;
+
+-- name: UpsertProjectGuideline :one
+-- Replacing a guideline gives it a new id, so extraction jobs for the old file find nothing
+INSERT INTO project_guidelines (id, project_id, uploaded_by, file_name, file_path, mime_type, file_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id) DO UPDATE
+SET id = EXCLUDED.id, uploaded_by = EXCLUDED.uploaded_by, file_name = EXCLUDED.file_name,
+    file_path = EXCLUDED.file_path, mime_type = EXCLUDED.mime_type, file_size = EXCLUDED.file_size,
+    status = 'pending', error = '', rules = '{}'
+RETURNING *;
+
+-- name: SetGuidelineResult :exec
+UPDATE project_guidelines
+SET status = @status, error = @error, rules = @rules
+WHERE id = @id;
+
+-- name: DeleteProjectGuideline :one
+DELETE FROM project_guidelines
+WHERE project_id = $1
+RETURNING *;
+
+-- name: GetProjectFormatting :one
+SELECT options FROM project_formatting
+WHERE project_id = $1;
+
+-- name: UpsertProjectFormatting :exec
+INSERT INTO project_formatting (project_id, options)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET options = EXCLUDED.options, updated_at = NOW();
+
+-- name: GetProjectFrontMatter :one
+SELECT content FROM project_front_matter
+WHERE project_id = $1;
+
+-- name: UpsertProjectFrontMatter :exec
+INSERT INTO project_front_matter (project_id, content)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET content = EXCLUDED.content, updated_at = NOW();
+
+-- name: CreateChapterFigure :one
+INSERT INTO chapter_figures (id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetChapterFigure :one
+SELECT * FROM chapter_figures
+WHERE id = $1 AND chapter_id = $2 LIMIT 1;
+
+-- name: ListChapterFigures :many
+SELECT * FROM chapter_figures
+WHERE chapter_id = $1
+ORDER BY position, created_at;
+
+-- name: ListProjectFigures :many
+-- Figures and tables of all a project's chapters, in numbering order within each chapter
+SELECT * FROM chapter_figures
+WHERE project_id = $1
+ORDER BY chapter_id, position, created_at;
+
+-- name: UpdateChapterFigure :one
+UPDATE chapter_figures
+SET caption = $1, position = $2, table_data = $3
+WHERE id = $4
+RETURNING *;
+
+-- name: DeleteChapterFigure :one
+DELETE FROM chapter_figures
+WHERE id = $1 AND chapter_id = $2
+RETURNING *;
+
+-- name: CreateAppendix :one
+INSERT INTO project_appendices (project_id, title, content, position, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAppendix :one
+SELECT * FROM project_appendices
+WHERE id = $1 AND project_id = $2 LIMIT 1;
+
+-- name: ListProjectAppendices :many
+-- In lettering order
+SELECT * FROM project_appendices
+WHERE project_id = $1
+ORDER BY position, created_at;
+
+-- name: UpdateAppendix :one
+UPDATE project_appendices
+SET title = $1, content = $2, position = $3
+WHERE id = $4
+RETURNING *;
+
+-- name: SetAppendixFile :one
+UPDATE project_appendices
+SET file_path = $1, mime_type = $2
+WHERE id = $3
+RETURNING *;
+
+-- name: DeleteAppendix :one
+DELETE FROM project_appendices
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: UpsertProjectTimeline :one
+-- A regenerated timeline replaces the project's previous one
+INSERT INTO project_timelines (project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id) DO UPDATE
+SET start_date = EXCLUDED.start_date, end_date = EXCLUDED.end_date, milestones = EXCLUDED.milestones, tasks = EXCLUDED.tasks,
+    include_in_document = EXCLUDED.include_in_document, generated_by = EXCLUDED.generated_by
+RETURNING *;
+
+-- name: GetProjectTimeline :one
+SELECT * FROM project_timelines
+WHERE project_id = $1 LIMIT 1;
+
+-- name: SetTimelineInDocument :one
+UPDATE project_timelines
+SET include_in_document = $1
+WHERE project_id = $2
+RETURNING *;
+
+-- name: DeleteProjectTimeline :one
+DELETE FROM project_timelines
+WHERE project_id = $1
+RETURNING *;
+
+-- name: GetProjectMethodology :one
+SELECT * FROM project_methodology
+WHERE project_id = $1;
+
+-- name: UpsertProjectMethodology :one
+INSERT INTO project_methodology (project_id, answers, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id) DO UPDATE
+SET answers = EXCLUDED.answers, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING *;
+
+-- name: CreateQuestionnaire :one
+INSERT INTO questionnaires (project_id, title, instructions, sections, include_in_document, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetQuestionnaire :one
+SELECT * FROM questionnaires
+WHERE id = $1 AND project_id = $2 LIMIT 1;
+
+-- name: ListProjectQuestionnaires :many
+SELECT * FROM questionnaires
+WHERE project_id = $1
+ORDER BY created_at;
+
+-- name: UpdateQuestionnaire :one
+UPDATE questionnaires
+SET title = $1, instructions = $2, sections = $3, include_in_document = $4
+WHERE id = $5
+RETURNING *;
+
+-- name: DeleteQuestionnaire :one
+DELETE FROM questionnaires
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: CreateProjectDataset :one
+INSERT INTO project_datasets (project_id, uploaded_by, file_name, row_count, columns)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetProjectDataset :one
+SELECT * FROM project_datasets
+WHERE id = $1 AND project_id = $2 LIMIT 1;
+
+-- name: ListProjectDatasets :many
+SELECT * FROM project_datasets
+WHERE project_id = $1
+ORDER BY created_at;
+
+-- name: SetDatasetTables :exec
+UPDATE project_datasets
+SET table_ids = $1
+WHERE id = $2;
+
+-- name: DeleteProjectDataset :one
+DELETE FROM project_datasets
+WHERE id = $1 AND project_id = $2
+RETURNING *;
+
+-- name: DeleteFiguresByID :exec
+DELETE FROM chapter_figures
+WHERE project_id = $1 AND id = ANY($2::uuid[]);
+
+-- name: GetProjectReference :one
+SELECT * FROM "references"
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetReferenceNotes :one
+SELECT * FROM reference_notes
+WHERE reference_id = $1;
+
+-- name: UpsertReferenceNotes :one
+INSERT INTO reference_notes (reference_id, project_id, notes, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (reference_id) DO UPDATE
+SET notes = EXCLUDED.notes, updated_by = EXCLUDED.updated_by
+RETURNING *;
+
+-- name: ListProjectAnnotatedReferences :many
+-- References in use with notes or highlights, for literature review generation
+SELECT r.id, r.title, r.authors, r.publication_year, COALESCE(n.notes, '') AS notes
+FROM "references" r
+LEFT JOIN reference_notes n ON n.reference_id = r.id
+WHERE r.project_id = $1 AND r.deleted_at IS NULL
+  AND (COALESCE(n.notes, '') <> '' OR EXISTS (SELECT 1 FROM reference_highlights h WHERE h.reference_id = r.id))
+ORDER BY r.created_at;
+
+-- name: ListReferenceHighlights :many
+SELECT * FROM reference_highlights
+WHERE reference_id = $1
+ORDER BY created_at;
+
+-- name: ListProjectReferenceHighlights :many
+SELECT * FROM reference_highlights
+WHERE project_id = $1
+ORDER BY reference_id, created_at;
+
+-- name: CreateReferenceHighlight :one
+INSERT INTO reference_highlights (reference_id, project_id, text, comment, page, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteReferenceHighlight :one
+DELETE FROM reference_highlights
+WHERE id = $1 AND reference_id = $2
+RETURNING *;
+
+-- name: ListProjectReferenceTags :many
+SELECT * FROM reference_tags
+WHERE project_id = $1
+ORDER BY reference_id, tag;
+
+-- name: DeleteReferenceTags :exec
+DELETE FROM reference_tags
+WHERE reference_id = $1;
+
+-- name: AddReferenceTag :exec
+INSERT INTO reference_tags (reference_id, project_id, tag)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetReferencesByTags :many
+-- References in use carrying any of the tags
+SELECT * FROM "references"
+WHERE project_id = @project_id AND deleted_at IS NULL
+  AND id IN (SELECT reference_id FROM reference_tags WHERE tag = ANY(@tags::text[]))
+ORDER BY created_at DESC;
+
+-- name: ListProjectTaggedReferences :many
+-- References in use carrying a tag, with any notes, for scoped literature review generation
+SELECT r.id, r.title, r.authors, r.publication_year, r.abstract, COALESCE(n.notes, '') AS notes
+FROM "references" r
+JOIN reference_tags t ON t.reference_id = r.id
+LEFT JOIN reference_notes n ON n.reference_id = r.id
+WHERE r.project_id = @project_id AND r.deleted_at IS NULL AND t.tag = @tag
+ORDER BY r.publication_year NULLS LAST, r.created_at;
+
+-- name: ListChapterCitations :many
+SELECT * FROM chapter_citations
+WHERE chapter_id = $1
+ORDER BY position;
+
+-- name: DeleteChapterCitations :exec
+DELETE FROM chapter_citations
+WHERE chapter_id = $1;
+
+-- name: CreateChapterCitation :exec
+-- A concurrent rebuild may have written the same marker already
+INSERT INTO chapter_citations (chapter_id, project_id, reference_id, marker, author, year, position, chapter_version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (chapter_id, position) DO NOTHING;
+
+-- name: GetProjectReferencesByIDs :many
+-- Including references in the trash, which chapters may still cite
+SELECT * FROM "references"
+WHERE project_id = @project_id AND id = ANY(@ids::uuid[])
+ORDER BY created_at;
+
+-- name: CreateReferencePaper :exec
+INSERT INTO reference_papers (reference_id, project_id, paper_id, open_access_pdf_url, pdf_status)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetReferencePaper :one
+SELECT * FROM reference_papers
+WHERE reference_id = $1 LIMIT 1;
+
+-- name: ListProjectPaperIDs :many
+-- Papers already imported, including references in the trash
+SELECT paper_id FROM reference_papers
+WHERE project_id = $1;
+
+-- name: SetReferencePaperPDF :exec
+UPDATE reference_papers
+SET pdf_status = $1, pdf_error = $2, pdf_path = $3, pdf_size = $4
+WHERE reference_id = $5;
+
+-- name: ListPurgeableReferencePDFs :many
+-- Files of the papers PurgeTrashedReferences is about to delete
+SELECT rp.pdf_path FROM reference_papers rp
+JOIN "references" r ON r.id = rp.reference_id
+WHERE r.deleted_at IS NOT NULL AND r.deleted_at < $1 AND rp.pdf_path <> '';
+
+-- name: DeleteReferencePaperChunks :exec
+DELETE FROM reference_paper_chunks
+WHERE reference_id = $1;
+
+-- name: CreateReferencePaperChunks :exec
+INSERT INTO reference_paper_chunks (reference_id, project_id, position, content)
+SELECT @reference_id, @project_id, c.ord - 1, c.content
+FROM unnest(@contents::text[]) WITH ORDINALITY AS c(content, ord);
+
+-- name: ListRelevantPaperChunks :many
+-- Full-text passages of the project's imported papers that match the query, best first
+SELECT c.reference_id, c.position, c.content, r.title, r.authors, r.publication_year,
+    ts_rank(c.search_vector, websearch_to_tsquery('english', @query::text))::real AS rank
+FROM reference_paper_chunks c
+JOIN "references" r ON r.id = c.reference_id
+WHERE c.project_id = @project_id AND r.deleted_at IS NULL AND c.search_vector @@ websearch_to_tsquery('english', @query::text)
+ORDER BY rank DESC, c.reference_id, c.position
+LIMIT @max_chunks;
+
+-- name: ListProjectPaperSeeds :many
+-- The project's references that Semantic Scholar can identify, newest first
+SELECT rp.paper_id, r.doi FROM "references" r
+LEFT JOIN reference_papers rp ON rp.reference_id = r.id
+WHERE r.project_id = $1 AND r.deleted_at IS NULL AND (rp.paper_id IS NOT NULL OR r.doi IS NOT NULL)
+ORDER BY r.created_at DESC;
+
+-- name: MergeReference :one
+-- Fills in what a reference is missing from a duplicate of it, keeping what it has
+UPDATE "references"
+SET authors = COALESCE(NULLIF(authors, ''), $2),
+    journal = COALESCE(NULLIF(journal, ''), $3),
+    publication_year = COALESCE(publication_year, $4),
+    doi = COALESCE(NULLIF(doi, ''), $5),
+    url = COALESCE(NULLIF(url, ''), $6),
+    citation_apa = COALESCE(NULLIF(citation_apa, ''), $7),
+    citation_mla = COALESCE(NULLIF(citation_mla, ''), $8),
+    abstract = COALESCE(NULLIF(abstract, ''), $9)
+WHERE id = $1
+RETURNING *;
+
+-- name: UpsertChapterDraft :one
+-- Merges an autosave into the editor's draft; a NULL title or content keeps the draft's
+INSERT INTO chapter_drafts (chapter_id, user_id, project_id, title, content, base_version)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (chapter_id, user_id) DO UPDATE
+SET title = COALESCE(EXCLUDED.title, chapter_drafts.title),
+    content = COALESCE(EXCLUDED.content, chapter_drafts.content),
+    base_version = EXCLUDED.base_version,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetChapterDraft :one
+SELECT * FROM chapter_drafts
+WHERE chapter_id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: DeleteChapterDraft :exec
+DELETE FROM chapter_drafts
+WHERE chapter_id = $1 AND user_id = $2;
+
+-- name: ListChapterVersions :many
+-- Newest first, without content
+SELECT version, title, word_count, status, created_at FROM chapter_versions
+WHERE chapter_id = $1
+ORDER BY version DESC;
+
+-- name: GetChapterVersion :one
+SELECT * FROM chapter_versions
+WHERE chapter_id = $1 AND version = $2 LIMIT 1;
+
+-- name: GetChapterProvenance :one
+SELECT * FROM chapter_provenance
+WHERE chapter_id = $1 LIMIT 1;
+
+-- name: UpsertChapterProvenance :exec
+-- A save that lost a race to a newer one leaves the newer spans in place
+INSERT INTO chapter_provenance (chapter_id, project_id, version, spans)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chapter_id) DO UPDATE
+SET version = EXCLUDED.version, spans = EXCLUDED.spans, updated_at = NOW()
+WHERE chapter_provenance.version <= EXCLUDED.version;
+
+-- name: GetChapterMetrics :one
+SELECT * FROM chapter_metrics
+WHERE chapter_id = $1 LIMIT 1;
+
+-- name: UpsertChapterMetrics :one
+INSERT INTO chapter_metrics (chapter_id, project_id, version, metrics)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chapter_id) DO UPDATE
+SET version = EXCLUDED.version, metrics = EXCLUDED.metrics, computed_at = NOW()
+RETURNING *;
+
+-- name: CreateChapterEvaluation :one
+INSERT INTO chapter_evaluations (project_id, chapter_id, chapter_version, rubric, criteria, summary, evaluated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListChapterEvaluations :many
+SELECT * FROM chapter_evaluations
+WHERE chapter_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetChapterEvaluation :one
+SELECT * FROM chapter_evaluations
+WHERE id = $1 AND chapter_id = $2 LIMIT 1;
+
+-- name: CreateUploadSession :one
+INSERT INTO upload_sessions (project_id, user_id, file_name, total_size, sha256, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetUploadSession :one
+SELECT * FROM upload_sessions
+WHERE id = $1 AND project_id = $2 LIMIT 1;
+
+-- name: GetUploadSessionByID :one
+SELECT * FROM upload_sessions
+WHERE id = $1 LIMIT 1;
+
+-- name: AdvanceUploadSession :one
+-- Records a chunk received at received_size, only if no other chunk got there first.
+-- The session moves on to assembling with the last byte.
+UPDATE upload_sessions
+SET received_size = received_size + @chunk_size,
+    status = CASE WHEN received_size + @chunk_size = total_size THEN 'assembling' ELSE status END
+WHERE id = @id AND received_size = @received_size AND status = 'receiving' AND expires_at > NOW()
+RETURNING *;
+
+-- name: FinishUploadSession :exec
+UPDATE upload_sessions
+SET status = $2, error = $3, upload_id = $4
+WHERE id = $1 AND status = 'assembling';
+
+-- name: DeleteUploadSession :one
+DELETE FROM upload_sessions
+WHERE id = $1 AND project_id = $2 AND status <> 'assembling'
+RETURNING *;
+
+-- name: DeleteExpiredUploadSessions :exec
+DELETE FROM upload_sessions
+WHERE expires_at < $1 AND status <> 'assembling';
+
+-- name: CreateUploadSessionChunk :exec
+INSERT INTO upload_session_chunks (session_id, byte_offset, size, storage_key)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListUploadSessionChunks :many
+SELECT * FROM upload_session_chunks
+WHERE session_id = $1
+ORDER BY byte_offset;
+
+-- name: DeleteUploadSessionChunks :many
+DELETE FROM upload_session_chunks
+WHERE session_id = $1
+RETURNING storage_key;
+
+-- name: ListExpiredUploadSessionChunks :many
+-- Chunk objects of the sessions DeleteExpiredUploadSessions removes
+SELECT c.storage_key FROM upload_session_chunks c
+JOIN upload_sessions s ON s.id = c.session_id
+WHERE s.expires_at < $1 AND s.status <> 'assembling';
+
+-- name: CreateGuestUser :one
+INSERT INTO users (email, password_hash, first_name, last_name, guest_expires_at)
+VALUES ($1, $2, $3, '', $4)
+RETURNING *;
+
+-- name: ClaimGuestUser :one
+-- Turns a guest into a registered user, keeping its projects
+UPDATE users
+SET email = $2, password_hash = $3, first_name = $4, last_name = $5, guest_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND guest_expires_at IS NOT NULL
+RETURNING *;
+
+-- name: ListExpiredGuestFiles :many
+-- Stored files of the projects of expired guests, which DeleteExpiredGuests
+-- removes along with the guests
+WITH guest_projects AS (
+    SELECT p.id FROM research_projects p
+    JOIN users u ON u.id = p.user_id
+    WHERE u.guest_expires_at < $1
+)
+SELECT file_path AS file_ref FROM project_uploads WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT file_path FROM project_guidelines WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT file_path FROM chapter_figures WHERE project_id IN (SELECT id FROM guest_projects) AND file_path <> ''
+UNION ALL
+SELECT file_path FROM project_appendices WHERE project_id IN (SELECT id FROM guest_projects) AND file_path <> ''
+UNION ALL
+SELECT pdf_path FROM reference_papers WHERE project_id IN (SELECT id FROM guest_projects) AND pdf_path <> ''
+UNION ALL
+SELECT COALESCE(storage_key, file_path) FROM generated_documents WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT c.storage_key FROM upload_session_chunks c
+JOIN upload_sessions s ON s.id = c.session_id
+WHERE s.project_id IN (SELECT id FROM guest_projects);
+
+-- name: DeleteExpiredGuests :exec
+DELETE FROM users
+WHERE guest_expires_at < $1;
+
+-- name: ListChapterTypes :many
+-- The built-in chapter types, then the organization's and the project's own; NULL
+-- leaves either out
+SELECT * FROM chapter_types
+WHERE (organization_id IS NULL AND project_id IS NULL) OR organization_id = $1 OR project_id = $2
+ORDER BY position, organization_id IS NOT NULL, project_id IS NOT NULL, key;
+
+-- name: GetChapterType :one
+-- The built-in, organization or project chapter type with the key, in that order
+SELECT * FROM chapter_types
+WHERE key = $1 AND ((organization_id IS NULL AND project_id IS NULL) OR organization_id = $2 OR project_id = $3)
+ORDER BY project_id NULLS FIRST, organization_id NULLS FIRST
+LIMIT 1;
+
+-- name: CreateChapterType :one
+INSERT INTO chapter_types (organization_id, project_id, key, label, description, position, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateChapterType :one
+UPDATE chapter_types
+SET label = $3, description = $4, position = $5, updated_at = NOW()
+WHERE organization_id = $1 AND key = $2
+RETURNING *;
+
+-- name: DeleteChapterType :exec
+DELETE FROM chapter_types
+WHERE organization_id = $1 AND key = $2;
+
+-- name: ChapterTypeInUse :one
+-- Whether chapters of the organization's projects, trashed ones included, have the type
+SELECT EXISTS (
+    SELECT 1 FROM chapters c
+    JOIN research_projects p ON p.id = c.project_id
+    WHERE p.organization_id = $1 AND c.type = $2
+) AS in_use;
+
+-- name: DeleteProjectChapterType :exec
+DELETE FROM chapter_types
+WHERE project_id = $1 AND key = $2;
+
+-- name: ProjectChapterTypeInUse :one
+-- Whether chapters of the project, trashed ones included, have the type
+SELECT EXISTS (
+    SELECT 1 FROM chapters
+    WHERE project_id = $1 AND type = $2
+) AS in_use;
+
+-- name: SetChapterPosition :exec
+-- Moves a chapter within the thesis; its content and version are untouched
+UPDATE chapters
+SET position = $3
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL;
+
+-- name: ListChapterSections :many
+SELECT * FROM chapter_sections
+WHERE chapter_id = $1
+ORDER BY position, created_at;
+
+-- name: ListProjectSections :many
+-- Every section of the project's chapters, in order within each chapter
+SELECT * FROM chapter_sections
+WHERE project_id = $1
+ORDER BY chapter_id, position, created_at;
+
+-- name: GetChapterSection :one
+SELECT * FROM chapter_sections
+WHERE id = $1 AND chapter_id = $2;
+
+-- name: CreateChapterSection :one
+INSERT INTO chapter_sections (chapter_id, project_id, title, level, content, word_count, position)
+VALUES (
+    $1, $2, $3, $4, $5, $6,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM chapter_sections WHERE chapter_id = $1) -- Last
+)
+RETURNING *;
+
+-- name: UpdateChapterSection :one
+UPDATE chapter_sections
+SET title = $3, level = $4, content = $5, word_count = $6, updated_at = NOW()
+WHERE id = $1 AND chapter_id = $2
+RETURNING *;
+
+-- name: SaveGeneratedChapterSection :one
+UPDATE chapter_sections
+SET content = $3, word_count = $4, generated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND chapter_id = $2
+RETURNING *;
+
+-- name: SetChapterSectionPosition :exec
+UPDATE chapter_sections
+SET position = $3
+WHERE id = $1 AND chapter_id = $2;
+
+-- name: DeleteChapterSection :exec
+DELETE FROM chapter_sections
+WHERE id = $1 AND chapter_id = $2;
+
+-- name: GetIntegrityAcknowledgment :one
+SELECT * FROM integrity_acknowledgments
+WHERE user_id = $1 AND policy_version = $2;
+
+-- name: AcknowledgeIntegrityPolicy :one
+INSERT INTO integrity_acknowledgments (user_id, policy_version)
+VALUES ($1, $2)
+ON CONFLICT (user_id, policy_version) DO UPDATE SET acknowledged_at = NOW()
+RETURNING *;
+
+-- name: GetUserGenerationPreferences :one
+SELECT preferences FROM user_generation_preferences
+WHERE user_id = $1;
+
+-- name: UpsertUserGenerationPreferences :exec
+INSERT INTO user_generation_preferences (user_id, preferences)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET preferences = EXCLUDED.preferences, updated_at = NOW();
+
+-- name: GetProjectGenerationPreferences :one
+SELECT preferences FROM project_generation_preferences
+WHERE project_id = $1;
+
+-- name: UpsertProjectGenerationPreferences :exec
+INSERT INTO project_generation_preferences (project_id, preferences)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET preferences = EXCLUDED.preferences, updated_at = NOW();
+
+-- name: CreateGeneration :exec
+INSERT INTO generations (
+    user_id, project_id, chapter_id, kind, model, temperature, max_tokens, prompt_hash,
+    prompt, response, prompt_tokens, completion_tokens, latency_ms, outcome, error
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
+
+-- name: ListChapterGenerations :many
+-- Newest first
+SELECT * FROM generations
+WHERE chapter_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: CreateGenerationBatch :one
+INSERT INTO generation_batches (project_id, user_id, chapters)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetGenerationBatch :one
+SELECT * FROM generation_batches
+WHERE id = $1 AND project_id = $2;
+
+-- name: UpdateGenerationBatch :one
+-- Completed or failed runs are finished
+UPDATE generation_batches
+SET status = $2, chapters = $3, error = $4, updated_at = NOW(),
+    completed_at = CASE WHEN $2 IN ('completed', 'failed') THEN NOW() END
+WHERE id = $1
+RETURNING *;
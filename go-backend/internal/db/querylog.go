@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+)
+
+// queryLogger logs each query with its duration at debug level. It checks the
+// logger's level when a query starts, so it costs next to nothing until debug
+// logging is turned on for the db component.
+type queryLogger struct {
+	logger *applogger.AppLogger
+}
+
+type queryLogKey struct{}
+
+type queryLogStart struct {
+	sql   string
+	start time.Time
+}
+
+func (t *queryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !t.logger.Enabled(ctx, slog.LevelDebug) {
+		return ctx
+	}
+	return context.WithValue(ctx, queryLogKey{}, queryLogStart{sql: data.SQL, start: time.Now()})
+}
+
+func (t *queryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	started, ok := ctx.Value(queryLogKey{}).(queryLogStart)
+	if !ok {
+		return
+	}
+	// Arguments are left out on purpose: they carry user content and credentials
+	args := []any{"sql", started.sql, "duration", time.Since(started.start), "rows", data.CommandTag.RowsAffected()}
+	if data.Err != nil {
+		t.logger.DebugContext(ctx, "Query failed", append(args, "error", data.Err)...)
+		return
+	}
+	t.logger.DebugContext(ctx, "Query", args...)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// routingDB implements sqlc.DBTX, sending read-only statements to the replica
+// pool and everything else (writes, UPDATE ... RETURNING, locking reads) to the primary.
+// Transactions never go through it; ExecTx always begins on the primary.
+type routingDB struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+}
+
+type primaryContextKey struct{}
+
+// WithPrimary forces reads made with the returned context onto the primary,
+// for read-after-write paths that cannot tolerate replication lag
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func (r *routingDB) useReplica(ctx context.Context, sql string) bool {
+	forcePrimary, _ := ctx.Value(primaryContextKey{}).(bool)
+	return !forcePrimary && isReadOnlyQuery(sql)
+}
+
+func (r *routingDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return r.primary.Exec(ctx, sql, args...)
+}
+
+func (r *routingDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if r.useReplica(ctx, sql) {
+		return r.replica.Query(ctx, sql, args...)
+	}
+	return r.primary.Query(ctx, sql, args...)
+}
+
+func (r *routingDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if r.useReplica(ctx, sql) {
+		return r.replica.QueryRow(ctx, sql, args...)
+	}
+	return r.primary.QueryRow(ctx, sql, args...)
+}
+
+// isReadOnlyQuery reports whether sql is a plain SELECT. sqlc prefixes every
+// statement with a "-- name:" comment, so leading comment lines are skipped.
+func isReadOnlyQuery(sql string) bool {
+	s := strings.TrimSpace(sql)
+	for strings.HasPrefix(s, "--") {
+		newline := strings.IndexByte(s, '\n')
+		if newline < 0 {
+			return false
+		}
+		s = strings.TrimSpace(s[newline+1:])
+	}
+	if len(s) < len("SELECT") || !strings.EqualFold(s[:len("SELECT")], "SELECT") {
+		return false
+	}
+	upper := strings.ToUpper(s)
+	// Locking reads must see and lock the primary's rows
+	return !strings.Contains(upper, "FOR UPDATE") && !strings.Contains(upper, "FOR SHARE")
+}
@@ -8,29 +8,435 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BillingUsage struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+	Resource    string      `db:"resource" json:"resource"`
+	Used        int32       `db:"used" json:"used"`
+}
+
 type Chapter struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	ProjectID       pgtype.UUID        `db:"project_id" json:"project_id"`
+	Type            string             `db:"type" json:"type"`
+	Title           string             `db:"title" json:"title"`
+	Content         pgtype.Text        `db:"content" json:"content"`
+	WordCount       pgtype.Int4        `db:"word_count" json:"word_count"`
+	Status          pgtype.Text        `db:"status" json:"status"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	SearchVector    pgtype.Text        `db:"search_vector" json:"-"`
+	Version         int32              `db:"version" json:"version"`
+	DeletedAt       pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	TargetWordCount pgtype.Int4        `db:"target_word_count" json:"target_word_count"`
+	CustomPrompt    string             `db:"custom_prompt" json:"custom_prompt"`
+	Position        int32              `db:"position" json:"position"`
+}
+
+type ChapterCitation struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	ChapterID      pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ProjectID      pgtype.UUID        `db:"project_id" json:"project_id"`
+	ReferenceID    pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	Marker         string             `db:"marker" json:"marker"`
+	Author         string             `db:"author" json:"author"`
+	Year           int32              `db:"year" json:"year"`
+	Position       int32              `db:"position" json:"position"`
+	ChapterVersion int32              `db:"chapter_version" json:"chapter_version"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ChapterDraft struct {
+	ChapterID   pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Title       pgtype.Text        `db:"title" json:"title"`
+	Content     pgtype.Text        `db:"content" json:"content"`
+	BaseVersion int32              `db:"base_version" json:"base_version"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ChapterEvaluation struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	ProjectID      pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID      pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ChapterVersion int32              `db:"chapter_version" json:"chapter_version"`
+	Rubric         []byte             `db:"rubric" json:"rubric"`
+	Criteria       []byte             `db:"criteria" json:"criteria"`
+	Summary        string             `db:"summary" json:"summary"`
+	EvaluatedBy    pgtype.UUID        `db:"evaluated_by" json:"evaluated_by"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ChapterFigure struct {
 	ID        pgtype.UUID        `db:"id" json:"id"`
 	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
-	Type      string             `db:"type" json:"type"`
+	ChapterID pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	Kind      string             `db:"kind" json:"kind"`
+	Caption   string             `db:"caption" json:"caption"`
+	Position  int32              `db:"position" json:"position"`
+	FilePath  string             `db:"file_path" json:"file_path"`
+	MimeType  string             `db:"mime_type" json:"mime_type"`
+	TableData []byte             `db:"table_data" json:"table_data"`
+	CreatedBy pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ChapterMetric struct {
+	ChapterID  pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ProjectID  pgtype.UUID        `db:"project_id" json:"project_id"`
+	Version    int32              `db:"version" json:"version"`
+	Metrics    []byte             `db:"metrics" json:"metrics"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+}
+
+type ChapterProvenance struct {
+	ChapterID pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Version   int32              `db:"version" json:"version"`
+	Spans     []byte             `db:"spans" json:"spans"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ChapterReview struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	ProjectID      pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID      pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ChapterVersion int32              `db:"chapter_version" json:"chapter_version"`
+	Status         string             `db:"status" json:"status"`
+	SubmittedBy    pgtype.UUID        `db:"submitted_by" json:"submitted_by"`
+	SubmissionNote string             `db:"submission_note" json:"submission_note"`
+	ReviewedBy     pgtype.UUID        `db:"reviewed_by" json:"reviewed_by"`
+	DecisionNote   string             `db:"decision_note" json:"decision_note"`
+	SubmittedAt    pgtype.Timestamptz `db:"submitted_at" json:"submitted_at"`
+	DecidedAt      pgtype.Timestamptz `db:"decided_at" json:"decided_at"`
+}
+
+type ChapterSection struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	ChapterID   pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Title       string             `db:"title" json:"title"`
+	Level       int32              `db:"level" json:"level"`
+	Position    int32              `db:"position" json:"position"`
+	Content     string             `db:"content" json:"content"`
+	WordCount   int32              `db:"word_count" json:"word_count"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	GeneratedAt pgtype.Timestamptz `db:"generated_at" json:"generated_at"`
+}
+
+type ChapterType struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	Key            string             `db:"key" json:"key"`
+	Label          string             `db:"label" json:"label"`
+	Description    string             `db:"description" json:"description"`
+	Position       int32              `db:"position" json:"position"`
+	CreatedBy      pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ProjectID      pgtype.UUID        `db:"project_id" json:"project_id"`
+}
+
+type ChapterVersion struct {
+	ChapterID pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Version   int32              `db:"version" json:"version"`
 	Title     string             `db:"title" json:"title"`
-	Content   pgtype.Text        `db:"content" json:"content"`
-	WordCount pgtype.Int4        `db:"word_count" json:"word_count"`
-	Status    pgtype.Text        `db:"status" json:"status"`
+	Content   string             `db:"content" json:"content"`
+	WordCount int32              `db:"word_count" json:"word_count"`
+	Status    string             `db:"status" json:"status"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Comment struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	ThreadID  pgtype.UUID        `db:"thread_id" json:"thread_id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Body      string             `db:"body" json:"body"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type CommentThread struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	ProjectID     pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID     pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	AnchorStart   pgtype.Int4        `db:"anchor_start" json:"anchor_start"`
+	AnchorEnd     pgtype.Int4        `db:"anchor_end" json:"anchor_end"`
+	AnchorHeading pgtype.Text        `db:"anchor_heading" json:"anchor_heading"`
+	QuotedText    string             `db:"quoted_text" json:"quoted_text"`
+	CreatedBy     pgtype.UUID        `db:"created_by" json:"created_by"`
+	ResolvedAt    pgtype.Timestamptz `db:"resolved_at" json:"resolved_at"`
+	ResolvedBy    pgtype.UUID        `db:"resolved_by" json:"resolved_by"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type FeatureFlag struct {
+	Name      string             `db:"name" json:"name"`
+	Enabled   bool               `db:"enabled" json:"enabled"`
 	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 type GeneratedDocument struct {
+	ID         pgtype.UUID        `db:"id" json:"id"`
+	ProjectID  pgtype.UUID        `db:"project_id" json:"project_id"`
+	FileName   string             `db:"file_name" json:"file_name"`
+	FilePath   string             `db:"file_path" json:"file_path"`
+	FileSize   pgtype.Int8        `db:"file_size" json:"file_size"`
+	MimeType   pgtype.Text        `db:"mime_type" json:"mime_type"`
+	Status     pgtype.Text        `db:"status" json:"status"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	StorageKey pgtype.Text        `db:"storage_key" json:"storage_key"`
+}
+
+type GenerationBatch struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	Status      string             `db:"status" json:"status"`
+	Chapters    []byte             `db:"chapters" json:"chapters"`
+	Error       pgtype.Text        `db:"error" json:"error"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	CompletedAt pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+}
+
+type Generation struct {
+	ID               pgtype.UUID        `db:"id" json:"id"`
+	UserID           pgtype.UUID        `db:"user_id" json:"user_id"`
+	ProjectID        pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID        pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	Kind             string             `db:"kind" json:"kind"`
+	Model            string             `db:"model" json:"model"`
+	Temperature      float64            `db:"temperature" json:"temperature"`
+	MaxTokens        int32              `db:"max_tokens" json:"max_tokens"`
+	PromptHash       string             `db:"prompt_hash" json:"prompt_hash"`
+	Prompt           []byte             `db:"prompt" json:"prompt"`
+	Response         string             `db:"response" json:"response"`
+	PromptTokens     int32              `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int32              `db:"completion_tokens" json:"completion_tokens"`
+	LatencyMs        int32              `db:"latency_ms" json:"latency_ms"`
+	Outcome          string             `db:"outcome" json:"outcome"`
+	Error            pgtype.Text        `db:"error" json:"error"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	IdempotencyKey string             `db:"idempotency_key" json:"idempotency_key"`
+	RequestMethod  string             `db:"request_method" json:"request_method"`
+	RequestPath    string             `db:"request_path" json:"request_path"`
+	RequestHash    string             `db:"request_hash" json:"request_hash"`
+	ResponseStatus pgtype.Int4        `db:"response_status" json:"response_status"`
+	ResponseBody   []byte             `db:"response_body" json:"response_body"`
+	ResponseHash   pgtype.Text        `db:"response_hash" json:"response_hash"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type IntegrityAcknowledgment struct {
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	PolicyVersion  string             `db:"policy_version" json:"policy_version"`
+	AcknowledgedAt pgtype.Timestamptz `db:"acknowledged_at" json:"acknowledged_at"`
+}
+
+type Job struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	Kind        string             `db:"kind" json:"kind"`
+	Payload     []byte             `db:"payload" json:"payload"`
+	Status      string             `db:"status" json:"status"`
+	Attempts    int32              `db:"attempts" json:"attempts"`
+	MaxAttempts int32              `db:"max_attempts" json:"max_attempts"`
+	RunAt       pgtype.Timestamptz `db:"run_at" json:"run_at"`
+	UniqueKey   pgtype.Text        `db:"unique_key" json:"unique_key"`
+	LastError   string             `db:"last_error" json:"last_error"`
+	CompletedAt pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type Notification struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	UserID        pgtype.UUID        `db:"user_id" json:"user_id"`
+	Type          string             `db:"type" json:"type"`
+	ProjectID     pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID     pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	DocumentID    pgtype.UUID        `db:"document_id" json:"document_id"`
+	Title         string             `db:"title" json:"title"`
+	Body          string             `db:"body" json:"body"`
+	InApp         bool               `db:"in_app" json:"in_app"`
+	ReadAt        pgtype.Timestamptz `db:"read_at" json:"read_at"`
+	EmailStatus   string             `db:"email_status" json:"email_status"`
+	EmailAttempts int32              `db:"email_attempts" json:"email_attempts"`
+	EmailSentAt   pgtype.Timestamptz `db:"email_sent_at" json:"email_sent_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Type      string             `db:"type" json:"type"`
+	InApp     bool               `db:"in_app" json:"in_app"`
+	Email     bool               `db:"email" json:"email"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type Organization struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	Name      string             `db:"name" json:"name"`
+	CreatedBy pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationInvitation struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	Email          string             `db:"email" json:"email"`
+	Role           string             `db:"role" json:"role"`
+	InvitedBy      pgtype.UUID        `db:"invited_by" json:"invited_by"`
+	ExpiresAt      pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	TokenHash      pgtype.Text        `db:"token_hash" json:"token_hash"`
+}
+
+type OrganizationMember struct {
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	Role           string             `db:"role" json:"role"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ProjectAppendix struct {
 	ID        pgtype.UUID        `db:"id" json:"id"`
 	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
-	FileName  string             `db:"file_name" json:"file_name"`
+	Title     string             `db:"title" json:"title"`
+	Content   string             `db:"content" json:"content"`
+	Position  int32              `db:"position" json:"position"`
 	FilePath  string             `db:"file_path" json:"file_path"`
-	FileSize  pgtype.Int8        `db:"file_size" json:"file_size"`
-	MimeType  pgtype.Text        `db:"mime_type" json:"mime_type"`
-	Status    pgtype.Text        `db:"status" json:"status"`
+	MimeType  string             `db:"mime_type" json:"mime_type"`
+	CreatedBy pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectCollaborator struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Role      string             `db:"role" json:"role"`
+	AddedBy   pgtype.UUID        `db:"added_by" json:"added_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectDataset struct {
+	ID         pgtype.UUID        `db:"id" json:"id"`
+	ProjectID  pgtype.UUID        `db:"project_id" json:"project_id"`
+	UploadedBy pgtype.UUID        `db:"uploaded_by" json:"uploaded_by"`
+	FileName   string             `db:"file_name" json:"file_name"`
+	RowCount   int32              `db:"row_count" json:"row_count"`
+	Columns    []byte             `db:"columns" json:"columns"`
+	TableIds   []pgtype.UUID      `db:"table_ids" json:"table_ids"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectFormatting struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Options   []byte             `db:"options" json:"options"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectFrontMatter struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Content   []byte             `db:"content" json:"content"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectGenerationPreference struct {
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Preferences []byte             `db:"preferences" json:"preferences"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectGuideline struct {
+	ID         pgtype.UUID        `db:"id" json:"id"`
+	ProjectID  pgtype.UUID        `db:"project_id" json:"project_id"`
+	UploadedBy pgtype.UUID        `db:"uploaded_by" json:"uploaded_by"`
+	FileName   string             `db:"file_name" json:"file_name"`
+	FilePath   string             `db:"file_path" json:"file_path"`
+	MimeType   string             `db:"mime_type" json:"mime_type"`
+	FileSize   int64              `db:"file_size" json:"file_size"`
+	Status     string             `db:"status" json:"status"`
+	Error      string             `db:"error" json:"error"`
+	Rules      []byte             `db:"rules" json:"rules"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectMethodology struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Answers   []byte             `db:"answers" json:"answers"`
+	UpdatedBy pgtype.UUID        `db:"updated_by" json:"updated_by"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectTimeline struct {
+	ID                pgtype.UUID        `db:"id" json:"id"`
+	ProjectID         pgtype.UUID        `db:"project_id" json:"project_id"`
+	StartDate         pgtype.Date        `db:"start_date" json:"start_date"`
+	EndDate           pgtype.Date        `db:"end_date" json:"end_date"`
+	Milestones        []byte             `db:"milestones" json:"milestones"`
+	Tasks             []byte             `db:"tasks" json:"tasks"`
+	IncludeInDocument bool               `db:"include_in_document" json:"include_in_document"`
+	GeneratedBy       pgtype.UUID        `db:"generated_by" json:"generated_by"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectUpload struct {
+	ID               pgtype.UUID        `db:"id" json:"id"`
+	ProjectID        pgtype.UUID        `db:"project_id" json:"project_id"`
+	UploadedBy       pgtype.UUID        `db:"uploaded_by" json:"uploaded_by"`
+	FileName         string             `db:"file_name" json:"file_name"`
+	FilePath         string             `db:"file_path" json:"file_path"`
+	FileSize         int64              `db:"file_size" json:"file_size"`
+	Sha256           string             `db:"sha256" json:"sha256"`
+	ExtractionStatus string             `db:"extraction_status" json:"extraction_status"`
+	ExtractionError  string             `db:"extraction_error" json:"extraction_error"`
+	PageCount        pgtype.Int4        `db:"page_count" json:"page_count"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ProjectUploadText struct {
+	UploadID  pgtype.UUID        `db:"upload_id" json:"upload_id"`
+	Content   string             `db:"content" json:"content"`
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type Questionnaire struct {
+	ID                pgtype.UUID        `db:"id" json:"id"`
+	ProjectID         pgtype.UUID        `db:"project_id" json:"project_id"`
+	Title             string             `db:"title" json:"title"`
+	Instructions      string             `db:"instructions" json:"instructions"`
+	Sections          []byte             `db:"sections" json:"sections"`
+	IncludeInDocument bool               `db:"include_in_document" json:"include_in_document"`
+	CreatedBy         pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type QuotaUsage struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	Resource    string             `db:"resource" json:"resource"`
+	Used        int64              `db:"used" json:"used"`
+}
+
 type Reference struct {
 	ID              pgtype.UUID        `db:"id" json:"id"`
 	ProjectID       pgtype.UUID        `db:"project_id" json:"project_id"`
@@ -43,6 +449,63 @@ type Reference struct {
 	CitationApa     pgtype.Text        `db:"citation_apa" json:"citation_apa"`
 	CitationMla     pgtype.Text        `db:"citation_mla" json:"citation_mla"`
 	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Abstract        pgtype.Text        `db:"abstract" json:"abstract"`
+	SearchVector    pgtype.Text        `db:"search_vector" json:"-"`
+	DeletedAt       pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+}
+
+type ReferenceEmbedding struct {
+	ReferenceID pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	Model       string             `db:"model" json:"model"`
+	Embedding   string             `db:"embedding" json:"embedding"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ReferenceHighlight struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	ReferenceID pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Text        string             `db:"text" json:"text"`
+	Comment     string             `db:"comment" json:"comment"`
+	Page        pgtype.Int4        `db:"page" json:"page"`
+	CreatedBy   pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type ReferenceNote struct {
+	ReferenceID pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Notes       string             `db:"notes" json:"notes"`
+	UpdatedBy   pgtype.UUID        `db:"updated_by" json:"updated_by"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ReferencePaper struct {
+	ReferenceID      pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	ProjectID        pgtype.UUID        `db:"project_id" json:"project_id"`
+	PaperID          string             `db:"paper_id" json:"paper_id"`
+	OpenAccessPdfUrl string             `db:"open_access_pdf_url" json:"open_access_pdf_url"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	PdfStatus        string             `db:"pdf_status" json:"pdf_status"`
+	PdfError         string             `db:"pdf_error" json:"pdf_error"`
+	PdfPath          string             `db:"pdf_path" json:"pdf_path"`
+	PdfSize          pgtype.Int8        `db:"pdf_size" json:"pdf_size"`
+}
+
+type ReferencePaperChunk struct {
+	ReferenceID  pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID    pgtype.UUID `db:"project_id" json:"project_id"`
+	Position     int32       `db:"position" json:"position"`
+	Content      string      `db:"content" json:"content"`
+	SearchVector pgtype.Text `db:"search_vector" json:"-"`
+}
+
+type ReferenceTag struct {
+	ReferenceID pgtype.UUID        `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID        `db:"project_id" json:"project_id"`
+	Tag         string             `db:"tag" json:"tag"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
 type ResearchProject struct {
@@ -55,6 +518,9 @@ type ResearchProject struct {
 	Status         pgtype.Text        `db:"status" json:"status"`
 	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	AdvisorID      pgtype.UUID        `db:"advisor_id" json:"advisor_id"`
+	Language       string             `db:"language" json:"language"`
 }
 
 type Session struct {
@@ -68,13 +534,88 @@ type Session struct {
 	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
-type User struct {
+type StripeEvent struct {
+	ID         string             `db:"id" json:"id"`
+	Type       string             `db:"type" json:"type"`
+	ReceivedAt pgtype.Timestamptz `db:"received_at" json:"received_at"`
+}
+
+type Subscription struct {
+	UserID               pgtype.UUID        `db:"user_id" json:"user_id"`
+	StripeCustomerID     string             `db:"stripe_customer_id" json:"stripe_customer_id"`
+	StripeSubscriptionID pgtype.Text        `db:"stripe_subscription_id" json:"stripe_subscription_id"`
+	Plan                 string             `db:"plan" json:"plan"`
+	Status               string             `db:"status" json:"status"`
+	CurrentPeriodEnd     pgtype.Timestamptz `db:"current_period_end" json:"current_period_end"`
+	CancelAtPeriodEnd    bool               `db:"cancel_at_period_end" json:"cancel_at_period_end"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type UploadSession struct {
 	ID           pgtype.UUID        `db:"id" json:"id"`
-	Email        string             `db:"email" json:"email"`
-	PasswordHash string             `db:"password_hash" json:"password_hash"`
-	FirstName    string             `db:"first_name" json:"first_name"`
-	LastName     string             `db:"last_name" json:"last_name"`
-	IsVerified   pgtype.Bool        `db:"is_verified" json:"is_verified"`
+	ProjectID    pgtype.UUID        `db:"project_id" json:"project_id"`
+	UserID       pgtype.UUID        `db:"user_id" json:"user_id"`
+	FileName     string             `db:"file_name" json:"file_name"`
+	TotalSize    int64              `db:"total_size" json:"total_size"`
+	ReceivedSize int64              `db:"received_size" json:"received_size"`
+	Sha256       string             `db:"sha256" json:"sha256"`
+	Status       string             `db:"status" json:"status"`
+	Error        string             `db:"error" json:"error"`
+	UploadID     pgtype.UUID        `db:"upload_id" json:"upload_id"`
+	ExpiresAt    pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
 	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
+
+type UploadSessionChunk struct {
+	SessionID  pgtype.UUID `db:"session_id" json:"session_id"`
+	ByteOffset int64       `db:"byte_offset" json:"byte_offset"`
+	Size       int64       `db:"size" json:"size"`
+	StorageKey string      `db:"storage_key" json:"storage_key"`
+}
+
+type User struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Email          string             `db:"email" json:"email"`
+	PasswordHash   string             `db:"password_hash" json:"password_hash"`
+	FirstName      string             `db:"first_name" json:"first_name"`
+	LastName       string             `db:"last_name" json:"last_name"`
+	IsVerified     pgtype.Bool        `db:"is_verified" json:"is_verified"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	GuestExpiresAt pgtype.Timestamptz `db:"guest_expires_at" json:"guest_expires_at"`
+}
+
+type UserGenerationPreference struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	Preferences []byte             `db:"preferences" json:"preferences"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type Webhook struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	Url       string             `db:"url" json:"url"`
+	Secret    string             `db:"secret" json:"secret"`
+	Events    []string           `db:"events" json:"events"`
+	Active    bool               `db:"active" json:"active"`
+	CreatedBy pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	WebhookID      pgtype.UUID        `db:"webhook_id" json:"webhook_id"`
+	Event          string             `db:"event" json:"event"`
+	Payload        []byte             `db:"payload" json:"payload"`
+	Status         string             `db:"status" json:"status"`
+	Attempts       int32              `db:"attempts" json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseStatus pgtype.Int4        `db:"response_status" json:"response_status"`
+	LastError      string             `db:"last_error" json:"last_error"`
+	DurationMs     pgtype.Int4        `db:"duration_ms" json:"duration_ms"`
+	DeliveredAt    pgtype.Timestamptz `db:"delivered_at" json:"delivered_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
@@ -11,36 +11,316 @@ import (
 )
 
 type Querier interface {
+	AcknowledgeIntegrityPolicy(ctx context.Context, arg AcknowledgeIntegrityPolicyParams) (IntegrityAcknowledgment, error)
+	// An existing member keeps their current role
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	// Records usage known only after the fact, e.g. words generated, even past the limit
+	AddQuotaUsage(ctx context.Context, arg AddQuotaUsageParams) error
+	AddReferenceTag(ctx context.Context, arg AddReferenceTagParams) error
+	// Records a chunk received at received_size, only if no other chunk got there first.
+	// The session moves on to assembling with the last byte.
+	AdvanceUploadSession(ctx context.Context, arg AdvanceUploadSessionParams) (UploadSession, error)
 	BlockSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	// Whether chapters of the organization's projects, trashed ones included, have the type
+	ChapterTypeInUse(ctx context.Context, arg ChapterTypeInUseParams) (bool, error)
+	// Leases a batch by marking it running until leased_until. A running job whose
+	// lease ran out, because its worker died, is claimed again.
+	ClaimDueJobs(ctx context.Context, arg ClaimDueJobsParams) ([]Job, error)
+	// Leases a batch by moving next_attempt_at to leased_until, so other workers skip it
+	// without a transaction being held open while the HTTP requests run
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	// Turns a guest into a registered user, keeping its projects
+	ClaimGuestUser(ctx context.Context, arg ClaimGuestUserParams) (User, error)
+	// Locks a batch for the calling transaction; other workers skip it
+	ClaimPendingNotificationEmails(ctx context.Context, limit int32) ([]ClaimPendingNotificationEmailsRow, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) (IdempotencyKey, error)
+	// Matching attempts stops a worker whose lease ran out from overwriting a newer run
+	CompleteJob(ctx context.Context, arg CompleteJobParams) error
+	CountOpenCommentThreads(ctx context.Context, projectID pgtype.UUID) ([]CountOpenCommentThreadsRow, error)
+	CountOrganizationOwners(ctx context.Context, organizationID pgtype.UUID) (int64, error)
+	CountOwnedProjects(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountUnreadNotifications(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CreateAppendix(ctx context.Context, arg CreateAppendixParams) (ProjectAppendix, error)
+	// Keeps the existing customer when two checkouts race
+	CreateBillingCustomer(ctx context.Context, arg CreateBillingCustomerParams) (Subscription, error)
 	CreateChapter(ctx context.Context, arg CreateChapterParams) (Chapter, error)
+	// A concurrent rebuild may have written the same marker already
+	CreateChapterCitation(ctx context.Context, arg CreateChapterCitationParams) error
+	CreateChapterEvaluation(ctx context.Context, arg CreateChapterEvaluationParams) (ChapterEvaluation, error)
+	CreateChapterFigure(ctx context.Context, arg CreateChapterFigureParams) (ChapterFigure, error)
+	CreateChapterReview(ctx context.Context, arg CreateChapterReviewParams) (ChapterReview, error)
+	CreateChapterSection(ctx context.Context, arg CreateChapterSectionParams) (ChapterSection, error)
+	CreateChapterType(ctx context.Context, arg CreateChapterTypeParams) (ChapterType, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateCommentThread(ctx context.Context, arg CreateCommentThreadParams) (CommentThread, error)
 	CreateGeneratedDocument(ctx context.Context, arg CreateGeneratedDocumentParams) (GeneratedDocument, error)
+	CreateGeneration(ctx context.Context, arg CreateGenerationParams) error
+	CreateGenerationBatch(ctx context.Context, arg CreateGenerationBatchParams) (GenerationBatch, error)
+	CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (User, error)
+	// Returns no rows when the key already exists for the user
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateProjectDataset(ctx context.Context, arg CreateProjectDatasetParams) (ProjectDataset, error)
+	CreateProjectUpload(ctx context.Context, arg CreateProjectUploadParams) (ProjectUpload, error)
+	CreateQuestionnaire(ctx context.Context, arg CreateQuestionnaireParams) (Questionnaire, error)
 	CreateReference(ctx context.Context, arg CreateReferenceParams) (Reference, error)
+	CreateReferenceHighlight(ctx context.Context, arg CreateReferenceHighlightParams) (ReferenceHighlight, error)
+	CreateReferencePaper(ctx context.Context, arg CreateReferencePaperParams) error
+	CreateReferencePaperChunks(ctx context.Context, arg CreateReferencePaperChunksParams) error
 	CreateResearchProject(ctx context.Context, arg CreateResearchProjectParams) (ResearchProject, error)
-	// Ensure user owns project for delete if needed, or handled at service layer
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateUploadSession(ctx context.Context, arg CreateUploadSessionParams) (UploadSession, error)
+	CreateUploadSessionChunk(ctx context.Context, arg CreateUploadSessionChunkParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteChapter(ctx context.Context, arg DeleteChapterParams) error
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	DecideChapterReview(ctx context.Context, arg DecideChapterReviewParams) (ChapterReview, error)
+	DeleteAppendix(ctx context.Context, arg DeleteAppendixParams) (ProjectAppendix, error)
+	// Soft delete: the chapter moves to the project's trash
+	DeleteChapter(ctx context.Context, arg DeleteChapterParams) (int64, error)
+	DeleteChapterCitations(ctx context.Context, chapterID pgtype.UUID) error
+	DeleteChapterDraft(ctx context.Context, arg DeleteChapterDraftParams) error
+	DeleteChapterFigure(ctx context.Context, arg DeleteChapterFigureParams) (ChapterFigure, error)
+	DeleteChapterSection(ctx context.Context, arg DeleteChapterSectionParams) error
+	DeleteChapterType(ctx context.Context, arg DeleteChapterTypeParams) error
+	DeleteCompletedJobs(ctx context.Context, completedAt pgtype.Timestamptz) (int64, error)
+	DeleteExpiredGuests(ctx context.Context, guestExpiresAt pgtype.Timestamptz) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, createdAt pgtype.Timestamptz) error
+	DeleteExpiredUploadSessions(ctx context.Context, expiresAt pgtype.Timestamptz) error
+	DeleteFeatureFlag(ctx context.Context, name string) error
+	DeleteFiguresByID(ctx context.Context, arg DeleteFiguresByIDParams) error
 	DeleteGeneratedDocument(ctx context.Context, id pgtype.UUID) error
-	DeleteReference(ctx context.Context, arg DeleteReferenceParams) error
-	DeleteResearchProject(ctx context.Context, arg DeleteResearchProjectParams) error
+	DeleteIdempotencyKey(ctx context.Context, id pgtype.UUID) error
+	DeleteOrganizationInvitation(ctx context.Context, id pgtype.UUID) error
+	DeleteProjectChapterType(ctx context.Context, arg DeleteProjectChapterTypeParams) error
+	DeleteProjectCollaborator(ctx context.Context, arg DeleteProjectCollaboratorParams) (int64, error)
+	DeleteProjectDataset(ctx context.Context, arg DeleteProjectDatasetParams) (ProjectDataset, error)
+	DeleteProjectGuideline(ctx context.Context, projectID pgtype.UUID) (ProjectGuideline, error)
+	DeleteProjectTimeline(ctx context.Context, projectID pgtype.UUID) (ProjectTimeline, error)
+	DeleteProjectUpload(ctx context.Context, arg DeleteProjectUploadParams) (ProjectUpload, error)
+	DeleteQuestionnaire(ctx context.Context, arg DeleteQuestionnaireParams) (Questionnaire, error)
+	// Soft delete: the reference moves to the project's trash
+	DeleteReference(ctx context.Context, arg DeleteReferenceParams) (int64, error)
+	DeleteReferenceHighlight(ctx context.Context, arg DeleteReferenceHighlightParams) (ReferenceHighlight, error)
+	DeleteReferencePaperChunks(ctx context.Context, referenceID pgtype.UUID) error
+	DeleteReferenceTags(ctx context.Context, referenceID pgtype.UUID) error
+	// Only the owner, or an owner/admin of the project's organization, may delete it
+	DeleteResearchProject(ctx context.Context, arg DeleteResearchProjectParams) (int64, error)
 	DeleteSessionByRefreshToken(ctx context.Context, refreshToken string) error
+	DeleteUploadSession(ctx context.Context, arg DeleteUploadSessionParams) (UploadSession, error)
+	DeleteUploadSessionChunks(ctx context.Context, sessionID pgtype.UUID) ([]string, error)
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	// Returns no row when unique_key is already taken
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
+	FailJob(ctx context.Context, arg FailJobParams) error
+	FinishUploadSession(ctx context.Context, arg FinishUploadSessionParams) error
+	GetAppendix(ctx context.Context, arg GetAppendixParams) (ProjectAppendix, error)
 	GetChapterByID(ctx context.Context, id pgtype.UUID) (Chapter, error)
 	GetChapterByProjectIDAndType(ctx context.Context, arg GetChapterByProjectIDAndTypeParams) (Chapter, error)
+	GetChapterDraft(ctx context.Context, arg GetChapterDraftParams) (ChapterDraft, error)
+	GetChapterEvaluation(ctx context.Context, arg GetChapterEvaluationParams) (ChapterEvaluation, error)
+	GetChapterFigure(ctx context.Context, arg GetChapterFigureParams) (ChapterFigure, error)
+	GetChapterMetrics(ctx context.Context, chapterID pgtype.UUID) (ChapterMetric, error)
+	GetChapterProvenance(ctx context.Context, chapterID pgtype.UUID) (ChapterProvenance, error)
+	GetChapterSection(ctx context.Context, arg GetChapterSectionParams) (ChapterSection, error)
+	// The built-in, organization or project chapter type with the key, in that order
+	GetChapterType(ctx context.Context, arg GetChapterTypeParams) (ChapterType, error)
+	GetChapterVersion(ctx context.Context, arg GetChapterVersionParams) (ChapterVersion, error)
 	GetChaptersByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Chapter, error)
+	GetCommentThread(ctx context.Context, arg GetCommentThreadParams) (CommentThread, error)
 	GetGeneratedDocumentByID(ctx context.Context, id pgtype.UUID) (GeneratedDocument, error)
 	GetGeneratedDocumentsByProjectID(ctx context.Context, projectID pgtype.UUID) ([]GeneratedDocument, error)
+	GetGenerationBatch(ctx context.Context, arg GetGenerationBatchParams) (GenerationBatch, error)
+	GetGuideline(ctx context.Context, id pgtype.UUID) (ProjectGuideline, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetIntegrityAcknowledgment(ctx context.Context, arg GetIntegrityAcknowledgmentParams) (IntegrityAcknowledgment, error)
+	GetJob(ctx context.Context, id pgtype.UUID) (Job, error)
+	GetOrganizationByID(ctx context.Context, id pgtype.UUID) (Organization, error)
+	GetOrganizationInvitation(ctx context.Context, id pgtype.UUID) (OrganizationInvitation, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetPendingChapterReview(ctx context.Context, chapterID pgtype.UUID) (ChapterReview, error)
+	GetProjectChapterByID(ctx context.Context, arg GetProjectChapterByIDParams) (Chapter, error)
+	GetProjectDataset(ctx context.Context, arg GetProjectDatasetParams) (ProjectDataset, error)
+	GetProjectFormatting(ctx context.Context, projectID pgtype.UUID) ([]byte, error)
+	GetProjectFrontMatter(ctx context.Context, projectID pgtype.UUID) ([]byte, error)
+	GetProjectGenerationPreferences(ctx context.Context, projectID pgtype.UUID) ([]byte, error)
+	GetProjectGuideline(ctx context.Context, projectID pgtype.UUID) (ProjectGuideline, error)
+	GetProjectMethodology(ctx context.Context, projectID pgtype.UUID) (ProjectMethodology, error)
+	GetProjectReference(ctx context.Context, arg GetProjectReferenceParams) (Reference, error)
+	// Including references in the trash, which chapters may still cite
+	GetProjectReferencesByIDs(ctx context.Context, arg GetProjectReferencesByIDsParams) ([]Reference, error)
+	GetProjectTimeline(ctx context.Context, projectID pgtype.UUID) (ProjectTimeline, error)
+	GetProjectUpload(ctx context.Context, arg GetProjectUploadParams) (ProjectUpload, error)
+	GetProjectWithAccess(ctx context.Context, arg GetProjectWithAccessParams) (GetProjectWithAccessRow, error)
+	GetQuestionnaire(ctx context.Context, arg GetQuestionnaireParams) (Questionnaire, error)
+	GetReferenceNotes(ctx context.Context, referenceID pgtype.UUID) (ReferenceNote, error)
+	GetReferencePaper(ctx context.Context, referenceID pgtype.UUID) (ReferencePaper, error)
 	GetReferencesByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Reference, error)
-	GetResearchProjectByID(ctx context.Context, arg GetResearchProjectByIDParams) (ResearchProject, error)
+	// References in use carrying any of the tags
+	GetReferencesByTags(ctx context.Context, arg GetReferencesByTagsParams) ([]Reference, error)
+	GetReferencesForEmbedding(ctx context.Context, ids []pgtype.UUID) ([]GetReferencesForEmbeddingRow, error)
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (Session, error)
+	GetSubscription(ctx context.Context, userID pgtype.UUID) (Subscription, error)
+	GetUpload(ctx context.Context, id pgtype.UUID) (ProjectUpload, error)
+	GetUploadSession(ctx context.Context, arg GetUploadSessionParams) (UploadSession, error)
+	GetUploadSessionByID(ctx context.Context, id pgtype.UUID) (UploadSession, error)
+	GetUploadText(ctx context.Context, uploadID pgtype.UUID) (string, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
+	GetUserGenerationPreferences(ctx context.Context, userID pgtype.UUID) ([]byte, error)
+	// Projects are accessible to their owner, to members of the organization they
+	// belong to and to collaborators; project_access_role (migration 000010) decides the role
 	GetUserResearchProjects(ctx context.Context, userID pgtype.UUID) ([]ResearchProject, error)
+	GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error)
+	GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error)
+	ListActiveWebhooksForEvent(ctx context.Context, arg ListActiveWebhooksForEventParams) ([]Webhook, error)
+	ListChapterCitations(ctx context.Context, chapterID pgtype.UUID) ([]ChapterCitation, error)
+	ListChapterCommentThreads(ctx context.Context, arg ListChapterCommentThreadsParams) ([]CommentThread, error)
+	ListChapterComments(ctx context.Context, chapterID pgtype.UUID) ([]ListChapterCommentsRow, error)
+	ListChapterEvaluations(ctx context.Context, chapterID pgtype.UUID) ([]ChapterEvaluation, error)
+	ListChapterFigures(ctx context.Context, chapterID pgtype.UUID) ([]ChapterFigure, error)
+	// Newest first
+	ListChapterGenerations(ctx context.Context, arg ListChapterGenerationsParams) ([]Generation, error)
+	ListChapterReviews(ctx context.Context, chapterID pgtype.UUID) ([]ChapterReview, error)
+	ListChapterSections(ctx context.Context, chapterID pgtype.UUID) ([]ChapterSection, error)
+	// The built-in chapter types, then the organization's and the project's own; NULL
+	// leaves either out
+	ListChapterTypes(ctx context.Context, arg ListChapterTypesParams) ([]ChapterType, error)
+	// Newest first, without content
+	ListChapterVersions(ctx context.Context, chapterID pgtype.UUID) ([]ListChapterVersionsRow, error)
+	ListCommentThreadParticipants(ctx context.Context, threadID pgtype.UUID) ([]pgtype.UUID, error)
+	// Stored files of the projects of expired guests, which DeleteExpiredGuests
+	// removes along with the guests
+	ListExpiredGuestFiles(ctx context.Context, guestExpiresAt pgtype.Timestamptz) ([]string, error)
+	// Chunk objects of the sessions DeleteExpiredUploadSessions removes
+	ListExpiredUploadSessionChunks(ctx context.Context, expiresAt pgtype.Timestamptz) ([]string, error)
+	ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error)
+	ListInvitationsForEmail(ctx context.Context, email string) ([]ListInvitationsForEmailRow, error)
+	ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error)
+	ListNotificationPreferences(ctx context.Context, userID pgtype.UUID) ([]NotificationPreference, error)
+	ListOrganizationInvitations(ctx context.Context, organizationID pgtype.UUID) ([]OrganizationInvitation, error)
+	ListOrganizationMembers(ctx context.Context, organizationID pgtype.UUID) ([]ListOrganizationMembersRow, error)
+	ListOrganizationProjects(ctx context.Context, organizationID pgtype.UUID) ([]ResearchProject, error)
+	// References in use with notes or highlights, for literature review generation
+	ListProjectAnnotatedReferences(ctx context.Context, projectID pgtype.UUID) ([]ListProjectAnnotatedReferencesRow, error)
+	// In lettering order
+	ListProjectAppendices(ctx context.Context, projectID pgtype.UUID) ([]ProjectAppendix, error)
+	ListProjectCollaborators(ctx context.Context, projectID pgtype.UUID) ([]ListProjectCollaboratorsRow, error)
+	ListProjectDatasets(ctx context.Context, projectID pgtype.UUID) ([]ProjectDataset, error)
+	// Figures and tables of all a project's chapters, in numbering order within each chapter
+	ListProjectFigures(ctx context.Context, projectID pgtype.UUID) ([]ChapterFigure, error)
+	// Papers already imported, including references in the trash
+	ListProjectPaperIDs(ctx context.Context, projectID pgtype.UUID) ([]string, error)
+	// The project's references that Semantic Scholar can identify, newest first
+	ListProjectPaperSeeds(ctx context.Context, projectID pgtype.UUID) ([]ListProjectPaperSeedsRow, error)
+	ListProjectQuestionnaires(ctx context.Context, projectID pgtype.UUID) ([]Questionnaire, error)
+	ListProjectReferenceHighlights(ctx context.Context, projectID pgtype.UUID) ([]ReferenceHighlight, error)
+	ListProjectReferenceTags(ctx context.Context, projectID pgtype.UUID) ([]ReferenceTag, error)
+	// Every section of the project's chapters, in order within each chapter
+	ListProjectSections(ctx context.Context, projectID pgtype.UUID) ([]ChapterSection, error)
+	// Extracted text of a project's uploads, oldest first, for AI generation
+	ListProjectSourceTexts(ctx context.Context, projectID pgtype.UUID) ([]ListProjectSourceTextsRow, error)
+	// References in use carrying a tag, with any notes, for scoped literature review generation
+	ListProjectTaggedReferences(ctx context.Context, arg ListProjectTaggedReferencesParams) ([]ListProjectTaggedReferencesRow, error)
+	ListProjectUploads(ctx context.Context, projectID pgtype.UUID) ([]ProjectUpload, error)
+	ListProjectWebhooks(ctx context.Context, projectID pgtype.UUID) ([]Webhook, error)
+	// Files of the papers PurgeTrashedReferences is about to delete
+	ListPurgeableReferencePDFs(ctx context.Context, deletedAt pgtype.Timestamptz) ([]string, error)
+	ListQuotaUsage(ctx context.Context, arg ListQuotaUsageParams) ([]ListQuotaUsageRow, error)
+	ListReferenceHighlights(ctx context.Context, referenceID pgtype.UUID) ([]ReferenceHighlight, error)
+	// References without an embedding for the current model, oldest first
+	ListReferencesMissingEmbedding(ctx context.Context, arg ListReferencesMissingEmbeddingParams) ([]ListReferencesMissingEmbeddingRow, error)
+	// Full-text passages of the project's imported papers that match the query, best first
+	ListRelevantPaperChunks(ctx context.Context, arg ListRelevantPaperChunksParams) ([]ListRelevantPaperChunksRow, error)
+	ListReviewQueue(ctx context.Context, advisorID pgtype.UUID) ([]ListReviewQueueRow, error)
+	ListTrashedChapters(ctx context.Context, projectID pgtype.UUID) ([]Chapter, error)
+	// Ensure user owns project for delete if needed, or handled at service layer
+	ListTrashedReferences(ctx context.Context, projectID pgtype.UUID) ([]Reference, error)
+	ListUploadSessionChunks(ctx context.Context, sessionID pgtype.UUID) ([]UploadSessionChunk, error)
+	ListUsage(ctx context.Context, arg ListUsageParams) ([]ListUsageRow, error)
+	ListUserNotifications(ctx context.Context, arg ListUserNotificationsParams) ([]Notification, error)
+	ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]ListUserOrganizationsRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	MarkAllNotificationsRead(ctx context.Context, userID pgtype.UUID) error
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error)
+	// Fills in what a reference is missing from a duplicate of it, keeping what it has
+	MergeReference(ctx context.Context, arg MergeReferenceParams) (Reference, error)
+	// Whether chapters of the project, trashed ones included, have the type
+	ProjectChapterTypeInUse(ctx context.Context, arg ProjectChapterTypeInUseParams) (bool, error)
+	PurgeTrashedChapters(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	PurgeTrashedReferences(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	RecordStripeEvent(ctx context.Context, arg RecordStripeEventParams) (int64, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error
+	ReleaseQuota(ctx context.Context, arg ReleaseQuotaParams) error
+	ReleaseUsage(ctx context.Context, arg ReleaseUsageParams) error
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error)
+	// Starts a job over from its first attempt; running jobs are left alone
+	RequeueJob(ctx context.Context, id pgtype.UUID) (Job, error)
+	// Returns no row when the amount would take usage past the limit
+	ReserveQuota(ctx context.Context, arg ReserveQuotaParams) (int64, error)
+	// Returns no row when the limit is already reached
+	ReserveUsage(ctx context.Context, arg ReserveUsageParams) (int32, error)
+	RestoreChapter(ctx context.Context, arg RestoreChapterParams) (Chapter, error)
+	RestoreReference(ctx context.Context, arg RestoreReferenceParams) (Reference, error)
+	RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (WebhookDelivery, error)
+	SaveGeneratedChapterSection(ctx context.Context, arg SaveGeneratedChapterSectionParams) (ChapterSection, error)
+	SaveUploadText(ctx context.Context, arg SaveUploadTextParams) error
+	SearchProjectChapters(ctx context.Context, arg SearchProjectChaptersParams) ([]SearchProjectChaptersRow, error)
+	SearchProjectReferences(ctx context.Context, arg SearchProjectReferencesParams) ([]SearchProjectReferencesRow, error)
+	SetAppendixFile(ctx context.Context, arg SetAppendixFileParams) (ProjectAppendix, error)
+	// Moves a chapter within the thesis; its content and version are untouched
+	SetChapterPosition(ctx context.Context, arg SetChapterPositionParams) error
+	SetChapterSectionPosition(ctx context.Context, arg SetChapterSectionPositionParams) error
+	// Used by the review workflow; bumps version so concurrent edits based on the old status conflict
+	SetChapterStatus(ctx context.Context, arg SetChapterStatusParams) (Chapter, error)
+	SetCommentThreadResolved(ctx context.Context, arg SetCommentThreadResolvedParams) (CommentThread, error)
+	SetDatasetTables(ctx context.Context, arg SetDatasetTablesParams) error
+	SetGuidelineResult(ctx context.Context, arg SetGuidelineResultParams) error
+	SetProjectAdvisor(ctx context.Context, arg SetProjectAdvisorParams) (ResearchProject, error)
+	SetReferencePaperPDF(ctx context.Context, arg SetReferencePaperPDFParams) error
+	SetTimelineInDocument(ctx context.Context, arg SetTimelineInDocumentParams) (ProjectTimeline, error)
+	SetUploadExtraction(ctx context.Context, arg SetUploadExtractionParams) error
+	SimilarProjectReferences(ctx context.Context, arg SimilarProjectReferencesParams) ([]SimilarProjectReferencesRow, error)
+	UpdateAppendix(ctx context.Context, arg UpdateAppendixParams) (ProjectAppendix, error)
+	// expected_version is optional; when set the update only applies if nobody else saved in between.
+	// custom_prompt is kept when NULL.
 	UpdateChapter(ctx context.Context, arg UpdateChapterParams) (Chapter, error)
+	UpdateChapterFigure(ctx context.Context, arg UpdateChapterFigureParams) (ChapterFigure, error)
+	UpdateChapterSection(ctx context.Context, arg UpdateChapterSectionParams) (ChapterSection, error)
+	UpdateChapterType(ctx context.Context, arg UpdateChapterTypeParams) (ChapterType, error)
 	UpdateGeneratedDocument(ctx context.Context, arg UpdateGeneratedDocumentParams) (GeneratedDocument, error)
 	UpdateGeneratedDocumentStatus(ctx context.Context, arg UpdateGeneratedDocumentStatusParams) (GeneratedDocument, error)
+	// Completed or failed runs are finished
+	UpdateGenerationBatch(ctx context.Context, arg UpdateGenerationBatchParams) (GenerationBatch, error)
+	UpdateNotificationEmailStatus(ctx context.Context, arg UpdateNotificationEmailStatusParams) error
+	UpdateQuestionnaire(ctx context.Context, arg UpdateQuestionnaireParams) (Questionnaire, error)
 	UpdateResearchProject(ctx context.Context, arg UpdateResearchProjectParams) (ResearchProject, error)
 	UpdateResearchProjectStatus(ctx context.Context, arg UpdateResearchProjectStatusParams) (ResearchProject, error)
+	UpdateSubscriptionFromStripe(ctx context.Context, arg UpdateSubscriptionFromStripeParams) (Subscription, error)
 	UpdateUserVerificationStatus(ctx context.Context, arg UpdateUserVerificationStatusParams) (User, error)
+	UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error)
+	// Merges an autosave into the editor's draft; a NULL title or content keeps the draft's
+	UpsertChapterDraft(ctx context.Context, arg UpsertChapterDraftParams) (ChapterDraft, error)
+	UpsertChapterMetrics(ctx context.Context, arg UpsertChapterMetricsParams) (ChapterMetric, error)
+	// A save that lost a race to a newer one leaves the newer spans in place
+	UpsertChapterProvenance(ctx context.Context, arg UpsertChapterProvenanceParams) error
+	UpsertFeatureFlag(ctx context.Context, arg UpsertFeatureFlagParams) (FeatureFlag, error)
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
+	// Inviting the same email again refreshes the role and expiry
+	UpsertOrganizationInvitation(ctx context.Context, arg UpsertOrganizationInvitationParams) (OrganizationInvitation, error)
+	UpsertProjectCollaborator(ctx context.Context, arg UpsertProjectCollaboratorParams) (ProjectCollaborator, error)
+	UpsertProjectFormatting(ctx context.Context, arg UpsertProjectFormattingParams) error
+	UpsertProjectFrontMatter(ctx context.Context, arg UpsertProjectFrontMatterParams) error
+	UpsertProjectGenerationPreferences(ctx context.Context, arg UpsertProjectGenerationPreferencesParams) error
+	// Replacing a guideline gives it a new id, so extraction jobs for the old file find nothing
+	UpsertProjectGuideline(ctx context.Context, arg UpsertProjectGuidelineParams) (ProjectGuideline, error)
+	UpsertProjectMethodology(ctx context.Context, arg UpsertProjectMethodologyParams) (ProjectMethodology, error)
+	// A regenerated timeline replaces the project's previous one
+	UpsertProjectTimeline(ctx context.Context, arg UpsertProjectTimelineParams) (ProjectTimeline, error)
+	UpsertReferenceEmbedding(ctx context.Context, arg UpsertReferenceEmbeddingParams) error
+	UpsertReferenceNotes(ctx context.Context, arg UpsertReferenceNotesParams) (ReferenceNote, error)
+	UpsertUserGenerationPreferences(ctx context.Context, arg UpsertUserGenerationPreferencesParams) error
 }
 
 var _ Querier = (*Queries)(nil)
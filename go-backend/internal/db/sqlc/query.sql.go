@@ -11,224 +11,143 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const blockSession = `-- name: BlockSession :one
-UPDATE sessions
-SET is_blocked = TRUE
-WHERE id = $1
-RETURNING id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+const acknowledgeIntegrityPolicy = `-- name: AcknowledgeIntegrityPolicy :one
+INSERT INTO integrity_acknowledgments (user_id, policy_version)
+VALUES ($1, $2)
+ON CONFLICT (user_id, policy_version) DO UPDATE SET acknowledged_at = NOW()
+RETURNING user_id, policy_version, acknowledged_at
 `
 
-func (q *Queries) BlockSession(ctx context.Context, id pgtype.UUID) (Session, error) {
-	row := q.db.QueryRow(ctx, blockSession, id)
-	var i Session
+type AcknowledgeIntegrityPolicyParams struct {
+	UserID        pgtype.UUID `db:"user_id" json:"user_id"`
+	PolicyVersion string      `db:"policy_version" json:"policy_version"`
+}
+
+func (q *Queries) AcknowledgeIntegrityPolicy(ctx context.Context, arg AcknowledgeIntegrityPolicyParams) (IntegrityAcknowledgment, error) {
+	row := q.db.QueryRow(ctx, acknowledgeIntegrityPolicy, arg.UserID, arg.PolicyVersion)
+	var i IntegrityAcknowledgment
 	err := row.Scan(
-		&i.ID,
 		&i.UserID,
-		&i.RefreshToken,
-		&i.UserAgent,
-		&i.ClientIp,
-		&i.IsBlocked,
-		&i.ExpiresAt,
-		&i.CreatedAt,
+		&i.PolicyVersion,
+		&i.AcknowledgedAt,
 	)
 	return i, err
 }
 
-const createChapter = `-- name: CreateChapter :one
-INSERT INTO chapters (
-    project_id, type, title, content, word_count
-) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at
+const addOrganizationMember = `-- name: AddOrganizationMember :one
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES ($1, $2, $3)
+ON CONFLICT (organization_id, user_id) DO UPDATE SET role = organization_members.role
+RETURNING organization_id, user_id, role, created_at
 `
 
-type CreateChapterParams struct {
-	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
-	Type      string      `db:"type" json:"type"`
-	Title     string      `db:"title" json:"title"`
-	Content   pgtype.Text `db:"content" json:"content"`
-	WordCount pgtype.Int4 `db:"word_count" json:"word_count"`
+type AddOrganizationMemberParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	Role           string      `db:"role" json:"role"`
 }
 
-func (q *Queries) CreateChapter(ctx context.Context, arg CreateChapterParams) (Chapter, error) {
-	row := q.db.QueryRow(ctx, createChapter,
-		arg.ProjectID,
-		arg.Type,
-		arg.Title,
-		arg.Content,
-		arg.WordCount,
-	)
-	var i Chapter
+// An existing member keeps their current role
+func (q *Queries) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRow(ctx, addOrganizationMember, arg.OrganizationID, arg.UserID, arg.Role)
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.Type,
-		&i.Title,
-		&i.Content,
-		&i.WordCount,
-		&i.Status,
+		&i.OrganizationID,
+		&i.UserID,
+		&i.Role,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const createGeneratedDocument = `-- name: CreateGeneratedDocument :one
-INSERT INTO generated_documents (
-    project_id, file_name, file_path, file_size, mime_type
-) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at
+const addQuotaUsage = `-- name: AddQuotaUsage :exec
+INSERT INTO quota_usage (user_id, period_start, resource, used)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = quota_usage.used + EXCLUDED.used
 `
 
-type CreateGeneratedDocumentParams struct {
-	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
-	FileName  string      `db:"file_name" json:"file_name"`
-	FilePath  string      `db:"file_path" json:"file_path"`
-	FileSize  pgtype.Int8 `db:"file_size" json:"file_size"`
-	MimeType  pgtype.Text `db:"mime_type" json:"mime_type"`
+type AddQuotaUsageParams struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	Resource    string             `db:"resource" json:"resource"`
+	Amount      int64              `db:"amount" json:"amount"`
 }
 
-func (q *Queries) CreateGeneratedDocument(ctx context.Context, arg CreateGeneratedDocumentParams) (GeneratedDocument, error) {
-	row := q.db.QueryRow(ctx, createGeneratedDocument,
-		arg.ProjectID,
-		arg.FileName,
-		arg.FilePath,
-		arg.FileSize,
-		arg.MimeType,
-	)
-	var i GeneratedDocument
-	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.FileName,
-		&i.FilePath,
-		&i.FileSize,
-		&i.MimeType,
-		&i.Status,
-		&i.CreatedAt,
+// Records usage known only after the fact, e.g. words generated, even past the limit
+func (q *Queries) AddQuotaUsage(ctx context.Context, arg AddQuotaUsageParams) error {
+	_, err := q.db.Exec(ctx, addQuotaUsage,
+		arg.UserID,
+		arg.PeriodStart,
+		arg.Resource,
+		arg.Amount,
 	)
-	return i, err
+	return err
 }
 
-const createReference = `-- name: CreateReference :one
-INSERT INTO "references" ( -- Quoted
-    project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla
-) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at
+const addReferenceTag = `-- name: AddReferenceTag :exec
+INSERT INTO reference_tags (reference_id, project_id, tag)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
 `
 
-type CreateReferenceParams struct {
-	ProjectID       pgtype.UUID `db:"project_id" json:"project_id"`
-	Title           string      `db:"title" json:"title"`
-	Authors         pgtype.Text `db:"authors" json:"authors"`
-	Journal         pgtype.Text `db:"journal" json:"journal"`
-	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
-	Doi             pgtype.Text `db:"doi" json:"doi"`
-	Url             pgtype.Text `db:"url" json:"url"`
-	CitationApa     pgtype.Text `db:"citation_apa" json:"citation_apa"`
-	CitationMla     pgtype.Text `db:"citation_mla" json:"citation_mla"`
+type AddReferenceTagParams struct {
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Tag         string      `db:"tag" json:"tag"`
 }
 
-func (q *Queries) CreateReference(ctx context.Context, arg CreateReferenceParams) (Reference, error) {
-	row := q.db.QueryRow(ctx, createReference,
-		arg.ProjectID,
-		arg.Title,
-		arg.Authors,
-		arg.Journal,
-		arg.PublicationYear,
-		arg.Doi,
-		arg.Url,
-		arg.CitationApa,
-		arg.CitationMla,
-	)
-	var i Reference
-	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.Title,
-		&i.Authors,
-		&i.Journal,
-		&i.PublicationYear,
-		&i.Doi,
-		&i.Url,
-		&i.CitationApa,
-		&i.CitationMla,
-		&i.CreatedAt,
-	)
-	return i, err
+func (q *Queries) AddReferenceTag(ctx context.Context, arg AddReferenceTagParams) error {
+	_, err := q.db.Exec(ctx, addReferenceTag, arg.ReferenceID, arg.ProjectID, arg.Tag)
+	return err
 }
 
-const createResearchProject = `-- name: CreateResearchProject :one
-INSERT INTO research_projects (
-    user_id, title, specialization, university, description
-) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at
+const advanceUploadSession = `-- name: AdvanceUploadSession :one
+UPDATE upload_sessions
+SET received_size = received_size + $1,
+    status = CASE WHEN received_size + $1 = total_size THEN 'assembling' ELSE status END
+WHERE id = $2 AND received_size = $3 AND status = 'receiving' AND expires_at > NOW()
+RETURNING id, project_id, user_id, file_name, total_size, received_size, sha256, status, error, upload_id, expires_at, created_at, updated_at
 `
 
-type CreateResearchProjectParams struct {
-	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
-	Title          string      `db:"title" json:"title"`
-	Specialization string      `db:"specialization" json:"specialization"`
-	University     pgtype.Text `db:"university" json:"university"`
-	Description    pgtype.Text `db:"description" json:"description"`
+type AdvanceUploadSessionParams struct {
+	ChunkSize    int64       `db:"chunk_size" json:"chunk_size"`
+	ID           pgtype.UUID `db:"id" json:"id"`
+	ReceivedSize int64       `db:"received_size" json:"received_size"`
 }
 
-func (q *Queries) CreateResearchProject(ctx context.Context, arg CreateResearchProjectParams) (ResearchProject, error) {
-	row := q.db.QueryRow(ctx, createResearchProject,
-		arg.UserID,
-		arg.Title,
-		arg.Specialization,
-		arg.University,
-		arg.Description,
-	)
-	var i ResearchProject
+// Records a chunk received at received_size, only if no other chunk got there first.
+// The session moves on to assembling with the last byte.
+func (q *Queries) AdvanceUploadSession(ctx context.Context, arg AdvanceUploadSessionParams) (UploadSession, error) {
+	row := q.db.QueryRow(ctx, advanceUploadSession, arg.ChunkSize, arg.ID, arg.ReceivedSize)
+	var i UploadSession
 	err := row.Scan(
 		&i.ID,
+		&i.ProjectID,
 		&i.UserID,
-		&i.Title,
-		&i.Specialization,
-		&i.University,
-		&i.Description,
+		&i.FileName,
+		&i.TotalSize,
+		&i.ReceivedSize,
+		&i.Sha256,
 		&i.Status,
+		&i.Error,
+		&i.UploadID,
+		&i.ExpiresAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const createSession = `-- name: CreateSession :one
-
-INSERT INTO sessions (
-    id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at
-) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+const blockSession = `-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = TRUE
+WHERE id = $1
+RETURNING id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
 `
 
-type CreateSessionParams struct {
-	ID           pgtype.UUID        `db:"id" json:"id"`
-	UserID       pgtype.UUID        `db:"user_id" json:"user_id"`
-	RefreshToken string             `db:"refresh_token" json:"refresh_token"`
-	UserAgent    pgtype.Text        `db:"user_agent" json:"user_agent"`
-	ClientIp     pgtype.Text        `db:"client_ip" json:"client_ip"`
-	IsBlocked    pgtype.Bool        `db:"is_blocked" json:"is_blocked"`
-	ExpiresAt    pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
-}
-
-// Ensure user owns project for delete if needed, or handled at service layer
-func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
-	row := q.db.QueryRow(ctx, createSession,
-		arg.ID,
-		arg.UserID,
-		arg.RefreshToken,
-		arg.UserAgent,
-		arg.ClientIp,
-		arg.IsBlocked,
-		arg.ExpiresAt,
-	)
+func (q *Queries) BlockSession(ctx context.Context, id pgtype.UUID) (Session, error) {
+	row := q.db.QueryRow(ctx, blockSession, id)
 	var i Session
 	err := row.Scan(
 		&i.ID,
@@ -243,23 +162,164 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 	return i, err
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (
-    email, password_hash, first_name, last_name
-) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at
+const chapterTypeInUse = `-- name: ChapterTypeInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM chapters c
+    JOIN research_projects p ON p.id = c.project_id
+    WHERE p.organization_id = $1 AND c.type = $2
+) AS in_use
 `
 
-type CreateUserParams struct {
-	Email        string `db:"email" json:"email"`
-	PasswordHash string `db:"password_hash" json:"password_hash"`
-	FirstName    string `db:"first_name" json:"first_name"`
-	LastName     string `db:"last_name" json:"last_name"`
+type ChapterTypeInUseParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	Type           string      `db:"type" json:"type"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser,
+// Whether chapters of the organization's projects, trashed ones included, have the type
+func (q *Queries) ChapterTypeInUse(ctx context.Context, arg ChapterTypeInUseParams) (bool, error) {
+	row := q.db.QueryRow(ctx, chapterTypeInUse, arg.OrganizationID, arg.Type)
+	var inUse bool
+	err := row.Scan(&inUse)
+	return inUse, err
+}
+
+const claimDueJobs = `-- name: ClaimDueJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, run_at = $1
+WHERE id IN (
+    SELECT due.id FROM jobs due
+    WHERE due.status IN ('pending', 'running') AND due.run_at <= NOW()
+        AND due.kind = ANY($2::text[])
+    ORDER BY due.run_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, completed_at, created_at, updated_at
+`
+
+type ClaimDueJobsParams struct {
+	LeasedUntil pgtype.Timestamptz `db:"leased_until" json:"leased_until"`
+	Kinds       []string           `db:"kinds" json:"kinds"`
+	BatchSize   int32              `db:"batch_size" json:"batch_size"`
+}
+
+// Leases a batch by marking it running until leased_until. A running job whose
+// lease ran out, because its worker died, is claimed again.
+func (q *Queries) ClaimDueJobs(ctx context.Context, arg ClaimDueJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, claimDueJobs, arg.LeasedUntil, arg.Kinds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.UniqueKey,
+			&i.LastError,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimDueWebhookDeliveries = `-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = $1
+FROM webhooks
+WHERE webhooks.id = webhook_deliveries.webhook_id
+    AND webhook_deliveries.id IN (
+        SELECT due.id FROM webhook_deliveries due
+        WHERE due.status = 'pending' AND due.next_attempt_at <= NOW()
+        ORDER BY due.next_attempt_at
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts, webhook_deliveries.next_attempt_at, webhook_deliveries.response_status, webhook_deliveries.last_error, webhook_deliveries.duration_ms, webhook_deliveries.delivered_at, webhook_deliveries.created_at, webhooks.url, webhooks.secret
+`
+
+type ClaimDueWebhookDeliveriesParams struct {
+	LeasedUntil pgtype.Timestamptz `db:"leased_until" json:"leased_until"`
+	BatchSize   int32              `db:"batch_size" json:"batch_size"`
+}
+
+type ClaimDueWebhookDeliveriesRow struct {
+	WebhookDelivery WebhookDelivery `db:"webhook_delivery" json:"webhook_delivery"`
+	Url             string          `db:"url" json:"url"`
+	Secret          string          `db:"secret" json:"secret"`
+}
+
+// Leases a batch by moving next_attempt_at to leased_until, so other workers skip it
+// without a transaction being held open while the HTTP requests run
+func (q *Queries) ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueWebhookDeliveries, arg.LeasedUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimDueWebhookDeliveriesRow{}
+	for rows.Next() {
+		var i ClaimDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.WebhookDelivery.ID,
+			&i.WebhookDelivery.WebhookID,
+			&i.WebhookDelivery.Event,
+			&i.WebhookDelivery.Payload,
+			&i.WebhookDelivery.Status,
+			&i.WebhookDelivery.Attempts,
+			&i.WebhookDelivery.NextAttemptAt,
+			&i.WebhookDelivery.ResponseStatus,
+			&i.WebhookDelivery.LastError,
+			&i.WebhookDelivery.DurationMs,
+			&i.WebhookDelivery.DeliveredAt,
+			&i.WebhookDelivery.CreatedAt,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimGuestUser = `-- name: ClaimGuestUser :one
+UPDATE users
+SET email = $2, password_hash = $3, first_name = $4, last_name = $5, guest_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND guest_expires_at IS NOT NULL
+RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at
+`
+
+type ClaimGuestUserParams struct {
+	ID           pgtype.UUID `db:"id" json:"id"`
+	Email        string      `db:"email" json:"email"`
+	PasswordHash string      `db:"password_hash" json:"password_hash"`
+	FirstName    string      `db:"first_name" json:"first_name"`
+	LastName     string      `db:"last_name" json:"last_name"`
+}
+
+// Turns a guest into a registered user, keeping its projects
+func (q *Queries) ClaimGuestUser(ctx context.Context, arg ClaimGuestUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, claimGuestUser,
+		arg.ID,
 		arg.Email,
 		arg.PasswordHash,
 		arg.FirstName,
@@ -275,110 +335,298 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.IsVerified,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.GuestExpiresAt,
 	)
 	return i, err
 }
 
-const deleteChapter = `-- name: DeleteChapter :exec
-DELETE FROM chapters
-WHERE chapters.id = $1 AND project_id = (SELECT project_id FROM research_projects WHERE research_projects.id = $2 AND user_id = $3)
+const claimPendingNotificationEmails = `-- name: ClaimPendingNotificationEmails :many
+SELECT notifications.id, notifications.user_id, notifications.type, notifications.project_id, notifications.chapter_id, notifications.document_id, notifications.title, notifications.body, notifications.in_app, notifications.read_at, notifications.email_status, notifications.email_attempts, notifications.email_sent_at, notifications.created_at, users.email, users.first_name
+FROM notifications
+JOIN users ON users.id = notifications.user_id
+WHERE notifications.email_status = 'pending'
+ORDER BY notifications.created_at
+LIMIT $1
+FOR UPDATE OF notifications SKIP LOCKED
 `
 
-type DeleteChapterParams struct {
-	ID     pgtype.UUID `db:"id" json:"id"`
-	ID_2   pgtype.UUID `db:"id_2" json:"id_2"`
-	UserID pgtype.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *Queries) DeleteChapter(ctx context.Context, arg DeleteChapterParams) error {
-	_, err := q.db.Exec(ctx, deleteChapter, arg.ID, arg.ID_2, arg.UserID)
-	return err
+type ClaimPendingNotificationEmailsRow struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	UserID        pgtype.UUID        `db:"user_id" json:"user_id"`
+	Type          string             `db:"type" json:"type"`
+	ProjectID     pgtype.UUID        `db:"project_id" json:"project_id"`
+	ChapterID     pgtype.UUID        `db:"chapter_id" json:"chapter_id"`
+	DocumentID    pgtype.UUID        `db:"document_id" json:"document_id"`
+	Title         string             `db:"title" json:"title"`
+	Body          string             `db:"body" json:"body"`
+	InApp         bool               `db:"in_app" json:"in_app"`
+	ReadAt        pgtype.Timestamptz `db:"read_at" json:"read_at"`
+	EmailStatus   string             `db:"email_status" json:"email_status"`
+	EmailAttempts int32              `db:"email_attempts" json:"email_attempts"`
+	EmailSentAt   pgtype.Timestamptz `db:"email_sent_at" json:"email_sent_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Email         string             `db:"email" json:"email"`
+	FirstName     string             `db:"first_name" json:"first_name"`
 }
 
-const deleteGeneratedDocument = `-- name: DeleteGeneratedDocument :exec
-DELETE FROM generated_documents
+// Locks a batch for the calling transaction; other workers skip it
+func (q *Queries) ClaimPendingNotificationEmails(ctx context.Context, limit int32) ([]ClaimPendingNotificationEmailsRow, error) {
+	rows, err := q.db.Query(ctx, claimPendingNotificationEmails, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ClaimPendingNotificationEmailsRow{}
+	for rows.Next() {
+		var i ClaimPendingNotificationEmailsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Type,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.DocumentID,
+			&i.Title,
+			&i.Body,
+			&i.InApp,
+			&i.ReadAt,
+			&i.EmailStatus,
+			&i.EmailAttempts,
+			&i.EmailSentAt,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FirstName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :one
+UPDATE idempotency_keys
+SET response_status = $2, response_body = $3, response_hash = $4, completed_at = NOW()
 WHERE id = $1
+RETURNING id, user_id, idempotency_key, request_method, request_path, request_hash, response_status, response_body, response_hash, completed_at, created_at
 `
 
-func (q *Queries) DeleteGeneratedDocument(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteGeneratedDocument, id)
-	return err
+type CompleteIdempotencyKeyParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	ResponseStatus pgtype.Int4 `db:"response_status" json:"response_status"`
+	ResponseBody   []byte      `db:"response_body" json:"response_body"`
+	ResponseHash   pgtype.Text `db:"response_hash" json:"response_hash"`
 }
 
-const deleteReference = `-- name: DeleteReference :exec
-DELETE FROM "references" -- Quoted
-WHERE id = $1 AND project_id = $2
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, completeIdempotencyKey,
+		arg.ID,
+		arg.ResponseStatus,
+		arg.ResponseBody,
+		arg.ResponseHash,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.RequestMethod,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.ResponseHash,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const completeJob = `-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'completed', last_error = '', completed_at = NOW()
+WHERE id = $1 AND attempts = $2 AND status = 'running'
 `
 
-type DeleteReferenceParams struct {
-	ID        pgtype.UUID `db:"id" json:"id"`
-	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+type CompleteJobParams struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	Attempts int32       `db:"attempts" json:"attempts"`
 }
 
-func (q *Queries) DeleteReference(ctx context.Context, arg DeleteReferenceParams) error {
-	_, err := q.db.Exec(ctx, deleteReference, arg.ID, arg.ProjectID)
+// Matching attempts stops a worker whose lease ran out from overwriting a newer run
+func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) error {
+	_, err := q.db.Exec(ctx, completeJob, arg.ID, arg.Attempts)
 	return err
 }
 
-const deleteResearchProject = `-- name: DeleteResearchProject :exec
-DELETE FROM research_projects
-WHERE id = $1 AND user_id = $2
+const countOpenCommentThreads = `-- name: CountOpenCommentThreads :many
+SELECT chapter_id, COUNT(*) AS open_count
+FROM comment_threads
+WHERE project_id = $1 AND resolved_at IS NULL
+GROUP BY chapter_id
 `
 
-type DeleteResearchProjectParams struct {
-	ID     pgtype.UUID `db:"id" json:"id"`
-	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+type CountOpenCommentThreadsRow struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	OpenCount int64       `db:"open_count" json:"open_count"`
 }
 
-func (q *Queries) DeleteResearchProject(ctx context.Context, arg DeleteResearchProjectParams) error {
-	_, err := q.db.Exec(ctx, deleteResearchProject, arg.ID, arg.UserID)
-	return err
+func (q *Queries) CountOpenCommentThreads(ctx context.Context, projectID pgtype.UUID) ([]CountOpenCommentThreadsRow, error) {
+	rows, err := q.db.Query(ctx, countOpenCommentThreads, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountOpenCommentThreadsRow{}
+	for rows.Next() {
+		var i CountOpenCommentThreadsRow
+		if err := rows.Scan(&i.ChapterID, &i.OpenCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const deleteSessionByRefreshToken = `-- name: DeleteSessionByRefreshToken :exec
-DELETE FROM sessions
-WHERE refresh_token = $1
+const countOrganizationOwners = `-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_members
+WHERE organization_id = $1 AND role = 'owner'
 `
 
-func (q *Queries) DeleteSessionByRefreshToken(ctx context.Context, refreshToken string) error {
-	_, err := q.db.Exec(ctx, deleteSessionByRefreshToken, refreshToken)
-	return err
+func (q *Queries) CountOrganizationOwners(ctx context.Context, organizationID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrganizationOwners, organizationID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
-const getChapterByID = `-- name: GetChapterByID :one
-SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at FROM chapters
-WHERE id = $1 LIMIT 1
+const countOwnedProjects = `-- name: CountOwnedProjects :one
+SELECT COUNT(*) FROM research_projects
+WHERE user_id = $1
 `
 
-func (q *Queries) GetChapterByID(ctx context.Context, id pgtype.UUID) (Chapter, error) {
-	row := q.db.QueryRow(ctx, getChapterByID, id)
-	var i Chapter
+func (q *Queries) CountOwnedProjects(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countOwnedProjects, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications
+WHERE user_id = $1 AND in_app AND read_at IS NULL
+`
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnreadNotifications, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAppendix = `-- name: CreateAppendix :one
+INSERT INTO project_appendices (project_id, title, content, position, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at
+`
+
+type CreateAppendixParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Title     string      `db:"title" json:"title"`
+	Content   string      `db:"content" json:"content"`
+	Position  int32       `db:"position" json:"position"`
+	CreatedBy pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateAppendix(ctx context.Context, arg CreateAppendixParams) (ProjectAppendix, error) {
+	row := q.db.QueryRow(ctx, createAppendix,
+		arg.ProjectID,
+		arg.Title,
+		arg.Content,
+		arg.Position,
+		arg.CreatedBy,
+	)
+	var i ProjectAppendix
 	err := row.Scan(
 		&i.ID,
 		&i.ProjectID,
-		&i.Type,
 		&i.Title,
 		&i.Content,
-		&i.WordCount,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createBillingCustomer = `-- name: CreateBillingCustomer :one
+INSERT INTO subscriptions (user_id, stripe_customer_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET user_id = subscriptions.user_id
+RETURNING user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end, cancel_at_period_end, created_at, updated_at
+`
+
+type CreateBillingCustomerParams struct {
+	UserID           pgtype.UUID `db:"user_id" json:"user_id"`
+	StripeCustomerID string      `db:"stripe_customer_id" json:"stripe_customer_id"`
+}
+
+// Keeps the existing customer when two checkouts race
+func (q *Queries) CreateBillingCustomer(ctx context.Context, arg CreateBillingCustomerParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, createBillingCustomer, arg.UserID, arg.StripeCustomerID)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.StripeCustomerID,
+		&i.StripeSubscriptionID,
+		&i.Plan,
 		&i.Status,
+		&i.CurrentPeriodEnd,
+		&i.CancelAtPeriodEnd,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getChapterByProjectIDAndType = `-- name: GetChapterByProjectIDAndType :one
-SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at FROM chapters
-WHERE project_id = $1 AND type = $2 LIMIT 1
+const createChapter = `-- name: CreateChapter :one
+INSERT INTO chapters (
+    project_id, type, title, content, word_count, target_word_count, custom_prompt, position
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM chapters WHERE project_id = $1) -- Last
+) RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position
 `
 
-type GetChapterByProjectIDAndTypeParams struct {
-	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
-	Type      string      `db:"type" json:"type"`
+type CreateChapterParams struct {
+	ProjectID       pgtype.UUID `db:"project_id" json:"project_id"`
+	Type            string      `db:"type" json:"type"`
+	Title           string      `db:"title" json:"title"`
+	Content         pgtype.Text `db:"content" json:"content"`
+	WordCount       pgtype.Int4 `db:"word_count" json:"word_count"`
+	TargetWordCount pgtype.Int4 `db:"target_word_count" json:"target_word_count"`
+	CustomPrompt    string      `db:"custom_prompt" json:"custom_prompt"`
 }
 
-func (q *Queries) GetChapterByProjectIDAndType(ctx context.Context, arg GetChapterByProjectIDAndTypeParams) (Chapter, error) {
-	row := q.db.QueryRow(ctx, getChapterByProjectIDAndType, arg.ProjectID, arg.Type)
+func (q *Queries) CreateChapter(ctx context.Context, arg CreateChapterParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, createChapter,
+		arg.ProjectID,
+		arg.Type,
+		arg.Title,
+		arg.Content,
+		arg.WordCount,
+		arg.TargetWordCount,
+		arg.CustomPrompt,
+	)
 	var i Chapter
 	err := row.Scan(
 		&i.ID,
@@ -390,137 +638,6127 @@ func (q *Queries) GetChapterByProjectIDAndType(ctx context.Context, arg GetChapt
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
 	)
 	return i, err
 }
 
-const getChaptersByProjectID = `-- name: GetChaptersByProjectID :many
-SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at FROM chapters
-WHERE project_id = $1
-ORDER BY
-    CASE type
-        WHEN 'introduction' THEN 1
-        WHEN 'literature_review' THEN 2
-        WHEN 'methodology' THEN 3
-        WHEN 'results' THEN 4
-        WHEN 'conclusion' THEN 5
-        ELSE 6
-    END
+const createChapterCitation = `-- name: CreateChapterCitation :exec
+INSERT INTO chapter_citations (chapter_id, project_id, reference_id, marker, author, year, position, chapter_version)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (chapter_id, position) DO NOTHING
 `
 
-func (q *Queries) GetChaptersByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Chapter, error) {
-	rows, err := q.db.Query(ctx, getChaptersByProjectID, projectID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []Chapter{}
-	for rows.Next() {
-		var i Chapter
-		if err := rows.Scan(
-			&i.ID,
-			&i.ProjectID,
-			&i.Type,
-			&i.Title,
-			&i.Content,
-			&i.WordCount,
-			&i.Status,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type CreateChapterCitationParams struct {
+	ChapterID      pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+	ReferenceID    pgtype.UUID `db:"reference_id" json:"reference_id"`
+	Marker         string      `db:"marker" json:"marker"`
+	Author         string      `db:"author" json:"author"`
+	Year           int32       `db:"year" json:"year"`
+	Position       int32       `db:"position" json:"position"`
+	ChapterVersion int32       `db:"chapter_version" json:"chapter_version"`
 }
 
-const getGeneratedDocumentByID = `-- name: GetGeneratedDocumentByID :one
-SELECT id, project_id, file_name, file_path, file_size, mime_type, status, created_at FROM generated_documents
-WHERE id = $1 LIMIT 1
+// A concurrent rebuild may have written the same marker already
+func (q *Queries) CreateChapterCitation(ctx context.Context, arg CreateChapterCitationParams) error {
+	_, err := q.db.Exec(ctx, createChapterCitation,
+		arg.ChapterID,
+		arg.ProjectID,
+		arg.ReferenceID,
+		arg.Marker,
+		arg.Author,
+		arg.Year,
+		arg.Position,
+		arg.ChapterVersion,
+	)
+	return err
+}
+
+const createChapterEvaluation = `-- name: CreateChapterEvaluation :one
+INSERT INTO chapter_evaluations (project_id, chapter_id, chapter_version, rubric, criteria, summary, evaluated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, project_id, chapter_id, chapter_version, rubric, criteria, summary, evaluated_by, created_at
 `
 
-func (q *Queries) GetGeneratedDocumentByID(ctx context.Context, id pgtype.UUID) (GeneratedDocument, error) {
-	row := q.db.QueryRow(ctx, getGeneratedDocumentByID, id)
-	var i GeneratedDocument
+type CreateChapterEvaluationParams struct {
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID      pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ChapterVersion int32       `db:"chapter_version" json:"chapter_version"`
+	Rubric         []byte      `db:"rubric" json:"rubric"`
+	Criteria       []byte      `db:"criteria" json:"criteria"`
+	Summary        string      `db:"summary" json:"summary"`
+	EvaluatedBy    pgtype.UUID `db:"evaluated_by" json:"evaluated_by"`
+}
+
+func (q *Queries) CreateChapterEvaluation(ctx context.Context, arg CreateChapterEvaluationParams) (ChapterEvaluation, error) {
+	row := q.db.QueryRow(ctx, createChapterEvaluation,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.ChapterVersion,
+		arg.Rubric,
+		arg.Criteria,
+		arg.Summary,
+		arg.EvaluatedBy,
+	)
+	var i ChapterEvaluation
 	err := row.Scan(
 		&i.ID,
 		&i.ProjectID,
-		&i.FileName,
+		&i.ChapterID,
+		&i.ChapterVersion,
+		&i.Rubric,
+		&i.Criteria,
+		&i.Summary,
+		&i.EvaluatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createChapterFigure = `-- name: CreateChapterFigure :one
+INSERT INTO chapter_figures (id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at
+`
+
+type CreateChapterFigureParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Kind      string      `db:"kind" json:"kind"`
+	Caption   string      `db:"caption" json:"caption"`
+	Position  int32       `db:"position" json:"position"`
+	FilePath  string      `db:"file_path" json:"file_path"`
+	MimeType  string      `db:"mime_type" json:"mime_type"`
+	TableData []byte      `db:"table_data" json:"table_data"`
+	CreatedBy pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateChapterFigure(ctx context.Context, arg CreateChapterFigureParams) (ChapterFigure, error) {
+	row := q.db.QueryRow(ctx, createChapterFigure,
+		arg.ID,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.Kind,
+		arg.Caption,
+		arg.Position,
+		arg.FilePath,
+		arg.MimeType,
+		arg.TableData,
+		arg.CreatedBy,
+	)
+	var i ChapterFigure
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.Kind,
+		&i.Caption,
+		&i.Position,
 		&i.FilePath,
-		&i.FileSize,
 		&i.MimeType,
-		&i.Status,
+		&i.TableData,
+		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getGeneratedDocumentsByProjectID = `-- name: GetGeneratedDocumentsByProjectID :many
-SELECT id, project_id, file_name, file_path, file_size, mime_type, status, created_at FROM generated_documents
-WHERE project_id = $1
-ORDER BY created_at DESC
+const createChapterReview = `-- name: CreateChapterReview :one
+INSERT INTO chapter_reviews (project_id, chapter_id, chapter_version, submitted_by, submission_note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, project_id, chapter_id, chapter_version, status, submitted_by, submission_note, reviewed_by, decision_note, submitted_at, decided_at
 `
 
-func (q *Queries) GetGeneratedDocumentsByProjectID(ctx context.Context, projectID pgtype.UUID) ([]GeneratedDocument, error) {
-	rows, err := q.db.Query(ctx, getGeneratedDocumentsByProjectID, projectID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []GeneratedDocument{}
-	for rows.Next() {
-		var i GeneratedDocument
-		if err := rows.Scan(
-			&i.ID,
-			&i.ProjectID,
-			&i.FileName,
-			&i.FilePath,
-			&i.FileSize,
-			&i.MimeType,
-			&i.Status,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type CreateChapterReviewParams struct {
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID      pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ChapterVersion int32       `db:"chapter_version" json:"chapter_version"`
+	SubmittedBy    pgtype.UUID `db:"submitted_by" json:"submitted_by"`
+	SubmissionNote string      `db:"submission_note" json:"submission_note"`
 }
 
-const getReferencesByProjectID = `-- name: GetReferencesByProjectID :many
-SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at FROM "references" -- Quoted
-WHERE project_id = $1
-ORDER BY created_at DESC
+func (q *Queries) CreateChapterReview(ctx context.Context, arg CreateChapterReviewParams) (ChapterReview, error) {
+	row := q.db.QueryRow(ctx, createChapterReview,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.ChapterVersion,
+		arg.SubmittedBy,
+		arg.SubmissionNote,
+	)
+	var i ChapterReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.ChapterVersion,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionNote,
+		&i.ReviewedBy,
+		&i.DecisionNote,
+		&i.SubmittedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const createChapterSection = `-- name: CreateChapterSection :one
+INSERT INTO chapter_sections (chapter_id, project_id, title, level, content, word_count, position)
+VALUES (
+    $1, $2, $3, $4, $5, $6,
+    (SELECT COALESCE(MAX(position), 0) + 1 FROM chapter_sections WHERE chapter_id = $1) -- Last
+)
+RETURNING id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at
 `
 
-func (q *Queries) GetReferencesByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Reference, error) {
-	rows, err := q.db.Query(ctx, getReferencesByProjectID, projectID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []Reference{}
-	for rows.Next() {
-		var i Reference
-		if err := rows.Scan(
-			&i.ID,
-			&i.ProjectID,
-			&i.Title,
-			&i.Authors,
-			&i.Journal,
-			&i.PublicationYear,
-			&i.Doi,
-			&i.Url,
+type CreateChapterSectionParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Title     string      `db:"title" json:"title"`
+	Level     int32       `db:"level" json:"level"`
+	Content   string      `db:"content" json:"content"`
+	WordCount int32       `db:"word_count" json:"word_count"`
+}
+
+func (q *Queries) CreateChapterSection(ctx context.Context, arg CreateChapterSectionParams) (ChapterSection, error) {
+	row := q.db.QueryRow(ctx, createChapterSection,
+		arg.ChapterID,
+		arg.ProjectID,
+		arg.Title,
+		arg.Level,
+		arg.Content,
+		arg.WordCount,
+	)
+	var i ChapterSection
+	err := row.Scan(
+		&i.ID,
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Level,
+		&i.Position,
+		&i.Content,
+		&i.WordCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GeneratedAt,
+	)
+	return i, err
+}
+
+const createChapterType = `-- name: CreateChapterType :one
+INSERT INTO chapter_types (organization_id, project_id, key, label, description, position, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, organization_id, key, label, description, position, created_by, created_at, updated_at, project_id
+`
+
+type CreateChapterTypeParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+	Key            string      `db:"key" json:"key"`
+	Label          string      `db:"label" json:"label"`
+	Description    string      `db:"description" json:"description"`
+	Position       int32       `db:"position" json:"position"`
+	CreatedBy      pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateChapterType(ctx context.Context, arg CreateChapterTypeParams) (ChapterType, error) {
+	row := q.db.QueryRow(ctx, createChapterType,
+		arg.OrganizationID,
+		arg.ProjectID,
+		arg.Key,
+		arg.Label,
+		arg.Description,
+		arg.Position,
+		arg.CreatedBy,
+	)
+	var i ChapterType
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Key,
+		&i.Label,
+		&i.Description,
+		&i.Position,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const createComment = `-- name: CreateComment :one
+INSERT INTO comments (thread_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING id, thread_id, user_id, body, created_at
+`
+
+type CreateCommentParams struct {
+	ThreadID pgtype.UUID `db:"thread_id" json:"thread_id"`
+	UserID   pgtype.UUID `db:"user_id" json:"user_id"`
+	Body     string      `db:"body" json:"body"`
+}
+
+func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, createComment, arg.ThreadID, arg.UserID, arg.Body)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.ThreadID,
+		&i.UserID,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCommentThread = `-- name: CreateCommentThread :one
+INSERT INTO comment_threads (
+    project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by, resolved_at, resolved_by, created_at, updated_at
+`
+
+type CreateCommentThreadParams struct {
+	ProjectID     pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID     pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	AnchorStart   pgtype.Int4 `db:"anchor_start" json:"anchor_start"`
+	AnchorEnd     pgtype.Int4 `db:"anchor_end" json:"anchor_end"`
+	AnchorHeading pgtype.Text `db:"anchor_heading" json:"anchor_heading"`
+	QuotedText    string      `db:"quoted_text" json:"quoted_text"`
+	CreatedBy     pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateCommentThread(ctx context.Context, arg CreateCommentThreadParams) (CommentThread, error) {
+	row := q.db.QueryRow(ctx, createCommentThread,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.AnchorStart,
+		arg.AnchorEnd,
+		arg.AnchorHeading,
+		arg.QuotedText,
+		arg.CreatedBy,
+	)
+	var i CommentThread
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorHeading,
+		&i.QuotedText,
+		&i.CreatedBy,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createGeneratedDocument = `-- name: CreateGeneratedDocument :one
+INSERT INTO generated_documents (
+    project_id, file_name, file_path, file_size, mime_type
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at, storage_key
+`
+
+type CreateGeneratedDocumentParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	FileName  string      `db:"file_name" json:"file_name"`
+	FilePath  string      `db:"file_path" json:"file_path"`
+	FileSize  pgtype.Int8 `db:"file_size" json:"file_size"`
+	MimeType  pgtype.Text `db:"mime_type" json:"mime_type"`
+}
+
+func (q *Queries) CreateGeneratedDocument(ctx context.Context, arg CreateGeneratedDocumentParams) (GeneratedDocument, error) {
+	row := q.db.QueryRow(ctx, createGeneratedDocument,
+		arg.ProjectID,
+		arg.FileName,
+		arg.FilePath,
+		arg.FileSize,
+		arg.MimeType,
+	)
+	var i GeneratedDocument
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Status,
+		&i.CreatedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const createGeneration = `-- name: CreateGeneration :exec
+INSERT INTO generations (
+    user_id, project_id, chapter_id, kind, model, temperature, max_tokens, prompt_hash,
+    prompt, response, prompt_tokens, completion_tokens, latency_ms, outcome, error
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+`
+
+type CreateGenerationParams struct {
+	UserID           pgtype.UUID `db:"user_id" json:"user_id"`
+	ProjectID        pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID        pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Kind             string      `db:"kind" json:"kind"`
+	Model            string      `db:"model" json:"model"`
+	Temperature      float64     `db:"temperature" json:"temperature"`
+	MaxTokens        int32       `db:"max_tokens" json:"max_tokens"`
+	PromptHash       string      `db:"prompt_hash" json:"prompt_hash"`
+	Prompt           []byte      `db:"prompt" json:"prompt"`
+	Response         string      `db:"response" json:"response"`
+	PromptTokens     int32       `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int32       `db:"completion_tokens" json:"completion_tokens"`
+	LatencyMs        int32       `db:"latency_ms" json:"latency_ms"`
+	Outcome          string      `db:"outcome" json:"outcome"`
+	Error            pgtype.Text `db:"error" json:"error"`
+}
+
+func (q *Queries) CreateGeneration(ctx context.Context, arg CreateGenerationParams) error {
+	_, err := q.db.Exec(ctx, createGeneration,
+		arg.UserID,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.Kind,
+		arg.Model,
+		arg.Temperature,
+		arg.MaxTokens,
+		arg.PromptHash,
+		arg.Prompt,
+		arg.Response,
+		arg.PromptTokens,
+		arg.CompletionTokens,
+		arg.LatencyMs,
+		arg.Outcome,
+		arg.Error,
+	)
+	return err
+}
+
+const createGenerationBatch = `-- name: CreateGenerationBatch :one
+INSERT INTO generation_batches (project_id, user_id, chapters)
+VALUES ($1, $2, $3)
+RETURNING id, project_id, user_id, status, chapters, error, created_at, updated_at, completed_at
+`
+
+type CreateGenerationBatchParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+	Chapters  []byte      `db:"chapters" json:"chapters"`
+}
+
+func (q *Queries) CreateGenerationBatch(ctx context.Context, arg CreateGenerationBatchParams) (GenerationBatch, error) {
+	row := q.db.QueryRow(ctx, createGenerationBatch, arg.ProjectID, arg.UserID, arg.Chapters)
+	var i GenerationBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.Status,
+		&i.Chapters,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const createGuestUser = `-- name: CreateGuestUser :one
+INSERT INTO users (email, password_hash, first_name, last_name, guest_expires_at)
+VALUES ($1, $2, $3, '', $4)
+RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at
+`
+
+type CreateGuestUserParams struct {
+	Email          string             `db:"email" json:"email"`
+	PasswordHash   string             `db:"password_hash" json:"password_hash"`
+	FirstName      string             `db:"first_name" json:"first_name"`
+	GuestExpiresAt pgtype.Timestamptz `db:"guest_expires_at" json:"guest_expires_at"`
+}
+
+func (q *Queries) CreateGuestUser(ctx context.Context, arg CreateGuestUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createGuestUser,
+		arg.Email,
+		arg.PasswordHash,
+		arg.FirstName,
+		arg.GuestExpiresAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.IsVerified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GuestExpiresAt,
+	)
+	return i, err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    user_id, idempotency_key, request_method, request_path, request_hash
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+RETURNING id, user_id, idempotency_key, request_method, request_path, request_hash, response_status, response_body, response_hash, completed_at, created_at
+`
+
+type CreateIdempotencyKeyParams struct {
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	IdempotencyKey string      `db:"idempotency_key" json:"idempotency_key"`
+	RequestMethod  string      `db:"request_method" json:"request_method"`
+	RequestPath    string      `db:"request_path" json:"request_path"`
+	RequestHash    string      `db:"request_hash" json:"request_hash"`
+}
+
+// Returns no rows when the key already exists for the user
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.RequestMethod,
+		arg.RequestPath,
+		arg.RequestHash,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.RequestMethod,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.ResponseHash,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createNotification = `-- name: CreateNotification :one
+INSERT INTO notifications (
+    user_id, type, project_id, chapter_id, document_id, title, body, in_app, email_status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, user_id, type, project_id, chapter_id, document_id, title, body, in_app, read_at, email_status, email_attempts, email_sent_at, created_at
+`
+
+type CreateNotificationParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	Type        string      `db:"type" json:"type"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	ChapterID   pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	DocumentID  pgtype.UUID `db:"document_id" json:"document_id"`
+	Title       string      `db:"title" json:"title"`
+	Body        string      `db:"body" json:"body"`
+	InApp       bool        `db:"in_app" json:"in_app"`
+	EmailStatus string      `db:"email_status" json:"email_status"`
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
+	row := q.db.QueryRow(ctx, createNotification,
+		arg.UserID,
+		arg.Type,
+		arg.ProjectID,
+		arg.ChapterID,
+		arg.DocumentID,
+		arg.Title,
+		arg.Body,
+		arg.InApp,
+		arg.EmailStatus,
+	)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Type,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.DocumentID,
+		&i.Title,
+		&i.Body,
+		&i.InApp,
+		&i.ReadAt,
+		&i.EmailStatus,
+		&i.EmailAttempts,
+		&i.EmailSentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organizations (name, created_by)
+VALUES ($1, $2)
+RETURNING id, name, created_by, created_at, updated_at
+`
+
+type CreateOrganizationParams struct {
+	Name      string      `db:"name" json:"name"`
+	CreatedBy pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRow(ctx, createOrganization, arg.Name, arg.CreatedBy)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createProjectDataset = `-- name: CreateProjectDataset :one
+INSERT INTO project_datasets (project_id, uploaded_by, file_name, row_count, columns)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, project_id, uploaded_by, file_name, row_count, columns, table_ids, created_at, updated_at
+`
+
+type CreateProjectDatasetParams struct {
+	ProjectID  pgtype.UUID `db:"project_id" json:"project_id"`
+	UploadedBy pgtype.UUID `db:"uploaded_by" json:"uploaded_by"`
+	FileName   string      `db:"file_name" json:"file_name"`
+	RowCount   int32       `db:"row_count" json:"row_count"`
+	Columns    []byte      `db:"columns" json:"columns"`
+}
+
+func (q *Queries) CreateProjectDataset(ctx context.Context, arg CreateProjectDatasetParams) (ProjectDataset, error) {
+	row := q.db.QueryRow(ctx, createProjectDataset,
+		arg.ProjectID,
+		arg.UploadedBy,
+		arg.FileName,
+		arg.RowCount,
+		arg.Columns,
+	)
+	var i ProjectDataset
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.RowCount,
+		&i.Columns,
+		&i.TableIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createProjectUpload = `-- name: CreateProjectUpload :one
+INSERT INTO project_uploads (id, project_id, uploaded_by, file_name, file_path, file_size, sha256)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, project_id, uploaded_by, file_name, file_path, file_size, sha256, extraction_status, extraction_error, page_count, created_at, updated_at
+`
+
+type CreateProjectUploadParams struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	ProjectID  pgtype.UUID `db:"project_id" json:"project_id"`
+	UploadedBy pgtype.UUID `db:"uploaded_by" json:"uploaded_by"`
+	FileName   string      `db:"file_name" json:"file_name"`
+	FilePath   string      `db:"file_path" json:"file_path"`
+	FileSize   int64       `db:"file_size" json:"file_size"`
+	Sha256     string      `db:"sha256" json:"sha256"`
+}
+
+func (q *Queries) CreateProjectUpload(ctx context.Context, arg CreateProjectUploadParams) (ProjectUpload, error) {
+	row := q.db.QueryRow(ctx, createProjectUpload,
+		arg.ID,
+		arg.ProjectID,
+		arg.UploadedBy,
+		arg.FileName,
+		arg.FilePath,
+		arg.FileSize,
+		arg.Sha256,
+	)
+	var i ProjectUpload
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.Sha256,
+		&i.ExtractionStatus,
+		&i.ExtractionError,
+		&i.PageCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createQuestionnaire = `-- name: CreateQuestionnaire :one
+INSERT INTO questionnaires (project_id, title, instructions, sections, include_in_document, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, title, instructions, sections, include_in_document, created_by, created_at, updated_at
+`
+
+type CreateQuestionnaireParams struct {
+	ProjectID         pgtype.UUID `db:"project_id" json:"project_id"`
+	Title             string      `db:"title" json:"title"`
+	Instructions      string      `db:"instructions" json:"instructions"`
+	Sections          []byte      `db:"sections" json:"sections"`
+	IncludeInDocument bool        `db:"include_in_document" json:"include_in_document"`
+	CreatedBy         pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateQuestionnaire(ctx context.Context, arg CreateQuestionnaireParams) (Questionnaire, error) {
+	row := q.db.QueryRow(ctx, createQuestionnaire,
+		arg.ProjectID,
+		arg.Title,
+		arg.Instructions,
+		arg.Sections,
+		arg.IncludeInDocument,
+		arg.CreatedBy,
+	)
+	var i Questionnaire
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Instructions,
+		&i.Sections,
+		&i.IncludeInDocument,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createReference = `-- name: CreateReference :one
+INSERT INTO "references" ( -- Quoted
+    project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, abstract
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at
+`
+
+type CreateReferenceParams struct {
+	ProjectID       pgtype.UUID `db:"project_id" json:"project_id"`
+	Title           string      `db:"title" json:"title"`
+	Authors         pgtype.Text `db:"authors" json:"authors"`
+	Journal         pgtype.Text `db:"journal" json:"journal"`
+	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
+	Doi             pgtype.Text `db:"doi" json:"doi"`
+	Url             pgtype.Text `db:"url" json:"url"`
+	CitationApa     pgtype.Text `db:"citation_apa" json:"citation_apa"`
+	CitationMla     pgtype.Text `db:"citation_mla" json:"citation_mla"`
+	Abstract        pgtype.Text `db:"abstract" json:"abstract"`
+}
+
+func (q *Queries) CreateReference(ctx context.Context, arg CreateReferenceParams) (Reference, error) {
+	row := q.db.QueryRow(ctx, createReference,
+		arg.ProjectID,
+		arg.Title,
+		arg.Authors,
+		arg.Journal,
+		arg.PublicationYear,
+		arg.Doi,
+		arg.Url,
+		arg.CitationApa,
+		arg.CitationMla,
+		arg.Abstract,
+	)
+	var i Reference
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Authors,
+		&i.Journal,
+		&i.PublicationYear,
+		&i.Doi,
+		&i.Url,
+		&i.CitationApa,
+		&i.CitationMla,
+		&i.CreatedAt,
+		&i.Abstract,
+		&i.SearchVector,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createReferenceHighlight = `-- name: CreateReferenceHighlight :one
+INSERT INTO reference_highlights (reference_id, project_id, text, comment, page, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, reference_id, project_id, text, comment, page, created_by, created_at
+`
+
+type CreateReferenceHighlightParams struct {
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Text        string      `db:"text" json:"text"`
+	Comment     string      `db:"comment" json:"comment"`
+	Page        pgtype.Int4 `db:"page" json:"page"`
+	CreatedBy   pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateReferenceHighlight(ctx context.Context, arg CreateReferenceHighlightParams) (ReferenceHighlight, error) {
+	row := q.db.QueryRow(ctx, createReferenceHighlight,
+		arg.ReferenceID,
+		arg.ProjectID,
+		arg.Text,
+		arg.Comment,
+		arg.Page,
+		arg.CreatedBy,
+	)
+	var i ReferenceHighlight
+	err := row.Scan(
+		&i.ID,
+		&i.ReferenceID,
+		&i.ProjectID,
+		&i.Text,
+		&i.Comment,
+		&i.Page,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createReferencePaper = `-- name: CreateReferencePaper :exec
+INSERT INTO reference_papers (reference_id, project_id, paper_id, open_access_pdf_url, pdf_status)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateReferencePaperParams struct {
+	ReferenceID      pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID        pgtype.UUID `db:"project_id" json:"project_id"`
+	PaperID          string      `db:"paper_id" json:"paper_id"`
+	OpenAccessPdfUrl string      `db:"open_access_pdf_url" json:"open_access_pdf_url"`
+	PdfStatus        string      `db:"pdf_status" json:"pdf_status"`
+}
+
+func (q *Queries) CreateReferencePaper(ctx context.Context, arg CreateReferencePaperParams) error {
+	_, err := q.db.Exec(ctx, createReferencePaper,
+		arg.ReferenceID,
+		arg.ProjectID,
+		arg.PaperID,
+		arg.OpenAccessPdfUrl,
+		arg.PdfStatus,
+	)
+	return err
+}
+
+const createReferencePaperChunks = `-- name: CreateReferencePaperChunks :exec
+INSERT INTO reference_paper_chunks (reference_id, project_id, position, content)
+SELECT $1, $2, c.ord - 1, c.content
+FROM unnest($3::text[]) WITH ORDINALITY AS c(content, ord)
+`
+
+type CreateReferencePaperChunksParams struct {
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Contents    []string    `db:"contents" json:"contents"`
+}
+
+func (q *Queries) CreateReferencePaperChunks(ctx context.Context, arg CreateReferencePaperChunksParams) error {
+	_, err := q.db.Exec(ctx, createReferencePaperChunks, arg.ReferenceID, arg.ProjectID, arg.Contents)
+	return err
+}
+
+const createResearchProject = `-- name: CreateResearchProject :one
+INSERT INTO research_projects (
+    user_id, title, specialization, university, description, organization_id, language
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language
+`
+
+type CreateResearchProjectParams struct {
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	Title          string      `db:"title" json:"title"`
+	Specialization string      `db:"specialization" json:"specialization"`
+	University     pgtype.Text `db:"university" json:"university"`
+	Description    pgtype.Text `db:"description" json:"description"`
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	Language       string      `db:"language" json:"language"`
+}
+
+func (q *Queries) CreateResearchProject(ctx context.Context, arg CreateResearchProjectParams) (ResearchProject, error) {
+	row := q.db.QueryRow(ctx, createResearchProject,
+		arg.UserID,
+		arg.Title,
+		arg.Specialization,
+		arg.University,
+		arg.Description,
+		arg.OrganizationID,
+		arg.Language,
+	)
+	var i ResearchProject
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Specialization,
+		&i.University,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.AdvisorID,
+		&i.Language,
+	)
+	return i, err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+    id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+`
+
+type CreateSessionParams struct {
+	ID           pgtype.UUID        `db:"id" json:"id"`
+	UserID       pgtype.UUID        `db:"user_id" json:"user_id"`
+	RefreshToken string             `db:"refresh_token" json:"refresh_token"`
+	UserAgent    pgtype.Text        `db:"user_agent" json:"user_agent"`
+	ClientIp     pgtype.Text        `db:"client_ip" json:"client_ip"`
+	IsBlocked    pgtype.Bool        `db:"is_blocked" json:"is_blocked"`
+	ExpiresAt    pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, createSession,
+		arg.ID,
+		arg.UserID,
+		arg.RefreshToken,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.IsBlocked,
+		arg.ExpiresAt,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUploadSession = `-- name: CreateUploadSession :one
+INSERT INTO upload_sessions (project_id, user_id, file_name, total_size, sha256, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, user_id, file_name, total_size, received_size, sha256, status, error, upload_id, expires_at, created_at, updated_at
+`
+
+type CreateUploadSessionParams struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	FileName  string             `db:"file_name" json:"file_name"`
+	TotalSize int64              `db:"total_size" json:"total_size"`
+	Sha256    string             `db:"sha256" json:"sha256"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreateUploadSession(ctx context.Context, arg CreateUploadSessionParams) (UploadSession, error) {
+	row := q.db.QueryRow(ctx, createUploadSession,
+		arg.ProjectID,
+		arg.UserID,
+		arg.FileName,
+		arg.TotalSize,
+		arg.Sha256,
+		arg.ExpiresAt,
+	)
+	var i UploadSession
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.FileName,
+		&i.TotalSize,
+		&i.ReceivedSize,
+		&i.Sha256,
+		&i.Status,
+		&i.Error,
+		&i.UploadID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createUploadSessionChunk = `-- name: CreateUploadSessionChunk :exec
+INSERT INTO upload_session_chunks (session_id, byte_offset, size, storage_key)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateUploadSessionChunkParams struct {
+	SessionID  pgtype.UUID `db:"session_id" json:"session_id"`
+	ByteOffset int64       `db:"byte_offset" json:"byte_offset"`
+	Size       int64       `db:"size" json:"size"`
+	StorageKey string      `db:"storage_key" json:"storage_key"`
+}
+
+func (q *Queries) CreateUploadSessionChunk(ctx context.Context, arg CreateUploadSessionChunkParams) error {
+	_, err := q.db.Exec(ctx, createUploadSessionChunk,
+		arg.SessionID,
+		arg.ByteOffset,
+		arg.Size,
+		arg.StorageKey,
+	)
+	return err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (
+    email, password_hash, first_name, last_name
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at
+`
+
+type CreateUserParams struct {
+	Email        string `db:"email" json:"email"`
+	PasswordHash string `db:"password_hash" json:"password_hash"`
+	FirstName    string `db:"first_name" json:"first_name"`
+	LastName     string `db:"last_name" json:"last_name"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser,
+		arg.Email,
+		arg.PasswordHash,
+		arg.FirstName,
+		arg.LastName,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.IsVerified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GuestExpiresAt,
+	)
+	return i, err
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (project_id, url, secret, events, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, project_id, url, secret, events, active, created_by, created_at, updated_at
+`
+
+type CreateWebhookParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Url       string      `db:"url" json:"url"`
+	Secret    string      `db:"secret" json:"secret"`
+	Events    []string    `db:"events" json:"events"`
+	CreatedBy pgtype.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, createWebhook,
+		arg.ProjectID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.CreatedBy,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (id, webhook_id, event, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, duration_ms, delivered_at, created_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	WebhookID pgtype.UUID `db:"webhook_id" json:"webhook_id"`
+	Event     string      `db:"event" json:"event"`
+	Payload   []byte      `db:"payload" json:"payload"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.WebhookID,
+		arg.Event,
+		arg.Payload,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DurationMs,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const decideChapterReview = `-- name: DecideChapterReview :one
+UPDATE chapter_reviews
+SET status = $2, reviewed_by = $3, decision_note = $4, decided_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING id, project_id, chapter_id, chapter_version, status, submitted_by, submission_note, reviewed_by, decision_note, submitted_at, decided_at
+`
+
+type DecideChapterReviewParams struct {
+	ID           pgtype.UUID `db:"id" json:"id"`
+	Status       string      `db:"status" json:"status"`
+	ReviewedBy   pgtype.UUID `db:"reviewed_by" json:"reviewed_by"`
+	DecisionNote string      `db:"decision_note" json:"decision_note"`
+}
+
+func (q *Queries) DecideChapterReview(ctx context.Context, arg DecideChapterReviewParams) (ChapterReview, error) {
+	row := q.db.QueryRow(ctx, decideChapterReview,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.DecisionNote,
+	)
+	var i ChapterReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.ChapterVersion,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionNote,
+		&i.ReviewedBy,
+		&i.DecisionNote,
+		&i.SubmittedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const deleteAppendix = `-- name: DeleteAppendix :one
+DELETE FROM project_appendices
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at
+`
+
+type DeleteAppendixParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteAppendix(ctx context.Context, arg DeleteAppendixParams) (ProjectAppendix, error) {
+	row := q.db.QueryRow(ctx, deleteAppendix, arg.ID, arg.ProjectID)
+	var i ProjectAppendix
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteChapter = `-- name: DeleteChapter :execrows
+UPDATE chapters
+SET deleted_at = NOW()
+WHERE chapters.id = $1 AND deleted_at IS NULL
+    AND project_id = $2 AND project_access_role($2, $3) IN ('owner', 'edit')
+`
+
+type DeleteChapterParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+// Soft delete: the chapter moves to the project's trash
+func (q *Queries) DeleteChapter(ctx context.Context, arg DeleteChapterParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteChapter, arg.ID, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteChapterCitations = `-- name: DeleteChapterCitations :exec
+DELETE FROM chapter_citations
+WHERE chapter_id = $1
+`
+
+func (q *Queries) DeleteChapterCitations(ctx context.Context, chapterID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteChapterCitations, chapterID)
+	return err
+}
+
+const deleteChapterDraft = `-- name: DeleteChapterDraft :exec
+DELETE FROM chapter_drafts
+WHERE chapter_id = $1 AND user_id = $2
+`
+
+type DeleteChapterDraftParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) DeleteChapterDraft(ctx context.Context, arg DeleteChapterDraftParams) error {
+	_, err := q.db.Exec(ctx, deleteChapterDraft, arg.ChapterID, arg.UserID)
+	return err
+}
+
+const deleteChapterFigure = `-- name: DeleteChapterFigure :one
+DELETE FROM chapter_figures
+WHERE id = $1 AND chapter_id = $2
+RETURNING id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at
+`
+
+type DeleteChapterFigureParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+}
+
+func (q *Queries) DeleteChapterFigure(ctx context.Context, arg DeleteChapterFigureParams) (ChapterFigure, error) {
+	row := q.db.QueryRow(ctx, deleteChapterFigure, arg.ID, arg.ChapterID)
+	var i ChapterFigure
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.Kind,
+		&i.Caption,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.TableData,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteChapterSection = `-- name: DeleteChapterSection :exec
+DELETE FROM chapter_sections
+WHERE id = $1 AND chapter_id = $2
+`
+
+type DeleteChapterSectionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+}
+
+func (q *Queries) DeleteChapterSection(ctx context.Context, arg DeleteChapterSectionParams) error {
+	_, err := q.db.Exec(ctx, deleteChapterSection, arg.ID, arg.ChapterID)
+	return err
+}
+
+const deleteChapterType = `-- name: DeleteChapterType :exec
+DELETE FROM chapter_types
+WHERE organization_id = $1 AND key = $2
+`
+
+type DeleteChapterTypeParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	Key            string      `db:"key" json:"key"`
+}
+
+func (q *Queries) DeleteChapterType(ctx context.Context, arg DeleteChapterTypeParams) error {
+	_, err := q.db.Exec(ctx, deleteChapterType, arg.OrganizationID, arg.Key)
+	return err
+}
+
+const deleteCompletedJobs = `-- name: DeleteCompletedJobs :execrows
+DELETE FROM jobs
+WHERE status = 'completed' AND completed_at < $1
+`
+
+func (q *Queries) DeleteCompletedJobs(ctx context.Context, completedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCompletedJobs, completedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredGuests = `-- name: DeleteExpiredGuests :exec
+DELETE FROM users
+WHERE guest_expires_at < $1
+`
+
+func (q *Queries) DeleteExpiredGuests(ctx context.Context, guestExpiresAt pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, deleteExpiredGuests, guestExpiresAt)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE created_at < $1
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, createdAt pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys, createdAt)
+	return err
+}
+
+const deleteExpiredUploadSessions = `-- name: DeleteExpiredUploadSessions :exec
+DELETE FROM upload_sessions
+WHERE expires_at < $1 AND status <> 'assembling'
+`
+
+func (q *Queries) DeleteExpiredUploadSessions(ctx context.Context, expiresAt pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, deleteExpiredUploadSessions, expiresAt)
+	return err
+}
+
+const deleteFeatureFlag = `-- name: DeleteFeatureFlag :exec
+DELETE FROM feature_flags
+WHERE name = $1
+`
+
+func (q *Queries) DeleteFeatureFlag(ctx context.Context, name string) error {
+	_, err := q.db.Exec(ctx, deleteFeatureFlag, name)
+	return err
+}
+
+const deleteFiguresByID = `-- name: DeleteFiguresByID :exec
+DELETE FROM chapter_figures
+WHERE project_id = $1 AND id = ANY($2::uuid[])
+`
+
+type DeleteFiguresByIDParams struct {
+	ProjectID pgtype.UUID   `db:"project_id" json:"project_id"`
+	Ids       []pgtype.UUID `db:"ids" json:"ids"`
+}
+
+func (q *Queries) DeleteFiguresByID(ctx context.Context, arg DeleteFiguresByIDParams) error {
+	_, err := q.db.Exec(ctx, deleteFiguresByID, arg.ProjectID, arg.Ids)
+	return err
+}
+
+const deleteGeneratedDocument = `-- name: DeleteGeneratedDocument :exec
+DELETE FROM generated_documents
+WHERE id = $1
+`
+
+func (q *Queries) DeleteGeneratedDocument(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteGeneratedDocument, id)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE id = $1
+`
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, id)
+	return err
+}
+
+const deleteOrganizationInvitation = `-- name: DeleteOrganizationInvitation :exec
+DELETE FROM organization_invitations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteOrganizationInvitation(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteOrganizationInvitation, id)
+	return err
+}
+
+const deleteProjectChapterType = `-- name: DeleteProjectChapterType :exec
+DELETE FROM chapter_types
+WHERE project_id = $1 AND key = $2
+`
+
+type DeleteProjectChapterTypeParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Key       string      `db:"key" json:"key"`
+}
+
+func (q *Queries) DeleteProjectChapterType(ctx context.Context, arg DeleteProjectChapterTypeParams) error {
+	_, err := q.db.Exec(ctx, deleteProjectChapterType, arg.ProjectID, arg.Key)
+	return err
+}
+
+const deleteProjectCollaborator = `-- name: DeleteProjectCollaborator :execrows
+DELETE FROM project_collaborators
+WHERE project_id = $1 AND user_id = $2
+`
+
+type DeleteProjectCollaboratorParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) DeleteProjectCollaborator(ctx context.Context, arg DeleteProjectCollaboratorParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteProjectCollaborator, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteProjectDataset = `-- name: DeleteProjectDataset :one
+DELETE FROM project_datasets
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, uploaded_by, file_name, row_count, columns, table_ids, created_at, updated_at
+`
+
+type DeleteProjectDatasetParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteProjectDataset(ctx context.Context, arg DeleteProjectDatasetParams) (ProjectDataset, error) {
+	row := q.db.QueryRow(ctx, deleteProjectDataset, arg.ID, arg.ProjectID)
+	var i ProjectDataset
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.RowCount,
+		&i.Columns,
+		&i.TableIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteProjectGuideline = `-- name: DeleteProjectGuideline :one
+DELETE FROM project_guidelines
+WHERE project_id = $1
+RETURNING id, project_id, uploaded_by, file_name, file_path, mime_type, file_size, status, error, rules, created_at, updated_at
+`
+
+func (q *Queries) DeleteProjectGuideline(ctx context.Context, projectID pgtype.UUID) (ProjectGuideline, error) {
+	row := q.db.QueryRow(ctx, deleteProjectGuideline, projectID)
+	var i ProjectGuideline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Status,
+		&i.Error,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteProjectTimeline = `-- name: DeleteProjectTimeline :one
+DELETE FROM project_timelines
+WHERE project_id = $1
+RETURNING id, project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by, created_at, updated_at
+`
+
+func (q *Queries) DeleteProjectTimeline(ctx context.Context, projectID pgtype.UUID) (ProjectTimeline, error) {
+	row := q.db.QueryRow(ctx, deleteProjectTimeline, projectID)
+	var i ProjectTimeline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Milestones,
+		&i.Tasks,
+		&i.IncludeInDocument,
+		&i.GeneratedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteProjectUpload = `-- name: DeleteProjectUpload :one
+DELETE FROM project_uploads
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, uploaded_by, file_name, file_path, file_size, sha256, extraction_status, extraction_error, page_count, created_at, updated_at
+`
+
+type DeleteProjectUploadParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteProjectUpload(ctx context.Context, arg DeleteProjectUploadParams) (ProjectUpload, error) {
+	row := q.db.QueryRow(ctx, deleteProjectUpload, arg.ID, arg.ProjectID)
+	var i ProjectUpload
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.Sha256,
+		&i.ExtractionStatus,
+		&i.ExtractionError,
+		&i.PageCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteQuestionnaire = `-- name: DeleteQuestionnaire :one
+DELETE FROM questionnaires
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, title, instructions, sections, include_in_document, created_by, created_at, updated_at
+`
+
+type DeleteQuestionnaireParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteQuestionnaire(ctx context.Context, arg DeleteQuestionnaireParams) (Questionnaire, error) {
+	row := q.db.QueryRow(ctx, deleteQuestionnaire, arg.ID, arg.ProjectID)
+	var i Questionnaire
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Instructions,
+		&i.Sections,
+		&i.IncludeInDocument,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteReference = `-- name: DeleteReference :execrows
+UPDATE "references" -- Quoted
+SET deleted_at = NOW()
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+`
+
+type DeleteReferenceParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+// Soft delete: the reference moves to the project's trash
+func (q *Queries) DeleteReference(ctx context.Context, arg DeleteReferenceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteReference, arg.ID, arg.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteReferenceHighlight = `-- name: DeleteReferenceHighlight :one
+DELETE FROM reference_highlights
+WHERE id = $1 AND reference_id = $2
+RETURNING id, reference_id, project_id, text, comment, page, created_by, created_at
+`
+
+type DeleteReferenceHighlightParams struct {
+	ID          pgtype.UUID `db:"id" json:"id"`
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+}
+
+func (q *Queries) DeleteReferenceHighlight(ctx context.Context, arg DeleteReferenceHighlightParams) (ReferenceHighlight, error) {
+	row := q.db.QueryRow(ctx, deleteReferenceHighlight, arg.ID, arg.ReferenceID)
+	var i ReferenceHighlight
+	err := row.Scan(
+		&i.ID,
+		&i.ReferenceID,
+		&i.ProjectID,
+		&i.Text,
+		&i.Comment,
+		&i.Page,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteReferencePaperChunks = `-- name: DeleteReferencePaperChunks :exec
+DELETE FROM reference_paper_chunks
+WHERE reference_id = $1
+`
+
+func (q *Queries) DeleteReferencePaperChunks(ctx context.Context, referenceID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteReferencePaperChunks, referenceID)
+	return err
+}
+
+const deleteReferenceTags = `-- name: DeleteReferenceTags :exec
+DELETE FROM reference_tags
+WHERE reference_id = $1
+`
+
+func (q *Queries) DeleteReferenceTags(ctx context.Context, referenceID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteReferenceTags, referenceID)
+	return err
+}
+
+const deleteResearchProject = `-- name: DeleteResearchProject :execrows
+DELETE FROM research_projects
+WHERE id = $1 AND (research_projects.user_id = $2
+    OR organization_id IN (SELECT organization_id FROM organization_members WHERE organization_members.user_id = $2 AND role IN ('owner', 'admin')))
+`
+
+type DeleteResearchProjectParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+// Only the owner, or an owner/admin of the project's organization, may delete it
+func (q *Queries) DeleteResearchProject(ctx context.Context, arg DeleteResearchProjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteResearchProject, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSessionByRefreshToken = `-- name: DeleteSessionByRefreshToken :exec
+DELETE FROM sessions
+WHERE refresh_token = $1
+`
+
+func (q *Queries) DeleteSessionByRefreshToken(ctx context.Context, refreshToken string) error {
+	_, err := q.db.Exec(ctx, deleteSessionByRefreshToken, refreshToken)
+	return err
+}
+
+const deleteUploadSession = `-- name: DeleteUploadSession :one
+DELETE FROM upload_sessions
+WHERE id = $1 AND project_id = $2 AND status <> 'assembling'
+RETURNING id, project_id, user_id, file_name, total_size, received_size, sha256, status, error, upload_id, expires_at, created_at, updated_at
+`
+
+type DeleteUploadSessionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteUploadSession(ctx context.Context, arg DeleteUploadSessionParams) (UploadSession, error) {
+	row := q.db.QueryRow(ctx, deleteUploadSession, arg.ID, arg.ProjectID)
+	var i UploadSession
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.FileName,
+		&i.TotalSize,
+		&i.ReceivedSize,
+		&i.Sha256,
+		&i.Status,
+		&i.Error,
+		&i.UploadID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteUploadSessionChunks = `-- name: DeleteUploadSessionChunks :many
+DELETE FROM upload_session_chunks
+WHERE session_id = $1
+RETURNING storage_key
+`
+
+func (q *Queries) DeleteUploadSessionChunks(ctx context.Context, sessionID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, deleteUploadSessionChunks, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var storageKey string
+		if err := rows.Scan(&storageKey); err != nil {
+			return nil, err
+		}
+		items = append(items, storageKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND project_id = $2
+`
+
+type DeleteWebhookParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhook, arg.ID, arg.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO jobs (kind, payload, max_attempts, run_at, unique_key)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (unique_key) WHERE unique_key IS NOT NULL DO NOTHING
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, completed_at, created_at, updated_at
+`
+
+type EnqueueJobParams struct {
+	Kind        string             `db:"kind" json:"kind"`
+	Payload     []byte             `db:"payload" json:"payload"`
+	MaxAttempts int32              `db:"max_attempts" json:"max_attempts"`
+	RunAt       pgtype.Timestamptz `db:"run_at" json:"run_at"`
+	UniqueKey   pgtype.Text        `db:"unique_key" json:"unique_key"`
+}
+
+// Returns no row when unique_key is already taken
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error) {
+	row := q.db.QueryRow(ctx, enqueueJob,
+		arg.Kind,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+		arg.UniqueKey,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.UniqueKey,
+		&i.LastError,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const failJob = `-- name: FailJob :exec
+UPDATE jobs
+SET status = $1, run_at = $2, last_error = $3
+WHERE id = $4 AND attempts = $5 AND status = 'running'
+`
+
+type FailJobParams struct {
+	Status    string             `db:"status" json:"status"`
+	RunAt     pgtype.Timestamptz `db:"run_at" json:"run_at"`
+	LastError string             `db:"last_error" json:"last_error"`
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	Attempts  int32              `db:"attempts" json:"attempts"`
+}
+
+func (q *Queries) FailJob(ctx context.Context, arg FailJobParams) error {
+	_, err := q.db.Exec(ctx, failJob,
+		arg.Status,
+		arg.RunAt,
+		arg.LastError,
+		arg.ID,
+		arg.Attempts,
+	)
+	return err
+}
+
+const finishUploadSession = `-- name: FinishUploadSession :exec
+UPDATE upload_sessions
+SET status = $2, error = $3, upload_id = $4
+WHERE id = $1 AND status = 'assembling'
+`
+
+type FinishUploadSessionParams struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	Status   string      `db:"status" json:"status"`
+	Error    string      `db:"error" json:"error"`
+	UploadID pgtype.UUID `db:"upload_id" json:"upload_id"`
+}
+
+func (q *Queries) FinishUploadSession(ctx context.Context, arg FinishUploadSessionParams) error {
+	_, err := q.db.Exec(ctx, finishUploadSession,
+		arg.ID,
+		arg.Status,
+		arg.Error,
+		arg.UploadID,
+	)
+	return err
+}
+
+const getAppendix = `-- name: GetAppendix :one
+SELECT id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at FROM project_appendices
+WHERE id = $1 AND project_id = $2 LIMIT 1
+`
+
+type GetAppendixParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetAppendix(ctx context.Context, arg GetAppendixParams) (ProjectAppendix, error) {
+	row := q.db.QueryRow(ctx, getAppendix, arg.ID, arg.ProjectID)
+	var i ProjectAppendix
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getChapterByID = `-- name: GetChapterByID :one
+SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position FROM chapters
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetChapterByID(ctx context.Context, id pgtype.UUID) (Chapter, error) {
+	row := q.db.QueryRow(ctx, getChapterByID, id)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getChapterByProjectIDAndType = `-- name: GetChapterByProjectIDAndType :one
+SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position FROM chapters
+WHERE project_id = $1 AND type = $2 AND deleted_at IS NULL LIMIT 1
+`
+
+type GetChapterByProjectIDAndTypeParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Type      string      `db:"type" json:"type"`
+}
+
+func (q *Queries) GetChapterByProjectIDAndType(ctx context.Context, arg GetChapterByProjectIDAndTypeParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, getChapterByProjectIDAndType, arg.ProjectID, arg.Type)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getChapterDraft = `-- name: GetChapterDraft :one
+SELECT chapter_id, user_id, project_id, title, content, base_version, updated_at FROM chapter_drafts
+WHERE chapter_id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetChapterDraftParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) GetChapterDraft(ctx context.Context, arg GetChapterDraftParams) (ChapterDraft, error) {
+	row := q.db.QueryRow(ctx, getChapterDraft, arg.ChapterID, arg.UserID)
+	var i ChapterDraft
+	err := row.Scan(
+		&i.ChapterID,
+		&i.UserID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.BaseVersion,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getChapterEvaluation = `-- name: GetChapterEvaluation :one
+SELECT id, project_id, chapter_id, chapter_version, rubric, criteria, summary, evaluated_by, created_at FROM chapter_evaluations
+WHERE id = $1 AND chapter_id = $2 LIMIT 1
+`
+
+type GetChapterEvaluationParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+}
+
+func (q *Queries) GetChapterEvaluation(ctx context.Context, arg GetChapterEvaluationParams) (ChapterEvaluation, error) {
+	row := q.db.QueryRow(ctx, getChapterEvaluation, arg.ID, arg.ChapterID)
+	var i ChapterEvaluation
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.ChapterVersion,
+		&i.Rubric,
+		&i.Criteria,
+		&i.Summary,
+		&i.EvaluatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getChapterFigure = `-- name: GetChapterFigure :one
+SELECT id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at FROM chapter_figures
+WHERE id = $1 AND chapter_id = $2 LIMIT 1
+`
+
+type GetChapterFigureParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+}
+
+func (q *Queries) GetChapterFigure(ctx context.Context, arg GetChapterFigureParams) (ChapterFigure, error) {
+	row := q.db.QueryRow(ctx, getChapterFigure, arg.ID, arg.ChapterID)
+	var i ChapterFigure
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.Kind,
+		&i.Caption,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.TableData,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getChapterMetrics = `-- name: GetChapterMetrics :one
+SELECT chapter_id, project_id, version, metrics, computed_at FROM chapter_metrics
+WHERE chapter_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetChapterMetrics(ctx context.Context, chapterID pgtype.UUID) (ChapterMetric, error) {
+	row := q.db.QueryRow(ctx, getChapterMetrics, chapterID)
+	var i ChapterMetric
+	err := row.Scan(
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Version,
+		&i.Metrics,
+		&i.ComputedAt,
+	)
+	return i, err
+}
+
+const getChapterProvenance = `-- name: GetChapterProvenance :one
+SELECT chapter_id, project_id, version, spans, updated_at FROM chapter_provenance
+WHERE chapter_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetChapterProvenance(ctx context.Context, chapterID pgtype.UUID) (ChapterProvenance, error) {
+	row := q.db.QueryRow(ctx, getChapterProvenance, chapterID)
+	var i ChapterProvenance
+	err := row.Scan(
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Version,
+		&i.Spans,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getChapterSection = `-- name: GetChapterSection :one
+SELECT id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at FROM chapter_sections
+WHERE id = $1 AND chapter_id = $2
+`
+
+type GetChapterSectionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+}
+
+func (q *Queries) GetChapterSection(ctx context.Context, arg GetChapterSectionParams) (ChapterSection, error) {
+	row := q.db.QueryRow(ctx, getChapterSection, arg.ID, arg.ChapterID)
+	var i ChapterSection
+	err := row.Scan(
+		&i.ID,
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Level,
+		&i.Position,
+		&i.Content,
+		&i.WordCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GeneratedAt,
+	)
+	return i, err
+}
+
+const getChapterType = `-- name: GetChapterType :one
+SELECT id, organization_id, key, label, description, position, created_by, created_at, updated_at, project_id FROM chapter_types
+WHERE key = $1 AND ((organization_id IS NULL AND project_id IS NULL) OR organization_id = $2 OR project_id = $3)
+ORDER BY project_id NULLS FIRST, organization_id NULLS FIRST
+LIMIT 1
+`
+
+type GetChapterTypeParams struct {
+	Key            string      `db:"key" json:"key"`
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+// The built-in, organization or project chapter type with the key, in that order
+func (q *Queries) GetChapterType(ctx context.Context, arg GetChapterTypeParams) (ChapterType, error) {
+	row := q.db.QueryRow(ctx, getChapterType, arg.Key, arg.OrganizationID, arg.ProjectID)
+	var i ChapterType
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Key,
+		&i.Label,
+		&i.Description,
+		&i.Position,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const getChapterVersion = `-- name: GetChapterVersion :one
+SELECT chapter_id, project_id, version, title, content, word_count, status, created_at FROM chapter_versions
+WHERE chapter_id = $1 AND version = $2 LIMIT 1
+`
+
+type GetChapterVersionParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Version   int32       `db:"version" json:"version"`
+}
+
+func (q *Queries) GetChapterVersion(ctx context.Context, arg GetChapterVersionParams) (ChapterVersion, error) {
+	row := q.db.QueryRow(ctx, getChapterVersion, arg.ChapterID, arg.Version)
+	var i ChapterVersion
+	err := row.Scan(
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Version,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getChaptersByProjectID = `-- name: GetChaptersByProjectID :many
+SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position FROM chapters
+WHERE project_id = $1 AND deleted_at IS NULL
+ORDER BY position, created_at
+`
+
+func (q *Queries) GetChaptersByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Chapter, error) {
+	rows, err := q.db.Query(ctx, getChaptersByProjectID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Chapter{}
+	for rows.Next() {
+		var i Chapter
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Type,
+			&i.Title,
+			&i.Content,
+			&i.WordCount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SearchVector,
+			&i.Version,
+			&i.DeletedAt,
+			&i.TargetWordCount,
+			&i.CustomPrompt,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentThread = `-- name: GetCommentThread :one
+SELECT id, project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by, resolved_at, resolved_by, created_at, updated_at FROM comment_threads
+WHERE id = $1 AND project_id = $2 LIMIT 1
+`
+
+type GetCommentThreadParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetCommentThread(ctx context.Context, arg GetCommentThreadParams) (CommentThread, error) {
+	row := q.db.QueryRow(ctx, getCommentThread, arg.ID, arg.ProjectID)
+	var i CommentThread
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorHeading,
+		&i.QuotedText,
+		&i.CreatedBy,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getGeneratedDocumentByID = `-- name: GetGeneratedDocumentByID :one
+SELECT id, project_id, file_name, file_path, file_size, mime_type, status, created_at, storage_key FROM generated_documents
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetGeneratedDocumentByID(ctx context.Context, id pgtype.UUID) (GeneratedDocument, error) {
+	row := q.db.QueryRow(ctx, getGeneratedDocumentByID, id)
+	var i GeneratedDocument
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Status,
+		&i.CreatedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const getGeneratedDocumentsByProjectID = `-- name: GetGeneratedDocumentsByProjectID :many
+SELECT id, project_id, file_name, file_path, file_size, mime_type, status, created_at, storage_key FROM generated_documents
+WHERE project_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetGeneratedDocumentsByProjectID(ctx context.Context, projectID pgtype.UUID) ([]GeneratedDocument, error) {
+	rows, err := q.db.Query(ctx, getGeneratedDocumentsByProjectID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GeneratedDocument{}
+	for rows.Next() {
+		var i GeneratedDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.FileName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.MimeType,
+			&i.Status,
+			&i.CreatedAt,
+			&i.StorageKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGenerationBatch = `-- name: GetGenerationBatch :one
+SELECT id, project_id, user_id, status, chapters, error, created_at, updated_at, completed_at FROM generation_batches
+WHERE id = $1 AND project_id = $2
+`
+
+type GetGenerationBatchParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetGenerationBatch(ctx context.Context, arg GetGenerationBatchParams) (GenerationBatch, error) {
+	row := q.db.QueryRow(ctx, getGenerationBatch, arg.ID, arg.ProjectID)
+	var i GenerationBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.Status,
+		&i.Chapters,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getGuideline = `-- name: GetGuideline :one
+SELECT id, project_id, uploaded_by, file_name, file_path, mime_type, file_size, status, error, rules, created_at, updated_at FROM project_guidelines
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetGuideline(ctx context.Context, id pgtype.UUID) (ProjectGuideline, error) {
+	row := q.db.QueryRow(ctx, getGuideline, id)
+	var i ProjectGuideline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Status,
+		&i.Error,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT id, user_id, idempotency_key, request_method, request_path, request_hash, response_status, response_body, response_hash, completed_at, created_at FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 LIMIT 1
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	IdempotencyKey string      `db:"idempotency_key" json:"idempotency_key"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.RequestMethod,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.ResponseHash,
+		&i.CompletedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getIntegrityAcknowledgment = `-- name: GetIntegrityAcknowledgment :one
+SELECT user_id, policy_version, acknowledged_at FROM integrity_acknowledgments
+WHERE user_id = $1 AND policy_version = $2
+`
+
+type GetIntegrityAcknowledgmentParams struct {
+	UserID        pgtype.UUID `db:"user_id" json:"user_id"`
+	PolicyVersion string      `db:"policy_version" json:"policy_version"`
+}
+
+func (q *Queries) GetIntegrityAcknowledgment(ctx context.Context, arg GetIntegrityAcknowledgmentParams) (IntegrityAcknowledgment, error) {
+	row := q.db.QueryRow(ctx, getIntegrityAcknowledgment, arg.UserID, arg.PolicyVersion)
+	var i IntegrityAcknowledgment
+	err := row.Scan(
+		&i.UserID,
+		&i.PolicyVersion,
+		&i.AcknowledgedAt,
+	)
+	return i, err
+}
+
+const getJob = `-- name: GetJob :one
+SELECT id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, completed_at, created_at, updated_at FROM jobs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetJob(ctx context.Context, id pgtype.UUID) (Job, error) {
+	row := q.db.QueryRow(ctx, getJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.UniqueKey,
+		&i.LastError,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationByID = `-- name: GetOrganizationByID :one
+SELECT id, name, created_by, created_at, updated_at FROM organizations
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrganizationByID(ctx context.Context, id pgtype.UUID) (Organization, error) {
+	row := q.db.QueryRow(ctx, getOrganizationByID, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrganizationInvitation = `-- name: GetOrganizationInvitation :one
+SELECT id, organization_id, email, role, invited_by, expires_at, created_at, token_hash FROM organization_invitations
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOrganizationInvitation(ctx context.Context, id pgtype.UUID) (OrganizationInvitation, error) {
+	row := q.db.QueryRow(ctx, getOrganizationInvitation, id)
+	var i OrganizationInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Email,
+		&i.Role,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.TokenHash,
+	)
+	return i, err
+}
+
+const getOrganizationMember = `-- name: GetOrganizationMember :one
+SELECT organization_id, user_id, role, created_at FROM organization_members
+WHERE organization_id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetOrganizationMemberParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRow(ctx, getOrganizationMember, arg.OrganizationID, arg.UserID)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPendingChapterReview = `-- name: GetPendingChapterReview :one
+SELECT id, project_id, chapter_id, chapter_version, status, submitted_by, submission_note, reviewed_by, decision_note, submitted_at, decided_at FROM chapter_reviews
+WHERE chapter_id = $1 AND status = 'pending'
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetPendingChapterReview(ctx context.Context, chapterID pgtype.UUID) (ChapterReview, error) {
+	row := q.db.QueryRow(ctx, getPendingChapterReview, chapterID)
+	var i ChapterReview
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.ChapterVersion,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionNote,
+		&i.ReviewedBy,
+		&i.DecisionNote,
+		&i.SubmittedAt,
+		&i.DecidedAt,
+	)
+	return i, err
+}
+
+const getProjectChapterByID = `-- name: GetProjectChapterByID :one
+SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position FROM chapters
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL LIMIT 1
+`
+
+type GetProjectChapterByIDParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetProjectChapterByID(ctx context.Context, arg GetProjectChapterByIDParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, getProjectChapterByID, arg.ID, arg.ProjectID)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const getProjectDataset = `-- name: GetProjectDataset :one
+SELECT id, project_id, uploaded_by, file_name, row_count, columns, table_ids, created_at, updated_at FROM project_datasets
+WHERE id = $1 AND project_id = $2 LIMIT 1
+`
+
+type GetProjectDatasetParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetProjectDataset(ctx context.Context, arg GetProjectDatasetParams) (ProjectDataset, error) {
+	row := q.db.QueryRow(ctx, getProjectDataset, arg.ID, arg.ProjectID)
+	var i ProjectDataset
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.RowCount,
+		&i.Columns,
+		&i.TableIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectFormatting = `-- name: GetProjectFormatting :one
+SELECT options FROM project_formatting
+WHERE project_id = $1
+`
+
+func (q *Queries) GetProjectFormatting(ctx context.Context, projectID pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getProjectFormatting, projectID)
+	var options []byte
+	err := row.Scan(&options)
+	return options, err
+}
+
+const getProjectFrontMatter = `-- name: GetProjectFrontMatter :one
+SELECT content FROM project_front_matter
+WHERE project_id = $1
+`
+
+func (q *Queries) GetProjectFrontMatter(ctx context.Context, projectID pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getProjectFrontMatter, projectID)
+	var content []byte
+	err := row.Scan(&content)
+	return content, err
+}
+
+const getProjectGenerationPreferences = `-- name: GetProjectGenerationPreferences :one
+SELECT preferences FROM project_generation_preferences
+WHERE project_id = $1
+`
+
+func (q *Queries) GetProjectGenerationPreferences(ctx context.Context, projectID pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getProjectGenerationPreferences, projectID)
+	var preferences []byte
+	err := row.Scan(&preferences)
+	return preferences, err
+}
+
+const getProjectGuideline = `-- name: GetProjectGuideline :one
+SELECT id, project_id, uploaded_by, file_name, file_path, mime_type, file_size, status, error, rules, created_at, updated_at FROM project_guidelines
+WHERE project_id = $1
+`
+
+func (q *Queries) GetProjectGuideline(ctx context.Context, projectID pgtype.UUID) (ProjectGuideline, error) {
+	row := q.db.QueryRow(ctx, getProjectGuideline, projectID)
+	var i ProjectGuideline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Status,
+		&i.Error,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectMethodology = `-- name: GetProjectMethodology :one
+SELECT project_id, answers, updated_by, updated_at FROM project_methodology
+WHERE project_id = $1
+`
+
+func (q *Queries) GetProjectMethodology(ctx context.Context, projectID pgtype.UUID) (ProjectMethodology, error) {
+	row := q.db.QueryRow(ctx, getProjectMethodology, projectID)
+	var i ProjectMethodology
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Answers,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectReference = `-- name: GetProjectReference :one
+SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at FROM "references"
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL LIMIT 1
+`
+
+type GetProjectReferenceParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetProjectReference(ctx context.Context, arg GetProjectReferenceParams) (Reference, error) {
+	row := q.db.QueryRow(ctx, getProjectReference, arg.ID, arg.ProjectID)
+	var i Reference
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Authors,
+		&i.Journal,
+		&i.PublicationYear,
+		&i.Doi,
+		&i.Url,
+		&i.CitationApa,
+		&i.CitationMla,
+		&i.CreatedAt,
+		&i.Abstract,
+		&i.SearchVector,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getProjectReferencesByIDs = `-- name: GetProjectReferencesByIDs :many
+SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at FROM "references"
+WHERE project_id = $1 AND id = ANY($2::uuid[])
+ORDER BY created_at
+`
+
+type GetProjectReferencesByIDsParams struct {
+	ProjectID pgtype.UUID   `db:"project_id" json:"project_id"`
+	Ids       []pgtype.UUID `db:"ids" json:"ids"`
+}
+
+// Including references in the trash, which chapters may still cite
+func (q *Queries) GetProjectReferencesByIDs(ctx context.Context, arg GetProjectReferencesByIDsParams) ([]Reference, error) {
+	rows, err := q.db.Query(ctx, getProjectReferencesByIDs, arg.ProjectID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reference{}
+	for rows.Next() {
+		var i Reference
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Authors,
+			&i.Journal,
+			&i.PublicationYear,
+			&i.Doi,
+			&i.Url,
+			&i.CitationApa,
+			&i.CitationMla,
+			&i.CreatedAt,
+			&i.Abstract,
+			&i.SearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProjectTimeline = `-- name: GetProjectTimeline :one
+SELECT id, project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by, created_at, updated_at FROM project_timelines
+WHERE project_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetProjectTimeline(ctx context.Context, projectID pgtype.UUID) (ProjectTimeline, error) {
+	row := q.db.QueryRow(ctx, getProjectTimeline, projectID)
+	var i ProjectTimeline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Milestones,
+		&i.Tasks,
+		&i.IncludeInDocument,
+		&i.GeneratedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectUpload = `-- name: GetProjectUpload :one
+SELECT id, project_id, uploaded_by, file_name, file_path, file_size, sha256, extraction_status, extraction_error, page_count, created_at, updated_at FROM project_uploads
+WHERE id = $1 AND project_id = $2
+`
+
+type GetProjectUploadParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetProjectUpload(ctx context.Context, arg GetProjectUploadParams) (ProjectUpload, error) {
+	row := q.db.QueryRow(ctx, getProjectUpload, arg.ID, arg.ProjectID)
+	var i ProjectUpload
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.Sha256,
+		&i.ExtractionStatus,
+		&i.ExtractionError,
+		&i.PageCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectWithAccess = `-- name: GetProjectWithAccess :one
+SELECT research_projects.id, research_projects.user_id, research_projects.title, research_projects.specialization, research_projects.university, research_projects.description, research_projects.status, research_projects.created_at, research_projects.updated_at, research_projects.organization_id, research_projects.advisor_id, research_projects.language, project_access_role(research_projects.id, $1)::text AS access_role
+FROM research_projects
+WHERE research_projects.id = $2 AND project_access_role(research_projects.id, $1) IS NOT NULL
+LIMIT 1
+`
+
+type GetProjectWithAccessParams struct {
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+	ID     pgtype.UUID `db:"id" json:"id"`
+}
+
+type GetProjectWithAccessRow struct {
+	ResearchProject ResearchProject `db:"research_project" json:"research_project"`
+	AccessRole      string          `db:"access_role" json:"access_role"`
+}
+
+func (q *Queries) GetProjectWithAccess(ctx context.Context, arg GetProjectWithAccessParams) (GetProjectWithAccessRow, error) {
+	row := q.db.QueryRow(ctx, getProjectWithAccess, arg.UserID, arg.ID)
+	var i GetProjectWithAccessRow
+	err := row.Scan(
+		&i.ResearchProject.ID,
+		&i.ResearchProject.UserID,
+		&i.ResearchProject.Title,
+		&i.ResearchProject.Specialization,
+		&i.ResearchProject.University,
+		&i.ResearchProject.Description,
+		&i.ResearchProject.Status,
+		&i.ResearchProject.CreatedAt,
+		&i.ResearchProject.UpdatedAt,
+		&i.ResearchProject.OrganizationID,
+		&i.ResearchProject.AdvisorID,
+		&i.ResearchProject.Language,
+		&i.AccessRole,
+	)
+	return i, err
+}
+
+const getQuestionnaire = `-- name: GetQuestionnaire :one
+SELECT id, project_id, title, instructions, sections, include_in_document, created_by, created_at, updated_at FROM questionnaires
+WHERE id = $1 AND project_id = $2 LIMIT 1
+`
+
+type GetQuestionnaireParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetQuestionnaire(ctx context.Context, arg GetQuestionnaireParams) (Questionnaire, error) {
+	row := q.db.QueryRow(ctx, getQuestionnaire, arg.ID, arg.ProjectID)
+	var i Questionnaire
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Instructions,
+		&i.Sections,
+		&i.IncludeInDocument,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReferenceNotes = `-- name: GetReferenceNotes :one
+SELECT reference_id, project_id, notes, updated_by, created_at, updated_at FROM reference_notes
+WHERE reference_id = $1
+`
+
+func (q *Queries) GetReferenceNotes(ctx context.Context, referenceID pgtype.UUID) (ReferenceNote, error) {
+	row := q.db.QueryRow(ctx, getReferenceNotes, referenceID)
+	var i ReferenceNote
+	err := row.Scan(
+		&i.ReferenceID,
+		&i.ProjectID,
+		&i.Notes,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReferencePaper = `-- name: GetReferencePaper :one
+SELECT reference_id, project_id, paper_id, open_access_pdf_url, created_at, pdf_status, pdf_error, pdf_path, pdf_size FROM reference_papers
+WHERE reference_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetReferencePaper(ctx context.Context, referenceID pgtype.UUID) (ReferencePaper, error) {
+	row := q.db.QueryRow(ctx, getReferencePaper, referenceID)
+	var i ReferencePaper
+	err := row.Scan(
+		&i.ReferenceID,
+		&i.ProjectID,
+		&i.PaperID,
+		&i.OpenAccessPdfUrl,
+		&i.CreatedAt,
+		&i.PdfStatus,
+		&i.PdfError,
+		&i.PdfPath,
+		&i.PdfSize,
+	)
+	return i, err
+}
+
+const getReferencesByProjectID = `-- name: GetReferencesByProjectID :many
+SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at FROM "references" -- Quoted
+WHERE project_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetReferencesByProjectID(ctx context.Context, projectID pgtype.UUID) ([]Reference, error) {
+	rows, err := q.db.Query(ctx, getReferencesByProjectID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reference{}
+	for rows.Next() {
+		var i Reference
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Authors,
+			&i.Journal,
+			&i.PublicationYear,
+			&i.Doi,
+			&i.Url,
+			&i.CitationApa,
+			&i.CitationMla,
+			&i.CreatedAt,
+			&i.Abstract,
+			&i.SearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReferencesByTags = `-- name: GetReferencesByTags :many
+SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at FROM "references"
+WHERE project_id = $1 AND deleted_at IS NULL
+  AND id IN (SELECT reference_id FROM reference_tags WHERE tag = ANY($2::text[]))
+ORDER BY created_at DESC
+`
+
+type GetReferencesByTagsParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Tags      []string    `db:"tags" json:"tags"`
+}
+
+// References in use carrying any of the tags
+func (q *Queries) GetReferencesByTags(ctx context.Context, arg GetReferencesByTagsParams) ([]Reference, error) {
+	rows, err := q.db.Query(ctx, getReferencesByTags, arg.ProjectID, arg.Tags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reference{}
+	for rows.Next() {
+		var i Reference
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Authors,
+			&i.Journal,
+			&i.PublicationYear,
+			&i.Doi,
+			&i.Url,
+			&i.CitationApa,
+			&i.CitationMla,
+			&i.CreatedAt,
+			&i.Abstract,
+			&i.SearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReferencesForEmbedding = `-- name: GetReferencesForEmbedding :many
+SELECT id, title, abstract FROM "references"
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+`
+
+type GetReferencesForEmbeddingRow struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	Title    string      `db:"title" json:"title"`
+	Abstract pgtype.Text `db:"abstract" json:"abstract"`
+}
+
+func (q *Queries) GetReferencesForEmbedding(ctx context.Context, ids []pgtype.UUID) ([]GetReferencesForEmbeddingRow, error) {
+	rows, err := q.db.Query(ctx, getReferencesForEmbedding, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetReferencesForEmbeddingRow{}
+	for rows.Next() {
+		var i GetReferencesForEmbeddingRow
+		if err := rows.Scan(&i.ID, &i.Title, &i.Abstract); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionByRefreshToken = `-- name: GetSessionByRefreshToken :one
+SELECT id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE refresh_token = $1 LIMIT 1
+`
+
+func (q *Queries) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (Session, error) {
+	row := q.db.QueryRow(ctx, getSessionByRefreshToken, refreshToken)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSubscription = `-- name: GetSubscription :one
+SELECT user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end, cancel_at_period_end, created_at, updated_at FROM subscriptions
+WHERE user_id = $1
+`
+
+func (q *Queries) GetSubscription(ctx context.Context, userID pgtype.UUID) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscription, userID)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.StripeCustomerID,
+		&i.StripeSubscriptionID,
+		&i.Plan,
+		&i.Status,
+		&i.CurrentPeriodEnd,
+		&i.CancelAtPeriodEnd,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUpload = `-- name: GetUpload :one
+SELECT id, project_id, uploaded_by, file_name, file_path, file_size, sha256, extraction_status, extraction_error, page_count, created_at, updated_at FROM project_uploads
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUpload(ctx context.Context, id pgtype.UUID) (ProjectUpload, error) {
+	row := q.db.QueryRow(ctx, getUpload, id)
+	var i ProjectUpload
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UploadedBy,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.Sha256,
+		&i.ExtractionStatus,
+		&i.ExtractionError,
+		&i.PageCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUploadSession = `-- name: GetUploadSession :one
+SELECT id, project_id, user_id, file_name, total_size, received_size, sha256, status, error, upload_id, expires_at, created_at, updated_at FROM upload_sessions
+WHERE id = $1 AND project_id = $2 LIMIT 1
+`
+
+type GetUploadSessionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetUploadSession(ctx context.Context, arg GetUploadSessionParams) (UploadSession, error) {
+	row := q.db.QueryRow(ctx, getUploadSession, arg.ID, arg.ProjectID)
+	var i UploadSession
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.FileName,
+		&i.TotalSize,
+		&i.ReceivedSize,
+		&i.Sha256,
+		&i.Status,
+		&i.Error,
+		&i.UploadID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUploadSessionByID = `-- name: GetUploadSessionByID :one
+SELECT id, project_id, user_id, file_name, total_size, received_size, sha256, status, error, upload_id, expires_at, created_at, updated_at FROM upload_sessions
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUploadSessionByID(ctx context.Context, id pgtype.UUID) (UploadSession, error) {
+	row := q.db.QueryRow(ctx, getUploadSessionByID, id)
+	var i UploadSession
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.FileName,
+		&i.TotalSize,
+		&i.ReceivedSize,
+		&i.Sha256,
+		&i.Status,
+		&i.Error,
+		&i.UploadID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUploadText = `-- name: GetUploadText :one
+SELECT content FROM project_upload_texts
+WHERE upload_id = $1
+`
+
+func (q *Queries) GetUploadText(ctx context.Context, uploadID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUploadText, uploadID)
+	var content string
+	err := row.Scan(&content)
+	return content, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.IsVerified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GuestExpiresAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.IsVerified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GuestExpiresAt,
+	)
+	return i, err
+}
+
+const getUserGenerationPreferences = `-- name: GetUserGenerationPreferences :one
+SELECT preferences FROM user_generation_preferences
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserGenerationPreferences(ctx context.Context, userID pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getUserGenerationPreferences, userID)
+	var preferences []byte
+	err := row.Scan(&preferences)
+	return preferences, err
+}
+
+const getUserResearchProjects = `-- name: GetUserResearchProjects :many
+
+SELECT id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language FROM research_projects
+WHERE research_projects.user_id = $1
+    OR organization_id IN (SELECT organization_id FROM organization_members WHERE organization_members.user_id = $1)
+    OR id IN (SELECT project_id FROM project_collaborators WHERE project_collaborators.user_id = $1)
+    OR advisor_id = $1
+ORDER BY created_at DESC
+`
+
+// Projects are accessible to their owner, to members of the organization they
+// belong to and to collaborators; project_access_role (migration 000010) decides the role
+func (q *Queries) GetUserResearchProjects(ctx context.Context, userID pgtype.UUID) ([]ResearchProject, error) {
+	rows, err := q.db.Query(ctx, getUserResearchProjects, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ResearchProject{}
+	for rows.Next() {
+		var i ResearchProject
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Specialization,
+			&i.University,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.AdvisorID,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhook = `-- name: GetWebhook :one
+SELECT id, project_id, url, secret, events, active, created_by, created_at, updated_at FROM webhooks
+WHERE id = $1 AND project_id = $2
+`
+
+type GetWebhookParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, arg GetWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, arg.ID, arg.ProjectID)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWebhookDelivery = `-- name: GetWebhookDelivery :one
+SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, duration_ms, delivered_at, created_at FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2
+`
+
+type GetWebhookDeliveryParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	WebhookID pgtype.UUID `db:"webhook_id" json:"webhook_id"`
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, getWebhookDelivery, arg.ID, arg.WebhookID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DurationMs,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listActiveWebhooksForEvent = `-- name: ListActiveWebhooksForEvent :many
+SELECT id, project_id, url, secret, events, active, created_by, created_at, updated_at FROM webhooks
+WHERE project_id = $1 AND active
+    AND (cardinality(events) = 0 OR $2::text = ANY(events))
+`
+
+type ListActiveWebhooksForEventParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Event     string      `db:"event" json:"event"`
+}
+
+func (q *Queries) ListActiveWebhooksForEvent(ctx context.Context, arg ListActiveWebhooksForEventParams) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, listActiveWebhooksForEvent, arg.ProjectID, arg.Event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Webhook{}
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterCitations = `-- name: ListChapterCitations :many
+SELECT id, chapter_id, project_id, reference_id, marker, author, year, position, chapter_version, created_at FROM chapter_citations
+WHERE chapter_id = $1
+ORDER BY position
+`
+
+func (q *Queries) ListChapterCitations(ctx context.Context, chapterID pgtype.UUID) ([]ChapterCitation, error) {
+	rows, err := q.db.Query(ctx, listChapterCitations, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterCitation{}
+	for rows.Next() {
+		var i ChapterCitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChapterID,
+			&i.ProjectID,
+			&i.ReferenceID,
+			&i.Marker,
+			&i.Author,
+			&i.Year,
+			&i.Position,
+			&i.ChapterVersion,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterCommentThreads = `-- name: ListChapterCommentThreads :many
+SELECT id, project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by, resolved_at, resolved_by, created_at, updated_at FROM comment_threads
+WHERE chapter_id = $1
+    AND ($2::boolean IS NULL OR (resolved_at IS NOT NULL) = $2::boolean)
+ORDER BY anchor_start NULLS LAST, created_at
+`
+
+type ListChapterCommentThreadsParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Resolved  pgtype.Bool `db:"resolved" json:"resolved"`
+}
+
+func (q *Queries) ListChapterCommentThreads(ctx context.Context, arg ListChapterCommentThreadsParams) ([]CommentThread, error) {
+	rows, err := q.db.Query(ctx, listChapterCommentThreads, arg.ChapterID, arg.Resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CommentThread{}
+	for rows.Next() {
+		var i CommentThread
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.AnchorStart,
+			&i.AnchorEnd,
+			&i.AnchorHeading,
+			&i.QuotedText,
+			&i.CreatedBy,
+			&i.ResolvedAt,
+			&i.ResolvedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterComments = `-- name: ListChapterComments :many
+SELECT comments.id, comments.thread_id, comments.user_id, comments.body, comments.created_at, users.first_name, users.last_name
+FROM comments
+JOIN comment_threads ON comment_threads.id = comments.thread_id
+LEFT JOIN users ON users.id = comments.user_id
+WHERE comment_threads.chapter_id = $1
+ORDER BY comments.created_at
+`
+
+type ListChapterCommentsRow struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	ThreadID  pgtype.UUID        `db:"thread_id" json:"thread_id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Body      string             `db:"body" json:"body"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	FirstName pgtype.Text        `db:"first_name" json:"first_name"`
+	LastName  pgtype.Text        `db:"last_name" json:"last_name"`
+}
+
+func (q *Queries) ListChapterComments(ctx context.Context, chapterID pgtype.UUID) ([]ListChapterCommentsRow, error) {
+	rows, err := q.db.Query(ctx, listChapterComments, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListChapterCommentsRow{}
+	for rows.Next() {
+		var i ListChapterCommentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ThreadID,
+			&i.UserID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterEvaluations = `-- name: ListChapterEvaluations :many
+SELECT id, project_id, chapter_id, chapter_version, rubric, criteria, summary, evaluated_by, created_at FROM chapter_evaluations
+WHERE chapter_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListChapterEvaluations(ctx context.Context, chapterID pgtype.UUID) ([]ChapterEvaluation, error) {
+	rows, err := q.db.Query(ctx, listChapterEvaluations, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterEvaluation{}
+	for rows.Next() {
+		var i ChapterEvaluation
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.ChapterVersion,
+			&i.Rubric,
+			&i.Criteria,
+			&i.Summary,
+			&i.EvaluatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterFigures = `-- name: ListChapterFigures :many
+SELECT id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at FROM chapter_figures
+WHERE chapter_id = $1
+ORDER BY position, created_at
+`
+
+func (q *Queries) ListChapterFigures(ctx context.Context, chapterID pgtype.UUID) ([]ChapterFigure, error) {
+	rows, err := q.db.Query(ctx, listChapterFigures, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterFigure{}
+	for rows.Next() {
+		var i ChapterFigure
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.Kind,
+			&i.Caption,
+			&i.Position,
+			&i.FilePath,
+			&i.MimeType,
+			&i.TableData,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterGenerations = `-- name: ListChapterGenerations :many
+SELECT id, user_id, project_id, chapter_id, kind, model, temperature, max_tokens, prompt_hash, prompt, response, prompt_tokens, completion_tokens, latency_ms, outcome, error, created_at FROM generations
+WHERE chapter_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListChapterGenerationsParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Limit     int32       `db:"limit" json:"limit"`
+}
+
+// Newest first
+func (q *Queries) ListChapterGenerations(ctx context.Context, arg ListChapterGenerationsParams) ([]Generation, error) {
+	rows, err := q.db.Query(ctx, listChapterGenerations, arg.ChapterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Generation{}
+	for rows.Next() {
+		var i Generation
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.Kind,
+			&i.Model,
+			&i.Temperature,
+			&i.MaxTokens,
+			&i.PromptHash,
+			&i.Prompt,
+			&i.Response,
+			&i.PromptTokens,
+			&i.CompletionTokens,
+			&i.LatencyMs,
+			&i.Outcome,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterReviews = `-- name: ListChapterReviews :many
+SELECT id, project_id, chapter_id, chapter_version, status, submitted_by, submission_note, reviewed_by, decision_note, submitted_at, decided_at FROM chapter_reviews
+WHERE chapter_id = $1
+ORDER BY submitted_at DESC
+`
+
+func (q *Queries) ListChapterReviews(ctx context.Context, chapterID pgtype.UUID) ([]ChapterReview, error) {
+	rows, err := q.db.Query(ctx, listChapterReviews, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterReview{}
+	for rows.Next() {
+		var i ChapterReview
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.ChapterVersion,
+			&i.Status,
+			&i.SubmittedBy,
+			&i.SubmissionNote,
+			&i.ReviewedBy,
+			&i.DecisionNote,
+			&i.SubmittedAt,
+			&i.DecidedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterSections = `-- name: ListChapterSections :many
+SELECT id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at FROM chapter_sections
+WHERE chapter_id = $1
+ORDER BY position, created_at
+`
+
+func (q *Queries) ListChapterSections(ctx context.Context, chapterID pgtype.UUID) ([]ChapterSection, error) {
+	rows, err := q.db.Query(ctx, listChapterSections, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterSection{}
+	for rows.Next() {
+		var i ChapterSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChapterID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Level,
+			&i.Position,
+			&i.Content,
+			&i.WordCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.GeneratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterTypes = `-- name: ListChapterTypes :many
+SELECT id, organization_id, key, label, description, position, created_by, created_at, updated_at, project_id FROM chapter_types
+WHERE (organization_id IS NULL AND project_id IS NULL) OR organization_id = $1 OR project_id = $2
+ORDER BY position, organization_id IS NOT NULL, project_id IS NOT NULL, key
+`
+
+type ListChapterTypesParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	ProjectID      pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+// The built-in chapter types, then the organization's and the project's own; NULL
+// leaves either out
+func (q *Queries) ListChapterTypes(ctx context.Context, arg ListChapterTypesParams) ([]ChapterType, error) {
+	rows, err := q.db.Query(ctx, listChapterTypes, arg.OrganizationID, arg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterType{}
+	for rows.Next() {
+		var i ChapterType
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Key,
+			&i.Label,
+			&i.Description,
+			&i.Position,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ProjectID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChapterVersions = `-- name: ListChapterVersions :many
+SELECT version, title, word_count, status, created_at FROM chapter_versions
+WHERE chapter_id = $1
+ORDER BY version DESC
+`
+
+type ListChapterVersionsRow struct {
+	Version   int32              `db:"version" json:"version"`
+	Title     string             `db:"title" json:"title"`
+	WordCount int32              `db:"word_count" json:"word_count"`
+	Status    string             `db:"status" json:"status"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+// Newest first, without content
+func (q *Queries) ListChapterVersions(ctx context.Context, chapterID pgtype.UUID) ([]ListChapterVersionsRow, error) {
+	rows, err := q.db.Query(ctx, listChapterVersions, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListChapterVersionsRow{}
+	for rows.Next() {
+		var i ListChapterVersionsRow
+		if err := rows.Scan(
+			&i.Version,
+			&i.Title,
+			&i.WordCount,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommentThreadParticipants = `-- name: ListCommentThreadParticipants :many
+SELECT DISTINCT user_id FROM comments
+WHERE thread_id = $1 AND user_id IS NOT NULL
+`
+
+func (q *Queries) ListCommentThreadParticipants(ctx context.Context, threadID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listCommentThreadParticipants, threadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var user_id pgtype.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredGuestFiles = `-- name: ListExpiredGuestFiles :many
+WITH guest_projects AS (
+    SELECT p.id FROM research_projects p
+    JOIN users u ON u.id = p.user_id
+    WHERE u.guest_expires_at < $1
+)
+SELECT file_path AS file_ref FROM project_uploads WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT file_path FROM project_guidelines WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT file_path FROM chapter_figures WHERE project_id IN (SELECT id FROM guest_projects) AND file_path <> ''
+UNION ALL
+SELECT file_path FROM project_appendices WHERE project_id IN (SELECT id FROM guest_projects) AND file_path <> ''
+UNION ALL
+SELECT pdf_path FROM reference_papers WHERE project_id IN (SELECT id FROM guest_projects) AND pdf_path <> ''
+UNION ALL
+SELECT COALESCE(storage_key, file_path) FROM generated_documents WHERE project_id IN (SELECT id FROM guest_projects)
+UNION ALL
+SELECT c.storage_key FROM upload_session_chunks c
+JOIN upload_sessions s ON s.id = c.session_id
+WHERE s.project_id IN (SELECT id FROM guest_projects)
+`
+
+// Stored files of the projects of expired guests, which DeleteExpiredGuests
+// removes along with the guests
+func (q *Queries) ListExpiredGuestFiles(ctx context.Context, guestExpiresAt pgtype.Timestamptz) ([]string, error) {
+	rows, err := q.db.Query(ctx, listExpiredGuestFiles, guestExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var fileRef string
+		if err := rows.Scan(&fileRef); err != nil {
+			return nil, err
+		}
+		items = append(items, fileRef)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredUploadSessionChunks = `-- name: ListExpiredUploadSessionChunks :many
+SELECT c.storage_key FROM upload_session_chunks c
+JOIN upload_sessions s ON s.id = c.session_id
+WHERE s.expires_at < $1 AND s.status <> 'assembling'
+`
+
+// Chunk objects of the sessions DeleteExpiredUploadSessions removes
+func (q *Queries) ListExpiredUploadSessionChunks(ctx context.Context, expiresAt pgtype.Timestamptz) ([]string, error) {
+	rows, err := q.db.Query(ctx, listExpiredUploadSessionChunks, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var storageKey string
+		if err := rows.Scan(&storageKey); err != nil {
+			return nil, err
+		}
+		items = append(items, storageKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeatureFlags = `-- name: ListFeatureFlags :many
+SELECT name, enabled, updated_at FROM feature_flags
+ORDER BY name
+`
+
+func (q *Queries) ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error) {
+	rows, err := q.db.Query(ctx, listFeatureFlags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeatureFlag{}
+	for rows.Next() {
+		var i FeatureFlag
+		if err := rows.Scan(&i.Name, &i.Enabled, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitationsForEmail = `-- name: ListInvitationsForEmail :many
+SELECT organization_invitations.id, organization_invitations.organization_id, organization_invitations.email, organization_invitations.role, organization_invitations.invited_by, organization_invitations.expires_at, organization_invitations.created_at, organization_invitations.token_hash, organizations.name AS organization_name
+FROM organization_invitations
+JOIN organizations ON organizations.id = organization_invitations.organization_id
+WHERE lower(organization_invitations.email) = lower($1::text) AND organization_invitations.expires_at > NOW()
+ORDER BY organization_invitations.created_at DESC
+`
+
+type ListInvitationsForEmailRow struct {
+	ID               pgtype.UUID        `db:"id" json:"id"`
+	OrganizationID   pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	Email            string             `db:"email" json:"email"`
+	Role             string             `db:"role" json:"role"`
+	InvitedBy        pgtype.UUID        `db:"invited_by" json:"invited_by"`
+	ExpiresAt        pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	TokenHash        pgtype.Text        `db:"token_hash" json:"token_hash"`
+	OrganizationName string             `db:"organization_name" json:"organization_name"`
+}
+
+func (q *Queries) ListInvitationsForEmail(ctx context.Context, email string) ([]ListInvitationsForEmailRow, error) {
+	rows, err := q.db.Query(ctx, listInvitationsForEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInvitationsForEmailRow{}
+	for rows.Next() {
+		var i ListInvitationsForEmailRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Email,
+			&i.Role,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.TokenHash,
+			&i.OrganizationName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobs = `-- name: ListJobs :many
+SELECT id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, completed_at, created_at, updated_at FROM jobs
+WHERE ($1::text IS NULL OR status = $1::text)
+    AND ($2::text IS NULL OR kind = $2::text)
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type ListJobsParams struct {
+	Status   pgtype.Text `db:"status" json:"status"`
+	Kind     pgtype.Text `db:"kind" json:"kind"`
+	RowLimit int32       `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListJobs(ctx context.Context, arg ListJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, listJobs, arg.Status, arg.Kind, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.UniqueKey,
+			&i.LastError,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationPreferences = `-- name: ListNotificationPreferences :many
+SELECT user_id, type, in_app, email, updated_at FROM notification_preferences
+WHERE user_id = $1
+`
+
+func (q *Queries) ListNotificationPreferences(ctx context.Context, userID pgtype.UUID) ([]NotificationPreference, error) {
+	rows, err := q.db.Query(ctx, listNotificationPreferences, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NotificationPreference{}
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Type,
+			&i.InApp,
+			&i.Email,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationInvitations = `-- name: ListOrganizationInvitations :many
+SELECT id, organization_id, email, role, invited_by, expires_at, created_at, token_hash FROM organization_invitations
+WHERE organization_id = $1 AND expires_at > NOW()
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListOrganizationInvitations(ctx context.Context, organizationID pgtype.UUID) ([]OrganizationInvitation, error) {
+	rows, err := q.db.Query(ctx, listOrganizationInvitations, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrganizationInvitation{}
+	for rows.Next() {
+		var i OrganizationInvitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Email,
+			&i.Role,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.TokenHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationMembers = `-- name: ListOrganizationMembers :many
+SELECT organization_members.organization_id, organization_members.user_id, organization_members.role, organization_members.created_at, users.email, users.first_name, users.last_name
+FROM organization_members
+JOIN users ON users.id = organization_members.user_id
+WHERE organization_members.organization_id = $1
+ORDER BY organization_members.created_at
+`
+
+type ListOrganizationMembersRow struct {
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	Role           string             `db:"role" json:"role"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Email          string             `db:"email" json:"email"`
+	FirstName      string             `db:"first_name" json:"first_name"`
+	LastName       string             `db:"last_name" json:"last_name"`
+}
+
+func (q *Queries) ListOrganizationMembers(ctx context.Context, organizationID pgtype.UUID) ([]ListOrganizationMembersRow, error) {
+	rows, err := q.db.Query(ctx, listOrganizationMembers, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrganizationMembersRow{}
+	for rows.Next() {
+		var i ListOrganizationMembersRow
+		if err := rows.Scan(
+			&i.OrganizationID,
+			&i.UserID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationProjects = `-- name: ListOrganizationProjects :many
+SELECT id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language FROM research_projects
+WHERE organization_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListOrganizationProjects(ctx context.Context, organizationID pgtype.UUID) ([]ResearchProject, error) {
+	rows, err := q.db.Query(ctx, listOrganizationProjects, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ResearchProject{}
+	for rows.Next() {
+		var i ResearchProject
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Specialization,
+			&i.University,
+			&i.Description,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.AdvisorID,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectAnnotatedReferences = `-- name: ListProjectAnnotatedReferences :many
+SELECT r.id, r.title, r.authors, r.publication_year, COALESCE(n.notes, '') AS notes
+FROM "references" r
+LEFT JOIN reference_notes n ON n.reference_id = r.id
+WHERE r.project_id = $1 AND r.deleted_at IS NULL
+  AND (COALESCE(n.notes, '') <> '' OR EXISTS (SELECT 1 FROM reference_highlights h WHERE h.reference_id = r.id))
+ORDER BY r.created_at
+`
+
+type ListProjectAnnotatedReferencesRow struct {
+	ID              pgtype.UUID `db:"id" json:"id"`
+	Title           string      `db:"title" json:"title"`
+	Authors         pgtype.Text `db:"authors" json:"authors"`
+	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
+	Notes           string      `db:"notes" json:"notes"`
+}
+
+// References in use with notes or highlights, for literature review generation
+func (q *Queries) ListProjectAnnotatedReferences(ctx context.Context, projectID pgtype.UUID) ([]ListProjectAnnotatedReferencesRow, error) {
+	rows, err := q.db.Query(ctx, listProjectAnnotatedReferences, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectAnnotatedReferencesRow{}
+	for rows.Next() {
+		var i ListProjectAnnotatedReferencesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Authors,
+			&i.PublicationYear,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectAppendices = `-- name: ListProjectAppendices :many
+SELECT id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at FROM project_appendices
+WHERE project_id = $1
+ORDER BY position, created_at
+`
+
+// In lettering order
+func (q *Queries) ListProjectAppendices(ctx context.Context, projectID pgtype.UUID) ([]ProjectAppendix, error) {
+	rows, err := q.db.Query(ctx, listProjectAppendices, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectAppendix{}
+	for rows.Next() {
+		var i ProjectAppendix
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Content,
+			&i.Position,
+			&i.FilePath,
+			&i.MimeType,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectCollaborators = `-- name: ListProjectCollaborators :many
+SELECT project_collaborators.project_id, project_collaborators.user_id, project_collaborators.role, project_collaborators.added_by, project_collaborators.created_at, project_collaborators.updated_at, users.email, users.first_name, users.last_name
+FROM project_collaborators
+JOIN users ON users.id = project_collaborators.user_id
+WHERE project_collaborators.project_id = $1
+ORDER BY project_collaborators.created_at
+`
+
+type ListProjectCollaboratorsRow struct {
+	ProjectID pgtype.UUID        `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	Role      string             `db:"role" json:"role"`
+	AddedBy   pgtype.UUID        `db:"added_by" json:"added_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	Email     string             `db:"email" json:"email"`
+	FirstName string             `db:"first_name" json:"first_name"`
+	LastName  string             `db:"last_name" json:"last_name"`
+}
+
+func (q *Queries) ListProjectCollaborators(ctx context.Context, projectID pgtype.UUID) ([]ListProjectCollaboratorsRow, error) {
+	rows, err := q.db.Query(ctx, listProjectCollaborators, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectCollaboratorsRow{}
+	for rows.Next() {
+		var i ListProjectCollaboratorsRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Role,
+			&i.AddedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectDatasets = `-- name: ListProjectDatasets :many
+SELECT id, project_id, uploaded_by, file_name, row_count, columns, table_ids, created_at, updated_at FROM project_datasets
+WHERE project_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListProjectDatasets(ctx context.Context, projectID pgtype.UUID) ([]ProjectDataset, error) {
+	rows, err := q.db.Query(ctx, listProjectDatasets, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectDataset{}
+	for rows.Next() {
+		var i ProjectDataset
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UploadedBy,
+			&i.FileName,
+			&i.RowCount,
+			&i.Columns,
+			&i.TableIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectFigures = `-- name: ListProjectFigures :many
+SELECT id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at FROM chapter_figures
+WHERE project_id = $1
+ORDER BY chapter_id, position, created_at
+`
+
+// Figures and tables of all a project's chapters, in numbering order within each chapter
+func (q *Queries) ListProjectFigures(ctx context.Context, projectID pgtype.UUID) ([]ChapterFigure, error) {
+	rows, err := q.db.Query(ctx, listProjectFigures, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterFigure{}
+	for rows.Next() {
+		var i ChapterFigure
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.Kind,
+			&i.Caption,
+			&i.Position,
+			&i.FilePath,
+			&i.MimeType,
+			&i.TableData,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectPaperIDs = `-- name: ListProjectPaperIDs :many
+SELECT paper_id FROM reference_papers
+WHERE project_id = $1
+`
+
+// Papers already imported, including references in the trash
+func (q *Queries) ListProjectPaperIDs(ctx context.Context, projectID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, listProjectPaperIDs, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var paperID string
+		if err := rows.Scan(&paperID); err != nil {
+			return nil, err
+		}
+		items = append(items, paperID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectPaperSeeds = `-- name: ListProjectPaperSeeds :many
+SELECT rp.paper_id, r.doi FROM "references" r
+LEFT JOIN reference_papers rp ON rp.reference_id = r.id
+WHERE r.project_id = $1 AND r.deleted_at IS NULL AND (rp.paper_id IS NOT NULL OR r.doi IS NOT NULL)
+ORDER BY r.created_at DESC
+`
+
+type ListProjectPaperSeedsRow struct {
+	PaperID pgtype.Text `db:"paper_id" json:"paper_id"`
+	Doi     pgtype.Text `db:"doi" json:"doi"`
+}
+
+// The project's references that Semantic Scholar can identify, newest first
+func (q *Queries) ListProjectPaperSeeds(ctx context.Context, projectID pgtype.UUID) ([]ListProjectPaperSeedsRow, error) {
+	rows, err := q.db.Query(ctx, listProjectPaperSeeds, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectPaperSeedsRow{}
+	for rows.Next() {
+		var i ListProjectPaperSeedsRow
+		if err := rows.Scan(
+			&i.PaperID,
+			&i.Doi,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectQuestionnaires = `-- name: ListProjectQuestionnaires :many
+SELECT id, project_id, title, instructions, sections, include_in_document, created_by, created_at, updated_at FROM questionnaires
+WHERE project_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListProjectQuestionnaires(ctx context.Context, projectID pgtype.UUID) ([]Questionnaire, error) {
+	rows, err := q.db.Query(ctx, listProjectQuestionnaires, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Questionnaire{}
+	for rows.Next() {
+		var i Questionnaire
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Instructions,
+			&i.Sections,
+			&i.IncludeInDocument,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectReferenceHighlights = `-- name: ListProjectReferenceHighlights :many
+SELECT id, reference_id, project_id, text, comment, page, created_by, created_at FROM reference_highlights
+WHERE project_id = $1
+ORDER BY reference_id, created_at
+`
+
+func (q *Queries) ListProjectReferenceHighlights(ctx context.Context, projectID pgtype.UUID) ([]ReferenceHighlight, error) {
+	rows, err := q.db.Query(ctx, listProjectReferenceHighlights, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReferenceHighlight{}
+	for rows.Next() {
+		var i ReferenceHighlight
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReferenceID,
+			&i.ProjectID,
+			&i.Text,
+			&i.Comment,
+			&i.Page,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectReferenceTags = `-- name: ListProjectReferenceTags :many
+SELECT reference_id, project_id, tag, created_at FROM reference_tags
+WHERE project_id = $1
+ORDER BY reference_id, tag
+`
+
+func (q *Queries) ListProjectReferenceTags(ctx context.Context, projectID pgtype.UUID) ([]ReferenceTag, error) {
+	rows, err := q.db.Query(ctx, listProjectReferenceTags, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReferenceTag{}
+	for rows.Next() {
+		var i ReferenceTag
+		if err := rows.Scan(
+			&i.ReferenceID,
+			&i.ProjectID,
+			&i.Tag,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectSections = `-- name: ListProjectSections :many
+SELECT id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at FROM chapter_sections
+WHERE project_id = $1
+ORDER BY chapter_id, position, created_at
+`
+
+// Every section of the project's chapters, in order within each chapter
+func (q *Queries) ListProjectSections(ctx context.Context, projectID pgtype.UUID) ([]ChapterSection, error) {
+	rows, err := q.db.Query(ctx, listProjectSections, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChapterSection{}
+	for rows.Next() {
+		var i ChapterSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChapterID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Level,
+			&i.Position,
+			&i.Content,
+			&i.WordCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.GeneratedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectSourceTexts = `-- name: ListProjectSourceTexts :many
+SELECT project_uploads.id, project_uploads.file_name, project_upload_texts.content
+FROM project_uploads
+JOIN project_upload_texts ON project_upload_texts.upload_id = project_uploads.id
+WHERE project_uploads.project_id = $1 AND project_uploads.extraction_status = 'completed'
+ORDER BY project_uploads.created_at
+`
+
+type ListProjectSourceTextsRow struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	FileName string      `db:"file_name" json:"file_name"`
+	Content  string      `db:"content" json:"content"`
+}
+
+// Extracted text of a project's uploads, oldest first, for AI generation
+func (q *Queries) ListProjectSourceTexts(ctx context.Context, projectID pgtype.UUID) ([]ListProjectSourceTextsRow, error) {
+	rows, err := q.db.Query(ctx, listProjectSourceTexts, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectSourceTextsRow{}
+	for rows.Next() {
+		var i ListProjectSourceTextsRow
+		if err := rows.Scan(&i.ID, &i.FileName, &i.Content); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectTaggedReferences = `-- name: ListProjectTaggedReferences :many
+SELECT r.id, r.title, r.authors, r.publication_year, r.abstract, COALESCE(n.notes, '') AS notes
+FROM "references" r
+JOIN reference_tags t ON t.reference_id = r.id
+LEFT JOIN reference_notes n ON n.reference_id = r.id
+WHERE r.project_id = $1 AND r.deleted_at IS NULL AND t.tag = $2
+ORDER BY r.publication_year NULLS LAST, r.created_at
+`
+
+type ListProjectTaggedReferencesParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Tag       string      `db:"tag" json:"tag"`
+}
+
+type ListProjectTaggedReferencesRow struct {
+	ID              pgtype.UUID `db:"id" json:"id"`
+	Title           string      `db:"title" json:"title"`
+	Authors         pgtype.Text `db:"authors" json:"authors"`
+	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
+	Abstract        pgtype.Text `db:"abstract" json:"abstract"`
+	Notes           string      `db:"notes" json:"notes"`
+}
+
+// References in use carrying a tag, with any notes, for scoped literature review generation
+func (q *Queries) ListProjectTaggedReferences(ctx context.Context, arg ListProjectTaggedReferencesParams) ([]ListProjectTaggedReferencesRow, error) {
+	rows, err := q.db.Query(ctx, listProjectTaggedReferences, arg.ProjectID, arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListProjectTaggedReferencesRow{}
+	for rows.Next() {
+		var i ListProjectTaggedReferencesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Authors,
+			&i.PublicationYear,
+			&i.Abstract,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectUploads = `-- name: ListProjectUploads :many
+SELECT id, project_id, uploaded_by, file_name, file_path, file_size, sha256, extraction_status, extraction_error, page_count, created_at, updated_at FROM project_uploads
+WHERE project_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListProjectUploads(ctx context.Context, projectID pgtype.UUID) ([]ProjectUpload, error) {
+	rows, err := q.db.Query(ctx, listProjectUploads, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectUpload{}
+	for rows.Next() {
+		var i ProjectUpload
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.UploadedBy,
+			&i.FileName,
+			&i.FilePath,
+			&i.FileSize,
+			&i.Sha256,
+			&i.ExtractionStatus,
+			&i.ExtractionError,
+			&i.PageCount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectWebhooks = `-- name: ListProjectWebhooks :many
+SELECT id, project_id, url, secret, events, active, created_by, created_at, updated_at FROM webhooks
+WHERE project_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListProjectWebhooks(ctx context.Context, projectID pgtype.UUID) ([]Webhook, error) {
+	rows, err := q.db.Query(ctx, listProjectWebhooks, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Webhook{}
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Active,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableReferencePDFs = `-- name: ListPurgeableReferencePDFs :many
+SELECT rp.pdf_path FROM reference_papers rp
+JOIN "references" r ON r.id = rp.reference_id
+WHERE r.deleted_at IS NOT NULL AND r.deleted_at < $1 AND rp.pdf_path <> ''
+`
+
+// Files of the papers PurgeTrashedReferences is about to delete
+func (q *Queries) ListPurgeableReferencePDFs(ctx context.Context, deletedAt pgtype.Timestamptz) ([]string, error) {
+	rows, err := q.db.Query(ctx, listPurgeableReferencePDFs, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var pdfPath string
+		if err := rows.Scan(&pdfPath); err != nil {
+			return nil, err
+		}
+		items = append(items, pdfPath)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuotaUsage = `-- name: ListQuotaUsage :many
+SELECT resource, used FROM quota_usage
+WHERE user_id = $1 AND period_start = $2
+`
+
+type ListQuotaUsageParams struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+}
+
+type ListQuotaUsageRow struct {
+	Resource string `db:"resource" json:"resource"`
+	Used     int64  `db:"used" json:"used"`
+}
+
+func (q *Queries) ListQuotaUsage(ctx context.Context, arg ListQuotaUsageParams) ([]ListQuotaUsageRow, error) {
+	rows, err := q.db.Query(ctx, listQuotaUsage, arg.UserID, arg.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListQuotaUsageRow{}
+	for rows.Next() {
+		var i ListQuotaUsageRow
+		if err := rows.Scan(&i.Resource, &i.Used); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReferenceHighlights = `-- name: ListReferenceHighlights :many
+SELECT id, reference_id, project_id, text, comment, page, created_by, created_at FROM reference_highlights
+WHERE reference_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListReferenceHighlights(ctx context.Context, referenceID pgtype.UUID) ([]ReferenceHighlight, error) {
+	rows, err := q.db.Query(ctx, listReferenceHighlights, referenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReferenceHighlight{}
+	for rows.Next() {
+		var i ReferenceHighlight
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReferenceID,
+			&i.ProjectID,
+			&i.Text,
+			&i.Comment,
+			&i.Page,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReferencesMissingEmbedding = `-- name: ListReferencesMissingEmbedding :many
+SELECT r.id, r.title, r.abstract
+FROM "references" r
+LEFT JOIN reference_embeddings e ON e.reference_id = r.id AND e.model = $1
+WHERE r.deleted_at IS NULL AND e.reference_id IS NULL
+ORDER BY r.created_at
+LIMIT $2
+`
+
+type ListReferencesMissingEmbeddingParams struct {
+	Model       string `db:"model" json:"model"`
+	ResultLimit int32  `db:"result_limit" json:"result_limit"`
+}
+
+type ListReferencesMissingEmbeddingRow struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	Title    string      `db:"title" json:"title"`
+	Abstract pgtype.Text `db:"abstract" json:"abstract"`
+}
+
+// References without an embedding for the current model, oldest first
+func (q *Queries) ListReferencesMissingEmbedding(ctx context.Context, arg ListReferencesMissingEmbeddingParams) ([]ListReferencesMissingEmbeddingRow, error) {
+	rows, err := q.db.Query(ctx, listReferencesMissingEmbedding, arg.Model, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReferencesMissingEmbeddingRow{}
+	for rows.Next() {
+		var i ListReferencesMissingEmbeddingRow
+		if err := rows.Scan(&i.ID, &i.Title, &i.Abstract); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRelevantPaperChunks = `-- name: ListRelevantPaperChunks :many
+SELECT c.reference_id, c.position, c.content, r.title, r.authors, r.publication_year,
+    ts_rank(c.search_vector, websearch_to_tsquery('english', $1::text))::real AS rank
+FROM reference_paper_chunks c
+JOIN "references" r ON r.id = c.reference_id
+WHERE c.project_id = $2 AND r.deleted_at IS NULL AND c.search_vector @@ websearch_to_tsquery('english', $1::text)
+ORDER BY rank DESC, c.reference_id, c.position
+LIMIT $3
+`
+
+type ListRelevantPaperChunksParams struct {
+	Query     string      `db:"query" json:"query"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	MaxChunks int32       `db:"max_chunks" json:"max_chunks"`
+}
+
+type ListRelevantPaperChunksRow struct {
+	ReferenceID     pgtype.UUID `db:"reference_id" json:"reference_id"`
+	Position        int32       `db:"position" json:"position"`
+	Content         string      `db:"content" json:"content"`
+	Title           string      `db:"title" json:"title"`
+	Authors         pgtype.Text `db:"authors" json:"authors"`
+	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
+	Rank            float32     `db:"rank" json:"rank"`
+}
+
+// Full-text passages of the project's imported papers that match the query, best first
+func (q *Queries) ListRelevantPaperChunks(ctx context.Context, arg ListRelevantPaperChunksParams) ([]ListRelevantPaperChunksRow, error) {
+	rows, err := q.db.Query(ctx, listRelevantPaperChunks, arg.Query, arg.ProjectID, arg.MaxChunks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRelevantPaperChunksRow{}
+	for rows.Next() {
+		var i ListRelevantPaperChunksRow
+		if err := rows.Scan(
+			&i.ReferenceID,
+			&i.Position,
+			&i.Content,
+			&i.Title,
+			&i.Authors,
+			&i.PublicationYear,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReviewQueue = `-- name: ListReviewQueue :many
+SELECT chapter_reviews.id, chapter_reviews.project_id, chapter_reviews.chapter_id, chapter_reviews.chapter_version, chapter_reviews.status, chapter_reviews.submitted_by, chapter_reviews.submission_note, chapter_reviews.reviewed_by, chapter_reviews.decision_note, chapter_reviews.submitted_at, chapter_reviews.decided_at, chapters.title AS chapter_title, chapters.type AS chapter_type, research_projects.title AS project_title
+FROM chapter_reviews
+JOIN research_projects ON research_projects.id = chapter_reviews.project_id
+JOIN chapters ON chapters.id = chapter_reviews.chapter_id
+WHERE research_projects.advisor_id = $1 AND chapter_reviews.status = 'pending' AND chapters.deleted_at IS NULL
+ORDER BY chapter_reviews.submitted_at
+`
+
+type ListReviewQueueRow struct {
+	ChapterReview ChapterReview `db:"chapter_review" json:"chapter_review"`
+	ChapterTitle  string        `db:"chapter_title" json:"chapter_title"`
+	ChapterType   string        `db:"chapter_type" json:"chapter_type"`
+	ProjectTitle  string        `db:"project_title" json:"project_title"`
+}
+
+func (q *Queries) ListReviewQueue(ctx context.Context, advisorID pgtype.UUID) ([]ListReviewQueueRow, error) {
+	rows, err := q.db.Query(ctx, listReviewQueue, advisorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReviewQueueRow{}
+	for rows.Next() {
+		var i ListReviewQueueRow
+		if err := rows.Scan(
+			&i.ChapterReview.ID,
+			&i.ChapterReview.ProjectID,
+			&i.ChapterReview.ChapterID,
+			&i.ChapterReview.ChapterVersion,
+			&i.ChapterReview.Status,
+			&i.ChapterReview.SubmittedBy,
+			&i.ChapterReview.SubmissionNote,
+			&i.ChapterReview.ReviewedBy,
+			&i.ChapterReview.DecisionNote,
+			&i.ChapterReview.SubmittedAt,
+			&i.ChapterReview.DecidedAt,
+			&i.ChapterTitle,
+			&i.ChapterType,
+			&i.ProjectTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedChapters = `-- name: ListTrashedChapters :many
+SELECT id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position FROM chapters
+WHERE project_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListTrashedChapters(ctx context.Context, projectID pgtype.UUID) ([]Chapter, error) {
+	rows, err := q.db.Query(ctx, listTrashedChapters, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Chapter{}
+	for rows.Next() {
+		var i Chapter
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Type,
+			&i.Title,
+			&i.Content,
+			&i.WordCount,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SearchVector,
+			&i.Version,
+			&i.DeletedAt,
+			&i.TargetWordCount,
+			&i.CustomPrompt,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedReferences = `-- name: ListTrashedReferences :many
+
+SELECT id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at FROM "references"
+WHERE project_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+// Ensure user owns project for delete if needed, or handled at service layer
+func (q *Queries) ListTrashedReferences(ctx context.Context, projectID pgtype.UUID) ([]Reference, error) {
+	rows, err := q.db.Query(ctx, listTrashedReferences, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reference{}
+	for rows.Next() {
+		var i Reference
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Authors,
+			&i.Journal,
+			&i.PublicationYear,
+			&i.Doi,
+			&i.Url,
+			&i.CitationApa,
+			&i.CitationMla,
+			&i.CreatedAt,
+			&i.Abstract,
+			&i.SearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUploadSessionChunks = `-- name: ListUploadSessionChunks :many
+SELECT session_id, byte_offset, size, storage_key FROM upload_session_chunks
+WHERE session_id = $1
+ORDER BY byte_offset
+`
+
+func (q *Queries) ListUploadSessionChunks(ctx context.Context, sessionID pgtype.UUID) ([]UploadSessionChunk, error) {
+	rows, err := q.db.Query(ctx, listUploadSessionChunks, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UploadSessionChunk{}
+	for rows.Next() {
+		var i UploadSessionChunk
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.ByteOffset,
+			&i.Size,
+			&i.StorageKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsage = `-- name: ListUsage :many
+SELECT resource, used FROM billing_usage
+WHERE user_id = $1 AND period_start = $2
+`
+
+type ListUsageParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+}
+
+type ListUsageRow struct {
+	Resource string `db:"resource" json:"resource"`
+	Used     int32  `db:"used" json:"used"`
+}
+
+func (q *Queries) ListUsage(ctx context.Context, arg ListUsageParams) ([]ListUsageRow, error) {
+	rows, err := q.db.Query(ctx, listUsage, arg.UserID, arg.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsageRow{}
+	for rows.Next() {
+		var i ListUsageRow
+		if err := rows.Scan(&i.Resource, &i.Used); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserNotifications = `-- name: ListUserNotifications :many
+SELECT id, user_id, type, project_id, chapter_id, document_id, title, body, in_app, read_at, email_status, email_attempts, email_sent_at, created_at FROM notifications
+WHERE user_id = $1 AND in_app
+    AND (NOT $2::boolean OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT $3
+`
+
+type ListUserNotificationsParams struct {
+	UserID     pgtype.UUID `db:"user_id" json:"user_id"`
+	UnreadOnly bool        `db:"unread_only" json:"unread_only"`
+	RowLimit   int32       `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListUserNotifications(ctx context.Context, arg ListUserNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, listUserNotifications, arg.UserID, arg.UnreadOnly, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Type,
+			&i.ProjectID,
+			&i.ChapterID,
+			&i.DocumentID,
+			&i.Title,
+			&i.Body,
+			&i.InApp,
+			&i.ReadAt,
+			&i.EmailStatus,
+			&i.EmailAttempts,
+			&i.EmailSentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserOrganizations = `-- name: ListUserOrganizations :many
+SELECT organizations.id, organizations.name, organizations.created_by, organizations.created_at, organizations.updated_at, organization_members.role
+FROM organizations
+JOIN organization_members ON organization_members.organization_id = organizations.id
+WHERE organization_members.user_id = $1
+ORDER BY organizations.name
+`
+
+type ListUserOrganizationsRow struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	Name      string             `db:"name" json:"name"`
+	CreatedBy pgtype.UUID        `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	Role      string             `db:"role" json:"role"`
+}
+
+func (q *Queries) ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]ListUserOrganizationsRow, error) {
+	rows, err := q.db.Query(ctx, listUserOrganizations, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserOrganizationsRow{}
+	for rows.Next() {
+		var i ListUserOrganizationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, duration_ms, delivered_at, created_at FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookID pgtype.UUID `db:"webhook_id" json:"webhook_id"`
+	RowLimit  int32       `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listWebhookDeliveries, arg.WebhookID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WebhookDelivery{}
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.Event,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DurationMs,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND in_app AND read_at IS NULL
+`
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, markAllNotificationsRead, userID)
+	return err
+}
+
+const markNotificationRead = `-- name: MarkNotificationRead :execrows
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2 AND in_app
+`
+
+type MarkNotificationReadParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markNotificationRead, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const mergeReference = `-- name: MergeReference :one
+UPDATE "references"
+SET authors = COALESCE(NULLIF(authors, ''), $2),
+    journal = COALESCE(NULLIF(journal, ''), $3),
+    publication_year = COALESCE(publication_year, $4),
+    doi = COALESCE(NULLIF(doi, ''), $5),
+    url = COALESCE(NULLIF(url, ''), $6),
+    citation_apa = COALESCE(NULLIF(citation_apa, ''), $7),
+    citation_mla = COALESCE(NULLIF(citation_mla, ''), $8),
+    abstract = COALESCE(NULLIF(abstract, ''), $9)
+WHERE id = $1
+RETURNING id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at
+`
+
+type MergeReferenceParams struct {
+	ID              pgtype.UUID `db:"id" json:"id"`
+	Authors         pgtype.Text `db:"authors" json:"authors"`
+	Journal         pgtype.Text `db:"journal" json:"journal"`
+	PublicationYear pgtype.Int4 `db:"publication_year" json:"publication_year"`
+	Doi             pgtype.Text `db:"doi" json:"doi"`
+	Url             pgtype.Text `db:"url" json:"url"`
+	CitationApa     pgtype.Text `db:"citation_apa" json:"citation_apa"`
+	CitationMla     pgtype.Text `db:"citation_mla" json:"citation_mla"`
+	Abstract        pgtype.Text `db:"abstract" json:"abstract"`
+}
+
+// Fills in what a reference is missing from a duplicate of it, keeping what it has
+func (q *Queries) MergeReference(ctx context.Context, arg MergeReferenceParams) (Reference, error) {
+	row := q.db.QueryRow(ctx, mergeReference,
+		arg.ID,
+		arg.Authors,
+		arg.Journal,
+		arg.PublicationYear,
+		arg.Doi,
+		arg.Url,
+		arg.CitationApa,
+		arg.CitationMla,
+		arg.Abstract,
+	)
+	var i Reference
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Authors,
+		&i.Journal,
+		&i.PublicationYear,
+		&i.Doi,
+		&i.Url,
+		&i.CitationApa,
+		&i.CitationMla,
+		&i.CreatedAt,
+		&i.Abstract,
+		&i.SearchVector,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const projectChapterTypeInUse = `-- name: ProjectChapterTypeInUse :one
+SELECT EXISTS (
+    SELECT 1 FROM chapters
+    WHERE project_id = $1 AND type = $2
+) AS in_use
+`
+
+type ProjectChapterTypeInUseParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Type      string      `db:"type" json:"type"`
+}
+
+// Whether chapters of the project, trashed ones included, have the type
+func (q *Queries) ProjectChapterTypeInUse(ctx context.Context, arg ProjectChapterTypeInUseParams) (bool, error) {
+	row := q.db.QueryRow(ctx, projectChapterTypeInUse, arg.ProjectID, arg.Type)
+	var inUse bool
+	err := row.Scan(&inUse)
+	return inUse, err
+}
+
+const purgeTrashedChapters = `-- name: PurgeTrashedChapters :execrows
+DELETE FROM chapters
+WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`
+
+func (q *Queries) PurgeTrashedChapters(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTrashedChapters, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeTrashedReferences = `-- name: PurgeTrashedReferences :execrows
+DELETE FROM "references"
+WHERE deleted_at IS NOT NULL AND deleted_at < $1
+`
+
+func (q *Queries) PurgeTrashedReferences(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeTrashedReferences, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordStripeEvent = `-- name: RecordStripeEvent :execrows
+INSERT INTO stripe_events (id, type)
+VALUES ($1, $2)
+ON CONFLICT (id) DO NOTHING
+`
+
+type RecordStripeEventParams struct {
+	ID   string `db:"id" json:"id"`
+	Type string `db:"type" json:"type"`
+}
+
+func (q *Queries) RecordStripeEvent(ctx context.Context, arg RecordStripeEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordStripeEvent, arg.ID, arg.Type)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordWebhookDeliveryAttempt = `-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = $1, attempts = attempts + 1, next_attempt_at = $2,
+    response_status = $3, last_error = $4, duration_ms = $5,
+    delivered_at = CASE WHEN $1 = 'succeeded' THEN NOW() END
+WHERE id = $6
+`
+
+type RecordWebhookDeliveryAttemptParams struct {
+	Status         string             `db:"status" json:"status"`
+	NextAttemptAt  pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseStatus pgtype.Int4        `db:"response_status" json:"response_status"`
+	LastError      string             `db:"last_error" json:"last_error"`
+	DurationMs     pgtype.Int4        `db:"duration_ms" json:"duration_ms"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+}
+
+func (q *Queries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error {
+	_, err := q.db.Exec(ctx, recordWebhookDeliveryAttempt,
+		arg.Status,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.DurationMs,
+		arg.ID,
+	)
+	return err
+}
+
+const releaseQuota = `-- name: ReleaseQuota :exec
+UPDATE quota_usage
+SET used = GREATEST(used - $1, 0)
+WHERE user_id = $2 AND period_start = $3 AND resource = $4
+`
+
+type ReleaseQuotaParams struct {
+	Amount      int64              `db:"amount" json:"amount"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	Resource    string             `db:"resource" json:"resource"`
+}
+
+func (q *Queries) ReleaseQuota(ctx context.Context, arg ReleaseQuotaParams) error {
+	_, err := q.db.Exec(ctx, releaseQuota,
+		arg.Amount,
+		arg.UserID,
+		arg.PeriodStart,
+		arg.Resource,
+	)
+	return err
+}
+
+const releaseUsage = `-- name: ReleaseUsage :exec
+UPDATE billing_usage
+SET used = used - 1
+WHERE user_id = $1 AND period_start = $2 AND resource = $3 AND used > 0
+`
+
+type ReleaseUsageParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+	Resource    string      `db:"resource" json:"resource"`
+}
+
+func (q *Queries) ReleaseUsage(ctx context.Context, arg ReleaseUsageParams) error {
+	_, err := q.db.Exec(ctx, releaseUsage, arg.UserID, arg.PeriodStart, arg.Resource)
+	return err
+}
+
+const removeOrganizationMember = `-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2
+`
+
+type RemoveOrganizationMemberParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeOrganizationMember, arg.OrganizationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const requeueJob = `-- name: RequeueJob :one
+UPDATE jobs
+SET status = 'pending', attempts = 0, run_at = NOW(), last_error = '', completed_at = NULL
+WHERE id = $1 AND status <> 'running'
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, completed_at, created_at, updated_at
+`
+
+// Starts a job over from its first attempt; running jobs are left alone
+func (q *Queries) RequeueJob(ctx context.Context, id pgtype.UUID) (Job, error) {
+	row := q.db.QueryRow(ctx, requeueJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.UniqueKey,
+		&i.LastError,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const reserveQuota = `-- name: ReserveQuota :one
+INSERT INTO quota_usage (user_id, period_start, resource, used)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = quota_usage.used + EXCLUDED.used
+WHERE quota_usage.used + EXCLUDED.used <= $5::bigint
+RETURNING used
+`
+
+type ReserveQuotaParams struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	Resource    string             `db:"resource" json:"resource"`
+	Amount      int64              `db:"amount" json:"amount"`
+	QuotaLimit  int64              `db:"quota_limit" json:"quota_limit"`
+}
+
+// Returns no row when the amount would take usage past the limit
+func (q *Queries) ReserveQuota(ctx context.Context, arg ReserveQuotaParams) (int64, error) {
+	row := q.db.QueryRow(ctx, reserveQuota,
+		arg.UserID,
+		arg.PeriodStart,
+		arg.Resource,
+		arg.Amount,
+		arg.QuotaLimit,
+	)
+	var used int64
+	err := row.Scan(&used)
+	return used, err
+}
+
+const reserveUsage = `-- name: ReserveUsage :one
+INSERT INTO billing_usage (user_id, period_start, resource, used)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (user_id, period_start, resource) DO UPDATE
+SET used = billing_usage.used + 1
+WHERE billing_usage.used < $4::int
+RETURNING used
+`
+
+type ReserveUsageParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+	Resource    string      `db:"resource" json:"resource"`
+	UsageLimit  int32       `db:"usage_limit" json:"usage_limit"`
+}
+
+// Returns no row when the limit is already reached
+func (q *Queries) ReserveUsage(ctx context.Context, arg ReserveUsageParams) (int32, error) {
+	row := q.db.QueryRow(ctx, reserveUsage,
+		arg.UserID,
+		arg.PeriodStart,
+		arg.Resource,
+		arg.UsageLimit,
+	)
+	var used int32
+	err := row.Scan(&used)
+	return used, err
+}
+
+const restoreChapter = `-- name: RestoreChapter :one
+UPDATE chapters
+SET deleted_at = NULL
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position
+`
+
+type RestoreChapterParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) RestoreChapter(ctx context.Context, arg RestoreChapterParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, restoreChapter, arg.ID, arg.ProjectID)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const restoreReference = `-- name: RestoreReference :one
+UPDATE "references"
+SET deleted_at = NULL
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, project_id, title, authors, journal, publication_year, doi, url, citation_apa, citation_mla, created_at, abstract, search_vector, deleted_at
+`
+
+type RestoreReferenceParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) RestoreReference(ctx context.Context, arg RestoreReferenceParams) (Reference, error) {
+	row := q.db.QueryRow(ctx, restoreReference, arg.ID, arg.ProjectID)
+	var i Reference
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Authors,
+		&i.Journal,
+		&i.PublicationYear,
+		&i.Doi,
+		&i.Url,
+		&i.CitationApa,
+		&i.CitationMla,
+		&i.CreatedAt,
+		&i.Abstract,
+		&i.SearchVector,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const retryWebhookDelivery = `-- name: RetryWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', next_attempt_at = NOW()
+WHERE id = $1 AND webhook_id = $2
+RETURNING id, webhook_id, event, payload, status, attempts, next_attempt_at, response_status, last_error, duration_ms, delivered_at, created_at
+`
+
+type RetryWebhookDeliveryParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	WebhookID pgtype.UUID `db:"webhook_id" json:"webhook_id"`
+}
+
+func (q *Queries) RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRow(ctx, retryWebhookDelivery, arg.ID, arg.WebhookID)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.Event,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.DurationMs,
+		&i.DeliveredAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const saveGeneratedChapterSection = `-- name: SaveGeneratedChapterSection :one
+UPDATE chapter_sections
+SET content = $3, word_count = $4, generated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND chapter_id = $2
+RETURNING id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at
+`
+
+type SaveGeneratedChapterSectionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Content   string      `db:"content" json:"content"`
+	WordCount int32       `db:"word_count" json:"word_count"`
+}
+
+func (q *Queries) SaveGeneratedChapterSection(ctx context.Context, arg SaveGeneratedChapterSectionParams) (ChapterSection, error) {
+	row := q.db.QueryRow(ctx, saveGeneratedChapterSection,
+		arg.ID,
+		arg.ChapterID,
+		arg.Content,
+		arg.WordCount,
+	)
+	var i ChapterSection
+	err := row.Scan(
+		&i.ID,
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Level,
+		&i.Position,
+		&i.Content,
+		&i.WordCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GeneratedAt,
+	)
+	return i, err
+}
+
+const saveUploadText = `-- name: SaveUploadText :exec
+INSERT INTO project_upload_texts (upload_id, content)
+VALUES ($1, $2)
+ON CONFLICT (upload_id) DO UPDATE SET content = EXCLUDED.content
+`
+
+type SaveUploadTextParams struct {
+	UploadID pgtype.UUID `db:"upload_id" json:"upload_id"`
+	Content  string      `db:"content" json:"content"`
+}
+
+func (q *Queries) SaveUploadText(ctx context.Context, arg SaveUploadTextParams) error {
+	_, err := q.db.Exec(ctx, saveUploadText, arg.UploadID, arg.Content)
+	return err
+}
+
+const searchProjectChapters = `-- name: SearchProjectChapters :many
+SELECT id, type, title,
+    ts_rank(search_vector, websearch_to_tsquery('english', $1::text))::real AS rank,
+    ts_headline('english', coalesce(content, ''), websearch_to_tsquery('english', $1::text),
+        E'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=\x02, StopSel=\x03')::text AS snippet
+FROM chapters
+WHERE project_id = $2 AND deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', $1::text)
+ORDER BY rank DESC
+LIMIT $3
+`
+
+type SearchProjectChaptersParams struct {
+	Query       string      `db:"query" json:"query"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	ResultLimit int32       `db:"result_limit" json:"result_limit"`
+}
+
+type SearchProjectChaptersRow struct {
+	ID      pgtype.UUID `db:"id" json:"id"`
+	Type    string      `db:"type" json:"type"`
+	Title   string      `db:"title" json:"title"`
+	Rank    float32     `db:"rank" json:"rank"`
+	Snippet string      `db:"snippet" json:"snippet"`
+}
+
+// Matches are delimited with STX and ETX, not HTML: the text is user content and is escaped first
+func (q *Queries) SearchProjectChapters(ctx context.Context, arg SearchProjectChaptersParams) ([]SearchProjectChaptersRow, error) {
+	rows, err := q.db.Query(ctx, searchProjectChapters, arg.Query, arg.ProjectID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchProjectChaptersRow{}
+	for rows.Next() {
+		var i SearchProjectChaptersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Title,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProjectReferences = `-- name: SearchProjectReferences :many
+SELECT id, title,
+    ts_rank(search_vector, websearch_to_tsquery('english', $1::text))::real AS rank,
+    ts_headline('english', coalesce(abstract, title), websearch_to_tsquery('english', $1::text),
+        E'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=\x02, StopSel=\x03')::text AS snippet
+FROM "references"
+WHERE project_id = $2 AND deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('english', $1::text)
+ORDER BY rank DESC
+LIMIT $3
+`
+
+type SearchProjectReferencesParams struct {
+	Query       string      `db:"query" json:"query"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	ResultLimit int32       `db:"result_limit" json:"result_limit"`
+}
+
+type SearchProjectReferencesRow struct {
+	ID      pgtype.UUID `db:"id" json:"id"`
+	Title   string      `db:"title" json:"title"`
+	Rank    float32     `db:"rank" json:"rank"`
+	Snippet string      `db:"snippet" json:"snippet"`
+}
+
+func (q *Queries) SearchProjectReferences(ctx context.Context, arg SearchProjectReferencesParams) ([]SearchProjectReferencesRow, error) {
+	rows, err := q.db.Query(ctx, searchProjectReferences, arg.Query, arg.ProjectID, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchProjectReferencesRow{}
+	for rows.Next() {
+		var i SearchProjectReferencesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAppendixFile = `-- name: SetAppendixFile :one
+UPDATE project_appendices
+SET file_path = $1, mime_type = $2
+WHERE id = $3
+RETURNING id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at
+`
+
+type SetAppendixFileParams struct {
+	FilePath string      `db:"file_path" json:"file_path"`
+	MimeType string      `db:"mime_type" json:"mime_type"`
+	ID       pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) SetAppendixFile(ctx context.Context, arg SetAppendixFileParams) (ProjectAppendix, error) {
+	row := q.db.QueryRow(ctx, setAppendixFile, arg.FilePath, arg.MimeType, arg.ID)
+	var i ProjectAppendix
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setChapterPosition = `-- name: SetChapterPosition :exec
+UPDATE chapters
+SET position = $3
+WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+`
+
+type SetChapterPositionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Position  int32       `db:"position" json:"position"`
+}
+
+// Moves a chapter within the thesis; its content and version are untouched
+func (q *Queries) SetChapterPosition(ctx context.Context, arg SetChapterPositionParams) error {
+	_, err := q.db.Exec(ctx, setChapterPosition, arg.ID, arg.ProjectID, arg.Position)
+	return err
+}
+
+const setChapterSectionPosition = `-- name: SetChapterSectionPosition :exec
+UPDATE chapter_sections
+SET position = $3
+WHERE id = $1 AND chapter_id = $2
+`
+
+type SetChapterSectionPositionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Position  int32       `db:"position" json:"position"`
+}
+
+func (q *Queries) SetChapterSectionPosition(ctx context.Context, arg SetChapterSectionPositionParams) error {
+	_, err := q.db.Exec(ctx, setChapterSectionPosition, arg.ID, arg.ChapterID, arg.Position)
+	return err
+}
+
+const setChapterStatus = `-- name: SetChapterStatus :one
+UPDATE chapters
+SET status = $2, version = version + 1, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position
+`
+
+type SetChapterStatusParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	Status pgtype.Text `db:"status" json:"status"`
+}
+
+// Used by the review workflow; bumps version so concurrent edits based on the old status conflict
+func (q *Queries) SetChapterStatus(ctx context.Context, arg SetChapterStatusParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, setChapterStatus, arg.ID, arg.Status)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const setCommentThreadResolved = `-- name: SetCommentThreadResolved :one
+UPDATE comment_threads
+SET resolved_at = CASE WHEN $1::boolean THEN NOW() END,
+    resolved_by = CASE WHEN $1::boolean THEN $2::uuid END
+WHERE id = $3 AND project_id = $4
+RETURNING id, project_id, chapter_id, anchor_start, anchor_end, anchor_heading, quoted_text, created_by, resolved_at, resolved_by, created_at, updated_at
+`
+
+type SetCommentThreadResolvedParams struct {
+	Resolved   bool        `db:"resolved" json:"resolved"`
+	ResolvedBy pgtype.UUID `db:"resolved_by" json:"resolved_by"`
+	ID         pgtype.UUID `db:"id" json:"id"`
+	ProjectID  pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) SetCommentThreadResolved(ctx context.Context, arg SetCommentThreadResolvedParams) (CommentThread, error) {
+	row := q.db.QueryRow(ctx, setCommentThreadResolved,
+		arg.Resolved,
+		arg.ResolvedBy,
+		arg.ID,
+		arg.ProjectID,
+	)
+	var i CommentThread
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.AnchorStart,
+		&i.AnchorEnd,
+		&i.AnchorHeading,
+		&i.QuotedText,
+		&i.CreatedBy,
+		&i.ResolvedAt,
+		&i.ResolvedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setDatasetTables = `-- name: SetDatasetTables :exec
+UPDATE project_datasets
+SET table_ids = $1
+WHERE id = $2
+`
+
+type SetDatasetTablesParams struct {
+	TableIds []pgtype.UUID `db:"table_ids" json:"table_ids"`
+	ID       pgtype.UUID   `db:"id" json:"id"`
+}
+
+func (q *Queries) SetDatasetTables(ctx context.Context, arg SetDatasetTablesParams) error {
+	_, err := q.db.Exec(ctx, setDatasetTables, arg.TableIds, arg.ID)
+	return err
+}
+
+const setGuidelineResult = `-- name: SetGuidelineResult :exec
+UPDATE project_guidelines
+SET status = $1, error = $2, rules = $3
+WHERE id = $4
+`
+
+type SetGuidelineResultParams struct {
+	Status string      `db:"status" json:"status"`
+	Error  string      `db:"error" json:"error"`
+	Rules  []byte      `db:"rules" json:"rules"`
+	ID     pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) SetGuidelineResult(ctx context.Context, arg SetGuidelineResultParams) error {
+	_, err := q.db.Exec(ctx, setGuidelineResult,
+		arg.Status,
+		arg.Error,
+		arg.Rules,
+		arg.ID,
+	)
+	return err
+}
+
+const setProjectAdvisor = `-- name: SetProjectAdvisor :one
+UPDATE research_projects
+SET advisor_id = $1
+WHERE id = $2
+RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language
+`
+
+type SetProjectAdvisorParams struct {
+	AdvisorID pgtype.UUID `db:"advisor_id" json:"advisor_id"`
+	ID        pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) SetProjectAdvisor(ctx context.Context, arg SetProjectAdvisorParams) (ResearchProject, error) {
+	row := q.db.QueryRow(ctx, setProjectAdvisor, arg.AdvisorID, arg.ID)
+	var i ResearchProject
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Specialization,
+		&i.University,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.AdvisorID,
+		&i.Language,
+	)
+	return i, err
+}
+
+const setReferencePaperPDF = `-- name: SetReferencePaperPDF :exec
+UPDATE reference_papers
+SET pdf_status = $1, pdf_error = $2, pdf_path = $3, pdf_size = $4
+WHERE reference_id = $5
+`
+
+type SetReferencePaperPDFParams struct {
+	PdfStatus   string      `db:"pdf_status" json:"pdf_status"`
+	PdfError    string      `db:"pdf_error" json:"pdf_error"`
+	PdfPath     string      `db:"pdf_path" json:"pdf_path"`
+	PdfSize     pgtype.Int8 `db:"pdf_size" json:"pdf_size"`
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+}
+
+func (q *Queries) SetReferencePaperPDF(ctx context.Context, arg SetReferencePaperPDFParams) error {
+	_, err := q.db.Exec(ctx, setReferencePaperPDF,
+		arg.PdfStatus,
+		arg.PdfError,
+		arg.PdfPath,
+		arg.PdfSize,
+		arg.ReferenceID,
+	)
+	return err
+}
+
+const setTimelineInDocument = `-- name: SetTimelineInDocument :one
+UPDATE project_timelines
+SET include_in_document = $1
+WHERE project_id = $2
+RETURNING id, project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by, created_at, updated_at
+`
+
+type SetTimelineInDocumentParams struct {
+	IncludeInDocument bool        `db:"include_in_document" json:"include_in_document"`
+	ProjectID         pgtype.UUID `db:"project_id" json:"project_id"`
+}
+
+func (q *Queries) SetTimelineInDocument(ctx context.Context, arg SetTimelineInDocumentParams) (ProjectTimeline, error) {
+	row := q.db.QueryRow(ctx, setTimelineInDocument, arg.IncludeInDocument, arg.ProjectID)
+	var i ProjectTimeline
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Milestones,
+		&i.Tasks,
+		&i.IncludeInDocument,
+		&i.GeneratedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setUploadExtraction = `-- name: SetUploadExtraction :exec
+UPDATE project_uploads
+SET extraction_status = $1, extraction_error = $2, page_count = $3
+WHERE id = $4
+`
+
+type SetUploadExtractionParams struct {
+	ExtractionStatus string      `db:"extraction_status" json:"extraction_status"`
+	ExtractionError  string      `db:"extraction_error" json:"extraction_error"`
+	PageCount        pgtype.Int4 `db:"page_count" json:"page_count"`
+	ID               pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) SetUploadExtraction(ctx context.Context, arg SetUploadExtractionParams) error {
+	_, err := q.db.Exec(ctx, setUploadExtraction,
+		arg.ExtractionStatus,
+		arg.ExtractionError,
+		arg.PageCount,
+		arg.ID,
+	)
+	return err
+}
+
+const similarProjectReferences = `-- name: SimilarProjectReferences :many
+SELECT r.id, r.project_id, r.title, r.authors, r.journal, r.publication_year, r.doi, r.url,
+    r.citation_apa, r.citation_mla, r.abstract, r.created_at,
+    (1 - (e.embedding <=> $1::vector))::real AS similarity
+FROM "references" r
+JOIN reference_embeddings e ON e.reference_id = r.id AND e.model = $2
+WHERE r.project_id = $3 AND r.deleted_at IS NULL
+ORDER BY e.embedding <=> $1::vector
+LIMIT $4
+`
+
+type SimilarProjectReferencesParams struct {
+	Embedding   string      `db:"embedding" json:"embedding"`
+	Model       string      `db:"model" json:"model"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	ResultLimit int32       `db:"result_limit" json:"result_limit"`
+}
+
+type SimilarProjectReferencesRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	ProjectID       pgtype.UUID        `db:"project_id" json:"project_id"`
+	Title           string             `db:"title" json:"title"`
+	Authors         pgtype.Text        `db:"authors" json:"authors"`
+	Journal         pgtype.Text        `db:"journal" json:"journal"`
+	PublicationYear pgtype.Int4        `db:"publication_year" json:"publication_year"`
+	Doi             pgtype.Text        `db:"doi" json:"doi"`
+	Url             pgtype.Text        `db:"url" json:"url"`
+	CitationApa     pgtype.Text        `db:"citation_apa" json:"citation_apa"`
+	CitationMla     pgtype.Text        `db:"citation_mla" json:"citation_mla"`
+	Abstract        pgtype.Text        `db:"abstract" json:"abstract"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Similarity      float32            `db:"similarity" json:"similarity"`
+}
+
+func (q *Queries) SimilarProjectReferences(ctx context.Context, arg SimilarProjectReferencesParams) ([]SimilarProjectReferencesRow, error) {
+	rows, err := q.db.Query(ctx, similarProjectReferences,
+		arg.Embedding,
+		arg.Model,
+		arg.ProjectID,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SimilarProjectReferencesRow{}
+	for rows.Next() {
+		var i SimilarProjectReferencesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Title,
+			&i.Authors,
+			&i.Journal,
+			&i.PublicationYear,
+			&i.Doi,
+			&i.Url,
 			&i.CitationApa,
 			&i.CitationMla,
+			&i.Abstract,
 			&i.CreatedAt,
+			&i.Similarity,
 		); err != nil {
 			return nil, err
 		}
@@ -532,337 +6770,1040 @@ func (q *Queries) GetReferencesByProjectID(ctx context.Context, projectID pgtype
 	return items, nil
 }
 
-const getResearchProjectByID = `-- name: GetResearchProjectByID :one
-SELECT id, user_id, title, specialization, university, description, status, created_at, updated_at FROM research_projects
-WHERE id = $1 AND user_id = $2 LIMIT 1
+const updateAppendix = `-- name: UpdateAppendix :one
+UPDATE project_appendices
+SET title = $1, content = $2, position = $3
+WHERE id = $4
+RETURNING id, project_id, title, content, position, file_path, mime_type, created_by, created_at, updated_at
+`
+
+type UpdateAppendixParams struct {
+	Title    string      `db:"title" json:"title"`
+	Content  string      `db:"content" json:"content"`
+	Position int32       `db:"position" json:"position"`
+	ID       pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateAppendix(ctx context.Context, arg UpdateAppendixParams) (ProjectAppendix, error) {
+	row := q.db.QueryRow(ctx, updateAppendix,
+		arg.Title,
+		arg.Content,
+		arg.Position,
+		arg.ID,
+	)
+	var i ProjectAppendix
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateChapter = `-- name: UpdateChapter :one
+UPDATE chapters
+SET title = $1, content = $2, word_count = $3, status = $4, target_word_count = $5,
+    custom_prompt = COALESCE($6, custom_prompt), version = version + 1, updated_at = NOW()
+WHERE chapters.id = $7 AND deleted_at IS NULL
+    AND project_id = $8 AND project_access_role($8, $9) IN ('owner', 'edit') -- ensure user can edit project
+    AND ($10::int IS NULL OR version = $10::int)
+RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at, search_vector, version, deleted_at, target_word_count, custom_prompt, position
+`
+
+type UpdateChapterParams struct {
+	Title           string      `db:"title" json:"title"`
+	Content         pgtype.Text `db:"content" json:"content"`
+	WordCount       pgtype.Int4 `db:"word_count" json:"word_count"`
+	Status          pgtype.Text `db:"status" json:"status"`
+	TargetWordCount pgtype.Int4 `db:"target_word_count" json:"target_word_count"`
+	CustomPrompt    pgtype.Text `db:"custom_prompt" json:"custom_prompt"`
+	ID              pgtype.UUID `db:"id" json:"id"`
+	ProjectID       pgtype.UUID `db:"project_id" json:"project_id"`
+	UserID          pgtype.UUID `db:"user_id" json:"user_id"`
+	ExpectedVersion pgtype.Int4 `db:"expected_version" json:"expected_version"`
+}
+
+// expected_version is optional; when set the update only applies if nobody else saved in between.
+// custom_prompt is kept when NULL.
+func (q *Queries) UpdateChapter(ctx context.Context, arg UpdateChapterParams) (Chapter, error) {
+	row := q.db.QueryRow(ctx, updateChapter,
+		arg.Title,
+		arg.Content,
+		arg.WordCount,
+		arg.Status,
+		arg.TargetWordCount,
+		arg.CustomPrompt,
+		arg.ID,
+		arg.ProjectID,
+		arg.UserID,
+		arg.ExpectedVersion,
+	)
+	var i Chapter
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Type,
+		&i.Title,
+		&i.Content,
+		&i.WordCount,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Version,
+		&i.DeletedAt,
+		&i.TargetWordCount,
+		&i.CustomPrompt,
+		&i.Position,
+	)
+	return i, err
+}
+
+const updateChapterFigure = `-- name: UpdateChapterFigure :one
+UPDATE chapter_figures
+SET caption = $1, position = $2, table_data = $3
+WHERE id = $4
+RETURNING id, project_id, chapter_id, kind, caption, position, file_path, mime_type, table_data, created_by, created_at, updated_at
+`
+
+type UpdateChapterFigureParams struct {
+	Caption   string      `db:"caption" json:"caption"`
+	Position  int32       `db:"position" json:"position"`
+	TableData []byte      `db:"table_data" json:"table_data"`
+	ID        pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateChapterFigure(ctx context.Context, arg UpdateChapterFigureParams) (ChapterFigure, error) {
+	row := q.db.QueryRow(ctx, updateChapterFigure,
+		arg.Caption,
+		arg.Position,
+		arg.TableData,
+		arg.ID,
+	)
+	var i ChapterFigure
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ChapterID,
+		&i.Kind,
+		&i.Caption,
+		&i.Position,
+		&i.FilePath,
+		&i.MimeType,
+		&i.TableData,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateChapterSection = `-- name: UpdateChapterSection :one
+UPDATE chapter_sections
+SET title = $3, level = $4, content = $5, word_count = $6, updated_at = NOW()
+WHERE id = $1 AND chapter_id = $2
+RETURNING id, chapter_id, project_id, title, level, position, content, word_count, created_at, updated_at, generated_at
+`
+
+type UpdateChapterSectionParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	Title     string      `db:"title" json:"title"`
+	Level     int32       `db:"level" json:"level"`
+	Content   string      `db:"content" json:"content"`
+	WordCount int32       `db:"word_count" json:"word_count"`
+}
+
+func (q *Queries) UpdateChapterSection(ctx context.Context, arg UpdateChapterSectionParams) (ChapterSection, error) {
+	row := q.db.QueryRow(ctx, updateChapterSection,
+		arg.ID,
+		arg.ChapterID,
+		arg.Title,
+		arg.Level,
+		arg.Content,
+		arg.WordCount,
+	)
+	var i ChapterSection
+	err := row.Scan(
+		&i.ID,
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Level,
+		&i.Position,
+		&i.Content,
+		&i.WordCount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GeneratedAt,
+	)
+	return i, err
+}
+
+const updateChapterType = `-- name: UpdateChapterType :one
+UPDATE chapter_types
+SET label = $3, description = $4, position = $5, updated_at = NOW()
+WHERE organization_id = $1 AND key = $2
+RETURNING id, organization_id, key, label, description, position, created_by, created_at, updated_at, project_id
+`
+
+type UpdateChapterTypeParams struct {
+	OrganizationID pgtype.UUID `db:"organization_id" json:"organization_id"`
+	Key            string      `db:"key" json:"key"`
+	Label          string      `db:"label" json:"label"`
+	Description    string      `db:"description" json:"description"`
+	Position       int32       `db:"position" json:"position"`
+}
+
+func (q *Queries) UpdateChapterType(ctx context.Context, arg UpdateChapterTypeParams) (ChapterType, error) {
+	row := q.db.QueryRow(ctx, updateChapterType,
+		arg.OrganizationID,
+		arg.Key,
+		arg.Label,
+		arg.Description,
+		arg.Position,
+	)
+	var i ChapterType
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Key,
+		&i.Label,
+		&i.Description,
+		&i.Position,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ProjectID,
+	)
+	return i, err
+}
+
+const updateGeneratedDocument = `-- name: UpdateGeneratedDocument :one
+UPDATE generated_documents
+SET file_name = $2, file_path = $3, file_size = $4, mime_type = $5, status = $6, storage_key = $7
+WHERE id = $1
+RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at, storage_key
+`
+
+type UpdateGeneratedDocumentParams struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	FileName   string      `db:"file_name" json:"file_name"`
+	FilePath   string      `db:"file_path" json:"file_path"`
+	FileSize   pgtype.Int8 `db:"file_size" json:"file_size"`
+	MimeType   pgtype.Text `db:"mime_type" json:"mime_type"`
+	Status     pgtype.Text `db:"status" json:"status"`
+	StorageKey pgtype.Text `db:"storage_key" json:"storage_key"`
+}
+
+func (q *Queries) UpdateGeneratedDocument(ctx context.Context, arg UpdateGeneratedDocumentParams) (GeneratedDocument, error) {
+	row := q.db.QueryRow(ctx, updateGeneratedDocument,
+		arg.ID,
+		arg.FileName,
+		arg.FilePath,
+		arg.FileSize,
+		arg.MimeType,
+		arg.Status,
+		arg.StorageKey,
+	)
+	var i GeneratedDocument
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Status,
+		&i.CreatedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const updateGeneratedDocumentStatus = `-- name: UpdateGeneratedDocumentStatus :one
+UPDATE generated_documents
+SET status = $2
+WHERE id = $1
+RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at, storage_key
+`
+
+type UpdateGeneratedDocumentStatusParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	Status pgtype.Text `db:"status" json:"status"`
+}
+
+func (q *Queries) UpdateGeneratedDocumentStatus(ctx context.Context, arg UpdateGeneratedDocumentStatusParams) (GeneratedDocument, error) {
+	row := q.db.QueryRow(ctx, updateGeneratedDocumentStatus, arg.ID, arg.Status)
+	var i GeneratedDocument
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FileName,
+		&i.FilePath,
+		&i.FileSize,
+		&i.MimeType,
+		&i.Status,
+		&i.CreatedAt,
+		&i.StorageKey,
+	)
+	return i, err
+}
+
+const updateGenerationBatch = `-- name: UpdateGenerationBatch :one
+UPDATE generation_batches
+SET status = $2, chapters = $3, error = $4, updated_at = NOW(),
+    completed_at = CASE WHEN $2 IN ('completed', 'failed') THEN NOW() END
+WHERE id = $1
+RETURNING id, project_id, user_id, status, chapters, error, created_at, updated_at, completed_at
+`
+
+type UpdateGenerationBatchParams struct {
+	ID       pgtype.UUID `db:"id" json:"id"`
+	Status   string      `db:"status" json:"status"`
+	Chapters []byte      `db:"chapters" json:"chapters"`
+	Error    pgtype.Text `db:"error" json:"error"`
+}
+
+// Completed or failed runs are finished
+func (q *Queries) UpdateGenerationBatch(ctx context.Context, arg UpdateGenerationBatchParams) (GenerationBatch, error) {
+	row := q.db.QueryRow(ctx, updateGenerationBatch,
+		arg.ID,
+		arg.Status,
+		arg.Chapters,
+		arg.Error,
+	)
+	var i GenerationBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.Status,
+		&i.Chapters,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const updateNotificationEmailStatus = `-- name: UpdateNotificationEmailStatus :exec
+UPDATE notifications
+SET email_status = $2, email_attempts = email_attempts + 1,
+    email_sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+WHERE id = $1
+`
+
+type UpdateNotificationEmailStatusParams struct {
+	ID          pgtype.UUID `db:"id" json:"id"`
+	EmailStatus string      `db:"email_status" json:"email_status"`
+}
+
+func (q *Queries) UpdateNotificationEmailStatus(ctx context.Context, arg UpdateNotificationEmailStatusParams) error {
+	_, err := q.db.Exec(ctx, updateNotificationEmailStatus, arg.ID, arg.EmailStatus)
+	return err
+}
+
+const updateQuestionnaire = `-- name: UpdateQuestionnaire :one
+UPDATE questionnaires
+SET title = $1, instructions = $2, sections = $3, include_in_document = $4
+WHERE id = $5
+RETURNING id, project_id, title, instructions, sections, include_in_document, created_by, created_at, updated_at
+`
+
+type UpdateQuestionnaireParams struct {
+	Title             string      `db:"title" json:"title"`
+	Instructions      string      `db:"instructions" json:"instructions"`
+	Sections          []byte      `db:"sections" json:"sections"`
+	IncludeInDocument bool        `db:"include_in_document" json:"include_in_document"`
+	ID                pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateQuestionnaire(ctx context.Context, arg UpdateQuestionnaireParams) (Questionnaire, error) {
+	row := q.db.QueryRow(ctx, updateQuestionnaire,
+		arg.Title,
+		arg.Instructions,
+		arg.Sections,
+		arg.IncludeInDocument,
+		arg.ID,
+	)
+	var i Questionnaire
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Instructions,
+		&i.Sections,
+		&i.IncludeInDocument,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateResearchProject = `-- name: UpdateResearchProject :one
+UPDATE research_projects
+SET title = $2, specialization = $3, university = $4, description = $5, status = $6, language = $8, updated_at = NOW()
+WHERE id = $1 AND project_access_role(id, $7) IN ('owner', 'edit')
+RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language
+`
+
+type UpdateResearchProjectParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	Title          string      `db:"title" json:"title"`
+	Specialization string      `db:"specialization" json:"specialization"`
+	University     pgtype.Text `db:"university" json:"university"`
+	Description    pgtype.Text `db:"description" json:"description"`
+	Status         pgtype.Text `db:"status" json:"status"`
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	Language       string      `db:"language" json:"language"`
+}
+
+func (q *Queries) UpdateResearchProject(ctx context.Context, arg UpdateResearchProjectParams) (ResearchProject, error) {
+	row := q.db.QueryRow(ctx, updateResearchProject,
+		arg.ID,
+		arg.Title,
+		arg.Specialization,
+		arg.University,
+		arg.Description,
+		arg.Status,
+		arg.UserID,
+		arg.Language,
+	)
+	var i ResearchProject
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Specialization,
+		&i.University,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.AdvisorID,
+		&i.Language,
+	)
+	return i, err
+}
+
+const updateResearchProjectStatus = `-- name: UpdateResearchProjectStatus :one
+UPDATE research_projects
+SET status = $2, updated_at = NOW()
+WHERE id = $1 AND project_access_role(id, $3) IN ('owner', 'edit')
+RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at, organization_id, advisor_id, language
+`
+
+type UpdateResearchProjectStatusParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	Status pgtype.Text `db:"status" json:"status"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) UpdateResearchProjectStatus(ctx context.Context, arg UpdateResearchProjectStatusParams) (ResearchProject, error) {
+	row := q.db.QueryRow(ctx, updateResearchProjectStatus, arg.ID, arg.Status, arg.UserID)
+	var i ResearchProject
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Specialization,
+		&i.University,
+		&i.Description,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.AdvisorID,
+		&i.Language,
+	)
+	return i, err
+}
+
+const updateSubscriptionFromStripe = `-- name: UpdateSubscriptionFromStripe :one
+UPDATE subscriptions
+SET stripe_subscription_id = $2, plan = $3, status = $4, current_period_end = $5, cancel_at_period_end = $6
+WHERE stripe_customer_id = $1
+RETURNING user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end, cancel_at_period_end, created_at, updated_at
+`
+
+type UpdateSubscriptionFromStripeParams struct {
+	StripeCustomerID     string             `db:"stripe_customer_id" json:"stripe_customer_id"`
+	StripeSubscriptionID pgtype.Text        `db:"stripe_subscription_id" json:"stripe_subscription_id"`
+	Plan                 string             `db:"plan" json:"plan"`
+	Status               string             `db:"status" json:"status"`
+	CurrentPeriodEnd     pgtype.Timestamptz `db:"current_period_end" json:"current_period_end"`
+	CancelAtPeriodEnd    bool               `db:"cancel_at_period_end" json:"cancel_at_period_end"`
+}
+
+func (q *Queries) UpdateSubscriptionFromStripe(ctx context.Context, arg UpdateSubscriptionFromStripeParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, updateSubscriptionFromStripe,
+		arg.StripeCustomerID,
+		arg.StripeSubscriptionID,
+		arg.Plan,
+		arg.Status,
+		arg.CurrentPeriodEnd,
+		arg.CancelAtPeriodEnd,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.UserID,
+		&i.StripeCustomerID,
+		&i.StripeSubscriptionID,
+		&i.Plan,
+		&i.Status,
+		&i.CurrentPeriodEnd,
+		&i.CancelAtPeriodEnd,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserVerificationStatus = `-- name: UpdateUserVerificationStatus :one
+UPDATE users
+SET is_verified = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at, guest_expires_at
+`
+
+type UpdateUserVerificationStatusParams struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	IsVerified pgtype.Bool `db:"is_verified" json:"is_verified"`
+}
+
+func (q *Queries) UpdateUserVerificationStatus(ctx context.Context, arg UpdateUserVerificationStatusParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserVerificationStatus, arg.ID, arg.IsVerified)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FirstName,
+		&i.LastName,
+		&i.IsVerified,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GuestExpiresAt,
+	)
+	return i, err
+}
+
+const updateWebhook = `-- name: UpdateWebhook :one
+UPDATE webhooks
+SET url = $3, events = $4, active = $5
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, url, secret, events, active, created_by, created_at, updated_at
+`
+
+type UpdateWebhookParams struct {
+	ID        pgtype.UUID `db:"id" json:"id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Url       string      `db:"url" json:"url"`
+	Events    []string    `db:"events" json:"events"`
+	Active    bool        `db:"active" json:"active"`
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRow(ctx, updateWebhook,
+		arg.ID,
+		arg.ProjectID,
+		arg.Url,
+		arg.Events,
+		arg.Active,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Active,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertChapterDraft = `-- name: UpsertChapterDraft :one
+INSERT INTO chapter_drafts (chapter_id, user_id, project_id, title, content, base_version)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (chapter_id, user_id) DO UPDATE
+SET title = COALESCE(EXCLUDED.title, chapter_drafts.title),
+    content = COALESCE(EXCLUDED.content, chapter_drafts.content),
+    base_version = EXCLUDED.base_version,
+    updated_at = NOW()
+RETURNING chapter_id, user_id, project_id, title, content, base_version, updated_at
+`
+
+type UpsertChapterDraftParams struct {
+	ChapterID   pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Title       pgtype.Text `db:"title" json:"title"`
+	Content     pgtype.Text `db:"content" json:"content"`
+	BaseVersion int32       `db:"base_version" json:"base_version"`
+}
+
+// Merges an autosave into the editor's draft; a NULL title or content keeps the draft's
+func (q *Queries) UpsertChapterDraft(ctx context.Context, arg UpsertChapterDraftParams) (ChapterDraft, error) {
+	row := q.db.QueryRow(ctx, upsertChapterDraft,
+		arg.ChapterID,
+		arg.UserID,
+		arg.ProjectID,
+		arg.Title,
+		arg.Content,
+		arg.BaseVersion,
+	)
+	var i ChapterDraft
+	err := row.Scan(
+		&i.ChapterID,
+		&i.UserID,
+		&i.ProjectID,
+		&i.Title,
+		&i.Content,
+		&i.BaseVersion,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertChapterMetrics = `-- name: UpsertChapterMetrics :one
+INSERT INTO chapter_metrics (chapter_id, project_id, version, metrics)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chapter_id) DO UPDATE
+SET version = EXCLUDED.version, metrics = EXCLUDED.metrics, computed_at = NOW()
+RETURNING chapter_id, project_id, version, metrics, computed_at
+`
+
+type UpsertChapterMetricsParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Version   int32       `db:"version" json:"version"`
+	Metrics   []byte      `db:"metrics" json:"metrics"`
+}
+
+func (q *Queries) UpsertChapterMetrics(ctx context.Context, arg UpsertChapterMetricsParams) (ChapterMetric, error) {
+	row := q.db.QueryRow(ctx, upsertChapterMetrics,
+		arg.ChapterID,
+		arg.ProjectID,
+		arg.Version,
+		arg.Metrics,
+	)
+	var i ChapterMetric
+	err := row.Scan(
+		&i.ChapterID,
+		&i.ProjectID,
+		&i.Version,
+		&i.Metrics,
+		&i.ComputedAt,
+	)
+	return i, err
+}
+
+const upsertChapterProvenance = `-- name: UpsertChapterProvenance :exec
+INSERT INTO chapter_provenance (chapter_id, project_id, version, spans)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chapter_id) DO UPDATE
+SET version = EXCLUDED.version, spans = EXCLUDED.spans, updated_at = NOW()
+WHERE chapter_provenance.version <= EXCLUDED.version
+`
+
+type UpsertChapterProvenanceParams struct {
+	ChapterID pgtype.UUID `db:"chapter_id" json:"chapter_id"`
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Version   int32       `db:"version" json:"version"`
+	Spans     []byte      `db:"spans" json:"spans"`
+}
+
+// A save that lost a race to a newer one leaves the newer spans in place
+func (q *Queries) UpsertChapterProvenance(ctx context.Context, arg UpsertChapterProvenanceParams) error {
+	_, err := q.db.Exec(ctx, upsertChapterProvenance,
+		arg.ChapterID,
+		arg.ProjectID,
+		arg.Version,
+		arg.Spans,
+	)
+	return err
+}
+
+const upsertFeatureFlag = `-- name: UpsertFeatureFlag :one
+INSERT INTO feature_flags (name, enabled)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE
+SET enabled = EXCLUDED.enabled, updated_at = NOW()
+RETURNING name, enabled, updated_at
+`
+
+type UpsertFeatureFlagParams struct {
+	Name    string `db:"name" json:"name"`
+	Enabled bool   `db:"enabled" json:"enabled"`
+}
+
+func (q *Queries) UpsertFeatureFlag(ctx context.Context, arg UpsertFeatureFlagParams) (FeatureFlag, error) {
+	row := q.db.QueryRow(ctx, upsertFeatureFlag, arg.Name, arg.Enabled)
+	var i FeatureFlag
+	err := row.Scan(&i.Name, &i.Enabled, &i.UpdatedAt)
+	return i, err
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (user_id, type, in_app, email)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, type) DO UPDATE
+SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, updated_at = NOW()
+RETURNING user_id, type, in_app, email, updated_at
 `
 
-type GetResearchProjectByIDParams struct {
-	ID     pgtype.UUID `db:"id" json:"id"`
+type UpsertNotificationPreferenceParams struct {
 	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+	Type   string      `db:"type" json:"type"`
+	InApp  bool        `db:"in_app" json:"in_app"`
+	Email  bool        `db:"email" json:"email"`
 }
 
-func (q *Queries) GetResearchProjectByID(ctx context.Context, arg GetResearchProjectByIDParams) (ResearchProject, error) {
-	row := q.db.QueryRow(ctx, getResearchProjectByID, arg.ID, arg.UserID)
-	var i ResearchProject
+func (q *Queries) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error) {
+	row := q.db.QueryRow(ctx, upsertNotificationPreference,
+		arg.UserID,
+		arg.Type,
+		arg.InApp,
+		arg.Email,
+	)
+	var i NotificationPreference
 	err := row.Scan(
-		&i.ID,
 		&i.UserID,
-		&i.Title,
-		&i.Specialization,
-		&i.University,
-		&i.Description,
-		&i.Status,
-		&i.CreatedAt,
+		&i.Type,
+		&i.InApp,
+		&i.Email,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getSessionByRefreshToken = `-- name: GetSessionByRefreshToken :one
-SELECT id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
-WHERE refresh_token = $1 LIMIT 1
+const upsertOrganizationInvitation = `-- name: UpsertOrganizationInvitation :one
+INSERT INTO organization_invitations (organization_id, email, role, invited_by, expires_at, token_hash)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (organization_id, lower(email)) DO UPDATE
+SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, expires_at = EXCLUDED.expires_at, token_hash = EXCLUDED.token_hash, created_at = NOW()
+RETURNING id, organization_id, email, role, invited_by, expires_at, created_at, token_hash
 `
 
-func (q *Queries) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (Session, error) {
-	row := q.db.QueryRow(ctx, getSessionByRefreshToken, refreshToken)
-	var i Session
+type UpsertOrganizationInvitationParams struct {
+	OrganizationID pgtype.UUID        `db:"organization_id" json:"organization_id"`
+	Email          string             `db:"email" json:"email"`
+	Role           string             `db:"role" json:"role"`
+	InvitedBy      pgtype.UUID        `db:"invited_by" json:"invited_by"`
+	ExpiresAt      pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	TokenHash      pgtype.Text        `db:"token_hash" json:"token_hash"`
+}
+
+// Inviting the same email again refreshes the role, expiry and token
+func (q *Queries) UpsertOrganizationInvitation(ctx context.Context, arg UpsertOrganizationInvitationParams) (OrganizationInvitation, error) {
+	row := q.db.QueryRow(ctx, upsertOrganizationInvitation,
+		arg.OrganizationID,
+		arg.Email,
+		arg.Role,
+		arg.InvitedBy,
+		arg.ExpiresAt,
+		arg.TokenHash,
+	)
+	var i OrganizationInvitation
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.RefreshToken,
-		&i.UserAgent,
-		&i.ClientIp,
-		&i.IsBlocked,
+		&i.OrganizationID,
+		&i.Email,
+		&i.Role,
+		&i.InvitedBy,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.TokenHash,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at FROM users
-WHERE email = $1 LIMIT 1
+const upsertProjectCollaborator = `-- name: UpsertProjectCollaborator :one
+INSERT INTO project_collaborators (project_id, user_id, role, added_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (project_id, user_id) DO UPDATE
+SET role = EXCLUDED.role, updated_at = NOW()
+RETURNING project_id, user_id, role, added_by, created_at, updated_at
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
-	var i User
+type UpsertProjectCollaboratorParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+	Role      string      `db:"role" json:"role"`
+	AddedBy   pgtype.UUID `db:"added_by" json:"added_by"`
+}
+
+func (q *Queries) UpsertProjectCollaborator(ctx context.Context, arg UpsertProjectCollaboratorParams) (ProjectCollaborator, error) {
+	row := q.db.QueryRow(ctx, upsertProjectCollaborator,
+		arg.ProjectID,
+		arg.UserID,
+		arg.Role,
+		arg.AddedBy,
+	)
+	var i ProjectCollaborator
 	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.PasswordHash,
-		&i.FirstName,
-		&i.LastName,
-		&i.IsVerified,
+		&i.ProjectID,
+		&i.UserID,
+		&i.Role,
+		&i.AddedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at FROM users
-WHERE id = $1 LIMIT 1
+const upsertProjectFormatting = `-- name: UpsertProjectFormatting :exec
+INSERT INTO project_formatting (project_id, options)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET options = EXCLUDED.options, updated_at = NOW()
 `
 
-func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByID, id)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.PasswordHash,
-		&i.FirstName,
-		&i.LastName,
-		&i.IsVerified,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type UpsertProjectFormattingParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Options   []byte      `db:"options" json:"options"`
 }
 
-const getUserResearchProjects = `-- name: GetUserResearchProjects :many
-SELECT id, user_id, title, specialization, university, description, status, created_at, updated_at FROM research_projects
-WHERE user_id = $1
-ORDER BY created_at DESC
+func (q *Queries) UpsertProjectFormatting(ctx context.Context, arg UpsertProjectFormattingParams) error {
+	_, err := q.db.Exec(ctx, upsertProjectFormatting, arg.ProjectID, arg.Options)
+	return err
+}
+
+const upsertProjectFrontMatter = `-- name: UpsertProjectFrontMatter :exec
+INSERT INTO project_front_matter (project_id, content)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET content = EXCLUDED.content, updated_at = NOW()
 `
 
-func (q *Queries) GetUserResearchProjects(ctx context.Context, userID pgtype.UUID) ([]ResearchProject, error) {
-	rows, err := q.db.Query(ctx, getUserResearchProjects, userID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []ResearchProject{}
-	for rows.Next() {
-		var i ResearchProject
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Title,
-			&i.Specialization,
-			&i.University,
-			&i.Description,
-			&i.Status,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type UpsertProjectFrontMatterParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Content   []byte      `db:"content" json:"content"`
 }
 
-const updateChapter = `-- name: UpdateChapter :one
-UPDATE chapters
-SET title = $2, content = $3, word_count = $4, status = $5, updated_at = NOW()
-WHERE chapters.id = $1 AND project_id = (SELECT project_id FROM research_projects WHERE research_projects.id = $6 AND user_id = $7) -- ensure user owns project
-RETURNING id, project_id, type, title, content, word_count, status, created_at, updated_at
+func (q *Queries) UpsertProjectFrontMatter(ctx context.Context, arg UpsertProjectFrontMatterParams) error {
+	_, err := q.db.Exec(ctx, upsertProjectFrontMatter, arg.ProjectID, arg.Content)
+	return err
+}
+
+const upsertProjectGenerationPreferences = `-- name: UpsertProjectGenerationPreferences :exec
+INSERT INTO project_generation_preferences (project_id, preferences)
+VALUES ($1, $2)
+ON CONFLICT (project_id) DO UPDATE
+SET preferences = EXCLUDED.preferences, updated_at = NOW()
 `
 
-type UpdateChapterParams struct {
-	ID        pgtype.UUID `db:"id" json:"id"`
-	Title     string      `db:"title" json:"title"`
-	Content   pgtype.Text `db:"content" json:"content"`
-	WordCount pgtype.Int4 `db:"word_count" json:"word_count"`
-	Status    pgtype.Text `db:"status" json:"status"`
-	ID_2      pgtype.UUID `db:"id_2" json:"id_2"`
-	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+type UpsertProjectGenerationPreferencesParams struct {
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Preferences []byte      `db:"preferences" json:"preferences"`
 }
 
-func (q *Queries) UpdateChapter(ctx context.Context, arg UpdateChapterParams) (Chapter, error) {
-	row := q.db.QueryRow(ctx, updateChapter,
-		arg.ID,
-		arg.Title,
-		arg.Content,
-		arg.WordCount,
-		arg.Status,
-		arg.ID_2,
-		arg.UserID,
-	)
-	var i Chapter
-	err := row.Scan(
-		&i.ID,
-		&i.ProjectID,
-		&i.Type,
-		&i.Title,
-		&i.Content,
-		&i.WordCount,
-		&i.Status,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) UpsertProjectGenerationPreferences(ctx context.Context, arg UpsertProjectGenerationPreferencesParams) error {
+	_, err := q.db.Exec(ctx, upsertProjectGenerationPreferences, arg.ProjectID, arg.Preferences)
+	return err
 }
 
-const updateGeneratedDocument = `-- name: UpdateGeneratedDocument :one
-UPDATE generated_documents
-SET file_name = $2, file_path = $3, file_size = $4, mime_type = $5, status = $6
-WHERE id = $1
-RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at
+const upsertProjectGuideline = `-- name: UpsertProjectGuideline :one
+INSERT INTO project_guidelines (id, project_id, uploaded_by, file_name, file_path, mime_type, file_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id) DO UPDATE
+SET id = EXCLUDED.id, uploaded_by = EXCLUDED.uploaded_by, file_name = EXCLUDED.file_name,
+    file_path = EXCLUDED.file_path, mime_type = EXCLUDED.mime_type, file_size = EXCLUDED.file_size,
+    status = 'pending', error = '', rules = '{}'
+RETURNING id, project_id, uploaded_by, file_name, file_path, mime_type, file_size, status, error, rules, created_at, updated_at
 `
 
-type UpdateGeneratedDocumentParams struct {
-	ID       pgtype.UUID `db:"id" json:"id"`
-	FileName string      `db:"file_name" json:"file_name"`
-	FilePath string      `db:"file_path" json:"file_path"`
-	FileSize pgtype.Int8 `db:"file_size" json:"file_size"`
-	MimeType pgtype.Text `db:"mime_type" json:"mime_type"`
-	Status   pgtype.Text `db:"status" json:"status"`
+type UpsertProjectGuidelineParams struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	ProjectID  pgtype.UUID `db:"project_id" json:"project_id"`
+	UploadedBy pgtype.UUID `db:"uploaded_by" json:"uploaded_by"`
+	FileName   string      `db:"file_name" json:"file_name"`
+	FilePath   string      `db:"file_path" json:"file_path"`
+	MimeType   string      `db:"mime_type" json:"mime_type"`
+	FileSize   int64       `db:"file_size" json:"file_size"`
 }
 
-func (q *Queries) UpdateGeneratedDocument(ctx context.Context, arg UpdateGeneratedDocumentParams) (GeneratedDocument, error) {
-	row := q.db.QueryRow(ctx, updateGeneratedDocument,
+// Replacing a guideline gives it a new id, so extraction jobs for the old file find nothing
+func (q *Queries) UpsertProjectGuideline(ctx context.Context, arg UpsertProjectGuidelineParams) (ProjectGuideline, error) {
+	row := q.db.QueryRow(ctx, upsertProjectGuideline,
 		arg.ID,
+		arg.ProjectID,
+		arg.UploadedBy,
 		arg.FileName,
 		arg.FilePath,
-		arg.FileSize,
 		arg.MimeType,
-		arg.Status,
+		arg.FileSize,
 	)
-	var i GeneratedDocument
+	var i ProjectGuideline
 	err := row.Scan(
 		&i.ID,
 		&i.ProjectID,
+		&i.UploadedBy,
 		&i.FileName,
 		&i.FilePath,
-		&i.FileSize,
 		&i.MimeType,
+		&i.FileSize,
 		&i.Status,
+		&i.Error,
+		&i.Rules,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const updateGeneratedDocumentStatus = `-- name: UpdateGeneratedDocumentStatus :one
-UPDATE generated_documents
-SET status = $2
-WHERE id = $1
-RETURNING id, project_id, file_name, file_path, file_size, mime_type, status, created_at
+const upsertProjectMethodology = `-- name: UpsertProjectMethodology :one
+INSERT INTO project_methodology (project_id, answers, updated_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (project_id) DO UPDATE
+SET answers = EXCLUDED.answers, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING project_id, answers, updated_by, updated_at
 `
 
-type UpdateGeneratedDocumentStatusParams struct {
-	ID     pgtype.UUID `db:"id" json:"id"`
-	Status pgtype.Text `db:"status" json:"status"`
+type UpsertProjectMethodologyParams struct {
+	ProjectID pgtype.UUID `db:"project_id" json:"project_id"`
+	Answers   []byte      `db:"answers" json:"answers"`
+	UpdatedBy pgtype.UUID `db:"updated_by" json:"updated_by"`
 }
 
-func (q *Queries) UpdateGeneratedDocumentStatus(ctx context.Context, arg UpdateGeneratedDocumentStatusParams) (GeneratedDocument, error) {
-	row := q.db.QueryRow(ctx, updateGeneratedDocumentStatus, arg.ID, arg.Status)
-	var i GeneratedDocument
+func (q *Queries) UpsertProjectMethodology(ctx context.Context, arg UpsertProjectMethodologyParams) (ProjectMethodology, error) {
+	row := q.db.QueryRow(ctx, upsertProjectMethodology, arg.ProjectID, arg.Answers, arg.UpdatedBy)
+	var i ProjectMethodology
 	err := row.Scan(
-		&i.ID,
 		&i.ProjectID,
-		&i.FileName,
-		&i.FilePath,
-		&i.FileSize,
-		&i.MimeType,
-		&i.Status,
-		&i.CreatedAt,
+		&i.Answers,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const updateResearchProject = `-- name: UpdateResearchProject :one
-UPDATE research_projects
-SET title = $2, specialization = $3, university = $4, description = $5, status = $6, updated_at = NOW()
-WHERE id = $1 AND user_id = $7
-RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at
+const upsertProjectTimeline = `-- name: UpsertProjectTimeline :one
+INSERT INTO project_timelines (project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (project_id) DO UPDATE
+SET start_date = EXCLUDED.start_date, end_date = EXCLUDED.end_date, milestones = EXCLUDED.milestones, tasks = EXCLUDED.tasks,
+    include_in_document = EXCLUDED.include_in_document, generated_by = EXCLUDED.generated_by
+RETURNING id, project_id, start_date, end_date, milestones, tasks, include_in_document, generated_by, created_at, updated_at
 `
 
-type UpdateResearchProjectParams struct {
-	ID             pgtype.UUID `db:"id" json:"id"`
-	Title          string      `db:"title" json:"title"`
-	Specialization string      `db:"specialization" json:"specialization"`
-	University     pgtype.Text `db:"university" json:"university"`
-	Description    pgtype.Text `db:"description" json:"description"`
-	Status         pgtype.Text `db:"status" json:"status"`
-	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+type UpsertProjectTimelineParams struct {
+	ProjectID         pgtype.UUID `db:"project_id" json:"project_id"`
+	StartDate         pgtype.Date `db:"start_date" json:"start_date"`
+	EndDate           pgtype.Date `db:"end_date" json:"end_date"`
+	Milestones        []byte      `db:"milestones" json:"milestones"`
+	Tasks             []byte      `db:"tasks" json:"tasks"`
+	IncludeInDocument bool        `db:"include_in_document" json:"include_in_document"`
+	GeneratedBy       pgtype.UUID `db:"generated_by" json:"generated_by"`
 }
 
-func (q *Queries) UpdateResearchProject(ctx context.Context, arg UpdateResearchProjectParams) (ResearchProject, error) {
-	row := q.db.QueryRow(ctx, updateResearchProject,
-		arg.ID,
-		arg.Title,
-		arg.Specialization,
-		arg.University,
-		arg.Description,
-		arg.Status,
-		arg.UserID,
+// A regenerated timeline replaces the project's previous one
+func (q *Queries) UpsertProjectTimeline(ctx context.Context, arg UpsertProjectTimelineParams) (ProjectTimeline, error) {
+	row := q.db.QueryRow(ctx, upsertProjectTimeline,
+		arg.ProjectID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Milestones,
+		arg.Tasks,
+		arg.IncludeInDocument,
+		arg.GeneratedBy,
 	)
-	var i ResearchProject
+	var i ProjectTimeline
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.Specialization,
-		&i.University,
-		&i.Description,
-		&i.Status,
+		&i.ProjectID,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Milestones,
+		&i.Tasks,
+		&i.IncludeInDocument,
+		&i.GeneratedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const updateResearchProjectStatus = `-- name: UpdateResearchProjectStatus :one
-UPDATE research_projects
-SET status = $2, updated_at = NOW()
-WHERE id = $1 AND user_id = $3
-RETURNING id, user_id, title, specialization, university, description, status, created_at, updated_at
+const upsertReferenceEmbedding = `-- name: UpsertReferenceEmbedding :exec
+INSERT INTO reference_embeddings (reference_id, model, embedding)
+VALUES ($1, $2, $3::vector)
+ON CONFLICT (reference_id) DO UPDATE
+SET model = EXCLUDED.model, embedding = EXCLUDED.embedding, created_at = NOW()
 `
 
-type UpdateResearchProjectStatusParams struct {
-	ID     pgtype.UUID `db:"id" json:"id"`
-	Status pgtype.Text `db:"status" json:"status"`
-	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+type UpsertReferenceEmbeddingParams struct {
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+	Model       string      `db:"model" json:"model"`
+	Embedding   string      `db:"embedding" json:"embedding"`
 }
 
-func (q *Queries) UpdateResearchProjectStatus(ctx context.Context, arg UpdateResearchProjectStatusParams) (ResearchProject, error) {
-	row := q.db.QueryRow(ctx, updateResearchProjectStatus, arg.ID, arg.Status, arg.UserID)
-	var i ResearchProject
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.Specialization,
-		&i.University,
-		&i.Description,
-		&i.Status,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *Queries) UpsertReferenceEmbedding(ctx context.Context, arg UpsertReferenceEmbeddingParams) error {
+	_, err := q.db.Exec(ctx, upsertReferenceEmbedding, arg.ReferenceID, arg.Model, arg.Embedding)
+	return err
 }
 
-const updateUserVerificationStatus = `-- name: UpdateUserVerificationStatus :one
-UPDATE users
-SET is_verified = $2, updated_at = NOW()
-WHERE id = $1
-RETURNING id, email, password_hash, first_name, last_name, is_verified, created_at, updated_at
+const upsertReferenceNotes = `-- name: UpsertReferenceNotes :one
+INSERT INTO reference_notes (reference_id, project_id, notes, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (reference_id) DO UPDATE
+SET notes = EXCLUDED.notes, updated_by = EXCLUDED.updated_by
+RETURNING reference_id, project_id, notes, updated_by, created_at, updated_at
 `
 
-type UpdateUserVerificationStatusParams struct {
-	ID         pgtype.UUID `db:"id" json:"id"`
-	IsVerified pgtype.Bool `db:"is_verified" json:"is_verified"`
+type UpsertReferenceNotesParams struct {
+	ReferenceID pgtype.UUID `db:"reference_id" json:"reference_id"`
+	ProjectID   pgtype.UUID `db:"project_id" json:"project_id"`
+	Notes       string      `db:"notes" json:"notes"`
+	UpdatedBy   pgtype.UUID `db:"updated_by" json:"updated_by"`
 }
 
-func (q *Queries) UpdateUserVerificationStatus(ctx context.Context, arg UpdateUserVerificationStatusParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUserVerificationStatus, arg.ID, arg.IsVerified)
-	var i User
+func (q *Queries) UpsertReferenceNotes(ctx context.Context, arg UpsertReferenceNotesParams) (ReferenceNote, error) {
+	row := q.db.QueryRow(ctx, upsertReferenceNotes,
+		arg.ReferenceID,
+		arg.ProjectID,
+		arg.Notes,
+		arg.UpdatedBy,
+	)
+	var i ReferenceNote
 	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.PasswordHash,
-		&i.FirstName,
-		&i.LastName,
-		&i.IsVerified,
+		&i.ReferenceID,
+		&i.ProjectID,
+		&i.Notes,
+		&i.UpdatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const upsertUserGenerationPreferences = `-- name: UpsertUserGenerationPreferences :exec
+INSERT INTO user_generation_preferences (user_id, preferences)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET preferences = EXCLUDED.preferences, updated_at = NOW()
+`
+
+type UpsertUserGenerationPreferencesParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	Preferences []byte      `db:"preferences" json:"preferences"`
+}
+
+func (q *Queries) UpsertUserGenerationPreferences(ctx context.Context, arg UpsertUserGenerationPreferencesParams) error {
+	_, err := q.db.Exec(ctx, upsertUserGenerationPreferences, arg.UserID, arg.Preferences)
+	return err
+}
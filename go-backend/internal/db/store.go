@@ -1,6 +1,9 @@
 package db
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc" // Ensure this path is correct
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -9,25 +12,46 @@ import (
 // Store defines all functions to execute db queries and transactions
 type Store interface {
 	sqlc.Querier // Embeds all query methods from sqlc
-	// Add transaction methods here if needed, e.g., ExecTx(ctx context.Context, fn func(*sqlc.Queries) error) error
+	Ping(ctx context.Context) error
+	ExecTx(ctx context.Context, fn func(*sqlc.Queries) error) error
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
 type SQLStore struct {
 	*sqlc.Queries // Embeds all query methods from generated sqlc code
 	db            *pgxpool.Pool
+	replica       *pgxpool.Pool // Optional read replica; nil when reads go to the primary
 }
 
-// NewStore creates a new Store
-func NewStore(db *pgxpool.Pool) Store {
+// NewStore creates a new Store. When replica is not nil, read-only queries are
+// routed to it and writes and transactions go to db.
+func NewStore(db *pgxpool.Pool, replica *pgxpool.Pool) Store {
+	var dbtx sqlc.DBTX = db // sqlc.New expects a DBTX, which *pgxpool.Pool implements
+	if replica != nil {
+		dbtx = &routingDB{primary: db, replica: replica}
+	}
 	return &SQLStore{
-		Queries: sqlc.New(db), // sqlc.New expects a DBTX, which *pgxpool.Pool implements
+		Queries: sqlc.New(dbtx),
 		db:      db,
+		replica: replica,
+	}
+}
+
+// Ping checks that the database (and the replica, if configured) is reachable
+func (store *SQLStore) Ping(ctx context.Context) error {
+	if err := store.db.Ping(ctx); err != nil {
+		return err
+	}
+	if store.replica != nil {
+		if err := store.replica.Ping(ctx); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
 	}
+	return nil
 }
 
-// Example of a transaction method (add to Store interface as well)
-/*
+// ExecTx executes fn within a database transaction. The transaction is rolled back
+// if fn returns an error and committed otherwise.
 func (store *SQLStore) ExecTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
 	tx, err := store.db.Begin(ctx)
 	if err != nil {
@@ -43,4 +67,3 @@ func (store *SQLStore) ExecTx(ctx context.Context, fn func(*sqlc.Queries) error)
 
 	return tx.Commit(ctx)
 }
-*/
@@ -0,0 +1,130 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies what happened. Values are part of the WebSocket protocol.
+type Type string
+
+const (
+	GenerationStarted   Type = "generation.started"
+	GenerationProgress  Type = "generation.progress"
+	GenerationCompleted Type = "generation.completed"
+	GenerationFailed    Type = "generation.failed"
+	DocumentStarted     Type = "document.started"
+	DocumentReady       Type = "document.ready"
+	DocumentFailed      Type = "document.failed"
+
+	// Review workflow; Status is the review's new status
+	ReviewRequested Type = "review.requested"
+	ReviewDecided   Type = "review.decided"
+
+	// Raised by database triggers (see migration 000007) whenever a status column changes
+	ChapterStatusChanged  Type = "chapter.status_changed"
+	DocumentStatusChanged Type = "document.status_changed"
+)
+
+// Event is a progress notification for a long-running operation on a project
+type Event struct {
+	Type       Type       `json:"type"`
+	ProjectID  uuid.UUID  `json:"project_id"`
+	ChapterID  *uuid.UUID `json:"chapter_id,omitempty"`
+	DocumentID *uuid.UUID `json:"document_id,omitempty"`
+	Status     string     `json:"status,omitempty"` // New status, for *.status_changed events
+	Message    string     `json:"message,omitempty"`
+	Step       int        `json:"step,omitempty"`        // 1-based index of the finished step
+	TotalSteps int        `json:"total_steps,omitempty"` // Number of steps in the operation
+	Timestamp  time.Time  `json:"timestamp"`
+}
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before events are dropped
+const subscriberBuffer = 32
+
+// Bus fans events out to subscribers of a project. Publishing never blocks:
+// progress events are best effort and must not slow down generation.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+
+	// outbox is set for a Postgres-backed bus: published events are sent through
+	// NOTIFY and delivered locally when they come back on LISTEN
+	outbox chan Event
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel of events for projectID and a func to unsubscribe.
+// The channel is closed once unsubscribed.
+func (b *Bus) Subscribe(projectID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	if b.subscribers[projectID] == nil {
+		b.subscribers[projectID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[projectID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[projectID], ch)
+			if len(b.subscribers[projectID]) == 0 {
+				delete(b.subscribers, projectID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers e to every subscriber of e.ProjectID. A nil Bus is a no-op.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	if b.outbox != nil {
+		select {
+		case b.outbox <- e:
+			return
+		default: // NOTIFY is backed up; at least reach subscribers in this process
+		}
+	}
+	b.deliver(e)
+}
+
+func (b *Bus) deliver(e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers[e.ProjectID] {
+		select {
+		case ch <- e:
+		default: // Subscriber is too slow; drop rather than block the publisher
+		}
+	}
+}
+
+type emitterKey struct{}
+
+// WithEmitter returns a context carrying fn, so code deeper in the call chain
+// (e.g. AIService) can report progress without knowing which project it works for.
+func WithEmitter(ctx context.Context, fn func(Event)) context.Context {
+	return context.WithValue(ctx, emitterKey{}, fn)
+}
+
+// Emit reports e through the emitter attached to ctx, if any
+func Emit(ctx context.Context, e Event) {
+	if fn, ok := ctx.Value(emitterKey{}).(func(Event)); ok {
+		fn(e)
+	}
+}
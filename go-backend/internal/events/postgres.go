@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is the Postgres NOTIFY channel events travel on. Database triggers
+// publish status changes on it too.
+const Channel = "research_events"
+
+const (
+	outboxSize        = 256
+	maxPayloadBytes   = 7900 // NOTIFY payloads must stay below 8000 bytes
+	listenRetryDelay  = time.Second
+	maxListenRetryGap = 30 * time.Second
+)
+
+// NewPostgresBus returns a Bus shared by every process connected to the database:
+// Publish sends events with NOTIFY, and a dedicated LISTEN connection delivers
+// events from all processes (including database triggers) to local subscribers.
+// Background goroutines stop when ctx is cancelled.
+func NewPostgresBus(ctx context.Context, pool *pgxpool.Pool, logger *applogger.AppLogger) *Bus {
+	b := NewBus()
+	b.outbox = make(chan Event, outboxSize)
+	go b.notifyLoop(ctx, pool, logger)
+	go b.listenLoop(ctx, pool, logger)
+	return b
+}
+
+func (b *Bus) notifyLoop(ctx context.Context, pool *pgxpool.Pool, logger *applogger.AppLogger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-b.outbox:
+			payload, err := json.Marshal(e)
+			if err != nil || len(payload) > maxPayloadBytes {
+				// Too large for NOTIFY (e.g. a long message); local subscribers still get it
+				b.deliver(e)
+				continue
+			}
+			if _, err := pool.Exec(ctx, "SELECT pg_notify($1, $2)", Channel, string(payload)); err != nil {
+				logger.Warn("Failed to NOTIFY event, delivering locally", "type", e.Type, "error", err)
+				b.deliver(e)
+			}
+		}
+	}
+}
+
+// listenLoop keeps a LISTEN connection open, reconnecting with backoff.
+// Notifications sent while disconnected are lost, which is acceptable for progress events.
+func (b *Bus) listenLoop(ctx context.Context, pool *pgxpool.Pool, logger *applogger.AppLogger) {
+	delay := listenRetryDelay
+	for {
+		connected, err := b.listen(ctx, pool, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = listenRetryDelay
+		}
+		logger.Warn("Event listener disconnected, reconnecting", "error", err, "retry_in", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxListenRetryGap)
+	}
+}
+
+// listen reports whether it got as far as LISTENing, so the caller can reset its backoff
+func (b *Bus) listen(ctx context.Context, pool *pgxpool.Pool, logger *applogger.AppLogger) (bool, error) {
+	// A dedicated connection: LISTEN state must not leak into pooled connections
+	conn, err := pgx.ConnectConfig(ctx, pool.Config().ConnConfig.Copy())
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	logger.Info("Listening for events", "channel", Channel)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			logger.Warn("Dropping malformed event notification", "payload", n.Payload, "error", err)
+			continue
+		}
+		b.deliver(e)
+	}
+}
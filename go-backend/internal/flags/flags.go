@@ -0,0 +1,230 @@
+// Package flags holds runtime feature flags. A flag's value comes from, in order
+// of precedence: an override stored in the feature_flags table (set through the
+// admin API), the FEATURE_FLAGS setting, and the flag's built-in default.
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+)
+
+// Known flags. Add new flags here and to definitions.
+const (
+	EnableArxivProvider = "enable_arxiv_provider"
+	EnableNativeDocgen  = "enable_native_docgen"
+	EnableStreaming     = "enable_streaming"
+)
+
+type definition struct {
+	Default     bool
+	Description string
+}
+
+var definitions = map[string]definition{
+	EnableArxivProvider: {Default: false, Description: "Search arXiv when enriching references"},
+	EnableNativeDocgen:  {Default: false, Description: "Render documents in Go instead of calling the Python docgen service"},
+	EnableStreaming:     {Default: false, Description: "Stream AI generation output to clients as it is produced"},
+}
+
+// refreshInterval is how quickly overrides made on another instance are picked up
+const refreshInterval = 30 * time.Second
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Source says where a flag's current value comes from
+type Source string
+
+const (
+	SourceDefault  Source = "default"
+	SourceEnv      Source = "env"
+	SourceOverride Source = "override"
+)
+
+// Flag is the resolved state of one flag
+type Flag struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Enabled     bool       `json:"enabled"`
+	Source      Source     `json:"source" doc:"default, env or override"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" doc:"When the override was set"`
+}
+
+// Store is the subset of db.Store the flags need
+type Store interface {
+	ListFeatureFlags(ctx context.Context) ([]sqlc.FeatureFlag, error)
+	UpsertFeatureFlag(ctx context.Context, arg sqlc.UpsertFeatureFlagParams) (sqlc.FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, name string) error
+}
+
+type Flags struct {
+	store  Store
+	env    map[string]bool
+	logger *applogger.AppLogger
+
+	mu        sync.RWMutex
+	overrides map[string]sqlc.FeatureFlag
+}
+
+// New parses envSpec ("enable_streaming=true,enable_native_docgen") and loads
+// stored overrides. Unknown flag names in envSpec are rejected so typos surface at startup.
+func New(ctx context.Context, store Store, envSpec string, logger *applogger.AppLogger) (*Flags, error) {
+	env, err := parseEnv(envSpec)
+	if err != nil {
+		return nil, err
+	}
+	f := &Flags{store: store, env: env, logger: logger, overrides: map[string]sqlc.FeatureFlag{}}
+	if err := f.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func parseEnv(spec string) (map[string]bool, error) {
+	env := map[string]bool{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, raw, hasValue := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if _, ok := definitions[name]; !ok {
+			return nil, fmt.Errorf("FEATURE_FLAGS: %w %q", ErrUnknownFlag, name)
+		}
+		enabled := true
+		if hasValue {
+			v, err := strconv.ParseBool(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("FEATURE_FLAGS: invalid value for %s: %q", name, raw)
+			}
+			enabled = v
+		}
+		env[name] = enabled
+	}
+	return env, nil
+}
+
+// Enabled reports whether the named flag is on. Unknown names are off.
+// A nil *Flags reports every flag at its default.
+func (f *Flags) Enabled(name string) bool {
+	def, ok := definitions[name]
+	if !ok {
+		return false
+	}
+	if f == nil {
+		return def.Default
+	}
+	f.mu.RLock()
+	override, overridden := f.overrides[name]
+	f.mu.RUnlock()
+	if overridden {
+		return override.Enabled
+	}
+	if v, ok := f.env[name]; ok {
+		return v
+	}
+	return def.Default
+}
+
+// List returns every known flag with its current value, sorted by name
+func (f *Flags) List() []Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make([]Flag, 0, len(definitions))
+	for name := range definitions {
+		out = append(out, f.resolve(name))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// resolve must be called with f.mu held
+func (f *Flags) resolve(name string) Flag {
+	def := definitions[name]
+	flag := Flag{Name: name, Description: def.Description, Enabled: def.Default, Source: SourceDefault}
+	if v, ok := f.env[name]; ok {
+		flag.Enabled, flag.Source = v, SourceEnv
+	}
+	if override, ok := f.overrides[name]; ok {
+		updatedAt := override.UpdatedAt.Time
+		flag.Enabled, flag.Source, flag.UpdatedAt = override.Enabled, SourceOverride, &updatedAt
+	}
+	return flag
+}
+
+// Set stores a runtime override for the named flag
+func (f *Flags) Set(ctx context.Context, name string, enabled bool) (Flag, error) {
+	if _, ok := definitions[name]; !ok {
+		return Flag{}, ErrUnknownFlag
+	}
+	row, err := f.store.UpsertFeatureFlag(ctx, sqlc.UpsertFeatureFlagParams{Name: name, Enabled: enabled})
+	if err != nil {
+		return Flag{}, fmt.Errorf("could not save feature flag: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides[name] = row
+	f.logger.Info("Feature flag overridden", "flag", name, "enabled", enabled)
+	return f.resolve(name), nil
+}
+
+// Reset removes the runtime override, returning the flag to its env/default value
+func (f *Flags) Reset(ctx context.Context, name string) (Flag, error) {
+	if _, ok := definitions[name]; !ok {
+		return Flag{}, ErrUnknownFlag
+	}
+	if err := f.store.DeleteFeatureFlag(ctx, name); err != nil {
+		return Flag{}, fmt.Errorf("could not reset feature flag: %w", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.overrides, name)
+	f.logger.Info("Feature flag override removed", "flag", name)
+	return f.resolve(name), nil
+}
+
+// Refresh reloads overrides from the database
+func (f *Flags) Refresh(ctx context.Context) error {
+	rows, err := f.store.ListFeatureFlags(db.WithPrimary(ctx))
+	if err != nil {
+		return fmt.Errorf("could not load feature flags: %w", err)
+	}
+	overrides := make(map[string]sqlc.FeatureFlag, len(rows))
+	for _, row := range rows {
+		if _, ok := definitions[row.Name]; !ok {
+			continue // Left behind by a removed flag
+		}
+		overrides[row.Name] = row
+	}
+	f.mu.Lock()
+	f.overrides = overrides
+	f.mu.Unlock()
+	return nil
+}
+
+// Watch refreshes overrides periodically so toggles made through another
+// instance take effect here too. It returns when ctx is cancelled.
+func (f *Flags) Watch(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := f.Refresh(ctx); err != nil {
+				f.logger.Error("Failed to refresh feature flags", "error", err)
+			}
+		}
+	}
+}
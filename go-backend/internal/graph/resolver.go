@@ -0,0 +1,37 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
+
+import (
+	"context"
+	"errors"
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+
+	"github.com/google/uuid"
+)
+
+// Resolver is the root resolver. It holds the services the field resolvers delegate to.
+type Resolver struct {
+	ResearchService *services.ResearchService
+	Logger          *applogger.AppLogger
+}
+
+type userIDContextKey struct{}
+
+// ErrUnauthenticated is returned by resolvers when no user is attached to the request context
+var ErrUnauthenticated = errors.New("authentication required")
+
+// WithUserID attaches the authenticated user's ID to ctx for the resolvers
+func WithUserID(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDContextKey{}, userID)
+}
+
+func userIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := ctx.Value(userIDContextKey{}).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, ErrUnauthenticated
+	}
+	return userID, nil
+}
@@ -0,0 +1,112 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+)
+
+// Chapters is the resolver for the chapters field.
+func (r *projectResolver) Chapters(ctx context.Context, obj *models.ProjectResponse) ([]*models.ChapterResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chapters, err := r.ResearchService.GetProjectChapters(ctx, obj.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*models.ChapterResponse, 0, len(chapters))
+	for _, ch := range chapters {
+		chapter := models.ToChapterResponse(ch)
+		resp = append(resp, &chapter)
+	}
+	return resp, nil
+}
+
+// References is the resolver for the references field.
+func (r *projectResolver) References(ctx context.Context, obj *models.ProjectResponse) ([]*models.ReferenceResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.ResearchService.GetProjectReferences(ctx, obj.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]*models.ReferenceResponse, 0, len(refs))
+	for _, ref := range refs {
+		reference := models.ToReferenceResponse(ref)
+		resp = append(resp, &reference)
+	}
+	return resp, nil
+}
+
+// Projects is the resolver for the projects field.
+func (r *queryResolver) Projects(ctx context.Context) ([]*models.ProjectResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := r.ResearchService.GetUserProjects(ctx, userID)
+	if err != nil {
+		r.Logger.Error("GraphQL: failed to list projects", "userID", userID, "error", err)
+		return nil, err
+	}
+	resp := make([]*models.ProjectResponse, 0, len(projects))
+	for _, p := range projects {
+		project := models.ToProjectResponse(p)
+		resp = append(resp, &project)
+	}
+	return resp, nil
+}
+
+// Project is the resolver for the project field.
+func (r *queryResolver) Project(ctx context.Context, id uuid.UUID) (*models.ProjectResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	project, err := r.ResearchService.GetUserProjectByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resp := models.ToProjectResponse(project)
+	return &resp, nil
+}
+
+// Chapter is the resolver for the chapter field.
+func (r *queryResolver) Chapter(ctx context.Context, projectID uuid.UUID, id uuid.UUID) (*models.ChapterResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chapter, err := r.ResearchService.GetChapterByID(ctx, id, projectID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) || errors.Is(err, services.ErrChapterNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resp := models.ToChapterResponse(chapter)
+	return &resp, nil
+}
+
+// Project returns ProjectResolver implementation.
+func (r *Resolver) Project() ProjectResolver { return &projectResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type projectResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
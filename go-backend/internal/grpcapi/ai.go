@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"context"
+
+	researchv1 "github.com/shawgichan/research-service/go-backend/api/proto/research/v1"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type aiServer struct {
+	researchv1.UnimplementedAIServiceServer
+	aiService *services.AIService
+	logger    *applogger.AppLogger
+}
+
+func (s *aiServer) GenerateLiteratureReview(ctx context.Context, req *researchv1.GenerateLiteratureReviewRequest) (*researchv1.GenerateLiteratureReviewResponse, error) {
+	if req.GetTitle() == "" || req.GetSpecialization() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and specialization are required")
+	}
+	content, refs, err := s.aiService.GenerateLiteratureReview(ctx, req.GetTitle(), req.GetSpecialization(), services.ChapterOptions{}, nil, nil, nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &researchv1.GenerateLiteratureReviewResponse{Content: content}
+	for _, ref := range refs {
+		if ref != nil {
+			resp.References = append(resp.References, toProtoReference(*ref))
+		}
+	}
+	return resp, nil
+}
+
+func (s *aiServer) GenerateIntroduction(ctx context.Context, req *researchv1.GenerateIntroductionRequest) (*researchv1.GenerateIntroductionResponse, error) {
+	if req.GetTitle() == "" || req.GetSpecialization() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and specialization are required")
+	}
+	content, err := s.aiService.GenerateIntroduction(ctx, req.GetTitle(), req.GetSpecialization(), services.ChapterOptions{}, req.GetLiteratureReviewSummary())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &researchv1.GenerateIntroductionResponse{Content: content}, nil
+}
+
+func (s *aiServer) GenerateMethodology(ctx context.Context, req *researchv1.GenerateMethodologyRequest) (*researchv1.GenerateMethodologyResponse, error) {
+	if req.GetTitle() == "" || req.GetSpecialization() == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and specialization are required")
+	}
+	content, err := s.aiService.GenerateMethodologyTemplate(ctx, req.GetTitle(), req.GetSpecialization(), services.ChapterOptions{}, researchTypeAnswers(req.GetResearchType()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &researchv1.GenerateMethodologyResponse{Content: content}, nil
+}
+
+// researchTypeAnswers carries the request's free-text research type as the approach of
+// the methodology questionnaire; without one the AI writes a template of placeholders
+func researchTypeAnswers(researchType string) *apimodels.MethodologyAnswers {
+	if researchType == "" {
+		return nil
+	}
+	return &apimodels.MethodologyAnswers{Approach: researchType}
+}
@@ -0,0 +1,73 @@
+package grpcapi
+
+import (
+	"time"
+
+	researchv1 "github.com/shawgichan/research-service/go-backend/api/proto/research/v1"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The converters start from the API response models so REST, GraphQL and gRPC
+// agree on how nullable columns are flattened.
+
+func toProtoProject(p apimodels.ProjectResponse) *researchv1.Project {
+	return &researchv1.Project{
+		Id:             p.ID.String(),
+		UserId:         p.UserID.String(),
+		Title:          p.Title,
+		Specialization: p.Specialization,
+		University:     p.University,
+		Description:    p.Description,
+		Status:         p.Status,
+		CreatedAt:      toTimestamp(p.CreatedAt),
+		UpdatedAt:      toTimestamp(p.UpdatedAt),
+	}
+}
+
+func toProtoChapter(ch apimodels.ChapterResponse) *researchv1.Chapter {
+	return &researchv1.Chapter{
+		Id:        ch.ID.String(),
+		ProjectId: ch.ProjectID.String(),
+		Type:      ch.Type,
+		Title:     ch.Title,
+		Content:   ch.Content,
+		WordCount: ch.WordCount,
+		Status:    ch.Status,
+		CreatedAt: toTimestamp(ch.CreatedAt),
+		UpdatedAt: toTimestamp(ch.UpdatedAt),
+	}
+}
+
+func toProtoReference(ref apimodels.ReferenceResponse) *researchv1.Reference {
+	return &researchv1.Reference{
+		Id:              uuidString(ref.ID),
+		ProjectId:       uuidString(ref.ProjectID),
+		Title:           ref.Title,
+		Authors:         ref.Authors,
+		Journal:         ref.Journal,
+		PublicationYear: int32(ref.PublicationYear),
+		Doi:             ref.DOI,
+		Url:             ref.URL,
+		CitationApa:     ref.CitationAPA,
+		CitationMla:     ref.CitationMLA,
+		CreatedAt:       toTimestamp(ref.CreatedAt),
+	}
+}
+
+// uuidString returns "" for the zero UUID (e.g. AI-suggested references not yet saved)
+func uuidString(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
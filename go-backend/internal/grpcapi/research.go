@@ -0,0 +1,128 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	researchv1 "github.com/shawgichan/research-service/go-backend/api/proto/research/v1"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type researchServer struct {
+	researchv1.UnimplementedResearchServiceServer
+	researchService *services.ResearchService
+	logger          *applogger.AppLogger
+}
+
+func (s *researchServer) GetProject(ctx context.Context, req *researchv1.GetProjectRequest) (*researchv1.GetProjectResponse, error) {
+	userID, projectID, err := parseUserAndProject(req.GetUserId(), req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.researchService.GetUserProjectByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	chapters, err := s.researchService.GetProjectChapters(ctx, projectID, userID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	references, err := s.researchService.GetProjectReferences(ctx, projectID, userID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := toProtoProject(apimodels.ToProjectResponse(project))
+	for _, ch := range chapters {
+		resp.Chapters = append(resp.Chapters, toProtoChapter(apimodels.ToChapterResponseWithOptions(ch, !req.GetExcludeContent())))
+	}
+	for _, ref := range references {
+		resp.References = append(resp.References, toProtoReference(apimodels.ToReferenceResponse(ref)))
+	}
+	return &researchv1.GetProjectResponse{Project: resp}, nil
+}
+
+func (s *researchServer) ListChapters(ctx context.Context, req *researchv1.ListChaptersRequest) (*researchv1.ListChaptersResponse, error) {
+	userID, projectID, err := parseUserAndProject(req.GetUserId(), req.GetProjectId())
+	if err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.researchService.GetProjectChapters(ctx, projectID, userID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &researchv1.ListChaptersResponse{Chapters: make([]*researchv1.Chapter, 0, len(chapters))}
+	for _, ch := range chapters {
+		resp.Chapters = append(resp.Chapters, toProtoChapter(apimodels.ToChapterResponseWithOptions(ch, !req.GetExcludeContent())))
+	}
+	return resp, nil
+}
+
+func (s *researchServer) ReportDocumentStatus(ctx context.Context, req *researchv1.ReportDocumentStatusRequest) (*researchv1.ReportDocumentStatusResponse, error) {
+	docID, err := uuid.Parse(req.GetDocumentId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid document_id")
+	}
+	switch req.GetStatus() {
+	case "processing", "completed", "failed":
+	default:
+		return nil, status.Error(codes.InvalidArgument, "status must be one of processing, completed, failed")
+	}
+
+	if err := s.researchService.UpdateDocumentStatus(ctx, docID, req.GetStatus(), req.GetMessage()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &researchv1.ReportDocumentStatusResponse{}, nil
+}
+
+func parseUserAndProject(userIDStr, projectIDStr string) (uuid.UUID, uuid.UUID, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+	projectID, err := uuid.Parse(projectIDStr)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, status.Error(codes.InvalidArgument, "invalid project_id")
+	}
+	return userID, projectID, nil
+}
+
+// toStatus maps service sentinel errors onto gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrChapterNotFound),
+		errors.Is(err, services.ErrReferenceNotFound),
+		errors.Is(err, services.ErrDocumentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrInsufficientRole):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrChapterInReview),
+		errors.Is(err, services.ErrReviewIsRequired),
+		errors.Is(err, services.ErrIntegrityAcknowledgmentRequired),
+		errors.Is(err, services.ErrBatchGenerationBlocked):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrChapterAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrEmptyAfterSanitizing),
+		errors.Is(err, services.ErrUnknownChapterType):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrQuotaExceeded),
+		errors.Is(err, services.ErrQuotaReached):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
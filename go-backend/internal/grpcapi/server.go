@@ -0,0 +1,84 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	researchv1 "github.com/shawgichan/research-service/go-backend/api/proto/research/v1"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/util"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationMetadataKey = "authorization"
+	authorizationTypeBearer  = "bearer"
+)
+
+// NewServer builds the internal gRPC server exposing the research and AI services.
+// Every call except health checks must carry "authorization: Bearer <GRPC_AUTH_TOKEN>".
+func NewServer(config util.Config, researchService *services.ResearchService, aiService *services.AIService, logger *applogger.AppLogger) (*grpc.Server, error) {
+	if config.GRPCAuthToken == "" {
+		return nil, errors.New("GRPC_AUTH_TOKEN must be set when GRPC_ENABLED is true")
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			loggingInterceptor(logger),
+			authInterceptor(config.GRPCAuthToken),
+		),
+	)
+	researchv1.RegisterResearchServiceServer(server, &researchServer{researchService: researchService, logger: logger})
+	researchv1.RegisterAIServiceServer(server, &aiServer{aiService: aiService, logger: logger})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	return server, nil
+}
+
+// authInterceptor checks the shared internal token on every call
+func authInterceptor(authToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(authorizationMetadataKey)
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is not provided")
+		}
+		fields := strings.Fields(values[0])
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+		if subtle.ConstantTimeCompare([]byte(fields[1]), []byte(authToken)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// loggingInterceptor logs failed calls at the same levels the HTTP access log uses
+func loggingInterceptor(logger *applogger.AppLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			code := status.Code(err)
+			switch code {
+			case codes.Internal, codes.Unknown, codes.Unavailable:
+				logger.Error("gRPC call failed", "method", info.FullMethod, "code", code.String(), "error", err)
+			default:
+				logger.Warn("gRPC call rejected", "method", info.FullMethod, "code", code.String(), "error", err)
+			}
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,471 @@
+// Package jobs runs background work from the jobs table. Work is enqueued as a
+// kind plus a JSON payload and picked up by whichever instance polls first, so
+// it survives restarts and spreads across replicas. A failed run is retried with
+// exponential backoff until the kind's MaxAttempts, after which the job is left
+// dead for an operator to inspect and requeue through the admin API.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusDead      = "dead" // Out of attempts, or failed permanently
+)
+
+// KindPurgeCompleted deletes completed jobs older than Config.Retention
+const KindPurgeCompleted = "jobs.purge_completed"
+
+const (
+	defaultMaxAttempts = 5
+	defaultTimeout     = 5 * time.Minute
+	firstRetryDelay    = 30 * time.Second
+	maxRetryDelay      = time.Hour
+	errorMaxLen        = 2000
+)
+
+var (
+	ErrUnknownKind = errors.New("unknown job kind")
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is running")
+)
+
+// Job is one row of the jobs table
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status" doc:"pending, running, completed or dead"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at" doc:"When a pending job is due; while running, when its lease runs out"`
+	LastError   string          `json:"last_error,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func toJob(j sqlc.Job) Job {
+	job := Job{
+		ID:          j.ID.Bytes,
+		Kind:        j.Kind,
+		Payload:     j.Payload,
+		Status:      j.Status,
+		Attempts:    int(j.Attempts),
+		MaxAttempts: int(j.MaxAttempts),
+		RunAt:       j.RunAt.Time,
+		LastError:   j.LastError,
+		CreatedAt:   j.CreatedAt.Time,
+		UpdatedAt:   j.UpdatedAt.Time,
+	}
+	if j.CompletedAt.Valid {
+		job.CompletedAt = &j.CompletedAt.Time
+	}
+	return job
+}
+
+// LastAttempt reports whether a failure of the current run makes the job dead.
+// Handlers use it to clean up only once no retry is coming.
+func (j Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// Decode unmarshals the job's payload into v
+func (j Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("invalid %s payload: %w", j.Kind, err))
+	}
+	return nil
+}
+
+// HandlerFunc runs one attempt of a job. Returning an error schedules a retry,
+// unless it is wrapped with Permanent or the job is on its last attempt.
+type HandlerFunc func(ctx context.Context, job Job) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, such as a deleted project,
+// so the job goes straight to dead
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Options tune how one kind of job is run
+type Options struct {
+	MaxAttempts int           // Defaults to 5
+	Timeout     time.Duration // Per attempt, defaults to 5 minutes
+}
+
+type kind struct {
+	opts    Options
+	handler HandlerFunc
+}
+
+type periodic struct {
+	kind     string
+	interval time.Duration
+}
+
+// Store is the subset of db.Store the queue needs
+type Store interface {
+	EnqueueJob(ctx context.Context, arg sqlc.EnqueueJobParams) (sqlc.Job, error)
+	ClaimDueJobs(ctx context.Context, arg sqlc.ClaimDueJobsParams) ([]sqlc.Job, error)
+	CompleteJob(ctx context.Context, arg sqlc.CompleteJobParams) error
+	FailJob(ctx context.Context, arg sqlc.FailJobParams) error
+	GetJob(ctx context.Context, id pgtype.UUID) (sqlc.Job, error)
+	ListJobs(ctx context.Context, arg sqlc.ListJobsParams) ([]sqlc.Job, error)
+	RequeueJob(ctx context.Context, id pgtype.UUID) (sqlc.Job, error)
+	DeleteCompletedJobs(ctx context.Context, completedAt pgtype.Timestamptz) (int64, error)
+}
+
+// Config controls how the queue polls and how long finished jobs are kept
+type Config struct {
+	PollInterval time.Duration // How often due jobs are claimed when none are enqueued locally
+	Concurrency  int           // Jobs run at once by this instance
+	Retention    time.Duration // Completed jobs are deleted after this; dead jobs are kept
+}
+
+type Queue struct {
+	store  Store
+	cfg    Config
+	logger *applogger.AppLogger
+
+	mu       sync.RWMutex
+	kinds    map[string]kind
+	periodic []periodic
+
+	slots chan struct{} // One token per running job
+	wake  chan struct{} // Nudges Run to poll right after a local Enqueue
+}
+
+func New(store Store, cfg Config, logger *applogger.AppLogger) *Queue {
+	q := &Queue{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+		kinds:  make(map[string]kind),
+		slots:  make(chan struct{}, max(cfg.Concurrency, 1)),
+		wake:   make(chan struct{}, 1),
+	}
+	q.Register(KindPurgeCompleted, Options{MaxAttempts: 1}, func(ctx context.Context, _ Job) error {
+		cutoff := pgtype.Timestamptz{Time: time.Now().Add(-cfg.Retention), Valid: true}
+		deleted, err := store.DeleteCompletedJobs(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			logger.Info("Purged completed jobs", "count", deleted)
+		}
+		return nil
+	})
+	q.Periodic(KindPurgeCompleted, time.Hour)
+	return q
+}
+
+// Register sets the handler for a kind. Kinds must be registered before Run and
+// before anything enqueues them; an instance only claims the kinds it knows.
+func (q *Queue) Register(name string, opts Options, handler HandlerFunc) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.kinds[name] = kind{opts: opts, handler: handler}
+}
+
+// Periodic enqueues a registered kind, without a payload, once per interval.
+// Intervals are aligned to the clock so replicas enqueue the same run only once.
+func (q *Queue) Periodic(name string, interval time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.periodic = append(q.periodic, periodic{kind: name, interval: interval})
+}
+
+// Enqueue adds a job to run as soon as a worker is free
+func (q *Queue) Enqueue(ctx context.Context, name string, payload any) error {
+	return q.enqueue(ctx, name, payload, "", time.Now())
+}
+
+// EnqueueIn is Enqueue for a job that only becomes due after delay
+func (q *Queue) EnqueueIn(ctx context.Context, name string, delay time.Duration, payload any) error {
+	return q.enqueue(ctx, name, payload, "", time.Now().Add(delay))
+}
+
+// EnqueueUnique is Enqueue, unless a job with uniqueKey was enqueued before, in which case it does nothing
+func (q *Queue) EnqueueUnique(ctx context.Context, name, uniqueKey string, payload any) error {
+	return q.enqueue(ctx, name, payload, uniqueKey, time.Now())
+}
+
+func (q *Queue) enqueue(ctx context.Context, name string, payload any, uniqueKey string, runAt time.Time) error {
+	q.mu.RLock()
+	k, ok := q.kinds[name]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, name)
+	}
+	data := []byte("{}")
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("could not encode %s payload: %w", name, err)
+		}
+	}
+	_, err := q.store.EnqueueJob(ctx, sqlc.EnqueueJobParams{
+		Kind:        name,
+		Payload:     data,
+		MaxAttempts: int32(k.opts.MaxAttempts),
+		RunAt:       pgtype.Timestamptz{Time: runAt, Valid: true},
+		UniqueKey:   pgtype.Text{String: uniqueKey, Valid: uniqueKey != ""},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) { // uniqueKey already taken
+			return nil
+		}
+		return fmt.Errorf("could not enqueue %s job: %w", name, err)
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run claims and runs due jobs until ctx is cancelled, then waits for running jobs to return.
+// Cancelling ctx cancels their contexts too; interrupted jobs are retried like failed ones.
+func (q *Queue) Run(ctx context.Context) {
+	q.mu.RLock()
+	kinds := make([]string, 0, len(q.kinds))
+	for name := range q.kinds {
+		kinds = append(kinds, name)
+	}
+	schedules := append([]periodic(nil), q.periodic...)
+	q.mu.RUnlock()
+	sort.Strings(kinds)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for _, p := range schedules {
+		wg.Add(1)
+		go func(p periodic) {
+			defer wg.Done()
+			q.schedule(ctx, p)
+		}(p)
+	}
+
+	ticker := time.NewTicker(q.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		q.poll(ctx, kinds, &wg)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+// poll claims as many due jobs as there are free slots and starts them
+func (q *Queue) poll(ctx context.Context, kinds []string, wg *sync.WaitGroup) {
+	free := cap(q.slots) - len(q.slots)
+	if free == 0 || ctx.Err() != nil {
+		return
+	}
+	batch, err := q.store.ClaimDueJobs(ctx, sqlc.ClaimDueJobsParams{
+		LeasedUntil: pgtype.Timestamptz{Time: time.Now().Add(q.lease()), Valid: true},
+		Kinds:       kinds,
+		BatchSize:   int32(free),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			q.logger.Error("Failed to claim jobs", "error", err)
+		}
+		return
+	}
+	for _, row := range batch {
+		q.slots <- struct{}{}
+		wg.Add(1)
+		go func(job Job) {
+			defer func() {
+				<-q.slots
+				wg.Done()
+			}()
+			q.run(ctx, job)
+		}(toJob(row))
+	}
+}
+
+// lease is how long a claimed job is reserved for: long enough for the slowest kind's timeout
+func (q *Queue) lease() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	longest := defaultTimeout
+	for _, k := range q.kinds {
+		longest = max(longest, k.opts.Timeout)
+	}
+	return longest + time.Minute
+}
+
+// run runs one attempt of a claimed job and records the outcome
+func (q *Queue) run(ctx context.Context, job Job) {
+	q.mu.RLock()
+	k := q.kinds[job.Kind]
+	q.mu.RUnlock()
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// Claimed again after its worker died during the final attempt
+		err = Permanent(errors.New("worker stopped during the final attempt"))
+	} else {
+		start := time.Now()
+		err = q.call(ctx, k, job)
+		if err == nil {
+			q.logger.Debug("Job completed", "jobID", job.ID, "kind", job.Kind, "attempt", job.Attempts, "duration", time.Since(start))
+		}
+	}
+
+	// Recorded even when ctx was cancelled by shutdown
+	recordCtx := context.WithoutCancel(ctx)
+	id := pgtype.UUID{Bytes: job.ID, Valid: true}
+	if err == nil {
+		if err := q.store.CompleteJob(recordCtx, sqlc.CompleteJobParams{ID: id, Attempts: int32(job.Attempts)}); err != nil {
+			q.logger.Error("Failed to mark job completed", "jobID", job.ID, "kind", job.Kind, "error", err)
+		}
+		return
+	}
+
+	params := sqlc.FailJobParams{
+		Status:    StatusPending,
+		RunAt:     pgtype.Timestamptz{Time: time.Now().Add(retryDelay(job.Attempts)), Valid: true},
+		LastError: truncate(err.Error(), errorMaxLen),
+		ID:        id,
+		Attempts:  int32(job.Attempts),
+	}
+	var permanent permanentError
+	if job.LastAttempt() || errors.As(err, &permanent) {
+		params.Status = StatusDead
+		q.logger.Error("Job failed for good", "jobID", job.ID, "kind", job.Kind, "attempt", job.Attempts, "error", err)
+	} else {
+		q.logger.Warn("Job failed, will retry", "jobID", job.ID, "kind", job.Kind, "attempt", job.Attempts, "retryAt", params.RunAt.Time, "error", err)
+	}
+	if err := q.store.FailJob(recordCtx, params); err != nil {
+		q.logger.Error("Failed to record job failure", "jobID", job.ID, "kind", job.Kind, "error", err)
+	}
+}
+
+// call runs the handler under the kind's timeout, turning a panic into an error
+func (q *Queue) call(ctx context.Context, k kind, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, k.opts.Timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return k.handler(ctx, job)
+}
+
+// schedule enqueues a periodic kind at the start of every interval
+func (q *Queue) schedule(ctx context.Context, p periodic) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		slot := time.Now().Truncate(p.interval)
+		uniqueKey := fmt.Sprintf("%s@%d", p.kind, slot.Unix())
+		if err := q.EnqueueUnique(ctx, p.kind, uniqueKey, nil); err != nil && ctx.Err() == nil {
+			q.logger.Error("Failed to schedule periodic job", "kind", p.kind, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// List returns the most recent jobs, newest first, optionally filtered by status and kind
+func (q *Queue) List(ctx context.Context, status, kind string, limit int) ([]Job, error) {
+	rows, err := q.store.ListJobs(ctx, sqlc.ListJobsParams{
+		Status:   pgtype.Text{String: status, Valid: status != ""},
+		Kind:     pgtype.Text{String: kind, Valid: kind != ""},
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("database error listing jobs: %w", err)
+	}
+	jobs := make([]Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = toJob(row)
+	}
+	return jobs, nil
+}
+
+func (q *Queue) Get(ctx context.Context, id uuid.UUID) (Job, error) {
+	row, err := q.store.GetJob(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Job{}, ErrJobNotFound
+		}
+		return Job{}, fmt.Errorf("database error fetching job: %w", err)
+	}
+	return toJob(row), nil
+}
+
+// Requeue runs a job again from its first attempt, typically a dead one once its cause is fixed
+func (q *Queue) Requeue(ctx context.Context, id uuid.UUID) (Job, error) {
+	row, err := q.store.RequeueJob(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := q.Get(db.WithPrimary(ctx), id); err != nil {
+				return Job{}, err
+			}
+			return Job{}, ErrJobRunning
+		}
+		return Job{}, fmt.Errorf("database error requeueing job: %w", err)
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return toJob(row), nil
+}
+
+// retryDelay is the wait after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen]
+}
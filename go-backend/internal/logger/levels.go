@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultComponent names the level used by components without their own setting
+const DefaultComponent = "default"
+
+var componentPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// Levels maps components to minimum log levels. Components are dot separated and
+// inherit from their parent: "services.ai" falls back to "services", then to the default.
+// Reads are lock free so checking a level on every log call stays cheap.
+type Levels struct {
+	mu       sync.Mutex // Serialises writers
+	snapshot atomic.Pointer[levelSnapshot]
+}
+
+type levelSnapshot struct {
+	defaultLevel slog.Level
+	components   map[string]slog.Level
+}
+
+func NewLevels(defaultLevel slog.Level) *Levels {
+	l := &Levels{}
+	l.snapshot.Store(&levelSnapshot{defaultLevel: defaultLevel, components: map[string]slog.Level{}})
+	return l
+}
+
+// Level returns the effective level for component
+func (l *Levels) Level(component string) slog.Level {
+	s := l.snapshot.Load()
+	for component != "" {
+		if level, ok := s.components[component]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(component, '.')
+		if i < 0 {
+			break
+		}
+		component = component[:i]
+	}
+	return s.defaultLevel
+}
+
+// Set changes the level of a component, or the default level for DefaultComponent
+func (l *Levels) Set(component string, level slog.Level) error {
+	if component != DefaultComponent && !componentPattern.MatchString(component) {
+		return fmt.Errorf("invalid component name %q", component)
+	}
+	l.update(func(s *levelSnapshot) {
+		if component == DefaultComponent {
+			s.defaultLevel = level
+		} else {
+			s.components[component] = level
+		}
+	})
+	return nil
+}
+
+// Reset removes a component's own level so it inherits again
+func (l *Levels) Reset(component string) {
+	l.update(func(s *levelSnapshot) { delete(s.components, component) })
+}
+
+// All returns the default level and every component override, by name
+func (l *Levels) All() map[string]string {
+	s := l.snapshot.Load()
+	out := map[string]string{DefaultComponent: LevelName(s.defaultLevel)}
+	for component, level := range s.components {
+		out[component] = LevelName(level)
+	}
+	return out
+}
+
+// Configure applies a spec like "info,services.ai=debug,db=warn"; a bare level sets the default
+func (l *Levels) Configure(spec string) error {
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		component, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			component, raw = DefaultComponent, item
+		}
+		level, err := ParseLevel(raw)
+		if err != nil {
+			return err
+		}
+		if err := l.Set(strings.TrimSpace(component), level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Levels) update(fn func(*levelSnapshot)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cur := l.snapshot.Load()
+	next := &levelSnapshot{defaultLevel: cur.defaultLevel, components: maps.Clone(cur.components)}
+	fn(next)
+	l.snapshot.Store(next)
+}
+
+// ParseLevel accepts debug, info, warn(ing) and error in any case
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", s)
+	}
+}
+
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
@@ -1,32 +1,75 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
 )
 
 type AppLogger struct {
 	*slog.Logger
+	levels *Levels
+	out    *fanout
 }
 
 func New() *AppLogger {
 	var handler slog.Handler
+	defaultLevel := slog.LevelInfo
 	env := os.Getenv("ENVIRONMENT")
+	// The handlers let everything through; per-component levels do the filtering
+	opts := &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: redactAttr,
+	}
+	out := &fanout{} // Stdout until AddSinks adds more
 	if env == "development" {
-		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})
+		defaultLevel = slog.LevelDebug
+		handler = slog.NewTextHandler(out, opts)
 	} else {
-		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelInfo,
-		})
+		handler = slog.NewJSONHandler(out, opts)
+	}
+	levels := NewLevels(defaultLevel)
+	logger := slog.New(&componentHandler{Handler: handler, levels: levels})
+	return &AppLogger{Logger: logger, levels: levels, out: out}
+}
+
+// For returns a logger for a component such as "api", "services.ai" or "db".
+// Its records carry a component attribute and are filtered by the component's level.
+func (l *AppLogger) For(component string) *AppLogger {
+	h := l.Logger.Handler()
+	if ch, ok := h.(*componentHandler); ok {
+		h = &componentHandler{Handler: ch.Handler, component: component, levels: ch.levels}
 	}
-	logger := slog.New(handler)
-	return &AppLogger{Logger: logger}
+	return &AppLogger{Logger: slog.New(h).With("component", component), levels: l.levels, out: l.out}
+}
+
+// Levels returns the level registry shared by this logger and everything derived from it
+func (l *AppLogger) Levels() *Levels {
+	return l.levels
 }
 
 func (l *AppLogger) Fatal(msg string, err error, args ...any) {
 	allArgs := append(args, "error", err)
 	l.Error(msg, allArgs...)
+	l.Close() // Flush shipped logs so the reason for the exit is not lost
 	os.Exit(1)
 }
+
+// componentHandler applies the current level of its component before handing records on
+type componentHandler struct {
+	slog.Handler
+	component string
+	levels    *Levels
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.Level(h.component) && h.Handler.Enabled(ctx, level)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &componentHandler{Handler: h.Handler.WithAttrs(attrs), component: h.component, levels: h.levels}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{Handler: h.Handler.WithGroup(name), component: h.component, levels: h.levels}
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redaction runs on every attribute before it is written, including attributes
+// bound with With and the message itself, so call sites need no special care.
+// Secrets are removed, emails keep only their first letter and domain, and
+// generated content is replaced by its length.
+
+const (
+	redacted = "[REDACTED]"
+	// maxBodyLength caps upstream response bodies; error bodies are short, content is not
+	maxBodyLength = 512
+)
+
+// secretKeys and the suffixes below match keys after lowercasing and removing '_' and '-'
+var secretKeys = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"password":      true,
+	"passwd":        true,
+	"secret":        true,
+	"token":         true,
+	"apikey":        true,
+}
+
+var secretSuffixes = []string{"token", "secret", "password", "apikey"}
+
+// contentKeys hold user or AI generated text, which can be long and personal
+var contentKeys = map[string]bool{
+	"content":        true,
+	"chaptercontent": true,
+	"prompt":         true,
+	"text":           true,
+}
+
+var bodyKeys = map[string]bool{
+	"body":         true,
+	"responsebody": true,
+	"requestbody":  true,
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=\-]+`)
+	// OpenAI/Groq style keys, AWS access key IDs and PASETO tokens
+	apiKeyPattern = regexp.MustCompile(`\b(sk-[A-Za-z0-9_\-]{16,}|gsk_[A-Za-z0-9]{16,}|AKIA[0-9A-Z]{16}|v2\.local\.[A-Za-z0-9_\-]+)`)
+	// user:password@ in connection strings
+	urlPasswordPattern = regexp.MustCompile(`(://[^:/@\s]+:)[^@\s]+@`)
+)
+
+// redactAttr is used as the handlers' ReplaceAttr
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey || a.Key == slog.LevelKey {
+		return a
+	}
+	key := normalizeKey(a.Key)
+	switch {
+	case isSecretKey(key):
+		return slog.String(a.Key, redacted)
+	case contentKeys[key]:
+		if s, ok := stringValue(a.Value); ok {
+			return slog.String(a.Key, fmt.Sprintf("[REDACTED %d chars]", len(s)))
+		}
+		return a
+	case strings.Contains(key, "email"):
+		if s, ok := stringValue(a.Value); ok {
+			return slog.String(a.Key, maskEmail(s))
+		}
+		return a
+	}
+
+	s, ok := stringValue(a.Value)
+	if !ok {
+		return a
+	}
+	s = RedactString(s)
+	if bodyKeys[key] && len(s) > maxBodyLength {
+		s = fmt.Sprintf("%s... [truncated, %d chars]", s[:maxBodyLength], len(s))
+	}
+	return slog.String(a.Key, s)
+}
+
+// RedactString masks emails, bearer tokens, API keys and URL passwords inside free text
+func RedactString(s string) string {
+	if strings.Contains(s, "@") {
+		s = urlPasswordPattern.ReplaceAllString(s, "${1}"+redacted+"@")
+		s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	}
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redacted)
+	s = apiKeyPattern.ReplaceAllString(s, redacted)
+	return s
+}
+
+// maskEmail keeps the first character and the domain: j***@example.com
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redacted
+	}
+	return local[:1] + "***@" + domain
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+func isSecretKey(key string) bool {
+	if secretKeys[key] {
+		return true
+	}
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns the text of string and error values, which are the kinds that can carry sensitive data
+func stringValue(v slog.Value) (string, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String(), true
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error(), true
+		case []byte:
+			return string(x), true
+		}
+	}
+	return "", false
+}
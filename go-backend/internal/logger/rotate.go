@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const rotatedTimeFormat = "20060102T150405.000"
+
+// rotatingFile appends to a file and renames it aside once it reaches maxSize.
+// Rotated files are named <name>-<timestamp><ext> next to the original.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups, maxAge: maxAge}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("cannot stat log file: %w", err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate must be called with r.mu held
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(r.path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(r.path, ext), time.Now().UTC().Format(rotatedTimeFormat))
+	rotated := base + ext
+	for i := 1; fileExists(rotated); i++ { // Several rotations within one millisecond
+		rotated = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+	if err := os.Rename(r.path, rotated); err != nil {
+		return fmt.Errorf("cannot rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	go r.prune()
+	return nil
+}
+
+// prune removes rotated files beyond maxBackups or older than maxAge
+func (r *rotatingFile) prune() {
+	ext := filepath.Ext(r.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(r.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+	// The timestamp format sorts chronologically; newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+	for i, name := range matches {
+		expired := false
+		if r.maxAge > 0 {
+			if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > r.maxAge {
+				expired = true
+			}
+		}
+		if (r.maxBackups > 0 && i >= r.maxBackups) || expired {
+			os.Remove(name)
+		}
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
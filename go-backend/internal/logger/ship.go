@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	shipQueueSize     = 10000 // Lines held while the backend is slow or down; newer lines are dropped beyond this
+	shipBatchSize     = 500
+	shipFlushInterval = 2 * time.Second
+	shipTimeout       = 10 * time.Second
+)
+
+type shippedLine struct {
+	at   time.Time
+	line []byte
+}
+
+// shipper batches log lines and posts them in the background so a slow
+// backend never blocks request handling. Failed batches are dropped.
+type shipper struct {
+	name    string
+	send    func(batch []shippedLine) error
+	queue   chan shippedLine
+	dropped atomic.Int64
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newShipper(name string, send func([]shippedLine) error) *shipper {
+	s := &shipper{name: name, send: send, queue: make(chan shippedLine, shipQueueSize), done: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+func (s *shipper) Write(p []byte) (int, error) {
+	// The handler reuses its buffer after Write returns
+	line := shippedLine{at: time.Now(), line: bytes.TrimRight(bytes.Clone(p), "\n")}
+	select {
+	case s.queue <- line:
+	default:
+		s.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+func (s *shipper) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(shipFlushInterval)
+	defer ticker.Stop()
+	batch := make([]shippedLine, 0, shipBatchSize)
+	flush := func() {
+		if n := s.dropped.Swap(0); n > 0 {
+			fmt.Fprintf(os.Stderr, "logger: %s queue full, dropped %d lines\n", s.name, n)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.send(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: %s shipment of %d lines failed: %v\n", s.name, len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case line, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= shipBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close sends what is queued and waits up to shipTimeout for it to go out
+func (s *shipper) Close() error {
+	s.once.Do(func() { close(s.queue) })
+	select {
+	case <-s.done:
+		return nil
+	case <-time.After(shipTimeout):
+		return fmt.Errorf("%s: timed out flushing logs", s.name)
+	}
+}
+
+// endpoint splits credentials out of a sink URL so they are sent as basic auth
+type endpoint struct {
+	url      string
+	user     *url.Userinfo
+	client   *http.Client
+	apiKey   string
+	mimeType string
+}
+
+func newEndpoint(raw, path, mimeType string) (*endpoint, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		// url.Error embeds the raw URL, which may carry credentials
+		return nil, fmt.Errorf("invalid log sink URL")
+	}
+	user := u.User
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	return &endpoint{url: u.String(), user: user, client: &http.Client{Timeout: shipTimeout}, mimeType: mimeType}, nil
+}
+
+func (e *endpoint) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", e.mimeType)
+	if e.user != nil {
+		password, _ := e.user.Password()
+		req.SetBasicAuth(e.user.Username(), password)
+	}
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	// The bulk API reports per-document failures with a 200
+	if bytes.Contains(respBody, []byte(`"errors":true`)) {
+		return fmt.Errorf("some documents were rejected")
+	}
+	return nil
+}
+
+// newLokiSink ships lines to Loki's push API as a single stream with the given labels
+func newLokiSink(rawURL string, labels map[string]string) (*shipper, error) {
+	ep, err := newEndpoint(rawURL, "/loki/api/v1/push", "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("loki: %w", err)
+	}
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	return newShipper("loki", func(batch []shippedLine) error {
+		values := make([][2]string, len(batch))
+		for i, l := range batch {
+			values[i] = [2]string{strconv.FormatInt(l.at.UnixNano(), 10), string(l.line)}
+		}
+		body, err := json.Marshal(map[string][]stream{"streams": {{Stream: labels, Values: values}}})
+		if err != nil {
+			return err
+		}
+		return ep.post(body)
+	}), nil
+}
+
+// newElasticsearchSink indexes each line as a document through the bulk API.
+// JSON lines are indexed as they are; text lines are wrapped in a message field.
+func newElasticsearchSink(rawURL, index, apiKey string, labels map[string]string) (*shipper, error) {
+	ep, err := newEndpoint(rawURL, "/_bulk", "application/x-ndjson")
+	if err != nil {
+		return nil, fmt.Errorf("elasticsearch: %w", err)
+	}
+	if index == "" {
+		return nil, fmt.Errorf("elasticsearch: index is required")
+	}
+	ep.apiKey = apiKey
+	// "create" works for both plain indices and data streams
+	action, err := json.Marshal(map[string]map[string]string{"create": {"_index": index}})
+	if err != nil {
+		return nil, err
+	}
+	return newShipper("elasticsearch", func(batch []shippedLine) error {
+		var body bytes.Buffer
+		for _, l := range batch {
+			doc := map[string]any{}
+			if err := json.Unmarshal(l.line, &doc); err != nil {
+				doc = map[string]any{"message": string(l.line)}
+			}
+			doc["@timestamp"] = l.at.UTC().Format(time.RFC3339Nano)
+			for k, v := range labels {
+				if _, taken := doc[k]; !taken {
+					doc[k] = v
+				}
+			}
+			encoded, err := json.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			body.Write(action)
+			body.WriteByte('\n')
+			body.Write(encoded)
+			body.WriteByte('\n')
+		}
+		return ep.post(body.Bytes())
+	}), nil
+}
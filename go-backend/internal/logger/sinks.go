@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SinkConfig selects where logs go in addition to stdout. Empty fields disable a sink.
+type SinkConfig struct {
+	// Rotating file
+	FilePath       string
+	FileMaxSizeMB  int
+	FileMaxBackups int           // Rotated files to keep; 0 keeps all
+	FileMaxAge     time.Duration // Rotated files older than this are removed; 0 keeps all
+
+	// Log shipping. Credentials may be given as user:password@ in the URL.
+	LokiURL             string            // Base URL, e.g. http://loki:3100
+	ElasticsearchURL    string            // Base URL, e.g. https://es:9200
+	ElasticsearchIndex  string            // Index or data stream to write to
+	ElasticsearchAPIKey string            // Sent as "Authorization: ApiKey ..." when set
+	Labels              map[string]string // Loki stream labels, also added to Elasticsearch documents
+}
+
+// fanout copies every log line to stdout and the configured sinks.
+// slog handlers write one whole record per Write call, so each sink sees complete lines.
+type fanout struct {
+	mu    sync.RWMutex
+	sinks []io.Writer
+}
+
+func (f *fanout) Write(p []byte) (int, error) {
+	n, err := os.Stdout.Write(p)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, sink := range f.sinks {
+		if _, sinkErr := sink.Write(p); sinkErr != nil {
+			// The logger cannot log its own failures
+			os.Stderr.WriteString("logger: sink write failed: " + sinkErr.Error() + "\n")
+		}
+	}
+	return n, err
+}
+
+func (f *fanout) add(sink io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sinks = append(f.sinks, sink)
+}
+
+func (f *fanout) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var errs []error
+	for _, sink := range f.sinks {
+		if c, ok := sink.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	f.sinks = nil
+	return errors.Join(errs...)
+}
+
+// AddSinks starts the sinks in cfg. It is called once the config is loaded;
+// everything logged before then goes to stdout only.
+func (l *AppLogger) AddSinks(cfg SinkConfig) error {
+	var sinks []io.Writer
+	if cfg.FilePath != "" {
+		file, err := newRotatingFile(cfg.FilePath, int64(cfg.FileMaxSizeMB)<<20, cfg.FileMaxBackups, cfg.FileMaxAge)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, file)
+	}
+	if cfg.LokiURL != "" {
+		loki, err := newLokiSink(cfg.LokiURL, cfg.Labels)
+		if err != nil {
+			return errors.Join(err, closeAll(sinks))
+		}
+		sinks = append(sinks, loki)
+	}
+	if cfg.ElasticsearchURL != "" {
+		es, err := newElasticsearchSink(cfg.ElasticsearchURL, cfg.ElasticsearchIndex, cfg.ElasticsearchAPIKey, cfg.Labels)
+		if err != nil {
+			return errors.Join(err, closeAll(sinks))
+		}
+		sinks = append(sinks, es)
+	}
+	for _, sink := range sinks {
+		l.out.add(sink)
+	}
+	return nil
+}
+
+// Close flushes buffered log shipments and closes the log file
+func (l *AppLogger) Close() error {
+	return l.out.Close()
+}
+
+func closeAll(sinks []io.Writer) error {
+	var errs []error
+	for _, sink := range sinks {
+		if c, ok := sink.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,163 @@
+// Package mail renders transactional emails from templates and sends them through
+// an SMTP relay, Amazon SES or SendGrid, whichever MAIL_PROVIDER selects
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSES      = "ses"
+	ProviderSendGrid = "sendgrid"
+)
+
+// Message is an email to a single recipient. Body is plain text; HTML, when set,
+// is sent alongside it as an alternative for clients that render HTML.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures the provider
+type Config struct {
+	Provider string // smtp, ses or sendgrid; empty turns email off
+	From     string // e.g. "Research Service <no-reply@example.com>"
+	SMTP     SMTPConfig
+	SES      SESConfig
+	SendGrid SendGridConfig
+}
+
+// NewSender returns the configured provider's sender, or nil when email is off
+func NewSender(cfg Config) (Sender, error) {
+	var (
+		sender Sender
+		err    error
+	)
+	// Each case assigns through a typed variable so a failed constructor never yields a non-nil Sender
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderSMTP:
+		smtpCfg := cfg.SMTP
+		smtpCfg.From = cfg.From
+		var s *SMTPSender
+		if s, err = NewSMTPSender(smtpCfg); err == nil {
+			sender = s
+		}
+	case ProviderSES:
+		var s *SESSender
+		if s, err = NewSESSender(cfg.SES, cfg.From); err == nil {
+			sender = s
+		}
+	case ProviderSendGrid:
+		var s *SendGridSender
+		if s, err = NewSendGridSender(cfg.SendGrid, cfg.From); err == nil {
+			sender = s
+		}
+	default:
+		err = fmt.Errorf("unknown mail provider %q", cfg.Provider)
+	}
+	return sender, err
+}
+
+// SMTPConfig locates the relay. Username and Password are optional; when set,
+// PLAIN auth is used, which net/smtp only allows over TLS or to localhost.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string // e.g. "Research Service <no-reply@example.com>"
+}
+
+type SMTPSender struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTPSender(cfg SMTPConfig) (*SMTPSender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", cfg.From, err)
+	}
+	return &SMTPSender{cfg: cfg, from: from}, nil
+}
+
+// Send delivers msg. net/smtp has no context support, so ctx is only checked up front.
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	if err := smtp.SendMail(addr, auth, s.from.Address, []string{to.Address}, s.build(to, msg)); err != nil {
+		return fmt.Errorf("smtp send to %s: %w", addr, err)
+	}
+	return nil
+}
+
+func (s *SMTPSender) build(to *mail.Address, msg Message) []byte {
+	var b strings.Builder
+	header := func(k, v string) { b.WriteString(k + ": " + v + "\r\n") }
+	header("From", s.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "8bit")
+		b.WriteString("\r\n")
+		// SMTP wants CRLF line endings in the body too
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+		return []byte(b.String())
+	}
+
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	b.WriteString("\r\n")
+	// Least preferred first, per RFC 2046
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Body},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		qp := quotedprintable.NewWriter(w) // Also turns line breaks into CRLF
+		qp.Write([]byte(part.content))
+		qp.Close()
+	}
+	mw.Close()
+	b.Write(parts.Bytes())
+	return []byte(b.String())
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridConfig holds an API key with the Mail Send permission
+type SendGridConfig struct {
+	APIKey string
+}
+
+// SendGridSender sends through the SendGrid v3 Mail Send API
+type SendGridSender struct {
+	apiKey string
+	from   *mail.Address
+	client *http.Client
+}
+
+func NewSendGridSender(cfg SendGridConfig, from string) (*SendGridSender, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+	return &SendGridSender{
+		apiKey: cfg.APIKey,
+		from:   fromAddr,
+		client: telemetry.NewHTTPClient(&http.Client{Timeout: 30 * time.Second}),
+	}, nil
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"` // text/plain must come first
+}
+
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to.Address, Name: to.Name}}}},
+		From:             sendGridAddress{Email: s.from.Address, Name: s.from.Name},
+		Subject:          msg.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: msg.Body}},
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid send: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		message := strings.TrimSpace(string(respBody))
+		if json.Unmarshal(respBody, &apiErr) == nil && len(apiErr.Errors) > 0 {
+			message = apiErr.Errors[0].Message
+		}
+		return fmt.Errorf("sendgrid send: %s (status %d)", message, resp.StatusCode)
+	}
+	return nil
+}
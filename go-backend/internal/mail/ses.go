@@ -0,0 +1,154 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+)
+
+// SESConfig holds the credentials of an IAM user allowed to call ses:SendEmail
+type SESConfig struct {
+	Region          string // e.g. eu-west-1
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// SESSender sends through the Amazon SES v2 API, signing requests with AWS Signature Version 4
+type SESSender struct {
+	cfg      SESConfig
+	from     *mail.Address
+	endpoint string
+	client   *http.Client
+}
+
+func NewSESSender(cfg SESConfig, from string) (*SESSender, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", from, err)
+	}
+	return &SESSender{
+		cfg:      cfg,
+		from:     fromAddr,
+		endpoint: "https://email." + cfg.Region + ".amazonaws.com/v2/email/outbound-emails",
+		client:   telemetry.NewHTTPClient(&http.Client{Timeout: 30 * time.Second}),
+	}, nil
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesSendRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				Text *sesContent `json:"Text,omitempty"`
+				Html *sesContent `json:"Html,omitempty"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+func (s *SESSender) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	var payload sesSendRequest
+	payload.FromEmailAddress = s.from.String()
+	payload.Destination.ToAddresses = []string{to.String()}
+	simple := &payload.Content.Simple
+	simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	simple.Body.Text = &sesContent{Data: msg.Body, Charset: "UTF-8"}
+	if msg.HTML != "" {
+		simple.Body.Html = &sesContent{Data: msg.HTML, Charset: "UTF-8"}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, body, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ses send: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(respBody, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(respBody))
+		}
+		return fmt.Errorf("ses send: %s (status %d)", apiErr.Message, resp.StatusCode)
+	}
+	return nil
+}
+
+// sign adds AWS Signature Version 4 headers for the ses service
+func (s *SESSender) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+			"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), day)
+	for _, part := range []string{s.cfg.Region, "ses", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
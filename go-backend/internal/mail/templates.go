@@ -0,0 +1,222 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Transactional email templates
+const (
+	TemplateVerification    = "verification"     // ActionURL confirms the address, valid for ExpiresIn
+	TemplatePasswordReset   = "password_reset"   // ActionURL sets a new password, valid for ExpiresIn
+	TemplateInvitation      = "invitation"       // Title is the organization, Body who invited them; ActionURL or Code accepts, valid for ExpiresIn
+	TemplateDocumentReady   = "document_ready"   // Title and Body from the notification
+	TemplateReviewRequested = "review_requested" // Title and Body from the notification
+	TemplateNotification    = "notification"     // Any other notification
+)
+
+// TemplateData fills a template; each template uses the fields it needs
+type TemplateData struct {
+	Name      string // Recipient's first name, may be empty
+	Title     string
+	Body      string
+	ActionURL string // Link the email asks the recipient to open, may be empty for notifications
+	ExpiresIn string // How long ActionURL stays valid, e.g. "24 hours"
+	Code      string // One-time code to enter in the app when there is no ActionURL
+}
+
+type templateSource struct {
+	subject string
+	text    string
+	html    string // The content block of htmlLayout
+}
+
+// notificationFooter ends every notification email; emails the user cannot turn off leave it out
+const notificationFooter = "You can change which emails you receive in your notification settings."
+
+var templateSources = map[string]templateSource{
+	TemplateVerification: {
+		subject: "Confirm your email address",
+		text: `{{greeting .Name}}
+
+Confirm your email address by opening this link:
+{{.ActionURL}}
+
+The link expires in {{.ExpiresIn}}. If you did not create an account, you can ignore this email.
+`,
+		html: `<p>{{greeting .Name}}</p>
+<p>Confirm your email address to finish setting up your account.</p>
+<p><a class="button" href="{{.ActionURL}}">Confirm email address</a></p>
+<p class="muted">The link expires in {{.ExpiresIn}}. If you did not create an account, you can ignore this email.</p>`,
+	},
+	TemplatePasswordReset: {
+		subject: "Reset your password",
+		text: `{{greeting .Name}}
+
+Someone asked to reset the password of your account. Choose a new password here:
+{{.ActionURL}}
+
+The link expires in {{.ExpiresIn}}. If it was not you, ignore this email; your password stays the same.
+`,
+		html: `<p>{{greeting .Name}}</p>
+<p>Someone asked to reset the password of your account.</p>
+<p><a class="button" href="{{.ActionURL}}">Choose a new password</a></p>
+<p class="muted">The link expires in {{.ExpiresIn}}. If it was not you, ignore this email; your password stays the same.</p>`,
+	},
+	TemplateInvitation: {
+		subject: "You are invited to join {{.Title}}",
+		text: `{{greeting .Name}}
+
+{{.Body}}
+{{if .ActionURL}}
+Accept the invitation by opening this link:
+{{.ActionURL}}
+{{else}}
+Accept the invitation in the app with this code:
+{{.Code}}
+{{end}}
+The invitation expires in {{.ExpiresIn}}. If you were not expecting it, you can ignore this email.
+`,
+		html: `<p>{{greeting .Name}}</p>
+<p>{{.Body}}</p>
+{{if .ActionURL}}<p><a class="button" href="{{.ActionURL}}">Accept the invitation</a></p>{{else}}<p>Accept the invitation in the app with this code:</p>
+<p><code>{{.Code}}</code></p>{{end}}
+<p class="muted">The invitation expires in {{.ExpiresIn}}. If you were not expecting it, you can ignore this email.</p>`,
+	},
+	TemplateDocumentReady: {
+		subject: "{{.Title}}",
+		text: `{{greeting .Name}}
+
+{{.Title}}
+{{if .Body}}
+{{.Body}}
+{{end}}
+Open the project to download it.{{if .ActionURL}}
+{{.ActionURL}}{{end}}
+
+` + notificationFooter + "\n",
+		html: `<p>{{greeting .Name}}</p>
+<h2>{{.Title}}</h2>
+{{if .Body}}<p>{{.Body}}</p>{{end}}
+{{if .ActionURL}}<p><a class="button" href="{{.ActionURL}}">Download</a></p>{{else}}<p>Open the project to download it.</p>{{end}}
+<p class="muted">` + notificationFooter + `</p>`,
+	},
+	TemplateReviewRequested: {
+		subject: "{{.Title}}",
+		text: `{{greeting .Name}}
+
+{{.Title}}
+{{if .Body}}
+{{.Body}}
+{{end}}
+Open the project to review the chapter.{{if .ActionURL}}
+{{.ActionURL}}{{end}}
+
+` + notificationFooter + "\n",
+		html: `<p>{{greeting .Name}}</p>
+<h2>{{.Title}}</h2>
+{{if .Body}}<p>{{.Body}}</p>{{end}}
+{{if .ActionURL}}<p><a class="button" href="{{.ActionURL}}">Review the chapter</a></p>{{else}}<p>Open the project to review the chapter.</p>{{end}}
+<p class="muted">` + notificationFooter + `</p>`,
+	},
+	TemplateNotification: {
+		subject: "{{.Title}}",
+		text: `{{greeting .Name}}
+
+{{.Title}}
+{{if .Body}}
+{{.Body}}
+{{end}}{{if .ActionURL}}
+{{.ActionURL}}
+{{end}}
+` + notificationFooter + "\n",
+		html: `<p>{{greeting .Name}}</p>
+<h2>{{.Title}}</h2>
+{{if .Body}}<p>{{.Body}}</p>{{end}}
+{{if .ActionURL}}<p><a class="button" href="{{.ActionURL}}">Open</a></p>{{end}}
+<p class="muted">` + notificationFooter + `</p>`,
+	},
+}
+
+// htmlLayout wraps every HTML body; inline styles, as many clients drop <style>
+const htmlLayout = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="margin:0;padding:24px;background:#f5f5f5;font-family:Arial,Helvetica,sans-serif;color:#222;line-height:1.5">
+<div style="max-width:560px;margin:0 auto;background:#fff;padding:24px;border-radius:6px">
+{{.Content}}
+</div>
+</body>
+</html>
+`
+
+var templateFuncs = map[string]any{
+	"greeting": func(name string) string {
+		if name == "" {
+			return "Hi,"
+		}
+		return "Hi " + name + ","
+	},
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+var (
+	templates = compileTemplates()
+	layout    = htmltemplate.Must(htmltemplate.New("layout").Parse(htmlLayout))
+)
+
+func compileTemplates() map[string]compiledTemplate {
+	compiled := make(map[string]compiledTemplate, len(templateSources))
+	for name, src := range templateSources {
+		// Classes are replaced by inline styles at render time, see inlineStyles
+		compiled[name] = compiledTemplate{
+			subject: texttemplate.Must(texttemplate.New(name).Funcs(templateFuncs).Parse(src.subject)),
+			text:    texttemplate.Must(texttemplate.New(name).Funcs(templateFuncs).Parse(src.text)),
+			html:    htmltemplate.Must(htmltemplate.New(name).Funcs(templateFuncs).Parse(src.html)),
+		}
+	}
+	return compiled
+}
+
+// inlineStyles are substituted for the class attributes used by the templates
+var inlineStyles = strings.NewReplacer(
+	`class="button"`, `style="display:inline-block;padding:10px 18px;background:#1a56db;color:#fff;text-decoration:none;border-radius:4px"`,
+	`class="muted"`, `style="color:#777;font-size:13px"`,
+)
+
+// Render fills the named template for one recipient
+func Render(to, name string, data TemplateData) (Message, error) {
+	t, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+	var subject, text, content, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := t.html.Execute(&content, data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+	err := layout.Execute(&html, struct {
+		Subject string
+		Content htmltemplate.HTML
+	}{
+		Subject: subject.String(),
+		Content: htmltemplate.HTML(inlineStyles.Replace(content.String())), // Escaped by t.html already
+	})
+	if err != nil {
+		return Message{}, fmt.Errorf("render %s layout: %w", name, err)
+	}
+	return Message{To: to, Subject: strings.TrimSpace(subject.String()), Body: text.String(), HTML: html.String()}, nil
+}
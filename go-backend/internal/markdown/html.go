@@ -0,0 +1,430 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ToHTML renders CommonMark as HTML. Raw HTML in the source is escaped and shown as
+// text rather than passed through, and links and images to unsafe URLs are rendered
+// as their text, so the output is safe to embed whatever the source.
+func ToHTML(src string) string {
+	lines := strings.Split(Normalize(src), "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	r := &renderer{refs: map[string]linkRef{}}
+	blocks := r.parseBlocks(lines)
+	var b strings.Builder
+	r.renderBlocks(&b, blocks)
+	return b.String()
+}
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	ruleBlock
+)
+
+type block struct {
+	kind  blockKind
+	text  string // Inline source of a paragraph or heading, or the content of a code block
+	level int    // Heading level
+	info  string // Info string of a fenced code block
+
+	ordered bool
+	start   int
+	tight   bool
+	items   [][]*block
+	// Children of a blockquote
+	children []*block
+}
+
+type linkRef struct {
+	dest, title string
+}
+
+type renderer struct {
+	// Link reference definitions, by normalized label; they apply document-wide
+	refs map[string]linkRef
+}
+
+// parseBlocks splits lines into blocks, collecting link reference definitions
+func (r *renderer) parseBlocks(lines []string) []*block {
+	var blocks []*block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			i++
+			continue
+		}
+		indent := indentation(line)
+		if indent >= 4 { // Indented code
+			var code []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				code = append(code, stripIndent(lines[i], 4))
+			}
+			for isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, &block{kind: codeBlock, text: strings.Join(code, "\n") + "\n"})
+			continue
+		}
+		if f, ok := openingFence(line); ok {
+			var code strings.Builder
+			for i++; i < len(lines) && !closesFence(lines[i], f); i++ {
+				code.WriteString(stripIndent(lines[i], f.indent) + "\n")
+			}
+			i++ // The closing fence
+			blocks = append(blocks, &block{kind: codeBlock, text: code.String(), info: f.info})
+			continue
+		}
+		rest := line[indent:]
+		if level, text, ok := atxHeading(rest); ok {
+			blocks = append(blocks, &block{kind: headingBlock, level: level, text: text})
+			i++
+			continue
+		}
+		if isThematicBreak(line) {
+			blocks = append(blocks, &block{kind: ruleBlock})
+			i++
+			continue
+		}
+		if rest[0] == '>' {
+			var inner []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if ind := indentation(l); ind < 4 && strings.HasPrefix(l[ind:], ">") {
+					inner = append(inner, strings.TrimPrefix(l[ind+1:], " "))
+					continue
+				}
+				// A lazy continuation line of a paragraph
+				if isBlank(l) || isBlank(inner[len(inner)-1]) || interruptsParagraph(l) {
+					break
+				}
+				inner = append(inner, l)
+			}
+			blocks = append(blocks, &block{kind: quoteBlock, children: r.parseBlocks(inner)})
+			continue
+		}
+		if m, ok := parseListMarker(line); ok {
+			var list *block
+			list, i = r.parseList(lines, i, m)
+			blocks = append(blocks, list)
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !isBlank(lines[end]) && setextLevel(lines[end]) == 0 && !interruptsParagraph(lines[end]) {
+			end++
+		}
+		para := r.takeDefinitions(lines[i:end])
+		i = end
+		if len(para) == 0 {
+			continue
+		}
+		var text strings.Builder
+		for k, l := range para {
+			if k > 0 {
+				text.WriteByte('\n')
+			}
+			text.WriteString(strings.TrimLeft(l, " "))
+		}
+		paragraph := &block{kind: paragraphBlock, text: strings.TrimRight(text.String(), " ")}
+		if i < len(lines) {
+			if level := setextLevel(lines[i]); level > 0 {
+				paragraph.kind, paragraph.level = headingBlock, level
+				i++
+			}
+		}
+		blocks = append(blocks, paragraph)
+	}
+	return blocks
+}
+
+// parseList reads the items of a list starting at lines[i], returning it and the
+// index of the line after it
+func (r *renderer) parseList(lines []string, i int, first listMarker) (*block, int) {
+	list := &block{kind: listBlock, ordered: first.ordered, start: first.start, tight: true}
+	for i < len(lines) {
+		m, ok := parseListMarker(lines[i])
+		if !ok || m.ordered != first.ordered || m.char != first.char || isThematicBreak(lines[i]) {
+			break
+		}
+		content := []string{lines[i][min(m.width, len(lines[i])):]}
+		internalBlank := false
+	item:
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			last := content[len(content)-1]
+			switch {
+			case isBlank(l):
+				if len(content) == 1 && isBlank(last) {
+					break item // An item can begin with at most one blank line
+				}
+				content = append(content, "")
+			case indentation(l) >= m.width:
+				internalBlank = internalBlank || isBlank(last)
+				content = append(content, l[m.width:])
+			case isListItem(l):
+				break item
+			case !isBlank(last) && !interruptsParagraph(l): // A lazy continuation line
+				content = append(content, l)
+			default:
+				break item
+			}
+		}
+		trailingBlank := false
+		for len(content) > 1 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+			trailingBlank = true
+		}
+		item := r.parseBlocks(content)
+		list.items = append(list.items, item)
+		if internalBlank && len(item) > 1 {
+			list.tight = false
+		}
+		if next, ok := parseListMarker(lineAt(lines, i)); trailingBlank && ok && next.ordered == m.ordered && next.char == m.char {
+			list.tight = false
+		}
+	}
+	return list, i
+}
+
+var definitionPattern = regexp.MustCompile(`^ {0,3}\[((?:[^\\\[\]]|\\.)+)\]:[ \t]*(<[^<>]*>|\S+)(?:[ \t]+("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\((?:[^()\\]|\\.)*\)))?[ \t]*$`)
+
+// takeDefinitions records the link reference definitions a paragraph starts with,
+// returning the lines after them
+func (r *renderer) takeDefinitions(para []string) []string {
+	for len(para) > 0 {
+		m := definitionPattern.FindStringSubmatch(para[0])
+		if m == nil || strings.TrimSpace(m[1]) == "" {
+			break
+		}
+		label := normalizeLabel(m[1])
+		if _, seen := r.refs[label]; !seen { // The first definition wins
+			dest := strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">")
+			title := m[3]
+			if title != "" {
+				title = title[1 : len(title)-1]
+			}
+			r.refs[label] = linkRef{dest: unescapeLink(dest), title: unescapeLink(title)}
+		}
+		para = para[1:]
+	}
+	return para
+}
+
+func (r *renderer) renderBlocks(b *strings.Builder, blocks []*block) {
+	for _, bl := range blocks {
+		switch bl.kind {
+		case paragraphBlock:
+			b.WriteString("<p>" + r.inline(bl.text) + "</p>\n")
+		case headingBlock:
+			tag := "h" + strconv.Itoa(bl.level)
+			b.WriteString("<" + tag + ">" + r.inline(bl.text) + "</" + tag + ">\n")
+		case codeBlock:
+			b.WriteString("<pre><code")
+			if lang, _, _ := strings.Cut(bl.info, " "); lang != "" {
+				b.WriteString(` class="language-` + escapeHTML(unescapeLink(lang)) + `"`)
+			}
+			b.WriteString(">" + escapeHTML(bl.text) + "</code></pre>\n")
+		case quoteBlock:
+			b.WriteString("<blockquote>\n")
+			r.renderBlocks(b, bl.children)
+			b.WriteString("</blockquote>\n")
+		case listBlock:
+			tag := "ul"
+			if bl.ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag)
+			if bl.ordered && bl.start != 1 {
+				b.WriteString(` start="` + strconv.Itoa(bl.start) + `"`)
+			}
+			b.WriteString(">\n")
+			for _, item := range bl.items {
+				r.renderItem(b, item, bl.tight)
+			}
+			b.WriteString("</" + tag + ">\n")
+		case ruleBlock:
+			b.WriteString("<hr />\n")
+		}
+	}
+}
+
+// renderItem renders a list item. In a tight list its paragraphs are not wrapped in <p>.
+func (r *renderer) renderItem(b *strings.Builder, item []*block, tight bool) {
+	if !tight {
+		b.WriteString("<li>\n")
+		r.renderBlocks(b, item)
+		b.WriteString("</li>\n")
+		return
+	}
+	b.WriteString("<li>")
+	for k, bl := range item {
+		if bl.kind != paragraphBlock {
+			if k == 0 {
+				b.WriteByte('\n')
+			}
+			r.renderBlocks(b, []*block{bl})
+			continue
+		}
+		b.WriteString(r.inline(bl.text))
+		if k < len(item)-1 {
+			b.WriteByte('\n')
+		}
+	}
+	b.WriteString("</li>\n")
+}
+
+// listMarker is the bullet or number opening a list item
+type listMarker struct {
+	ordered bool
+	char    byte // The bullet, or the . or ) after the number
+	start   int
+	width   int // Columns from the start of the line to the item's content
+	empty   bool
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	indent := indentation(line)
+	if indent >= 4 || indent == len(line) {
+		return listMarker{}, false
+	}
+	rest := line[indent:]
+	var m listMarker
+	n := 0
+	if c := rest[0]; c == '-' || c == '+' || c == '*' {
+		m.char, n = c, 1
+	} else {
+		for n < len(rest) && n < 9 && isDigit(rest[n]) {
+			n++
+		}
+		if n == 0 || n >= len(rest) || rest[n] != '.' && rest[n] != ')' {
+			return listMarker{}, false
+		}
+		m.ordered, m.char = true, rest[n]
+		m.start, _ = strconv.Atoi(rest[:n])
+		n++
+	}
+	after := rest[n:]
+	switch spaces := indentation(after); {
+	case isBlank(after):
+		m.empty, m.width = true, indent+n+1
+	case spaces == 0:
+		return listMarker{}, false
+	case spaces > 4: // The content is indented code, one space past the marker
+		m.width = indent + n + 1
+	default:
+		m.width = indent + n + spaces
+	}
+	return m, true
+}
+
+func isListItem(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok
+}
+
+// interruptsParagraph reports whether a line starts a block even in the middle of a paragraph
+func interruptsParagraph(line string) bool {
+	indent := indentation(line)
+	if indent >= 4 || indent == len(line) {
+		return false
+	}
+	rest := line[indent:]
+	if _, _, ok := atxHeading(rest); ok || rest[0] == '>' || isThematicBreak(line) {
+		return true
+	}
+	if _, ok := openingFence(line); ok {
+		return true
+	}
+	m, ok := parseListMarker(line)
+	return ok && !m.empty && (!m.ordered || m.start == 1)
+}
+
+func atxHeading(rest string) (level int, text string, ok bool) {
+	n := runLength(rest, '#')
+	if n == 0 || n > 6 || n < len(rest) && rest[n] != ' ' && rest[n] != '\t' {
+		return 0, "", false
+	}
+	text = strings.TrimSpace(rest[n:])
+	if closed := strings.TrimRight(text, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		text = strings.TrimSpace(closed)
+	}
+	return n, text, true
+}
+
+func isThematicBreak(line string) bool {
+	indent := indentation(line)
+	if indent >= 4 || indent == len(line) {
+		return false
+	}
+	c, n := line[indent], 0
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	for i := indent; i < len(line); i++ {
+		switch line[i] {
+		case c:
+			n++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return n >= 3
+}
+
+// setextLevel is 1 or 2 for a line of = or - underlining the paragraph above as a heading
+func setextLevel(line string) int {
+	indent := indentation(line)
+	rest := strings.TrimRight(line[indent:], " \t")
+	if indent >= 4 || rest == "" {
+		return 0
+	}
+	if n := runLength(rest, rest[0]); n == len(rest) && rest[0] == '=' {
+		return 1
+	} else if n == len(rest) && rest[0] == '-' {
+		return 2
+	}
+	return 0
+}
+
+// expandTabs turns tabs in a line's indentation into spaces, to tab stops of 4
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	i := 0
+	for ; i < len(line) && (line[i] == ' ' || line[i] == '\t'); i++ {
+		if line[i] == '\t' {
+			b.WriteString(strings.Repeat(" ", 4-b.Len()%4))
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String() + line[i:]
+}
+
+func stripIndent(line string, n int) string {
+	return line[min(n, indentation(line)):]
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
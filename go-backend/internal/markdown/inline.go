@@ -0,0 +1,349 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	entityPattern = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	tagPattern    = regexp.MustCompile(`<[^>]*>`)
+	htmlEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// inlineNode is rendered HTML, or a run of * or _ that may open or close emphasis
+type inlineNode struct {
+	html string
+
+	delim             byte
+	count, length     int // Delimiters left unmatched, of the run's length
+	canOpen, canClose bool
+	opens, closes     []string
+}
+
+// inline renders the inline content of a paragraph or heading
+func (r *renderer) inline(src string) string {
+	var nodes []*inlineNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &inlineNode{html: text.String()})
+			text.Reset()
+		}
+	}
+	emit := func(out string) {
+		flush()
+		nodes = append(nodes, &inlineNode{html: out})
+	}
+	for i := 0; i < len(src); {
+		switch c := src[i]; c {
+		case '\\':
+			switch {
+			case i+1 < len(src) && src[i+1] == '\n':
+				emit("<br />\n")
+				i += 2
+			case i+1 < len(src) && isPunct(src[i+1]):
+				text.WriteString(escapeHTML(src[i+1 : i+2]))
+				i += 2
+			default:
+				text.WriteByte('\\')
+				i++
+			}
+		case '`':
+			n := runLength(src[i:], '`')
+			end := closingBackticks(src, i+n, n)
+			if end < 0 {
+				text.WriteString(src[i : i+n])
+				i += n
+				continue
+			}
+			code := src[i+n : end-n]
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			emit("<code>" + escapeHTML(code) + "</code>")
+			i = end
+		case '&':
+			if m := entityPattern.FindString(src[i:]); m != "" {
+				text.WriteString(escapeHTML(html.UnescapeString(m)))
+				i += len(m)
+			} else {
+				text.WriteString("&amp;")
+				i++
+			}
+		case '<':
+			if n, href, label := autolink(src[i:]); n > 0 && safeURL(href) {
+				emit(`<a href="` + escapeURL(href) + `">` + escapeHTML(label) + "</a>")
+				i += n
+			} else {
+				text.WriteString("&lt;")
+				i++
+			}
+		case '!', '[':
+			start := i
+			if c == '!' {
+				start++
+			}
+			if start < len(src) && src[start] == '[' {
+				if out, end, ok := r.link(src, start, c == '!'); ok {
+					emit(out)
+					i = end
+					continue
+				}
+			}
+			text.WriteByte(c)
+			i++
+		case '*', '_':
+			n := runLength(src[i:], c)
+			before, after := ' ', ' ' // The start and end of the text count as whitespace
+			if i > 0 {
+				before, _ = utf8.DecodeLastRuneInString(src[:i])
+			}
+			if i+n < len(src) {
+				after, _ = utf8.DecodeRuneInString(src[i+n:])
+			}
+			left := !unicode.IsSpace(after) && (!isPunctRune(after) || unicode.IsSpace(before) || isPunctRune(before))
+			right := !unicode.IsSpace(before) && (!isPunctRune(before) || unicode.IsSpace(after) || isPunctRune(after))
+			node := &inlineNode{delim: c, count: n, length: n, canOpen: left, canClose: right}
+			if c == '_' { // Not inside words
+				node.canOpen = left && (!right || isPunctRune(before))
+				node.canClose = right && (!left || isPunctRune(after))
+			}
+			flush()
+			nodes = append(nodes, node)
+			i += n
+		case '\n':
+			line := text.String()
+			trimmed := strings.TrimRight(line, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(line)-len(trimmed) >= 2 {
+				emit("<br />\n")
+			} else {
+				text.WriteByte('\n')
+			}
+			for i++; i < len(src) && src[i] == ' '; i++ {
+			}
+		default:
+			end := i + 1
+			for end < len(src) && strings.IndexByte("\\`&<![*_\n", src[end]) < 0 {
+				end++
+			}
+			text.WriteString(escapeHTML(src[i:end]))
+			i = end
+		}
+	}
+	flush()
+	processEmphasis(nodes)
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(strings.Join(n.closes, ""))
+		b.WriteString(n.html)
+		b.WriteString(strings.Repeat(string(n.delim), n.count))
+		b.WriteString(strings.Join(n.opens, ""))
+	}
+	return b.String()
+}
+
+// processEmphasis matches emphasis delimiters as CommonMark does: each closer with
+// the nearest opener of the same character, strong where both have two to spare.
+// Delimiters between a matched pair can no longer match, which keeps tags nested.
+func processEmphasis(nodes []*inlineNode) {
+	for c, closer := range nodes {
+		for closer.delim != 0 && closer.canClose && closer.count > 0 {
+			o := c - 1
+			for ; o >= 0; o-- {
+				opener := nodes[o]
+				if opener.delim != closer.delim || !opener.canOpen || opener.count == 0 {
+					continue
+				}
+				// The rule of 3, for runs that can both open and close
+				if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 &&
+					(opener.length%3 != 0 || closer.length%3 != 0) {
+					continue
+				}
+				break
+			}
+			if o < 0 {
+				break
+			}
+			opener := nodes[o]
+			use, tag := 1, "em"
+			if opener.count >= 2 && closer.count >= 2 {
+				use, tag = 2, "strong"
+			}
+			opener.count -= use
+			closer.count -= use
+			opener.opens = append([]string{"<" + tag + ">"}, opener.opens...)
+			closer.closes = append(closer.closes, "</"+tag+">")
+			for _, between := range nodes[o+1 : c] {
+				between.canOpen, between.canClose = false, false
+			}
+		}
+	}
+}
+
+// link renders the link or image whose text opens with the [ at src[i], returning
+// the index after it. Links to unsafe URLs are rendered as their text.
+func (r *renderer) link(src string, i int, image bool) (out string, end int, ok bool) {
+	closing := matchingBracket(src, i)
+	if closing < 0 {
+		return "", 0, false
+	}
+	label := src[i+1 : closing]
+	end = closing + 1
+	dest, title, n, inline := inlineDestination(src[end:])
+	if inline {
+		end += n
+	} else {
+		ref := label
+		if strings.HasPrefix(src[end:], "[") { // A full or collapsed reference
+			k := strings.IndexByte(src[end+1:], ']')
+			if k < 0 {
+				return "", 0, false
+			}
+			if k > 0 {
+				ref = src[end+1 : end+1+k]
+			}
+			end += k + 2
+		}
+		def, found := r.refs[normalizeLabel(ref)]
+		if !found {
+			return "", 0, false
+		}
+		dest, title = def.dest, def.title
+	}
+	text := r.inline(label)
+	titleAttr := ""
+	if title != "" {
+		titleAttr = ` title="` + escapeHTML(title) + `"`
+	}
+	switch {
+	case image && safeURL(dest):
+		alt := tagPattern.ReplaceAllString(text, "")
+		return `<img src="` + escapeURL(dest) + `" alt="` + alt + `"` + titleAttr + " />", end, true
+	case image:
+		return tagPattern.ReplaceAllString(text, ""), end, true
+	case safeURL(dest):
+		return `<a href="` + escapeURL(dest) + `"` + titleAttr + ">" + text + "</a>", end, true
+	default:
+		return text, end, true
+	}
+}
+
+// matchingBracket finds the ] closing the [ at src[i], skipping escapes and code spans
+func matchingBracket(src string, i int) int {
+	depth := 0
+	for j := i; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '`':
+			n := runLength(src[j:], '`')
+			if end := closingBackticks(src, j+n, n); end >= 0 {
+				j = end - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// inlineDestination reads the (destination "title") of an inline link at the start of s
+func inlineDestination(s string) (dest, title string, n int, ok bool) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", 0, false
+	}
+	i := skipSpace(s, 1)
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], "<>\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : i+1+end]
+		i += end + 2
+	} else {
+		start, depth := i, 0
+	scan:
+		for i < len(s) {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+				i++
+			case c <= ' ':
+				break scan
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			}
+			i++
+		}
+		if depth != 0 {
+			return "", "", 0, false
+		}
+		dest = s[start:i]
+	}
+	j := skipSpace(s, i)
+	if j > i && j < len(s) && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closer := s[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		k := j + 1
+		for ; k < len(s) && s[k] != closer; k++ {
+			if s[k] == '\\' {
+				k++
+			}
+		}
+		if k >= len(s) {
+			return "", "", 0, false
+		}
+		title = s[j+1 : k]
+		j = skipSpace(s, k+1)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return unescapeLink(dest), unescapeLink(title), j + 1, true
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+// normalizeLabel makes link labels match case-insensitively and whatever their spacing
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func unescapeLink(s string) string {
+	return html.UnescapeString(unescapeBackslashes(s))
+}
+
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+func escapeURL(s string) string {
+	return escapeHTML(strings.ReplaceAll(s, " ", "%20"))
+}
+
+func isPunctRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,182 @@
+// Package markdown defines the format chapter content is stored in: CommonMark,
+// sanitized of embedded HTML on every write, and rendered to HTML for clients
+// without a Markdown renderer of their own.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Normalize puts text in the canonical form content is stored in: valid UTF-8 with
+// LF line endings, no control characters and no leading or trailing blank lines
+func Normalize(src string) string {
+	src = strings.ToValidUTF8(src, "\uFFFD")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if unicode.IsControl(r) || r == '\uFEFF' {
+			return -1
+		}
+		return r
+	}, src)
+	src = strings.TrimRight(src, " \t\n")
+	for { // Leading blank lines, keeping the first line's indentation
+		line, rest, found := strings.Cut(src, "\n")
+		if !found || strings.TrimSpace(line) != "" {
+			return src
+		}
+		src = rest
+	}
+}
+
+// allowedSchemes are the URL schemes links and images may use; javascript:, data:
+// and anything else a browser might act on are dropped
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true, "ftp": true}
+
+// safeURL reports whether a link destination is relative or uses an allowed scheme.
+// Escapes and entities are resolved first, as a renderer would before following it.
+func safeURL(dest string) bool {
+	dest = html.UnescapeString(unescapeBackslashes(dest))
+	dest = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, dest)
+	colon := strings.IndexByte(dest, ':')
+	if colon <= 0 {
+		return true
+	}
+	scheme := strings.ToLower(dest[:colon])
+	for i := 0; i < len(scheme); i++ {
+		c := scheme[i]
+		if !(isLetter(c) || i > 0 && (isDigit(c) || c == '+' || c == '-' || c == '.')) {
+			return true // The colon is in a path or query, not after a scheme
+		}
+	}
+	return allowedSchemes[scheme]
+}
+
+var (
+	uriAutolink   = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9+.-]{1,31}:[^\x00-\x20<>]*>`)
+	emailAutolink = regexp.MustCompile(`^<[A-Za-z0-9.!#$%&'*+/=?^_\x60{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*>`)
+)
+
+// autolink matches a <scheme:...> or <address@domain> autolink at the start of s,
+// returning its length and where it points
+func autolink(s string) (n int, href, label string) {
+	if m := uriAutolink.FindString(s); m != "" {
+		return len(m), m[1 : len(m)-1], m[1 : len(m)-1]
+	}
+	if m := emailAutolink.FindString(s); m != "" {
+		return len(m), "mailto:" + m[1:len(m)-1], m[1 : len(m)-1]
+	}
+	return 0, "", ""
+}
+
+// fence is the opening line of a fenced code block
+type fence struct {
+	char   byte
+	length int
+	indent int
+	info   string
+}
+
+func openingFence(line string) (fence, bool) {
+	indent := indentation(line)
+	if indent > 3 || indent == len(line) {
+		return fence{}, false
+	}
+	c := line[indent]
+	if c != '`' && c != '~' {
+		return fence{}, false
+	}
+	n := runLength(line[indent:], c)
+	info := strings.TrimSpace(line[indent+n:])
+	if n < 3 || c == '`' && strings.Contains(info, "`") {
+		return fence{}, false
+	}
+	return fence{char: c, length: n, indent: indent, info: info}, true
+}
+
+func closesFence(line string, f fence) bool {
+	indent := indentation(line)
+	if indent > 3 || indent == len(line) || line[indent] != f.char {
+		return false
+	}
+	n := runLength(line[indent:], f.char)
+	return n >= f.length && strings.TrimSpace(line[indent+n:]) == ""
+}
+
+// closingBackticks finds the run of exactly n backticks closing a code span opened
+// before from, returning the index after it or -1. Spans stay on one line.
+func closingBackticks(s string, from, n int) int {
+	for i := from; i < len(s); {
+		switch s[i] {
+		case '\n':
+			return -1
+		case '`':
+			run := runLength(s[i:], '`')
+			if run == n {
+				return i + n
+			}
+			i += run
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// indentation counts the leading spaces of a line
+func indentation(line string) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
@@ -0,0 +1,315 @@
+package markdown
+
+import "strings"
+
+// dropContent are the elements removed along with their content; other tags are
+// removed leaving their text
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "applet": true, "noscript": true,
+	"noembed": true, "noframes": true, "template": true, "textarea": true, "select": true, "title": true,
+	"head": true, "xmp": true, "svg": true, "math": true,
+}
+
+// blockTags are the elements that end a paragraph, and so leave a blank line when removed
+var blockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true, "aside": true,
+	"blockquote": true, "pre": true, "ul": true, "ol": true, "li": true, "table": true, "tr": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "figure": true, "br": true,
+}
+
+// inlineTags are the other elements a tag is removed for. A tag-like text naming
+// anything else is kept as text, as is one whose attributes are not well formed.
+var inlineTags = map[string]bool{
+	"a": true, "abbr": true, "address": true, "area": true, "audio": true, "b": true, "base": true, "bdi": true,
+	"bdo": true, "big": true, "blink": true, "body": true, "button": true, "canvas": true, "caption": true,
+	"center": true, "cite": true, "code": true, "col": true, "colgroup": true, "data": true, "dd": true,
+	"del": true, "details": true, "dfn": true, "dialog": true, "dl": true, "dt": true, "em": true, "embed": true,
+	"fieldset": true, "figcaption": true, "font": true, "form": true, "frame": true, "frameset": true,
+	"html": true, "i": true, "img": true, "input": true, "ins": true, "kbd": true, "label": true, "legend": true,
+	"link": true, "main": true, "map": true, "mark": true, "marquee": true, "menu": true, "meta": true,
+	"meter": true, "nav": true, "optgroup": true, "option": true, "output": true, "param": true, "picture": true,
+	"portal": true, "progress": true, "q": true, "rp": true, "rt": true, "ruby": true, "s": true, "samp": true,
+	"search": true, "slot": true, "small": true, "source": true, "span": true, "strike": true, "strong": true,
+	"sub": true, "summary": true, "sup": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "time": true, "track": true, "tt": true, "u": true, "var": true, "video": true, "wbr": true,
+}
+
+// booleanAttributes are the attributes a tag may carry without a value; a bare word
+// other than these, as in "a <b and c> d", makes the text an inequality rather than a tag
+var booleanAttributes = map[string]bool{
+	"allowfullscreen": true, "async": true, "autofocus": true, "autoplay": true, "checked": true,
+	"controls": true, "default": true, "defer": true, "disabled": true, "formnovalidate": true, "hidden": true,
+	"inert": true, "ismap": true, "itemscope": true, "loop": true, "multiple": true, "muted": true,
+	"nomodule": true, "novalidate": true, "open": true, "playsinline": true, "readonly": true,
+	"required": true, "reversed": true, "selected": true,
+}
+
+// maxSanitizePasses bounds the passes Sanitize makes: removing a tag can turn the
+// text around it into a code fence, which changes what the next pass keeps verbatim
+const maxSanitizePasses = 8
+
+// Sanitize normalizes content and strips what has no place in it. Raw HTML tags are
+// removed, keeping their text, while script, style and similar elements go with their
+// content, as do comments. Links and images pointing at other than http(s), mailto
+// or ftp URLs are pointed at # instead. Code is kept verbatim, since it renders as text.
+func Sanitize(src string) string {
+	text := Normalize(src)
+	for range maxSanitizePasses {
+		next := sanitizePass(text)
+		if next == text {
+			return text
+		}
+		text = next
+	}
+	// Still changing: give up on keeping code blocks verbatim
+	return Normalize(sanitizeText(text))
+}
+
+// SanitizeLine sanitizes a one-line text such as a title or heading: as Sanitize,
+// with line breaks and runs of spaces collapsed to one space
+func SanitizeLine(src string) string {
+	return strings.Join(strings.Fields(Sanitize(src)), " ")
+}
+
+// sanitizePass sanitizes the text between fenced code blocks. Only fences in the
+// first column are kept verbatim: an indented one may belong to a list item, which
+// the next unindented line would end along with the code.
+func sanitizePass(text string) string {
+	lines := strings.Split(text, "\n")
+	var out, pending []string
+	flush := func() {
+		if len(pending) > 0 {
+			out = append(out, sanitizeText(strings.Join(pending, "\n")))
+			pending = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		f, ok := openingFence(lines[i])
+		if !ok || f.indent > 0 {
+			pending = append(pending, lines[i])
+			continue
+		}
+		flush()
+		end := i + 1
+		for end < len(lines) && !closesFence(lines[end], f) {
+			end++
+		}
+		end = min(end+1, len(lines))
+		out = append(out, lines[i:end]...)
+		i = end - 1
+	}
+	flush()
+	return Normalize(strings.Join(out, "\n"))
+}
+
+// sanitizeText removes HTML from Markdown outside code blocks and neutralizes unsafe
+// link destinations, in one left-to-right scan as a CommonMark parser reads inlines
+func sanitizeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			b.WriteString(s[i : i+2])
+			i += 2
+		case c == '`':
+			n := runLength(s[i:], '`')
+			end := closingBackticks(s, i+n, n)
+			if end < 0 {
+				end = i + n
+			}
+			b.WriteString(s[i:end])
+			i = end
+		case c == ']' && i+1 < len(s) && (s[i+1] == '(' || s[i+1] == ':'):
+			// An inline link's destination, or a reference definition's
+			start := i
+			for i += 2; i < len(s) && isSpace(s[i]); i++ {
+			}
+			b.WriteString(s[start:i])
+			i += writeDestination(&b, s[i:])
+		case c == '<':
+			i += sanitizeAngle(&b, s[i:])
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// writeDestination copies the link destination at the start of s, or # in place of
+// an unsafe one, returning its length
+func writeDestination(b *strings.Builder, s string) int {
+	n, dest := 0, ""
+	if strings.HasPrefix(s, "<") {
+		end := strings.IndexAny(s[1:], "<>\n")
+		if end < 0 || s[1+end] != '>' {
+			return 0
+		}
+		n, dest = end+2, s[1:end+1]
+	} else {
+		depth := 0
+	scan:
+		for n < len(s) {
+			switch c := s[n]; {
+			case c == '\\' && n+1 < len(s) && isPunct(s[n+1]):
+				n++
+			case c <= ' ':
+				break scan
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			}
+			n++
+		}
+		dest = s[:n]
+	}
+	if safeURL(dest) {
+		b.WriteString(s[:n])
+	} else if n > 0 {
+		b.WriteByte('#')
+	}
+	return n
+}
+
+// sanitizeAngle handles a < in text: autolinks to safe URLs are kept, tags, comments
+// and the like removed, and anything else left as text, escaped where a Markdown
+// renderer could take it for HTML. It returns the length read.
+func sanitizeAngle(b *strings.Builder, s string) int {
+	switch {
+	case strings.HasPrefix(s, "<!--"):
+		if end := strings.Index(s[4:], "-->"); end >= 0 {
+			return 4 + end + 3
+		}
+		b.WriteString(`\<`)
+		return 1
+	case len(s) > 1 && (s[1] == '!' || s[1] == '?'): // Declarations, CDATA and processing instructions
+		b.WriteString(`\<`)
+		return 1
+	}
+	if n, href, _ := autolink(s); n > 0 {
+		if !safeURL(href) {
+			b.WriteByte('\\') // Shown as text rather than linked
+		}
+		b.WriteString(s[:n])
+		return n
+	}
+	name, n, closing := htmlTag(s)
+	if n == 0 {
+		if len(s) > 1 && (isLetter(s[1]) || s[1] == '/') {
+			b.WriteString(`\<`) // Not a tag, but a renderer might read it as raw HTML
+		} else {
+			b.WriteByte('<')
+		}
+		return 1
+	}
+	if dropContent[name] && !closing && !strings.HasSuffix(s[:n], "/>") {
+		end := closingTag(s[n:], name)
+		if end < 0 {
+			b.WriteString(`\<`) // Never closed, as in prose naming the element: kept as text
+			return 1
+		}
+		return n + end
+	}
+	if blockTags[name] {
+		for out := b.String(); out != "" && !strings.HasSuffix(out, "\n\n"); out = b.String() {
+			b.WriteByte('\n')
+		}
+	}
+	return n
+}
+
+// htmlTag matches an opening or closing tag of a known element at the start of s,
+// returning the lowercased tag name and the tag's length, or a length of 0 for no tag.
+// Attributes follow CommonMark's raw HTML, bare ones limited to booleanAttributes.
+func htmlTag(s string) (name string, n int, closing bool) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && (isLetter(s[i]) || i > start && (isDigit(s[i]) || s[i] == '-')) {
+		i++
+	}
+	name = strings.ToLower(s[start:i])
+	if !dropContent[name] && !blockTags[name] && !inlineTags[name] {
+		return "", 0, false
+	}
+	for {
+		spaced := i
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		switch {
+		case i >= len(s):
+			return "", 0, false
+		case s[i] == '>':
+			return name, i + 1, closing
+		case s[i] == '/' && !closing && i+1 < len(s) && s[i+1] == '>':
+			return name, i + 2, closing
+		case closing || i == spaced: // Closing tags take no attributes, and attributes are separated by space
+			return "", 0, false
+		}
+		attr := i
+		if !isLetter(s[i]) && s[i] != '_' && s[i] != ':' {
+			return "", 0, false
+		}
+		for i < len(s) && (isLetter(s[i]) || isDigit(s[i]) || strings.IndexByte("_.:-", s[i]) >= 0) {
+			i++
+		}
+		attrName := strings.ToLower(s[attr:i])
+		j := i
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+		if j >= len(s) || s[j] != '=' {
+			if !booleanAttributes[attrName] {
+				return "", 0, false
+			}
+			continue
+		}
+		for j++; j < len(s) && isSpace(s[j]); j++ {
+		}
+		switch {
+		case j >= len(s):
+			return "", 0, false
+		case s[j] == '"' || s[j] == '\'':
+			end := strings.IndexByte(s[j+1:], s[j])
+			if end < 0 {
+				return "", 0, false
+			}
+			i = j + 1 + end + 1
+		default:
+			k := j
+			for k < len(s) && !isSpace(s[k]) && strings.IndexByte("\"'=<>`", s[k]) < 0 {
+				k++
+			}
+			if k == j {
+				return "", 0, false
+			}
+			i = k
+		}
+	}
+}
+
+// closingTag finds </name> in s, returning the index after it or -1
+func closingTag(s, name string) int {
+	for i := 0; ; {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		i += j + 2
+		if len(s)-i >= len(name) && strings.EqualFold(s[i:i+len(name)], name) {
+			if end := strings.IndexByte(s[i:], '>'); end >= 0 {
+				return i + end + 1
+			}
+			return -1
+		}
+	}
+}
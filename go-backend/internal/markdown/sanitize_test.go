@@ -0,0 +1,104 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		// Tags that are never closed, or are not tags at all, stay as text
+		{"unclosed drop-content tag", "See the <title> element in the head.\n\nMore text follows.", "See the \\<title> element in the head.\n\nMore text follows."},
+		{"unclosed script", "before <script> after", "before \\<script> after"},
+		{"inequality with words", "a <b and c> d", "a \\<b and c> d"},
+		{"less-than between letters", "if x<y and y>z", "if x\\<y and y>z"},
+		{"less-than before a number", "p < 0.05 and n<30", "p < 0.05 and n<30"},
+		{"unknown element", "<foo-bar onclick=x>hi", "\\<foo-bar onclick=x>hi"},
+		{"bare non-boolean attribute", "<img src=x onerror=alert(1) foo>", "\\<img src=x onerror=alert(1) foo>"},
+		{"unterminated attribute quote", `<a href="x>text`, `\<a href="x>text`},
+		{"closing tag with attributes", "</b class=x>", "\\</b class=x>"},
+
+		// Tags are removed, keeping their text
+		{"inline tags", "<b>bold</b> and <i>it</i>", "bold and it"},
+		{"block tag breaks the paragraph", "<p>para</p>next", "para\n\nnext"},
+		{"self-closing tag", "<br/>line", "line"},
+		{"boolean attribute", "<input disabled>x", "x"},
+
+		// Drop-content elements go with their content, nested ones too
+		{"script", "<script>alert(1)</script>after", "after"},
+		{"nested script", "<script><script>alert(1)</script>text</script>end", "textend"},
+		{"script inside svg", "<svg><script>alert(1)</script></svg>ok", "ok"},
+		{"uppercase closing tag", "<STYLE>body{}</style>ok", "ok"},
+		{"comment", "<!-- hidden -->shown", "shown"},
+
+		// XSS vectors
+		{"event handler", "<img src=x onerror=alert(1)>", ""},
+		{"uppercase event handler", `<IMG SRC="x" ONERROR="alert(1)">`, ""},
+		{"javascript link tag", `<a href="javascript:alert(1)">x</a>`, "x"},
+		{"javascript link", "[x](javascript:alert(1))", "[x](#)"},
+		{"entity-encoded scheme", "[x](jav&#x61;script:alert(1))", "[x](#)"},
+		{"javascript autolink", "<javascript:alert(1)>", "\\<javascript:alert(1)>"},
+		{"data image", "![x](data:text/html;base64,PHNjcmlwdD4=)", "![x](#)"},
+		{"iframe", `<iframe src="https://evil.example"></iframe>ok`, "ok"},
+
+		// Kept as they are
+		{"safe autolink", "<https://example.com>", "<https://example.com>"},
+		{"code span", "`<script>`", "`<script>`"},
+		{"code fence", "```\n<script>alert(1)</script>\n```", "```\n<script>alert(1)</script>\n```"},
+		{"safe link", "[x](https://example.com)", "[x](https://example.com)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(tt.in)
+			if got != tt.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if again := Sanitize(got); again != got {
+				t.Errorf("Sanitize is not idempotent on %q: %q", got, again)
+			}
+		})
+	}
+}
+
+func TestSanitizeRendersSafely(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"unclosed tag", "the <title> element", "<p>the &lt;title&gt; element</p>\n"},
+		{"inequality", "a <b and c> d", "<p>a &lt;b and c&gt; d</p>\n"},
+		{"unknown element", "<foo-bar onclick=x>hi", "<p>&lt;foo-bar onclick=x&gt;hi</p>\n"},
+		{"javascript link", "[x](javascript:alert(1))", "<p><a href=\"#\">x</a></p>\n"},
+		{"code span", "`<script>`", "<p><code>&lt;script&gt;</code></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ToHTML(Sanitize(tt.in))
+			if got != tt.want {
+				t.Errorf("ToHTML(Sanitize(%q)) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeNeverEmitsTags(t *testing.T) {
+	vectors := []string{
+		"<img src=x onerror=alert(1)>",
+		"<img src=x onerror=alert(1) foo>",
+		"<svg onload=alert(1)>",
+		"<script>alert(1)",
+		"<a href=javascript:alert(1)>x",
+		"<<script>script>alert(1)<</script>/script>",
+		"<scr<script>ipt>alert(1)</script>",
+		"<details open ontoggle=alert(1)>",
+	}
+	for _, in := range vectors {
+		html := ToHTML(Sanitize(in))
+		for _, bad := range []string{"<img", "<svg", "<script", "<details", "<a href=\"javascript"} {
+			if strings.Contains(strings.ToLower(html), bad) {
+				t.Errorf("ToHTML(Sanitize(%q)) = %q contains %s", in, html, bad)
+			}
+		}
+	}
+}
@@ -1,5 +1,58 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
 func ToStringPtr(s string) *string {
 	return &s
 }
+
+// timePtr maps a nullable timestamp to a pointer so it can be omitted from JSON
+func timePtr(ts pgtype.Timestamptz) *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	return &ts.Time
+}
+
+// uuidPtr maps a nullable UUID to a pointer so it can be omitted from JSON
+func uuidPtr(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	u := uuid.UUID(id.Bytes)
+	return &u
+}
+
+// int32Ptr maps a nullable integer to a pointer so it can be omitted from JSON
+func int32Ptr(n pgtype.Int4) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+// SelectFields reduces a response value to the requested top-level JSON fields.
+// It round-trips through JSON so it works for any response struct.
+func SelectFields(v interface{}, fields []string) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if val, ok := all[f]; ok {
+			selected[f] = val
+		}
+	}
+	return selected, nil
+}
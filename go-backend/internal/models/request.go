@@ -9,6 +9,13 @@ type RegisterUserRequest struct {
 	LastName  string `json:"last_name" binding:"required"`
 }
 
+// CreateGuestRequest names the sandbox project of a trial; every field is optional
+type CreateGuestRequest struct {
+	Title          string `json:"title,omitempty" binding:"max=500"`
+	Specialization string `json:"specialization,omitempty" binding:"max=100"`
+	Language       string `json:"language,omitempty" binding:"omitempty,oneof=en ar fr es de tr pt"`
+}
+
 type LoginUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
@@ -23,6 +30,18 @@ type CreateProjectRequest struct {
 	Specialization string `json:"specialization" binding:"required,max=100"`
 	University     string `json:"university,omitempty" binding:"max=200"`
 	Description    string `json:"description,omitempty"`
+	Language       string `json:"language,omitempty" binding:"omitempty,oneof=en ar fr es de tr pt" doc:"Language chapters are generated in, en by default"`
+
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty" doc:"Share the project with an organization you belong to"`
+}
+
+// ImportThesisRequest holds the form fields of a thesis import; the document fills in
+// those left empty
+type ImportThesisRequest struct {
+	Title          string `form:"title" binding:"max=500" doc:"Defaults to the document's title, or its file name"`
+	Specialization string `form:"specialization" binding:"max=100"`
+	University     string `form:"university" binding:"max=200"`
+	Language       string `form:"language" binding:"omitempty,oneof=en ar fr es de tr pt"`
 }
 
 type UpdateProjectRequest struct {
@@ -31,19 +50,76 @@ type UpdateProjectRequest struct {
 	University     *string `json:"university,omitempty" binding:"omitempty,max=200"`
 	Description    *string `json:"description,omitempty"`
 	Status         *string `json:"status,omitempty" binding:"omitempty,oneof=draft in_progress completed cancelled"`
+	Language       *string `json:"language,omitempty" binding:"omitempty,oneof=en ar fr es de tr pt"`
 }
 
 type CreateChapterRequest struct {
-	ProjectID uuid.UUID `json:"project_id" binding:"required"`
-	Type      string    `json:"type" binding:"required,oneof=introduction literature_review methodology results conclusion"`
+	ProjectID uuid.UUID `json:"project_id" binding:"required" doc:"Overridden by the project_id path parameter"`
+	Type      string    `json:"type" binding:"required,max=50" doc:"Key of a chapter type of the project, see GET /projects/{project_id}/chapter-types"`
 	Title     string    `json:"title" binding:"required,max=300"`
 	Content   string    `json:"content,omitempty"` // Content can be generated later
+
+	TargetWordCount int32  `json:"target_word_count,omitempty" binding:"omitempty,min=1,max=100000" doc:"Words the chapter should reach; generation aims for it"`
+	CustomPrompt    string `json:"custom_prompt,omitempty" binding:"omitempty,max=4000" doc:"Brief for generating the chapter: what it should cover and how"`
 }
 
 type UpdateChapterRequest struct {
 	Title   *string `json:"title,omitempty" binding:"omitempty,max=300"`
 	Content *string `json:"content,omitempty"`
 	Status  *string `json:"status,omitempty" binding:"omitempty,oneof=draft generated approved rejected"`
+	Version *int32  `json:"version,omitempty" doc:"Version the edit is based on; the update is rejected with 409 if the chapter has moved on"`
+
+	TargetWordCount *int32  `json:"target_word_count,omitempty" binding:"omitempty,min=0,max=100000" doc:"Words the chapter should reach; 0 removes the target"`
+	CustomPrompt    *string `json:"custom_prompt,omitempty" binding:"omitempty,max=4000" doc:"Brief for generating the chapter; empty removes it"`
+}
+
+// UpdateChapterDraftRequest autosaves edits to a chapter; fields left out keep the draft's value
+type UpdateChapterDraftRequest struct {
+	Title       *string `json:"title,omitempty" binding:"omitempty,max=300"`
+	Content     *string `json:"content,omitempty"`
+	BaseVersion int32   `json:"base_version" binding:"required,min=1" doc:"Chapter version the edits started from; 409 once someone else has saved the chapter since"`
+}
+
+// BulkChapterItem creates the chapter of the given type, or updates it if the project already has one
+type BulkChapterItem struct {
+	Type    string  `json:"type" binding:"required,max=50" doc:"Key of a chapter type of the project"`
+	Title   *string `json:"title,omitempty" binding:"omitempty,max=300" doc:"Required when the project has no chapter of this type yet"`
+	Content *string `json:"content,omitempty"`
+	Status  *string `json:"status,omitempty" binding:"omitempty,oneof=draft generated approved rejected"`
+}
+
+type BulkChaptersRequest struct {
+	Chapters []BulkChapterItem `json:"chapters" binding:"required,min=1,max=50,dive"`
+}
+
+// ReorderChaptersRequest is the order the project's chapters appear in
+type ReorderChaptersRequest struct {
+	ChapterIDs []uuid.UUID `json:"chapter_ids" binding:"required,min=1,max=200" doc:"Every chapter of the project, first to last; the executive summary may be left out"`
+}
+
+// CreateSectionRequest adds a section after the chapter's others
+type CreateSectionRequest struct {
+	Title   string `json:"title" binding:"required,max=300"`
+	Level   int32  `json:"level,omitempty" binding:"omitempty,min=1,max=3" doc:"Heading level under the chapter: 1 for 2.1, 2 for 2.1.1, 3 for 2.1.1.1; defaults to 1"`
+	Content string `json:"content,omitempty"`
+}
+
+// UpdateSectionRequest edits a section; fields left out keep their value
+type UpdateSectionRequest struct {
+	Title   *string `json:"title,omitempty" binding:"omitempty,min=1,max=300"`
+	Level   *int32  `json:"level,omitempty" binding:"omitempty,min=1,max=3"`
+	Content *string `json:"content,omitempty"`
+}
+
+// ReorderSectionsRequest is the order a chapter's sections appear in
+type ReorderSectionsRequest struct {
+	SectionIDs []uuid.UUID `json:"section_ids" binding:"required,min=1,max=200" doc:"Every section of the chapter, first to last"`
+}
+
+// GenerateSectionRequest steers the AI rewrite of a single section; the body is optional
+type GenerateSectionRequest struct {
+	Instructions    string `json:"instructions,omitempty" binding:"omitempty,max=4000" doc:"What this section should cover or change"`
+	TargetWordCount int32  `json:"target_word_count,omitempty" binding:"omitempty,min=1,max=20000" doc:"Words the section should reach; 300-600 when left out"`
 }
 
 type GenerateChapterContentRequest struct {
@@ -53,7 +129,7 @@ type GenerateChapterContentRequest struct {
 }
 
 type CreateReferenceRequest struct {
-	ProjectID       uuid.UUID `json:"project_id" binding:"required"`
+	ProjectID       uuid.UUID `json:"project_id" binding:"required" doc:"Overridden by the project_id path parameter"`
 	Title           string    `json:"title" binding:"required"`
 	Authors         *string   `json:"authors,omitempty"`
 	Journal         *string   `json:"journal,omitempty" binding:"max=300"`
@@ -61,10 +137,314 @@ type CreateReferenceRequest struct {
 	DOI             *string   `json:"doi,omitempty" binding:"max=100"`
 	URL             *string   `json:"url,omitempty"`
 	CitationAPA     *string   `json:"citation_apa,omitempty"`
+	Abstract        *string   `json:"abstract,omitempty"`
 	CitationMLA     *string   `json:"citation_mla,omitempty"`
 }
 
+// UpdateReferenceNotesRequest replaces a reference's notes
+type UpdateReferenceNotesRequest struct {
+	Notes string `json:"notes" binding:"max=10000" doc:"Why the paper matters to the thesis; empty clears the notes"`
+}
+
+// UpdateReferenceTagsRequest replaces a reference's tags
+type UpdateReferenceTagsRequest struct {
+	Tags []string `json:"tags" binding:"max=4,dive,oneof=methodology seminal recent contradictory" doc:"An empty list clears the tags"`
+}
+
+type CreateReferenceHighlightRequest struct {
+	Text    string `json:"text" binding:"required,max=5000" doc:"Passage quoted from the paper"`
+	Comment string `json:"comment,omitempty" binding:"max=2000"`
+	Page    *int32 `json:"page,omitempty" binding:"omitempty,min=1"`
+}
+
 type GenerateDocumentRequest struct {
 	ProjectID uuid.UUID `json:"project_id" binding:"required"`
 	// Add other options like template, citation style if needed
 }
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=200"`
+}
+
+// CreateChapterTypeRequest adds a chapter type the organization's projects, or a single project, can use
+type CreateChapterTypeRequest struct {
+	Key         string `json:"key" binding:"required,max=50" doc:"Lowercase letters, digits and underscores, starting with a letter; what chapters' type is set to"`
+	Label       string `json:"label" binding:"required,max=200"`
+	Description string `json:"description,omitempty" binding:"omitempty,max=2000"`
+	Position    *int32 `json:"position,omitempty" binding:"omitempty,min=0,max=10000" doc:"Where the type is listed; after the others when left out"`
+}
+
+// UpdateChapterTypeRequest edits an organization chapter type; fields left out keep their value
+type UpdateChapterTypeRequest struct {
+	Label       *string `json:"label,omitempty" binding:"omitempty,max=200"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=2000"`
+	Position    *int32  `json:"position,omitempty" binding:"omitempty,min=0,max=10000"`
+}
+
+// InviteMemberRequest invites someone by email; they join when they accept
+type InviteMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// AcceptInvitationRequest carries the token emailed to the invitee
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AddCollaboratorRequest shares a project with a registered user
+type AddCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=read comment edit"`
+}
+
+// CreateCommentThreadRequest starts a comment thread anchored either to the
+// character range [anchor_start, anchor_end) of the chapter content or to a section heading
+type CreateCommentThreadRequest struct {
+	Body          string `json:"body" binding:"required,max=10000"`
+	AnchorStart   *int32 `json:"anchor_start,omitempty" binding:"omitempty,min=0"`
+	AnchorEnd     *int32 `json:"anchor_end,omitempty" binding:"omitempty,min=1"`
+	AnchorHeading string `json:"anchor_heading,omitempty" binding:"max=500"`
+}
+
+// AddCommentRequest replies to a comment thread
+type AddCommentRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+// SetAdvisorRequest assigns a registered user as the project's advisor
+type SetAdvisorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// SubmitChapterRequest submits a chapter to the project's advisor for review
+type SubmitChapterRequest struct {
+	Note string `json:"note,omitempty" binding:"max=2000"`
+}
+
+// ReviewDecisionRequest records the advisor's decision on a pending review
+type ReviewDecisionRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve request_changes"`
+	Note     string `json:"note,omitempty" binding:"max=5000"`
+}
+
+// UpdateNotificationPreferenceRequest turns a notification type on or off per channel
+type UpdateNotificationPreferenceRequest struct {
+	InApp *bool `json:"in_app,omitempty"`
+	Email *bool `json:"email,omitempty"`
+}
+
+// CreateWebhookRequest registers a URL that receives the project's events.
+// An empty events list subscribes to every event.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2000"`
+	Events []string `json:"events,omitempty" binding:"max=20"`
+}
+
+// UpdateWebhookRequest changes a webhook; omitted fields keep their value
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url,omitempty" binding:"omitempty,url,max=2000"`
+	Events []string `json:"events,omitempty" binding:"omitempty,max=20"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// CreateTableRequest adds a table to a chapter. Figures are uploaded as images instead.
+type CreateTableRequest struct {
+	Caption  string     `json:"caption" binding:"required,max=1000"`
+	Position int32      `json:"position" binding:"min=0" doc:"Order among the chapter's figures and tables"`
+	Rows     [][]string `json:"rows" binding:"required,min=1,max=200" doc:"Cells of the table, the first row its header; every row has the same number of cells"`
+}
+
+// UpdateFigureRequest changes a figure or table; omitted fields keep their value
+type UpdateFigureRequest struct {
+	Caption  *string    `json:"caption,omitempty" binding:"omitempty,min=1,max=1000"`
+	Position *int32     `json:"position,omitempty" binding:"omitempty,min=0"`
+	Rows     [][]string `json:"rows,omitempty" binding:"omitempty,min=1,max=200" doc:"Tables only"`
+}
+
+// CreateAppendixRequest adds an appendix; an image can be attached afterwards
+// CreateUploadSessionRequest starts a resumable upload of a source PDF
+type CreateUploadSessionRequest struct {
+	FileName string `json:"file_name" binding:"required,max=255"`
+	Size     int64  `json:"size" binding:"required,min=1" doc:"Size of the whole file in bytes"`
+	SHA256   string `json:"sha256,omitempty" binding:"omitempty,len=64,hexadecimal" doc:"Hex SHA-256 of the whole file; the assembled file must match it"`
+}
+
+type CreateAppendixRequest struct {
+	Title    string `json:"title" binding:"required,max=500"`
+	Content  string `json:"content" binding:"max=200000"`
+	Position int32  `json:"position" binding:"min=0" doc:"Order among the appendices, which sets their letters"`
+}
+
+// UpdateAppendixRequest changes an appendix; omitted fields keep their value
+type UpdateAppendixRequest struct {
+	Title    *string `json:"title,omitempty" binding:"omitempty,min=1,max=500"`
+	Content  *string `json:"content,omitempty" binding:"omitempty,max=200000"`
+	Position *int32  `json:"position,omitempty" binding:"omitempty,min=0"`
+}
+
+// TimelineMilestone is a fixed date a research timeline has to meet, e.g. a proposal defense
+type TimelineMilestone struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Date  string `json:"date" binding:"required,datetime=2006-01-02"`
+}
+
+// GenerateTimelineRequest asks for a research timeline from the start date to the
+// submission deadline; it replaces the project's previous timeline
+type GenerateTimelineRequest struct {
+	StartDate         string              `json:"start_date" binding:"required,datetime=2006-01-02"`
+	EndDate           string              `json:"end_date" binding:"required,datetime=2006-01-02" doc:"Submission deadline"`
+	Milestones        []TimelineMilestone `json:"milestones,omitempty" binding:"max=20,dive"`
+	IncludeInDocument bool                `json:"include_in_document,omitempty" doc:"Render the timeline in generated documents"`
+}
+
+// UpdateTimelineRequest sets whether generated documents include the timeline
+type UpdateTimelineRequest struct {
+	IncludeInDocument *bool `json:"include_in_document" binding:"required"`
+}
+
+// MethodologyAnswers are a project's answers to the methodology questionnaire.
+// Methodology generation writes the chapter from them, with placeholders for what is left open.
+type MethodologyAnswers struct {
+	Approach    string `json:"approach" binding:"required,oneof=quantitative qualitative mixed_methods experimental case_study systematic_review"`
+	Design      string `json:"design,omitempty" binding:"max=1000" doc:"Research design, e.g. cross-sectional survey or phenomenological study"`
+	Population  string `json:"population,omitempty" binding:"max=1000" doc:"Who or what is studied"`
+	Sampling    string `json:"sampling,omitempty" binding:"max=1000" doc:"Sampling strategy and sample size"`
+	Instruments string `json:"instruments,omitempty" binding:"max=1000" doc:"Data collection instruments, e.g. questionnaire or interview guide"`
+	Analysis    string `json:"analysis,omitempty" binding:"max=1000" doc:"Analysis approach, e.g. multiple regression or thematic analysis"`
+	Software    string `json:"software,omitempty" binding:"max=200"`
+	Ethics      string `json:"ethics,omitempty" binding:"max=1000" doc:"Ethical approval, consent and data protection"`
+}
+
+// QuestionnaireItem is one question of a questionnaire
+type QuestionnaireItem struct {
+	Text     string   `json:"text" binding:"required,max=1000"`
+	Type     string   `json:"type" binding:"required,oneof=likert single_choice multiple_choice yes_no open"`
+	Options  []string `json:"options,omitempty" binding:"max=20,dive,required,max=200" doc:"Choices of a choice item, or the scale labels of a likert item from lowest to highest; likert items without them use a 5-point agreement scale"`
+	Required bool     `json:"required,omitempty"`
+}
+
+// QuestionnaireSection groups the items of a questionnaire under a heading
+type QuestionnaireSection struct {
+	Title       string              `json:"title" binding:"required,max=300"`
+	Description string              `json:"description,omitempty" binding:"max=2000"`
+	Items       []QuestionnaireItem `json:"items" binding:"max=100,dive"`
+}
+
+type CreateQuestionnaireRequest struct {
+	Title             string                 `json:"title" binding:"required,max=500"`
+	Instructions      string                 `json:"instructions,omitempty" binding:"max=5000" doc:"Shown to respondents before the first section"`
+	Sections          []QuestionnaireSection `json:"sections,omitempty" binding:"max=30,dive"`
+	IncludeInDocument bool                   `json:"include_in_document,omitempty" doc:"Render the questionnaire as an appendix of generated documents"`
+}
+
+// UpdateQuestionnaireRequest changes a questionnaire; omitted fields keep their value.
+// Sections, when given, replace all of the questionnaire's sections.
+type UpdateQuestionnaireRequest struct {
+	Title             *string                `json:"title,omitempty" binding:"omitempty,min=1,max=500"`
+	Instructions      *string                `json:"instructions,omitempty" binding:"omitempty,max=5000"`
+	Sections          []QuestionnaireSection `json:"sections,omitempty" binding:"omitempty,max=30,dive"`
+	IncludeInDocument *bool                  `json:"include_in_document,omitempty"`
+}
+
+// GenerateQuestionnaireItemsRequest has the AI draft a section of items for the research questions
+type GenerateQuestionnaireItemsRequest struct {
+	ResearchQuestions []string `json:"research_questions,omitempty" binding:"max=10,dive,required,max=1000" doc:"Questions the items should measure; the project's title and description are used without them"`
+	SectionTitle      string   `json:"section_title,omitempty" binding:"max=300"`
+	Count             int      `json:"count,omitempty" binding:"omitempty,min=1,max=40" doc:"Number of items, 10 by default"`
+	Type              string   `json:"type,omitempty" binding:"omitempty,oneof=likert single_choice multiple_choice yes_no open mixed" doc:"Item type, likert by default; mixed lets the AI choose per item"`
+}
+
+// AIDetectionRequest scores a chapter's saved content, or the given unsaved text
+type AIDetectionRequest struct {
+	Content *string `json:"content,omitempty" binding:"omitempty,max=500000" doc:"Text to score instead of the saved chapter, e.g. the editor's draft"`
+}
+
+// RubricCriterion is one criterion a chapter is evaluated against
+type RubricCriterion struct {
+	Key         string  `json:"key" binding:"required,max=50" doc:"Identifies the criterion in the scores, e.g. argument_clarity"`
+	Name        string  `json:"name" binding:"required,max=200"`
+	Description string  `json:"description,omitempty" binding:"max=1000" doc:"What a strong chapter does on this criterion, given to the evaluator"`
+	Weight      float64 `json:"weight,omitempty" binding:"omitempty,gt=0,max=10" doc:"Share of the overall score relative to the other criteria, 1 by default"`
+}
+
+// EvaluateChapterRequest has the AI score a chapter against a rubric
+type EvaluateChapterRequest struct {
+	Rubric []RubricCriterion `json:"rubric,omitempty" binding:"max=12,dive" doc:"Criteria to score; argument clarity, citation density, structure and originality of synthesis by default"`
+}
+
+// GenerateDefenseQuestionsRequest sets how many viva questions to prepare
+type GenerateDefenseQuestionsRequest struct {
+	QuestionsPerChapter int `json:"questions_per_chapter,omitempty" binding:"omitempty,min=1,max=10" doc:"5 by default"`
+}
+
+// GeneratePosterRequest sets the size and layout of an academic poster
+type GeneratePosterRequest struct {
+	Size       string `json:"size,omitempty" binding:"omitempty,oneof=A0 A1" doc:"A0 by default"`
+	Template   string `json:"template,omitempty" binding:"omitempty,oneof=three_column two_column landscape" doc:"Layout, three_column (portrait) by default"`
+	MaxFigures *int   `json:"max_figures,omitempty" binding:"omitempty,min=0,max=6" doc:"Key figures to include, results chapter first; 3 by default"`
+}
+
+// GenerateExecutiveSummaryRequest sets who an executive summary is written for and its length
+type GenerateExecutiveSummaryRequest struct {
+	Audience    string `json:"audience,omitempty" binding:"omitempty,oneof=supervisors funding_body" doc:"supervisors by default"`
+	TargetWords int32  `json:"target_words,omitempty" binding:"omitempty,min=600,max=2500" doc:"The chapter's word target, or about 1200 words (2-3 pages), by default"`
+}
+
+// GenerateArticleRequest sets the length and style of a journal article condensed from the thesis
+type GenerateArticleRequest struct {
+	TargetWords   int    `json:"target_words,omitempty" binding:"omitempty,min=3000,max=12000" doc:"Words in the body, without the abstract and references; 7000 by default"`
+	CitationStyle string `json:"citation_style,omitempty" binding:"omitempty,oneof=APA MLA Harvard Chicago IEEE" doc:"The journal's citation style; the project's by default"`
+	Journal       string `json:"journal,omitempty" binding:"omitempty,max=200" doc:"Journal the manuscript is prepared for, whose conventions guide its tone"`
+}
+
+// InterpretAnalysisRequest carries statistical output to be written up for a results chapter
+type InterpretAnalysisRequest struct {
+	Output           string `json:"output" binding:"required,max=20000" doc:"Output pasted from SPSS, R, Stata or similar"`
+	Software         string `json:"software,omitempty" binding:"omitempty,oneof=spss r stata other"`
+	ResearchQuestion string `json:"research_question,omitempty" binding:"max=1000" doc:"Question or hypothesis the analysis tests, for context"`
+	Language         string `json:"language,omitempty" binding:"omitempty,oneof=en ar fr es de tr pt" doc:"Language of the interpretation, en by default"`
+}
+
+// SearchPapersRequest is a Semantic Scholar paper search, bound from the query string
+type SearchPapersRequest struct {
+	Query            string   `form:"q" binding:"required,max=500"`
+	Limit            int      `form:"limit" binding:"omitempty,min=1,max=100" doc:"Results per page, 10 by default"`
+	Offset           int      `form:"offset" binding:"omitempty,min=0,max=999" doc:"Only the first 1000 results can be paged through"`
+	Year             string   `form:"year" binding:"omitempty,max=9" doc:"A year or range: 2019, 2016-2020, 2010- or -2015"`
+	Venues           []string `form:"venue" binding:"max=10,dive,max=200" doc:"Repeat to match any of several venues"`
+	MinCitationCount int      `form:"min_citation_count" binding:"omitempty,min=0"`
+	OpenAccessOnly   bool     `form:"open_access_only" doc:"Only papers with a freely available PDF"`
+	ProjectID        string   `form:"project_id" binding:"omitempty,uuid" doc:"Rank the page by relevance to this project's title and description instead of keyword order"`
+}
+
+// RecommendedPapersRequest pages paper recommendations, bound from the query string
+type RecommendedPapersRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100" doc:"Papers to return, 20 by default"`
+}
+
+// CitationGraphRequest pages a saved paper's references or citing papers, bound from the query string
+type CitationGraphRequest struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=1000" doc:"Papers per page, 20 by default"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ImportPapersRequest adds Semantic Scholar papers to a project's references
+type ImportPapersRequest struct {
+	PaperIDs []string `json:"paper_ids" binding:"required,min=1,max=500,dive,required,max=64" doc:"Semantic Scholar paper IDs, or prefixed IDs such as DOI:10.1000/xyz"`
+}
+
+// CheckoutRequest starts a Stripe Checkout for a paid plan
+type CheckoutRequest struct {
+	Plan string `json:"plan" binding:"required,oneof=pro institutional"`
+}
+
+// SetFeatureFlagRequest overrides a feature flag at runtime
+type SetFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// SetLogLevelRequest changes a component's log level at runtime
+type SetLogLevelRequest struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
+}
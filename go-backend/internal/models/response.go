@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc" // For direct use or mapping
@@ -9,16 +11,17 @@ import (
 )
 
 type UserResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Email      string    `json:"email"`
-	FirstName  string    `json:"first_name"`
-	LastName   string    `json:"last_name"`
-	IsVerified bool      `json:"is_verified"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	Email          string     `json:"email"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	IsVerified     bool       `json:"is_verified"`
+	GuestExpiresAt *time.Time `json:"guest_expires_at,omitempty" doc:"Set for trial guests: when the guest and its project are deleted unless it registers"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 func ToUserResponse(user sqlc.User) UserResponse {
-	return UserResponse{
+	resp := UserResponse{
 		ID:         user.ID.Bytes, //tobe validated
 		Email:      user.Email,
 		FirstName:  user.FirstName,
@@ -26,6 +29,10 @@ func ToUserResponse(user sqlc.User) UserResponse {
 		IsVerified: user.IsVerified.Bool, // sqlc generates pgtype.Bool for NULLABLE booleans
 		CreatedAt:  user.CreatedAt.Time,  // sqlc generates pgtype.Timestamptz
 	}
+	if user.GuestExpiresAt.Valid {
+		resp.GuestExpiresAt = &user.GuestExpiresAt.Time
+	}
+	return resp
 }
 
 type LoginUserResponse struct {
@@ -37,14 +44,27 @@ type LoginUserResponse struct {
 	User                  UserResponse `json:"user"`
 }
 
+// GuestSessionResponse starts a trial. There is no refresh token: the access token lasts
+// as long as the guest.
+type GuestSessionResponse struct {
+	AccessToken          string          `json:"access_token"`
+	AccessTokenExpiresAt time.Time       `json:"access_token_expires_at"`
+	User                 UserResponse    `json:"user"`
+	Project              ProjectResponse `json:"project" doc:"The guest's sandbox project"`
+}
+
 type ProjectResponse struct {
 	ID             uuid.UUID           `json:"id"`
 	UserID         uuid.UUID           `json:"user_id"`
+	OrganizationID *uuid.UUID          `json:"organization_id,omitempty"`
+	AdvisorID      *uuid.UUID          `json:"advisor_id,omitempty"`
+	AccessRole     string              `json:"access_role,omitempty" doc:"Your role on the project: owner, edit, comment or read"`
 	Title          string              `json:"title"`
 	Specialization string              `json:"specialization"`
 	University     string              `json:"university,omitempty"`
 	Description    string              `json:"description,omitempty"`
 	Status         string              `json:"status"`
+	Language       string              `json:"language"`
 	CreatedAt      time.Time           `json:"created_at"`
 	UpdatedAt      time.Time           `json:"updated_at"`
 	Chapters       []ChapterResponse   `json:"chapters,omitempty"`   // Optionally include chapters
@@ -55,26 +75,242 @@ func ToProjectResponse(project sqlc.ResearchProject) ProjectResponse {
 	return ProjectResponse{
 		ID:             project.ID.Bytes,     //tobe validated
 		UserID:         project.UserID.Bytes, //tobe validated
+		OrganizationID: uuidPtr(project.OrganizationID),
+		AdvisorID:      uuidPtr(project.AdvisorID),
 		Title:          project.Title,
 		Specialization: project.Specialization,
 		University:     project.University.String,
 		Description:    project.Description.String,
 		Status:         project.Status.String,
+		Language:       project.Language,
 		CreatedAt:      project.CreatedAt.Time,
 		UpdatedAt:      project.UpdatedAt.Time,
 	}
 }
 
 type ChapterResponse struct {
-	ID        uuid.UUID `json:"id"`
-	ProjectID uuid.UUID `json:"project_id"`
-	Type      string    `json:"type"`
+	ID        uuid.UUID  `json:"id"`
+	ProjectID uuid.UUID  `json:"project_id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content,omitempty" doc:"CommonMark, sanitized of embedded HTML when saved"` // Content might be large, consider separate endpoint for full content
+	WordCount int32      `json:"word_count"`
+	Status    string     `json:"status"`
+	Version   int32      `json:"version"`
+	Position  int32      `json:"position" doc:"Order in the thesis, from 1; see PUT /projects/{project_id}/chapters/reorder"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // Only set for chapters in the trash
+
+	TargetWordCount *int32 `json:"target_word_count,omitempty"`
+	CustomPrompt    string `json:"custom_prompt,omitempty" doc:"The student's brief for generating the chapter"`
+	// Unresolved comment threads; filled in where the chapter is read, not on writes
+	OpenComments int64 `json:"open_comments"`
+	// Filled in where the chapter is read, when asked for with ?include_html=true
+	ContentHTML string `json:"content_html,omitempty" doc:"Content rendered as HTML, with ?include_html=true"`
+}
+
+// ChapterDraftResponse is an editor's autosaved chapter, falling back to the saved
+// chapter for fields not edited
+type ChapterDraftResponse struct {
+	ChapterID      uuid.UUID `json:"chapter_id"`
+	Title          string    `json:"title"`
+	Content        string    `json:"content"`
+	BaseVersion    int32     `json:"base_version"`
+	ChapterVersion int32     `json:"chapter_version" doc:"Current version of the saved chapter"`
+	Conflict       bool      `json:"conflict" doc:"Someone else saved the chapter after the draft's base version"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ChapterVersionResponse describes one saved version of a chapter
+type ChapterVersionResponse struct {
+	Version   int32     `json:"version"`
 	Title     string    `json:"title"`
-	Content   string    `json:"content,omitempty"` // Content might be large, consider separate endpoint for full content
 	WordCount int32     `json:"word_count"`
-	Status    string    `json:"status"`
+	Status    string    `json:"status" doc:"generated for a version written by AI generation"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChapterDiffChange is a run of lines or words kept, inserted or deleted
+type ChapterDiffChange struct {
+	Op   string `json:"op" doc:"equal, insert or delete"`
+	Text string `json:"text"`
+}
+
+// ChapterDiffResponse is what changed in a chapter between two versions
+type ChapterDiffResponse struct {
+	ChapterID   uuid.UUID              `json:"chapter_id"`
+	From        ChapterVersionResponse `json:"from"`
+	To          ChapterVersionResponse `json:"to"`
+	Granularity string                 `json:"granularity" doc:"line or word"`
+	Added       int                    `json:"added" doc:"Lines or words inserted"`
+	Removed     int                    `json:"removed" doc:"Lines or words deleted"`
+	Changes     []ChapterDiffChange    `json:"changes"`
+}
+
+// ChapterProvenanceResponse breaks a chapter's content down by who wrote it, for
+// academic-integrity disclosure
+type ChapterProvenanceResponse struct {
+	ChapterID      uuid.UUID                `json:"chapter_id"`
+	ChapterVersion int32                    `json:"chapter_version"`
+	AIWords        int                      `json:"ai_words"`
+	HumanWords     int                      `json:"human_words"`
+	UnknownWords   int                      `json:"unknown_words" doc:"Written before provenance was tracked"`
+	AIShare        float64                  `json:"ai_share" doc:"Fraction of the words written by AI generation, 0 to 1"`
+	Spans          []ProvenanceSpanResponse `json:"spans"`
+}
+
+// IntegrityPolicyResponse is the deployment's academic-integrity policy and where the
+// user stands with it
+type IntegrityPolicyResponse struct {
+	Version                string     `json:"version"`
+	URL                    string     `json:"url,omitempty" doc:"The policy's text"`
+	AcknowledgmentRequired bool       `json:"acknowledgment_required" doc:"AI generation is refused until the current version is acknowledged"`
+	AcknowledgedAt         *time.Time `json:"acknowledged_at,omitempty" doc:"When the user acknowledged the current version; absent if they have not"`
+	WatermarkExports       bool       `json:"watermark_exports" doc:"Exported documents mark the chapters and sections AI wrote"`
+	BatchGenerationBlocked bool       `json:"batch_generation_blocked" doc:"Institutional projects cannot have their chapters generated in one batch"`
+}
+
+// ProvenanceSpanResponse is a run of content from one origin; the spans in order make up
+// the whole chapter
+type ProvenanceSpanResponse struct {
+	Origin string `json:"origin" doc:"ai, human or unknown"`
+	Text   string `json:"text"`
+	Words  int    `json:"words"`
+}
+
+// AIDetectionResponse estimates how likely an AI detector is to flag a chapter
+type AIDetectionResponse struct {
+	ChapterID   uuid.UUID            `json:"chapter_id"`
+	Detector    string               `json:"detector"`
+	Score       float64              `json:"score" doc:"0 (reads as human) to 1 (reads as AI), weighted by words"`
+	Verdict     string               `json:"verdict" doc:"likely_human, mixed or likely_ai"`
+	ScoredWords int                  `json:"scored_words" doc:"Words in paragraphs long enough to score"`
+	Passages    []AIDetectionPassage `json:"passages" doc:"Paragraphs likely to be flagged, in order"`
+}
+
+// AIDetectionPassage is a paragraph likely to be flagged. Start and End are character
+// offsets into the scored content.
+type AIDetectionPassage struct {
+	Text    string   `json:"text"`
+	Start   int      `json:"start"`
+	End     int      `json:"end"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// ChapterMetricsResponse holds a chapter's readability and academic-tone metrics
+type ChapterMetricsResponse struct {
+	ChapterID      uuid.UUID `json:"chapter_id"`
+	ChapterVersion int32     `json:"chapter_version" doc:"Version the metrics were computed for"`
+	ComputedAt     time.Time `json:"computed_at"`
+	TextMetrics
+}
+
+// TextMetrics measures the prose of a text, headings, tables and figure markers aside.
+// The readability formulas are calibrated for English.
+type TextMetrics struct {
+	Words              int                    `json:"words"`
+	Sentences          int                    `json:"sentences"`
+	FleschReadingEase  float64                `json:"flesch_reading_ease" doc:"0-100, higher is easier; academic prose typically scores 10-40"`
+	FleschKincaidGrade float64                `json:"flesch_kincaid_grade" doc:"US school grade needed to follow the text"`
+	SentenceLength     SentenceLengthStats    `json:"sentence_length"`
+	PassiveSentences   int                    `json:"passive_sentences"`
+	PassiveRatio       float64                `json:"passive_ratio" doc:"Share of sentences in the passive voice, 0 to 1"`
+	Hedges             int                    `json:"hedges" doc:"Hedging words and phrases such as may, suggest, likely"`
+	HedgesPer1000Words float64                `json:"hedges_per_1000_words"`
+	HedgeTerms         map[string]int         `json:"hedge_terms,omitempty"`
+	LengthBuckets      []SentenceLengthBucket `json:"length_buckets"`
+}
+
+// SentenceLengthStats summarizes sentence lengths in words
+type SentenceLengthStats struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"std_dev"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+}
+
+// SentenceLengthBucket counts the sentences within a range of lengths
+type SentenceLengthBucket struct {
+	Range     string `json:"range" doc:"Words, e.g. 11-20 or 41+"`
+	Sentences int    `json:"sentences"`
+}
+
+// ChapterEvaluationResponse is an AI evaluation of a chapter version against a rubric
+type ChapterEvaluationResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	ChapterID      uuid.UUID         `json:"chapter_id"`
+	ChapterVersion int32             `json:"chapter_version" doc:"Version of the chapter that was evaluated"`
+	Rubric         []RubricCriterion `json:"rubric"`
+	Criteria       []CriterionScore  `json:"criteria"`
+	OverallScore   float64           `json:"overall_score" doc:"Weighted mean of the criterion scores"`
+	MaxScore       int               `json:"max_score" doc:"Top of the scale; scores run from 1"`
+	Summary        string            `json:"summary"`
+	EvaluatedBy    *uuid.UUID        `json:"evaluated_by,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// CriterionScore is how a chapter did on one rubric criterion
+type CriterionScore struct {
+	Key           string   `json:"key"`
+	Score         float64  `json:"score"`
+	Justification string   `json:"justification"`
+	Suggestions   []string `json:"suggestions" doc:"Concrete improvements, most important first"`
+}
+
+// DefenseQuestionsResponse is the questions examiners are likely to ask at the viva,
+// by chapter and about the thesis as a whole
+type DefenseQuestionsResponse struct {
+	ProjectID uuid.UUID                 `json:"project_id"`
+	Chapters  []DefenseChapterQuestions `json:"chapters"`
+	General   []DefenseQuestion         `json:"general" doc:"Questions about the thesis as a whole"`
+}
+
+type DefenseChapterQuestions struct {
+	ChapterID    uuid.UUID         `json:"chapter_id"`
+	ChapterType  string            `json:"chapter_type"`
+	ChapterTitle string            `json:"chapter_title"`
+	Questions    []DefenseQuestion `json:"questions"`
+}
+
+type DefenseQuestion struct {
+	Question      string   `json:"question"`
+	Rationale     string   `json:"rationale,omitempty" doc:"Why an examiner would ask it"`
+	AnswerOutline []string `json:"answer_outline" doc:"Points a good answer covers"`
+}
+
+// ProjectProgressResponse compares the words written in a project's chapters with their targets
+type ProjectProgressResponse struct {
+	ProjectID       uuid.UUID         `json:"project_id"`
+	WordsWritten    int32             `json:"words_written"`
+	TargetWords     int32             `json:"target_words" doc:"Sum of the chapters' word targets; 0 when no chapter has one"`
+	PercentComplete *float64          `json:"percent_complete,omitempty" doc:"Progress toward the targets, each chapter counting up to its own target"`
+	StatusCounts    map[string]int    `json:"status_counts" doc:"Number of chapters per status"`
+	Chapters        []ChapterProgress `json:"chapters"`
+}
+
+type ChapterProgress struct {
+	ID              uuid.UUID `json:"id"`
+	Type            string    `json:"type"`
+	Title           string    `json:"title"`
+	Status          string    `json:"status"`
+	WordCount       int32     `json:"word_count"`
+	TargetWordCount *int32    `json:"target_word_count,omitempty"`
+	PercentComplete *float64  `json:"percent_complete,omitempty"`
+}
+
+// ChapterFields lists the selectable JSON fields of ChapterResponse for ?fields=
+var ChapterFields = []string{"id", "project_id", "type", "title", "content", "word_count", "target_word_count", "custom_prompt", "status", "version", "position", "created_at", "updated_at", "open_comments", "content_html"}
+
+// ToChapterResponseWithOptions maps a chapter, leaving Content empty (and thus omitted) when includeContent is false
+func ToChapterResponseWithOptions(chapter sqlc.Chapter, includeContent bool) ChapterResponse {
+	resp := ToChapterResponse(chapter)
+	if !includeContent {
+		resp.Content = ""
+	}
+	return resp
 }
 
 func ToChapterResponse(chapter sqlc.Chapter) ChapterResponse {
@@ -86,23 +322,66 @@ func ToChapterResponse(chapter sqlc.Chapter) ChapterResponse {
 		Content:   chapter.Content.String,
 		WordCount: chapter.WordCount.Int32,
 		Status:    chapter.Status.String,
+		Version:   chapter.Version,
+		Position:  chapter.Position,
 		CreatedAt: chapter.CreatedAt.Time,
 		UpdatedAt: chapter.UpdatedAt.Time,
+		DeletedAt: timePtr(chapter.DeletedAt),
+
+		TargetWordCount: int32Ptr(chapter.TargetWordCount),
+		CustomPrompt:    chapter.CustomPrompt,
+	}
+}
+
+// SectionResponse is a sub-chapter: numbered under its chapter in the document by
+// its position and level
+type SectionResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	ChapterID   uuid.UUID  `json:"chapter_id"`
+	Title       string     `json:"title"`
+	Level       int32      `json:"level"`
+	Position    int32      `json:"position"`
+	Content     string     `json:"content,omitempty"`
+	ContentHTML string     `json:"content_html,omitempty" doc:"Content rendered as HTML, with ?include_html=true"`
+	WordCount   int32      `json:"word_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	GeneratedAt *time.Time `json:"generated_at,omitempty" doc:"When the AI last wrote the section; absent for sections written by hand"`
+}
+
+func ToSectionResponse(sec sqlc.ChapterSection) SectionResponse {
+	resp := SectionResponse{
+		ID:        sec.ID.Bytes,
+		ChapterID: sec.ChapterID.Bytes,
+		Title:     sec.Title,
+		Level:     sec.Level,
+		Position:  sec.Position,
+		Content:   sec.Content,
+		WordCount: sec.WordCount,
+		CreatedAt: sec.CreatedAt.Time,
+		UpdatedAt: sec.UpdatedAt.Time,
 	}
+	if sec.GeneratedAt.Valid {
+		resp.GeneratedAt = &sec.GeneratedAt.Time
+	}
+	return resp
 }
 
 type ReferenceResponse struct {
-	ID              uuid.UUID `json:"id"`
-	ProjectID       uuid.UUID `json:"project_id"`
-	Title           string    `json:"title"`
-	Authors         string    `json:"authors,omitempty"`
-	Journal         string    `json:"journal,omitempty"`
-	PublicationYear int       `json:"publication_year,omitempty"`
-	DOI             string    `json:"doi,omitempty"`
-	URL             string    `json:"url,omitempty"`
-	CitationAPA     string    `json:"citation_apa,omitempty"`
-	CitationMLA     string    `json:"citation_mla,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              uuid.UUID  `json:"id"`
+	ProjectID       uuid.UUID  `json:"project_id"`
+	Title           string     `json:"title"`
+	Authors         string     `json:"authors,omitempty"`
+	Journal         string     `json:"journal,omitempty"`
+	PublicationYear int        `json:"publication_year,omitempty"`
+	DOI             string     `json:"doi,omitempty"`
+	URL             string     `json:"url,omitempty"`
+	CitationAPA     string     `json:"citation_apa,omitempty"`
+	CitationMLA     string     `json:"citation_mla,omitempty"`
+	Abstract        string     `json:"abstract,omitempty"`
+	Tags            []string   `json:"tags,omitempty" doc:"Set by the reference listing and tagging endpoints"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"` // Only set for references in the trash
 }
 
 func ToReferenceResponse(ref sqlc.Reference) ReferenceResponse {
@@ -121,7 +400,443 @@ func ToReferenceResponse(ref sqlc.Reference) ReferenceResponse {
 		URL:             ref.Url.String,
 		CitationAPA:     ref.CitationApa.String,
 		CitationMLA:     ref.CitationMla.String,
+		Abstract:        ref.Abstract.String,
 		CreatedAt:       ref.CreatedAt.Time,
+		DeletedAt:       timePtr(ref.DeletedAt),
+	}
+}
+
+// TrashResponse lists a project's deleted items awaiting restore or purge
+type TrashResponse struct {
+	Chapters   []ChapterResponse   `json:"chapters"`
+	References []ReferenceResponse `json:"references"`
+}
+
+// SearchResultResponse is one full-text search hit within a project
+type SearchResultResponse struct {
+	Kind        string    `json:"kind" doc:"chapter or reference"`
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	ChapterType string    `json:"chapter_type,omitempty"`
+	Snippet     string    `json:"snippet" doc:"Matching fragments as escaped HTML, with matches wrapped in <mark> tags"`
+	Rank        float32   `json:"rank"`
+}
+
+type ReferenceHighlightResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Text      string    `json:"text"`
+	Comment   string    `json:"comment"`
+	Page      *int32    `json:"page,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToReferenceHighlightResponse(h sqlc.ReferenceHighlight) ReferenceHighlightResponse {
+	resp := ReferenceHighlightResponse{
+		ID:        h.ID.Bytes,
+		Text:      h.Text,
+		Comment:   h.Comment,
+		CreatedAt: h.CreatedAt.Time,
+	}
+	if h.Page.Valid {
+		resp.Page = &h.Page.Int32
+	}
+	return resp
+}
+
+type ReferenceNotesResponse struct {
+	ReferenceID uuid.UUID                    `json:"reference_id"`
+	Notes       string                       `json:"notes"`
+	Highlights  []ReferenceHighlightResponse `json:"highlights" doc:"Oldest first"`
+	UpdatedAt   *time.Time                   `json:"updated_at,omitempty" doc:"When the notes last changed; absent until they are first written"`
+}
+
+// CitationResponse is an in-text citation in a chapter and the reference it resolved to
+type CitationResponse struct {
+	ID        uuid.UUID          `json:"id"`
+	Marker    string             `json:"marker" doc:"The citation as written, e.g. Smith et al., 2020"`
+	Author    string             `json:"author"`
+	Year      int32              `json:"year"`
+	Position  int32              `json:"position" doc:"Byte offset of the marker in the chapter content"`
+	Reference *ReferenceResponse `json:"reference,omitempty" doc:"Absent when no reference in the project matches"`
+}
+
+// SimilarReferenceResponse is a reference ranked by semantic similarity to a passage
+type SimilarReferenceResponse struct {
+	ReferenceResponse
+	Similarity float32 `json:"similarity" doc:"Cosine similarity between the passage and the reference, 1 is identical"`
+}
+
+type OrganizationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	Role      string     `json:"role,omitempty" doc:"Your role: owner, admin or member"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func ToOrganizationResponse(org sqlc.Organization, role string) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        org.ID.Bytes,
+		Name:      org.Name,
+		CreatedBy: uuidPtr(org.CreatedBy),
+		Role:      role,
+		CreatedAt: org.CreatedAt.Time,
+	}
+}
+
+// ChapterTypeResponse is a type chapters can have: built in, or added by an organization
+// or for a single project
+type ChapterTypeResponse struct {
+	Key            string     `json:"key"`
+	Label          string     `json:"label"`
+	Description    string     `json:"description,omitempty"`
+	Position       int32      `json:"position"`
+	BuiltIn        bool       `json:"built_in"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	ProjectID      *uuid.UUID `json:"project_id,omitempty"`
+}
+
+func ToChapterTypeResponse(t sqlc.ChapterType) ChapterTypeResponse {
+	return ChapterTypeResponse{
+		Key:            t.Key,
+		Label:          t.Label,
+		Description:    t.Description,
+		Position:       t.Position,
+		BuiltIn:        !t.OrganizationID.Valid && !t.ProjectID.Valid,
+		OrganizationID: uuidPtr(t.OrganizationID),
+		ProjectID:      uuidPtr(t.ProjectID),
+	}
+}
+
+type OrganizationMemberResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+func ToOrganizationMemberResponse(m sqlc.ListOrganizationMembersRow) OrganizationMemberResponse {
+	return OrganizationMemberResponse{
+		UserID:    m.UserID.Bytes,
+		Email:     m.Email,
+		FirstName: m.FirstName,
+		LastName:  m.LastName,
+		Role:      m.Role,
+		JoinedAt:  m.CreatedAt.Time,
+	}
+}
+
+type CollaboratorResponse struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Role      string     `json:"role" doc:"read, comment or edit"`
+	AddedBy   *uuid.UUID `json:"added_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func ToCollaboratorResponse(c sqlc.ListProjectCollaboratorsRow) CollaboratorResponse {
+	return CollaboratorResponse{
+		UserID:    c.UserID.Bytes,
+		Email:     c.Email,
+		FirstName: c.FirstName,
+		LastName:  c.LastName,
+		Role:      c.Role,
+		AddedBy:   uuidPtr(c.AddedBy),
+		CreatedAt: c.CreatedAt.Time,
+	}
+}
+
+// CommentThreadResponse is a thread with its comments, oldest first
+type CommentThreadResponse struct {
+	ID            uuid.UUID         `json:"id"`
+	ChapterID     uuid.UUID         `json:"chapter_id"`
+	AnchorStart   *int32            `json:"anchor_start,omitempty"`
+	AnchorEnd     *int32            `json:"anchor_end,omitempty"`
+	AnchorHeading string            `json:"anchor_heading,omitempty"`
+	QuotedText    string            `json:"quoted_text,omitempty" doc:"Anchored passage as it was when the thread was started"`
+	CreatedBy     *uuid.UUID        `json:"created_by,omitempty"`
+	Resolved      bool              `json:"resolved"`
+	ResolvedAt    *time.Time        `json:"resolved_at,omitempty"`
+	ResolvedBy    *uuid.UUID        `json:"resolved_by,omitempty"`
+	Comments      []CommentResponse `json:"comments"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+type CommentResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	AuthorName string     `json:"author_name,omitempty"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func ToCommentThreadResponse(t sqlc.CommentThread, comments []CommentResponse) CommentThreadResponse {
+	resp := CommentThreadResponse{
+		ID:            t.ID.Bytes,
+		ChapterID:     t.ChapterID.Bytes,
+		AnchorHeading: t.AnchorHeading.String,
+		QuotedText:    t.QuotedText,
+		CreatedBy:     uuidPtr(t.CreatedBy),
+		Resolved:      t.ResolvedAt.Valid,
+		ResolvedAt:    timePtr(t.ResolvedAt),
+		ResolvedBy:    uuidPtr(t.ResolvedBy),
+		Comments:      comments,
+		CreatedAt:     t.CreatedAt.Time,
+		UpdatedAt:     t.UpdatedAt.Time,
+	}
+	if t.AnchorStart.Valid && t.AnchorEnd.Valid {
+		resp.AnchorStart = &t.AnchorStart.Int32
+		resp.AnchorEnd = &t.AnchorEnd.Int32
+	}
+	if resp.Comments == nil {
+		resp.Comments = []CommentResponse{}
+	}
+	return resp
+}
+
+func ToCommentResponse(c sqlc.ListChapterCommentsRow) CommentResponse {
+	return CommentResponse{
+		ID:         c.ID.Bytes,
+		UserID:     uuidPtr(c.UserID),
+		AuthorName: strings.TrimSpace(c.FirstName.String + " " + c.LastName.String),
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt.Time,
+	}
+}
+
+// ReviewResponse is one submission of a chapter for review and, once made, the advisor's decision
+type ReviewResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	ProjectID      uuid.UUID  `json:"project_id"`
+	ChapterID      uuid.UUID  `json:"chapter_id"`
+	ChapterVersion int32      `json:"chapter_version" doc:"Chapter version that was submitted"`
+	Status         string     `json:"status" doc:"pending, approved or changes_requested"`
+	SubmittedBy    *uuid.UUID `json:"submitted_by,omitempty"`
+	SubmissionNote string     `json:"submission_note,omitempty"`
+	ReviewedBy     *uuid.UUID `json:"reviewed_by,omitempty"`
+	DecisionNote   string     `json:"decision_note,omitempty"`
+	SubmittedAt    time.Time  `json:"submitted_at"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+}
+
+func ToReviewResponse(r sqlc.ChapterReview) ReviewResponse {
+	return ReviewResponse{
+		ID:             r.ID.Bytes,
+		ProjectID:      r.ProjectID.Bytes,
+		ChapterID:      r.ChapterID.Bytes,
+		ChapterVersion: r.ChapterVersion,
+		Status:         r.Status,
+		SubmittedBy:    uuidPtr(r.SubmittedBy),
+		SubmissionNote: r.SubmissionNote,
+		ReviewedBy:     uuidPtr(r.ReviewedBy),
+		DecisionNote:   r.DecisionNote,
+		SubmittedAt:    r.SubmittedAt.Time,
+		DecidedAt:      timePtr(r.DecidedAt),
+	}
+}
+
+// ReviewQueueItemResponse is a pending review in an advisor's queue
+type ReviewQueueItemResponse struct {
+	ReviewResponse
+	ProjectTitle string `json:"project_title"`
+	ChapterTitle string `json:"chapter_title"`
+	ChapterType  string `json:"chapter_type"`
+}
+
+func ToReviewQueueItemResponse(r sqlc.ListReviewQueueRow) ReviewQueueItemResponse {
+	return ReviewQueueItemResponse{
+		ReviewResponse: ToReviewResponse(r.ChapterReview),
+		ProjectTitle:   r.ProjectTitle,
+		ChapterTitle:   r.ChapterTitle,
+		ChapterType:    r.ChapterType,
+	}
+}
+
+type NotificationResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Type       string     `json:"type" doc:"generation.completed, generation.failed, document.ready, comment.added, review.requested or review.decided"`
+	ProjectID  *uuid.UUID `json:"project_id,omitempty"`
+	ChapterID  *uuid.UUID `json:"chapter_id,omitempty"`
+	DocumentID *uuid.UUID `json:"document_id,omitempty"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body,omitempty"`
+	Read       bool       `json:"read"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func ToNotificationResponse(n sqlc.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:         n.ID.Bytes,
+		Type:       n.Type,
+		ProjectID:  uuidPtr(n.ProjectID),
+		ChapterID:  uuidPtr(n.ChapterID),
+		DocumentID: uuidPtr(n.DocumentID),
+		Title:      n.Title,
+		Body:       n.Body,
+		Read:       n.ReadAt.Valid,
+		ReadAt:     timePtr(n.ReadAt),
+		CreatedAt:  n.CreatedAt.Time,
+	}
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	UnreadCount   int64                  `json:"unread_count"`
+}
+
+type NotificationPreferenceResponse struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+type WebhookResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events" doc:"Subscribed events; empty means every event"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty" doc:"Signing secret, only returned when the webhook is created"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToWebhookResponse(w sqlc.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        w.ID.Bytes,
+		ProjectID: w.ProjectID.Bytes,
+		URL:       w.Url,
+		Events:    w.Events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt.Time,
+		UpdatedAt: w.UpdatedAt.Time,
+	}
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status" doc:"pending, succeeded or failed"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus *int32          `json:"response_status,omitempty" doc:"HTTP status of the last attempt"`
+	LastError      string          `json:"last_error,omitempty"`
+	DurationMs     *int32          `json:"duration_ms,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func ToWebhookDeliveryResponse(d sqlc.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:          d.ID.Bytes,
+		WebhookID:   d.WebhookID.Bytes,
+		Event:       d.Event,
+		Payload:     d.Payload,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastError:   d.LastError,
+		DeliveredAt: timePtr(d.DeliveredAt),
+		CreatedAt:   d.CreatedAt.Time,
+	}
+	if d.ResponseStatus.Valid {
+		resp.ResponseStatus = &d.ResponseStatus.Int32
+	}
+	if d.DurationMs.Valid {
+		resp.DurationMs = &d.DurationMs.Int32
+	}
+	if d.Status == "pending" {
+		resp.NextAttemptAt = timePtr(d.NextAttemptAt)
+	}
+	return resp
+}
+
+type PlanResponse struct {
+	Name                  string `json:"name" doc:"free, pro or institutional"`
+	MaxProjects           int    `json:"max_projects" doc:"Projects owned at once; 0 means unlimited"`
+	AIGenerationsPerMonth int    `json:"ai_generations_per_month" doc:"0 means unlimited"`
+	Purchasable           bool   `json:"purchasable"`
+}
+
+type UsageResponse struct {
+	PeriodStart   time.Time `json:"period_start"`
+	Projects      int64     `json:"projects" doc:"Projects owned now"`
+	AIGenerations int64     `json:"ai_generations"`
+}
+
+type SubscriptionResponse struct {
+	Plan              string        `json:"plan"`
+	Status            string        `json:"status" doc:"Stripe subscription status, or none"`
+	CurrentPeriodEnd  *time.Time    `json:"current_period_end,omitempty"`
+	CancelAtPeriodEnd bool          `json:"cancel_at_period_end"`
+	Limits            PlanResponse  `json:"limits"`
+	Usage             UsageResponse `json:"usage"`
+}
+
+type QuotaUsageResponse struct {
+	Resource  string `json:"resource" doc:"projects, ai_words or documents"`
+	Limit     int64  `json:"limit" doc:"Per billing period; 0 means unlimited"`
+	Used      int64  `json:"used"`
+	Remaining *int64 `json:"remaining,omitempty" doc:"Omitted when unlimited"`
+}
+
+func NewQuotaUsageResponse(resource string, limit, used int64) QuotaUsageResponse {
+	resp := QuotaUsageResponse{Resource: resource, Limit: limit, Used: used}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		resp.Remaining = &remaining
+	}
+	return resp
+}
+
+type QuotaResponse struct {
+	Plan        string               `json:"plan"`
+	PeriodStart time.Time            `json:"period_start"`
+	PeriodEnd   time.Time            `json:"period_end" doc:"When usage resets"`
+	Quotas      []QuotaUsageResponse `json:"quotas"`
+}
+
+// QuotaExceededResponse is the data of a 402 or 429 answer to an action that would go past a quota
+type QuotaExceededResponse struct {
+	Plan string `json:"plan"`
+	QuotaUsageResponse
+	ResetsAt   time.Time `json:"resets_at"`
+	Upgradable bool      `json:"upgradable" doc:"Whether a higher plan has a larger quota; the status is 402 if so and 429 otherwise"`
+}
+
+// BillingSessionResponse carries a Stripe-hosted page to redirect the user to
+type BillingSessionResponse struct {
+	URL string `json:"url"`
+}
+
+type InvitationResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	OrganizationName string     `json:"organization_name,omitempty"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	InvitedBy        *uuid.UUID `json:"invited_by,omitempty"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	Token            string     `json:"token,omitempty" doc:"Accepts the invitation; only returned on creation when email is not configured, to pass on to the invitee"`
+}
+
+func ToInvitationResponse(inv sqlc.OrganizationInvitation) InvitationResponse {
+	return InvitationResponse{
+		ID:             inv.ID.Bytes,
+		OrganizationID: inv.OrganizationID.Bytes,
+		Email:          inv.Email,
+		Role:           inv.Role,
+		InvitedBy:      uuidPtr(inv.InvitedBy),
+		ExpiresAt:      inv.ExpiresAt.Time,
+		CreatedAt:      inv.CreatedAt.Time,
 	}
 }
 
@@ -149,6 +864,461 @@ func ToGeneratedDocumentResponse(doc sqlc.GeneratedDocument) GeneratedDocumentRe
 	}
 }
 
+type UploadResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	ProjectID        uuid.UUID  `json:"project_id"`
+	UploadedBy       *uuid.UUID `json:"uploaded_by,omitempty"`
+	FileName         string     `json:"file_name"`
+	FileSize         int64      `json:"file_size"`
+	ExtractionStatus string     `json:"extraction_status" doc:"pending, completed or failed"`
+	ExtractionError  string     `json:"extraction_error,omitempty"`
+	PageCount        *int32     `json:"page_count,omitempty"`
+	Text             string     `json:"text,omitempty" doc:"Extracted text, only returned when fetching a single upload"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func ToUploadResponse(u sqlc.ProjectUpload) UploadResponse {
+	resp := UploadResponse{
+		ID:               u.ID.Bytes,
+		ProjectID:        u.ProjectID.Bytes,
+		UploadedBy:       uuidPtr(u.UploadedBy),
+		FileName:         u.FileName,
+		FileSize:         u.FileSize,
+		ExtractionStatus: u.ExtractionStatus,
+		ExtractionError:  u.ExtractionError,
+		CreatedAt:        u.CreatedAt.Time,
+	}
+	if u.PageCount.Valid {
+		resp.PageCount = &u.PageCount.Int32
+	}
+	return resp
+}
+
+type UploadSessionResponse struct {
+	ID           uuid.UUID  `json:"id"`
+	ProjectID    uuid.UUID  `json:"project_id"`
+	FileName     string     `json:"file_name"`
+	Size         int64      `json:"size"`
+	Offset       int64      `json:"offset" doc:"Bytes received so far; the next chunk starts here"`
+	Status       string     `json:"status" doc:"receiving, assembling, completed or failed"`
+	Error        string     `json:"error,omitempty"`
+	UploadID     *uuid.UUID `json:"upload_id,omitempty" doc:"The upload the file became, once completed"`
+	MaxChunkSize int64      `json:"max_chunk_size" doc:"Largest chunk accepted, in bytes"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func ToUploadSessionResponse(u sqlc.UploadSession, maxChunkSize int64) UploadSessionResponse {
+	return UploadSessionResponse{
+		ID:           u.ID.Bytes,
+		ProjectID:    u.ProjectID.Bytes,
+		FileName:     u.FileName,
+		Size:         u.TotalSize,
+		Offset:       u.ReceivedSize,
+		Status:       u.Status,
+		Error:        u.Error,
+		UploadID:     uuidPtr(u.UploadID),
+		MaxChunkSize: maxChunkSize,
+		ExpiresAt:    u.ExpiresAt.Time,
+		CreatedAt:    u.CreatedAt.Time,
+	}
+}
+
+// FormattingOptions controls the layout of a generated document; unset fields use the defaults
+type FormattingOptions struct {
+	FontFamily         string   `json:"font_family,omitempty" binding:"omitempty,max=100"`
+	FontSizeMain       float64  `json:"font_size_main,omitempty" binding:"omitempty,min=8,max=16" doc:"Body text size in points"`
+	LineSpacing        float64  `json:"line_spacing,omitempty" binding:"omitempty,min=1,max=3"`
+	PaperSize          string   `json:"paper_size,omitempty" binding:"omitempty,oneof=A4 Letter"`
+	MarginTopCm        float64  `json:"margin_top_cm,omitempty" binding:"omitempty,min=0.5,max=6"`
+	MarginBottomCm     float64  `json:"margin_bottom_cm,omitempty" binding:"omitempty,min=0.5,max=6"`
+	MarginLeftCm       float64  `json:"margin_left_cm,omitempty" binding:"omitempty,min=0.5,max=6"`
+	MarginRightCm      float64  `json:"margin_right_cm,omitempty" binding:"omitempty,min=0.5,max=6"`
+	ChapterTitleFormat string   `json:"chapter_title_format,omitempty" binding:"omitempty,max=100" doc:"Chapter heading pattern with {number} and {title}, e.g. \"Chapter {number}: {title}\""`
+	ChapterNumbering   string   `json:"chapter_numbering,omitempty" binding:"omitempty,oneof=arabic roman" doc:"How {number} is written in chapter headings: arabic (1, 2, 3), the default, or roman (I, II, III)"`
+	CitationStyle      string   `json:"citation_style,omitempty" binding:"omitempty,oneof=APA MLA Harvard Chicago IEEE"`
+	Language           string   `json:"language,omitempty" binding:"omitempty,oneof=en ar" doc:"Language of the document's fixed labels; ar lays it out right to left"`
+	FontFamilyComplex  string   `json:"font_family_complex,omitempty" binding:"omitempty,max=100" doc:"Font for Arabic script, e.g. \"Simplified Arabic\""`
+	FrontMatter        []string `json:"front_matter,omitempty" binding:"omitempty,max=7,dive,oneof=title_page declaration dedication acknowledgments table_of_contents list_of_figures list_of_tables" doc:"Pages before the first chapter, in order; by default the title page, table of contents and, when the document has any figures or tables, their lists. The listings are Word fields, updated when the document is opened"`
+}
+
+// FrontMatter is the project's own text for the front matter of its thesis document;
+// which pages appear is up to the formatting options
+type FrontMatter struct {
+	Degree          string `json:"degree,omitempty" binding:"omitempty,max=200" doc:"Degree the thesis is submitted for, e.g. \"Master of Science in Computer Science\""`
+	Supervisor      string `json:"supervisor,omitempty" binding:"omitempty,max=200"`
+	Declaration     string `json:"declaration,omitempty" binding:"omitempty,max=4000" doc:"Replaces the standard declaration of originality"`
+	Dedication      string `json:"dedication,omitempty" binding:"omitempty,max=2000"`
+	Acknowledgments string `json:"acknowledgments,omitempty" binding:"omitempty,max=10000"`
+}
+
+// GenerationPreferences steer how the AI writes: a user's apply to everything they
+// generate, and a project's over them; unset fields fall back to the user's and then the
+// defaults
+type GenerationPreferences struct {
+	Temperature    *float64 `json:"temperature,omitempty" binding:"omitempty,gt=0,lte=2" doc:"Sampling temperature for chapters, sections and other long-form writing; by default each kind of writing has its own"`
+	ReadingLevel   string   `json:"reading_level,omitempty" binding:"omitempty,oneof=secondary undergraduate postgraduate expert" doc:"Readers the writing is pitched at"`
+	EnglishVariant string   `json:"english_variant,omitempty" binding:"omitempty,oneof=us uk" doc:"American or British spelling and usage for English writing"`
+	Verbosity      string   `json:"verbosity,omitempty" binding:"omitempty,oneof=concise balanced detailed" doc:"How fully points are developed within the length asked for"`
+}
+
+// GenerationPreferencesResponse is a user's or project's own generation preferences and
+// those generation uses for the user, once the levels are combined
+type GenerationPreferencesResponse struct {
+	Preferences GenerationPreferences `json:"preferences"`
+	Effective   GenerationPreferences `json:"effective" doc:"What generation uses: the project's preferences, on project routes, over your own, then the defaults"`
+}
+
+// GenerationResponse is one AI call from the generation history
+type GenerationResponse struct {
+	ID               uuid.UUID       `json:"id"`
+	UserID           *uuid.UUID      `json:"user_id,omitempty" doc:"Who the call was made for; absent for calls made by background work or for deleted users"`
+	ChapterID        *uuid.UUID      `json:"chapter_id,omitempty"`
+	Kind             string          `json:"kind" doc:"What was generated: a chapter type, section, chapter_evaluation, executive_summary and so on"`
+	Model            string          `json:"model"`
+	Temperature      float64         `json:"temperature"`
+	MaxTokens        int32           `json:"max_tokens"`
+	PromptHash       string          `json:"prompt_hash" doc:"SHA-256 of the messages sent; the same prompt always has the same hash"`
+	Prompt           json.RawMessage `json:"prompt" doc:"The messages sent, each cut to a length limit"`
+	Response         string          `json:"response" doc:"The reply, cut to a length limit; empty when the call failed"`
+	PromptTokens     int32           `json:"prompt_tokens"`
+	CompletionTokens int32           `json:"completion_tokens"`
+	LatencyMs        int32           `json:"latency_ms"`
+	Outcome          string          `json:"outcome" doc:"success, error, leak (a reply that repeated its instructions and was discarded) or cached (the reply to an identical request made shortly before, reused without spending tokens)"`
+	Error            string          `json:"error,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+func ToGenerationResponse(g sqlc.Generation) GenerationResponse {
+	return GenerationResponse{
+		ID:               g.ID.Bytes,
+		UserID:           uuidPtr(g.UserID),
+		ChapterID:        uuidPtr(g.ChapterID),
+		Kind:             g.Kind,
+		Model:            g.Model,
+		Temperature:      g.Temperature,
+		MaxTokens:        g.MaxTokens,
+		PromptHash:       g.PromptHash,
+		Prompt:           g.Prompt,
+		Response:         g.Response,
+		PromptTokens:     g.PromptTokens,
+		CompletionTokens: g.CompletionTokens,
+		LatencyMs:        g.LatencyMs,
+		Outcome:          g.Outcome,
+		Error:            g.Error.String,
+		CreatedAt:        g.CreatedAt.Time,
+	}
+}
+
+// GenerationBatchChapter is the progress of one chapter in a "generate all chapters" run
+type GenerationBatchChapter struct {
+	ChapterID uuid.UUID `json:"chapter_id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status" doc:"pending, running, completed, skipped or failed"`
+	Reason    string    `json:"reason,omitempty" doc:"Why the chapter was skipped or failed"`
+}
+
+// GenerationBatchResponse is a "generate all chapters" run
+type GenerationBatchResponse struct {
+	ID          uuid.UUID                `json:"id"`
+	ProjectID   uuid.UUID                `json:"project_id"`
+	UserID      *uuid.UUID               `json:"user_id,omitempty" doc:"Who started the run; absent once the user is deleted"`
+	Status      string                   `json:"status" doc:"pending, running, completed or failed; a completed run can still have chapters that failed"`
+	Chapters    []GenerationBatchChapter `json:"chapters" doc:"In generation order: literature review, introduction, methodology, results, the other chapters in document order, then the executive summary"`
+	Error       string                   `json:"error,omitempty"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	CompletedAt *time.Time               `json:"completed_at,omitempty"`
+}
+
+func ToGenerationBatchResponse(b sqlc.GenerationBatch) GenerationBatchResponse {
+	resp := GenerationBatchResponse{
+		ID:          b.ID.Bytes,
+		ProjectID:   b.ProjectID.Bytes,
+		UserID:      uuidPtr(b.UserID),
+		Status:      b.Status,
+		Chapters:    []GenerationBatchChapter{},
+		Error:       b.Error.String,
+		CreatedAt:   b.CreatedAt.Time,
+		UpdatedAt:   b.UpdatedAt.Time,
+		CompletedAt: timePtr(b.CompletedAt),
+	}
+	_ = json.Unmarshal(b.Chapters, &resp.Chapters) // Written by the service from this type
+	return resp
+}
+
+type GuidelineResponse struct {
+	ID        uuid.UUID         `json:"id"`
+	ProjectID uuid.UUID         `json:"project_id"`
+	FileName  string            `json:"file_name"`
+	MimeType  string            `json:"mime_type"`
+	FileSize  int64             `json:"file_size"`
+	Status    string            `json:"status" doc:"pending, completed or failed"`
+	Error     string            `json:"error,omitempty"`
+	Rules     FormattingOptions `json:"rules" doc:"Formatting rules extracted from the guideline, applied to the project once completed"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func ToGuidelineResponse(g sqlc.ProjectGuideline) GuidelineResponse {
+	resp := GuidelineResponse{
+		ID:        g.ID.Bytes,
+		ProjectID: g.ProjectID.Bytes,
+		FileName:  g.FileName,
+		MimeType:  g.MimeType,
+		FileSize:  g.FileSize,
+		Status:    g.Status,
+		Error:     g.Error,
+		CreatedAt: g.CreatedAt.Time,
+		UpdatedAt: g.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(g.Rules, &resp.Rules) // Written by the service from this type
+	return resp
+}
+
+type FigureResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	ChapterID uuid.UUID  `json:"chapter_id"`
+	Kind      string     `json:"kind" doc:"figure or table"`
+	Number    int        `json:"number" doc:"Number among the chapter's figures or tables; the document prefixes the chapter number, e.g. Figure 2.1"`
+	Caption   string     `json:"caption"`
+	Position  int32      `json:"position"`
+	Marker    string     `json:"marker" doc:"Line to put in the chapter content where the figure or table belongs; without one it follows the chapter text"`
+	MimeType  string     `json:"mime_type,omitempty" doc:"Image type of a figure"`
+	Rows      [][]string `json:"rows,omitempty" doc:"Cells of a table, the first row its header"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// FigureMarker is the line of chapter content that places a figure or table
+func FigureMarker(kind string, id uuid.UUID) string {
+	return "[[" + kind + ":" + id.String() + "]]"
+}
+
+func ToFigureResponse(f sqlc.ChapterFigure, number int) FigureResponse {
+	resp := FigureResponse{
+		ID:        f.ID.Bytes,
+		ChapterID: f.ChapterID.Bytes,
+		Kind:      f.Kind,
+		Number:    number,
+		Caption:   f.Caption,
+		Position:  f.Position,
+		Marker:    FigureMarker(f.Kind, f.ID.Bytes),
+		MimeType:  f.MimeType,
+		CreatedAt: f.CreatedAt.Time,
+		UpdatedAt: f.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(f.TableData, &resp.Rows) // Written by the service from this type
+	if len(resp.Rows) == 0 {
+		resp.Rows = nil
+	}
+	return resp
+}
+
+type AppendixResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Letter    string    `json:"letter" doc:"A, B, ... following position; Z is followed by AA"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Position  int32     `json:"position"`
+	MimeType  string    `json:"mime_type,omitempty" doc:"Type of the attached image, if any"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func ToAppendixResponse(a sqlc.ProjectAppendix, letter string) AppendixResponse {
+	return AppendixResponse{
+		ID:        a.ID.Bytes,
+		ProjectID: a.ProjectID.Bytes,
+		Letter:    letter,
+		Title:     a.Title,
+		Content:   a.Content,
+		Position:  a.Position,
+		MimeType:  a.MimeType,
+		CreatedAt: a.CreatedAt.Time,
+		UpdatedAt: a.UpdatedAt.Time,
+	}
+}
+
+// TimelineTask is one task of a research timeline; its dates are inclusive
+type TimelineTask struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	ChapterType  string   `json:"chapter_type,omitempty" doc:"Chapter the task works on, if any"`
+	DurationDays int      `json:"duration_days"`
+	DependsOn    []string `json:"depends_on" doc:"IDs of the tasks that must finish before this one starts"`
+	StartDate    string   `json:"start_date"`
+	EndDate      string   `json:"end_date"`
+}
+
+type TimelineResponse struct {
+	ProjectID         uuid.UUID           `json:"project_id"`
+	StartDate         string              `json:"start_date"`
+	EndDate           string              `json:"end_date"`
+	Milestones        []TimelineMilestone `json:"milestones"`
+	Tasks             []TimelineTask      `json:"tasks" doc:"In order of start date"`
+	IncludeInDocument bool                `json:"include_in_document"`
+	GeneratedAt       time.Time           `json:"generated_at"`
+}
+
+func ToTimelineResponse(t sqlc.ProjectTimeline) TimelineResponse {
+	resp := TimelineResponse{
+		ProjectID:         t.ProjectID.Bytes,
+		StartDate:         t.StartDate.Time.Format(time.DateOnly),
+		EndDate:           t.EndDate.Time.Format(time.DateOnly),
+		Milestones:        []TimelineMilestone{},
+		IncludeInDocument: t.IncludeInDocument,
+		GeneratedAt:       t.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(t.Milestones, &resp.Milestones) // Written by the service from these types
+	_ = json.Unmarshal(t.Tasks, &resp.Tasks)
+	return resp
+}
+
+type MethodologyResponse struct {
+	ProjectID uuid.UUID          `json:"project_id"`
+	Answers   MethodologyAnswers `json:"answers"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+func ToMethodologyResponse(m sqlc.ProjectMethodology) MethodologyResponse {
+	resp := MethodologyResponse{
+		ProjectID: m.ProjectID.Bytes,
+		UpdatedAt: m.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(m.Answers, &resp.Answers) // Written by the service from this type
+	return resp
+}
+
+type QuestionnaireResponse struct {
+	ID                uuid.UUID              `json:"id"`
+	ProjectID         uuid.UUID              `json:"project_id"`
+	Title             string                 `json:"title"`
+	Instructions      string                 `json:"instructions"`
+	Sections          []QuestionnaireSection `json:"sections"`
+	ItemCount         int                    `json:"item_count"`
+	IncludeInDocument bool                   `json:"include_in_document"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+func ToQuestionnaireResponse(q sqlc.Questionnaire) QuestionnaireResponse {
+	resp := QuestionnaireResponse{
+		ID:                q.ID.Bytes,
+		ProjectID:         q.ProjectID.Bytes,
+		Title:             q.Title,
+		Instructions:      q.Instructions,
+		Sections:          []QuestionnaireSection{},
+		IncludeInDocument: q.IncludeInDocument,
+		CreatedAt:         q.CreatedAt.Time,
+		UpdatedAt:         q.UpdatedAt.Time,
+	}
+	_ = json.Unmarshal(q.Sections, &resp.Sections) // Written by the service from this type
+	for _, section := range resp.Sections {
+		resp.ItemCount += len(section.Items)
+	}
+	return resp
+}
+
+// DatasetColumn is one column of an uploaded dataset with its descriptive statistics.
+// Numeric columns have the mean through max; categorical ones their frequencies.
+type DatasetColumn struct {
+	Name        string              `json:"name"`
+	Kind        string              `json:"kind" doc:"numeric or categorical"`
+	Count       int                 `json:"count" doc:"Rows with a value"`
+	Missing     int                 `json:"missing"`
+	Mean        *float64            `json:"mean,omitempty"`
+	StdDev      *float64            `json:"std_dev,omitempty" doc:"Sample standard deviation"`
+	Min         *float64            `json:"min,omitempty"`
+	Median      *float64            `json:"median,omitempty"`
+	Max         *float64            `json:"max,omitempty"`
+	Unique      int                 `json:"unique,omitempty" doc:"Distinct values of a categorical column"`
+	Frequencies []CategoryFrequency `json:"frequencies,omitempty" doc:"Most frequent values first"`
+}
+
+type CategoryFrequency struct {
+	Value   string  `json:"value"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent" doc:"Of the rows with a value"`
+}
+
+type DatasetResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	ProjectID  uuid.UUID       `json:"project_id"`
+	FileName   string          `json:"file_name"`
+	RowCount   int32           `json:"row_count"`
+	Columns    []DatasetColumn `json:"columns"`
+	TableCount int             `json:"table_count" doc:"Tables of the results chapter generated from the dataset"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func ToDatasetResponse(d sqlc.ProjectDataset) DatasetResponse {
+	resp := DatasetResponse{
+		ID:         d.ID.Bytes,
+		ProjectID:  d.ProjectID.Bytes,
+		FileName:   d.FileName,
+		RowCount:   d.RowCount,
+		Columns:    []DatasetColumn{},
+		TableCount: len(d.TableIds),
+		CreatedAt:  d.CreatedAt.Time,
+	}
+	_ = json.Unmarshal(d.Columns, &resp.Columns) // Written by the service from this type
+	return resp
+}
+
+type AnalysisInterpretationResponse struct {
+	Interpretation string `json:"interpretation" doc:"A paragraph to drop into the results chapter"`
+	WordCount      int    `json:"word_count"`
+}
+
+// PaperResponse is a paper found on Semantic Scholar
+type PaperResponse struct {
+	PaperID          string   `json:"paper_id"`
+	Title            string   `json:"title"`
+	Authors          []string `json:"authors"`
+	Year             int      `json:"year,omitempty"`
+	Venue            string   `json:"venue,omitempty"`
+	Abstract         string   `json:"abstract,omitempty"`
+	DOI              string   `json:"doi,omitempty"`
+	URL              string   `json:"url,omitempty"`
+	CitationCount    int      `json:"citation_count"`
+	OpenAccessPDFURL string   `json:"open_access_pdf_url,omitempty"`
+	Relevance        *float64 `json:"relevance,omitempty" doc:"Cosine similarity to the project, when the search was ranked for one"`
+	InProject        bool     `json:"in_project,omitempty" doc:"Set in citation graph results for papers the project already has"`
+}
+
+type SearchPapersResponse struct {
+	Total  int             `json:"total" doc:"Matches in all, of which only the first 1000 can be paged through"`
+	Offset int             `json:"offset"`
+	Next   *int            `json:"next,omitempty" doc:"Offset of the next page; absent on the last one"`
+	Papers []PaperResponse `json:"papers"`
+}
+
+// CitationGraphResponse is one page of the papers a saved paper cites or is cited by
+type CitationGraphResponse struct {
+	PaperID string          `json:"paper_id" doc:"The saved paper's Semantic Scholar ID, or DOI:<doi> for references added by hand"`
+	Offset  int             `json:"offset"`
+	Next    *int            `json:"next,omitempty" doc:"Offset of the next page; absent on the last one"`
+	Papers  []PaperResponse `json:"papers"`
+}
+
+// ImportPapersResponse lists the references created from imported papers
+type ImportPapersResponse struct {
+	Imported []ReferenceResponse `json:"imported"`
+	Merged   []ReferenceResponse `json:"merged,omitempty" doc:"Existing references the papers duplicated, with missing fields filled in"`
+	Skipped  []string            `json:"skipped,omitempty" doc:"IDs already in the project or unknown to Semantic Scholar"`
+}
+
+// ImportThesisResponse is the project created from an imported thesis
+type ImportThesisResponse struct {
+	Project    ProjectResponse     `json:"project"`
+	Chapters   []ChapterResponse   `json:"chapters"`
+	References []ReferenceResponse `json:"references"`
+	Skipped    []string            `json:"skipped,omitempty" doc:"Headings of sections that fit no chapter type, such as appendices, left out of the project"`
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
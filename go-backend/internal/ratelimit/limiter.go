@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/util"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Result describes the outcome of a single Allow call
+type Result struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity (burst)
+	Remaining  int           // Whole tokens left after this request
+	ResetAfter time.Duration // Time until the bucket is full again
+	RetryAfter time.Duration // Time until the next token is available when not allowed
+}
+
+// Limiter is a token-bucket rate limiter keyed by an arbitrary string (e.g. client IP)
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+	// SetLimits changes the refill rate and bucket size for subsequent requests
+	SetLimits(rate float64, burst int)
+}
+
+// New builds the limiter selected by RATE_LIMIT_BACKEND
+func New(config util.Config) (Limiter, error) {
+	switch config.RateLimitBackend {
+	case "", "memory":
+		return NewMemoryLimiter(config.RateLimitRPS, config.RateLimitBurst), nil
+	case "redis":
+		opts, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		return NewRedisLimiter(redis.NewClient(opts), config.RateLimitRPS, config.RateLimitBurst), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", config.RateLimitBackend)
+	}
+}
+
+// durationForTokens is how long it takes to accumulate the given number of tokens
+func durationForTokens(tokens, rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / rate * float64(time.Second))
+}
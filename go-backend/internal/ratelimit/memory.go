@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long an untouched bucket is kept before being evicted
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// MemoryLimiter keeps buckets in process memory. Limits are per instance,
+// so use the Redis limiter when running more than one replica.
+type MemoryLimiter struct {
+	rate  float64 // Tokens added per second
+	burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter(rate float64, burst int) *MemoryLimiter {
+	return &MemoryLimiter{
+		rate:      rate,
+		burst:     burst,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string) (Result, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(l.burst), b.tokens+elapsed*l.rate)
+	b.lastSeen = now
+
+	res := Result{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = durationForTokens(1-b.tokens, l.rate)
+	}
+	res.Remaining = int(math.Floor(b.tokens))
+	res.ResetAfter = durationForTokens(float64(l.burst)-b.tokens, l.rate)
+	return res, nil
+}
+
+func (l *MemoryLimiter) SetLimits(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.burst = burst
+}
+
+// sweep drops idle buckets; called with mu held
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+// tokenBucketScript atomically refills and takes from a bucket stored as a hash.
+// KEYS[1] = bucket key; ARGV = rate (tokens/s), burst, now (ms), ttl (ms)
+// Returns {allowed, tokens_left (string, float)}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+tokens = math.min(burst, tokens + (math.max(0, now - ts) / 1000) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter shares buckets across all API instances
+type RedisLimiter struct {
+	client *redis.Client
+
+	mu    sync.RWMutex
+	rate  float64
+	burst int
+}
+
+func NewRedisLimiter(client *redis.Client, rate float64, burst int) *RedisLimiter {
+	return &RedisLimiter{client: client, rate: rate, burst: burst}
+}
+
+func (l *RedisLimiter) SetLimits(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.burst = burst
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	l.mu.RLock()
+	rate, burst := l.rate, l.burst
+	l.mu.RUnlock()
+
+	// Keep the key around long enough to refill completely, then let it expire
+	ttl := durationForTokens(float64(burst), rate) + time.Second
+
+	raw, err := tokenBucketScript.Run(ctx, l.client, []string{redisKeyPrefix + key},
+		rate, burst, time.Now().UnixMilli(), ttl.Milliseconds()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := raw[0].(int64)
+	tokensStr, _ := raw[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := Result{
+		Allowed:    allowed == 1,
+		Limit:      burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: durationForTokens(float64(burst)-tokens, rate),
+	}
+	if !res.Allowed {
+		res.RetryAfter = durationForTokens(1-tokens, rate)
+	}
+	return res, nil
+}
@@ -0,0 +1,146 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// awsBackend calls Secrets Manager's GetSecretValue, signing requests with
+// Signature Version 4 from the standard AWS_* credential variables
+type awsBackend struct {
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+}
+
+func newAWSFromEnv(client *http.Client) *awsBackend {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return &awsBackend{
+		region:       region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client:       client,
+	}
+}
+
+func (a *awsBackend) Fetch(ctx context.Context, secretID string) (map[string]string, error) {
+	if a.region == "" || a.accessKey == "" || a.secretKey == "" {
+		return nil, errors.New("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	host := "secretsmanager." + a.region + ".amazonaws.com"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, host, body, time.Now().UTC())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Secrets Manager: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Secrets Manager response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Type != "" {
+			return nil, fmt.Errorf("Secrets Manager error: %s: %s", apiErr.Type, apiErr.Message)
+		}
+		return nil, fmt.Errorf("Secrets Manager returned status %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(respBody, &secret); err != nil {
+		return nil, fmt.Errorf("failed to decode Secrets Manager response: %w", err)
+	}
+	if secret.SecretString == "" && secret.SecretBinary != "" {
+		raw, err := base64.StdEncoding.DecodeString(secret.SecretBinary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode binary secret: %w", err)
+		}
+		return map[string]string{"": string(raw)}, nil
+	}
+	return map[string]string{"": secret.SecretString}, nil
+}
+
+// sign adds SigV4 headers for a single-shot POST to the service root
+func (a *awsBackend) sign(req *http.Request, host string, body []byte, now time.Time) {
+	const service = "secretsmanager"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.sessionToken)
+	}
+
+	// Headers must be listed in sorted order
+	names := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if a.sessionToken != "" {
+		names = []string{"content-type", "host", "x-amz-date", "x-amz-security-token", "x-amz-target"}
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + req.Header.Get(name) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	payloadHash := sha256Hex(body)
+	canonicalRequest := "POST\n/\n\n" + canonicalHeaders.String() + "\n" + signedHeaders + "\n" + payloadHash
+
+	scope := date + "/" + a.region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+a.secretKey), date)
+	key = hmacSHA256(key, a.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.accessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com/v1/"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// gcpBackend reads Secret Manager versions. It authenticates with
+// GCP_ACCESS_TOKEN when set, otherwise with the metadata server's service account
+// (GCE, GKE with workload identity, Cloud Run).
+type gcpBackend struct {
+	accessToken string
+	client      *http.Client
+}
+
+func newGCPFromEnv(client *http.Client) *gcpBackend {
+	return &gcpBackend{accessToken: os.Getenv("GCP_ACCESS_TOKEN"), client: client}
+}
+
+// Fetch accepts projects/<p>/secrets/<s> (latest version) or projects/<p>/secrets/<s>/versions/<v>
+func (g *gcpBackend) Fetch(ctx context.Context, name string) (map[string]string, error) {
+	name = strings.Trim(name, "/")
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return nil, fmt.Errorf("secret name must look like projects/<project>/secrets/<secret>[/versions/<version>]")
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := g.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpSecretManagerURL+name+":access", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Secret Manager: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Secret Manager response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Secret Manager returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode Secret Manager response: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(payload.Payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret payload: %w", err)
+	}
+	return map[string]string{"": string(raw)}, nil
+}
+
+func (g *gcpBackend) token(ctx context.Context) (string, error) {
+	if g.accessToken != "" {
+		return g.accessToken, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("no GCP_ACCESS_TOKEN and metadata server unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned status %d", resp.StatusCode)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("failed to decode metadata token: %w", err)
+	}
+	return tok.AccessToken, nil
+}
@@ -0,0 +1,117 @@
+// Package secrets resolves config values that point at an external secret store
+// instead of holding the secret itself. A reference looks like
+//
+//	vault:secret/data/research#OPENAI_API_KEY
+//	awssm:prod/research#TOKEN_SECRET_KEY
+//	gcpsm:projects/my-project/secrets/openai-key
+//
+// The part after # selects a field: for Vault it is required, for AWS and GCP it
+// is optional and picks a key out of a JSON secret (otherwise the whole value is used).
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend fetches the raw secret stored at path
+type Backend interface {
+	// Fetch returns the secret's fields. Backends storing a single opaque value
+	// return it under the empty key.
+	Fetch(ctx context.Context, path string) (map[string]string, error)
+}
+
+// Resolver resolves references against the configured backends, fetching each
+// secret path at most once
+type Resolver struct {
+	backends map[string]Backend
+
+	mu    sync.Mutex
+	cache map[string]map[string]string
+}
+
+// NewResolver returns a Resolver for the given scheme -> backend mapping
+func NewResolver(backends map[string]Backend) *Resolver {
+	return &Resolver{backends: backends, cache: make(map[string]map[string]string)}
+}
+
+// NewResolverFromEnv wires the Vault, AWS Secrets Manager and GCP Secret Manager
+// backends using their standard environment variables. Backends are only
+// contacted when a reference for them is resolved.
+func NewResolverFromEnv() *Resolver {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return NewResolver(map[string]Backend{
+		"vault": newVaultFromEnv(client),
+		"awssm": newAWSFromEnv(client),
+		"gcpsm": newGCPFromEnv(client),
+	})
+}
+
+// IsReference reports whether value uses one of the known secret schemes
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, known := r.backends[scheme]
+	return known
+}
+
+// Resolve returns the secret value a reference points at
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, _ := strings.Cut(ref, ":")
+	backend, ok := r.backends[scheme]
+	if !ok {
+		return "", fmt.Errorf("unknown secret scheme %q", scheme)
+	}
+	path, field, _ := strings.Cut(rest, "#")
+	if path == "" {
+		return "", fmt.Errorf("secret reference %q has no path", ref)
+	}
+
+	fields, err := r.fetch(ctx, scheme, path, backend)
+	if err != nil {
+		return "", fmt.Errorf("%s:%s: %w", scheme, path, err)
+	}
+
+	if field == "" {
+		value, ok := fields[""]
+		if !ok {
+			return "", fmt.Errorf("%s:%s holds several fields, select one with #field", scheme, path)
+		}
+		return value, nil
+	}
+	if value, ok := fields[field]; ok {
+		return value, nil
+	}
+	// Opaque secrets may still be JSON objects, e.g. an AWS secret created from key/value pairs
+	if raw, ok := fields[""]; ok {
+		var obj map[string]any
+		if json.Unmarshal([]byte(raw), &obj) == nil {
+			if value, ok := obj[field]; ok {
+				return fmt.Sprint(value), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s:%s has no field %q", scheme, path, field)
+}
+
+func (r *Resolver) fetch(ctx context.Context, scheme, path string, backend Backend) (map[string]string, error) {
+	key := scheme + ":" + path
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fields, ok := r.cache[key]; ok {
+		return fields, nil
+	}
+	fields, err := backend.Fetch(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	r.cache[key] = fields
+	return fields, nil
+}
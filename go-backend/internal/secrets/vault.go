@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultBackend reads KV secrets over Vault's HTTP API. Both KV v1 and v2 mounts
+// work; for v2 the path includes "data/", e.g. secret/data/research.
+type vaultBackend struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultFromEnv(client *http.Client) *vaultBackend {
+	return &vaultBackend{
+		addr:      strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		client:    client,
+	}
+}
+
+func (v *vaultBackend) Fetch(ctx context.Context, path string) (map[string]string, error) {
+	if v.addr == "" || v.token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Vault: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Vault returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+	data := payload.Data
+	// KV v2 nests the fields under data.data, next to data.metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nested
+		}
+	}
+
+	fields := make(map[string]string, len(data))
+	for k, val := range data {
+		fields[k] = fmt.Sprint(val)
+	}
+	return fields, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AI-likeness verdicts
+const (
+	AIVerdictHuman = "likely_human"
+	AIVerdictMixed = "mixed"
+	AIVerdictAI    = "likely_ai"
+)
+
+// aiDetectorName identifies the scoring model in responses, so scores from a future
+// third-party detector are not mistaken for these
+const aiDetectorName = "heuristic-v1"
+
+// Scoring thresholds
+const (
+	minDetectionWords  = 40   // Shorter paragraphs carry too little signal to score
+	aiPassageThreshold = 0.5  // Paragraphs scoring this or more are highlighted
+	aiMixedThreshold   = 0.35 // Overall scores from here are mixed
+	aiLikelyThreshold  = 0.6  // and from here likely AI
+)
+
+// aiStockPhrases are words and phrases language models overuse in academic prose
+var aiStockPhrases = []string{
+	"delve", "delves", "delving", "tapestry", "intricate", "multifaceted", "pivotal", "underscore",
+	"underscores", "showcasing", "navigating the", "in the realm of", "ever-evolving", "ever-changing",
+	"it is important to note", "it is worth noting", "plays a crucial role", "plays a vital role",
+	"a testament to", "in today's", "shed light on", "sheds light on", "paving the way", "holistic",
+	"seamlessly", "nuanced", "robust framework", "valuable insights", "comprehensive understanding",
+	"in conclusion", "furthermore", "moreover", "additionally", "notably", "landscape of",
+}
+
+// aiTransitionOpeners are sentence openers that models lean on to link every sentence
+var aiTransitionOpeners = []string{
+	"moreover", "furthermore", "additionally", "in addition", "however", "overall", "consequently",
+	"thus", "therefore", "notably", "importantly", "ultimately", "in summary", "in conclusion",
+}
+
+var sentenceEndPattern = regexp.MustCompile(`[.!?]+["')\]]*\s+`)
+
+// DetectAIContent estimates how likely a chapter, or unsaved text of it, is to be flagged
+// as AI-written. The heuristic scores each paragraph on the traits detectors key on:
+// uniform sentence lengths, stock phrases and transition-word openers. It is a guide to
+// what to revise, not a verdict. Requires read access.
+func (s *ResearchService) DetectAIContent(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.AIDetectionRequest) (apimodels.AIDetectionResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.AIDetectionResponse{}, err
+	}
+	content := chapter.Content.String
+	if req.Content != nil {
+		content = *req.Content
+	}
+	resp := scoreAIContent(content)
+	resp.ChapterID = chapterID
+	s.logger.Info("Scored chapter for AI content", "chapterID", chapterID, "score", resp.Score, "passages", len(resp.Passages))
+	return resp, nil
+}
+
+func scoreAIContent(content string) apimodels.AIDetectionResponse {
+	resp := apimodels.AIDetectionResponse{Detector: aiDetectorName, Verdict: AIVerdictHuman, Passages: []apimodels.AIDetectionPassage{}}
+	var weighted float64
+	offset := 0 // In runes, for highlighting in the editor
+	for _, paragraph := range strings.SplitAfter(content, "\n\n") {
+		start := offset
+		offset += utf8.RuneCountInString(paragraph)
+		text := strings.TrimSpace(paragraph)
+		// Headings, tables and figure markers are not prose
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "|") || strings.HasPrefix(text, "[[") {
+			continue
+		}
+		words := len(strings.Fields(text))
+		if words < minDetectionWords {
+			continue
+		}
+		score, reasons := scoreAIParagraph(text, words)
+		resp.ScoredWords += words
+		weighted += score * float64(words)
+		if score >= aiPassageThreshold {
+			lead := utf8.RuneCountInString(paragraph[:strings.Index(paragraph, text)])
+			resp.Passages = append(resp.Passages, apimodels.AIDetectionPassage{
+				Text:    text,
+				Start:   start + lead,
+				End:     start + lead + utf8.RuneCountInString(text),
+				Score:   round2(score),
+				Reasons: reasons,
+			})
+		}
+	}
+	if resp.ScoredWords > 0 {
+		resp.Score = round2(weighted / float64(resp.ScoredWords))
+	}
+	switch {
+	case resp.Score >= aiLikelyThreshold:
+		resp.Verdict = AIVerdictAI
+	case resp.Score >= aiMixedThreshold:
+		resp.Verdict = AIVerdictMixed
+	}
+	return resp
+}
+
+// scoreAIParagraph rates one paragraph from 0 (human-like) to 1 (AI-like) and says why
+func scoreAIParagraph(text string, words int) (float64, []string) {
+	var reasons []string
+	sentences := sentenceEndPattern.Split(text, -1)
+	var lengths []float64
+	openers := 0
+	for _, sentence := range sentences {
+		fields := strings.Fields(sentence)
+		if len(fields) == 0 {
+			continue
+		}
+		lengths = append(lengths, float64(len(fields)))
+		lower := strings.ToLower(sentence)
+		for _, opener := range aiTransitionOpeners {
+			if strings.HasPrefix(lower, opener+",") || strings.HasPrefix(lower, opener+" ") {
+				openers++
+				break
+			}
+		}
+	}
+
+	// Burstiness: people mix short and long sentences, models keep them even
+	var uniformity float64
+	if len(lengths) >= 3 {
+		var mean, variance float64
+		for _, l := range lengths {
+			mean += l
+		}
+		mean /= float64(len(lengths))
+		for _, l := range lengths {
+			variance += (l - mean) * (l - mean)
+		}
+		cv := math.Sqrt(variance/float64(len(lengths))) / mean
+		uniformity = clamp01((0.55 - cv) / 0.35)
+		if uniformity >= 0.5 {
+			reasons = append(reasons, "sentences are of very even length")
+		}
+	}
+
+	lower := strings.ToLower(text)
+	var found []string
+	hits := 0
+	for _, phrase := range aiStockPhrases {
+		if n := countPhrase(lower, phrase); n > 0 {
+			hits += n
+			found = append(found, phrase)
+		}
+	}
+	phrases := clamp01(float64(hits) * 100 / float64(words) / 1.5)
+	if len(found) > 0 {
+		slices.Sort(found)
+		reasons = append(reasons, "stock phrases: "+strings.Join(found, ", "))
+	}
+
+	var transitions float64
+	if len(lengths) > 0 {
+		transitions = clamp01(float64(openers) / float64(len(lengths)) / 0.4)
+		if transitions >= 0.5 {
+			reasons = append(reasons, "many sentences open with transition words")
+		}
+	}
+	return 0.45*uniformity + 0.35*phrases + 0.2*transitions, reasons
+}
+
+// countPhrase counts whole-word occurrences of phrase in text
+func countPhrase(text, phrase string) int {
+	n := 0
+	for i := 0; ; {
+		j := strings.Index(text[i:], phrase)
+		if j < 0 {
+			return n
+		}
+		start, end := i+j, i+j+len(phrase)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(before) && !isWordRune(after) {
+			n++
+		}
+		i = end
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (r == '-' || r == '\'' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
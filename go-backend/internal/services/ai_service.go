@@ -4,38 +4,106 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
 	applogger "github.com/shawgichan/research-service/go-backend/internal/logger" // aliased
 	"github.com/shawgichan/research-service/go-backend/internal/models"           // For placeholder references
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+	"github.com/shawgichan/research-service/go-backend/internal/util"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // const openAIAPIURL = "https://api.openai.com/v1/chat/completions"
 const openAIAPIURL = "https://api.groq.com/openai/v1/chat/completions"
+const openAIModelsURL = "https://api.groq.com/openai/v1/models"
+
+// apiKeyCheckTTL bounds how often readiness probes hit the provider
+const apiKeyCheckTTL = 5 * time.Minute
+
+// EmbeddingDimensions must match the reference_embeddings.embedding column
+const EmbeddingDimensions = 1536
+
+// ErrEmbeddingsDisabled is returned by EmbedText when no embedding API key is configured
+var ErrEmbeddingsDisabled = errors.New("embeddings are not configured")
+
+// EmbeddingConfig points EmbedText at an OpenAI-compatible /embeddings endpoint
+type EmbeddingConfig struct {
+	APIURL string
+	APIKey string
+	Model  string
+}
+
+// GenerationTimeouts bound AI work, and decide whether a generation stops when the
+// client that asked for it disconnects
+type GenerationTimeouts struct {
+	Call               time.Duration // One call to the AI provider
+	Generation         time.Duration // A whole generation, across its calls, until it is saved
+	CancelOnDisconnect bool          // Otherwise a generation runs on, detached from its request
+}
 
 type AIService struct {
-	apiKey string
-	client *http.Client
-	logger *applogger.AppLogger
+	apiKey     string
+	embeddings EmbeddingConfig
+	scholar    ScholarConfig
+	timeouts   GenerationTimeouts
+	cache      *responseCache     // Recent replies of the AI provider
+	tunables   *util.LiveTunables // Model name and temperatures, hot-reloadable
+	logger     *applogger.AppLogger
+
+	// One client per service, each behind its own circuit breaker
+	client        *http.Client // The AI provider
+	embedClient   *http.Client
+	scholarClient *http.Client
+	circuits      []*breaker.Breaker
+
+	keyCheckMu   sync.Mutex
+	keyCheckedAt time.Time
+	keyCheckErr  error
 }
 
-func NewAIService(apiKey string, logger *applogger.AppLogger) *AIService {
+func NewAIService(apiKey string, embeddings EmbeddingConfig, scholar ScholarConfig, circuits breaker.Config, timeouts GenerationTimeouts, cache ResponseCacheConfig, tunables *util.LiveTunables, logger *applogger.AppLogger) *AIService {
+	provider := breaker.New("ai_provider", circuits)
+	embedder := breaker.New("embeddings", circuits)
+	scholarCircuit := breaker.New("semantic_scholar", circuits)
 	return &AIService{
-		apiKey: apiKey,
-		client: &http.Client{Timeout: 60 * time.Second}, // Increased timeout for potentially long AI responses
-		logger: logger,
+		apiKey:        apiKey,
+		embeddings:    embeddings,
+		scholar:       scholar,
+		timeouts:      timeouts,
+		cache:         newResponseCache(cache),
+		tunables:      tunables,
+		logger:        logger,
+		client:        telemetry.NewHTTPClient(&http.Client{Transport: provider.Transport(nil)}), // Calls carry their own deadline, timeouts.Call
+		embedClient:   telemetry.NewHTTPClient(&http.Client{Timeout: 60 * time.Second, Transport: embedder.Transport(nil)}),
+		scholarClient: telemetry.NewHTTPClient(&http.Client{Timeout: 60 * time.Second, Transport: scholarCircuit.Transport(nil)}),
+		circuits:      []*breaker.Breaker{provider, embedder, scholarCircuit},
 	}
 }
 
+// Circuits returns the circuit breakers of the services AIService calls
+func (s *AIService) Circuits() []*breaker.Breaker {
+	return s.circuits
+}
+
 type OpenAIRequest struct {
 	Model       string          `json:"model"`
 	Messages    []OpenAIMessage `json:"messages"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
 	// Stream bool `json:"stream,omitempty"` // For streaming responses
+
+	prose bool // Long-form writing, which follows the user's generation preferences
 }
 
 type OpenAIMessage struct {
@@ -68,7 +136,64 @@ type OpenAIError struct {
 	Code    string `json:"code"`
 }
 
-func (s *AIService) callOpenAI(ctx context.Context, request OpenAIRequest) (*OpenAIResponse, error) {
+// callOpenAI sends a chat completion request, guarded against prompt injection: the
+// system message is told to treat quoted material as content, and a reply that gives
+// the system message away is rejected with ErrPromptLeak. The call fails with
+// context.DeadlineExceeded when it takes longer than timeouts.Call. The reply to an
+// identical request made shortly before is reused unless ctx asks otherwise, see
+// responseCache and withResponseCacheUse. Calls count against the AI words quota
+// attached to ctx, see QuotaService.withAIWords; reused replies are not charged again.
+func (s *AIService) callOpenAI(ctx context.Context, request OpenAIRequest) (resp *OpenAIResponse, err error) {
+	if err := checkAIWords(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeouts.Call)
+	defer cancel()
+	ctx, span := telemetry.Tracer().Start(ctx, "AIService.callOpenAI")
+	span.SetAttributes(
+		attribute.String("ai.model", request.Model),
+		attribute.Int("ai.max_tokens", request.MaxTokens),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(
+				attribute.Int("ai.usage.prompt_tokens", resp.Usage.PromptTokens),
+				attribute.Int("ai.usage.completion_tokens", resp.Usage.CompletionTokens),
+			)
+		}
+		span.End()
+	}()
+
+	applyGenerationPreferences(ctx, &request)
+	logged := request // Recorded without the guard, whose canary differs on every call
+	var reply *OpenAIResponse
+	var cached bool
+	start := time.Now()
+	defer func() {
+		logGeneration(ctx, generationCall{Request: logged, Response: reply, Latency: time.Since(start), Cached: cached, Err: err})
+	}()
+	// Keyed before the guard too, for the same reason
+	reply, cached, err = s.cache.do(ctx, responseCacheKey(request), func() (*OpenAIResponse, error) {
+		return s.sendChatCompletion(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Bool("ai.cached", cached))
+	if !cached {
+		recordAIWords(ctx, reply.Choices[0].Message.Content)
+		noteFreshReply(ctx)
+	}
+	return reply, nil
+}
+
+// sendChatCompletion makes the call for callOpenAI. A reply that was read is returned
+// also when it is rejected, for the generation history.
+func (s *AIService) sendChatCompletion(ctx context.Context, request OpenAIRequest) (*OpenAIResponse, error) {
+	canary := guardRequest(&request)
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		s.logger.Error("Failed to marshal OpenAI request", "error", err)
@@ -84,27 +209,33 @@ func (s *AIService) callOpenAI(ctx context.Context, request OpenAIRequest) (*Ope
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
 
-	resp, err := s.client.Do(req)
+	httpResp, err := s.client.Do(req)
 	if err != nil {
 		s.logger.Error("Failed to send request to OpenAI", "error", err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("OpenAI request timed out: %w", context.DeadlineExceeded)
+		}
 		return nil, fmt.Errorf("failed to send request to OpenAI: %w", err)
 	}
-	defer resp.Body.Close()
+	defer httpResp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		s.logger.Error("Failed to read OpenAI response body", "error", err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("OpenAI request timed out: %w", context.DeadlineExceeded)
+		}
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		s.logger.Error("OpenAI API error", "status_code", resp.StatusCode, "response_body", string(body))
+	if httpResp.StatusCode != http.StatusOK {
+		s.logger.Error("OpenAI API error", "status_code", httpResp.StatusCode, "response_body", string(body))
 		// Try to unmarshal error response
 		var errResp OpenAIResponse
 		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
 			return nil, fmt.Errorf("OpenAI API error: %s (type: %s, code: %s)", errResp.Error.Message, errResp.Error.Type, errResp.Error.Code)
 		}
-		return nil, fmt.Errorf("OpenAI API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("OpenAI API request failed with status %d: %s", httpResp.StatusCode, string(body))
 	}
 
 	var openAIResp OpenAIResponse
@@ -115,19 +246,277 @@ func (s *AIService) callOpenAI(ctx context.Context, request OpenAIRequest) (*Ope
 
 	if openAIResp.Error != nil {
 		s.logger.Error("OpenAI API returned an error in response", "error_message", openAIResp.Error.Message)
-		return nil, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
+		return &openAIResp, fmt.Errorf("OpenAI API error: %s", openAIResp.Error.Message)
 	}
 
 	if len(openAIResp.Choices) == 0 {
 		s.logger.Warn("OpenAI response contained no choices")
-		return nil, fmt.Errorf("no response choices from OpenAI")
+		return &openAIResp, fmt.Errorf("no response choices from OpenAI")
+	}
+	if promptLeaked(request, canary, openAIResp.Choices[0].Message.Content) {
+		s.logger.Warn("OpenAI reply repeated its system prompt, discarding it", "model", request.Model)
+		return &openAIResp, ErrPromptLeak
 	}
 
 	return &openAIResp, nil
 }
 
-func (s *AIService) GenerateLiteratureReview(ctx context.Context, title, specialization string) (string, []*models.ReferenceResponse, error) {
-	s.logger.Info("Generating Literature Review", "title", title, "specialization", specialization)
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *OpenAIError `json:"error,omitempty"`
+}
+
+// EmbeddingsEnabled reports whether EmbedText can be used
+func (s *AIService) EmbeddingsEnabled() bool {
+	return s.embeddings.APIKey != ""
+}
+
+// EmbeddingModel names the model stored alongside each embedding, so vectors
+// from different models are never compared
+func (s *AIService) EmbeddingModel() string {
+	return s.embeddings.Model
+}
+
+// EmbedText returns one embedding per input, in input order
+func (s *AIService) EmbedText(ctx context.Context, inputs ...string) (vectors [][]float32, err error) {
+	if !s.EmbeddingsEnabled() {
+		return nil, ErrEmbeddingsDisabled
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	ctx, span := telemetry.Tracer().Start(ctx, "AIService.EmbedText")
+	span.SetAttributes(
+		attribute.String("ai.model", s.embeddings.Model),
+		attribute.Int("ai.inputs", len(inputs)),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	jsonData, err := json.Marshal(embeddingRequest{Model: s.embeddings.Model, Input: inputs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.embeddings.APIURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.embeddings.APIKey))
+
+	httpResp, err := s.embedClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send embedding request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding response body: %w", err)
+	}
+
+	var embResp embeddingResponse
+	if httpResp.StatusCode != http.StatusOK {
+		s.logger.Error("Embedding API error", "status_code", httpResp.StatusCode, "response_body", string(body))
+		if json.Unmarshal(body, &embResp) == nil && embResp.Error != nil {
+			return nil, fmt.Errorf("embedding API error: %s (type: %s, code: %s)", embResp.Error.Message, embResp.Error.Type, embResp.Error.Code)
+		}
+		return nil, fmt.Errorf("embedding API request failed with status %d", httpResp.StatusCode)
+	}
+	if err := json.Unmarshal(body, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embedding response: %w", err)
+	}
+	if len(embResp.Data) != len(inputs) {
+		return nil, fmt.Errorf("embedding API returned %d embeddings for %d inputs", len(embResp.Data), len(inputs))
+	}
+
+	vectors = make([][]float32, len(inputs))
+	for _, d := range embResp.Data {
+		if d.Index < 0 || d.Index >= len(inputs) {
+			return nil, fmt.Errorf("embedding API returned out of range index %d", d.Index)
+		}
+		if len(d.Embedding) != EmbeddingDimensions {
+			return nil, fmt.Errorf("embedding model %q returned %d dimensions, expected %d", s.embeddings.Model, len(d.Embedding), EmbeddingDimensions)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// VectorLiteral formats an embedding in pgvector's text form, e.g. [0.1,0.2,0.3]
+func VectorLiteral(v []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// CheckAPIKey verifies the provider accepts the configured API key.
+// The result is cached for apiKeyCheckTTL so frequent readiness probes don't burn rate limit.
+func (s *AIService) CheckAPIKey(ctx context.Context) error {
+	s.keyCheckMu.Lock()
+	defer s.keyCheckMu.Unlock()
+
+	if !s.keyCheckedAt.IsZero() && time.Since(s.keyCheckedAt) < apiKeyCheckTTL {
+		return s.keyCheckErr
+	}
+
+	s.keyCheckErr = s.checkAPIKey(ctx)
+	s.keyCheckedAt = time.Now()
+	return s.keyCheckErr
+}
+
+func (s *AIService) checkAPIKey(ctx context.Context) error {
+	if s.apiKey == "" {
+		return fmt.Errorf("AI provider API key is not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openAIModelsURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create http request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach AI provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("AI provider rejected the API key (status %d)", resp.StatusCode)
+	default:
+		return fmt.Errorf("AI provider returned status %d", resp.StatusCode)
+	}
+}
+
+// SourceMaterial is text from a paper the student supplied, e.g. an uploaded PDF
+type SourceMaterial struct {
+	Name string // Shown to the model so it can tell sources apart
+	Text string
+}
+
+// Languages chapters can be generated in, by project language code
+var ProjectLanguages = map[string]string{
+	LanguageEnglish: "English",
+	LanguageArabic:  "Arabic",
+	"fr":            "French",
+	"es":            "Spanish",
+	"de":            "German",
+	"tr":            "Turkish",
+	"pt":            "Portuguese",
+}
+
+// ChapterOptions shape a generated chapter beyond its project's title and specialization
+type ChapterOptions struct {
+	Language     string // Project language code; English when empty
+	TargetWords  int    // Length to aim for; the prompt's own range when 0
+	Instructions string // The chapter's custom prompt, followed as well as the usual brief
+}
+
+// lengthTarget states the length a chapter should aim for: the chapter's word target
+// when it has one, otherwise the given default range
+func lengthTarget(targetWords, minWords, maxWords int) string {
+	if targetWords <= 0 {
+		return fmt.Sprintf("%d-%d words", minWords, maxWords)
+	}
+	return fmt.Sprintf("about %d words", targetWords)
+}
+
+// maxTokensFor raises a request's completion budget to fit a word target, at roughly
+// two tokens per word to leave room for headings and references
+func maxTokensFor(targetWords, defaultTokens int) int {
+	const maxCompletionTokens = 16000
+	return min(max(defaultTokens, targetWords*2), maxCompletionTokens)
+}
+
+// languageInstruction tells the model to write in the project's language. Citations are
+// kept as published so they still match their sources and the reference list.
+func languageInstruction(language string) string {
+	name, ok := ProjectLanguages[language]
+	if !ok || language == LanguageEnglish {
+		return ""
+	}
+	return fmt.Sprintf(`
+Write the whole chapter, including headings, in %s. Keep every citation in the language of the source it cites: do not translate author names, titles, journal names or the entries of the References section, and keep the in-text citation format (e.g., (Author, Year)) unchanged.
+`, name)
+}
+
+// chapterInstructions passes on the student's own brief for a chapter
+func chapterInstructions(instructions string) string {
+	if strings.TrimSpace(instructions) == "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+The student gave these instructions for the chapter; follow them where they don't conflict with the above:
+"""
+%s
+"""
+`, promptText(instructions, maxPromptInstructions))
+}
+
+// AnnotatedReference is a reference the student has taken notes on or highlighted
+type AnnotatedReference struct {
+	Citation   string // Authors, title and year, enough to cite it by
+	Abstract   string // Only given for the references of a LiteratureScope
+	Notes      string
+	Highlights []string // Passages quoted from the paper, with any comment on them
+}
+
+// writeAnnotatedReference lists a reference with what the student noted on it
+func writeAnnotatedReference(b *strings.Builder, a AnnotatedReference) {
+	fmt.Fprintf(b, "\n- %s\n", promptLine(a.Citation))
+	if a.Abstract != "" {
+		fmt.Fprintf(b, "  Abstract: %s\n", singleLine(promptText(a.Abstract, maxPromptExcerpt)))
+	}
+	if a.Notes != "" {
+		fmt.Fprintf(b, "  Notes: %s\n", singleLine(promptText(a.Notes, maxPromptExcerpt)))
+	}
+	for _, h := range a.Highlights {
+		fmt.Fprintf(b, "  Highlight: %q\n", promptText(h, maxPromptExcerpt))
+	}
+}
+
+// LiteratureScope limits a literature review to the references the student tagged
+type LiteratureScope struct {
+	Tag        string
+	References []AnnotatedReference // The only works the review may cite
+}
+
+// GenerateLiteratureReview drafts a literature review; sources, when given, are papers
+// the student already has, which the review should draw on alongside other literature.
+// Annotated references carry the student's reading of papers in their library. A scope
+// limits the review to the references given in it.
+func (s *AIService) GenerateLiteratureReview(ctx context.Context, title, specialization string, opts ChapterOptions, sources []SourceMaterial, annotated []AnnotatedReference, scope *LiteratureScope) (string, []*models.ReferenceResponse, error) {
+	s.logger.Info("Generating Literature Review", "title", title, "specialization", specialization, "language", opts.Language, "targetWords", opts.TargetWords, "sources", len(sources), "annotated", len(annotated), "scoped", scope != nil)
+	title, specialization = promptLine(title), promptLine(specialization)
+	referenceRequirement := "Include at least 10-15 recent academic references (published between 2019 and the current year)."
+	if scope != nil {
+		referenceRequirement = "Cite only the references listed below, and every one of them; do not introduce other works."
+	}
 	prompt := fmt.Sprintf(`
 You are an academic research assistant. Generate a comprehensive literature review for a research thesis with the following details:
 
@@ -135,8 +524,8 @@ Title: "%s"
 Specialization: %s
 
 Please provide:
-1. A well-structured literature review (target 1500-2000 words).
-2. Include at least 10-15 recent academic references (published between 2019 and the current year).
+1. A well-structured literature review (target %s).
+2. %s
 3. Organize the content with appropriate subheadings.
 4. Follow academic writing standards.
 5. Include in-text citations in APA format (e.g., (Author, Year)).
@@ -155,18 +544,46 @@ Ensure the "References" section is clearly demarcated at the end, like:
 Author, A. A. (Year). Title of work. Publisher.
 Another, B. B. (Year). Title of article. Journal Title, volume(issue), pages.
 ---REFERENCES_END---
-`, title, specialization)
+`, title, specialization, lengthTarget(opts.TargetWords, 1500, 2000), referenceRequirement)
+	if scope != nil {
+		var b strings.Builder
+		tag := promptLine(scope.Tag)
+		fmt.Fprintf(&b, "\nThe student has tagged the following papers as %s. Review these works only, organizing the synthesis around what they contribute as %s literature, and use the abstracts, notes and highlights as evidence:\n", tag, tag)
+		for _, a := range scope.References {
+			writeAnnotatedReference(&b, a)
+		}
+		prompt += b.String()
+	}
+	if len(sources) > 0 {
+		var b strings.Builder
+		b.WriteString("\nThe student has provided the following papers. Discuss and cite each of them in the review, using only what the excerpts support, and include them in the References section:\n")
+		for i, src := range sources {
+			fmt.Fprintf(&b, "\n---SOURCE %d: %s---\n%s\n", i+1, promptLine(src.Name), promptText(src.Text, maxPromptSource))
+		}
+		prompt += b.String()
+	}
+	if len(annotated) > 0 {
+		var b strings.Builder
+		b.WriteString("\nThe student has noted why the following papers matter to the thesis. Cite each of them and build the synthesis around these notes: group the papers as the notes suggest, bring out agreements and tensions between them, and use the highlighted passages as evidence:\n")
+		for _, a := range annotated {
+			a.Abstract = "" // Only given for a scope
+			writeAnnotatedReference(&b, a)
+		}
+		prompt += b.String()
+	}
+	prompt += languageInstruction(opts.Language)
+	prompt += chapterInstructions(opts.Instructions)
 
+	tunables := s.tunables.Load()
 	request := OpenAIRequest{
-		// Model: "gpt-4-turbo-preview", // Or "gpt-3.5-turbo" for faster/cheaper, "gpt-4" for higher quality
-		Model: "meta-llama/llama-4-scout-17b-16e-instruct",
-
+		Model: tunables.AIModel,
 		Messages: []OpenAIMessage{
 			{Role: "system", Content: "You are an expert academic research assistant specializing in writing literature reviews."},
 			{Role: "user", Content: prompt},
 		},
-		MaxTokens:   3500, // Adjust as needed
-		Temperature: 0.6,  // Balance creativity and factualness
+		MaxTokens:   maxTokensFor(opts.TargetWords, 3500),
+		Temperature: tunables.AITemperatureLiteratureReview, // Balance creativity and factualness
+		prose:       true,
 	}
 
 	openAIResp, err := s.callOpenAI(ctx, request)
@@ -179,19 +596,23 @@ Another, B. B. (Year). Title of article. Journal Title, volume(issue), pages.
 	// TODO: Implement more robust reference extraction and parsing.
 	// For now, we use a placeholder.
 	extractedReferences := s.extractPlaceholderReferences(content, specialization)
+	events.Emit(ctx, events.Event{Type: events.GenerationProgress, Message: fmt.Sprintf("Literature review drafted, %d references identified", len(extractedReferences))})
 
 	s.logger.Info("Literature Review generated successfully", "title", title)
 	return content, extractedReferences, nil
 }
 
-func (s *AIService) GenerateIntroduction(ctx context.Context, title, specialization, literatureReviewSummary string) (string, error) {
-	s.logger.Info("Generating Introduction", "title", title, "specialization", specialization)
+func (s *AIService) GenerateIntroduction(ctx context.Context, title, specialization string, opts ChapterOptions, literatureReviewSummary string) (string, error) {
+	s.logger.Info("Generating Introduction", "title", title, "specialization", specialization, "language", opts.Language, "targetWords", opts.TargetWords)
 	prompt := fmt.Sprintf(`
-You are an academic research assistant. Generate a compelling introduction chapter (target 800-1200 words) for a research thesis.
+You are an academic research assistant. Generate a compelling introduction chapter (target %s) for a research thesis.
 
 Thesis Title: "%s"
 Specialization: %s
-Summary of Literature Review: %s
+Summary of Literature Review:
+"""
+%s
+"""
 
 The introduction should include:
 1. Background of the study: Briefly introduce the broader context.
@@ -202,17 +623,20 @@ The introduction should include:
 6. Structure of the thesis: Briefly outline the subsequent chapters.
 
 Ensure academic tone and clarity.
-`, title, specialization, literatureReviewSummary)
+`, lengthTarget(opts.TargetWords, 800, 1200), promptLine(title), promptLine(specialization), promptText(literatureReviewSummary, maxPromptExcerpt))
+	prompt += languageInstruction(opts.Language)
+	prompt += chapterInstructions(opts.Instructions)
 
+	tunables := s.tunables.Load()
 	request := OpenAIRequest{
-		// Model: "gpt-4-turbo-preview",
-		Model: "meta-llama/llama-4-scout-17b-16e-instruct",
+		Model: tunables.AIModel,
 		Messages: []OpenAIMessage{
 			{Role: "system", Content: "You are an expert academic writer specializing in crafting thesis introductions."},
 			{Role: "user", Content: prompt},
 		},
-		MaxTokens:   2000,
-		Temperature: 0.7,
+		MaxTokens:   maxTokensFor(opts.TargetWords, 2000),
+		Temperature: tunables.AITemperatureIntroduction,
+		prose:       true,
 	}
 
 	openAIResp, err := s.callOpenAI(ctx, request)
@@ -224,37 +648,80 @@ Ensure academic tone and clarity.
 	return openAIResp.Choices[0].Message.Content, nil
 }
 
-func (s *AIService) GenerateMethodologyTemplate(ctx context.Context, title, specialization, researchType string) (string, error) {
-	s.logger.Info("Generating Methodology Template", "title", title, "researchType", researchType)
-	prompt := fmt.Sprintf(`
+// GenerateMethodologyTemplate drafts the methodology chapter from the project's
+// questionnaire answers. Without answers, or for the questions left open, it writes
+// bracketed placeholders for the student to fill in.
+func (s *AIService) GenerateMethodologyTemplate(ctx context.Context, title, specialization string, opts ChapterOptions, answers *models.MethodologyAnswers) (string, error) {
+	approach := "not specified"
+	if answers != nil {
+		approach = answers.Approach
+	}
+	s.logger.Info("Generating Methodology Template", "title", title, "approach", approach, "language", opts.Language)
+	title, specialization = promptLine(title), promptLine(specialization)
+	var prompt string
+	if answers == nil {
+		prompt = fmt.Sprintf(`
 You are an academic research assistant. Generate a template for the methodology chapter (Chapter 3) of a research thesis.
 
 Thesis Title: "%s"
 Specialization: %s
-Research Type/Approach: %s (e.g., Quantitative, Qualitative, Mixed-Methods, Systematic Review, Experimental Design)
+Research Type/Approach: not yet chosen; suggest the approaches that suit the title (e.g., Quantitative, Qualitative, Mixed-Methods, Systematic Review, Experimental Design)
 
 The methodology template should include sections like:
-1. Research Design: (Provide a placeholder based on Research Type)
+1. Research Design: (Provide a placeholder)
 2. Population and Sampling (if applicable): (Provide a placeholder)
-3. Data Collection Methods/Instruments: (Provide a placeholder, suggest common methods for the research type)
+3. Data Collection Methods/Instruments: (Provide a placeholder, suggest common methods)
 4. Data Analysis Procedures: (Provide a placeholder, suggest common analysis techniques)
 5. Ethical Considerations: (Provide a placeholder with common points)
 6. Validity and Reliability (or Trustworthiness for qualitative): (Provide a placeholder)
 
 Provide bracketed placeholders like [Describe specific research design here] or [Specify data analysis software, if any] for the user to fill in.
 The template should be a starting point, guiding the student.
-Target length: 500-800 words of guidance and placeholders.
-`, title, specialization, researchType)
+Target length: %s of guidance and placeholders.
+`, title, specialization, lengthTarget(opts.TargetWords, 500, 800))
+	} else {
+		prompt = fmt.Sprintf(`
+You are an academic research assistant. Write the methodology chapter (Chapter 3) of a research thesis from the student's answers below.
+
+Thesis Title: "%s"
+Specialization: %s
+Research approach: %s
+Research design: %s
+Population: %s
+Sampling strategy and size: %s
+Data collection instruments: %s
+Data analysis: %s
+Software: %s
+Ethical considerations: %s
+
+Write these sections, in academic prose and in the future tense of a research plan:
+1. Research Design: state and justify the design, with the philosophical stance that fits the approach.
+2. Population and Sampling: describe the population, the sampling strategy and how the sample size was reached.
+3. Data Collection: describe each instrument, how it will be administered and, where relevant, how it was developed or adapted.
+4. Data Analysis: describe the analysis step by step, naming the specific tests or coding procedures and the software.
+5. Validity and Reliability (or Trustworthiness for qualitative research): the measures suited to this design.
+6. Ethical Considerations: approval, informed consent, confidentiality and data protection.
+7. Limitations of the methodology.
 
+Base every section on the answers. Where an answer is "not specified", do not invent specifics: write a bracketed placeholder such as [Specify the sample size] with a short note on what the student should decide.
+Target length: %s.
+`, title, specialization, methodologyAnswer(answers.Approach), methodologyAnswer(answers.Design), methodologyAnswer(answers.Population),
+			methodologyAnswer(answers.Sampling), methodologyAnswer(answers.Instruments), methodologyAnswer(answers.Analysis),
+			methodologyAnswer(answers.Software), methodologyAnswer(answers.Ethics), lengthTarget(opts.TargetWords, 1500, 2500))
+	}
+	prompt += languageInstruction(opts.Language)
+	prompt += chapterInstructions(opts.Instructions)
+
+	tunables := s.tunables.Load()
 	request := OpenAIRequest{
-		// Model: "gpt-3.5-turbo", // Can use a less powerful model for templates
-		Model: "meta-llama/llama-4-scout-17b-16e-instruct",
+		Model: tunables.AIModel,
 		Messages: []OpenAIMessage{
-			{Role: "system", Content: "You are an expert in research methodologies, providing structured templates."},
+			{Role: "system", Content: "You are an expert in research methodologies, writing methodology chapters and templates."},
 			{Role: "user", Content: prompt},
 		},
-		MaxTokens:   1500,
-		Temperature: 0.5,
+		MaxTokens:   maxTokensFor(opts.TargetWords, methodologyMaxTokens(answers)),
+		Temperature: tunables.AITemperatureMethodology,
+		prose:       true,
 	}
 
 	openAIResp, err := s.callOpenAI(ctx, request)
@@ -266,6 +733,907 @@ Target length: 500-800 words of guidance and placeholders.
 	return openAIResp.Choices[0].Message.Content, nil
 }
 
+// methodologyAnswer is a questionnaire answer as the methodology prompt states it
+func methodologyAnswer(answer string) string {
+	if answer = promptLine(answer); answer == "" {
+		return "not specified"
+	}
+	return strings.ReplaceAll(answer, "_", " ")
+}
+
+// methodologyMaxTokens is the completion budget of a methodology chapter: a written
+// chapter from questionnaire answers needs more than a template of placeholders
+func methodologyMaxTokens(answers *models.MethodologyAnswers) int {
+	if answers == nil {
+		return 1500
+	}
+	return 3500
+}
+
+// ResultsTable is a table of descriptive statistics the results chapter discusses
+type ResultsTable struct {
+	Placeholder string // Stands for the table on a line of its own, e.g. [[TABLE 1]]
+	Caption     string
+	Rows        [][]string // Header row first
+}
+
+// GenerateResultsNarrative writes the results chapter around tables of descriptive
+// statistics computed from the project's data. The tables are placed through their
+// placeholders; the narrative reports and interprets only the values they hold.
+func (s *AIService) GenerateResultsNarrative(ctx context.Context, title, specialization string, opts ChapterOptions, answers *models.MethodologyAnswers, tables []ResultsTable) (string, error) {
+	s.logger.Info("Generating Results Narrative", "title", title, "tables", len(tables), "language", opts.Language)
+	title, specialization = promptLine(title), promptLine(specialization)
+	var data strings.Builder
+	for _, t := range tables {
+		fmt.Fprintf(&data, "%s %s\n", t.Placeholder, promptLine(t.Caption)) // Captions and cells name the student's variables
+		for _, row := range t.Rows {
+			fmt.Fprintf(&data, "| %s |\n", strings.Join(promptLines(row), " | "))
+		}
+		data.WriteString("\n")
+	}
+	analysis := "not specified"
+	if answers != nil {
+		analysis = methodologyAnswer(answers.Analysis)
+	}
+	prompt := fmt.Sprintf(`
+You are an academic research assistant. Write the results chapter (Chapter 4) of a research thesis from the descriptive statistics below.
+
+Thesis Title: "%s"
+Specialization: %s
+Planned data analysis: %s
+
+Tables:
+%s
+Write these sections:
+1. An opening paragraph on the data analysed: the datasets, the number of cases and any missing data.
+2. Descriptive results: for each table, introduce it, put its placeholder (e.g., [[TABLE 1]]) on a line of its own where the table should appear, then report its key figures (central tendency, spread, the largest and smallest categories) in the conventions of academic reporting, e.g., (M = 3.45, SD = 0.82).
+3. A short summary of the main patterns.
+
+Report only the values in the tables; do not invent statistics, tests or significance levels that are not given. Where the planned analysis calls for inferential tests, add a bracketed placeholder such as [Insert the results of the planned t-test here] for the student to complete. Do not write the tables out yourself and do not number them; refer to each as "the table below" or by its content.
+Target length: %s.
+`, title, specialization, analysis, data.String(), lengthTarget(opts.TargetWords, 800, 1500))
+	prompt += languageInstruction(opts.Language)
+	prompt += chapterInstructions(opts.Instructions)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert in quantitative research, reporting results accurately and in academic style."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokensFor(opts.TargetWords, 3000),
+		Temperature: tunables.AITemperatureResults,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for results narrative failed: %w", err)
+	}
+
+	s.logger.Info("Results narrative generated successfully", "title", title)
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// CustomChapter is a chapter of a type with no dedicated generator, written from its
+// title, its type and the student's brief in ChapterOptions.Instructions
+type CustomChapter struct {
+	Title           string
+	TypeLabel       string // e.g. "Theoretical Framework"
+	TypeDescription string
+	OtherChapters   []string // Titles of the thesis's other chapters, so it doesn't repeat them
+}
+
+// GenerateCustomChapter writes a chapter of a user-defined type. Without a brief it
+// falls back on what the type's label and description imply.
+func (s *AIService) GenerateCustomChapter(ctx context.Context, title, specialization string, opts ChapterOptions, chapter CustomChapter) (string, error) {
+	s.logger.Info("Generating custom chapter", "title", title, "chapter", chapter.Title, "type", chapter.TypeLabel, "language", opts.Language, "targetWords", opts.TargetWords)
+	typeLabel := promptLine(chapter.TypeLabel)
+	brief := promptText(opts.Instructions, maxPromptInstructions)
+	if brief == "" {
+		brief = fmt.Sprintf("Write the %s chapter a thesis like this one would be expected to have.", typeLabel)
+		if description := promptLine(chapter.TypeDescription); description != "" {
+			brief += " " + description
+		}
+	}
+	others := "None yet"
+	if len(chapter.OtherChapters) > 0 {
+		others = strings.Join(promptLines(chapter.OtherChapters), "; ")
+	}
+	prompt := fmt.Sprintf(`
+You are an academic research assistant. Write the chapter "%s" (a %s chapter, target %s) of a research thesis.
+
+Thesis Title: "%s"
+Specialization: %s
+The thesis's other chapters: %s
+
+What the chapter should cover:
+"""
+%s
+"""
+
+Structure the chapter with Markdown headings, cite sources in (Author, Year) format where claims need support, and do not repeat what the other chapters cover. Ensure academic tone and clarity.
+`, promptLine(chapter.Title), typeLabel, lengthTarget(opts.TargetWords, 1000, 1500), promptLine(title), promptLine(specialization), others, brief)
+	prompt += languageInstruction(opts.Language)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert academic writer who drafts thesis chapters to the student's brief."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokensFor(opts.TargetWords, 2500),
+		Temperature: tunables.AITemperatureCustomChapter,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for custom chapter failed: %w", err)
+	}
+
+	s.logger.Info("Custom chapter generated successfully", "title", title, "chapter", chapter.Title)
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// SectionBrief is one section of a chapter to be written on its own, in the context of
+// the chapter around it
+type SectionBrief struct {
+	Title          string
+	Number         string // e.g. 2.3, as the section is numbered in the document
+	ChapterTitle   string
+	ChapterType    string   // The chapter type's label, e.g. "Literature Review"
+	ChapterBrief   string   // The chapter's custom prompt, if any
+	Outline        []string // Numbered titles of all the chapter's sections, this one included
+	CurrentContent string   // What the section says now, to improve on rather than start over
+}
+
+// GenerateSection writes a single section of a chapter, so that one part can be
+// regenerated without rewriting the rest. ChapterOptions.Instructions are the
+// student's instructions for this section.
+func (s *AIService) GenerateSection(ctx context.Context, title, specialization string, opts ChapterOptions, section SectionBrief) (string, error) {
+	s.logger.Info("Generating section", "title", title, "chapter", section.ChapterTitle, "section", section.Title, "language", opts.Language, "targetWords", opts.TargetWords)
+	prompt := fmt.Sprintf(`
+You are an academic research assistant. Write only section %s "%s" (target %s) of the chapter "%s" (a %s chapter) of a research thesis.
+
+Thesis Title: "%s"
+Specialization: %s
+The chapter's sections:
+%s
+`, section.Number, promptLine(section.Title), lengthTarget(opts.TargetWords, 300, 600), promptLine(section.ChapterTitle), promptLine(section.ChapterType),
+		promptLine(title), promptLine(specialization), strings.Join(promptLines(section.Outline), "\n"))
+	if brief := promptText(section.ChapterBrief, maxPromptInstructions); brief != "" {
+		prompt += fmt.Sprintf(`
+What the chapter as a whole should cover:
+"""
+%s
+"""
+`, brief)
+	}
+	if current := promptText(section.CurrentContent, maxPromptSource); current != "" {
+		prompt += fmt.Sprintf(`
+The section currently reads as follows; rewrite it, keeping what is sound:
+"""
+%s
+"""
+`, current)
+	}
+	prompt += `
+Cover this section's subject only, leaving the other sections' subjects to them. Write it as paragraphs, without the section's heading or headings of its own. Cite sources in (Author, Year) format where claims need support. Ensure academic tone and clarity.
+`
+	prompt += languageInstruction(opts.Language)
+	prompt += chapterInstructions(opts.Instructions)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert academic writer who revises thesis chapters one section at a time."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokensFor(opts.TargetWords, 1500),
+		Temperature: tunables.AITemperatureSection,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for section failed: %w", err)
+	}
+
+	s.logger.Info("Section generated successfully", "title", title, "section", section.Title)
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// AnalysisOutput is statistical output to be interpreted, as pasted by the student
+type AnalysisOutput struct {
+	Output           string
+	Software         string // spss, r, stata or other; empty when not given
+	ResearchQuestion string
+	Language         string
+}
+
+// InterpretAnalysis writes up statistical output as a results paragraph in academic
+// style, with the test statistics, significance and effect sizes it reports
+func (s *AIService) InterpretAnalysis(ctx context.Context, in AnalysisOutput) (string, error) {
+	s.logger.Info("Interpreting statistical output", "software", in.Software, "chars", len(in.Output), "language", in.Language)
+	software := map[string]string{"spss": "SPSS", "r": "R", "stata": "Stata"}[in.Software]
+	if software == "" {
+		software = "statistical software (identify it from the output)"
+	}
+	question := promptLine(in.ResearchQuestion)
+	if question == "" {
+		question = "not given; infer what was tested from the output"
+	}
+	prompt := fmt.Sprintf(`
+You are an academic research assistant. Interpret the following output from %s for the results chapter of a thesis.
+
+Research question or hypothesis: %s
+
+Output:
+"""
+%s
+"""
+
+Write one paragraph (two for output with several tests) of academic prose in APA style:
+- Name each test and what it compared or related, in plain terms.
+- Report the test statistics as the output gives them, e.g., t(48) = 2.31, p = .025, or F(2, 87) = 5.12, p = .008; write p < .001 for very small p-values.
+- State whether each result is statistically significant at the .05 level and what it means for the research question.
+- Report effect sizes (e.g., Cohen's d, eta squared, r, odds ratios) with their conventional magnitude (small, medium, large). Use the effect sizes in the output; only when none is given and one follows exactly from the reported statistics, derive it and say so. Otherwise add a bracketed placeholder such as [Report the effect size].
+- Report confidence intervals where the output gives them.
+
+Use only the numbers in the output; never invent values. Do not add headings, bullet points or commentary on the output itself.
+`, software, question, promptText(in.Output, maxPromptSource))
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nWrite the interpretation in %s, keeping statistical symbols and numbers as they are.\n", name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert statistician who reports quantitative results in academic style."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   1200,
+		Temperature: tunables.AITemperatureResults,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for analysis interpretation failed: %w", err)
+	}
+	return strings.TrimSpace(openAIResp.Choices[0].Message.Content), nil
+}
+
+// maxGuidelineChars bounds how much of a formatting guideline goes into the prompt
+const maxGuidelineChars = 24000
+
+// ExtractFormattingRules reads a university's thesis formatting guideline and returns
+// the rules it states; anything the guideline leaves open is left unset
+func (s *AIService) ExtractFormattingRules(ctx context.Context, guideline string) (models.FormattingOptions, error) {
+	guideline = promptText(guideline, maxGuidelineChars)
+	s.logger.Info("Extracting formatting rules", "chars", len(guideline))
+	prompt := fmt.Sprintf(`
+Below is a university's thesis formatting guideline. Extract the formatting rules it states for the main thesis document.
+
+Reply with a single JSON object and nothing else, using only these keys:
+- "font_family": body text font, e.g. "Times New Roman"
+- "font_size_main": body text size in points, e.g. 12
+- "line_spacing": body line spacing as a multiple, e.g. 1.5 (double spacing is 2)
+- "paper_size": "A4" or "Letter"
+- "margin_top_cm", "margin_bottom_cm", "margin_left_cm", "margin_right_cm": margins in centimetres (convert inches: 1 in = 2.54 cm)
+- "chapter_title_format": how chapter headings are written, using {number} and {title}, e.g. "CHAPTER {number}: {title}"
+- "chapter_numbering": "roman" if chapters are numbered I, II, III, or "arabic" for 1, 2, 3
+- "citation_style": one of "APA", "MLA", "Harvard", "Chicago", "IEEE"
+- "language": "ar" if the thesis is to be written in Arabic, otherwise "en"
+- "font_family_complex": font for Arabic text, e.g. "Simplified Arabic", when the guideline names one
+- "front_matter": the pages required before the first chapter, in order, from "title_page", "declaration", "dedication", "acknowledgments", "table_of_contents", "list_of_figures" and "list_of_tables"
+
+Leave out any key the guideline does not specify. Do not guess.
+
+Guideline:
+---GUIDELINE_START---
+%s
+---GUIDELINE_END---
+`, guideline)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You extract structured data from documents and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   500,
+		Temperature: 0.1, // Extraction, not writing (0 would be dropped by omitempty)
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return models.FormattingOptions{}, fmt.Errorf("OpenAI API call for formatting rules failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	// Models sometimes wrap the object in a code fence or a sentence
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return models.FormattingOptions{}, fmt.Errorf("formatting rules reply is not JSON: %q", content)
+	}
+	var rules models.FormattingOptions
+	if err := json.Unmarshal([]byte(content[start:end+1]), &rules); err != nil {
+		return models.FormattingOptions{}, fmt.Errorf("could not parse formatting rules: %w", err)
+	}
+	return rules, nil
+}
+
+// TimelineChapter is a chapter as a research timeline is planned around it
+type TimelineChapter struct {
+	Type        string
+	Title       string
+	Status      string
+	WordCount   int32
+	TargetWords int32
+}
+
+// TimelineInput is what a research timeline is planned from
+type TimelineInput struct {
+	Title          string
+	Specialization string
+	Chapters       []TimelineChapter
+	StartDate      time.Time
+	EndDate        time.Time
+	Milestones     []models.TimelineMilestone
+}
+
+// GenerateTimelineTasks plans the tasks of a research project with their durations and
+// dependencies. Dates are left to the caller, which schedules the tasks between the
+// start date and the deadline.
+func (s *AIService) GenerateTimelineTasks(ctx context.Context, in TimelineInput) ([]models.TimelineTask, error) {
+	s.logger.Info("Generating research timeline", "title", in.Title, "chapters", len(in.Chapters), "milestones", len(in.Milestones))
+	var chapters strings.Builder
+	for _, ch := range in.Chapters {
+		fmt.Fprintf(&chapters, "- %s (type %s): %s, %d words written", promptLine(ch.Title), ch.Type, ch.Status, ch.WordCount)
+		if ch.TargetWords > 0 {
+			fmt.Fprintf(&chapters, " of a %d word target", ch.TargetWords)
+		}
+		chapters.WriteString("\n")
+	}
+	if chapters.Len() == 0 {
+		chapters.WriteString("- No chapters yet; plan the usual thesis chapters\n")
+	}
+	var milestones strings.Builder
+	for _, m := range in.Milestones {
+		fmt.Fprintf(&milestones, "- %s: %s\n", m.Date, promptLine(m.Title))
+	}
+	if milestones.Len() == 0 {
+		milestones.WriteString("- None\n")
+	}
+	days := int(in.EndDate.Sub(in.StartDate).Hours()/24) + 1
+
+	prompt := fmt.Sprintf(`
+You are an academic research planner. Plan the remaining work of a research thesis as a list of tasks.
+
+Thesis Title: "%s"
+Specialization: %s
+Start date: %s
+Submission deadline: %s (%d days in total)
+
+Chapters:
+%s
+Fixed milestones:
+%s
+Cover research, writing and revision of each chapter (less for chapters already well advanced), data collection and analysis where the research needs them, and final proofreading and submission. Order the tasks so the milestones can be met, and fit the whole plan into the available days.
+
+Reply with a single JSON object and nothing else, in this form:
+{"tasks": [{"id": "t1", "title": "Search and screen literature", "chapter_type": "literature_review", "duration_days": 14, "depends_on": []}]}
+
+- "id": a short unique identifier
+- "chapter_type": the type of the chapter the task works on, or "" for tasks not tied to one chapter
+- "duration_days": working time in days, at least 1
+- "depends_on": ids of tasks that must finish before this one starts
+`, promptLine(in.Title), promptLine(in.Specialization), in.StartDate.Format(time.DateOnly), in.EndDate.Format(time.DateOnly), days, chapters.String(), milestones.String())
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You plan academic research projects and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   2000,
+		Temperature: 0.3,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call for timeline failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("timeline reply is not JSON: %q", content)
+	}
+	var plan struct {
+		Tasks []models.TimelineTask `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &plan); err != nil {
+		return nil, fmt.Errorf("could not parse timeline: %w", err)
+	}
+	return plan.Tasks, nil
+}
+
+// QuestionnaireItemsInput is what questionnaire items are drafted from
+type QuestionnaireItemsInput struct {
+	Title             string
+	Specialization    string
+	Description       string
+	Language          string
+	ResearchQuestions []string
+	Count             int
+	Type              string // An item type, or "mixed" to choose per item
+}
+
+// GenerateQuestionnaireItems drafts survey items that measure the research questions
+func (s *AIService) GenerateQuestionnaireItems(ctx context.Context, in QuestionnaireItemsInput) ([]models.QuestionnaireItem, error) {
+	s.logger.Info("Generating questionnaire items", "title", in.Title, "count", in.Count, "type", in.Type)
+	focus := "Research questions:\n"
+	for i, q := range in.ResearchQuestions {
+		focus += fmt.Sprintf("%d. %s\n", i+1, promptLine(q))
+	}
+	if len(in.ResearchQuestions) == 0 {
+		focus = fmt.Sprintf("Project description: %s\n", singleLine(promptText(in.Description, maxPromptExcerpt)))
+	}
+	itemType := fmt.Sprintf(`Every item has type "%s".`, in.Type)
+	if in.Type == "mixed" {
+		itemType = `Choose the type that suits each item: "likert", "single_choice", "multiple_choice", "yes_no" or "open".`
+	}
+	prompt := fmt.Sprintf(`
+You are an expert in survey design. Draft %d questionnaire items for a research thesis.
+
+Thesis Title: "%s"
+Specialization: %s
+%s
+Each item should measure one idea, be neutral and unambiguous, and avoid double-barrelled or leading wording. Cover every research question. %s
+
+Reply with a single JSON object and nothing else, in this form:
+{"items": [{"text": "The training improved my confidence with the software.", "type": "likert", "options": ["Strongly disagree", "Disagree", "Neutral", "Agree", "Strongly agree"], "required": true}]}
+
+- "options": the scale labels from lowest to highest for "likert" items, the choices for "single_choice" and "multiple_choice" items, and [] for "yes_no" and "open" items
+`, in.Count, promptLine(in.Title), promptLine(in.Specialization), focus, itemType)
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nWrite the items and their options in %s.\n", name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You design research instruments and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   min(120*in.Count+300, 6000),
+		Temperature: 0.5,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call for questionnaire items failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("questionnaire items reply is not JSON: %q", content)
+	}
+	var reply struct {
+		Items []models.QuestionnaireItem `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("could not parse questionnaire items: %w", err)
+	}
+	return reply.Items, nil
+}
+
+// maxEvaluationChars bounds how much of a chapter goes into an evaluation prompt
+const maxEvaluationChars = 60000
+
+// ChapterEvaluationInput is a chapter as it is evaluated against a rubric
+type ChapterEvaluationInput struct {
+	Title          string
+	Specialization string
+	Language       string
+	ChapterType    string
+	ChapterTitle   string
+	Content        string
+	Rubric         []models.RubricCriterion
+	MaxScore       int
+}
+
+// EvaluateChapter scores a chapter on each rubric criterion, with the reasons and
+// suggestions for improving it, and sums the chapter up
+func (s *AIService) EvaluateChapter(ctx context.Context, in ChapterEvaluationInput) ([]models.CriterionScore, string, error) {
+	content := promptText(in.Content, maxEvaluationChars)
+	s.logger.Info("Evaluating chapter", "title", in.ChapterTitle, "criteria", len(in.Rubric), "chars", len(content))
+	var rubric strings.Builder
+	for _, c := range in.Rubric {
+		fmt.Fprintf(&rubric, "- %s (key %q)", promptLine(c.Name), c.Key)
+		if c.Description != "" {
+			fmt.Fprintf(&rubric, ": %s", promptLine(c.Description))
+		}
+		rubric.WriteString("\n")
+	}
+	prompt := fmt.Sprintf(`
+You are an experienced thesis examiner. Evaluate the chapter below against the rubric, as an examiner would when marking it.
+
+Thesis Title: "%s"
+Specialization: %s
+Chapter: %s (type %s)
+
+Rubric:
+%s
+Score each criterion from 1 (poor) to %d (excellent), judging the chapter as it is rather than as it could be. Justify each score in one or two sentences that point to the text, and give up to three concrete suggestions for improving it, most important first.
+
+Reply with a single JSON object and nothing else, in this form:
+{"criteria": [{"key": "argument_clarity", "score": 3, "justification": "...", "suggestions": ["..."]}], "summary": "Two or three sentences on the chapter's main strengths and weaknesses."}
+
+Chapter:
+---CHAPTER_START---
+%s
+---CHAPTER_END---
+`, promptLine(in.Title), promptLine(in.Specialization), promptLine(in.ChapterTitle), in.ChapterType, rubric.String(), in.MaxScore, content)
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nThe chapter is written in %s; write the justifications, suggestions and summary in %s.\n", name, name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You examine academic theses and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   min(250*len(in.Rubric)+300, 4000),
+		Temperature: 0.2, // Marking should be repeatable
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return nil, "", fmt.Errorf("OpenAI API call for chapter evaluation failed: %w", err)
+	}
+	reply := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, "", fmt.Errorf("chapter evaluation reply is not JSON: %q", reply)
+	}
+	var evaluation struct {
+		Criteria []models.CriterionScore `json:"criteria"`
+		Summary  string                  `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &evaluation); err != nil {
+		return nil, "", fmt.Errorf("could not parse chapter evaluation: %w", err)
+	}
+	return evaluation.Criteria, evaluation.Summary, nil
+}
+
+// maxDefenseChars bounds how much of a thesis goes into the defense questions prompt;
+// each chapter gets an equal share
+const maxDefenseChars = 80000
+
+// DefenseChapter is a chapter as viva questions and defense slides are prepared from it
+type DefenseChapter struct {
+	Type    string
+	Title   string
+	Content string
+}
+
+// DefenseQuestionsInput is the thesis viva questions and defense slides are prepared from
+type DefenseQuestionsInput struct {
+	Title          string
+	Specialization string
+	Language       string
+	Chapters       []DefenseChapter
+	PerChapter     int // Questions per chapter
+}
+
+// defenseThesis lays the chapters out for a prompt, each cut to an equal share of
+// maxDefenseChars
+func defenseThesis(chapters []DefenseChapter) string {
+	share := maxDefenseChars / max(len(chapters), 1)
+	var thesis strings.Builder
+	for _, ch := range chapters {
+		content := promptText(ch.Content, share)
+		fmt.Fprintf(&thesis, "---CHAPTER %s START: %s---\n%s\n---CHAPTER %s END---\n\n", ch.Type, promptLine(ch.Title), content, ch.Type)
+	}
+	return thesis.String()
+}
+
+// GenerateDefenseQuestions prepares the questions examiners are likely to ask about a
+// thesis, keyed by chapter type, with outlines of good answers. Questions about the
+// thesis as a whole are returned separately.
+func (s *AIService) GenerateDefenseQuestions(ctx context.Context, in DefenseQuestionsInput) (map[string][]models.DefenseQuestion, []models.DefenseQuestion, error) {
+	s.logger.Info("Generating defense questions", "title", in.Title, "chapters", len(in.Chapters), "perChapter", in.PerChapter)
+	prompt := fmt.Sprintf(`
+You are an experienced external examiner preparing for a thesis viva (oral defense). Read the thesis below and write the questions you would be most likely to ask.
+
+Thesis Title: "%s"
+Specialization: %s
+
+Write %d questions for each chapter, aimed at what that chapter claims, how it justifies its choices and where it is weakest, and up to 5 general questions about the thesis as a whole (contribution, limitations, future work). For each question give the reason an examiner would ask it and an outline of the points a strong answer covers, drawing on the thesis itself.
+
+Reply with a single JSON object and nothing else, in this form:
+{"chapters": [{"chapter": "methodology", "questions": [{"question": "Why did you choose a purposive sample?", "rationale": "...", "answer_outline": ["...", "..."]}]}], "general": [{"question": "...", "rationale": "...", "answer_outline": ["..."]}]}
+
+- "chapter": the type given in the chapter's START line
+
+%s`, promptLine(in.Title), promptLine(in.Specialization), in.PerChapter, defenseThesis(in.Chapters))
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nThe thesis is written in %s; write the questions and answers in %s.\n", name, name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You examine academic theses and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   min(180*in.PerChapter*len(in.Chapters)+1000, 8000),
+		Temperature: 0.5,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("OpenAI API call for defense questions failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, nil, fmt.Errorf("defense questions reply is not JSON: %q", content)
+	}
+	var reply struct {
+		Chapters []struct {
+			Chapter   string                   `json:"chapter"`
+			Questions []models.DefenseQuestion `json:"questions"`
+		} `json:"chapters"`
+		General []models.DefenseQuestion `json:"general"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err != nil {
+		return nil, nil, fmt.Errorf("could not parse defense questions: %w", err)
+	}
+	byChapter := make(map[string][]models.DefenseQuestion, len(reply.Chapters))
+	for _, ch := range reply.Chapters {
+		byChapter[ch.Chapter] = append(byChapter[ch.Chapter], ch.Questions...)
+	}
+	return byChapter, reply.General, nil
+}
+
+// PresentationSlide is one content slide of a defense presentation
+type PresentationSlide struct {
+	Section string   `json:"section"` // problem, methods, findings or conclusions
+	Title   string   `json:"title"`
+	Bullets []string `json:"bullets"`
+	Notes   string   `json:"notes"`
+}
+
+// GeneratePresentationSlides writes the content slides of a thesis defense presentation,
+// covering the problem, methods, findings and conclusions. The title slide is left to the
+// renderer.
+func (s *AIService) GeneratePresentationSlides(ctx context.Context, in DefenseQuestionsInput) ([]PresentationSlide, error) {
+	s.logger.Info("Generating presentation slides", "title", in.Title, "chapters", len(in.Chapters))
+	prompt := fmt.Sprintf(`
+You are helping a student prepare the slides for their thesis defense. Read the thesis below and write the content slides of a 15 to 20 minute presentation.
+
+Thesis Title: "%s"
+Specialization: %s
+
+Cover, in this order: the research problem and its significance, the research questions or objectives, the methods, the key findings, and the conclusions with contributions, limitations and future work. Use 8 to 14 slides, drawn from the thesis itself; do not invent results. Each slide has a short title, 3 to 5 concise bullet points (no more than 15 words each) and speaker notes of 2 to 4 sentences saying what to explain. Do not write a title slide.
+
+Reply with a single JSON object and nothing else, in this form:
+{"slides": [{"section": "problem", "title": "The Problem", "bullets": ["..."], "notes": "..."}]}
+
+- "section": one of "problem", "methods", "findings" or "conclusions"
+
+%s`, promptLine(in.Title), promptLine(in.Specialization), defenseThesis(in.Chapters))
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nThe thesis is written in %s; write the slides and notes in %s.\n", name, name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You write clear academic presentations and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   4000,
+		Temperature: 0.4,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI API call for presentation slides failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("presentation slides reply is not JSON: %q", content)
+	}
+	var reply struct {
+		Slides []PresentationSlide `json:"slides"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("could not parse presentation slides: %w", err)
+	}
+	return reply.Slides, nil
+}
+
+// PosterContent is the text of an academic poster
+type PosterContent struct {
+	Abstract    string          `json:"abstract"`
+	Sections    []PosterSection `json:"sections"`
+	Conclusions []string        `json:"conclusions"`
+}
+
+// PosterSection is a headed block of bullets on a poster
+type PosterSection struct {
+	Heading string   `json:"heading"`
+	Bullets []string `json:"bullets"`
+}
+
+// GeneratePosterContent condenses a thesis into the text of a poster: a short abstract,
+// sections on the background, methods and results, and the conclusions
+func (s *AIService) GeneratePosterContent(ctx context.Context, in DefenseQuestionsInput) (PosterContent, error) {
+	s.logger.Info("Generating poster content", "title", in.Title, "chapters", len(in.Chapters))
+	prompt := fmt.Sprintf(`
+You are helping a student turn their thesis into an academic conference poster. Read the thesis below and write the poster's text.
+
+Thesis Title: "%s"
+Specialization: %s
+
+Write an abstract of 120 to 180 words; three or four sections, such as background, objectives, methods and results, each with a short heading and 3 to 5 bullet points of no more than 20 words; and 3 to 5 conclusions of one sentence each. Posters are read from a distance, so be concise and concrete, and draw only on the thesis itself; do not invent results.
+
+Reply with a single JSON object and nothing else, in this form:
+{"abstract": "...", "sections": [{"heading": "Methods", "bullets": ["..."]}], "conclusions": ["..."]}
+
+%s`, promptLine(in.Title), promptLine(in.Specialization), defenseThesis(in.Chapters))
+	if name, ok := ProjectLanguages[in.Language]; ok && in.Language != LanguageEnglish {
+		prompt += fmt.Sprintf("\nThe thesis is written in %s; write the poster in %s.\n", name, name)
+	}
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You write clear academic posters and reply with JSON only."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   2000,
+		Temperature: 0.4,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return PosterContent{}, fmt.Errorf("OpenAI API call for poster content failed: %w", err)
+	}
+	content := openAIResp.Choices[0].Message.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return PosterContent{}, fmt.Errorf("poster content reply is not JSON: %q", content)
+	}
+	var poster PosterContent
+	if err := json.Unmarshal([]byte(content[start:end+1]), &poster); err != nil {
+		return PosterContent{}, fmt.Errorf("could not parse poster content: %w", err)
+	}
+	return poster, nil
+}
+
+// GenerateExecutiveSummary writes an executive summary of a thesis for its supervisors
+// or a funding body: longer than an abstract, and readable without the thesis
+func (s *AIService) GenerateExecutiveSummary(ctx context.Context, in DefenseQuestionsInput, audience string, targetWords int) (string, error) {
+	s.logger.Info("Generating executive summary", "title", in.Title, "chapters", len(in.Chapters), "audience", audience, "targetWords", targetWords)
+	reader := "the student's supervisors, who know the field and want to judge the progress, rigour and contribution of the work"
+	if audience == "funding_body" {
+		reader = "a funding body, whose reviewers may not be specialists and want to know why the work matters, what it found and what it would achieve with further support"
+	}
+	prompt := fmt.Sprintf(`
+You are an academic research assistant. Read the thesis below and write an executive summary of it (target %s, about 2 to 3 pages) for %s.
+
+Thesis Title: "%s"
+Specialization: %s
+
+The executive summary is not the abstract: it stands on its own as a short report. Use Markdown headings for its sections:
+1. Background and problem: the context and the gap the research addresses.
+2. Aims: the research questions or objectives.
+3. Approach: the methods, in plain terms.
+4. Key findings: the main results, with the figures that matter.
+5. Implications and recommendations: what the findings mean and what should follow.
+
+Draw only on the thesis itself; do not invent results, and say plainly where chapters are not yet written. Avoid jargon the reader would not know, and keep citations to a minimum.
+
+%s`, lengthTarget(targetWords, 1000, 1400), reader, promptLine(in.Title), promptLine(in.Specialization), defenseThesis(in.Chapters))
+	prompt += languageInstruction(in.Language)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert academic writer who summarises research for decision makers."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokensFor(targetWords, 3000),
+		Temperature: 0.4,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for executive summary failed: %w", err)
+	}
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
+// ArticleSectionInput is one IMRaD section of a journal article and the thesis chapters
+// it is condensed from
+type ArticleSectionInput struct {
+	Title          string
+	Specialization string
+	Language       string
+	Section        string // abstract, introduction, methods, results or discussion
+	Journal        string // Journal the article is prepared for; may be empty
+	CitationStyle  string
+	TargetWords    int
+	Chapters       []DefenseChapter
+}
+
+// articleSectionBriefs say what each section of an IMRaD article covers
+var articleSectionBriefs = map[string]string{
+	"abstract":     "a single-paragraph structured abstract stating the purpose, methods, key results and main conclusion, with no citations",
+	"introduction": "the background and the gap in the literature, kept to the sources that matter most, ending with the aims or research questions of the study",
+	"methods":      "the design, participants or data, instruments, procedure and analysis, with enough detail to replicate the study",
+	"results":      "the findings in a logical order with the key statistics, without interpreting them",
+	"discussion":   "what the findings mean, how they compare with earlier work, the limitations, and a short conclusion with implications and future work",
+}
+
+// CondenseArticleSection writes one section of a journal article from the thesis chapters
+// it covers. The abstract is condensed from the other sections once they are written.
+func (s *AIService) CondenseArticleSection(ctx context.Context, in ArticleSectionInput) (string, error) {
+	s.logger.Info("Condensing article section", "title", in.Title, "section", in.Section, "chapters", len(in.Chapters), "targetWords", in.TargetWords)
+	journal := "a peer-reviewed journal in the field"
+	if in.Journal != "" {
+		journal = fmt.Sprintf("the journal %q, following its conventions", promptLine(in.Journal))
+	}
+	prompt := fmt.Sprintf(`
+You are an academic editor turning a thesis into a journal article for %s. Write the %s section of the article (about %d words): %s.
+
+Thesis Title: "%s"
+Specialization: %s
+
+Condense rather than copy: a journal article is far shorter than a thesis, so keep what a reviewer needs and drop the rest. Draw only on the text below; do not invent results or sources. Keep the in-text citations of the sources you use, in %s style as written in the thesis, and do not add a reference list. Write the section body only, without its heading; use Markdown subheadings only where the journal would.
+
+%s`, journal, in.Section, in.TargetWords, articleSectionBriefs[in.Section], promptLine(in.Title), promptLine(in.Specialization), in.CitationStyle, defenseThesis(in.Chapters))
+	prompt += languageInstruction(in.Language)
+
+	tunables := s.tunables.Load()
+	request := OpenAIRequest{
+		Model: tunables.AIModel,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: "You are an expert academic editor who condenses theses into publishable journal articles."},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens:   maxTokensFor(in.TargetWords, 1000),
+		Temperature: 0.4,
+		prose:       true,
+	}
+
+	openAIResp, err := s.callOpenAI(ctx, request)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API call for article %s failed: %w", in.Section, err)
+	}
+	return openAIResp.Choices[0].Message.Content, nil
+}
+
 // extractPlaceholderReferences is a simplified placeholder.
 // In a real application, you'd use more sophisticated NLP to parse references
 // or have the AI return them in a structured format (e.g., JSON within the response).
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// InterpretAnalysis turns pasted statistical output into an interpretation paragraph for
+// a results chapter. It is not tied to a project, so it counts against the user's own
+// plan like a chapter generation.
+func (s *ResearchService) InterpretAnalysis(ctx context.Context, userID uuid.UUID, req apimodels.InterpretAnalysisRequest) (apimodels.AnalysisInterpretationResponse, error) {
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return apimodels.AnalysisInterpretationResponse{}, err
+	}
+	ctx, err := s.withGenerationPreferences(ctx, nil, userID)
+	if err != nil {
+		return apimodels.AnalysisInterpretationResponse{}, err
+	}
+	release, err := s.billing.ReserveUsage(ctx, userID, UsageAIGenerations)
+	if err != nil {
+		return apimodels.AnalysisInterpretationResponse{}, err
+	}
+	ctx = s.withGenerationLog(ctx, GenerationKindAnalysis, userID, nil, nil)
+	ctx = s.quotas.withAIWords(ctx, userID)
+	ctx, cacheUse := withResponseCacheUse(ctx, false)
+	interpretation, err := s.aiService.InterpretAnalysis(ctx, AnalysisOutput{
+		Output:           req.Output,
+		Software:         req.Software,
+		ResearchQuestion: req.ResearchQuestion,
+		Language:         req.Language,
+	})
+	if err != nil {
+		release()
+		s.logger.Error("AI analysis interpretation failed", "userID", userID, "error", err)
+		return apimodels.AnalysisInterpretationResponse{}, fmt.Errorf("AI generation failed: %w", err)
+	}
+	if !cacheUse.fresh() {
+		release() // A reused reply is not a new generation
+	}
+	return apimodels.AnalysisInterpretationResponse{Interpretation: interpretation, WordCount: int(countWords(interpretation))}, nil
+}
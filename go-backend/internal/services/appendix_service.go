@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrAppendixNotFound  = errors.New("appendix not found")
+	ErrAppendixNoFile    = errors.New("appendix has no attached file")
+	ErrAppendixImageType = errors.New("appendix files must be PNG or JPEG images")
+)
+
+// ListAppendices returns a project's appendices in lettering order
+func (s *ResearchService) ListAppendices(ctx context.Context, projectID, userID uuid.UUID) ([]apimodels.AppendixResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	appendices, err := s.store.ListProjectAppendices(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching appendices: %w", err)
+	}
+	resp := make([]apimodels.AppendixResponse, len(appendices))
+	for i, a := range appendices {
+		resp[i] = apimodels.ToAppendixResponse(a, appendixLetter(i))
+	}
+	return resp, nil
+}
+
+// CreateAppendix adds an appendix to a project. Requires the edit role.
+func (s *ResearchService) CreateAppendix(ctx context.Context, projectID, userID uuid.UUID, req apimodels.CreateAppendixRequest) (apimodels.AppendixResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return apimodels.AppendixResponse{}, err
+	}
+	title := markdown.SanitizeLine(req.Title)
+	if title == "" {
+		return apimodels.AppendixResponse{}, ErrEmptyAfterSanitizing
+	}
+	appendix, err := s.store.CreateAppendix(ctx, sqlc.CreateAppendixParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		Title:     title,
+		Content:   markdown.Sanitize(req.Content),
+		Position:  req.Position,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return apimodels.AppendixResponse{}, fmt.Errorf("could not create appendix: %w", err)
+	}
+	s.logger.Info("Appendix created", "projectID", projectID, "appendixID", appendix.ID.Bytes, "userID", userID)
+	return s.appendixResponse(ctx, appendix)
+}
+
+// UpdateAppendix changes an appendix's title, content or position. Requires the edit role.
+func (s *ResearchService) UpdateAppendix(ctx context.Context, projectID, appendixID, userID uuid.UUID, req apimodels.UpdateAppendixRequest) (apimodels.AppendixResponse, error) {
+	appendix, err := s.getAppendix(ctx, projectID, appendixID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.AppendixResponse{}, err
+	}
+	params := sqlc.UpdateAppendixParams{
+		Title:    appendix.Title,
+		Content:  appendix.Content,
+		Position: appendix.Position,
+		ID:       appendix.ID,
+	}
+	if req.Title != nil {
+		if params.Title = markdown.SanitizeLine(*req.Title); params.Title == "" {
+			return apimodels.AppendixResponse{}, ErrEmptyAfterSanitizing
+		}
+	}
+	if req.Content != nil {
+		params.Content = markdown.Sanitize(*req.Content)
+	}
+	if req.Position != nil {
+		params.Position = *req.Position
+	}
+	appendix, err = s.store.UpdateAppendix(ctx, params)
+	if err != nil {
+		return apimodels.AppendixResponse{}, fmt.Errorf("could not update appendix: %w", err)
+	}
+	return s.appendixResponse(ctx, appendix)
+}
+
+// SetAppendixFile attaches an image to an appendix, replacing any earlier one.
+// Requires the edit role.
+func (s *ResearchService) SetAppendixFile(ctx context.Context, projectID, appendixID, userID uuid.UUID, r io.Reader) (apimodels.AppendixResponse, error) {
+	appendix, err := s.getAppendix(ctx, projectID, appendixID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.AppendixResponse{}, err
+	}
+
+	// A fresh key per file, so the old one stays valid until the row points elsewhere
+	filePath := projectFileKey(projectID, "appendices", uuid.NewString())
+	stored, err := s.writeUpload(ctx, filePath, r, appendixUploadRule)
+	if err != nil {
+		return apimodels.AppendixResponse{}, err
+	}
+
+	previous := appendix.FilePath
+	appendix, err = s.store.SetAppendixFile(ctx, sqlc.SetAppendixFileParams{FilePath: filePath, MimeType: stored.MimeType, ID: appendix.ID})
+	if err != nil {
+		s.removeUploadFile(ctx, filePath)
+		return apimodels.AppendixResponse{}, fmt.Errorf("could not attach appendix file: %w", err)
+	}
+	if previous != "" {
+		s.removeUploadFile(ctx, previous)
+	}
+	return s.appendixResponse(ctx, appendix)
+}
+
+// AppendixFile returns the attached image of an appendix and its MIME type
+func (s *ResearchService) AppendixFile(ctx context.Context, projectID, appendixID, userID uuid.UUID) (string, string, error) {
+	appendix, err := s.getAppendix(ctx, projectID, appendixID, userID, ProjectRoleRead)
+	if err != nil {
+		return "", "", err
+	}
+	if appendix.FilePath == "" {
+		return "", "", ErrAppendixNoFile
+	}
+	return appendix.FilePath, appendix.MimeType, nil
+}
+
+// DeleteAppendix removes an appendix and its file; later appendices move up a letter.
+// Requires the edit role.
+func (s *ResearchService) DeleteAppendix(ctx context.Context, projectID, appendixID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	appendix, err := s.store.DeleteAppendix(ctx, sqlc.DeleteAppendixParams{
+		ID:        pgtype.UUID{Bytes: appendixID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrAppendixNotFound
+		}
+		return fmt.Errorf("could not delete appendix: %w", err)
+	}
+	if appendix.FilePath != "" {
+		s.removeUploadFile(ctx, appendix.FilePath)
+	}
+	s.logger.Info("Appendix deleted", "projectID", projectID, "appendixID", appendixID, "userID", userID)
+	return nil
+}
+
+func (s *ResearchService) getAppendix(ctx context.Context, projectID, appendixID, userID uuid.UUID, role string) (sqlc.ProjectAppendix, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, role); err != nil {
+		return sqlc.ProjectAppendix{}, err
+	}
+	appendix, err := s.store.GetAppendix(ctx, sqlc.GetAppendixParams{
+		ID:        pgtype.UUID{Bytes: appendixID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectAppendix{}, ErrAppendixNotFound
+		}
+		return sqlc.ProjectAppendix{}, fmt.Errorf("database error fetching appendix: %w", err)
+	}
+	return appendix, nil
+}
+
+// appendixResponse letters one appendix among the rest of its project's
+func (s *ResearchService) appendixResponse(ctx context.Context, appendix sqlc.ProjectAppendix) (apimodels.AppendixResponse, error) {
+	appendices, err := s.store.ListProjectAppendices(ctx, appendix.ProjectID)
+	if err != nil {
+		return apimodels.AppendixResponse{}, fmt.Errorf("database error fetching appendices: %w", err)
+	}
+	for i, a := range appendices {
+		if a.ID == appendix.ID {
+			return apimodels.ToAppendixResponse(appendix, appendixLetter(i)), nil
+		}
+	}
+	return apimodels.ToAppendixResponse(appendix, ""), nil
+}
+
+// appendixLetter letters the i-th appendix (from 0) like spreadsheet columns: A-Z, AA, AB, ...
+func appendixLetter(i int) string {
+	letter := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		letter = string(rune('A'+(i-1)%26)) + letter
+	}
+	return letter
+}
+
+// PythonAppendixData is an appendix as the docgen service renders it after the references
+type PythonAppendixData struct {
+	Letter      string `json:"letter"`
+	Title       string `json:"title"`
+	Content     string `json:"content,omitempty"`
+	ImageBase64 string `json:"image_base64,omitempty"`
+}
+
+// pythonAppendices loads a project's appendices for document generation, followed by
+// the questionnaires it includes. An image that has gone missing is left out rather
+// than failing the document.
+func (s *ResearchService) pythonAppendices(ctx context.Context, projectID uuid.UUID, opts apimodels.FormattingOptions) ([]PythonAppendixData, error) {
+	appendices, err := s.store.ListProjectAppendices(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch appendices for doc gen: %w", err)
+	}
+	out := make([]PythonAppendixData, len(appendices))
+	for i, a := range appendices {
+		out[i] = PythonAppendixData{Letter: documentAppendixLetter(i, opts), Title: a.Title, Content: a.Content}
+		if a.FilePath == "" {
+			continue
+		}
+		image, err := s.readFile(ctx, a.FilePath)
+		if err != nil {
+			s.logger.Warn("Appendix image unavailable, leaving it out", "appendixID", a.ID.Bytes, "error", err)
+			continue
+		}
+		out[i].ImageBase64 = base64.StdEncoding.EncodeToString(image)
+	}
+	questionnaires, err := s.questionnaireAppendices(ctx, projectID, len(out), opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, questionnaires...), nil
+}
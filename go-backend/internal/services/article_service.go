@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultArticleWords  = 7000 // Body of the article, without the abstract and references
+	articleAbstractWords = 250
+)
+
+// ArticleOptions are the choices made when an article was queued
+type ArticleOptions struct {
+	TargetWords   int    `json:"target_words"`
+	CitationStyle string `json:"citation_style,omitempty"` // The project's when empty
+	Journal       string `json:"journal,omitempty"`
+}
+
+// articleSections are the IMRaD sections of an article in order, with the chapter types
+// each is condensed from and its share of the word target. A section none of whose
+// chapters is written is left out.
+var articleSections = []struct {
+	Key   string
+	From  []string
+	Share float64
+}{
+	{"introduction", []string{"introduction", "literature_review"}, 0.25},
+	{"methods", []string{"methodology"}, 0.2},
+	{"results", []string{"results"}, 0.3},
+	{"discussion", []string{"results", "conclusion", "literature_review"}, 0.25},
+}
+
+// GenerateArticle queues a journal article condensed from the project: the AI writes each
+// IMRaD section from the chapters it covers, then the abstract from those sections, and
+// the docgen service renders them as a manuscript. It is downloaded like any generated
+// document.
+func (s *ResearchService) GenerateArticle(ctx context.Context, projectID, userID uuid.UUID, req apimodels.GenerateArticleRequest) (sqlc.GeneratedDocument, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GenerateArticle")
+	span.SetAttributes(attribute.String("project.id", projectID.String()))
+	defer span.End()
+
+	opts := ArticleOptions{TargetWords: req.TargetWords, CitationStyle: req.CitationStyle, Journal: strings.TrimSpace(req.Journal)}
+	if opts.TargetWords == 0 {
+		opts.TargetWords = defaultArticleWords
+	}
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	if len(defenseChapters(chapters)) == 0 { // Fail now rather than in the job
+		return sqlc.GeneratedDocument{}, ErrNoThesisContent
+	}
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return sqlc.GeneratedDocument{}, err
+	}
+	return s.queueDocument(ctx, project, documentJob{UserID: userID, Kind: DocumentKindArticle, Article: &opts})
+}
+
+// generateArticle condenses the chapters of a queued article section by section, with
+// the preferences of the user who asked for it, and has the manuscript rendered
+func (s *ResearchService) generateArticle(ctx context.Context, project sqlc.ResearchProject, userID uuid.UUID, dbDoc sqlc.GeneratedDocument, opts *ArticleOptions) (sqlc.GeneratedDocument, error) {
+	if opts == nil {
+		opts = &ArticleOptions{TargetWords: defaultArticleWords}
+	}
+	ctx, err := s.withGenerationPreferences(ctx, &project, userID)
+	if err != nil {
+		return dbDoc, err
+	}
+	chaptersDB, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return dbDoc, fmt.Errorf("failed to fetch chapters for article: %w", err)
+	}
+	chapters := defenseChapters(chaptersDB)
+	if len(chapters) == 0 {
+		return dbDoc, ErrNoThesisContent
+	}
+	formatting, err := s.projectFormatting(ctx, project)
+	if err != nil {
+		return dbDoc, err
+	}
+	if opts.CitationStyle != "" {
+		formatting.CitationStyle = opts.CitationStyle
+	}
+	labels := documentLabels(formatting)
+	input := ArticleSectionInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       formatting.Language,
+		Journal:        opts.Journal,
+		CitationStyle:  formatting.CitationStyle,
+	}
+	if input.CitationStyle == "" {
+		input.CitationStyle = "APA"
+	}
+
+	// Sections with no chapter to condense are left out; the abstract is written last
+	sources := make([][]DefenseChapter, len(articleSections))
+	totalSteps := 1
+	for i, section := range articleSections {
+		for _, ch := range chapters {
+			if slices.Contains(section.From, ch.Type) {
+				sources[i] = append(sources[i], ch)
+			}
+		}
+		if len(sources[i]) > 0 {
+			totalSteps++
+		}
+	}
+
+	// Each section is kept as an unsaved chapter, with an ID of its own for its citations
+	var sections []sqlc.Chapter
+	var written []DefenseChapter
+	step := 0
+	for i, section := range articleSections {
+		if len(sources[i]) == 0 {
+			continue
+		}
+		input.Section = section.Key
+		input.TargetWords = int(float64(opts.TargetWords) * section.Share)
+		input.Chapters = sources[i]
+		content, err := s.aiService.CondenseArticleSection(ctx, input)
+		if err != nil {
+			return dbDoc, fmt.Errorf("AI generation failed: %w", err)
+		}
+		step++
+		events.Emit(ctx, events.Event{Type: events.GenerationProgress, Message: fmt.Sprintf("Section %d of %d written: %s", step, totalSteps, labels[section.Key]), Step: step, TotalSteps: totalSteps})
+		if content = strings.TrimSpace(content); content == "" {
+			continue
+		}
+		sections = append(sections, articleSection(section.Key, labels[section.Key], content))
+		written = append(written, DefenseChapter{Type: section.Key, Title: labels[section.Key], Content: content})
+	}
+	if len(sections) == 0 {
+		return dbDoc, errors.New("AI generation failed: no article section was written")
+	}
+	input.Section, input.TargetWords, input.Chapters = "abstract", articleAbstractWords, written
+	abstract, err := s.aiService.CondenseArticleSection(ctx, input)
+	if err != nil {
+		return dbDoc, fmt.Errorf("AI generation failed: %w", err)
+	}
+	events.Emit(ctx, events.Event{Type: events.GenerationProgress, Message: fmt.Sprintf("Section %d of %d written: %s", totalSteps, totalSteps, labels["abstract"]), Step: totalSteps, TotalSteps: totalSteps})
+	if abstract = strings.TrimSpace(abstract); abstract != "" {
+		sections = slices.Insert(sections, 0, articleSection("abstract", labels["abstract"], abstract))
+	}
+
+	bib, err := s.unsavedBibliography(ctx, project.ID, sections, formatting.CitationStyle)
+	if err != nil {
+		return dbDoc, err
+	}
+	if bib.Unresolved > 0 {
+		s.logger.Warn("Article cites works missing from the project's references", "projectID", uuid.UUID(project.ID.Bytes), "citations", bib.Unresolved)
+	}
+	sectionsPy := make([]PythonChapterData, len(sections))
+	for i, section := range sections {
+		sectionsPy[i] = PythonChapterData{Type: section.Type, Title: section.Title, Content: bib.chapterContent(section)}
+		if s.integrity.WatermarkExports { // Written by the AI throughout
+			sectionsPy[i].AIShare = 1
+		}
+	}
+	s.logger.Info("Article sections written", "projectID", uuid.UUID(project.ID.Bytes), "sections", len(sections), "targetWords", opts.TargetWords)
+
+	return s.renderDocument(ctx, dbDoc, "/generate-document", PythonDocGenRequest{
+		ProjectID:         project.ID.Bytes,
+		ResearchTitle:     project.Title,
+		StudentName:       s.studentName(ctx, project),
+		UniversityName:    project.University.String,
+		Specialization:    project.Specialization,
+		Chapters:          sectionsPy,
+		References:        bib.References,
+		FormattingOptions: formatting,
+		Direction:         documentDirection(formatting),
+		Labels:            labels,
+		Layout:            "article",
+		Journal:           opts.Journal,
+	})
+}
+
+// articleSection is a written section of an article, in the shape of a chapter
+func articleSection(key, title, content string) sqlc.Chapter {
+	return sqlc.Chapter{
+		ID:      pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Type:    key,
+		Title:   title,
+		Content: pgtype.Text{String: content, Valid: true},
+	}
+}
@@ -14,7 +14,9 @@ import (
 	"github.com/shawgichan/research-service/go-backend/internal/token"
 	"github.com/shawgichan/research-service/go-backend/internal/util"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -23,6 +25,7 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrSessionNotFound    = errors.New("session not found or expired")
 	ErrSessionBlocked     = errors.New("session is blocked")
+	ErrNotGuest           = errors.New("account is already registered")
 )
 
 type AuthService struct {
@@ -108,6 +111,71 @@ func (s *AuthService) Login(ctx context.Context, req models.LoginUserRequest, us
 	return s.createSessionAndTokens(ctx, user, userAgent, clientIP)
 }
 
+// CreateGuest creates a guest account that expires after the guest TTL, with a
+// placeholder address and a password nobody knows. The guest gets one access token for
+// its whole life; the sandbox project is the caller's to create.
+func (s *AuthService) CreateGuest(ctx context.Context) (*models.GuestSessionResponse, error) {
+	hashedPassword, err := util.HashPassword(uuid.NewString())
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+	user, err := s.store.CreateGuestUser(ctx, sqlc.CreateGuestUserParams{
+		Email:          fmt.Sprintf("guest-%s@%s", uuid.NewString(), guestEmailDomain),
+		PasswordHash:   hashedPassword,
+		FirstName:      "Guest",
+		GuestExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.config.GuestTTL), Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to create guest user", "error", err)
+		return nil, fmt.Errorf("could not create guest: %w", err)
+	}
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.ID.Bytes, s.config.GuestTTL)
+	if err != nil {
+		s.logger.Error("Failed to create access token", "userID", user.ID, "error", err)
+		return nil, fmt.Errorf("could not create access token: %w", err)
+	}
+	s.logger.Info("Guest created", "userID", user.ID, "expiresAt", user.GuestExpiresAt.Time)
+	return &models.GuestSessionResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+		User:                 models.ToUserResponse(user),
+	}, nil
+}
+
+// ClaimGuest registers a guest under the given details, keeping its projects and
+// lifting the trial limits, and logs it in as any user
+func (s *AuthService) ClaimGuest(ctx context.Context, userID uuid.UUID, req models.RegisterUserRequest, userAgent, clientIP string) (*models.LoginUserResponse, error) {
+	if _, err := s.store.GetUserByEmail(ctx, req.Email); err == nil {
+		return nil, ErrUserAlreadyExists
+	} else if !errors.Is(err, pgx.ErrNoRows) && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("database error checking user: %w", err)
+	}
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+	user, err := s.store.ClaimGuestUser(ctx, sqlc.ClaimGuestUserParams{
+		ID:           pgtype.UUID{Bytes: userID, Valid: true},
+		Email:        req.Email,
+		PasswordHash: hashedPassword,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrNotGuest
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation: // Taken since the check
+			return nil, ErrUserAlreadyExists
+		}
+		s.logger.Error("Failed to claim guest", "userID", userID, "error", err)
+		return nil, fmt.Errorf("could not register guest: %w", err)
+	}
+	s.logger.Info("Guest registered", "userID", user.ID, "email", user.Email)
+	return s.createSessionAndTokens(ctx, user, userAgent, clientIP)
+}
+
 func (s *AuthService) createSessionAndTokens(ctx context.Context, user sqlc.User, userAgent, clientIP string) (*models.LoginUserResponse, error) {
 	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.ID.Bytes, s.config.AccessTokenDuration)
 	if err != nil {
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// bibEntry is an entry of a BibTeX file, with field names lowercased and values
+// cleaned of LaTeX markup
+type bibEntry struct {
+	Type, Key string
+	Fields    map[string]string
+}
+
+// parseBibTeX reads the entries of a BibTeX file. @string macros are expanded;
+// @comment and @preamble are skipped, as is anything malformed up to the next @.
+func parseBibTeX(src string) []bibEntry {
+	p := &bibParser{src: src, macros: map[string]string{}}
+	var entries []bibEntry
+	for {
+		at := strings.IndexByte(p.src[p.pos:], '@')
+		if at < 0 {
+			return entries
+		}
+		p.pos += at + 1
+		entryType := strings.ToLower(p.ident())
+		p.space()
+		if p.pos >= len(p.src) || (p.src[p.pos] != '{' && p.src[p.pos] != '(') {
+			continue
+		}
+		closer := byte('}')
+		if p.src[p.pos] == '(' {
+			closer = ')'
+		}
+		p.pos++
+		switch entryType {
+		case "comment", "preamble":
+			p.pos-- // Skip the whole group
+			p.braced()
+		case "string":
+			for name, value := range p.fields(closer) {
+				p.macros[name] = value
+			}
+		default:
+			p.space()
+			key := strings.TrimSpace(p.until(",", string(closer)))
+			if p.pos < len(p.src) && p.src[p.pos] == ',' {
+				p.pos++
+			}
+			fields := p.fields(closer)
+			for name, value := range fields {
+				fields[name] = cleanLaTeX(value)
+			}
+			entries = append(entries, bibEntry{Type: entryType, Key: key, Fields: fields})
+		}
+	}
+}
+
+type bibParser struct {
+	src    string
+	pos    int
+	macros map[string]string
+}
+
+func (p *bibParser) space() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// ident reads a name: an entry type, field name or macro
+func (p *bibParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if !(c == '_' || c == '-' || c == ':' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// until reads up to the first of stops, or the end
+func (p *bibParser) until(stops ...string) string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		for _, stop := range stops {
+			if strings.HasPrefix(p.src[p.pos:], stop) {
+				return p.src[start:p.pos]
+			}
+		}
+		p.pos++
+	}
+	return p.src[start:]
+}
+
+// braced reads a {group} or (group) at pos, returning its inside
+func (p *bibParser) braced() string {
+	open := p.src[p.pos]
+	closer := byte('}')
+	if open == '(' {
+		closer = ')'
+	}
+	depth, start := 0, p.pos+1
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case open:
+			depth++
+		case closer:
+			if depth--; depth == 0 {
+				p.pos++
+				return p.src[start : p.pos-1]
+			}
+		case '\\': // An escaped brace does not nest
+			p.pos++
+		}
+	}
+	return p.src[start:]
+}
+
+// fields reads name = value pairs up to closer
+func (p *bibParser) fields(closer byte) map[string]string {
+	fields := map[string]string{}
+	for {
+		p.space()
+		if p.pos >= len(p.src) || p.src[p.pos] == closer || p.src[p.pos] == '@' {
+			if p.pos < len(p.src) && p.src[p.pos] == closer {
+				p.pos++
+			}
+			return fields
+		}
+		name := strings.ToLower(p.ident())
+		p.space()
+		if name == "" || p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			p.until(",", string(closer), "@") // Malformed; skip the pair
+			if p.pos < len(p.src) && p.src[p.pos] == ',' {
+				p.pos++
+			}
+			continue
+		}
+		p.pos++
+		fields[name] = p.value()
+		p.space()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+// value reads a field value: braced or quoted text, a number or a macro, joined by #
+func (p *bibParser) value() string {
+	var b strings.Builder
+	for {
+		p.space()
+		if p.pos >= len(p.src) {
+			return b.String()
+		}
+		switch c := p.src[p.pos]; {
+		case c == '{':
+			b.WriteString(p.braced())
+		case c == '"':
+			p.pos++
+			start, depth := p.pos, 0
+			for ; p.pos < len(p.src) && (p.src[p.pos] != '"' || depth > 0); p.pos++ {
+				switch p.src[p.pos] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				case '\\':
+					p.pos++
+				}
+			}
+			b.WriteString(p.src[start:min(p.pos, len(p.src))])
+			p.pos++
+		default:
+			name := p.ident()
+			if name == "" {
+				return b.String()
+			}
+			if macro, ok := p.macros[strings.ToLower(name)]; ok {
+				b.WriteString(macro)
+			} else if month, ok := bibMonths[strings.ToLower(name)]; ok {
+				b.WriteString(month)
+			} else {
+				b.WriteString(name)
+			}
+		}
+		p.space()
+		if p.pos >= len(p.src) || p.src[p.pos] != '#' {
+			return b.String()
+		}
+		p.pos++
+	}
+}
+
+// bibMonths are BibTeX's predefined month macros
+var bibMonths = map[string]string{
+	"jan": "January", "feb": "February", "mar": "March", "apr": "April", "may": "May", "jun": "June",
+	"jul": "July", "aug": "August", "sep": "September", "oct": "October", "nov": "November", "dec": "December",
+}
+
+// referenceParams maps a BibTeX entry to a reference. Authors keep BibTeX's
+// "Last, First and Last, First" form, which splitAuthors reads back for exports.
+func (e bibEntry) referenceParams() sqlc.CreateReferenceParams {
+	text := func(fields ...string) pgtype.Text {
+		for _, field := range fields {
+			if v := strings.TrimSpace(e.Fields[field]); v != "" {
+				return pgtype.Text{String: v, Valid: true}
+			}
+		}
+		return pgtype.Text{}
+	}
+	params := sqlc.CreateReferenceParams{
+		Title:    strings.TrimSpace(e.Fields["title"]),
+		Authors:  text("author", "editor"),
+		Journal:  text("journal", "journaltitle", "booktitle", "publisher", "school", "institution"),
+		Doi:      text("doi"),
+		Url:      text("url"),
+		Abstract: text("abstract"),
+	}
+	if params.Journal.Valid {
+		params.Journal.String = truncateRunes(params.Journal.String, 297)
+	}
+	if params.Doi.Valid {
+		params.Doi.String = truncateRunes(strings.TrimPrefix(strings.TrimPrefix(params.Doi.String, "https://doi.org/"), "doi:"), 97)
+	}
+	year := e.Fields["year"]
+	if year == "" && len(e.Fields["date"]) >= 4 { // biblatex
+		year = e.Fields["date"][:4]
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(year)); err == nil {
+		params.PublicationYear = pgtype.Int4{Int32: int32(n), Valid: true}
+	}
+	if params.Title == "" {
+		params.Title = e.Key
+	}
+	return params
+}
+
+// latexAccents composes a letter with the accent command before it, as in \'e or \"{o}
+var latexAccents = map[byte]map[byte]string{
+	'\'': {'a': "á", 'e': "é", 'i': "í", 'o': "ó", 'u': "ú", 'y': "ý", 'c': "ć", 'n': "ń", 's': "ś", 'z': "ź", 'A': "Á", 'E': "É", 'I': "Í", 'O': "Ó", 'U': "Ú", 'C': "Ć", 'S': "Ś"},
+	'`':  {'a': "à", 'e': "è", 'i': "ì", 'o': "ò", 'u': "ù", 'A': "À", 'E': "È", 'I': "Ì", 'O': "Ò", 'U': "Ù"},
+	'^':  {'a': "â", 'e': "ê", 'i': "î", 'o': "ô", 'u': "û", 'A': "Â", 'E': "Ê", 'I': "Î", 'O': "Ô", 'U': "Û"},
+	'"':  {'a': "ä", 'e': "ë", 'i': "ï", 'o': "ö", 'u': "ü", 'y': "ÿ", 'A': "Ä", 'E': "Ë", 'I': "Ï", 'O': "Ö", 'U': "Ü"},
+	'~':  {'a': "ã", 'n': "ñ", 'o': "õ", 'A': "Ã", 'N': "Ñ", 'O': "Õ"},
+	'c':  {'c': "ç", 's': "ş", 'C': "Ç", 'S': "Ş"},
+	'v':  {'c': "č", 's': "š", 'z': "ž", 'r': "ř", 'e': "ě", 'C': "Č", 'S': "Š", 'Z': "Ž"},
+}
+
+// latexSymbols are letter commands with a Unicode equivalent
+var latexSymbols = map[string]string{
+	"ss": "ß", "o": "ø", "O": "Ø", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ", "aa": "å", "AA": "Å",
+	"l": "ł", "L": "Ł", "i": "ı", "&": "&", "%": "%", "$": "$", "#": "#", "_": "_", "{": "{", "}": "}",
+	"textendash": "–", "textemdash": "—", "ldots": "…", "dots": "…", "LaTeX": "LaTeX", "TeX": "TeX",
+}
+
+// cleanLaTeX reduces LaTeX markup in a short text, such as a BibTeX field, to plain text:
+// accents are composed, grouping braces dropped and other commands replaced by their
+// argument
+func cleanLaTeX(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '{' || c == '}':
+		case c == '~':
+			b.WriteByte(' ')
+		case c == '-' && strings.HasPrefix(text[i:], "---"):
+			b.WriteString("—")
+			i += 2
+		case c == '-' && strings.HasPrefix(text[i:], "--"):
+			b.WriteString("–")
+			i++
+		case c == '\\' && i+1 < len(text):
+			i++
+			// \c and \v are only accents when no letter follows, unlike \cite or \vspace
+			if accents, ok := latexAccents[text[i]]; ok && (!isLetter(text[i]) || i+1 < len(text) && !isLetter(text[i+1])) {
+				j := i + 1
+				for j < len(text) && (text[j] == '{' || text[j] == ' ') {
+					j++
+				}
+				if j < len(text) {
+					if composed, ok := accents[text[j]]; ok {
+						b.WriteString(composed)
+						i = j
+						if i+1 < len(text) && text[i+1] == '}' {
+							i++
+						}
+						continue
+					}
+				}
+			}
+			j := i
+			for j < len(text) && isLetter(text[j]) {
+				j++
+			}
+			if j == i { // \& and the like
+				j = i + 1
+			}
+			name := text[i:j]
+			i = j - 1
+			if symbol, ok := latexSymbols[name]; ok {
+				b.WriteString(symbol)
+				if j < len(text) && text[j] == ' ' && isLetter(name[0]) { // The space ends the command
+					i++
+				}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
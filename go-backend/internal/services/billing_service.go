@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/billing"
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Subscription plans
+const (
+	PlanFree          = "free"
+	PlanPro           = "pro"
+	PlanInstitutional = "institutional"
+	PlanGuest         = "guest" // Trial guests, whatever billing says; not for sale
+)
+
+// Metered resources, counted per calendar month (UTC). Documents are counted on the quota
+// ledger, see QuotaService.
+const (
+	UsageAIGenerations = "ai_generations"
+)
+
+// PlanLimits caps what a plan's users can do; 0 means unlimited
+type PlanLimits struct {
+	MaxProjects           int // Projects owned at once
+	AIGenerationsPerMonth int
+}
+
+// BillingConfig holds the Stripe settings and plan limits
+type BillingConfig struct {
+	WebhookSecret   string
+	Prices          map[string]string // Plan name to Stripe price ID, for the paid plans
+	SuccessURL      string            // Where Checkout sends the user after paying
+	CancelURL       string            // Where Checkout sends the user when they back out
+	PortalReturnURL string            // Where the customer portal's back link goes
+	Limits          map[string]PlanLimits
+}
+
+var (
+	ErrQuotaExceeded      = errors.New("your plan's limit has been reached")
+	ErrPlanNotPurchasable = errors.New("this plan cannot be purchased")
+	ErrAlreadySubscribed  = errors.New("you already have a subscription; change plans in the billing portal")
+	ErrGuestAccount       = errors.New("register an account before subscribing")
+	ErrNoBillingAccount   = errors.New("you have no billing account yet; subscribe to a plan first")
+	ErrInvalidStripeEvent = errors.New("invalid stripe webhook")
+)
+
+// QuotaError is returned when an action would exceed the plan of the account it is billed to
+type QuotaError struct {
+	Plan     string
+	Resource string // "projects" or one of the Usage* resources
+	Limit    int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("the %s plan allows %d %s; upgrade to continue", e.Plan, e.Limit, e.resourceName())
+}
+
+func (e *QuotaError) Unwrap() error { return ErrQuotaExceeded }
+
+func (e *QuotaError) resourceName() string {
+	switch e.Resource {
+	case UsageAIGenerations:
+		return "AI generations per month"
+	}
+	return e.Resource
+}
+
+// subscriptionGrantsPlan lists the Stripe statuses that keep the paid plan.
+// past_due keeps it while Stripe retries the payment.
+var subscriptionGrantsPlan = map[string]bool{"active": true, "trialing": true, "past_due": true}
+
+// BillingService sells plans through Stripe and enforces their limits.
+// Without a Stripe client billing is disabled and nothing is limited, as suits self-hosted installs.
+type BillingService struct {
+	store  db.Store
+	stripe *billing.Client
+	cfg    BillingConfig
+	logger *applogger.AppLogger
+}
+
+func NewBillingService(store db.Store, stripe *billing.Client, cfg BillingConfig, logger *applogger.AppLogger) *BillingService {
+	return &BillingService{store: store, stripe: stripe, cfg: cfg, logger: logger}
+}
+
+// Enabled reports whether billing is configured. A nil BillingService is disabled.
+func (s *BillingService) Enabled() bool {
+	return s != nil && s.stripe != nil
+}
+
+// Plans lists every plan with its limits, cheapest first
+func (s *BillingService) Plans() []apimodels.PlanResponse {
+	plans := []string{PlanFree, PlanPro, PlanInstitutional}
+	resp := make([]apimodels.PlanResponse, len(plans))
+	for i, plan := range plans {
+		resp[i] = s.planResponse(plan)
+	}
+	return resp
+}
+
+func (s *BillingService) planResponse(plan string) apimodels.PlanResponse {
+	limits := s.cfg.Limits[plan]
+	return apimodels.PlanResponse{
+		Name:                  plan,
+		MaxProjects:           limits.MaxProjects,
+		AIGenerationsPerMonth: limits.AIGenerationsPerMonth,
+		Purchasable:           s.cfg.Prices[plan] != "",
+	}
+}
+
+// effectivePlan returns the plan the user currently pays for, falling back to free.
+// Guests are on the guest plan.
+func (s *BillingService) effectivePlan(ctx context.Context, userID uuid.UUID) (string, *sqlc.Subscription, error) {
+	if guest, err := isGuest(ctx, s.store, userID); err != nil || guest {
+		return PlanGuest, nil, err
+	}
+	sub, err := s.store.GetSubscription(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return PlanFree, nil, nil
+		}
+		return "", nil, fmt.Errorf("database error fetching subscription: %w", err)
+	}
+	if !subscriptionGrantsPlan[sub.Status] {
+		return PlanFree, &sub, nil
+	}
+	return sub.Plan, &sub, nil
+}
+
+// CurrentPlan returns the plan the user is on and, for paying subscribers, the end of the
+// current Stripe billing period. With billing disabled everyone but guests is on the
+// free plan.
+func (s *BillingService) CurrentPlan(ctx context.Context, userID uuid.UUID) (string, *time.Time, error) {
+	if !s.Enabled() {
+		if guest, err := isGuest(ctx, s.store, userID); err != nil || guest {
+			return PlanGuest, nil, err
+		}
+		return PlanFree, nil, nil
+	}
+	plan, sub, err := s.effectivePlan(ctx, userID)
+	if err != nil || sub == nil || plan == PlanFree || !sub.CurrentPeriodEnd.Valid {
+		return plan, nil, err
+	}
+	return plan, &sub.CurrentPeriodEnd.Time, nil
+}
+
+// Subscription returns the user's plan, its limits and this month's usage
+func (s *BillingService) Subscription(ctx context.Context, userID uuid.UUID) (apimodels.SubscriptionResponse, error) {
+	plan, sub, err := s.effectivePlan(ctx, userID)
+	if err != nil {
+		return apimodels.SubscriptionResponse{}, err
+	}
+	resp := apimodels.SubscriptionResponse{Plan: plan, Status: "none", Limits: s.planResponse(plan)}
+	if sub != nil {
+		resp.Status = sub.Status
+		resp.CancelAtPeriodEnd = sub.CancelAtPeriodEnd
+		if sub.CurrentPeriodEnd.Valid {
+			resp.CurrentPeriodEnd = &sub.CurrentPeriodEnd.Time
+		}
+	}
+
+	period := usagePeriod(time.Now())
+	resp.Usage.PeriodStart = period.Time
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+	if resp.Usage.Projects, err = s.store.CountOwnedProjects(ctx, pgUserID); err != nil {
+		return apimodels.SubscriptionResponse{}, fmt.Errorf("database error counting projects: %w", err)
+	}
+	usage, err := s.store.ListUsage(ctx, sqlc.ListUsageParams{UserID: pgUserID, PeriodStart: period})
+	if err != nil {
+		return apimodels.SubscriptionResponse{}, fmt.Errorf("database error fetching usage: %w", err)
+	}
+	for _, u := range usage {
+		if u.Resource == UsageAIGenerations {
+			resp.Usage.AIGenerations = int64(u.Used)
+		}
+	}
+	return resp, nil
+}
+
+// CreateCheckoutSession starts a Stripe Checkout for a paid plan and returns its URL.
+// The Stripe customer is created on the first checkout and reused afterwards.
+func (s *BillingService) CreateCheckoutSession(ctx context.Context, userID uuid.UUID, plan string) (string, error) {
+	price := s.cfg.Prices[plan]
+	if price == "" {
+		return "", ErrPlanNotPurchasable
+	}
+	current, sub, err := s.effectivePlan(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if current == PlanGuest {
+		return "", ErrGuestAccount
+	}
+	if current != PlanFree {
+		return "", ErrAlreadySubscribed
+	}
+
+	customerID := ""
+	if sub != nil {
+		customerID = sub.StripeCustomerID
+	} else {
+		user, err := s.store.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+		if err != nil {
+			return "", fmt.Errorf("database error fetching user: %w", err)
+		}
+		customer, err := s.stripe.CreateCustomer(ctx, user.Email, map[string]string{"user_id": userID.String()})
+		if err != nil {
+			return "", fmt.Errorf("could not create stripe customer: %w", err)
+		}
+		created, err := s.store.CreateBillingCustomer(ctx, sqlc.CreateBillingCustomerParams{
+			UserID:           pgtype.UUID{Bytes: userID, Valid: true},
+			StripeCustomerID: customer.ID,
+		})
+		if err != nil {
+			return "", fmt.Errorf("could not save stripe customer: %w", err)
+		}
+		customerID = created.StripeCustomerID
+	}
+
+	session, err := s.stripe.CreateCheckoutSession(ctx, billing.CheckoutParams{
+		CustomerID:        customerID,
+		PriceID:           price,
+		ClientReferenceID: userID.String(),
+		SuccessURL:        s.cfg.SuccessURL,
+		CancelURL:         s.cfg.CancelURL,
+		Metadata:          map[string]string{"user_id": userID.String(), "plan": plan},
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not create checkout session: %w", err)
+	}
+	s.logger.Info("Checkout session created", "userID", userID, "plan", plan, "sessionID", session.ID)
+	return session.URL, nil
+}
+
+// CreatePortalSession returns a link to Stripe's customer portal for managing the subscription
+func (s *BillingService) CreatePortalSession(ctx context.Context, userID uuid.UUID) (string, error) {
+	sub, err := s.store.GetSubscription(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return "", ErrNoBillingAccount
+		}
+		return "", fmt.Errorf("database error fetching subscription: %w", err)
+	}
+	session, err := s.stripe.CreatePortalSession(ctx, sub.StripeCustomerID, s.cfg.PortalReturnURL)
+	if err != nil {
+		return "", fmt.Errorf("could not create portal session: %w", err)
+	}
+	return session.URL, nil
+}
+
+// HandleStripeWebhook verifies and applies a Stripe event. Each event is applied once;
+// redeliveries of an event that was already handled succeed without doing anything.
+func (s *BillingService) HandleStripeWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := billing.ParseWebhook(payload, signature, s.cfg.WebhookSecret, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStripeEvent, err)
+	}
+
+	var update *sqlc.UpdateSubscriptionFromStripeParams
+	switch event.Type {
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		var obj struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(event.Data.Object, &obj); err != nil || obj.ID == "" {
+			return fmt.Errorf("%w: subscription event without a subscription", ErrInvalidStripeEvent)
+		}
+		// Events can arrive out of order, so the subscription's current state is fetched
+		sub, err := s.stripe.GetSubscription(ctx, obj.ID)
+		if err != nil {
+			return fmt.Errorf("could not fetch subscription %s: %w", obj.ID, err)
+		}
+		update = &sqlc.UpdateSubscriptionFromStripeParams{
+			StripeCustomerID:     sub.Customer,
+			StripeSubscriptionID: pgtype.Text{String: sub.ID, Valid: true},
+			Plan:                 s.planForPrice(sub.PriceID()),
+			Status:               sub.Status,
+			CurrentPeriodEnd:     pgtype.Timestamptz{Time: time.Unix(sub.CurrentPeriodEnd, 0), Valid: sub.CurrentPeriodEnd > 0},
+			CancelAtPeriodEnd:    sub.CancelAtPeriodEnd,
+		}
+	}
+
+	return s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		rows, err := q.RecordStripeEvent(ctx, sqlc.RecordStripeEventParams{ID: event.ID, Type: event.Type})
+		if err != nil {
+			return err
+		}
+		if rows == 0 || update == nil {
+			return nil // Already handled, or an event type we don't act on
+		}
+		sub, err := q.UpdateSubscriptionFromStripe(ctx, *update)
+		if err != nil {
+			if isNoRows(err) {
+				s.logger.Warn("Stripe subscription for an unknown customer", "customer", update.StripeCustomerID, "eventID", event.ID)
+				return nil
+			}
+			return err
+		}
+		s.logger.Info("Subscription updated", "userID", sub.UserID.Bytes, "plan", sub.Plan, "status", sub.Status, "eventID", event.ID)
+		return nil
+	})
+}
+
+// planForPrice maps a Stripe price back to the plan it sells
+func (s *BillingService) planForPrice(price string) string {
+	for plan, p := range s.cfg.Prices {
+		if p == price {
+			return plan
+		}
+	}
+	s.logger.Warn("Stripe price does not match any plan", "price", price)
+	return PlanFree
+}
+
+// CheckProjectQuota fails with a *QuotaError when the user owns as many projects as their
+// plan allows. Deleting a project frees its slot.
+func (s *BillingService) CheckProjectQuota(ctx context.Context, userID uuid.UUID) error {
+	if !s.Enabled() {
+		return nil
+	}
+	plan, _, err := s.effectivePlan(ctx, userID)
+	if err != nil {
+		return err
+	}
+	limit := s.cfg.Limits[plan].MaxProjects
+	if limit == 0 {
+		return nil
+	}
+	count, err := s.store.CountOwnedProjects(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return fmt.Errorf("database error counting projects: %w", err)
+	}
+	if count >= int64(limit) {
+		return &QuotaError{Plan: plan, Resource: "projects", Limit: limit}
+	}
+	return nil
+}
+
+// ReserveUsage counts one use of resource against the user's monthly limit, failing with a
+// *QuotaError once it is reached. The returned func gives the use back if the action fails.
+func (s *BillingService) ReserveUsage(ctx context.Context, userID uuid.UUID, resource string) (func(), error) {
+	noop := func() {}
+	if !s.Enabled() {
+		return noop, nil
+	}
+	plan, _, err := s.effectivePlan(ctx, userID)
+	if err != nil {
+		return noop, err
+	}
+	limit := s.usageLimit(plan, resource)
+	if limit == 0 {
+		return noop, nil
+	}
+
+	period := usagePeriod(time.Now())
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+	_, err = s.store.ReserveUsage(ctx, sqlc.ReserveUsageParams{UserID: pgUserID, PeriodStart: period, Resource: resource, UsageLimit: int32(limit)})
+	if err != nil {
+		if isNoRows(err) {
+			return noop, &QuotaError{Plan: plan, Resource: resource, Limit: limit}
+		}
+		return noop, fmt.Errorf("database error reserving usage: %w", err)
+	}
+	return func() {
+		err := s.store.ReleaseUsage(context.WithoutCancel(ctx), sqlc.ReleaseUsageParams{UserID: pgUserID, PeriodStart: period, Resource: resource})
+		if err != nil {
+			s.logger.Error("Failed to release usage", "userID", userID, "resource", resource, "error", err)
+		}
+	}, nil
+}
+
+// ReleaseUsage gives back one unit of resource reserved this month, for actions that
+// fail after the func returned by ReserveUsage is gone, such as a document generation job
+func (s *BillingService) ReleaseUsage(ctx context.Context, userID uuid.UUID, resource string) {
+	if !s.Enabled() {
+		return
+	}
+	plan, _, err := s.effectivePlan(ctx, userID)
+	if err == nil {
+		if s.usageLimit(plan, resource) == 0 {
+			return // Nothing was reserved
+		}
+		err = s.store.ReleaseUsage(ctx, sqlc.ReleaseUsageParams{
+			UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+			PeriodStart: usagePeriod(time.Now()),
+			Resource:    resource,
+		})
+	}
+	if err != nil {
+		s.logger.Error("Failed to release usage", "userID", userID, "resource", resource, "error", err)
+	}
+}
+
+// usageLimit is the plan's monthly limit for resource; 0 means unlimited
+func (s *BillingService) usageLimit(plan, resource string) int {
+	if resource == UsageAIGenerations {
+		return s.cfg.Limits[plan].AIGenerationsPerMonth
+	}
+	return 0
+}
+
+// usagePeriod returns the first day of t's month in UTC
+func usagePeriod(t time.Time) pgtype.Date {
+	t = t.UTC()
+	return pgtype.Date{Time: time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), Valid: true}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrChapterDraftNotFound = errors.New("no draft is saved for this chapter")
+	ErrDraftConflict        = errors.New("the chapter was saved by someone else after this draft's base version")
+)
+
+// DraftConflictError is returned when an autosave is based on a chapter version someone
+// else has saved over. The draft is saved regardless; Draft holds it so the client can
+// merge it with the chapter and save.
+type DraftConflictError struct {
+	Draft apimodels.ChapterDraftResponse
+}
+
+func (e *DraftConflictError) Error() string { return ErrDraftConflict.Error() }
+func (e *DraftConflictError) Unwrap() error { return ErrDraftConflict }
+
+// SaveChapterDraft merges an autosave into the user's draft of a chapter, leaving the
+// chapter itself alone. It is cheap enough to call on every debounced keystroke: one
+// upsert after the access check. Requires the edit role.
+func (s *ResearchService) SaveChapterDraft(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.UpdateChapterDraftRequest) (apimodels.ChapterDraftResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.ChapterDraftResponse{}, err
+	}
+	params := sqlc.UpsertChapterDraftParams{
+		ChapterID:   chapter.ID,
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		ProjectID:   chapter.ProjectID,
+		BaseVersion: req.BaseVersion,
+	}
+	if req.Title != nil {
+		params.Title = pgtype.Text{String: markdown.SanitizeLine(*req.Title), Valid: true}
+	}
+	if req.Content != nil {
+		params.Content = pgtype.Text{String: markdown.Sanitize(*req.Content), Valid: true}
+	}
+	draft, err := s.store.UpsertChapterDraft(ctx, params)
+	if err != nil {
+		return apimodels.ChapterDraftResponse{}, fmt.Errorf("could not save chapter draft: %w", err)
+	}
+	resp := chapterDraftResponse(chapter, draft)
+	if resp.Conflict {
+		s.logger.Info("Autosave based on a stale chapter version", "chapterID", chapterID, "userID", userID, "baseVersion", draft.BaseVersion, "currentVersion", chapter.Version)
+		return apimodels.ChapterDraftResponse{}, &DraftConflictError{Draft: resp}
+	}
+	return resp, nil
+}
+
+// GetChapterDraft returns the user's draft of a chapter. Requires the edit role.
+func (s *ResearchService) GetChapterDraft(ctx context.Context, projectID, chapterID, userID uuid.UUID) (apimodels.ChapterDraftResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.ChapterDraftResponse{}, err
+	}
+	draft, err := s.store.GetChapterDraft(ctx, sqlc.GetChapterDraftParams{ChapterID: chapter.ID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		if isNoRows(err) {
+			return apimodels.ChapterDraftResponse{}, ErrChapterDraftNotFound
+		}
+		return apimodels.ChapterDraftResponse{}, fmt.Errorf("database error fetching chapter draft: %w", err)
+	}
+	return chapterDraftResponse(chapter, draft), nil
+}
+
+// DiscardChapterDraft deletes the user's draft of a chapter. Requires the edit role.
+func (s *ResearchService) DiscardChapterDraft(ctx context.Context, projectID, chapterID, userID uuid.UUID) error {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return err
+	}
+	if err := s.store.DeleteChapterDraft(ctx, sqlc.DeleteChapterDraftParams{ChapterID: chapter.ID, UserID: pgtype.UUID{Bytes: userID, Valid: true}}); err != nil {
+		return fmt.Errorf("could not delete chapter draft: %w", err)
+	}
+	return nil
+}
+
+// clearChapterDraft drops the user's draft once they have saved the chapter. It is best
+// effort: a leftover draft is only offered again.
+func (s *ResearchService) clearChapterDraft(ctx context.Context, chapterID, userID uuid.UUID) {
+	err := s.store.DeleteChapterDraft(ctx, sqlc.DeleteChapterDraftParams{
+		ChapterID: pgtype.UUID{Bytes: chapterID, Valid: true},
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Warn("Failed to clear chapter draft", "chapterID", chapterID, "userID", userID, "error", err)
+	}
+}
+
+func chapterDraftResponse(chapter sqlc.Chapter, draft sqlc.ChapterDraft) apimodels.ChapterDraftResponse {
+	resp := apimodels.ChapterDraftResponse{
+		ChapterID:      chapter.ID.Bytes,
+		Title:          chapter.Title,
+		Content:        chapter.Content.String,
+		BaseVersion:    draft.BaseVersion,
+		ChapterVersion: chapter.Version,
+		Conflict:       draft.BaseVersion != chapter.Version,
+		UpdatedAt:      draft.UpdatedAt.Time,
+	}
+	if draft.Title.Valid {
+		resp.Title = draft.Title.String
+	}
+	if draft.Content.Valid {
+		resp.Content = draft.Content.String
+	}
+	return resp
+}
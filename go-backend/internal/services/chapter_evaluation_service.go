@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// evaluationMaxScore is the top of the scale rubric criteria are scored on, from 1
+const evaluationMaxScore = 5
+
+var (
+	ErrChapterEvaluationNotFound = errors.New("chapter evaluation not found")
+	ErrInvalidRubric             = errors.New("invalid rubric")
+	ErrNothingToEvaluate         = errors.New("the chapter has no content to evaluate")
+)
+
+// defaultRubric is what chapters are evaluated against when no rubric is given
+var defaultRubric = []apimodels.RubricCriterion{
+	{Key: "argument_clarity", Name: "Argument clarity", Weight: 1,
+		Description: "States its aims and claims plainly and builds each point on evidence and the points before it"},
+	{Key: "citation_density", Name: "Citation density", Weight: 1,
+		Description: "Supports claims with citations where the field expects them, without padding or unsupported assertions"},
+	{Key: "structure", Name: "Structure", Weight: 1,
+		Description: "Is organised into sections that follow a logical order, with paragraphs that each make one point and transitions between them"},
+	{Key: "synthesis_originality", Name: "Originality of synthesis", Weight: 1,
+		Description: "Relates sources to each other and to the research question rather than summarising them one by one"},
+}
+
+// EvaluateChapter has the AI score the current version of a chapter against a rubric,
+// the default one unless the request gives its own, and keeps the evaluation with the
+// version. Advisors can evaluate the chapters of the projects they advise, so this
+// requires the comment role.
+func (s *ResearchService) EvaluateChapter(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.EvaluateChapterRequest) (apimodels.ChapterEvaluationResponse, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	rubric, err := normalizeRubric(req.Rubric)
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, err
+	}
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleComment)
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, err
+	}
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return apimodels.ChapterEvaluationResponse{}, ErrChapterNotFound
+		}
+		return apimodels.ChapterEvaluationResponse{}, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	if strings.TrimSpace(chapter.Content.String) == "" {
+		return apimodels.ChapterEvaluationResponse{}, ErrNothingToEvaluate
+	}
+
+	ctx = s.withGenerationLog(ctx, GenerationKindChapterEvaluation, userID, &projectID, &chapterID)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	scores, summary, err := s.aiService.EvaluateChapter(ctx, ChapterEvaluationInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       project.Language,
+		ChapterType:    chapter.Type,
+		ChapterTitle:   chapter.Title,
+		Content:        chapter.Content.String,
+		Rubric:         rubric,
+		MaxScore:       evaluationMaxScore,
+	})
+	if err != nil {
+		s.logger.Error("AI chapter evaluation failed", "chapterID", chapterID, "error", err)
+		return apimodels.ChapterEvaluationResponse{}, fmt.Errorf("AI evaluation failed: %w", err)
+	}
+	criteria := matchCriterionScores(rubric, scores)
+	if len(criteria) == 0 {
+		return apimodels.ChapterEvaluationResponse{}, errors.New("AI evaluation failed: no criterion was scored")
+	}
+
+	encodedRubric, err := json.Marshal(rubric)
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, err
+	}
+	encodedCriteria, err := json.Marshal(criteria)
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, err
+	}
+	evaluation, err := s.store.CreateChapterEvaluation(ctx, sqlc.CreateChapterEvaluationParams{
+		ProjectID:      chapter.ProjectID,
+		ChapterID:      chapter.ID,
+		ChapterVersion: chapter.Version,
+		Rubric:         encodedRubric,
+		Criteria:       encodedCriteria,
+		Summary:        strings.TrimSpace(summary),
+		EvaluatedBy:    pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, fmt.Errorf("could not save chapter evaluation: %w", err)
+	}
+	s.logger.Info("Chapter evaluated", "chapterID", chapterID, "version", chapter.Version, "criteria", len(criteria), "userID", userID)
+	return chapterEvaluationResponse(evaluation), nil
+}
+
+// ListChapterEvaluations returns a chapter's evaluations, newest first. Requires read access.
+func (s *ResearchService) ListChapterEvaluations(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]apimodels.ChapterEvaluationResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	evaluations, err := s.store.ListChapterEvaluations(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching chapter evaluations: %w", err)
+	}
+	resp := make([]apimodels.ChapterEvaluationResponse, len(evaluations))
+	for i, e := range evaluations {
+		resp[i] = chapterEvaluationResponse(e)
+	}
+	return resp, nil
+}
+
+// GetChapterEvaluation returns one evaluation of a chapter. Requires read access.
+func (s *ResearchService) GetChapterEvaluation(ctx context.Context, projectID, chapterID, evaluationID, userID uuid.UUID) (apimodels.ChapterEvaluationResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.ChapterEvaluationResponse{}, err
+	}
+	evaluation, err := s.store.GetChapterEvaluation(ctx, sqlc.GetChapterEvaluationParams{
+		ID:        pgtype.UUID{Bytes: evaluationID, Valid: true},
+		ChapterID: chapter.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return apimodels.ChapterEvaluationResponse{}, ErrChapterEvaluationNotFound
+		}
+		return apimodels.ChapterEvaluationResponse{}, fmt.Errorf("database error fetching chapter evaluation: %w", err)
+	}
+	return chapterEvaluationResponse(evaluation), nil
+}
+
+// normalizeRubric fills in the default rubric and weights and rejects repeated keys
+func normalizeRubric(rubric []apimodels.RubricCriterion) ([]apimodels.RubricCriterion, error) {
+	if len(rubric) == 0 {
+		return defaultRubric, nil
+	}
+	seen := make(map[string]bool, len(rubric))
+	normalized := make([]apimodels.RubricCriterion, 0, len(rubric))
+	for _, c := range rubric {
+		c.Key = strings.TrimSpace(c.Key)
+		c.Name = strings.TrimSpace(c.Name)
+		c.Description = strings.TrimSpace(c.Description)
+		if c.Key == "" || c.Name == "" {
+			return nil, fmt.Errorf("%w: every criterion needs a key and a name", ErrInvalidRubric)
+		}
+		if seen[c.Key] {
+			return nil, fmt.Errorf("%w: criterion key %q is repeated", ErrInvalidRubric, c.Key)
+		}
+		seen[c.Key] = true
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+		normalized = append(normalized, c)
+	}
+	return normalized, nil
+}
+
+// matchCriterionScores puts the AI's scores in rubric order, dropping criteria it made
+// up and clamping scores to the scale. Criteria it skipped are left out.
+func matchCriterionScores(rubric []apimodels.RubricCriterion, scores []apimodels.CriterionScore) []apimodels.CriterionScore {
+	byKey := make(map[string]apimodels.CriterionScore, len(scores))
+	for _, score := range scores {
+		if _, ok := byKey[score.Key]; !ok {
+			byKey[score.Key] = score
+		}
+	}
+	var criteria []apimodels.CriterionScore
+	for _, c := range rubric {
+		score, ok := byKey[c.Key]
+		if !ok || score.Score == 0 {
+			continue
+		}
+		score.Score = max(1, min(evaluationMaxScore, score.Score))
+		score.Justification = strings.TrimSpace(score.Justification)
+		if score.Suggestions == nil {
+			score.Suggestions = []string{}
+		}
+		criteria = append(criteria, score)
+	}
+	return criteria
+}
+
+func chapterEvaluationResponse(e sqlc.ChapterEvaluation) apimodels.ChapterEvaluationResponse {
+	resp := apimodels.ChapterEvaluationResponse{
+		ID:             e.ID.Bytes,
+		ChapterID:      e.ChapterID.Bytes,
+		ChapterVersion: e.ChapterVersion,
+		Rubric:         []apimodels.RubricCriterion{},
+		Criteria:       []apimodels.CriterionScore{},
+		MaxScore:       evaluationMaxScore,
+		Summary:        e.Summary,
+		CreatedAt:      e.CreatedAt.Time,
+	}
+	if e.EvaluatedBy.Valid {
+		evaluatedBy := uuid.UUID(e.EvaluatedBy.Bytes)
+		resp.EvaluatedBy = &evaluatedBy
+	}
+	// Both are written by the service from these types
+	_ = json.Unmarshal(e.Rubric, &resp.Rubric)
+	_ = json.Unmarshal(e.Criteria, &resp.Criteria)
+
+	weights := make(map[string]float64, len(resp.Rubric))
+	for _, c := range resp.Rubric {
+		weights[c.Key] = c.Weight
+	}
+	var weighted, total float64
+	for _, score := range resp.Criteria {
+		weighted += score.Score * weights[score.Key]
+		total += weights[score.Key]
+	}
+	if total > 0 {
+		resp.OverallScore = round2(weighted / total)
+	}
+	return resp
+}
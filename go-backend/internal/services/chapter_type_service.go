@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrUnknownChapterType     = errors.New("unknown chapter type for this project")
+	ErrChapterTypeNotFound    = errors.New("chapter type not found")
+	ErrChapterTypeExists      = errors.New("a chapter type with this key already exists")
+	ErrChapterTypeInUse       = errors.New("chapters still have this type")
+	ErrInvalidChapterTypeKey  = errors.New("chapter type keys are lowercase letters, digits and underscores, starting with a letter")
+	ErrReservedChapterTypeKey = errors.New("this chapter type key is reserved")
+)
+
+// chapterTypeKeyPattern matches the keys the chapter_types table accepts
+var chapterTypeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// projectChapterType loads a type chapters of the project may have: a built-in one, one
+// the project's organization added, or one added for the project itself
+func (s *ResearchService) projectChapterType(ctx context.Context, project sqlc.ResearchProject, chapterType string) (sqlc.ChapterType, error) {
+	t, err := s.store.GetChapterType(ctx, sqlc.GetChapterTypeParams{
+		Key:            chapterType,
+		OrganizationID: project.OrganizationID,
+		ProjectID:      project.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterType{}, fmt.Errorf("%w: %q", ErrUnknownChapterType, chapterType)
+		}
+		return sqlc.ChapterType{}, fmt.Errorf("database error fetching chapter type: %w", err)
+	}
+	return t, nil
+}
+
+// ListProjectChapterTypes returns the chapter types the project's chapters can have
+func (s *ResearchService) ListProjectChapterTypes(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.ChapterType, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	types, err := s.store.ListChapterTypes(ctx, sqlc.ListChapterTypesParams{
+		OrganizationID: project.OrganizationID,
+		ProjectID:      project.ID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list chapter types", "projectID", projectID, "error", err)
+		return nil, fmt.Errorf("database error fetching chapter types: %w", err)
+	}
+	return types, nil
+}
+
+// CreateProjectChapterType adds a chapter type for the project alone, such as a
+// theoretical framework or a case study, which editors can then add a chapter of
+func (s *ResearchService) CreateProjectChapterType(ctx context.Context, projectID, userID uuid.UUID, req apimodels.CreateChapterTypeRequest) (sqlc.ChapterType, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ChapterType{}, err
+	}
+	created, err := createChapterType(ctx, s.store, project.OrganizationID, project.ID, userID, req)
+	if err != nil {
+		if !isChapterTypeInputError(err) {
+			s.logger.Error("Failed to create chapter type", "projectID", projectID, "key", req.Key, "error", err)
+		}
+		return sqlc.ChapterType{}, err
+	}
+	s.logger.Info("Chapter type created", "projectID", projectID, "key", created.Key, "userID", userID)
+	return created, nil
+}
+
+// DeleteProjectChapterType removes a type added for the project, which none of its
+// chapters may still have
+func (s *ResearchService) DeleteProjectChapterType(ctx context.Context, projectID, userID uuid.UUID, key string) error {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return err
+	}
+	t, err := s.projectChapterType(ctx, project, key)
+	if errors.Is(err, ErrUnknownChapterType) || err == nil && !t.ProjectID.Valid {
+		return ErrChapterTypeNotFound // Built-in and organization types aren't the project's to remove
+	}
+	if err != nil {
+		return err
+	}
+	inUse, err := s.store.ProjectChapterTypeInUse(ctx, sqlc.ProjectChapterTypeInUseParams{ProjectID: project.ID, Type: key})
+	if err != nil {
+		return fmt.Errorf("database error checking chapter type use: %w", err)
+	}
+	if inUse {
+		return ErrChapterTypeInUse
+	}
+	if err := s.store.DeleteProjectChapterType(ctx, sqlc.DeleteProjectChapterTypeParams{ProjectID: project.ID, Key: key}); err != nil {
+		s.logger.Error("Failed to delete chapter type", "projectID", projectID, "key", key, "error", err)
+		return fmt.Errorf("could not delete chapter type: %w", err)
+	}
+	s.logger.Info("Chapter type deleted", "projectID", projectID, "key", key, "userID", userID)
+	return nil
+}
+
+// createChapterType adds an organization or project chapter type. Its key may not be
+// that of a type the organization or project can already use, nor executive_summary,
+// which the service manages.
+func createChapterType(ctx context.Context, store db.Store, orgID, projectID pgtype.UUID, userID uuid.UUID, req apimodels.CreateChapterTypeRequest) (sqlc.ChapterType, error) {
+	if !chapterTypeKeyPattern.MatchString(req.Key) {
+		return sqlc.ChapterType{}, ErrInvalidChapterTypeKey
+	}
+	if req.Key == ChapterTypeExecutiveSummary {
+		return sqlc.ChapterType{}, ErrReservedChapterTypeKey
+	}
+	label := markdown.SanitizeLine(req.Label)
+	if label == "" {
+		return sqlc.ChapterType{}, ErrEmptyAfterSanitizing
+	}
+
+	var created sqlc.ChapterType
+	err := store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		_, err := q.GetChapterType(ctx, sqlc.GetChapterTypeParams{Key: req.Key, OrganizationID: orgID, ProjectID: projectID})
+		if err == nil {
+			return ErrChapterTypeExists
+		}
+		if !isNoRows(err) {
+			return err
+		}
+		position := int32(0)
+		if req.Position != nil {
+			position = *req.Position
+		} else {
+			existing, err := q.ListChapterTypes(ctx, sqlc.ListChapterTypesParams{OrganizationID: orgID, ProjectID: projectID})
+			if err != nil {
+				return err
+			}
+			for _, t := range existing {
+				position = max(position, t.Position+1)
+			}
+		}
+		created, err = q.CreateChapterType(ctx, sqlc.CreateChapterTypeParams{
+			OrganizationID: orgID,
+			ProjectID:      projectID,
+			Key:            req.Key,
+			Label:          label,
+			Description:    markdown.SanitizeLine(req.Description),
+			Position:       position,
+			CreatedBy:      pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, ErrChapterTypeExists):
+			return sqlc.ChapterType{}, ErrChapterTypeExists
+		case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation: // Added since the check
+			return sqlc.ChapterType{}, ErrChapterTypeExists
+		}
+		return sqlc.ChapterType{}, fmt.Errorf("could not create chapter type: %w", err)
+	}
+	return created, nil
+}
+
+// isChapterTypeInputError reports whether createChapterType rejected the request itself
+func isChapterTypeInputError(err error) bool {
+	return errors.Is(err, ErrInvalidChapterTypeKey) || errors.Is(err, ErrReservedChapterTypeKey) ||
+		errors.Is(err, ErrEmptyAfterSanitizing) || errors.Is(err, ErrChapterTypeExists)
+}
+
+// ListChapterTypes returns the built-in chapter types and the organization's own
+func (s *OrganizationService) ListChapterTypes(ctx context.Context, orgID, userID uuid.UUID) ([]sqlc.ChapterType, error) {
+	if _, err := s.membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	types, err := s.store.ListChapterTypes(ctx, sqlc.ListChapterTypesParams{OrganizationID: pgtype.UUID{Bytes: orgID, Valid: true}})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching chapter types: %w", err)
+	}
+	return types, nil
+}
+
+// CreateChapterType adds a chapter type for the organization's projects
+func (s *OrganizationService) CreateChapterType(ctx context.Context, orgID, userID uuid.UUID, req apimodels.CreateChapterTypeRequest) (sqlc.ChapterType, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return sqlc.ChapterType{}, err
+	}
+	created, err := createChapterType(ctx, s.store, pgtype.UUID{Bytes: orgID, Valid: true}, pgtype.UUID{}, userID, req)
+	if err != nil {
+		if !isChapterTypeInputError(err) {
+			s.logger.Error("Failed to create chapter type", "organizationID", orgID, "key", req.Key, "error", err)
+		}
+		return sqlc.ChapterType{}, err
+	}
+	s.logger.Info("Chapter type created", "organizationID", orgID, "key", created.Key, "userID", userID)
+	return created, nil
+}
+
+// UpdateChapterType edits one of the organization's chapter types. Built-in types
+// can't be edited; the key can't change, since chapters refer to it.
+func (s *OrganizationService) UpdateChapterType(ctx context.Context, orgID, userID uuid.UUID, key string, req apimodels.UpdateChapterTypeRequest) (sqlc.ChapterType, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return sqlc.ChapterType{}, err
+	}
+	pgOrgID := pgtype.UUID{Bytes: orgID, Valid: true}
+	current, err := s.organizationChapterType(ctx, pgOrgID, key)
+	if err != nil {
+		return sqlc.ChapterType{}, err
+	}
+	params := sqlc.UpdateChapterTypeParams{
+		OrganizationID: pgOrgID,
+		Key:            key,
+		Label:          current.Label,
+		Description:    current.Description,
+		Position:       current.Position,
+	}
+	if req.Label != nil {
+		if params.Label = markdown.SanitizeLine(*req.Label); params.Label == "" {
+			return sqlc.ChapterType{}, ErrEmptyAfterSanitizing
+		}
+	}
+	if req.Description != nil {
+		params.Description = markdown.SanitizeLine(*req.Description)
+	}
+	if req.Position != nil {
+		params.Position = *req.Position
+	}
+	updated, err := s.store.UpdateChapterType(ctx, params)
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterType{}, ErrChapterTypeNotFound
+		}
+		s.logger.Error("Failed to update chapter type", "organizationID", orgID, "key", key, "error", err)
+		return sqlc.ChapterType{}, fmt.Errorf("could not update chapter type: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteChapterType removes one of the organization's chapter types, which no chapter
+// of its projects may still have
+func (s *OrganizationService) DeleteChapterType(ctx context.Context, orgID, userID uuid.UUID, key string) error {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return err
+	}
+	pgOrgID := pgtype.UUID{Bytes: orgID, Valid: true}
+	if _, err := s.organizationChapterType(ctx, pgOrgID, key); err != nil {
+		return err
+	}
+	inUse, err := s.store.ChapterTypeInUse(ctx, sqlc.ChapterTypeInUseParams{OrganizationID: pgOrgID, Type: key})
+	if err != nil {
+		return fmt.Errorf("database error checking chapter type use: %w", err)
+	}
+	if inUse {
+		return ErrChapterTypeInUse
+	}
+	if err := s.store.DeleteChapterType(ctx, sqlc.DeleteChapterTypeParams{OrganizationID: pgOrgID, Key: key}); err != nil {
+		s.logger.Error("Failed to delete chapter type", "organizationID", orgID, "key", key, "error", err)
+		return fmt.Errorf("could not delete chapter type: %w", err)
+	}
+	s.logger.Info("Chapter type deleted", "organizationID", orgID, "key", key, "userID", userID)
+	return nil
+}
+
+// organizationChapterType loads a type the organization added; built-in types are
+// reported as not found, as they aren't the organization's to change
+func (s *OrganizationService) organizationChapterType(ctx context.Context, orgID pgtype.UUID, key string) (sqlc.ChapterType, error) {
+	t, err := s.store.GetChapterType(ctx, sqlc.GetChapterTypeParams{Key: key, OrganizationID: orgID})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterType{}, ErrChapterTypeNotFound
+		}
+		return sqlc.ChapterType{}, fmt.Errorf("database error fetching chapter type: %w", err)
+	}
+	if !t.OrganizationID.Valid {
+		return sqlc.ChapterType{}, ErrChapterTypeNotFound
+	}
+	return t, nil
+}
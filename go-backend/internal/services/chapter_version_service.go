@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Diff granularities: whole lines, or words within the lines that changed
+const (
+	DiffLines = "line"
+	DiffWords = "word"
+)
+
+// Diff operations
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffCells bounds the table of one longest-common-subsequence comparison. A changed
+// block larger than this, such as a regenerated chapter against its predecessor, is
+// reported as deleted and inserted whole.
+const maxDiffCells = 4_000_000
+
+var (
+	ErrChapterVersionNotFound = errors.New("chapter version not found")
+	ErrInvalidDiffGranularity = errors.New("granularity must be line or word")
+)
+
+// diffWordPattern splits text into words and the whitespace between them
+var diffWordPattern = regexp.MustCompile(`\s+|\S+`)
+
+// ListChapterVersions returns a chapter's saved versions, newest first. Requires read access.
+func (s *ResearchService) ListChapterVersions(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]apimodels.ChapterVersionResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.store.ListChapterVersions(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching chapter versions: %w", err)
+	}
+	resp := make([]apimodels.ChapterVersionResponse, len(versions))
+	for i, v := range versions {
+		resp[i] = apimodels.ChapterVersionResponse{Version: v.Version, Title: v.Title, WordCount: v.WordCount, Status: v.Status, CreatedAt: v.CreatedAt.Time}
+	}
+	return resp, nil
+}
+
+// DiffChapterVersions compares two saved versions of a chapter, by line or by word.
+// Requires read access.
+func (s *ResearchService) DiffChapterVersions(ctx context.Context, projectID, chapterID, userID uuid.UUID, from, to int32, granularity string) (apimodels.ChapterDiffResponse, error) {
+	if granularity != DiffLines && granularity != DiffWords {
+		return apimodels.ChapterDiffResponse{}, ErrInvalidDiffGranularity
+	}
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.ChapterDiffResponse{}, err
+	}
+	a, err := s.chapterVersion(ctx, chapter, from)
+	if err != nil {
+		return apimodels.ChapterDiffResponse{}, err
+	}
+	b, err := s.chapterVersion(ctx, chapter, to)
+	if err != nil {
+		return apimodels.ChapterDiffResponse{}, err
+	}
+
+	edits := diffTokens(strings.SplitAfter(a.Content, "\n"), strings.SplitAfter(b.Content, "\n"))
+	if granularity == DiffWords {
+		edits = refineWords(edits)
+	}
+	resp := apimodels.ChapterDiffResponse{
+		ChapterID:   chapter.ID.Bytes,
+		From:        chapterVersionResponse(a),
+		To:          chapterVersionResponse(b),
+		Granularity: granularity,
+		Changes:     []apimodels.ChapterDiffChange{},
+	}
+	for _, e := range edits {
+		if e.token == "" {
+			continue // SplitAfter leaves an empty last line
+		}
+		counted := granularity == DiffLines || strings.TrimSpace(e.token) != ""
+		switch {
+		case e.op == DiffInsert && counted:
+			resp.Added++
+		case e.op == DiffDelete && counted:
+			resp.Removed++
+		}
+		if n := len(resp.Changes); n > 0 && resp.Changes[n-1].Op == e.op {
+			resp.Changes[n-1].Text += e.token
+		} else {
+			resp.Changes = append(resp.Changes, apimodels.ChapterDiffChange{Op: e.op, Text: e.token})
+		}
+	}
+	return resp, nil
+}
+
+func (s *ResearchService) chapterVersion(ctx context.Context, chapter sqlc.Chapter, version int32) (sqlc.ChapterVersion, error) {
+	v, err := s.store.GetChapterVersion(ctx, sqlc.GetChapterVersionParams{ChapterID: chapter.ID, Version: version})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterVersion{}, fmt.Errorf("%w: %d", ErrChapterVersionNotFound, version)
+		}
+		return sqlc.ChapterVersion{}, fmt.Errorf("database error fetching chapter version: %w", err)
+	}
+	return v, nil
+}
+
+func chapterVersionResponse(v sqlc.ChapterVersion) apimodels.ChapterVersionResponse {
+	return apimodels.ChapterVersionResponse{Version: v.Version, Title: v.Title, WordCount: v.WordCount, Status: v.Status, CreatedAt: v.CreatedAt.Time}
+}
+
+// diffEdit keeps, inserts or deletes one token
+type diffEdit struct {
+	op    string
+	token string
+}
+
+// diffTokens returns the edits turning a into b, deletions before insertions where a
+// block was replaced
+func diffTokens(a, b []string) []diffEdit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for _, t := range a[:prefix] {
+		edits = append(edits, diffEdit{DiffEqual, t})
+	}
+	edits = append(edits, lcsEdits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, t := range a[len(a)-suffix:] {
+		edits = append(edits, diffEdit{DiffEqual, t})
+	}
+	return edits
+}
+
+// lcsEdits diffs a and b by their longest common subsequence
+func lcsEdits(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	var edits []diffEdit
+	if n == 0 || m == 0 || n*m > maxDiffCells {
+		for _, t := range a {
+			edits = append(edits, diffEdit{DiffDelete, t})
+		}
+		for _, t := range b {
+			edits = append(edits, diffEdit{DiffInsert, t})
+		}
+		return edits
+	}
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, diffEdit{DiffEqual, a[i]})
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			edits = append(edits, diffEdit{DiffDelete, a[i]})
+			i++
+		default:
+			edits = append(edits, diffEdit{DiffInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = append(edits, diffEdit{DiffDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		edits = append(edits, diffEdit{DiffInsert, b[j]})
+	}
+	return edits
+}
+
+// refineWords re-diffs each block of replaced lines word by word, so a sentence with one
+// word changed shows that word rather than the whole line
+func refineWords(lines []diffEdit) []diffEdit {
+	var edits []diffEdit
+	var deleted, inserted strings.Builder
+	flush := func() {
+		edits = append(edits, diffTokens(diffWordPattern.FindAllString(deleted.String(), -1), diffWordPattern.FindAllString(inserted.String(), -1))...)
+		deleted.Reset()
+		inserted.Reset()
+	}
+	for _, e := range lines {
+		switch e.op {
+		case DiffDelete:
+			deleted.WriteString(e.token)
+		case DiffInsert:
+			inserted.WriteString(e.token)
+		default:
+			flush()
+			edits = append(edits, e)
+		}
+	}
+	flush()
+	return edits
+}
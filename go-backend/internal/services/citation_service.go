@@ -0,0 +1,462 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// citationGroup is a parenthesis that may hold citations, several separated by semicolons
+	citationGroup = regexp.MustCompile(`\(([^()]{4,400})\)`)
+	// citationPart is one citation within a group: authors, a comma, the year with an
+	// optional a/b suffix, and an optional page locator. The Arabic comma is accepted.
+	citationPart = regexp.MustCompile(`^([\p{Lu}\p{Lo}][^()]*?)\s*[,،]?\s+(\d{4})([a-z])?(?:\s*[,،:]\s*(?:pp?\.\s*)?\d+(?:\s*[-–]\s*\d+)?)?$`)
+	// citationLeadIns are words that may precede the first author inside the parenthesis
+	citationLeadIns = []string{"e.g.,", "e.g.", "see also", "see", "cf."}
+)
+
+// parsedCitation is an (Author, Year) marker found in chapter content
+type parsedCitation struct {
+	Marker   string
+	Author   string
+	Year     int
+	Suffix   string // a, b... distinguishing works by the same authors in one year
+	Position int    // Byte offset of the marker in the content
+}
+
+// ChapterCitations returns a chapter's in-text citations in reading order, each with the
+// reference it cites when one in the project matches
+func (s *ResearchService) ChapterCitations(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]apimodels.CitationResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return nil, ErrChapterNotFound
+		}
+		return nil, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	citations, err := s.chapterCitations(ctx, chapter)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.citedReferences(ctx, chapter.ProjectID, citations)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]apimodels.CitationResponse, len(citations))
+	for i, c := range citations {
+		resp[i] = apimodels.CitationResponse{
+			ID:       c.ID.Bytes,
+			Marker:   c.Marker,
+			Author:   c.Author,
+			Year:     c.Year,
+			Position: c.Position,
+		}
+		if ref, ok := refs[c.ReferenceID.Bytes]; ok && c.ReferenceID.Valid {
+			r := apimodels.ToReferenceResponse(ref)
+			resp[i].Reference = &r
+		}
+	}
+	return resp, nil
+}
+
+// chapterCitations returns the chapter's citation links, rebuilding them from the content
+// when it has changed since they were parsed or a marker is still unresolved, as a
+// reference added since may now match it
+func (s *ResearchService) chapterCitations(ctx context.Context, chapter sqlc.Chapter) ([]sqlc.ChapterCitation, error) {
+	citations, err := s.store.ListChapterCitations(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching citations: %w", err)
+	}
+	stale := len(citations) == 0
+	for _, c := range citations {
+		if c.ChapterVersion != chapter.Version || !c.ReferenceID.Valid {
+			stale = true
+			break
+		}
+	}
+	if !stale {
+		return citations, nil
+	}
+	parsed := parseCitations(chapter.Content.String)
+	if len(parsed) == 0 && len(citations) == 0 {
+		return citations, nil
+	}
+
+	refs, err := s.store.GetReferencesByProjectID(ctx, chapter.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching references: %w", err)
+	}
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteChapterCitations(ctx, chapter.ID); err != nil {
+			return fmt.Errorf("could not clear citations: %w", err)
+		}
+		for _, c := range parsed {
+			err := q.CreateChapterCitation(ctx, sqlc.CreateChapterCitationParams{
+				ChapterID:      chapter.ID,
+				ProjectID:      chapter.ProjectID,
+				ReferenceID:    resolveCitation(c, refs),
+				Marker:         c.Marker,
+				Author:         c.Author,
+				Year:           int32(c.Year),
+				Position:       int32(c.Position),
+				ChapterVersion: chapter.Version,
+			})
+			if err != nil {
+				return fmt.Errorf("could not save citation: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	citations, err = s.store.ListChapterCitations(db.WithPrimary(ctx), chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching citations: %w", err)
+	}
+	return citations, nil
+}
+
+// citedReferences loads the references the citations resolved to, including any since
+// moved to the trash, keyed by ID
+func (s *ResearchService) citedReferences(ctx context.Context, projectID pgtype.UUID, citations []sqlc.ChapterCitation) (map[uuid.UUID]sqlc.Reference, error) {
+	var ids []pgtype.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, c := range citations {
+		if c.ReferenceID.Valid && !seen[c.ReferenceID.Bytes] {
+			seen[c.ReferenceID.Bytes] = true
+			ids = append(ids, c.ReferenceID)
+		}
+	}
+	refs := make(map[uuid.UUID]sqlc.Reference, len(ids))
+	if len(ids) == 0 {
+		return refs, nil
+	}
+	rows, err := s.store.GetProjectReferencesByIDs(ctx, sqlc.GetProjectReferencesByIDsParams{ProjectID: projectID, Ids: ids})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching cited references: %w", err)
+	}
+	for _, ref := range rows {
+		refs[ref.ID.Bytes] = ref
+	}
+	return refs, nil
+}
+
+// parseCitations finds the parenthetical (Author, Year) citations in content, splitting
+// groups such as (Smith, 2019; Lee & Park, 2021) into one citation each
+func parseCitations(content string) []parsedCitation {
+	var found []parsedCitation
+	for _, m := range citationGroup.FindAllStringSubmatchIndex(content, -1) {
+		offset := m[2]
+		for _, part := range strings.Split(content[m[2]:m[3]], ";") {
+			start := offset + len(part) - len(strings.TrimLeftFunc(part, unicode.IsSpace))
+			offset += len(part) + 1
+			marker := strings.TrimSpace(part)
+			text := marker
+			for _, lead := range citationLeadIns {
+				if rest, ok := strings.CutPrefix(text, lead+" "); ok {
+					start += len(text) - len(rest)
+					text, marker = rest, rest
+					break
+				}
+			}
+			sub := citationPart.FindStringSubmatch(text)
+			if sub == nil || len(sub[1]) > 150 {
+				continue
+			}
+			year, _ := strconv.Atoi(sub[2])
+			found = append(found, parsedCitation{
+				Marker:   marker,
+				Author:   strings.TrimRight(sub[1], ",، "),
+				Year:     year,
+				Suffix:   sub[3],
+				Position: start,
+			})
+		}
+	}
+	return found
+}
+
+// resolveCitation picks the reference a citation cites: one from the same year whose first
+// author has the cited surname, or failing that one naming the cited author anywhere. A
+// year suffix picks among several such works in title order, as APA assigns them.
+func resolveCitation(c parsedCitation, refs []sqlc.Reference) pgtype.UUID {
+	surname := citedSurname(c.Author)
+	if surname == "" {
+		return pgtype.UUID{}
+	}
+	var first, named []sqlc.Reference
+	for _, ref := range refs {
+		if !ref.PublicationYear.Valid || int(ref.PublicationYear.Int32) != c.Year {
+			continue
+		}
+		switch {
+		case strings.EqualFold(referenceSurname(ref.Authors.String), surname):
+			first = append(first, ref)
+		case strings.Contains(strings.ToLower(ref.Authors.String), strings.ToLower(surname)):
+			named = append(named, ref)
+		}
+	}
+	candidates := first
+	if len(candidates) == 0 {
+		candidates = named
+	}
+	if len(candidates) == 0 {
+		return pgtype.UUID{}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Title < candidates[j].Title })
+	if c.Suffix != "" {
+		if i := int(c.Suffix[0] - 'a'); i < len(candidates) {
+			return candidates[i].ID
+		}
+	}
+	return candidates[0].ID
+}
+
+// citedSurname is the first author named in a citation: Smith in "Smith et al." or
+// "Smith & Lee"
+func citedSurname(author string) string {
+	author, _, _ = strings.Cut(author, " et al")
+	author = strings.TrimSuffix(strings.TrimSpace(author), " وآخرون")
+	return strings.TrimSpace(cutAtAny(author, "&", " and ", ",", "،"))
+}
+
+// referenceSurname is the first author's surname in a reference's author list, which
+// may be written "Smith, J., & Lee, K.", "John Smith and Kim Lee" or "Smith J, Lee K"
+func referenceSurname(authors string) string {
+	words := strings.Fields(cutAtAny(authors, ";", "&", " and ", ",", "،"))
+	switch {
+	case len(words) == 0:
+		return ""
+	case len(words) == 1:
+		return words[0]
+	case isInitials(words[len(words)-1]):
+		return words[0]
+	default:
+		return words[len(words)-1]
+	}
+}
+
+// cutAtAny returns s up to the first of the separators
+func cutAtAny(s string, seps ...string) string {
+	for _, sep := range seps {
+		if i := strings.Index(s, sep); i >= 0 {
+			s = s[:i]
+		}
+	}
+	return s
+}
+
+// isInitials reports whether word is initials such as J, J. or JK
+func isInitials(word string) bool {
+	letters := []rune(strings.NewReplacer(".", "", "-", "").Replace(word))
+	if len(letters) == 0 || len(letters) > 2 {
+		return false
+	}
+	for _, r := range letters {
+		if !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// bibliography is the references section of a generated document and the in-text
+// numbering that goes with it
+type bibliography struct {
+	References []PythonReferenceData
+	Unresolved int // Citations matching no reference, left out of the references section
+
+	numbers   map[uuid.UUID]int                    // IEEE numbers by reference ID; nil for author-date styles
+	citations map[uuid.UUID][]sqlc.ChapterCitation // By chapter or section ID
+}
+
+// documentBibliography builds the references section from the works actually cited in the
+// chapters: numbered in order of first citation for IEEE, alphabetical for the author-date
+// styles. Chapters without a single (Author, Year) citation, such as ones written with
+// numeric markers by hand, leave nothing to go on, so every stored reference is listed.
+// A chapter's sections, whose citations aren't stored, are read after the chapter.
+func (s *ResearchService) documentBibliography(ctx context.Context, projectID pgtype.UUID, chapters []sqlc.Chapter, sections map[uuid.UUID][]sqlc.ChapterSection, style string) (bibliography, error) {
+	bib := bibliography{citations: make(map[uuid.UUID][]sqlc.ChapterCitation)}
+	var refs []sqlc.Reference
+	if len(sections) > 0 {
+		var err error
+		if refs, err = s.store.GetReferencesByProjectID(ctx, projectID); err != nil {
+			return bib, fmt.Errorf("failed to fetch references for doc gen: %w", err)
+		}
+	}
+	var all []sqlc.ChapterCitation
+	for _, ch := range chapters {
+		citations, err := s.chapterCitations(ctx, ch)
+		if err != nil {
+			return bib, fmt.Errorf("failed to resolve citations for doc gen: %w", err)
+		}
+		bib.citations[ch.ID.Bytes] = citations
+		all = append(all, citations...)
+		for _, sec := range sections[ch.ID.Bytes] {
+			citations := textCitations(projectID, sec.ID, sec.Content, refs)
+			bib.citations[sec.ID.Bytes] = citations
+			all = append(all, citations...)
+		}
+	}
+	return s.citedBibliography(ctx, projectID, bib, all, style)
+}
+
+// unsavedBibliography is documentBibliography for text that is not saved as chapters, such
+// as the sections of a condensed article: its citations are resolved in memory rather
+// than kept. Each chapter needs an ID of its own to number its citations by.
+func (s *ResearchService) unsavedBibliography(ctx context.Context, projectID pgtype.UUID, chapters []sqlc.Chapter, style string) (bibliography, error) {
+	bib := bibliography{citations: make(map[uuid.UUID][]sqlc.ChapterCitation)}
+	refs, err := s.store.GetReferencesByProjectID(ctx, projectID)
+	if err != nil {
+		return bib, fmt.Errorf("failed to fetch references for doc gen: %w", err)
+	}
+	var all []sqlc.ChapterCitation
+	for _, ch := range chapters {
+		citations := textCitations(projectID, ch.ID, ch.Content.String, refs)
+		bib.citations[ch.ID.Bytes] = citations
+		all = append(all, citations...)
+	}
+	return s.citedBibliography(ctx, projectID, bib, all, style)
+}
+
+// textCitations resolves the citations of a text that is not saved as a chapter, keyed
+// by id, in memory
+func textCitations(projectID, id pgtype.UUID, content string, refs []sqlc.Reference) []sqlc.ChapterCitation {
+	var citations []sqlc.ChapterCitation
+	for _, c := range parseCitations(content) {
+		citations = append(citations, sqlc.ChapterCitation{
+			ChapterID:   id,
+			ProjectID:   projectID,
+			ReferenceID: resolveCitation(c, refs),
+			Marker:      c.Marker,
+			Author:      c.Author,
+			Year:        int32(c.Year),
+			Position:    int32(c.Position),
+		})
+	}
+	return citations
+}
+
+// citedBibliography lists the references the citations resolved to, in the style's order
+func (s *ResearchService) citedBibliography(ctx context.Context, projectID pgtype.UUID, bib bibliography, all []sqlc.ChapterCitation, style string) (bibliography, error) {
+	refs, err := s.citedReferences(ctx, projectID, all)
+	if err != nil {
+		return bib, err
+	}
+
+	var cited []sqlc.Reference
+	added := make(map[uuid.UUID]bool)
+	for _, c := range all {
+		ref, ok := refs[c.ReferenceID.Bytes]
+		switch {
+		case !c.ReferenceID.Valid || !ok:
+			bib.Unresolved++
+		case !added[c.ReferenceID.Bytes]:
+			added[c.ReferenceID.Bytes] = true
+			cited = append(cited, ref)
+		}
+	}
+	if len(all) == 0 {
+		if cited, err = s.store.GetReferencesByProjectID(ctx, projectID); err != nil {
+			return bib, fmt.Errorf("failed to fetch references for doc gen: %w", err)
+		}
+	}
+
+	if style == "IEEE" {
+		bib.numbers = make(map[uuid.UUID]int, len(cited))
+		for i, ref := range cited {
+			bib.numbers[ref.ID.Bytes] = i + 1
+		}
+	}
+	for _, ref := range cited {
+		bib.References = append(bib.References, PythonReferenceData{CitationAPA: apaCitation(ref)})
+	}
+	if bib.numbers == nil {
+		sort.SliceStable(bib.References, func(i, j int) bool {
+			return strings.ToLower(bib.References[i].CitationAPA) < strings.ToLower(bib.References[j].CitationAPA)
+		})
+	}
+	return bib, nil
+}
+
+// numberedGroup is a parenthesis left holding only numbered citations, e.g. ([1]; [4])
+var numberedGroup = regexp.MustCompile(`\((\[\d+\](?:;\s*\[\d+\])*)\)`)
+
+// chapterContent is the chapter's text for the document. For IEEE each resolved
+// (Author, Year) citation becomes its [n]; unresolved ones are left as written.
+func (b bibliography) chapterContent(ch sqlc.Chapter) string {
+	return b.numbered(ch.ID.Bytes, ch.Content.String)
+}
+
+// sectionContent is chapterContent for a section
+func (b bibliography) sectionContent(sec sqlc.ChapterSection) string {
+	return b.numbered(sec.ID.Bytes, sec.Content)
+}
+
+func (b bibliography) numbered(id uuid.UUID, content string) string {
+	if b.numbers == nil {
+		return content
+	}
+	citations := b.citations[id]
+	// From the end, so earlier positions stay valid
+	for i := len(citations) - 1; i >= 0; i-- {
+		c := citations[i]
+		n, ok := b.numbers[c.ReferenceID.Bytes]
+		start, end := int(c.Position), int(c.Position)+len(c.Marker)
+		if !ok || !c.ReferenceID.Valid || end > len(content) || content[start:end] != c.Marker {
+			continue
+		}
+		content = content[:start] + fmt.Sprintf("[%d]", n) + content[end:]
+	}
+	return numberedGroup.ReplaceAllStringFunc(content, func(group string) string {
+		return strings.ReplaceAll(group[1:len(group)-1], ";", ",")
+	})
+}
+
+// apaCitation is the reference's stored APA citation, or one put together from its
+// fields when it has none
+func apaCitation(ref sqlc.Reference) string {
+	if ref.CitationApa.String != "" {
+		return ref.CitationApa.String
+	}
+	var b strings.Builder
+	if ref.Authors.String != "" {
+		b.WriteString(ref.Authors.String + " ")
+	}
+	if ref.PublicationYear.Valid {
+		fmt.Fprintf(&b, "(%d). ", ref.PublicationYear.Int32)
+	} else {
+		b.WriteString("(n.d.). ")
+	}
+	b.WriteString(strings.TrimRight(ref.Title, ".") + ".")
+	if ref.Journal.String != "" {
+		b.WriteString(" " + ref.Journal.String + ".")
+	}
+	switch {
+	case ref.Doi.String != "":
+		b.WriteString(" https://doi.org/" + strings.TrimPrefix(ref.Doi.String, "https://doi.org/"))
+	case ref.Url.String != "":
+		b.WriteString(" " + ref.Url.String)
+	}
+	return b.String()
+}
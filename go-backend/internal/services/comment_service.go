@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrCommentThreadNotFound = errors.New("comment thread not found")
+	ErrInvalidAnchor         = errors.New("comment anchor must be a character range within the chapter or one of its section headings")
+)
+
+// ListCommentThreads returns a chapter's comment threads with their comments.
+// resolved filters by state when set.
+func (s *ResearchService) ListCommentThreads(ctx context.Context, projectID, chapterID, userID uuid.UUID, resolved *bool) ([]apimodels.CommentThreadResponse, error) {
+	chapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	filter := pgtype.Bool{}
+	if resolved != nil {
+		filter = pgtype.Bool{Bool: *resolved, Valid: true}
+	}
+	threads, err := s.store.ListChapterCommentThreads(ctx, sqlc.ListChapterCommentThreadsParams{ChapterID: chapter.ID, Resolved: filter})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching comment threads: %w", err)
+	}
+	comments, err := s.store.ListChapterComments(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching comments: %w", err)
+	}
+
+	byThread := make(map[uuid.UUID][]apimodels.CommentResponse)
+	for _, c := range comments {
+		byThread[c.ThreadID.Bytes] = append(byThread[c.ThreadID.Bytes], apimodels.ToCommentResponse(c))
+	}
+	resp := make([]apimodels.CommentThreadResponse, len(threads))
+	for i, t := range threads {
+		resp[i] = apimodels.ToCommentThreadResponse(t, byThread[t.ID.Bytes])
+	}
+	return resp, nil
+}
+
+// CreateCommentThread starts a thread on a chapter with its first comment.
+// Requires at least the comment role.
+func (s *ResearchService) CreateCommentThread(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.CreateCommentThreadRequest) (apimodels.CommentThreadResponse, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleComment)
+	if err != nil {
+		return apimodels.CommentThreadResponse{}, err
+	}
+	chapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+	if err != nil {
+		return apimodels.CommentThreadResponse{}, err
+	}
+	params, err := commentAnchor(chapter.Content.String, req)
+	if err != nil {
+		return apimodels.CommentThreadResponse{}, err
+	}
+	body := markdown.Sanitize(strings.TrimSpace(req.Body))
+	if body == "" {
+		return apimodels.CommentThreadResponse{}, ErrEmptyAfterSanitizing
+	}
+	params.ProjectID = chapter.ProjectID
+	params.ChapterID = chapter.ID
+	params.CreatedBy = pgtype.UUID{Bytes: userID, Valid: true}
+
+	var thread sqlc.CommentThread
+	var comment sqlc.Comment
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		if thread, err = q.CreateCommentThread(ctx, params); err != nil {
+			return err
+		}
+		comment, err = q.CreateComment(ctx, sqlc.CreateCommentParams{
+			ThreadID: thread.ID,
+			UserID:   params.CreatedBy,
+			Body:     body,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to create comment thread", "chapterID", chapterID, "error", err)
+		return apimodels.CommentThreadResponse{}, fmt.Errorf("could not create comment thread: %w", err)
+	}
+	s.logger.Info("Comment thread created", "threadID", thread.ID.Bytes, "chapterID", chapterID, "userID", userID)
+	s.notifications.Notify(ctx, Notification{
+		Type:      NotificationCommentAdded,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Title:     fmt.Sprintf("New comment on %s", chapter.Title),
+		Body:      notificationExcerpt(comment.Body),
+	}, recipientsExcept(userID, project.UserID, project.AdvisorID)...)
+	return apimodels.ToCommentThreadResponse(thread, []apimodels.CommentResponse{s.commentResponse(ctx, comment)}), nil
+}
+
+// AddComment replies to a thread. Requires at least the comment role.
+func (s *ResearchService) AddComment(ctx context.Context, projectID, threadID, userID uuid.UUID, req apimodels.AddCommentRequest) (apimodels.CommentResponse, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleComment)
+	if err != nil {
+		return apimodels.CommentResponse{}, err
+	}
+	thread, err := s.store.GetCommentThread(ctx, sqlc.GetCommentThreadParams{
+		ID:        pgtype.UUID{Bytes: threadID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return apimodels.CommentResponse{}, ErrCommentThreadNotFound
+		}
+		return apimodels.CommentResponse{}, fmt.Errorf("database error fetching comment thread: %w", err)
+	}
+	body := markdown.Sanitize(strings.TrimSpace(req.Body))
+	if body == "" {
+		return apimodels.CommentResponse{}, ErrEmptyAfterSanitizing
+	}
+	comment, err := s.store.CreateComment(ctx, sqlc.CreateCommentParams{
+		ThreadID: thread.ID,
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		Body:     body,
+	})
+	if err != nil {
+		return apimodels.CommentResponse{}, fmt.Errorf("could not add comment: %w", err)
+	}
+
+	// Everyone in the conversation hears about the reply, plus the project owner
+	participants, err := s.store.ListCommentThreadParticipants(ctx, thread.ID)
+	if err != nil {
+		s.logger.Warn("Failed to load comment thread participants", "threadID", threadID, "error", err)
+	}
+	chapterID := uuid.UUID(thread.ChapterID.Bytes)
+	s.notifications.Notify(ctx, Notification{
+		Type:      NotificationCommentAdded,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Title:     fmt.Sprintf("New reply in %s", project.Title),
+		Body:      notificationExcerpt(comment.Body),
+	}, recipientsExcept(userID, append(participants, thread.CreatedBy, project.UserID)...)...)
+	return s.commentResponse(ctx, comment), nil
+}
+
+// SetCommentThreadResolved resolves or reopens a thread. Requires at least the comment role.
+func (s *ResearchService) SetCommentThreadResolved(ctx context.Context, projectID, threadID, userID uuid.UUID, resolved bool) (sqlc.CommentThread, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleComment); err != nil {
+		return sqlc.CommentThread{}, err
+	}
+	thread, err := s.store.SetCommentThreadResolved(ctx, sqlc.SetCommentThreadResolvedParams{
+		Resolved:   resolved,
+		ResolvedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		ID:         pgtype.UUID{Bytes: threadID, Valid: true},
+		ProjectID:  pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.CommentThread{}, ErrCommentThreadNotFound
+		}
+		return sqlc.CommentThread{}, fmt.Errorf("could not update comment thread: %w", err)
+	}
+	s.logger.Info("Comment thread state changed", "threadID", threadID, "resolved", resolved, "userID", userID)
+	return thread, nil
+}
+
+// OpenCommentCounts returns the number of unresolved threads per chapter of a project.
+// Callers must already have checked the user's access to the project.
+func (s *ResearchService) OpenCommentCounts(ctx context.Context, projectID uuid.UUID) (map[uuid.UUID]int64, error) {
+	rows, err := s.store.CountOpenCommentThreads(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error counting open comments: %w", err)
+	}
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, r := range rows {
+		counts[r.ChapterID.Bytes] = r.OpenCount
+	}
+	return counts, nil
+}
+
+// commentAnchor validates the anchor of a new thread against the chapter content.
+// Ranges count characters (runes), not bytes, and keep the quoted passage.
+func commentAnchor(content string, req apimodels.CreateCommentThreadRequest) (sqlc.CreateCommentThreadParams, error) {
+	var params sqlc.CreateCommentThreadParams
+	heading := markdown.SanitizeLine(req.AnchorHeading)
+	if heading != "" {
+		if !hasHeading(content, heading) {
+			return params, ErrInvalidAnchor
+		}
+		params.AnchorHeading = pgtype.Text{String: heading, Valid: true}
+	}
+
+	switch {
+	case req.AnchorStart == nil && req.AnchorEnd == nil:
+		if heading == "" {
+			return params, ErrInvalidAnchor
+		}
+	case req.AnchorStart == nil || req.AnchorEnd == nil:
+		return params, ErrInvalidAnchor
+	default:
+		runes := []rune(content)
+		start, end := *req.AnchorStart, *req.AnchorEnd
+		if start < 0 || start >= end || int(end) > len(runes) {
+			return params, ErrInvalidAnchor
+		}
+		params.AnchorStart = pgtype.Int4{Int32: start, Valid: true}
+		params.AnchorEnd = pgtype.Int4{Int32: end, Valid: true}
+		params.QuotedText = string(runes[start:end])
+	}
+	return params, nil
+}
+
+// hasHeading reports whether content has a line that is heading, with or without Markdown #s
+func hasHeading(content, heading string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if strings.EqualFold(line, heading) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentResponse maps a newly written comment, looking up the author's name
+func (s *ResearchService) commentResponse(ctx context.Context, c sqlc.Comment) apimodels.CommentResponse {
+	row := sqlc.ListChapterCommentsRow{ID: c.ID, ThreadID: c.ThreadID, UserID: c.UserID, Body: c.Body, CreatedAt: c.CreatedAt}
+	if user, err := s.store.GetUserByID(ctx, c.UserID); err == nil {
+		row.FirstName = pgtype.Text{String: user.FirstName, Valid: true}
+		row.LastName = pgtype.Text{String: user.LastName, Valid: true}
+	}
+	return apimodels.ToCommentResponse(row)
+}
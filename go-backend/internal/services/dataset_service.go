@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrDatasetNotFound = errors.New("dataset not found")
+	ErrInvalidDataset  = errors.New("invalid CSV dataset")
+	ErrNoResultsData   = errors.New("upload a CSV dataset before generating the results chapter")
+)
+
+// Kinds of dataset columns
+const (
+	ColumnNumeric     = "numeric"
+	ColumnCategorical = "categorical"
+)
+
+const (
+	maxProjectDatasets     = 10
+	maxDatasetColumns      = 50
+	maxDatasetRows         = 100000
+	maxCategoryFrequencies = 10 // Values listed per categorical column
+	maxTableCategories     = 20 // More distinct values than this reads as free text or identifiers
+)
+
+// missingValues are the cell values, lower-cased, that stand for no value
+var missingValues = []string{"", "na", "n/a", "nan", "null", "none", ".", "-"}
+
+// UploadDataset reads a CSV of study results and stores its descriptive statistics;
+// the file itself is not kept. Requires the edit role.
+func (s *ResearchService) UploadDataset(ctx context.Context, projectID, userID uuid.UUID, fileName string, r io.Reader) (sqlc.ProjectDataset, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.ProjectDataset{}, err
+	}
+	datasets, err := s.store.ListProjectDatasets(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return sqlc.ProjectDataset{}, fmt.Errorf("database error fetching datasets: %w", err)
+	}
+	if len(datasets) >= maxProjectDatasets {
+		return sqlc.ProjectDataset{}, fmt.Errorf("%w: a project has at most %d datasets", ErrInvalidDataset, maxProjectDatasets)
+	}
+
+	// One byte past the limit tells a file of exactly MaxSize from a larger one
+	data, err := io.ReadAll(io.LimitReader(r, s.uploads.MaxSize+1))
+	if err != nil {
+		return sqlc.ProjectDataset{}, fmt.Errorf("could not read upload: %w", err)
+	}
+	if _, err := s.checkUpload(bytes.NewReader(data), int64(len(data)), datasetUploadRule); err != nil {
+		return sqlc.ProjectDataset{}, err
+	}
+	columns, rows, err := parseDataset(data)
+	if err != nil {
+		return sqlc.ProjectDataset{}, err
+	}
+	encoded, err := json.Marshal(columns)
+	if err != nil {
+		return sqlc.ProjectDataset{}, err
+	}
+
+	dataset, err := s.store.CreateProjectDataset(ctx, sqlc.CreateProjectDatasetParams{
+		ProjectID:  pgtype.UUID{Bytes: projectID, Valid: true},
+		UploadedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		FileName:   cleanFileName(fileName, "dataset.csv"),
+		RowCount:   int32(rows),
+		Columns:    encoded,
+	})
+	if err != nil {
+		return sqlc.ProjectDataset{}, fmt.Errorf("could not save dataset: %w", err)
+	}
+	s.logger.Info("Dataset uploaded", "projectID", projectID, "datasetID", dataset.ID.Bytes, "rows", rows, "columns", len(columns))
+	return dataset, nil
+}
+
+// ListDatasets returns a project's datasets in upload order
+func (s *ResearchService) ListDatasets(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.ProjectDataset, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	datasets, err := s.store.ListProjectDatasets(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching datasets: %w", err)
+	}
+	return datasets, nil
+}
+
+// GetDataset returns a dataset with the statistics of its columns
+func (s *ResearchService) GetDataset(ctx context.Context, projectID, datasetID, userID uuid.UUID) (sqlc.ProjectDataset, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return sqlc.ProjectDataset{}, err
+	}
+	dataset, err := s.store.GetProjectDataset(ctx, sqlc.GetProjectDatasetParams{
+		ID:        pgtype.UUID{Bytes: datasetID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectDataset{}, ErrDatasetNotFound
+		}
+		return sqlc.ProjectDataset{}, fmt.Errorf("database error fetching dataset: %w", err)
+	}
+	return dataset, nil
+}
+
+// DeleteDataset removes a dataset and the results tables generated from it. Markers
+// left in the chapter content are dropped from the document. Requires the edit role.
+func (s *ResearchService) DeleteDataset(ctx context.Context, projectID, datasetID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	err := s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		dataset, err := q.DeleteProjectDataset(ctx, sqlc.DeleteProjectDatasetParams{
+			ID:        pgtype.UUID{Bytes: datasetID, Valid: true},
+			ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		return q.DeleteFiguresByID(ctx, sqlc.DeleteFiguresByIDParams{ProjectID: dataset.ProjectID, Ids: dataset.TableIds})
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrDatasetNotFound
+		}
+		return fmt.Errorf("could not delete dataset: %w", err)
+	}
+	s.logger.Info("Dataset deleted", "projectID", projectID, "datasetID", datasetID, "userID", userID)
+	return nil
+}
+
+// parseDataset reads a CSV with a header row and returns the statistics of each column
+// and the number of data rows. Comma, semicolon and tab delimiters are recognised from
+// the header; with semicolons, decimal commas are read as well.
+func parseDataset(data []byte) ([]apimodels.DatasetColumn, int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel's UTF-8 byte order mark
+	if !utf8.Valid(data) {
+		return nil, 0, fmt.Errorf("%w: the file must be UTF-8 text", ErrInvalidDataset)
+	}
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	delimiter := ','
+	for _, d := range []rune{';', '\t'} {
+		if bytes.Count(header, []byte(string(d))) > bytes.Count(header, []byte(string(delimiter))) {
+			delimiter = d
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidDataset, err)
+	}
+	if len(records) < 2 {
+		return nil, 0, fmt.Errorf("%w: the file needs a header row and at least one data row", ErrInvalidDataset)
+	}
+	if len(records[0]) > maxDatasetColumns {
+		return nil, 0, fmt.Errorf("%w: at most %d columns", ErrInvalidDataset, maxDatasetColumns)
+	}
+	rows := records[1:]
+	if len(rows) > maxDatasetRows {
+		return nil, 0, fmt.Errorf("%w: at most %d rows", ErrInvalidDataset, maxDatasetRows)
+	}
+
+	columns := make([]apimodels.DatasetColumn, len(records[0]))
+	values := make([]string, len(rows))
+	for c, name := range records[0] {
+		if name = strings.TrimSpace(name); name == "" {
+			name = fmt.Sprintf("Column %d", c+1)
+		}
+		for r, row := range rows {
+			values[r] = row[c]
+		}
+		columns[c] = columnStats(name, values, delimiter == ';')
+	}
+	return columns, len(rows), nil
+}
+
+// columnStats describes one column: numeric when every value present reads as a number,
+// categorical otherwise
+func columnStats(name string, values []string, decimalComma bool) apimodels.DatasetColumn {
+	col := apimodels.DatasetColumn{Name: name, Kind: ColumnNumeric}
+	var present []string
+	var numbers []float64
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if slices.Contains(missingValues, strings.ToLower(v)) {
+			col.Missing++
+			continue
+		}
+		present = append(present, v)
+		if col.Kind != ColumnNumeric {
+			continue
+		}
+		if decimalComma {
+			v = strings.Replace(v, ",", ".", 1)
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(n, 0) {
+			col.Kind = ColumnCategorical
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	col.Count = len(present)
+	if col.Count == 0 {
+		col.Kind = ColumnCategorical
+		return col
+	}
+
+	if col.Kind == ColumnNumeric {
+		slices.Sort(numbers)
+		var sum float64
+		for _, n := range numbers {
+			sum += n
+		}
+		mean := sum / float64(len(numbers))
+		var squares float64
+		for _, n := range numbers {
+			squares += (n - mean) * (n - mean)
+		}
+		var stdDev float64
+		if len(numbers) > 1 {
+			stdDev = math.Sqrt(squares / float64(len(numbers)-1))
+		}
+		median := numbers[len(numbers)/2]
+		if len(numbers)%2 == 0 {
+			median = (numbers[len(numbers)/2-1] + median) / 2
+		}
+		stat := func(v float64) *float64 {
+			v = math.Round(v*10000) / 10000
+			return &v
+		}
+		col.Mean, col.StdDev, col.Median = stat(mean), stat(stdDev), stat(median)
+		col.Min, col.Max = stat(numbers[0]), stat(numbers[len(numbers)-1])
+		return col
+	}
+
+	counts := make(map[string]int)
+	for _, v := range present {
+		counts[v]++
+	}
+	col.Unique = len(counts)
+	for v, n := range counts {
+		col.Frequencies = append(col.Frequencies, apimodels.CategoryFrequency{
+			Value:   v,
+			Count:   n,
+			Percent: math.Round(float64(n)*1000/float64(col.Count)) / 10,
+		})
+	}
+	slices.SortFunc(col.Frequencies, func(a, b apimodels.CategoryFrequency) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	if len(col.Frequencies) > maxCategoryFrequencies {
+		col.Frequencies = col.Frequencies[:maxCategoryFrequencies]
+	}
+	return col
+}
+
+// resultsTable is a table of descriptive statistics added to the results chapter
+type resultsTable struct {
+	ResultsTable
+	ID      uuid.UUID
+	Dataset int // Index of the dataset it describes
+}
+
+// resultsTables builds the tables of the results chapter: per dataset, one of its numeric
+// columns and one of the frequencies of its categorical columns. Columns with too many
+// distinct values to tabulate are left out.
+func resultsTables(datasets []sqlc.ProjectDataset) []resultsTable {
+	format := func(v *float64) string { return strconv.FormatFloat(*v, 'f', 2, 64) }
+	var tables []resultsTable
+	for i, d := range datasets {
+		columns := apimodels.ToDatasetResponse(d).Columns
+		name := strings.TrimSuffix(d.FileName, filepath.Ext(d.FileName))
+
+		numeric := [][]string{{"Variable", "N", "Missing", "Mean", "SD", "Min", "Median", "Max"}}
+		categorical := [][]string{{"Variable", "Category", "n", "%"}}
+		for _, c := range columns {
+			switch {
+			case c.Kind == ColumnNumeric:
+				numeric = append(numeric, []string{c.Name, strconv.Itoa(c.Count), strconv.Itoa(c.Missing),
+					format(c.Mean), format(c.StdDev), format(c.Min), format(c.Median), format(c.Max)})
+			case c.Count > 0 && c.Unique <= maxTableCategories:
+				listed := 0
+				for j, f := range c.Frequencies {
+					variable := ""
+					if j == 0 {
+						variable = c.Name
+					}
+					categorical = append(categorical, []string{variable, f.Value, strconv.Itoa(f.Count), strconv.FormatFloat(f.Percent, 'f', 1, 64)})
+					listed += f.Count
+				}
+				if other := c.Count - listed; other > 0 {
+					percent := math.Round(float64(other)*1000/float64(c.Count)) / 10
+					categorical = append(categorical, []string{"", "Other", strconv.Itoa(other), strconv.FormatFloat(percent, 'f', 1, 64)})
+				}
+			}
+		}
+		if len(numeric) > 1 {
+			tables = append(tables, resultsTable{Dataset: i, ResultsTable: ResultsTable{
+				Caption: fmt.Sprintf("Descriptive statistics of the numeric variables in %s (N = %d)", name, d.RowCount),
+				Rows:    numeric,
+			}})
+		}
+		if len(categorical) > 1 {
+			tables = append(tables, resultsTable{Dataset: i, ResultsTable: ResultsTable{
+				Caption: fmt.Sprintf("Frequencies of the categorical variables in %s (N = %d)", name, d.RowCount),
+				Rows:    categorical,
+			}})
+		}
+	}
+	for i := range tables {
+		tables[i].ID = uuid.New()
+		tables[i].Placeholder = fmt.Sprintf("[[TABLE %d]]", i+1)
+	}
+	return tables
+}
+
+// tablePlaceholder matches the placeholders of the results prompt, including any the
+// model made up
+var tablePlaceholder = regexp.MustCompile(`\[\[TABLE \d+\]\]`)
+
+// placeResultsTables swaps the table placeholders in a generated results chapter for
+// the tables' markers. Tables the model did not place follow the chapter text.
+func placeResultsTables(content string, tables []resultsTable) string {
+	for _, t := range tables {
+		content = strings.ReplaceAll(content, t.Placeholder, "\n"+apimodels.FigureMarker(FigureKindTable, t.ID)+"\n")
+	}
+	return tablePlaceholder.ReplaceAllString(content, "")
+}
+
+// saveResultsTables replaces the tables earlier generations added to the project with
+// the new ones, in the results chapter, and records them on their datasets
+func saveResultsTables(ctx context.Context, q *sqlc.Queries, chapter sqlc.Chapter, userID uuid.UUID, datasets []sqlc.ProjectDataset, tables []resultsTable) error {
+	var previous []pgtype.UUID
+	for _, d := range datasets {
+		previous = append(previous, d.TableIds...)
+	}
+	if err := q.DeleteFiguresByID(ctx, sqlc.DeleteFiguresByIDParams{ProjectID: chapter.ProjectID, Ids: previous}); err != nil {
+		return fmt.Errorf("could not remove previous results tables: %w", err)
+	}
+	ids := make([][]pgtype.UUID, len(datasets))
+	for _, t := range tables {
+		rows, err := encodeTableRows(t.Rows)
+		if err != nil {
+			return err
+		}
+		_, err = q.CreateChapterFigure(ctx, sqlc.CreateChapterFigureParams{
+			ID:        pgtype.UUID{Bytes: t.ID, Valid: true},
+			ProjectID: chapter.ProjectID,
+			ChapterID: chapter.ID,
+			Kind:      FigureKindTable,
+			Caption:   t.Caption,
+			TableData: rows,
+			CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("could not save results table: %w", err)
+		}
+		ids[t.Dataset] = append(ids[t.Dataset], pgtype.UUID{Bytes: t.ID, Valid: true})
+	}
+	for i, d := range datasets {
+		if ids[i] == nil {
+			ids[i] = []pgtype.UUID{}
+		}
+		if err := q.SetDatasetTables(ctx, sqlc.SetDatasetTablesParams{TableIds: ids[i], ID: d.ID}); err != nil {
+			return fmt.Errorf("could not record results tables: %w", err)
+		}
+	}
+	return nil
+}
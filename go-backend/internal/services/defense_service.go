@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// maxDefenseQuestions caps the questions kept per chapter, and about the thesis as a
+// whole, whatever the AI returns
+const maxDefenseQuestions = 10
+
+var ErrNoThesisContent = errors.New("the project has no chapter content to prepare questions from")
+
+// GenerateDefenseQuestions reads the written chapters of a project and has the AI
+// prepare the questions examiners are likely to ask at the viva, grouped by chapter,
+// with outlines of good answers. Requires the edit role.
+func (s *ResearchService) GenerateDefenseQuestions(ctx context.Context, projectID, userID uuid.UUID, req apimodels.GenerateDefenseQuestionsRequest) (apimodels.DefenseQuestionsResponse, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.DefenseQuestionsResponse{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return apimodels.DefenseQuestionsResponse{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	input := DefenseQuestionsInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       project.Language,
+		PerChapter:     req.QuestionsPerChapter,
+	}
+	if input.PerChapter == 0 {
+		input.PerChapter = 5
+	}
+	resp := apimodels.DefenseQuestionsResponse{
+		ProjectID: projectID,
+		Chapters:  []apimodels.DefenseChapterQuestions{},
+		General:   []apimodels.DefenseQuestion{},
+	}
+	for _, ch := range chapters {
+		if ch.Type == ChapterTypeExecutiveSummary || strings.TrimSpace(ch.Content.String) == "" {
+			continue
+		}
+		input.Chapters = append(input.Chapters, DefenseChapter{Type: ch.Type, Title: ch.Title, Content: ch.Content.String})
+		resp.Chapters = append(resp.Chapters, apimodels.DefenseChapterQuestions{
+			ChapterID:    ch.ID.Bytes,
+			ChapterType:  ch.Type,
+			ChapterTitle: ch.Title,
+		})
+	}
+	if len(input.Chapters) == 0 {
+		return apimodels.DefenseQuestionsResponse{}, ErrNoThesisContent
+	}
+
+	ctx = s.withGenerationLog(ctx, GenerationKindDefenseQuestions, userID, &projectID, nil)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	byChapter, general, err := s.aiService.GenerateDefenseQuestions(ctx, input)
+	if err != nil {
+		s.logger.Error("AI defense question generation failed", "projectID", projectID, "error", err)
+		return apimodels.DefenseQuestionsResponse{}, fmt.Errorf("AI generation failed: %w", err)
+	}
+	questions := 0
+	for i := range resp.Chapters {
+		resp.Chapters[i].Questions = cleanDefenseQuestions(byChapter[resp.Chapters[i].ChapterType])
+		questions += len(resp.Chapters[i].Questions)
+	}
+	resp.General = cleanDefenseQuestions(general)
+	if questions+len(resp.General) == 0 {
+		return apimodels.DefenseQuestionsResponse{}, errors.New("AI generation failed: no questions were generated")
+	}
+	s.logger.Info("Defense questions generated", "projectID", projectID, "chapters", len(resp.Chapters), "questions", questions+len(resp.General), "userID", userID)
+	return resp, nil
+}
+
+// cleanDefenseQuestions drops empty questions and caps how many are kept
+func cleanDefenseQuestions(questions []apimodels.DefenseQuestion) []apimodels.DefenseQuestion {
+	cleaned := []apimodels.DefenseQuestion{}
+	for _, q := range questions {
+		q.Question = strings.TrimSpace(q.Question)
+		if q.Question == "" {
+			continue
+		}
+		q.Rationale = strings.TrimSpace(q.Rationale)
+		if q.AnswerOutline == nil {
+			q.AnswerOutline = []string{}
+		}
+		if cleaned = append(cleaned, q); len(cleaned) == maxDefenseQuestions {
+			break
+		}
+	}
+	return cleaned
+}
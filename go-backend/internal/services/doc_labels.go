@@ -0,0 +1,120 @@
+package services
+
+import (
+	"strconv"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+)
+
+// Languages with labels for generated documents. English is also the default project
+// language, which needs no instruction in generation prompts.
+const (
+	LanguageEnglish = "en"
+	LanguageArabic  = "ar"
+)
+
+// defaultArabicFont is used for Arabic script when the formatting options name none
+const defaultArabicFont = "Simplified Arabic"
+
+// docLabels are the fixed strings of a generated document (title page, headings and
+// caption prefixes) per language. The docgen service falls back to English for any
+// label missing here.
+var docLabels = map[string]map[string]string{
+	LanguageEnglish: {
+		"by":                "By",
+		"specialization":    "Specialization",
+		"institution":       "Institution",
+		"references":        "References",
+		"appendix":          "Appendix",
+		"figure":            "Figure",
+		"table":             "Table",
+		"timeline":          "Research Timeline",
+		"task":              "Task",
+		"milestones":        "Milestones",
+		"yes":               "Yes",
+		"no":                "No",
+		"abstract":          "Abstract",
+		"conclusions":       "Conclusions",
+		"executive_summary": "Executive Summary",
+		"introduction":      "Introduction",
+		"methods":           "Methods",
+		"results":           "Results",
+		"discussion":        "Discussion",
+		"submitted_to":      "Prepared for submission to",
+		"submitted_for":     "A thesis submitted in partial fulfilment of the requirements for the degree of",
+		"supervisor":        "Supervisor",
+		"declaration":       "Declaration",
+		"declaration_text":  "I, {student}, declare that this thesis, titled \"{title}\", is my own work. Where I have consulted the work of others, it is clearly attributed. This work has not been submitted for any other degree or qualification at {institution} or any other institution.",
+		"acknowledgments":   "Acknowledgments",
+		"contents":          "Table of Contents",
+		"list_of_figures":   "List of Figures",
+		"list_of_tables":    "List of Tables",
+		"update_field":      "Right-click and choose Update Field to build this table.",
+		"ai_share_notice":   "AI-generated content: about {percent}% of the text below was written by AI.",
+		"ai_notice":         "AI-generated content: the text below was written by AI.",
+	},
+	LanguageArabic: {
+		"by":                "إعداد",
+		"specialization":    "التخصص",
+		"institution":       "المؤسسة",
+		"references":        "المراجع",
+		"appendix":          "الملحق",
+		"figure":            "الشكل",
+		"table":             "الجدول",
+		"timeline":          "الخطة الزمنية للبحث",
+		"task":              "المهمة",
+		"milestones":        "المراحل الرئيسية",
+		"yes":               "نعم",
+		"no":                "لا",
+		"abstract":          "الملخص",
+		"conclusions":       "الاستنتاجات",
+		"executive_summary": "الملخص التنفيذي",
+		"introduction":      "المقدمة",
+		"methods":           "المنهجية",
+		"results":           "النتائج",
+		"discussion":        "المناقشة",
+		"submitted_to":      "أُعدّ للنشر في",
+		"submitted_for":     "رسالة مقدمة استكمالاً لمتطلبات الحصول على درجة",
+		"supervisor":        "المشرف",
+		"declaration":       "إقرار",
+		"declaration_text":  "أقر أنا {student} بأن هذه الرسالة المعنونة \"{title}\" هي من عملي الخاص، وأن كل ما استعنت به من أعمال الآخرين قد نُسب إلى أصحابه، وأنها لم تُقدَّم لنيل أي درجة أو مؤهل آخر في {institution} أو في أي مؤسسة أخرى.",
+		"acknowledgments":   "شكر وتقدير",
+		"contents":          "قائمة المحتويات",
+		"list_of_figures":   "قائمة الأشكال",
+		"list_of_tables":    "قائمة الجداول",
+		"update_field":      "انقر بزر الفأرة الأيمن واختر تحديث الحقل لإنشاء هذا الجدول.",
+		"ai_share_notice":   "محتوى مولَّد بالذكاء الاصطناعي: كُتب نحو {percent}% من النص التالي بالذكاء الاصطناعي.",
+		"ai_notice":         "محتوى مولَّد بالذكاء الاصطناعي: كُتب النص التالي بالذكاء الاصطناعي.",
+	},
+}
+
+// arabicLetters letter appendices of Arabic documents, in the abjadi order theses use
+var arabicLetters = []string{"أ", "ب", "ج", "د", "هـ", "و", "ز", "ح", "ط", "ي", "ك", "ل", "م", "ن", "س", "ع", "ف", "ص", "ق", "ر", "ش", "ت", "ث", "خ", "ذ", "ض", "ظ", "غ"}
+
+// documentLabels returns the labels for the document's language
+func documentLabels(opts apimodels.FormattingOptions) map[string]string {
+	if labels, ok := docLabels[opts.Language]; ok {
+		return labels
+	}
+	return docLabels[LanguageEnglish]
+}
+
+// documentDirection is the paragraph direction of the document, rtl for Arabic
+func documentDirection(opts apimodels.FormattingOptions) string {
+	if opts.Language == LanguageArabic {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// documentAppendixLetter letters the i-th appendix (from 0) in the document's language.
+// Arabic documents with more appendices than letters number the rest.
+func documentAppendixLetter(i int, opts apimodels.FormattingOptions) string {
+	if opts.Language != LanguageArabic {
+		return appendixLetter(i)
+	}
+	if i < len(arabicLetters) {
+		return arabicLetters[i]
+	}
+	return strconv.Itoa(i + 1)
+}
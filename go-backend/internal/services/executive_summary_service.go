@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ChapterTypeExecutiveSummary is the type of the chapter an executive summary is kept in.
+// It is written from the other chapters and left out of the thesis document.
+const ChapterTypeExecutiveSummary = "executive_summary"
+
+// Readers an executive summary can be written for
+const (
+	ExecutiveSummaryForSupervisors = "supervisors"
+	ExecutiveSummaryForFundingBody = "funding_body"
+)
+
+// defaultExecutiveSummaryWords is about 2-3 pages, used when neither the request nor the
+// chapter sets a target
+const defaultExecutiveSummaryWords = 1200
+
+// GenerateExecutiveSummary has the AI summarise the written chapters of a project in a
+// 2-3 page executive summary, saved as the project's executive_summary chapter: created
+// the first time and overwritten, as a new version, after that. Requires the edit role.
+func (s *ResearchService) GenerateExecutiveSummary(ctx context.Context, projectID, userID uuid.UUID, req apimodels.GenerateExecutiveSummaryRequest) (sqlc.Chapter, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Chapter{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.Chapter{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	written := defenseChapters(chapters)
+	if len(written) == 0 {
+		return sqlc.Chapter{}, ErrNoThesisContent
+	}
+	var existing *sqlc.Chapter
+	for i, ch := range chapters {
+		if ch.Type == ChapterTypeExecutiveSummary {
+			existing = &chapters[i]
+		}
+	}
+	if existing != nil && existing.Status.String == ChapterStatusInReview {
+		return sqlc.Chapter{}, ErrChapterInReview
+	}
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return sqlc.Chapter{}, err
+	}
+	if ctx, err = s.withGenerationPreferences(ctx, &project, userID); err != nil {
+		return sqlc.Chapter{}, err
+	}
+	// Counts against the project owner's plan like any other generation
+	release, err := s.billing.ReserveUsage(ctx, project.UserID.Bytes, UsageAIGenerations)
+	if err != nil {
+		return sqlc.Chapter{}, err
+	}
+
+	audience := req.Audience
+	if audience == "" {
+		audience = ExecutiveSummaryForSupervisors
+	}
+	target := req.TargetWords
+	if target == 0 && existing != nil {
+		target = existing.TargetWordCount.Int32
+	}
+	if target == 0 {
+		target = defaultExecutiveSummaryWords
+	}
+	var chapterID *uuid.UUID // The first summary's chapter does not exist yet, so its call is the project's
+	if existing != nil {
+		id := uuid.UUID(existing.ID.Bytes)
+		chapterID = &id
+	}
+	ctx = s.withGenerationLog(ctx, GenerationKindExecutiveSummary, userID, &projectID, chapterID)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	ctx, cacheUse := withResponseCacheUse(ctx, false)
+	content, err := s.aiService.GenerateExecutiveSummary(ctx, DefenseQuestionsInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       project.Language,
+		Chapters:       written,
+	}, audience, int(target))
+	if err != nil {
+		release()
+		s.logger.Error("AI executive summary generation failed", "projectID", projectID, "error", err)
+		return sqlc.Chapter{}, fmt.Errorf("AI generation failed: %w", err)
+	}
+	formatting, err := s.projectFormatting(ctx, project)
+	if err != nil {
+		release()
+		return sqlc.Chapter{}, err
+	}
+
+	var chapter sqlc.Chapter
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if existing == nil {
+			created, err := q.CreateChapter(ctx, sqlc.CreateChapterParams{
+				ProjectID:       project.ID,
+				Type:            ChapterTypeExecutiveSummary,
+				Title:           documentLabels(formatting)["executive_summary"],
+				TargetWordCount: pgtype.Int4{Int32: req.TargetWords, Valid: req.TargetWords > 0},
+			})
+			if err != nil {
+				return fmt.Errorf("could not create executive summary chapter: %w", err)
+			}
+			existing = &created
+		}
+		var err error
+		stored, wordCount := chapterContent(content)
+		chapter, err = q.UpdateChapter(ctx, sqlc.UpdateChapterParams{
+			ID:              existing.ID,
+			Title:           existing.Title,
+			Content:         stored,
+			WordCount:       wordCount,
+			Status:          pgtype.Text{String: "generated", Valid: true},
+			TargetWordCount: existing.TargetWordCount,
+			ProjectID:       project.ID,
+			UserID:          pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			if isNoRows(err) {
+				return ErrChapterNotFound
+			}
+			return fmt.Errorf("could not save executive summary: %w", err)
+		}
+		return recordChapterSave(ctx, q, chapter, ProvenanceAI)
+	})
+	if err != nil {
+		release()
+		return sqlc.Chapter{}, err
+	}
+	if !cacheUse.fresh() {
+		release() // A reused reply is not a new generation
+	}
+	s.logger.Info("Executive summary generated", "projectID", projectID, "chapterID", chapter.ID, "words", chapter.WordCount.Int32, "audience", audience, "userID", userID)
+	return chapter, nil
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrFigureNotFound  = errors.New("figure or table not found")
+	ErrFigureImageType = errors.New("figures must be PNG or JPEG images")
+	ErrInvalidTable    = errors.New("every table row must have the same number of cells, between 1 and 20, of at most 2000 characters each")
+	ErrNotATable       = errors.New("rows can only be set on tables")
+)
+
+// Kinds of chapter figures
+const (
+	FigureKindFigure = "figure"
+	FigureKindTable  = "table"
+)
+
+const (
+	maxTableColumns   = 20
+	maxTableCellChars = 2000
+)
+
+// figureChapter checks the user's role on the project and that the chapter belongs to it
+func (s *ResearchService) figureChapter(ctx context.Context, projectID, chapterID, userID uuid.UUID, role string) (sqlc.Chapter, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, role); err != nil {
+		return sqlc.Chapter{}, err
+	}
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Chapter{}, ErrChapterNotFound
+		}
+		return sqlc.Chapter{}, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	return chapter, nil
+}
+
+// ListFigures returns a chapter's figures and tables in order, numbered as in the document
+func (s *ResearchService) ListFigures(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]apimodels.FigureResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	figures, err := s.store.ListChapterFigures(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching figures: %w", err)
+	}
+	numbers := figureNumbers(figures)
+	resp := make([]apimodels.FigureResponse, len(figures))
+	for i, f := range figures {
+		resp[i] = apimodels.ToFigureResponse(f, numbers[f.ID.Bytes])
+	}
+	return resp, nil
+}
+
+// AddFigure stores an image as a figure of a chapter. Requires the edit role.
+func (s *ResearchService) AddFigure(ctx context.Context, projectID, chapterID, userID uuid.UUID, caption string, position int32, r io.Reader) (apimodels.FigureResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.FigureResponse{}, err
+	}
+
+	figureID := uuid.New()
+	filePath := projectFileKey(projectID, "figures", figureID.String())
+	stored, err := s.writeUpload(ctx, filePath, r, figureUploadRule)
+	if err != nil {
+		return apimodels.FigureResponse{}, err
+	}
+
+	figure, err := s.store.CreateChapterFigure(ctx, sqlc.CreateChapterFigureParams{
+		ID:        pgtype.UUID{Bytes: figureID, Valid: true},
+		ProjectID: chapter.ProjectID,
+		ChapterID: chapter.ID,
+		Kind:      FigureKindFigure,
+		Caption:   strings.TrimSpace(caption),
+		Position:  position,
+		FilePath:  filePath,
+		MimeType:  stored.MimeType,
+		TableData: []byte("[]"),
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		s.removeUploadFile(ctx, filePath)
+		return apimodels.FigureResponse{}, fmt.Errorf("could not save figure: %w", err)
+	}
+	s.logger.Info("Figure added", "chapterID", chapterID, "figureID", figureID, "userID", userID)
+	return s.figureResponse(ctx, figure)
+}
+
+// AddTable adds a table to a chapter. Requires the edit role.
+func (s *ResearchService) AddTable(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.CreateTableRequest) (apimodels.FigureResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.FigureResponse{}, err
+	}
+	rows, err := encodeTableRows(req.Rows)
+	if err != nil {
+		return apimodels.FigureResponse{}, err
+	}
+	figure, err := s.store.CreateChapterFigure(ctx, sqlc.CreateChapterFigureParams{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ProjectID: chapter.ProjectID,
+		ChapterID: chapter.ID,
+		Kind:      FigureKindTable,
+		Caption:   strings.TrimSpace(req.Caption),
+		Position:  req.Position,
+		TableData: rows,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return apimodels.FigureResponse{}, fmt.Errorf("could not save table: %w", err)
+	}
+	s.logger.Info("Table added", "chapterID", chapterID, "figureID", figure.ID.Bytes, "userID", userID)
+	return s.figureResponse(ctx, figure)
+}
+
+// UpdateFigure changes the caption, position or, for tables, the rows. Requires the edit role.
+func (s *ResearchService) UpdateFigure(ctx context.Context, projectID, chapterID, figureID, userID uuid.UUID, req apimodels.UpdateFigureRequest) (apimodels.FigureResponse, error) {
+	figure, err := s.getFigure(ctx, projectID, chapterID, figureID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.FigureResponse{}, err
+	}
+	params := sqlc.UpdateChapterFigureParams{
+		Caption:   figure.Caption,
+		Position:  figure.Position,
+		TableData: figure.TableData,
+		ID:        figure.ID,
+	}
+	if req.Caption != nil {
+		params.Caption = strings.TrimSpace(*req.Caption)
+	}
+	if req.Position != nil {
+		params.Position = *req.Position
+	}
+	if req.Rows != nil {
+		if figure.Kind != FigureKindTable {
+			return apimodels.FigureResponse{}, ErrNotATable
+		}
+		if params.TableData, err = encodeTableRows(req.Rows); err != nil {
+			return apimodels.FigureResponse{}, err
+		}
+	}
+	figure, err = s.store.UpdateChapterFigure(ctx, params)
+	if err != nil {
+		return apimodels.FigureResponse{}, fmt.Errorf("could not update figure: %w", err)
+	}
+	return s.figureResponse(ctx, figure)
+}
+
+// DeleteFigure removes a figure or table, and a figure's image. Markers left in the
+// chapter content are dropped from the document. Requires the edit role.
+func (s *ResearchService) DeleteFigure(ctx context.Context, projectID, chapterID, figureID, userID uuid.UUID) error {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return err
+	}
+	figure, err := s.store.DeleteChapterFigure(ctx, sqlc.DeleteChapterFigureParams{
+		ID:        pgtype.UUID{Bytes: figureID, Valid: true},
+		ChapterID: chapter.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrFigureNotFound
+		}
+		return fmt.Errorf("could not delete figure: %w", err)
+	}
+	if figure.FilePath != "" {
+		s.removeUploadFile(ctx, figure.FilePath)
+	}
+	s.logger.Info("Figure deleted", "chapterID", chapterID, "figureID", figureID, "userID", userID)
+	return nil
+}
+
+// FigureImage returns the image file of a figure and its MIME type
+func (s *ResearchService) FigureImage(ctx context.Context, projectID, chapterID, figureID, userID uuid.UUID) (string, string, error) {
+	figure, err := s.getFigure(ctx, projectID, chapterID, figureID, userID, ProjectRoleRead)
+	if err != nil {
+		return "", "", err
+	}
+	if figure.Kind != FigureKindFigure {
+		return "", "", ErrFigureNotFound
+	}
+	return figure.FilePath, figure.MimeType, nil
+}
+
+func (s *ResearchService) getFigure(ctx context.Context, projectID, chapterID, figureID, userID uuid.UUID, role string) (sqlc.ChapterFigure, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, role)
+	if err != nil {
+		return sqlc.ChapterFigure{}, err
+	}
+	figure, err := s.store.GetChapterFigure(ctx, sqlc.GetChapterFigureParams{
+		ID:        pgtype.UUID{Bytes: figureID, Valid: true},
+		ChapterID: chapter.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterFigure{}, ErrFigureNotFound
+		}
+		return sqlc.ChapterFigure{}, fmt.Errorf("database error fetching figure: %w", err)
+	}
+	return figure, nil
+}
+
+// figureResponse numbers one figure among the rest of its chapter's
+func (s *ResearchService) figureResponse(ctx context.Context, figure sqlc.ChapterFigure) (apimodels.FigureResponse, error) {
+	figures, err := s.store.ListChapterFigures(ctx, figure.ChapterID)
+	if err != nil {
+		return apimodels.FigureResponse{}, fmt.Errorf("database error fetching figures: %w", err)
+	}
+	return apimodels.ToFigureResponse(figure, figureNumbers(figures)[figure.ID.Bytes]), nil
+}
+
+// figureNumbers numbers figures and tables separately, in the order given
+func figureNumbers(figures []sqlc.ChapterFigure) map[uuid.UUID]int {
+	counts := make(map[string]int, 2)
+	numbers := make(map[uuid.UUID]int, len(figures))
+	for _, f := range figures {
+		counts[f.Kind]++
+		numbers[f.ID.Bytes] = counts[f.Kind]
+	}
+	return numbers
+}
+
+func encodeTableRows(rows [][]string) ([]byte, error) {
+	columns := len(rows[0])
+	if columns == 0 || columns > maxTableColumns {
+		return nil, ErrInvalidTable
+	}
+	for _, row := range rows {
+		if len(row) != columns {
+			return nil, ErrInvalidTable
+		}
+		for _, cell := range row {
+			if len(cell) > maxTableCellChars {
+				return nil, ErrInvalidTable
+			}
+		}
+	}
+	return json.Marshal(rows)
+}
+
+// PythonFigureData is a figure or table as the docgen service places it in a chapter
+type PythonFigureData struct {
+	Kind        string     `json:"kind"`
+	Label       string     `json:"label"` // e.g. "Figure 2.1"
+	Caption     string     `json:"caption"`
+	Marker      string     `json:"marker"`
+	ImageBase64 string     `json:"image_base64,omitempty"`
+	Rows        [][]string `json:"rows,omitempty"`
+}
+
+// docgenFigures loads a project's figures and tables for document generation, by chapter
+func (s *ResearchService) docgenFigures(ctx context.Context, projectID uuid.UUID) (map[uuid.UUID][]sqlc.ChapterFigure, error) {
+	figures, err := s.store.ListProjectFigures(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch figures for doc gen: %w", err)
+	}
+	byChapter := make(map[uuid.UUID][]sqlc.ChapterFigure)
+	for _, f := range figures {
+		byChapter[f.ChapterID.Bytes] = append(byChapter[f.ChapterID.Bytes], f)
+	}
+	return byChapter, nil
+}
+
+// pythonFigures labels a chapter's figures with the chapter's number in the document,
+// in the document's language.
+// A figure whose image has gone missing is left out rather than failing the document.
+func (s *ResearchService) pythonFigures(ctx context.Context, chapterNumber int, figures []sqlc.ChapterFigure, labels map[string]string) []PythonFigureData {
+	numbers := figureNumbers(figures)
+	var out []PythonFigureData
+	for _, f := range figures {
+		data := PythonFigureData{
+			Kind:    f.Kind,
+			Label:   fmt.Sprintf("%s %d.%d", labels[f.Kind], chapterNumber, numbers[f.ID.Bytes]),
+			Caption: f.Caption,
+			Marker:  apimodels.FigureMarker(f.Kind, f.ID.Bytes),
+		}
+		switch f.Kind {
+		case FigureKindFigure:
+			image, err := s.readFile(ctx, f.FilePath)
+			if err != nil {
+				s.logger.Warn("Figure image unavailable, leaving it out", "figureID", f.ID.Bytes, "error", err)
+				continue
+			}
+			data.ImageBase64 = base64.StdEncoding.EncodeToString(image)
+		case FigureKindTable:
+			_ = json.Unmarshal(f.TableData, &data.Rows) // Written by encodeTableRows
+		}
+		out = append(out, data)
+	}
+	return out
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrDocumentNotReady is returned when downloading a document still being generated, or
+// one whose generation failed
+var ErrDocumentNotReady = errors.New("document is still processing or failed generation")
+
+// projectFileKey is the storage key of a file of a project, e.g. projects/<id>/uploads/<file>
+func projectFileKey(projectID uuid.UUID, kind, name string) string {
+	return path.Join("projects", projectID.String(), kind, name)
+}
+
+// isLegacyFile tells the local paths recorded before object storage, under UPLOAD_DIR
+// or DOCGEN_OUTPUT_DIR, from storage keys
+func (s *ResearchService) isLegacyFile(ref string) bool {
+	for _, dir := range []string{s.uploads.Dir, s.docgen.OutputDir} {
+		if dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(ref))
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return filepath.IsAbs(ref)
+}
+
+// OpenFile opens a stored file by the key, or legacy path, recorded for it
+func (s *ResearchService) OpenFile(ctx context.Context, ref string) (*storage.Object, error) {
+	if !s.isLegacyFile(ref) {
+		return s.files.Open(ctx, ref)
+	}
+	f, err := os.Open(ref)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &storage.Object{ReadCloser: f, Size: info.Size()}, nil
+}
+
+// readFile reads a whole stored file, such as an image to embed in a document
+func (s *ResearchService) readFile(ctx context.Context, ref string) ([]byte, error) {
+	f, err := s.OpenFile(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// removeUploadFile deletes a stored file; a failure leaves an orphan, which is only logged
+func (s *ResearchService) removeUploadFile(ctx context.Context, ref string) {
+	var err error
+	if s.isLegacyFile(ref) {
+		if err = os.Remove(ref); errors.Is(err, os.ErrNotExist) {
+			err = nil
+		}
+	} else {
+		err = s.files.Delete(context.WithoutCancel(ctx), ref)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to remove stored file", "file", ref, "error", err)
+	}
+}
+
+// storeGeneratedFile moves a file the docgen service wrote to the shared output
+// directory into storage, returning its key and size
+func (s *ResearchService) storeGeneratedFile(ctx context.Context, doc sqlc.GeneratedDocument, localPath string) (string, int64, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not open generated file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, fmt.Errorf("could not open generated file: %w", err)
+	}
+	key := projectFileKey(doc.ProjectID.Bytes, "documents", uuid.UUID(doc.ID.Bytes).String()+"/"+filepath.Base(localPath))
+	if err := s.files.Put(ctx, key, f, info.Size(), doc.MimeType.String); err != nil {
+		return "", 0, fmt.Errorf("could not store generated file: %w", err)
+	}
+	f.Close()
+	if err := os.Remove(localPath); err != nil {
+		s.logger.Warn("Failed to remove generated file after storing it", "path", localPath, "error", err)
+	}
+	return key, info.Size(), nil
+}
+
+// DocumentDownload is a completed document ready to send: either a presigned URL to
+// redirect to, or the file opened for streaming
+type DocumentDownload struct {
+	Document sqlc.GeneratedDocument
+	URL      string
+	File     *storage.Object
+}
+
+// DownloadDocument opens a completed document of a project for download. With
+// presigned downloads configured, the client is sent straight to the store instead.
+func (s *ResearchService) DownloadDocument(ctx context.Context, projectID, documentID, userID uuid.UUID) (DocumentDownload, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return DocumentDownload{}, err
+	}
+	doc, err := s.store.GetGeneratedDocumentByID(ctx, pgtype.UUID{Bytes: documentID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return DocumentDownload{}, ErrDocumentNotFound
+		}
+		return DocumentDownload{}, fmt.Errorf("database error fetching document: %w", err)
+	}
+	if doc.ProjectID.Bytes != projectID {
+		return DocumentDownload{}, ErrDocumentNotFound
+	}
+	if doc.Status.String != "completed" {
+		return DocumentDownload{}, ErrDocumentNotReady
+	}
+
+	ref := doc.FilePath // Documents generated before object storage
+	if doc.StorageKey.Valid {
+		ref = doc.StorageKey.String
+		if s.docgen.PresignTTL > 0 {
+			url, err := s.files.PresignGet(ctx, ref, doc.FileName, s.docgen.PresignTTL)
+			if err == nil {
+				return DocumentDownload{Document: doc, URL: url}, nil
+			}
+			if !errors.Is(err, storage.ErrPresignUnsupported) {
+				return DocumentDownload{}, fmt.Errorf("could not presign document download: %w", err)
+			}
+		}
+	}
+	file, err := s.OpenFile(ctx, ref)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.logger.Error("Document file missing from storage", "documentID", documentID, "file", ref)
+			return DocumentDownload{}, ErrDocumentNotFound
+		}
+		return DocumentDownload{}, fmt.Errorf("could not open document: %w", err)
+	}
+	return DocumentDownload{Document: doc, File: file}, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// frontMatterPages are the pages the formatting options can put before the first chapter
+var frontMatterPages = []string{"title_page", "declaration", "dedication", "acknowledgments", "table_of_contents", "list_of_figures", "list_of_tables"}
+
+// GetFrontMatter returns the project's front-matter text. Requires read access.
+func (s *ResearchService) GetFrontMatter(ctx context.Context, projectID, userID uuid.UUID) (apimodels.FrontMatter, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.FrontMatter{}, err
+	}
+	return s.projectFrontMatter(ctx, project)
+}
+
+// UpdateFrontMatter replaces the project's front-matter text. Requires the edit role.
+func (s *ResearchService) UpdateFrontMatter(ctx context.Context, projectID, userID uuid.UUID, fm apimodels.FrontMatter) (apimodels.FrontMatter, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.FrontMatter{}, err
+	}
+	fm.Degree = strings.TrimSpace(fm.Degree)
+	fm.Supervisor = strings.TrimSpace(fm.Supervisor)
+	fm.Declaration = strings.TrimSpace(fm.Declaration)
+	fm.Dedication = strings.TrimSpace(fm.Dedication)
+	fm.Acknowledgments = strings.TrimSpace(fm.Acknowledgments)
+	encoded, err := json.Marshal(fm)
+	if err != nil {
+		return apimodels.FrontMatter{}, err
+	}
+	if err := s.store.UpsertProjectFrontMatter(ctx, sqlc.UpsertProjectFrontMatterParams{ProjectID: project.ID, Content: encoded}); err != nil {
+		return apimodels.FrontMatter{}, fmt.Errorf("could not save front matter: %w", err)
+	}
+	s.logger.Info("Front matter updated", "projectID", projectID, "userID", userID)
+	return fm, nil
+}
+
+// projectFrontMatter is the project's front-matter text, empty until it is first saved
+func (s *ResearchService) projectFrontMatter(ctx context.Context, project sqlc.ResearchProject) (apimodels.FrontMatter, error) {
+	var fm apimodels.FrontMatter
+	stored, err := s.store.GetProjectFrontMatter(ctx, project.ID)
+	if err != nil {
+		if isNoRows(err) {
+			return fm, nil
+		}
+		return fm, fmt.Errorf("could not load front matter: %w", err)
+	}
+	if err := json.Unmarshal(stored, &fm); err != nil {
+		return fm, fmt.Errorf("could not decode front matter: %w", err)
+	}
+	return fm, nil
+}
+
+// studentName is the name on a project's documents: its owner's, from their profile.
+// Empty when it cannot be loaded, which leaves it off the title page.
+func (s *ResearchService) studentName(ctx context.Context, project sqlc.ResearchProject) string {
+	owner, err := s.store.GetUserByID(ctx, project.UserID)
+	if err != nil {
+		s.logger.Warn("Could not load the project owner's name for a document", "projectID", uuid.UUID(project.ID.Bytes), "error", err)
+		return ""
+	}
+	return strings.TrimSpace(owner.FirstName + " " + owner.LastName)
+}
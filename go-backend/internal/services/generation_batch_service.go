@@ -0,0 +1,302 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// JobGenerateAll generates the next chapter of a "generate all chapters" run
+const JobGenerateAll = "chapters.generate_all"
+
+var (
+	ErrGenerationBatchNotFound = errors.New("generation run not found")
+	ErrGenerationBatchRunning  = errors.New("the project's chapters are already being generated")
+	ErrNothingToGenerate       = errors.New("the project has no chapters to generate; approved chapters and those in review are kept as they are")
+)
+
+// Statuses of a generation run and of each of its chapters
+const (
+	GenerationBatchPending   = "pending"
+	GenerationBatchRunning   = "running"
+	GenerationBatchCompleted = "completed"
+	GenerationBatchFailed    = "failed"
+	GenerationBatchSkipped   = "skipped" // Chapters only
+)
+
+// generateAllJob is the payload of JobGenerateAll
+type generateAllJob struct {
+	BatchID   uuid.UUID `json:"batch_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	UserID    uuid.UUID `json:"user_id"` // Who started the run, whom the chapters are generated for
+}
+
+// GenerateAllChapters queues the generation of every chapter of a project, one after
+// another in dependency order, skipping approved chapters and those in review. The run's
+// progress is read with GetGenerationBatch; each chapter also reports its own progress
+// events as it is generated. Requires the edit role, and is refused where the
+// integrity policy blocks batch generation.
+func (s *ResearchService) GenerateAllChapters(ctx context.Context, projectID, userID uuid.UUID) (sqlc.GenerationBatch, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.GenerationBatch{}, err
+	}
+	if err := s.checkBatchGeneration(ctx, project); err != nil {
+		return sqlc.GenerationBatch{}, err
+	}
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return sqlc.GenerationBatch{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.GenerationBatch{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	plan := generateAllPlan(chapters)
+	if !slices.ContainsFunc(plan, func(c apimodels.GenerationBatchChapter) bool { return c.Status == GenerationBatchPending }) {
+		return sqlc.GenerationBatch{}, ErrNothingToGenerate
+	}
+	encoded, err := json.Marshal(plan)
+	if err != nil {
+		return sqlc.GenerationBatch{}, err
+	}
+
+	batch, err := s.store.CreateGenerationBatch(ctx, sqlc.CreateGenerationBatchParams{
+		ProjectID: project.ID,
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		Chapters:  encoded,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return sqlc.GenerationBatch{}, ErrGenerationBatchRunning
+		}
+		return sqlc.GenerationBatch{}, fmt.Errorf("could not create generation run: %w", err)
+	}
+	if err := s.jobs.Enqueue(ctx, JobGenerateAll, generateAllJob{BatchID: batch.ID.Bytes, ProjectID: projectID, UserID: userID}); err != nil {
+		if saveErr := s.saveGenerationBatch(context.WithoutCancel(ctx), batch.ID, GenerationBatchFailed, plan, "Could not queue chapter generation"); saveErr != nil {
+			s.logger.Error("Failed to mark generation run failed", "batchID", batch.ID.Bytes, "error", saveErr)
+		}
+		return sqlc.GenerationBatch{}, fmt.Errorf("could not queue chapter generation: %w", err)
+	}
+	s.logger.Info("Generation of all chapters queued", "projectID", projectID, "batchID", batch.ID.Bytes, "chapters", len(plan))
+	return batch, nil
+}
+
+// GetGenerationBatch returns a "generate all chapters" run with the progress of each
+// chapter. Requires read access.
+func (s *ResearchService) GetGenerationBatch(ctx context.Context, projectID, batchID, userID uuid.UUID) (sqlc.GenerationBatch, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return sqlc.GenerationBatch{}, err
+	}
+	batch, err := s.store.GetGenerationBatch(ctx, sqlc.GetGenerationBatchParams{
+		ID:        pgtype.UUID{Bytes: batchID, Valid: true},
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.GenerationBatch{}, ErrGenerationBatchNotFound
+		}
+		return sqlc.GenerationBatch{}, fmt.Errorf("database error fetching generation run: %w", err)
+	}
+	return batch, nil
+}
+
+// generateAllPlan lists a project's chapters in the order a run generates them: the
+// literature review first, as the introduction draws on it, then the introduction,
+// methodology and results, the other chapters in document order, and last the executive
+// summary, written from all of them. Chapters kept as they are start out skipped.
+func generateAllPlan(chapters []sqlc.Chapter) []apimodels.GenerationBatchChapter {
+	ordered := slices.Clone(chapters)
+	slices.SortStableFunc(ordered, func(a, b sqlc.Chapter) int {
+		return cmp.Or(cmp.Compare(generateAllRank(a.Type), generateAllRank(b.Type)), cmp.Compare(a.Position, b.Position))
+	})
+	plan := make([]apimodels.GenerationBatchChapter, len(ordered))
+	for i, ch := range ordered {
+		plan[i] = apimodels.GenerationBatchChapter{
+			ChapterID: ch.ID.Bytes,
+			Type:      ch.Type,
+			Title:     ch.Title,
+			Status:    GenerationBatchPending,
+		}
+		if reason := generateAllSkip(ch); reason != "" {
+			plan[i].Status, plan[i].Reason = GenerationBatchSkipped, reason
+		}
+	}
+	return plan
+}
+
+func generateAllRank(chapterType string) int {
+	switch chapterType {
+	case "literature_review":
+		return 0
+	case "introduction":
+		return 1
+	case "methodology":
+		return 2
+	case "results":
+		return 3
+	case ChapterTypeExecutiveSummary:
+		return 5
+	}
+	return 4
+}
+
+// generateAllSkip is why a run leaves a chapter as it is, if it does
+func generateAllSkip(ch sqlc.Chapter) string {
+	switch ch.Status.String {
+	case ChapterStatusApproved:
+		return "approved chapters are kept"
+	case ChapterStatusInReview:
+		return ErrChapterInReview.Error()
+	}
+	return ""
+}
+
+// runGenerateAllJob generates the next chapter of a run and queues the job again for the
+// one after, so each chapter has the job's full timeout. A chapter that fails is recorded
+// and the run moves on; while the AI provider is down the job is retried instead, and the
+// run fails once it is out of attempts.
+func (s *ResearchService) runGenerateAllJob(ctx context.Context, job jobs.Job) error {
+	var payload generateAllJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	batch, err := s.store.GetGenerationBatch(db.WithPrimary(ctx), sqlc.GetGenerationBatchParams{
+		ID:        pgtype.UUID{Bytes: payload.BatchID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: payload.ProjectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) { // Deleted along with its project
+			return nil
+		}
+		return fmt.Errorf("could not load generation run: %w", err)
+	}
+	if batch.Status == GenerationBatchCompleted || batch.Status == GenerationBatchFailed {
+		return nil
+	}
+	var plan []apimodels.GenerationBatchChapter
+	if err := json.Unmarshal(batch.Chapters, &plan); err != nil {
+		return jobs.Permanent(fmt.Errorf("could not decode generation run: %w", err))
+	}
+	unfinished := func(c apimodels.GenerationBatchChapter) bool {
+		return c.Status == GenerationBatchPending || c.Status == GenerationBatchRunning // Running when an earlier attempt was interrupted
+	}
+
+	next := slices.IndexFunc(plan, unfinished)
+	if next >= 0 {
+		plan[next].Status = GenerationBatchRunning
+		if err := s.saveGenerationBatch(ctx, batch.ID, GenerationBatchRunning, plan, ""); err != nil {
+			return err
+		}
+		status, reason, err := s.generateBatchChapter(ctx, payload, plan[next])
+		if err != nil {
+			if job.LastAttempt() {
+				s.failGenerationBatch(ctx, batch.ID, plan, err)
+			}
+			return err
+		}
+		plan[next].Status, plan[next].Reason = status, reason
+		_, emit := s.progressEmitter(ctx, payload.ProjectID, &plan[next].ChapterID, nil)
+		emit(events.Event{Type: events.GenerationProgress, Message: fmt.Sprintf("Chapter %d of %d %s", next+1, len(plan), status), Step: next + 1, TotalSteps: len(plan)})
+	}
+
+	if !slices.ContainsFunc(plan, unfinished) {
+		if err := s.saveGenerationBatch(ctx, batch.ID, GenerationBatchCompleted, plan, ""); err != nil {
+			return err
+		}
+		s.logger.Info("Generation of all chapters finished", "projectID", payload.ProjectID, "batchID", payload.BatchID)
+		return nil
+	}
+	if err := s.saveGenerationBatch(ctx, batch.ID, GenerationBatchRunning, plan, ""); err != nil {
+		return err
+	}
+	if err := s.jobs.Enqueue(ctx, JobGenerateAll, payload); err != nil {
+		if job.LastAttempt() {
+			s.failGenerationBatch(ctx, batch.ID, plan, err)
+		}
+		return err // A retry generates the next chapter itself
+	}
+	return nil
+}
+
+// generateBatchChapter generates one chapter of a run, returning the status and reason to
+// record for it. Errors are returned only for failures worth retrying the chapter for:
+// the AI provider being down, or the job being interrupted.
+func (s *ResearchService) generateBatchChapter(ctx context.Context, payload generateAllJob, entry apimodels.GenerationBatchChapter) (string, string, error) {
+	chapter, err := s.store.GetProjectChapterByID(db.WithPrimary(ctx), sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: entry.ChapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: payload.ProjectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return GenerationBatchSkipped, "the chapter was deleted", nil
+		}
+		return "", "", fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	if reason := generateAllSkip(chapter); reason != "" { // Approved or submitted since the run started
+		return GenerationBatchSkipped, reason, nil
+	}
+
+	if entry.Type == ChapterTypeExecutiveSummary {
+		_, err = s.GenerateExecutiveSummary(ctx, payload.ProjectID, payload.UserID, apimodels.GenerateExecutiveSummaryRequest{})
+	} else {
+		_, err = s.GenerateChapterContent(ctx, payload.ProjectID, entry.ChapterID, payload.UserID, entry.Type, GenerationOptions{})
+	}
+	switch {
+	case err == nil:
+		return GenerationBatchCompleted, "", nil
+	case errors.Is(err, breaker.ErrOpen) || ctx.Err() != nil:
+		return "", "", err
+	case errors.Is(err, ErrChapterNotFound):
+		return GenerationBatchSkipped, "the chapter was deleted", nil
+	case errors.Is(err, ErrChapterInReview), errors.Is(err, ErrNoResultsData), errors.Is(err, ErrNoThesisContent):
+		return GenerationBatchSkipped, err.Error(), nil
+	}
+	s.logger.Warn("Chapter generation failed in a generate-all run", "batchID", payload.BatchID, "chapterID", entry.ChapterID, "error", err)
+	return GenerationBatchFailed, err.Error(), nil
+}
+
+// failGenerationBatch ends a run that is out of attempts, failing its unfinished chapters
+// with cause
+func (s *ResearchService) failGenerationBatch(ctx context.Context, batchID pgtype.UUID, plan []apimodels.GenerationBatchChapter, cause error) {
+	for i, c := range plan {
+		if c.Status == GenerationBatchPending || c.Status == GenerationBatchRunning {
+			plan[i].Status, plan[i].Reason = GenerationBatchFailed, cause.Error()
+		}
+	}
+	if err := s.saveGenerationBatch(context.WithoutCancel(ctx), batchID, GenerationBatchFailed, plan, cause.Error()); err != nil {
+		s.logger.Error("Failed to mark generation run failed", "batchID", batchID.Bytes, "error", err)
+	}
+}
+
+func (s *ResearchService) saveGenerationBatch(ctx context.Context, batchID pgtype.UUID, status string, plan []apimodels.GenerationBatchChapter, failure string) error {
+	encoded, err := json.Marshal(plan)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.UpdateGenerationBatch(ctx, sqlc.UpdateGenerationBatchParams{
+		ID:       batchID,
+		Status:   status,
+		Chapters: encoded,
+		Error:    pgtype.Text{String: failure, Valid: failure != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("could not save generation progress: %w", err)
+	}
+	return nil
+}
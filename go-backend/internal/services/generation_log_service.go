@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// What the generation history records a call as, besides the chapter types
+const (
+	GenerationKindSection           = "section"
+	GenerationKindChapterEvaluation = "chapter_evaluation"
+	GenerationKindExecutiveSummary  = "executive_summary"
+	GenerationKindAnalysis          = "analysis_interpretation"
+	GenerationKindDefenseQuestions  = "defense_questions"
+	GenerationKindQuestionnaire     = "questionnaire_items"
+	GenerationKindTimeline          = "timeline"
+	GenerationKindFormattingRules   = "formatting_rules"
+)
+
+// Outcomes of a recorded call
+const (
+	GenerationSucceeded = "success"
+	GenerationFailed    = "error"
+	GenerationLeaked    = "leak"   // The reply repeated its instructions and was discarded
+	GenerationCached    = "cached" // The reply to an identical request made shortly before was reused
+)
+
+const (
+	maxLoggedPromptMessage = 20000 // Characters of each message kept
+	maxLoggedResponse      = 4000  // Characters of the reply kept
+	maxLoggedError         = 1000
+	generationLogTimeout   = 5 * time.Second
+)
+
+// generationCall is one call to the AI, as callOpenAI reports it to the generation log
+type generationCall struct {
+	Request  OpenAIRequest   // As built, before the prompt guard and its one-off canary
+	Response *OpenAIResponse // Nil when no reply was read
+	Latency  time.Duration
+	Cached   bool // Response was reused rather than asked for
+	Err      error
+}
+
+type generationLogKey struct{}
+
+// withGenerationLog returns a context whose AI calls are kept in the generation history
+// as kind, for the user and, when set, a project and one of its chapters
+func (s *ResearchService) withGenerationLog(ctx context.Context, kind string, userID uuid.UUID, projectID, chapterID *uuid.UUID) context.Context {
+	scope := sqlc.CreateGenerationParams{Kind: kind}
+	if userID != uuid.Nil {
+		scope.UserID = pgtype.UUID{Bytes: userID, Valid: true}
+	}
+	if projectID != nil {
+		scope.ProjectID = pgtype.UUID{Bytes: *projectID, Valid: true}
+	}
+	if chapterID != nil {
+		scope.ChapterID = pgtype.UUID{Bytes: *chapterID, Valid: true}
+	}
+	return context.WithValue(ctx, generationLogKey{}, func(ctx context.Context, call generationCall) {
+		s.recordGeneration(ctx, scope, call)
+	})
+}
+
+// logGeneration reports a call to the generation log attached to ctx, if any
+func logGeneration(ctx context.Context, call generationCall) {
+	if fn, ok := ctx.Value(generationLogKey{}).(func(context.Context, generationCall)); ok {
+		fn(ctx, call)
+	}
+}
+
+// recordGeneration saves a call. The history is an aid, so a call it fails to save is
+// logged rather than failing the generation.
+func (s *ResearchService) recordGeneration(ctx context.Context, params sqlc.CreateGenerationParams, call generationCall) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), generationLogTimeout) // Also when the request was cancelled
+	defer cancel()
+
+	messages := make([]OpenAIMessage, len(call.Request.Messages))
+	for i, m := range call.Request.Messages {
+		messages[i] = OpenAIMessage{Role: m.Role, Content: truncateRunes(m.Content, maxLoggedPromptMessage)}
+	}
+	prompt, err := json.Marshal(messages)
+	if err != nil {
+		s.logger.Error("Failed to encode generation prompt", "error", err)
+		return
+	}
+	params.Model = call.Request.Model
+	params.Temperature = call.Request.Temperature
+	params.MaxTokens = int32(call.Request.MaxTokens)
+	params.PromptHash = promptHash(call.Request)
+	params.Prompt = prompt
+	params.LatencyMs = int32(call.Latency.Milliseconds())
+	if call.Response != nil {
+		if len(call.Response.Choices) > 0 {
+			params.Response = truncateRunes(call.Response.Choices[0].Message.Content, maxLoggedResponse)
+		}
+		if !call.Cached { // Only tokens spent count
+			params.PromptTokens = int32(call.Response.Usage.PromptTokens)
+			params.CompletionTokens = int32(call.Response.Usage.CompletionTokens)
+		}
+	}
+	switch {
+	case call.Err == nil && call.Cached:
+		params.Outcome = GenerationCached
+	case call.Err == nil:
+		params.Outcome = GenerationSucceeded
+	case errors.Is(call.Err, ErrPromptLeak):
+		params.Outcome = GenerationLeaked
+	default:
+		params.Outcome = GenerationFailed
+	}
+	if call.Err != nil {
+		params.Error = pgtype.Text{String: truncateRunes(call.Err.Error(), maxLoggedError), Valid: true}
+	}
+	if err := s.store.CreateGeneration(ctx, params); err != nil {
+		s.logger.Error("Failed to record generation", "kind", params.Kind, "error", err)
+	}
+}
+
+// promptHash identifies a prompt by its messages, whole
+func promptHash(request OpenAIRequest) string {
+	h := sha256.New()
+	for _, m := range request.Messages {
+		fmt.Fprintf(h, "%s\x00%s\x00", m.Role, m.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ListChapterGenerations returns the latest AI calls made for a chapter, newest first.
+// Requires read access.
+func (s *ResearchService) ListChapterGenerations(ctx context.Context, projectID, chapterID, userID uuid.UUID, limit int32) ([]sqlc.Generation, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return nil, ErrChapterNotFound
+		}
+		return nil, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	generations, err := s.store.ListChapterGenerations(ctx, sqlc.ListChapterGenerationsParams{ChapterID: chapter.ID, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("database error listing generations: %w", err)
+	}
+	return generations, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Generation preference values
+const (
+	ReadingLevelSecondary     = "secondary"
+	ReadingLevelUndergraduate = "undergraduate"
+	ReadingLevelPostgraduate  = "postgraduate"
+	ReadingLevelExpert        = "expert"
+
+	EnglishVariantUS = "us"
+	EnglishVariantUK = "uk"
+
+	VerbosityConcise  = "concise"
+	VerbosityBalanced = "balanced"
+	VerbosityDetailed = "detailed"
+)
+
+// defaultGenerationPreferences are what the prompts are written for; a temperature left
+// unset keeps each kind of writing's own
+var defaultGenerationPreferences = apimodels.GenerationPreferences{
+	ReadingLevel: ReadingLevelPostgraduate,
+	Verbosity:    VerbosityBalanced,
+}
+
+// GetUserGenerationPreferences returns the user's own generation preferences
+func (s *ResearchService) GetUserGenerationPreferences(ctx context.Context, userID uuid.UUID) (apimodels.GenerationPreferencesResponse, error) {
+	own, err := s.userGenerationPreferences(ctx, userID)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	return apimodels.GenerationPreferencesResponse{
+		Preferences: own,
+		Effective:   mergeGenerationPreferences(defaultGenerationPreferences, own),
+	}, nil
+}
+
+// UpdateUserGenerationPreferences replaces the user's generation preferences; the fields
+// left out take the defaults
+func (s *ResearchService) UpdateUserGenerationPreferences(ctx context.Context, userID uuid.UUID, prefs apimodels.GenerationPreferences) (apimodels.GenerationPreferencesResponse, error) {
+	encoded, err := json.Marshal(prefs)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	err = s.store.UpsertUserGenerationPreferences(ctx, sqlc.UpsertUserGenerationPreferencesParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		Preferences: encoded,
+	})
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, fmt.Errorf("could not save generation preferences: %w", err)
+	}
+	s.logger.Info("Generation preferences updated", "userID", userID)
+	return s.GetUserGenerationPreferences(ctx, userID)
+}
+
+// GetProjectGenerationPreferences returns the project's generation preferences, and
+// those the user's generations in it use. Requires read access.
+func (s *ResearchService) GetProjectGenerationPreferences(ctx context.Context, projectID, userID uuid.UUID) (apimodels.GenerationPreferencesResponse, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	return s.projectGenerationPreferencesResponse(ctx, project, userID)
+}
+
+// UpdateProjectGenerationPreferences replaces the project's generation preferences,
+// which apply over each member's own; the fields left out fall back to them. Requires
+// the edit role.
+func (s *ResearchService) UpdateProjectGenerationPreferences(ctx context.Context, projectID, userID uuid.UUID, prefs apimodels.GenerationPreferences) (apimodels.GenerationPreferencesResponse, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	encoded, err := json.Marshal(prefs)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	err = s.store.UpsertProjectGenerationPreferences(ctx, sqlc.UpsertProjectGenerationPreferencesParams{
+		ProjectID:   project.ID,
+		Preferences: encoded,
+	})
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, fmt.Errorf("could not save generation preferences: %w", err)
+	}
+	s.logger.Info("Project generation preferences updated", "projectID", projectID, "userID", userID)
+	return s.projectGenerationPreferencesResponse(ctx, project, userID)
+}
+
+func (s *ResearchService) projectGenerationPreferencesResponse(ctx context.Context, project sqlc.ResearchProject, userID uuid.UUID) (apimodels.GenerationPreferencesResponse, error) {
+	own, err := s.storedProjectGenerationPreferences(ctx, project)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	user, err := s.userGenerationPreferences(ctx, userID)
+	if err != nil {
+		return apimodels.GenerationPreferencesResponse{}, err
+	}
+	return apimodels.GenerationPreferencesResponse{
+		Preferences: own,
+		Effective:   mergeGenerationPreferences(mergeGenerationPreferences(defaultGenerationPreferences, user), own),
+	}, nil
+}
+
+func (s *ResearchService) userGenerationPreferences(ctx context.Context, userID uuid.UUID) (apimodels.GenerationPreferences, error) {
+	stored, err := s.store.GetUserGenerationPreferences(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	return decodeGenerationPreferences(stored, err)
+}
+
+func (s *ResearchService) storedProjectGenerationPreferences(ctx context.Context, project sqlc.ResearchProject) (apimodels.GenerationPreferences, error) {
+	stored, err := s.store.GetProjectGenerationPreferences(ctx, project.ID)
+	return decodeGenerationPreferences(stored, err)
+}
+
+func decodeGenerationPreferences(stored []byte, err error) (apimodels.GenerationPreferences, error) {
+	var prefs apimodels.GenerationPreferences
+	switch {
+	case isNoRows(err):
+		return prefs, nil
+	case err != nil:
+		return prefs, fmt.Errorf("could not load generation preferences: %w", err)
+	}
+	if err := json.Unmarshal(stored, &prefs); err != nil {
+		return prefs, fmt.Errorf("could not decode generation preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// mergeGenerationPreferences sets the fields over sets on base
+func mergeGenerationPreferences(base, over apimodels.GenerationPreferences) apimodels.GenerationPreferences {
+	if over.Temperature != nil {
+		base.Temperature = over.Temperature
+	}
+	if over.ReadingLevel != "" {
+		base.ReadingLevel = over.ReadingLevel
+	}
+	if over.EnglishVariant != "" {
+		base.EnglishVariant = over.EnglishVariant
+	}
+	if over.Verbosity != "" {
+		base.Verbosity = over.Verbosity
+	}
+	return base
+}
+
+// withGenerationPreferences has the AI write what ctx generates with the user's
+// preferences and, for a project, the project's over them
+func (s *ResearchService) withGenerationPreferences(ctx context.Context, project *sqlc.ResearchProject, userID uuid.UUID) (context.Context, error) {
+	prefs, err := s.userGenerationPreferences(ctx, userID)
+	if err != nil {
+		return ctx, err
+	}
+	if project != nil {
+		own, err := s.storedProjectGenerationPreferences(ctx, *project)
+		if err != nil {
+			return ctx, err
+		}
+		prefs = mergeGenerationPreferences(prefs, own)
+	}
+	return context.WithValue(ctx, generationPreferencesKey{}, prefs), nil
+}
+
+type generationPreferencesKey struct{}
+
+// applyGenerationPreferences sets a prose request's temperature and adds the style the
+// preferences in ctx ask for to its system message
+func applyGenerationPreferences(ctx context.Context, request *OpenAIRequest) {
+	prefs, ok := ctx.Value(generationPreferencesKey{}).(apimodels.GenerationPreferences)
+	if !ok || !request.prose {
+		return
+	}
+	if prefs.Temperature != nil {
+		request.Temperature = *prefs.Temperature
+	}
+	style := generationStyle(prefs)
+	if style == "" {
+		return
+	}
+	messages := make([]OpenAIMessage, len(request.Messages))
+	copy(messages, request.Messages)
+	for i, m := range messages {
+		if m.Role == "system" {
+			messages[i].Content += "\n\n" + style
+			request.Messages = messages
+			return
+		}
+	}
+	request.Messages = append([]OpenAIMessage{{Role: "system", Content: style}}, messages...)
+}
+
+// generationStyle words the preferences that differ from what the prompts are written
+// for as instructions
+func generationStyle(prefs apimodels.GenerationPreferences) string {
+	var lines []string
+	switch prefs.ReadingLevel {
+	case ReadingLevelSecondary:
+		lines = append(lines, "Write for readers at secondary-school level: plain vocabulary, short sentences, and every technical term explained.")
+	case ReadingLevelUndergraduate:
+		lines = append(lines, "Write for undergraduate readers: clear academic prose, with technical terms defined when first used.")
+	case ReadingLevelExpert:
+		lines = append(lines, "Write for specialists in the field: precise, technical prose that assumes the discipline's vocabulary and methods.")
+	}
+	switch prefs.EnglishVariant {
+	case EnglishVariantUK:
+		lines = append(lines, "When writing in English, use British spelling and usage (e.g., analyse, behaviour, organisation).")
+	case EnglishVariantUS:
+		lines = append(lines, "When writing in English, use American spelling and usage (e.g., analyze, behavior, organization).")
+	}
+	switch prefs.Verbosity {
+	case VerbosityConcise:
+		lines = append(lines, "Be concise within the length asked for: make each point once, keep paragraphs short and cut filler.")
+	case VerbosityDetailed:
+		lines = append(lines, "Be thorough within the length asked for: develop each point fully, with explanation and examples.")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// JobRetryGeneration runs a chapter or section generation again after its AI call failed
+const JobRetryGeneration = "generation.retry"
+
+// ErrGenerationRetrying is returned for a generation whose AI call failed and which has
+// been queued to run again in the background
+var ErrGenerationRetrying = errors.New("AI generation failed and will be retried in the background")
+
+// errGenerationRetryFailed marks a retry that failed in the AI call again, which the
+// queue retries in turn; any other failure ends the retries
+var errGenerationRetryFailed = errors.New("generation retry failed")
+
+const (
+	generationRetryDelay    = time.Minute // Before the first retry; the queue backs off after that
+	generationRetryAttempts = 4
+)
+
+// generationRetryJob is the payload of JobRetryGeneration
+type generationRetryJob struct {
+	ProjectID       uuid.UUID  `json:"project_id"`
+	ChapterID       uuid.UUID  `json:"chapter_id"`
+	ChapterType     string     `json:"chapter_type,omitempty"` // Set when the whole chapter was being generated
+	SectionID       *uuid.UUID `json:"section_id,omitempty"`   // Set when one of its sections was
+	UserID          uuid.UUID  `json:"user_id"`                // Who asked for the generation
+	ReferenceTag    string     `json:"reference_tag,omitempty"`
+	Instructions    string     `json:"instructions,omitempty"`
+	TargetWordCount int32      `json:"target_word_count,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"` // Of the chapter or section when generation failed
+}
+
+type generationRetryKey struct{}
+
+// retryGeneration queues a generation whose AI call failed with cause to run again,
+// returning the error to report for it: ErrGenerationRetrying once it is queued. A
+// retry that fails again is left to the queue's own retries, and a generation cancelled
+// by its caller, with timeouts.CancelOnDisconnect set, is not retried.
+func (s *ResearchService) retryGeneration(ctx context.Context, job generationRetryJob, cause error) error {
+	if errors.Is(cause, ErrQuotaReached) {
+		return cause // Nothing to retry until the quota resets
+	}
+	if ctx.Value(generationRetryKey{}) != nil {
+		return fmt.Errorf("%w: %w", errGenerationRetryFailed, cause)
+	}
+	if errors.Is(cause, breaker.ErrOpen) {
+		return cause // The provider is down; the user is told when to try again instead
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return cause
+	}
+	// Queued also when the generation timed out, its context done
+	if err := s.jobs.EnqueueIn(context.WithoutCancel(ctx), JobRetryGeneration, generationRetryDelay, job); err != nil {
+		s.logger.Error("Failed to queue generation retry", "chapterID", job.ChapterID, "sectionID", job.SectionID, "error", err)
+		return cause
+	}
+	s.logger.Info("Generation retry queued", "chapterID", job.ChapterID, "sectionID", job.SectionID, "cause", cause)
+	return fmt.Errorf("%w: %v", ErrGenerationRetrying, cause)
+}
+
+// runGenerationRetryJob generates a chapter or section again, saving it as a generation
+// asked for by the user would be. A chapter or section changed since the failure is
+// left as it is, as are those whose generation now fails for another reason than the
+// AI call, such as the user's access or quota.
+func (s *ResearchService) runGenerationRetryJob(ctx context.Context, job jobs.Job) error {
+	var payload generationRetryJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	title, updatedAt, err := s.generationRetryTarget(db.WithPrimary(ctx), payload)
+	if err != nil {
+		if errors.Is(err, ErrChapterNotFound) || errors.Is(err, ErrSectionNotFound) {
+			return nil // Deleted since
+		}
+		return err
+	}
+	if !updatedAt.Equal(payload.UpdatedAt) {
+		s.logger.Info("Generation retry dropped, its target changed since", "chapterID", payload.ChapterID, "sectionID", payload.SectionID)
+		return nil
+	}
+
+	ctx = context.WithValue(ctx, generationRetryKey{}, true)
+	if payload.SectionID != nil {
+		_, err = s.GenerateSection(ctx, payload.ProjectID, payload.ChapterID, *payload.SectionID, payload.UserID, apimodels.GenerateSectionRequest{
+			Instructions:    payload.Instructions,
+			TargetWordCount: payload.TargetWordCount,
+		})
+	} else {
+		_, err = s.GenerateChapterContent(ctx, payload.ProjectID, payload.ChapterID, payload.UserID, payload.ChapterType, GenerationOptions{ReferenceTag: payload.ReferenceTag})
+	}
+	switch {
+	case err == nil:
+		if payload.SectionID != nil { // Chapter generations notify of themselves
+			s.notifyGenerationRetry(ctx, payload, NotificationGenerationCompleted,
+				fmt.Sprintf("%s has been generated", title),
+				fmt.Sprintf("AI generated content was added to %s after an earlier attempt failed.", title))
+		}
+		return nil
+	case errors.Is(err, errGenerationRetryFailed) && !job.LastAttempt():
+		return err
+	}
+	s.notifyGenerationRetry(ctx, payload, NotificationGenerationFailed,
+		fmt.Sprintf("%s could not be generated", title),
+		fmt.Sprintf("Generating %s failed and retrying did not help: %s", title, err))
+	if !errors.Is(err, errGenerationRetryFailed) {
+		return jobs.Permanent(err)
+	}
+	return err
+}
+
+// generationRetryTarget returns the title of the chapter or section a retry generates,
+// and when it was last changed
+func (s *ResearchService) generationRetryTarget(ctx context.Context, payload generationRetryJob) (string, time.Time, error) {
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: payload.ChapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: payload.ProjectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return "", time.Time{}, ErrChapterNotFound
+		}
+		return "", time.Time{}, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	if payload.SectionID == nil {
+		return chapter.Title, chapter.UpdatedAt.Time, nil
+	}
+	section, err := s.chapterSection(ctx, chapter, *payload.SectionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return section.Title, section.UpdatedAt.Time, nil
+}
+
+func (s *ResearchService) notifyGenerationRetry(ctx context.Context, payload generationRetryJob, kind, title, body string) {
+	chapterID := payload.ChapterID
+	s.notifications.Notify(ctx, Notification{
+		Type:      kind,
+		ProjectID: payload.ProjectID,
+		ChapterID: &chapterID,
+		Title:     title,
+		Body:      body,
+	}, payload.UserID)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// JobPurgeGuests deletes guests past their expiry, with their projects and files
+const JobPurgeGuests = "guests.purge"
+
+// guestEmailDomain holds the placeholder addresses of guests; .invalid never resolves
+const guestEmailDomain = "guest.invalid"
+
+// isGuestEmail reports whether email is the placeholder address of a guest
+func isGuestEmail(email string) bool {
+	return strings.HasSuffix(email, "@"+guestEmailDomain)
+}
+
+// isGuest reports whether the user is a guest that has not registered yet
+func isGuest(ctx context.Context, store db.Store, userID uuid.UUID) (bool, error) {
+	user, err := store.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("database error fetching user: %w", err)
+	}
+	return user.GuestExpiresAt.Valid, nil
+}
+
+// CreateGuestProject creates the sandbox project a guest starts with, named as asked or
+// with placeholders
+func (s *ResearchService) CreateGuestProject(ctx context.Context, userID uuid.UUID, req apimodels.CreateGuestRequest) (sqlc.ResearchProject, error) {
+	project := apimodels.CreateProjectRequest{
+		Title:          req.Title,
+		Specialization: req.Specialization,
+		Language:       req.Language,
+	}
+	if project.Title == "" {
+		project.Title = "Trial project"
+	}
+	if project.Specialization == "" {
+		project.Specialization = "General"
+	}
+	return s.CreateProject(ctx, userID, project)
+}
+
+// PurgeExpiredGuests deletes the guests whose trial has run out. Their projects go with
+// them by cascade; stored files are removed once the rows are gone.
+func (s *ResearchService) PurgeExpiredGuests(ctx context.Context) error {
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	refs, err := s.store.ListExpiredGuestFiles(db.WithPrimary(ctx), now)
+	if err != nil {
+		return fmt.Errorf("could not list files of expired guests: %w", err)
+	}
+	if err := s.store.DeleteExpiredGuests(ctx, now); err != nil {
+		return fmt.Errorf("could not delete expired guests: %w", err)
+	}
+	for _, ref := range refs {
+		s.removeUploadFile(ctx, ref)
+	}
+	if len(refs) > 0 {
+		s.logger.Info("Purged expired guests", "files", len(refs))
+	}
+	return nil
+}
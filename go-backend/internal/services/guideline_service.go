@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrGuidelineNotFound = errors.New("the project has no formatting guideline")
+	ErrGuidelineFileType = errors.New("guidelines must be PDF or DOCX files")
+)
+
+// JobExtractGuidelineRules turns an uploaded guideline into the project's formatting options
+const JobExtractGuidelineRules = "guidelines.extract_rules"
+
+// defaultFormattingOptions apply wherever neither a guideline nor the user set a value
+var defaultFormattingOptions = apimodels.FormattingOptions{
+	FontFamily:    "Times New Roman",
+	FontSizeMain:  12,
+	LineSpacing:   1.5,
+	CitationStyle: "APA",
+}
+
+// UploadGuideline stores a project's formatting guideline, replacing any earlier one,
+// and queues the extraction of its rules. Requires the edit role.
+func (s *ResearchService) UploadGuideline(ctx context.Context, projectID, userID uuid.UUID, fileName string, r io.Reader) (sqlc.ProjectGuideline, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.ProjectGuideline{}, err
+	}
+	fileName = cleanFileName(fileName, "guideline")
+
+	guidelineID := uuid.New()
+	filePath := projectFileKey(projectID, "guidelines", guidelineID.String())
+	stored, err := s.writeUpload(ctx, filePath, r, guidelineUploadRule)
+	if err != nil {
+		return sqlc.ProjectGuideline{}, err
+	}
+
+	var previous sqlc.ProjectGuideline
+	var guideline sqlc.ProjectGuideline
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		previous, err = q.GetProjectGuideline(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+		if err != nil && !isNoRows(err) {
+			return err
+		}
+		guideline, err = q.UpsertProjectGuideline(ctx, sqlc.UpsertProjectGuidelineParams{
+			ID:         pgtype.UUID{Bytes: guidelineID, Valid: true},
+			ProjectID:  pgtype.UUID{Bytes: projectID, Valid: true},
+			UploadedBy: pgtype.UUID{Bytes: userID, Valid: true},
+			FileName:   fileName,
+			FilePath:   filePath,
+			MimeType:   stored.MimeType,
+			FileSize:   stored.Size,
+		})
+		return err
+	})
+	if err != nil {
+		s.removeUploadFile(ctx, filePath)
+		return sqlc.ProjectGuideline{}, fmt.Errorf("could not save guideline: %w", err)
+	}
+	if previous.FilePath != "" {
+		s.removeUploadFile(ctx, previous.FilePath)
+	}
+
+	if err := s.jobs.Enqueue(ctx, JobExtractGuidelineRules, guidelineJob{GuidelineID: guidelineID}); err != nil {
+		s.setGuidelineResult(ctx, guidelineID, ExtractionFailed, "Could not queue rule extraction", nil)
+		return sqlc.ProjectGuideline{}, fmt.Errorf("could not queue rule extraction: %w", err)
+	}
+	s.logger.Info("Formatting guideline uploaded", "projectID", projectID, "guidelineID", guidelineID, "mimeType", stored.MimeType)
+	return guideline, nil
+}
+
+// GetGuideline returns the project's formatting guideline and the rules extracted from it
+func (s *ResearchService) GetGuideline(ctx context.Context, projectID, userID uuid.UUID) (sqlc.ProjectGuideline, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return sqlc.ProjectGuideline{}, err
+	}
+	guideline, err := s.store.GetProjectGuideline(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectGuideline{}, ErrGuidelineNotFound
+		}
+		return sqlc.ProjectGuideline{}, fmt.Errorf("database error fetching guideline: %w", err)
+	}
+	return guideline, nil
+}
+
+// DeleteGuideline removes the project's guideline and its file. Formatting options
+// already taken from it stay in place. Requires the edit role.
+func (s *ResearchService) DeleteGuideline(ctx context.Context, projectID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	guideline, err := s.store.DeleteProjectGuideline(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrGuidelineNotFound
+		}
+		return fmt.Errorf("could not delete guideline: %w", err)
+	}
+	s.removeUploadFile(ctx, guideline.FilePath)
+	s.logger.Info("Formatting guideline deleted", "projectID", projectID)
+	return nil
+}
+
+// guidelineJob is the payload of JobExtractGuidelineRules
+type guidelineJob struct {
+	GuidelineID uuid.UUID `json:"guideline_id"`
+}
+
+// runGuidelineJob extracts a guideline's text, has the AI read the rules from it and
+// stores them as the project's formatting options
+func (s *ResearchService) runGuidelineJob(ctx context.Context, job jobs.Job) error {
+	var payload guidelineJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	guideline, err := s.store.GetGuideline(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.GuidelineID, Valid: true})
+	if err != nil {
+		if isNoRows(err) { // Replaced or deleted before extraction ran
+			return nil
+		}
+		return fmt.Errorf("could not load guideline: %w", err)
+	}
+
+	rules, err := s.extractGuidelineRules(ctx, guideline)
+	if err != nil {
+		permanent := errors.Is(err, errUnreadableFile) || errors.Is(err, ErrQuotaReached)
+		if permanent || job.LastAttempt() {
+			s.setGuidelineResult(context.WithoutCancel(ctx), payload.GuidelineID, ExtractionFailed, err.Error(), nil)
+		}
+		if permanent {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.SetGuidelineResult(ctx, sqlc.SetGuidelineResultParams{Status: ExtractionCompleted, Rules: encoded, ID: guideline.ID}); err != nil {
+			return err
+		}
+		return q.UpsertProjectFormatting(ctx, sqlc.UpsertProjectFormattingParams{ProjectID: guideline.ProjectID, Options: encoded})
+	})
+	if err != nil {
+		return fmt.Errorf("could not save formatting rules: %w", err)
+	}
+	s.logger.Info("Formatting rules extracted", "projectID", guideline.ProjectID.Bytes, "guidelineID", payload.GuidelineID)
+	return nil
+}
+
+func (s *ResearchService) extractGuidelineRules(ctx context.Context, guideline sqlc.ProjectGuideline) (apimodels.FormattingOptions, error) {
+	extracted, err := s.extractText(ctx, guideline.FilePath, guideline.MimeType)
+	if err != nil {
+		return apimodels.FormattingOptions{}, err
+	}
+	if extracted.Text == "" {
+		return apimodels.FormattingOptions{}, fmt.Errorf("%w: the guideline has no text layer", errUnreadableFile)
+	}
+	projectID := uuid.UUID(guideline.ProjectID.Bytes)
+	ctx = s.withGenerationLog(ctx, GenerationKindFormattingRules, guideline.UploadedBy.Bytes, &projectID, nil)
+	if guideline.UploadedBy.Valid { // Charged to whoever uploaded it
+		ctx = s.quotas.withAIWords(ctx, guideline.UploadedBy.Bytes)
+	}
+	rules, err := s.aiService.ExtractFormattingRules(ctx, extracted.Text)
+	if err != nil {
+		return apimodels.FormattingOptions{}, err
+	}
+	return sanitizeFormattingOptions(rules), nil
+}
+
+func (s *ResearchService) setGuidelineResult(ctx context.Context, guidelineID uuid.UUID, status, message string, rules []byte) {
+	if rules == nil {
+		rules = []byte("{}")
+	}
+	err := s.store.SetGuidelineResult(ctx, sqlc.SetGuidelineResultParams{
+		Status: status,
+		Error:  message,
+		Rules:  rules,
+		ID:     pgtype.UUID{Bytes: guidelineID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to update guideline status", "guidelineID", guidelineID, "status", status, "error", err)
+	}
+}
+
+// sanitizeFormattingOptions drops values outside the ranges the docgen service handles,
+// as AI-extracted rules are not checked by request binding
+func sanitizeFormattingOptions(o apimodels.FormattingOptions) apimodels.FormattingOptions {
+	inRange := func(v, lo, hi float64) float64 {
+		if v < lo || v > hi {
+			return 0
+		}
+		return v
+	}
+	o.FontSizeMain = inRange(o.FontSizeMain, 8, 16)
+	o.LineSpacing = inRange(o.LineSpacing, 1, 3)
+	o.MarginTopCm = inRange(o.MarginTopCm, 0.5, 6)
+	o.MarginBottomCm = inRange(o.MarginBottomCm, 0.5, 6)
+	o.MarginLeftCm = inRange(o.MarginLeftCm, 0.5, 6)
+	o.MarginRightCm = inRange(o.MarginRightCm, 0.5, 6)
+	if !slices.Contains([]string{"A4", "Letter"}, o.PaperSize) {
+		o.PaperSize = ""
+	}
+	if !slices.Contains([]string{"arabic", "roman"}, o.ChapterNumbering) {
+		o.ChapterNumbering = ""
+	}
+	if !slices.Contains([]string{"APA", "MLA", "Harvard", "Chicago", "IEEE"}, o.CitationStyle) {
+		o.CitationStyle = ""
+	}
+	if !slices.Contains([]string{LanguageEnglish, LanguageArabic}, o.Language) {
+		o.Language = ""
+	}
+	if len(o.FontFamily) > 100 {
+		o.FontFamily = ""
+	}
+	if len(o.FontFamilyComplex) > 100 {
+		o.FontFamilyComplex = ""
+	}
+	if len(o.ChapterTitleFormat) > 100 {
+		o.ChapterTitleFormat = ""
+	}
+	var pages []string
+	for _, page := range o.FrontMatter {
+		if slices.Contains(frontMatterPages, page) && !slices.Contains(pages, page) {
+			pages = append(pages, page)
+		}
+	}
+	o.FrontMatter = pages
+	return o
+}
+
+// GetFormattingOptions returns the formatting options the project's documents are
+// generated with, defaults included. Requires read access.
+func (s *ResearchService) GetFormattingOptions(ctx context.Context, projectID, userID uuid.UUID) (apimodels.FormattingOptions, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.FormattingOptions{}, err
+	}
+	return s.projectFormatting(ctx, project)
+}
+
+// UpdateFormattingOptions replaces the project's formatting options, as uploading a
+// guideline does; the fields left out take the defaults. Every document generated after
+// is formatted with them. Requires the edit role.
+func (s *ResearchService) UpdateFormattingOptions(ctx context.Context, projectID, userID uuid.UUID, opts apimodels.FormattingOptions) (apimodels.FormattingOptions, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.FormattingOptions{}, err
+	}
+	encoded, err := json.Marshal(sanitizeFormattingOptions(opts))
+	if err != nil {
+		return apimodels.FormattingOptions{}, err
+	}
+	if err := s.store.UpsertProjectFormatting(ctx, sqlc.UpsertProjectFormattingParams{ProjectID: project.ID, Options: encoded}); err != nil {
+		return apimodels.FormattingOptions{}, fmt.Errorf("could not save formatting options: %w", err)
+	}
+	s.logger.Info("Formatting options updated", "projectID", projectID, "userID", userID)
+	return s.projectFormatting(ctx, project)
+}
+
+// projectFormatting returns the formatting options for a project's document: the
+// project's own options over the defaults. Without a language of its own the document
+// takes the project's when it has labels for it.
+func (s *ResearchService) projectFormatting(ctx context.Context, project sqlc.ResearchProject) (apimodels.FormattingOptions, error) {
+	opts := defaultFormattingOptions
+	stored, err := s.store.GetProjectFormatting(ctx, project.ID)
+	if err != nil && !isNoRows(err) {
+		return opts, fmt.Errorf("could not load formatting options: %w", err)
+	}
+	// Unmarshalling over the defaults keeps them for the keys the project leaves out
+	if err == nil {
+		if err := json.Unmarshal(stored, &opts); err != nil {
+			return opts, fmt.Errorf("could not decode formatting options: %w", err)
+		}
+	}
+	if opts.Language == "" {
+		opts.Language = LanguageEnglish
+		if _, ok := docLabels[project.Language]; ok {
+			opts.Language = project.Language
+		}
+	}
+	if opts.Language == LanguageArabic && opts.FontFamilyComplex == "" {
+		opts.FontFamilyComplex = defaultArabicFont
+	}
+	return opts, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrIntegrityAcknowledgmentRequired = errors.New("the academic-integrity policy must be acknowledged before generating content")
+	ErrBatchGenerationBlocked          = errors.New("generating a whole thesis in one batch is not allowed for institutional projects")
+)
+
+// IntegrityPolicy is the deployment's academic-integrity policy
+type IntegrityPolicy struct {
+	RequireAcknowledgment bool   // AI writing waits until the user acknowledges Version
+	Version               string // Users acknowledge each version
+	URL                   string // The policy's text
+	WatermarkExports      bool   // Exported documents mark the chapters and sections AI wrote
+	BlockBatchGeneration  bool   // Institutional projects cannot have their chapters generated in one batch
+}
+
+// IntegrityPolicy describes the policy and whether the user has acknowledged its
+// current version
+func (s *ResearchService) IntegrityPolicy(ctx context.Context, userID uuid.UUID) (apimodels.IntegrityPolicyResponse, error) {
+	resp := s.integrityPolicyResponse()
+	ack, err := s.store.GetIntegrityAcknowledgment(ctx, sqlc.GetIntegrityAcknowledgmentParams{
+		UserID:        pgtype.UUID{Bytes: userID, Valid: true},
+		PolicyVersion: s.integrity.Version,
+	})
+	switch {
+	case err == nil:
+		resp.AcknowledgedAt = &ack.AcknowledgedAt.Time
+	case !isNoRows(err):
+		return apimodels.IntegrityPolicyResponse{}, fmt.Errorf("database error fetching integrity acknowledgment: %w", err)
+	}
+	return resp, nil
+}
+
+// AcknowledgeIntegrityPolicy records that the user accepts the current version of the
+// policy. Acknowledging again refreshes the time.
+func (s *ResearchService) AcknowledgeIntegrityPolicy(ctx context.Context, userID uuid.UUID) (apimodels.IntegrityPolicyResponse, error) {
+	ack, err := s.store.AcknowledgeIntegrityPolicy(ctx, sqlc.AcknowledgeIntegrityPolicyParams{
+		UserID:        pgtype.UUID{Bytes: userID, Valid: true},
+		PolicyVersion: s.integrity.Version,
+	})
+	if err != nil {
+		return apimodels.IntegrityPolicyResponse{}, fmt.Errorf("could not record integrity acknowledgment: %w", err)
+	}
+	s.logger.Info("Integrity policy acknowledged", "userID", userID, "version", ack.PolicyVersion)
+	resp := s.integrityPolicyResponse()
+	resp.AcknowledgedAt = &ack.AcknowledgedAt.Time
+	return resp, nil
+}
+
+func (s *ResearchService) integrityPolicyResponse() apimodels.IntegrityPolicyResponse {
+	return apimodels.IntegrityPolicyResponse{
+		Version:                s.integrity.Version,
+		URL:                    s.integrity.URL,
+		AcknowledgmentRequired: s.integrity.RequireAcknowledgment,
+		WatermarkExports:       s.integrity.WatermarkExports,
+		BatchGenerationBlocked: s.integrity.BlockBatchGeneration,
+	}
+}
+
+// requireIntegrityAcknowledgment fails with ErrIntegrityAcknowledgmentRequired when the
+// policy must be acknowledged before the AI writes for the user and they have not
+// acknowledged its current version
+func (s *ResearchService) requireIntegrityAcknowledgment(ctx context.Context, userID uuid.UUID) error {
+	if !s.integrity.RequireAcknowledgment {
+		return nil
+	}
+	_, err := s.store.GetIntegrityAcknowledgment(ctx, sqlc.GetIntegrityAcknowledgmentParams{
+		UserID:        pgtype.UUID{Bytes: userID, Valid: true},
+		PolicyVersion: s.integrity.Version,
+	})
+	switch {
+	case err == nil:
+		return nil
+	case isNoRows(err):
+		return ErrIntegrityAcknowledgmentRequired
+	default:
+		return fmt.Errorf("database error fetching integrity acknowledgment: %w", err)
+	}
+}
+
+// checkBatchGeneration fails with ErrBatchGenerationBlocked when the policy keeps the
+// project's chapters from being generated in one batch: for institutional projects,
+// those of an organization or owned by a user on the institutional plan
+func (s *ResearchService) checkBatchGeneration(ctx context.Context, project sqlc.ResearchProject) error {
+	if !s.integrity.BlockBatchGeneration {
+		return nil
+	}
+	if project.OrganizationID.Valid {
+		return ErrBatchGenerationBlocked
+	}
+	plan, _, err := s.billing.CurrentPlan(ctx, project.UserID.Bytes)
+	if err != nil {
+		return err
+	}
+	if plan == PlanInstitutional {
+		return ErrBatchGenerationBlocked
+	}
+	return nil
+}
+
+// watermarkChapters marks the AI-generated text of a document's chapters and sections,
+// from their provenance, when the policy watermarks exports
+func (s *ResearchService) watermarkChapters(ctx context.Context, chapters []sqlc.Chapter, sections map[uuid.UUID][]sqlc.ChapterSection, out []PythonChapterData) error {
+	if !s.integrity.WatermarkExports {
+		return nil
+	}
+	for i, ch := range chapters {
+		share, err := chapterAIShare(ctx, s.store, ch)
+		if err != nil {
+			return err
+		}
+		out[i].AIShare = share
+		for j, sec := range sections[ch.ID.Bytes] {
+			out[i].Sections[j].AIGenerated = sec.GeneratedAt.Valid
+		}
+	}
+	return nil
+}
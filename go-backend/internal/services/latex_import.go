@@ -0,0 +1,506 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLaTeXImportFileType = errors.New("LaTeX sources must be uploaded as a ZIP archive of .tex and .bib files")
+	ErrLaTeXNoDocument     = errors.New("the archive has no .tex file with \\begin{document}")
+)
+
+var latexImportRule = uploadRule{Accept: []string{mimeZIP}, Unsupported: ErrLaTeXImportFileType}
+
+const (
+	// latexSourceLimit bounds the .tex and .bib text read from an archive, however well
+	// it compresses, and the document once its inputs are expanded
+	latexSourceLimit = 16 << 20
+	// latexInputDepth bounds how deeply \input and \include are followed
+	latexInputDepth = 8
+)
+
+// ImportLaTeX creates a project from the LaTeX sources of a thesis, uploaded as a ZIP:
+// the document is split into chapters at \chapter, or \section when it has none, and
+// the entries of its .bib files and thebibliography become the project's references.
+func (s *ResearchService) ImportLaTeX(ctx context.Context, userID uuid.UUID, fileName string, r io.Reader, req apimodels.ImportThesisRequest) (ThesisImport, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxSize(latexImportRule)+1))
+	if err != nil {
+		return ThesisImport{}, fmt.Errorf("could not read upload: %w", err)
+	}
+	if _, err := s.checkUpload(bytes.NewReader(data), int64(len(data)), latexImportRule); err != nil {
+		return ThesisImport{}, err
+	}
+	sources, err := readLaTeXSources(data)
+	if err != nil {
+		return ThesisImport{}, err
+	}
+	main := mainLaTeXFile(sources)
+	if main == "" {
+		return ThesisImport{}, ErrLaTeXNoDocument
+	}
+
+	var entries []bibEntry
+	for name, src := range sources {
+		if path.Ext(name) == ".bib" {
+			entries = append(entries, parseBibTeX(src)...)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b bibEntry) int { return strings.Compare(a.Key, b.Key) })
+	expanded, err := expandLaTeXInputs(main, sources)
+	if err != nil {
+		return ThesisImport{}, err
+	}
+	doc := parseLaTeXDocument(expanded, entries)
+
+	outline := splitThesis(doc.Blocks)
+	if outline.Abstract == "" {
+		outline.Abstract = doc.Abstract
+	}
+	outline.Skipped = append(outline.Skipped, doc.Skipped...)
+	refs := make([]sqlc.CreateReferenceParams, 0, len(entries)+len(doc.BibItems))
+	for _, e := range entries {
+		refs = append(refs, e.referenceParams())
+	}
+	for _, item := range doc.BibItems {
+		refs = append(refs, parseBibliographyEntry(item))
+	}
+	return s.importThesis(ctx, userID, fileName, doc.Title, outline, refs, req)
+}
+
+// readLaTeXSources reads the .tex and .bib files of an archive by path, with comments
+// stripped from the .tex files
+func readLaTeXSources(data []byte) (map[string]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportUnreadable, err)
+	}
+	sources := map[string]string{}
+	budget := int64(latexSourceLimit)
+	for _, f := range archive.File {
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		ext := strings.ToLower(path.Ext(name))
+		if f.FileInfo().IsDir() || (ext != ".tex" && ext != ".bib") || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrImportUnreadable, name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, budget+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrImportUnreadable, name, err)
+		}
+		if budget -= int64(len(content)); budget < 0 {
+			return nil, fmt.Errorf("%w: sources exceed %d MB", ErrImportUnreadable, latexSourceLimit>>20)
+		}
+		name = strings.TrimSuffix(name, path.Ext(name)) + ext
+		if ext == ".tex" {
+			sources[name] = stripLaTeXComments(string(content))
+		} else {
+			sources[name] = string(content)
+		}
+	}
+	return sources, nil
+}
+
+// mainLaTeXFile picks the file that holds \begin{document}: main.tex or thesis.tex if
+// several do, else the one nearest the archive's root
+func mainLaTeXFile(sources map[string]string) string {
+	var candidates []string
+	for name, src := range sources {
+		if path.Ext(name) == ".tex" && strings.Contains(src, `\begin{document}`) {
+			candidates = append(candidates, name)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b string) int {
+		if d := strings.Count(a, "/") - strings.Count(b, "/"); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	for _, name := range candidates {
+		if base := path.Base(name); base == "main.tex" || base == "thesis.tex" {
+			return name
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	return candidates[0]
+}
+
+var latexComment = regexp.MustCompile(`(?m)(^|[^\\])%.*$`)
+
+// stripLaTeXComments removes % comments and comment environments
+func stripLaTeXComments(src string) string {
+	src = latexComment.ReplaceAllString(src, "$1")
+	return removeEnvironments(src, "comment")
+}
+
+var latexInput = regexp.MustCompile(`\\(?:input|include|subfile)\s*\{([^}]+)\}`)
+
+// expandLaTeXInputs returns the main file with \input, \include and \subfile replaced
+// by the files they name, looked up next to the including file and from the archive's
+// root. A file that includes itself, directly or through others, fails the import, as
+// does a document that expands past latexSourceLimit.
+func expandLaTeXInputs(main string, sources map[string]string) (string, error) {
+	e := latexExpansion{sources: sources, stack: []string{main}, size: len(sources[main])}
+	expanded := e.expand(sources[main], path.Dir(main))
+	return expanded, e.err
+}
+
+// latexExpansion is the state of expandLaTeXInputs
+type latexExpansion struct {
+	sources map[string]string
+	stack   []string // The files being expanded, the main file first
+	size    int      // Bytes of the document expanded so far
+	err     error
+}
+
+func (e *latexExpansion) expand(src, dir string) string {
+	if len(e.stack) > latexInputDepth {
+		return src
+	}
+	return latexInput.ReplaceAllStringFunc(src, func(m string) string {
+		if e.err != nil {
+			return ""
+		}
+		name := strings.TrimSpace(latexInput.FindStringSubmatch(m)[1])
+		if path.Ext(name) != ".tex" {
+			name += ".tex"
+		}
+		for _, candidate := range []string{path.Join(dir, name), path.Clean(name)} {
+			included, ok := e.sources[candidate]
+			if !ok {
+				continue
+			}
+			if slices.Contains(e.stack, candidate) {
+				e.err = fmt.Errorf("%w: %s includes itself", ErrImportUnreadable, candidate)
+				return ""
+			}
+			if body, ok := between(included, `\begin{document}`, `\end{document}`); ok { // A subfile
+				included = body
+			}
+			if e.size += len(included); e.size > latexSourceLimit {
+				e.err = fmt.Errorf("%w: sources exceed %d MB once their inputs are expanded", ErrImportUnreadable, latexSourceLimit>>20)
+				return ""
+			}
+			e.stack = append(e.stack, candidate)
+			expanded := e.expand(included, path.Dir(candidate))
+			e.stack = e.stack[:len(e.stack)-1]
+			return "\n\n" + expanded + "\n\n"
+		}
+		return ""
+	})
+}
+
+// latexDocument is what parseLaTeXDocument reads from a document
+type latexDocument struct {
+	Title    string
+	Abstract string
+	Blocks   []outlineBlock
+	BibItems []string // Entries of thebibliography
+	Skipped  []string
+}
+
+// latexHeadingLevels are the outline levels of the sectioning commands
+var latexHeadingLevels = map[string]int{"chapter": 1, "section": 2, "subsection": 3, "subsubsection": 4, "paragraph": 5}
+
+var (
+	latexChapter   = regexp.MustCompile(`\\chapter\*?\s*[\[{]`)
+	latexHeading   = regexp.MustCompile(`\\(chapter|section|subsection|subsubsection|paragraph)\*?\s*(?:\[[^\]]*\])?\s*\{`)
+	latexBibItem   = regexp.MustCompile(`\\bibitem\s*(?:\[[^\]]*\])?\s*\{[^}]*\}`)
+	latexListItem  = regexp.MustCompile(`\\item\s*(?:\[([^\]]*)\])?`)
+	latexDropped   = regexp.MustCompile(`\\(maketitle|tableofcontents|listoffigures|listoftables|newpage|clearpage|cleardoublepage|printbibliography|frontmatter|mainmatter|backmatter|centering|noindent|medskip|bigskip|smallskip)\b|\\(bibliographystyle|bibliography|addbibresource|vspace\*?|hspace\*?|label|pagenumbering|setlength|thispagestyle|pagestyle)\s*(\[[^\]]*\])?\s*\{[^}]*\}(\{[^}]*\})?`)
+	latexBlankLine = regexp.MustCompile(`\n\s*\n`)
+)
+
+// parseLaTeXDocument reads the title, abstract and outline of a LaTeX document, citing
+// the given BibTeX entries in (Author, Year) form. Floats are left out, and so is
+// everything after \appendix.
+func parseLaTeXDocument(src string, entries []bibEntry) latexDocument {
+	var doc latexDocument
+	if title, ok := commandArgument(src, "title"); ok {
+		doc.Title = cleanLaTeX(title)
+	}
+	body, ok := between(src, `\begin{document}`, `\end{document}`)
+	if !ok {
+		body = src
+	}
+	if i := strings.Index(body, `\appendix`); i >= 0 {
+		body = body[:i]
+		doc.Skipped = append(doc.Skipped, "Appendices")
+	}
+	if abstract, ok := between(body, `\begin{abstract}`, `\end{abstract}`); ok {
+		doc.Abstract = latexParagraphs(abstract, entries)
+	}
+	if items, ok := between(body, `\begin{thebibliography}`, `\end{thebibliography}`); ok {
+		for _, item := range latexBibItem.Split(items, -1)[1:] {
+			if text := cleanLaTeX(item); text != "" {
+				doc.BibItems = append(doc.BibItems, text)
+			}
+		}
+	}
+	body = removeEnvironments(body, "abstract", "thebibliography", "figure", "figure*", "table", "table*", "tikzpicture", "titlepage")
+	body = latexDropped.ReplaceAllString(body, "")
+
+	// Without \chapter, sections are the chapters
+	offset := 0
+	if !latexChapter.MatchString(body) {
+		offset = 1
+	}
+	for body != "" {
+		loc := latexHeading.FindStringSubmatchIndex(body)
+		text := body
+		if loc != nil {
+			text = body[:loc[0]]
+		}
+		if paragraphs := latexParagraphs(text, entries); paragraphs != "" {
+			for _, p := range strings.Split(paragraphs, "\n\n") {
+				doc.Blocks = append(doc.Blocks, outlineBlock{Text: p})
+			}
+		}
+		if loc == nil {
+			break
+		}
+		title, rest := readGroup(body[loc[1]-1:])
+		level := latexHeadingLevels[body[loc[2]:loc[3]]] - offset
+		if title = cleanLaTeX(title); title != "" && level > 0 {
+			doc.Blocks = append(doc.Blocks, outlineBlock{Text: title, Level: level})
+		}
+		body = rest
+	}
+	return doc
+}
+
+// latexParagraphs converts LaTeX text to Markdown paragraphs separated by blank lines:
+// lists become bullets, emphasis and links Markdown, citations (Author, Year) and math
+// is kept as written
+func latexParagraphs(text string, entries []bibEntry) string {
+	for _, env := range []string{"itemize", "enumerate", "description"} {
+		text = strings.NewReplacer(`\begin{`+env+`}`, "\n\n", `\end{`+env+`}`, "\n\n").Replace(text)
+	}
+	text = latexListItem.ReplaceAllStringFunc(text, func(m string) string {
+		if label := latexListItem.FindStringSubmatch(m)[1]; label != "" {
+			return "\n\n- **" + label + "** "
+		}
+		return "\n\n- "
+	})
+	text = replaceCommands(text, map[string]func(args []string) string{
+		"textbf":    func(args []string) string { return "**" + args[0] + "**" },
+		"emph":      func(args []string) string { return "*" + args[0] + "*" },
+		"textit":    func(args []string) string { return "*" + args[0] + "*" },
+		"url":       func(args []string) string { return args[0] },
+		"footnote":  func(args []string) string { return " (" + args[0] + ")" },
+		"ref":       func([]string) string { return "" },
+		"eqref":     func([]string) string { return "" },
+		"cite":      citeWith(entries, false),
+		"citep":     citeWith(entries, false),
+		"parencite": citeWith(entries, false),
+		"autocite":  citeWith(entries, false),
+		"citet":     citeWith(entries, true),
+		"textcite":  citeWith(entries, true),
+	}, map[string]func(args []string) string{
+		"href": func(args []string) string { return "[" + args[1] + "](" + args[0] + ")" },
+	})
+
+	var out []string
+	for _, p := range latexBlankLine.Split(text, -1) {
+		if p = cleanLaTeXKeepingMath(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, "\n\n")
+}
+
+var latexMath = regexp.MustCompile(`(?s)\$\$.*?\$\$|\$[^$]*\$|\\\[.*?\\\]|\\\(.*?\\\)|\\begin\{(equation|align|gather|multline)\*?\}.*?\\end\{(equation|align|gather|multline)\*?\}`)
+
+// cleanLaTeXKeepingMath cleans a paragraph with cleanLaTeX, leaving its math as written
+func cleanLaTeXKeepingMath(p string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range latexMath.FindAllStringIndex(p, -1) {
+		b.WriteString(" " + cleanLaTeX(p[last:loc[0]]) + " ")
+		b.WriteString(p[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(" " + cleanLaTeX(p[last:]))
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// citeWith renders \cite{a,b} as (Smith, 2020; Lee & Park, 2021), or textually as
+// Smith (2020), from the BibTeX entries. Unknown keys are cited by key.
+func citeWith(entries []bibEntry, textual bool) func(args []string) string {
+	return func(args []string) string {
+		var parts []string
+		for _, key := range strings.Split(args[0], ",") {
+			key = strings.TrimSpace(key)
+			i := slices.IndexFunc(entries, func(e bibEntry) bool { return e.Key == key })
+			if i < 0 {
+				parts = append(parts, key)
+				continue
+			}
+			author, year := bibCitationAuthor(entries[i]), entries[i].Fields["year"]
+			if year == "" && len(entries[i].Fields["date"]) >= 4 {
+				year = entries[i].Fields["date"][:4]
+			}
+			if textual {
+				parts = append(parts, author+" ("+year+")")
+			} else {
+				parts = append(parts, author+", "+year)
+			}
+		}
+		if textual {
+			return strings.Join(parts, "; ")
+		}
+		return "(" + strings.Join(parts, "; ") + ")"
+	}
+}
+
+// bibCitationAuthor names an entry's authors as an APA citation does: Smith, Smith &
+// Lee, or Smith et al.
+func bibCitationAuthor(e bibEntry) string {
+	authors := e.Fields["author"]
+	if authors == "" {
+		authors = e.Fields["editor"]
+	}
+	names := strings.Split(authors, " and ")
+	switch {
+	case authors == "":
+		return e.Key
+	case len(names) == 1:
+		return referenceSurname(names[0])
+	case len(names) == 2:
+		return referenceSurname(names[0]) + " & " + referenceSurname(names[1])
+	default:
+		return referenceSurname(names[0]) + " et al."
+	}
+}
+
+// replaceCommands replaces \name[opt]{arg}... by what fns return for their arguments,
+// one-argument commands from one and two-argument commands from two
+func replaceCommands(text string, one, two map[string]func(args []string) string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(text, '\\')
+		if i < 0 || i+1 >= len(text) {
+			b.WriteString(text)
+			return b.String()
+		}
+		j := i + 1
+		for j < len(text) && isLetter(text[j]) {
+			j++
+		}
+		name := text[i+1 : j]
+		fn, nargs := one[name], 1
+		if fn == nil {
+			fn, nargs = two[name], 2
+		}
+		if fn == nil || j == i+1 {
+			b.WriteString(text[:max(j, i+2)])
+			text = text[max(j, i+2):]
+			continue
+		}
+		rest := strings.TrimLeft(text[j:], "* ")
+		for strings.HasPrefix(rest, "[") { // Optional arguments, such as a cited page
+			if end := strings.IndexByte(rest, ']'); end >= 0 {
+				rest = strings.TrimLeft(rest[end+1:], " ")
+			} else {
+				break
+			}
+		}
+		var args []string
+		for len(args) < nargs {
+			if len(args) > 0 {
+				rest = strings.TrimLeft(rest, " ")
+			}
+			if !strings.HasPrefix(rest, "{") {
+				break
+			}
+			var arg string
+			arg, rest = readGroup(rest)
+			args = append(args, replaceCommands(arg, one, two))
+		}
+		if len(args) < nargs { // Not a use of the command after all
+			b.WriteString(text[:j])
+			text = text[j:]
+			continue
+		}
+		b.WriteString(text[:i])
+		b.WriteString(fn(args))
+		text = rest
+	}
+}
+
+// readGroup reads the {group} text starts with, returning its inside and what follows
+func readGroup(text string) (string, string) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return text[1:i], text[i+1:]
+			}
+		}
+	}
+	return strings.TrimPrefix(text, "{"), ""
+}
+
+// commandArgument returns the argument of the first \name{...} in src
+func commandArgument(src, name string) (string, bool) {
+	loc := regexp.MustCompile(`\\` + name + `\s*(?:\[[^\]]*\])?\s*\{`).FindStringIndex(src)
+	if loc == nil {
+		return "", false
+	}
+	arg, _ := readGroup(src[loc[1]-1:])
+	return arg, true
+}
+
+// between returns the text between the first start and the next end after it
+func between(src, start, end string) (string, bool) {
+	_, after, ok := strings.Cut(src, start)
+	if !ok {
+		return "", false
+	}
+	inside, _, _ := strings.Cut(after, end)
+	return inside, true
+}
+
+// removeEnvironments removes every \begin{name}...\end{name} of the given names
+func removeEnvironments(src string, names ...string) string {
+	for _, name := range names {
+		begin, end := `\begin{`+name+`}`, `\end{`+name+`}`
+		for {
+			i := strings.Index(src, begin)
+			if i < 0 {
+				break
+			}
+			j := strings.Index(src[i:], end)
+			if j < 0 {
+				src = src[:i]
+				break
+			}
+			src = src[:i] + "\n\n" + src[i+j+len(end):]
+		}
+	}
+	return src
+}
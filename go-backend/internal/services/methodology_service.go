@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrMethodologyNotFound = errors.New("the methodology questionnaire has not been answered")
+
+// GetMethodology returns the project's answers to the methodology questionnaire
+func (s *ResearchService) GetMethodology(ctx context.Context, projectID, userID uuid.UUID) (sqlc.ProjectMethodology, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return sqlc.ProjectMethodology{}, err
+	}
+	methodology, err := s.store.GetProjectMethodology(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectMethodology{}, ErrMethodologyNotFound
+		}
+		return sqlc.ProjectMethodology{}, fmt.Errorf("database error fetching methodology: %w", err)
+	}
+	return methodology, nil
+}
+
+// SaveMethodology stores the project's answers to the methodology questionnaire,
+// replacing earlier ones. Requires the edit role.
+func (s *ResearchService) SaveMethodology(ctx context.Context, projectID, userID uuid.UUID, answers apimodels.MethodologyAnswers) (sqlc.ProjectMethodology, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.ProjectMethodology{}, err
+	}
+	encoded, err := json.Marshal(answers)
+	if err != nil {
+		return sqlc.ProjectMethodology{}, err
+	}
+	methodology, err := s.store.UpsertProjectMethodology(ctx, sqlc.UpsertProjectMethodologyParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		Answers:   encoded,
+		UpdatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return sqlc.ProjectMethodology{}, fmt.Errorf("could not save methodology: %w", err)
+	}
+	s.logger.Info("Methodology questionnaire saved", "projectID", projectID, "approach", answers.Approach, "userID", userID)
+	return methodology, nil
+}
+
+// methodologyAnswers loads the questionnaire answers for methodology generation, or nil
+// when the project has not answered it
+func (s *ResearchService) methodologyAnswers(ctx context.Context, projectID uuid.UUID) (*apimodels.MethodologyAnswers, error) {
+	methodology, err := s.store.GetProjectMethodology(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not load methodology answers: %w", err)
+	}
+	answers := apimodels.ToMethodologyResponse(methodology).Answers
+	return &answers, nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/mail"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Notification types. Users can turn each one on or off per channel.
+const (
+	NotificationGenerationCompleted = "generation.completed"
+	NotificationGenerationFailed    = "generation.failed" // After its background retries
+	NotificationDocumentReady       = "document.ready"
+	NotificationCommentAdded        = "comment.added"
+	NotificationReviewRequested     = "review.requested"
+	NotificationReviewDecided       = "review.decided"
+)
+
+// notificationDefaults holds the channels used for types the user has not configured
+var notificationDefaults = map[string]struct{ InApp, Email bool }{
+	NotificationGenerationCompleted: {InApp: true, Email: false},
+	NotificationGenerationFailed:    {InApp: true, Email: true},
+	NotificationDocumentReady:       {InApp: true, Email: true},
+	NotificationCommentAdded:        {InApp: true, Email: false},
+	NotificationReviewRequested:     {InApp: true, Email: true},
+	NotificationReviewDecided:       {InApp: true, Email: true},
+}
+
+const (
+	emailBatchSize   = 20
+	maxEmailAttempts = 5
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+)
+
+// Notification is what happened, before it is fanned out to recipients
+type Notification struct {
+	Type       string
+	ProjectID  uuid.UUID
+	ChapterID  *uuid.UUID
+	DocumentID *uuid.UUID
+	Title      string
+	Body       string
+}
+
+// NotificationService stores in-app notifications and queues emails for the delivery worker
+type NotificationService struct {
+	store  db.Store
+	mailer mail.Sender // nil when email is not configured
+	logger *applogger.AppLogger
+}
+
+func NewNotificationService(store db.Store, mailer mail.Sender, logger *applogger.AppLogger) *NotificationService {
+	return &NotificationService{store: store, mailer: mailer, logger: logger}
+}
+
+// Notify records n for each recipient according to their preferences. It is best effort:
+// failures are logged, never returned, so they can't fail the action being notified about.
+// A nil NotificationService is a no-op.
+func (s *NotificationService) Notify(ctx context.Context, n Notification, recipients ...uuid.UUID) {
+	if s == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx) // The request may finish before every recipient is written
+	seen := make(map[uuid.UUID]bool, len(recipients))
+	for _, userID := range recipients {
+		if userID == uuid.Nil || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		inApp, email, err := s.channels(ctx, userID, n.Type)
+		if err != nil {
+			s.logger.Error("Failed to load notification preferences", "userID", userID, "type", n.Type, "error", err)
+			continue
+		}
+		emailStatus := "none"
+		if email && s.mailer != nil {
+			emailStatus = "pending"
+		}
+		if !inApp && emailStatus == "none" {
+			continue
+		}
+		_, err = s.store.CreateNotification(ctx, sqlc.CreateNotificationParams{
+			UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+			Type:        n.Type,
+			ProjectID:   optionalUUID(&n.ProjectID),
+			ChapterID:   optionalUUID(n.ChapterID),
+			DocumentID:  optionalUUID(n.DocumentID),
+			Title:       n.Title,
+			Body:        n.Body,
+			InApp:       inApp,
+			EmailStatus: emailStatus,
+		})
+		if err != nil {
+			s.logger.Error("Failed to store notification", "userID", userID, "type", n.Type, "error", err)
+		}
+	}
+}
+
+// channels returns whether userID wants notifications of type in the app and by email
+func (s *NotificationService) channels(ctx context.Context, userID uuid.UUID, notificationType string) (bool, bool, error) {
+	prefs, err := s.store.ListNotificationPreferences(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return false, false, err
+	}
+	for _, p := range prefs {
+		if p.Type == notificationType {
+			return p.InApp, p.Email, nil
+		}
+	}
+	d := notificationDefaults[notificationType]
+	return d.InApp, d.Email, nil
+}
+
+// ListNotifications returns the user's newest in-app notifications and their unread count
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]sqlc.Notification, int64, error) {
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+	notifications, err := s.store.ListUserNotifications(ctx, sqlc.ListUserNotificationsParams{
+		UserID:     pgUserID,
+		UnreadOnly: unreadOnly,
+		RowLimit:   int32(limit),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error fetching notifications: %w", err)
+	}
+	unread, err := s.store.CountUnreadNotifications(ctx, pgUserID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("database error counting notifications: %w", err)
+	}
+	return notifications, unread, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) error {
+	rows, err := s.store.MarkNotificationRead(ctx, sqlc.MarkNotificationReadParams{
+		ID:     pgtype.UUID{Bytes: notificationID, Valid: true},
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("could not mark notification read: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	if err := s.store.MarkAllNotificationsRead(ctx, pgtype.UUID{Bytes: userID, Valid: true}); err != nil {
+		return fmt.Errorf("could not mark notifications read: %w", err)
+	}
+	return nil
+}
+
+// Preferences returns the effective settings for every notification type
+func (s *NotificationService) Preferences(ctx context.Context, userID uuid.UUID) ([]apimodels.NotificationPreferenceResponse, error) {
+	prefs, err := s.store.ListNotificationPreferences(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching notification preferences: %w", err)
+	}
+	stored := make(map[string]sqlc.NotificationPreference, len(prefs))
+	for _, p := range prefs {
+		stored[p.Type] = p
+	}
+	resp := make([]apimodels.NotificationPreferenceResponse, 0, len(notificationDefaults))
+	for notificationType, d := range notificationDefaults {
+		pref := apimodels.NotificationPreferenceResponse{Type: notificationType, InApp: d.InApp, Email: d.Email}
+		if p, ok := stored[notificationType]; ok {
+			pref.InApp, pref.Email = p.InApp, p.Email
+		}
+		resp = append(resp, pref)
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Type < resp[j].Type })
+	return resp, nil
+}
+
+// SetPreference changes the channels for one type; fields missing from req keep their current value
+func (s *NotificationService) SetPreference(ctx context.Context, userID uuid.UUID, notificationType string, req apimodels.UpdateNotificationPreferenceRequest) (apimodels.NotificationPreferenceResponse, error) {
+	if _, ok := notificationDefaults[notificationType]; !ok {
+		return apimodels.NotificationPreferenceResponse{}, ErrUnknownNotificationType
+	}
+	inApp, email, err := s.channels(ctx, userID, notificationType)
+	if err != nil {
+		return apimodels.NotificationPreferenceResponse{}, fmt.Errorf("database error fetching notification preferences: %w", err)
+	}
+	if req.InApp != nil {
+		inApp = *req.InApp
+	}
+	if req.Email != nil {
+		email = *req.Email
+	}
+	pref, err := s.store.UpsertNotificationPreference(ctx, sqlc.UpsertNotificationPreferenceParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		Type:   notificationType,
+		InApp:  inApp,
+		Email:  email,
+	})
+	if err != nil {
+		return apimodels.NotificationPreferenceResponse{}, fmt.Errorf("could not save notification preference: %w", err)
+	}
+	return apimodels.NotificationPreferenceResponse{Type: pref.Type, InApp: pref.InApp, Email: pref.Email}, nil
+}
+
+// EmailEnabled reports whether an SMTP relay is configured
+func (s *NotificationService) EmailEnabled() bool {
+	return s.mailer != nil
+}
+
+// JobDeliverEmails drains the notification email queue
+const JobDeliverEmails = "notifications.deliver_emails"
+
+// RegisterJobs schedules email delivery every interval, when email is enabled
+func (s *NotificationService) RegisterJobs(q *jobs.Queue, interval time.Duration) {
+	if !s.EmailEnabled() {
+		return
+	}
+	q.Register(JobDeliverEmails, jobs.Options{MaxAttempts: 1}, func(ctx context.Context, _ jobs.Job) error {
+		for { // Batch after batch
+			sent, err := s.DeliverEmails(ctx)
+			if err != nil || sent == 0 {
+				return err
+			}
+		}
+	})
+	q.Periodic(JobDeliverEmails, interval)
+}
+
+// DeliverEmails sends one batch of pending notification emails and returns how many it handled.
+// Failed sends stay pending until they have been tried maxEmailAttempts times.
+// Several processes can run it at once; each claims a different batch.
+func (s *NotificationService) DeliverEmails(ctx context.Context) (int, error) {
+	if s.mailer == nil {
+		return 0, nil
+	}
+	handled := 0
+	err := s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		batch, err := q.ClaimPendingNotificationEmails(ctx, emailBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, n := range batch {
+			if isGuestEmail(n.Email) { // Nobody reads a guest's placeholder address
+				if err := q.UpdateNotificationEmailStatus(ctx, sqlc.UpdateNotificationEmailStatusParams{ID: n.ID, EmailStatus: "none"}); err != nil {
+					return err
+				}
+				handled++
+				continue
+			}
+			status := "sent"
+			msg, err := notificationEmail(n)
+			if err == nil {
+				err = s.mailer.Send(ctx, msg)
+			}
+			if err != nil {
+				status = "pending"
+				if n.EmailAttempts+1 >= maxEmailAttempts {
+					status = "failed"
+				}
+				s.logger.Warn("Failed to send notification email", "notificationID", n.ID.Bytes, "attempt", n.EmailAttempts+1, "error", err)
+			}
+			if err := q.UpdateNotificationEmailStatus(ctx, sqlc.UpdateNotificationEmailStatusParams{ID: n.ID, EmailStatus: status}); err != nil {
+				return err
+			}
+			handled++
+		}
+		return nil
+	})
+	if err != nil {
+		return handled, fmt.Errorf("could not deliver notification emails: %w", err)
+	}
+	return handled, nil
+}
+
+// notificationEmail renders n with the template for its type
+func notificationEmail(n sqlc.ClaimPendingNotificationEmailsRow) (mail.Message, error) {
+	template := mail.TemplateNotification
+	switch n.Type {
+	case NotificationDocumentReady:
+		template = mail.TemplateDocumentReady
+	case NotificationReviewRequested:
+		template = mail.TemplateReviewRequested
+	}
+	return mail.Render(n.Email, template, mail.TemplateData{Name: n.FirstName, Title: n.Title, Body: n.Body})
+}
+
+// recipientsExcept lists the valid ids other than actor; people aren't notified of their own actions
+func recipientsExcept(actor uuid.UUID, ids ...pgtype.UUID) []uuid.UUID {
+	recipients := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id.Valid && id.Bytes != actor {
+			recipients = append(recipients, id.Bytes)
+		}
+	}
+	return recipients
+}
+
+// notificationExcerpt shortens comment and note text for notification bodies
+func notificationExcerpt(text string) string {
+	const maxRunes = 280
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
+
+func optionalUUID(id *uuid.UUID) pgtype.UUID {
+	if id == nil || *id == uuid.Nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: *id, Valid: true}
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	"github.com/shawgichan/research-service/go-backend/internal/mail"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found or access denied")
+	ErrNotOrganizationAdmin = errors.New("only organization owners and admins can do this")
+	ErrInvitationNotFound   = errors.New("invitation not found or expired")
+	ErrAlreadyMember        = errors.New("user is already a member of this organization")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrLastOwner            = errors.New("an organization must keep at least one owner")
+)
+
+// Organization member roles
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// invitationTTL is how long an invitation can be accepted
+const invitationTTL = 14 * 24 * time.Hour
+
+// OrganizationService manages organizations, their members and invitations.
+// Access to an organization's projects is enforced by the project queries themselves.
+type OrganizationService struct {
+	store         db.Store
+	mailer        mail.Sender // nil when email is not configured
+	invitationURL string      // Page that accepts an invitation, may be empty
+	logger        *applogger.AppLogger
+}
+
+func NewOrganizationService(store db.Store, mailer mail.Sender, invitationURL string, logger *applogger.AppLogger) *OrganizationService {
+	return &OrganizationService{
+		store:         store,
+		mailer:        mailer,
+		invitationURL: invitationURL,
+		logger:        logger,
+	}
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows)
+}
+
+func isOrgAdmin(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin
+}
+
+// membership returns the user's membership, or ErrOrganizationNotFound when they are not a member.
+// It reads from the primary so a freshly created organization or accepted invitation is visible.
+func (s *OrganizationService) membership(ctx context.Context, orgID, userID uuid.UUID) (sqlc.OrganizationMember, error) {
+	member, err := s.store.GetOrganizationMember(db.WithPrimary(ctx), sqlc.GetOrganizationMemberParams{
+		OrganizationID: pgtype.UUID{Bytes: orgID, Valid: true},
+		UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.OrganizationMember{}, ErrOrganizationNotFound
+		}
+		return sqlc.OrganizationMember{}, fmt.Errorf("database error fetching membership: %w", err)
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) requireAdmin(ctx context.Context, orgID, userID uuid.UUID) (sqlc.OrganizationMember, error) {
+	member, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return sqlc.OrganizationMember{}, err
+	}
+	if !isOrgAdmin(member.Role) {
+		return sqlc.OrganizationMember{}, ErrNotOrganizationAdmin
+	}
+	return member, nil
+}
+
+// CreateOrganization creates an organization with the caller as its owner
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID uuid.UUID, req apimodels.CreateOrganizationRequest) (sqlc.Organization, error) {
+	s.logger.Info("Creating organization", "userID", userID, "name", req.Name)
+	var org sqlc.Organization
+	err := s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		org, err = q.CreateOrganization(ctx, sqlc.CreateOrganizationParams{
+			Name:      req.Name,
+			CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.AddOrganizationMember(ctx, sqlc.AddOrganizationMemberParams{
+			OrganizationID: org.ID,
+			UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+			Role:           OrgRoleOwner,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to create organization", "userID", userID, "error", err)
+		return sqlc.Organization{}, fmt.Errorf("could not create organization: %w", err)
+	}
+	s.logger.Info("Organization created", "organizationID", org.ID, "userID", userID)
+	return org, nil
+}
+
+// ListUserOrganizations returns the organizations the user belongs to, with their role in each
+func (s *OrganizationService) ListUserOrganizations(ctx context.Context, userID uuid.UUID) ([]sqlc.ListUserOrganizationsRow, error) {
+	orgs, err := s.store.ListUserOrganizations(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to list organizations", "userID", userID, "error", err)
+		return nil, fmt.Errorf("database error fetching organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// GetOrganization returns an organization and the caller's role in it
+func (s *OrganizationService) GetOrganization(ctx context.Context, orgID, userID uuid.UUID) (sqlc.Organization, string, error) {
+	member, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return sqlc.Organization{}, "", err
+	}
+	org, err := s.store.GetOrganizationByID(db.WithPrimary(ctx), pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Organization{}, "", ErrOrganizationNotFound
+		}
+		return sqlc.Organization{}, "", fmt.Errorf("database error fetching organization: %w", err)
+	}
+	return org, member.Role, nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID, userID uuid.UUID) ([]sqlc.ListOrganizationMembersRow, error) {
+	if _, err := s.membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	members, err := s.store.ListOrganizationMembers(ctx, pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching members: %w", err)
+	}
+	return members, nil
+}
+
+// InviteMember invites an email address to the organization. Inviting the
+// same address again refreshes the pending invitation and replaces its token.
+// The token is emailed to the invitee; when email is not configured it is
+// returned instead, for the inviter to pass on, and is otherwise empty.
+func (s *OrganizationService) InviteMember(ctx context.Context, orgID, userID uuid.UUID, req apimodels.InviteMemberRequest) (sqlc.OrganizationInvitation, string, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return sqlc.OrganizationInvitation{}, "", err
+	}
+	email := strings.TrimSpace(req.Email)
+	var inviteeName string
+	if invitee, err := s.store.GetUserByEmail(ctx, email); err == nil {
+		if _, err := s.membership(ctx, orgID, invitee.ID.Bytes); err == nil {
+			return sqlc.OrganizationInvitation{}, "", ErrAlreadyMember
+		}
+		inviteeName = invitee.FirstName
+	} else if !isNoRows(err) {
+		return sqlc.OrganizationInvitation{}, "", fmt.Errorf("database error fetching invitee: %w", err)
+	}
+
+	invitationToken, err := newInvitationToken()
+	if err != nil {
+		return sqlc.OrganizationInvitation{}, "", err
+	}
+	invitation, err := s.store.UpsertOrganizationInvitation(ctx, sqlc.UpsertOrganizationInvitationParams{
+		OrganizationID: pgtype.UUID{Bytes: orgID, Valid: true},
+		Email:          email,
+		Role:           req.Role,
+		InvitedBy:      pgtype.UUID{Bytes: userID, Valid: true},
+		ExpiresAt:      pgtype.Timestamptz{Time: time.Now().Add(invitationTTL), Valid: true},
+		TokenHash:      pgtype.Text{String: hashInvitationToken(invitationToken), Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to create invitation", "organizationID", orgID, "error", err)
+		return sqlc.OrganizationInvitation{}, "", fmt.Errorf("could not create invitation: %w", err)
+	}
+	s.logger.Info("Member invited", "organizationID", orgID, "invitedBy", userID, "email", email, "role", req.Role)
+
+	if s.mailer == nil {
+		return invitation, invitationToken, nil
+	}
+	if err := s.sendInvitation(ctx, invitation, invitationToken, inviteeName); err != nil {
+		s.logger.Error("Failed to send invitation", "organizationID", orgID, "invitationID", invitation.ID.Bytes, "error", err)
+		return sqlc.OrganizationInvitation{}, "", fmt.Errorf("could not send invitation: %w", err)
+	}
+	return invitation, "", nil
+}
+
+// sendInvitation emails the invitee the link, or the code, that accepts the invitation
+func (s *OrganizationService) sendInvitation(ctx context.Context, invitation sqlc.OrganizationInvitation, invitationToken, inviteeName string) error {
+	org, err := s.store.GetOrganizationByID(ctx, invitation.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("database error fetching organization: %w", err)
+	}
+	inviter, err := s.store.GetUserByID(ctx, invitation.InvitedBy)
+	if err != nil {
+		return fmt.Errorf("database error fetching inviter: %w", err)
+	}
+	data := mail.TemplateData{
+		Name:      inviteeName,
+		Title:     org.Name,
+		Body:      fmt.Sprintf("%s %s invited you to join %s with the %s role.", inviter.FirstName, inviter.LastName, org.Name, invitation.Role),
+		ExpiresIn: "14 days",
+		Code:      invitationToken,
+	}
+	if s.invitationURL != "" {
+		u, err := url.Parse(s.invitationURL)
+		if err != nil {
+			return fmt.Errorf("invalid invitation URL: %w", err)
+		}
+		query := u.Query()
+		query.Set("invitation", uuid.UUID(invitation.ID.Bytes).String())
+		query.Set("token", invitationToken)
+		u.RawQuery = query.Encode()
+		data.ActionURL = u.String()
+	}
+	msg, err := mail.Render(invitation.Email, mail.TemplateInvitation, data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// newInvitationToken returns a random token that accepts one invitation
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate invitation token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashInvitationToken is what is stored of a token, so a leaked table accepts nothing
+func hashInvitationToken(invitationToken string) string {
+	sum := sha256.Sum256([]byte(invitationToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListInvitations returns the organization's pending invitations, for owners and admins
+func (s *OrganizationService) ListInvitations(ctx context.Context, orgID, userID uuid.UUID) ([]sqlc.OrganizationInvitation, error) {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	invitations, err := s.store.ListOrganizationInvitations(ctx, pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (s *OrganizationService) RevokeInvitation(ctx context.Context, orgID, invitationID, userID uuid.UUID) error {
+	if _, err := s.requireAdmin(ctx, orgID, userID); err != nil {
+		return err
+	}
+	invitation, err := s.store.GetOrganizationInvitation(db.WithPrimary(ctx), pgtype.UUID{Bytes: invitationID, Valid: true})
+	if err != nil || invitation.OrganizationID.Bytes != orgID {
+		if err == nil || isNoRows(err) {
+			return ErrInvitationNotFound
+		}
+		return fmt.Errorf("database error fetching invitation: %w", err)
+	}
+	if err := s.store.DeleteOrganizationInvitation(ctx, invitation.ID); err != nil {
+		return fmt.Errorf("could not revoke invitation: %w", err)
+	}
+	s.logger.Info("Invitation revoked", "organizationID", orgID, "invitationID", invitationID, "userID", userID)
+	return nil
+}
+
+// ListMyInvitations returns pending invitations addressed to the user's email
+func (s *OrganizationService) ListMyInvitations(ctx context.Context, userID uuid.UUID) ([]sqlc.ListInvitationsForEmailRow, error) {
+	user, err := s.store.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching user: %w", err)
+	}
+	invitations, err := s.store.ListInvitationsForEmail(ctx, user.Email)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+// invitationFor loads an unexpired invitation addressed to the user
+func (s *OrganizationService) invitationFor(ctx context.Context, invitationID, userID uuid.UUID) (sqlc.OrganizationInvitation, error) {
+	invitation, err := s.store.GetOrganizationInvitation(db.WithPrimary(ctx), pgtype.UUID{Bytes: invitationID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.OrganizationInvitation{}, ErrInvitationNotFound
+		}
+		return sqlc.OrganizationInvitation{}, fmt.Errorf("database error fetching invitation: %w", err)
+	}
+	user, err := s.store.GetUserByID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return sqlc.OrganizationInvitation{}, fmt.Errorf("database error fetching user: %w", err)
+	}
+	// Someone else's invitation looks the same as a missing one
+	if !strings.EqualFold(invitation.Email, user.Email) || time.Now().After(invitation.ExpiresAt.Time) {
+		return sqlc.OrganizationInvitation{}, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the organization with the invited role. Addresses are
+// not verified, so the user must also present the token that was emailed to the invitee.
+// The invitation is deleted on acceptance, which makes the token single-use.
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, invitationID, userID uuid.UUID, invitationToken string) (sqlc.OrganizationMember, error) {
+	invitation, err := s.invitationFor(ctx, invitationID, userID)
+	if err != nil {
+		return sqlc.OrganizationMember{}, err
+	}
+	// Invitations from before tokens have none and can't be accepted; the same error as a wrong token
+	hash := hashInvitationToken(invitationToken)
+	if !invitation.TokenHash.Valid || subtle.ConstantTimeCompare([]byte(hash), []byte(invitation.TokenHash.String)) != 1 {
+		return sqlc.OrganizationMember{}, ErrInvitationNotFound
+	}
+	var member sqlc.OrganizationMember
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		member, err = q.AddOrganizationMember(ctx, sqlc.AddOrganizationMemberParams{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+			Role:           invitation.Role,
+		})
+		if err != nil {
+			return err
+		}
+		return q.DeleteOrganizationInvitation(ctx, invitation.ID)
+	})
+	if err != nil {
+		s.logger.Error("Failed to accept invitation", "invitationID", invitationID, "userID", userID, "error", err)
+		return sqlc.OrganizationMember{}, fmt.Errorf("could not accept invitation: %w", err)
+	}
+	s.logger.Info("Invitation accepted", "organizationID", invitation.OrganizationID, "userID", userID, "role", member.Role)
+	return member, nil
+}
+
+// DeclineInvitation deletes an invitation addressed to the user
+func (s *OrganizationService) DeclineInvitation(ctx context.Context, invitationID, userID uuid.UUID) error {
+	invitation, err := s.invitationFor(ctx, invitationID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.DeleteOrganizationInvitation(ctx, invitation.ID); err != nil {
+		return fmt.Errorf("could not decline invitation: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes memberID from the organization. Members may remove
+// themselves; owners and admins may remove others, but only owners can remove
+// an owner, and the last owner cannot leave.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, memberID, userID uuid.UUID) error {
+	caller, err := s.membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	target := caller
+	if memberID != userID {
+		if !isOrgAdmin(caller.Role) {
+			return ErrNotOrganizationAdmin
+		}
+		target, err = s.membership(ctx, orgID, memberID)
+		if err != nil {
+			if errors.Is(err, ErrOrganizationNotFound) {
+				return ErrMemberNotFound
+			}
+			return err
+		}
+		if target.Role == OrgRoleOwner && caller.Role != OrgRoleOwner {
+			return ErrNotOrganizationAdmin
+		}
+	}
+
+	pgOrgID := pgtype.UUID{Bytes: orgID, Valid: true}
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if target.Role == OrgRoleOwner {
+			owners, err := q.CountOrganizationOwners(ctx, pgOrgID)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return ErrLastOwner
+			}
+		}
+		_, err := q.RemoveOrganizationMember(ctx, sqlc.RemoveOrganizationMemberParams{OrganizationID: pgOrgID, UserID: target.UserID})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrLastOwner) {
+			return ErrLastOwner
+		}
+		return fmt.Errorf("could not remove member: %w", err)
+	}
+	s.logger.Info("Organization member removed", "organizationID", orgID, "memberID", memberID, "removedBy", userID)
+	return nil
+}
+
+// ListProjects returns the projects shared with the organization
+func (s *OrganizationService) ListProjects(ctx context.Context, orgID, userID uuid.UUID) ([]sqlc.ResearchProject, error) {
+	if _, err := s.membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	projects, err := s.store.ListOrganizationProjects(ctx, pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching organization projects: %w", err)
+	}
+	return projects, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// JobExtractPaperText extracts and chunks the text of one imported paper's stored PDF
+const JobExtractPaperText = "references.extract_pdf_text"
+
+// Full-text passages of imported papers in literature review prompts
+const (
+	paperChunkChars        = 1500 // Per stored passage, a few paragraphs
+	maxPaperChunksPerPaper = 3    // So one long paper cannot take the whole budget
+	maxPaperChunkMatches   = 60   // Ranked passages considered per review
+	charsPerToken          = 4    // Rough average for English prose
+)
+
+// queuePaperText queues the text extraction of a freshly stored paper PDF. It is best
+// effort: a paper without extracted text is reviewed from its abstract.
+func (s *ResearchService) queuePaperText(ctx context.Context, referenceID uuid.UUID) {
+	if err := s.jobs.Enqueue(ctx, JobExtractPaperText, paperPDFJob{ReferenceID: referenceID}); err != nil {
+		s.logger.Warn("Failed to queue paper text extraction", "referenceID", referenceID, "error", err)
+	}
+}
+
+// runExtractPaperTextJob extracts a stored paper PDF's text through the docgen service
+// and saves it in passages, replacing any saved before
+func (s *ResearchService) runExtractPaperTextJob(ctx context.Context, job jobs.Job) error {
+	var payload paperPDFJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	paper, err := s.store.GetReferencePaper(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.ReferenceID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return nil
+		}
+		return fmt.Errorf("could not load reference paper: %w", err)
+	}
+	if paper.PdfStatus != PaperPDFStored {
+		return nil
+	}
+
+	extracted, err := s.extractText(ctx, paper.PdfPath, mimePDF)
+	if err != nil {
+		if errors.Is(err, errUnreadableFile) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	chunks := chunkText(extracted.Text, paperChunkChars)
+
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteReferencePaperChunks(ctx, paper.ReferenceID); err != nil {
+			return err
+		}
+		if len(chunks) == 0 { // Scanned PDFs without a text layer
+			return nil
+		}
+		return q.CreateReferencePaperChunks(ctx, sqlc.CreateReferencePaperChunksParams{
+			ReferenceID: paper.ReferenceID,
+			ProjectID:   paper.ProjectID,
+			Contents:    chunks,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("could not save paper text: %w", err)
+	}
+	s.logger.Info("Paper text extracted", "referenceID", payload.ReferenceID, "pages", extracted.PageCount, "chunks", len(chunks))
+	return nil
+}
+
+// chunkText splits text into passages of at most limit characters, keeping paragraphs
+// whole where they fit. Whitespace inside a paragraph is collapsed.
+func chunkText(text string, limit int) []string {
+	var chunks []string
+	current, size := "", 0
+	add := func(piece, sep string) {
+		n := utf8.RuneCountInString(piece)
+		if size > 0 && size+len(sep)+n > limit {
+			chunks = append(chunks, current)
+			current, size = "", 0
+		}
+		if size > 0 {
+			current += sep
+			size += len(sep)
+		}
+		current += piece
+		size += n
+	}
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			continue
+		}
+		if joined := strings.Join(words, " "); utf8.RuneCountInString(joined) <= limit {
+			add(joined, "\n\n")
+			continue
+		}
+		// A paragraph longer than a passage is cut between words
+		add(words[0], "\n\n")
+		for _, word := range words[1:] {
+			add(word, " ")
+		}
+	}
+	if size > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// paperExcerpts returns the passages of the project's imported papers that best match the
+// thesis topic, grouped by paper, within the configured token budget. A failure is logged
+// and generation carries on without them.
+func (s *ResearchService) paperExcerpts(ctx context.Context, project sqlc.ResearchProject) []SourceMaterial {
+	budget := s.uploads.PaperContextTokens * charsPerToken
+	query := topicQuery(project.Title + " " + project.Specialization)
+	if budget <= 0 || query == "" {
+		return nil
+	}
+	matches, err := s.store.ListRelevantPaperChunks(ctx, sqlc.ListRelevantPaperChunksParams{
+		Query:     query,
+		ProjectID: project.ID,
+		MaxChunks: maxPaperChunkMatches,
+	})
+	if err != nil {
+		s.logger.Error("Failed to load paper passages", "projectID", uuid.UUID(project.ID.Bytes), "error", err)
+		return nil
+	}
+
+	// Best passages first, then each paper's passages in reading order
+	var order []uuid.UUID
+	byPaper := make(map[uuid.UUID][]sqlc.ListRelevantPaperChunksRow)
+	for _, m := range matches {
+		chars := utf8.RuneCountInString(m.Content)
+		if chars > budget || len(byPaper[m.ReferenceID.Bytes]) == maxPaperChunksPerPaper {
+			continue
+		}
+		if _, seen := byPaper[m.ReferenceID.Bytes]; !seen {
+			order = append(order, m.ReferenceID.Bytes)
+		}
+		byPaper[m.ReferenceID.Bytes] = append(byPaper[m.ReferenceID.Bytes], m)
+		budget -= chars
+	}
+	excerpts := make([]SourceMaterial, len(order))
+	for i, id := range order {
+		passages := byPaper[id]
+		slices.SortFunc(passages, func(a, b sqlc.ListRelevantPaperChunksRow) int { return int(a.Position - b.Position) })
+		texts := make([]string, len(passages))
+		for j, p := range passages {
+			texts[j] = p.Content
+		}
+		first := passages[0]
+		excerpts[i] = SourceMaterial{
+			Name: promptCitation(first.Title, first.Authors, first.PublicationYear),
+			Text: strings.Join(texts, "\n[...]\n"),
+		}
+	}
+	return excerpts
+}
+
+// topicQuery turns a topic into a web search query matching any of its words
+func topicQuery(topic string) string {
+	words := strings.FieldsFunc(strings.ToLower(topic), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var kept []string
+	for _, w := range words {
+		if utf8.RuneCountInString(w) > 1 && w != "or" && !slices.Contains(kept, w) {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " or ")
+}
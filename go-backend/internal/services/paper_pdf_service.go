@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrReferencePDFNotFound = errors.New("no PDF is stored for this reference")
+	ErrReferencePDFPending  = errors.New("the PDF for this reference is still being downloaded")
+)
+
+// Download states of an imported paper's open access PDF
+const (
+	PaperPDFNone    = "none" // The paper has no open access PDF
+	PaperPDFPending = "pending"
+	PaperPDFStored  = "stored"
+	PaperPDFFailed  = "failed"
+)
+
+// JobFetchPaperPDF downloads the open access PDF of one imported paper
+const JobFetchPaperPDF = "references.fetch_pdf"
+
+const (
+	paperPDFTimeout      = 2 * time.Minute // Bounds one PDF download; publisher hosts can be slow
+	paperPDFMaxRedirects = 5               // Links often go through a resolver or two before the PDF
+)
+
+// errPaperPDFUnavailable marks a download that will not succeed on retry
+var errPaperPDFUnavailable = errors.New("open access PDF unavailable")
+
+// paperPDFJob is the payload of JobFetchPaperPDF
+type paperPDFJob struct {
+	ReferenceID uuid.UUID `json:"reference_id"`
+}
+
+// queuePaperPDFs queues the PDF downloads of freshly imported papers. A paper whose job
+// cannot be queued is marked failed rather than left pending.
+func (s *ResearchService) queuePaperPDFs(ctx context.Context, referenceIDs []uuid.UUID) {
+	for _, id := range referenceIDs {
+		if err := s.jobs.Enqueue(ctx, JobFetchPaperPDF, paperPDFJob{ReferenceID: id}); err != nil {
+			s.logger.Warn("Failed to queue paper PDF download", "referenceID", id, "error", err)
+			s.setPaperPDF(ctx, id, PaperPDFFailed, "Could not queue the download")
+		}
+	}
+}
+
+// runFetchPaperPDFJob downloads a paper's open access PDF into the project's storage.
+// Links that are gone or do not lead to a PDF fail for good; other errors are retried and
+// only mark the paper failed on the last attempt.
+func (s *ResearchService) runFetchPaperPDFJob(ctx context.Context, job jobs.Job) error {
+	var payload paperPDFJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	paper, err := s.store.GetReferencePaper(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.ReferenceID, Valid: true})
+	if err != nil {
+		if isNoRows(err) { // Reference purged before the download ran
+			return nil
+		}
+		return fmt.Errorf("could not load reference paper: %w", err)
+	}
+	if paper.PdfStatus != PaperPDFPending {
+		return nil
+	}
+
+	// An attempt that stored the file but not the row is overwritten
+	filePath := projectFileKey(paper.ProjectID.Bytes, "papers", payload.ReferenceID.String()+".pdf")
+
+	stored, err := s.downloadPaperPDF(ctx, paper.OpenAccessPdfUrl, filePath)
+	if err != nil {
+		permanent := errors.Is(err, errPaperPDFUnavailable)
+		if permanent || job.LastAttempt() {
+			s.setPaperPDF(context.WithoutCancel(ctx), payload.ReferenceID, PaperPDFFailed, err.Error())
+		}
+		if permanent {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	err = s.store.SetReferencePaperPDF(ctx, sqlc.SetReferencePaperPDFParams{
+		PdfStatus:   PaperPDFStored,
+		PdfPath:     filePath,
+		PdfSize:     pgtype.Int8{Int64: stored.Size, Valid: true},
+		ReferenceID: paper.ReferenceID,
+	})
+	if err != nil {
+		s.removeUploadFile(ctx, filePath)
+		return fmt.Errorf("could not save paper PDF: %w", err)
+	}
+	s.logger.Info("Paper PDF stored", "referenceID", payload.ReferenceID, "paperID", paper.PaperID, "size", stored.Size)
+	s.queuePaperText(ctx, payload.ReferenceID)
+	return nil
+}
+
+// newPaperPDFClient returns the client PDFs are downloaded with. Links come from paper
+// metadata anyone can edit, so it only reaches public addresses, redirects included, and
+// follows a few redirects over http and https only.
+func newPaperPDFClient() *http.Client {
+	return telemetry.NewHTTPClient(&http.Client{
+		Timeout:   paperPDFTimeout,
+		Transport: publicTransport(false),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= paperPDFMaxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", errPaperPDFUnavailable, paperPDFMaxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirected to a %s link", errPaperPDFUnavailable, req.URL.Scheme)
+			}
+			return nil
+		},
+	})
+}
+
+// downloadPaperPDF fetches a PDF into storage under key, held to the upload size limit
+func (s *ResearchService) downloadPaperPDF(ctx context.Context, pdfURL, key string) (storedFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil {
+		return storedFile{}, fmt.Errorf("%w: invalid link: %v", errPaperPDFUnavailable, err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return storedFile{}, fmt.Errorf("%w: not an http or https link", errPaperPDFUnavailable)
+	}
+	req.Header.Set("Accept", mimePDF)
+	resp, err := s.pdfClient.Do(req)
+	if errors.Is(err, errPrivateTarget) || errors.Is(err, errPaperPDFUnavailable) {
+		return storedFile{}, fmt.Errorf("%w: %v", errPaperPDFUnavailable, err)
+	}
+	if err != nil {
+		return storedFile{}, fmt.Errorf("PDF download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone,
+		resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusUnauthorized:
+		return storedFile{}, fmt.Errorf("%w: the host returned %s", errPaperPDFUnavailable, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return storedFile{}, fmt.Errorf("PDF download returned %s", resp.Status)
+	}
+	stored, err := s.writeUpload(ctx, key, resp.Body, paperUploadRule)
+	var uploadErr *UploadError
+	switch {
+	case errors.Is(err, errUnsupportedFileType):
+		// Landing pages and paywalls answer 200 with HTML
+		return storedFile{}, fmt.Errorf("%w: the link did not return a PDF", errPaperPDFUnavailable)
+	case errors.As(err, &uploadErr): // Too large, too many pages or empty
+		return storedFile{}, fmt.Errorf("%w: %v", errPaperPDFUnavailable, err)
+	}
+	return stored, err
+}
+
+// setPaperPDF records a download that did not complete
+func (s *ResearchService) setPaperPDF(ctx context.Context, referenceID uuid.UUID, status, message string) {
+	err := s.store.SetReferencePaperPDF(ctx, sqlc.SetReferencePaperPDFParams{
+		PdfStatus:   status,
+		PdfError:    message,
+		ReferenceID: pgtype.UUID{Bytes: referenceID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to update paper PDF status", "referenceID", referenceID, "status", status, "error", err)
+	}
+}
+
+// ReferencePDF returns the path of a reference's stored open access PDF and a file name
+// to download it as
+func (s *ResearchService) ReferencePDF(ctx context.Context, projectID, referenceID, userID uuid.UUID) (string, string, error) {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleRead)
+	if err != nil {
+		return "", "", err
+	}
+	paper, err := s.store.GetReferencePaper(ctx, ref.ID)
+	if err != nil {
+		if isNoRows(err) { // Not imported from Semantic Scholar
+			return "", "", ErrReferencePDFNotFound
+		}
+		return "", "", fmt.Errorf("database error fetching reference paper: %w", err)
+	}
+	switch paper.PdfStatus {
+	case PaperPDFStored:
+		return paper.PdfPath, cleanFileName(paper.PaperID+".pdf", "paper.pdf"), nil
+	case PaperPDFPending:
+		return "", "", ErrReferencePDFPending
+	default:
+		return "", "", ErrReferencePDFNotFound
+	}
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Page sizes when a search or citation graph request names none
+const (
+	defaultPaperSearchLimit   = 10
+	defaultCitationGraphLimit = 20
+)
+
+// Paper recommendations: the most recent references seed them, and the recommendations
+// API returns at most 500 papers
+const (
+	defaultRecommendationLimit = 20
+	maxRecommendationSeeds     = 100
+	maxRecommendationResults   = 500
+)
+
+var (
+	ErrNoRecommendationSeeds = errors.New("add references with a DOI or import papers from Semantic Scholar to get recommendations")
+	ErrReferenceNotOnScholar = errors.New("this reference has no Semantic Scholar ID or DOI")
+)
+
+// SearchPapers searches Semantic Scholar for papers to add to a project. Given a project
+// the user can read, and with embeddings enabled, the page is re-ranked by similarity to
+// the project's title and description.
+func (s *ResearchService) SearchPapers(ctx context.Context, userID uuid.UUID, req apimodels.SearchPapersRequest) (apimodels.SearchPapersResponse, error) {
+	var project sqlc.ResearchProject
+	if req.ProjectID != "" {
+		projectID, err := uuid.Parse(req.ProjectID)
+		if err != nil {
+			return apimodels.SearchPapersResponse{}, fmt.Errorf("%w: invalid project_id", ErrInvalidPaperSearch)
+		}
+		if project, err = s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+			return apimodels.SearchPapersResponse{}, err
+		}
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultPaperSearchLimit
+	}
+	result, err := s.aiService.SearchSemanticScholar(ctx, PaperSearch{
+		Query:            req.Query,
+		Limit:            limit,
+		Offset:           req.Offset,
+		Year:             req.Year,
+		Venues:           req.Venues,
+		MinCitationCount: req.MinCitationCount,
+		OpenAccessOnly:   req.OpenAccessOnly,
+	})
+	if err != nil {
+		return apimodels.SearchPapersResponse{}, err
+	}
+	resp := apimodels.SearchPapersResponse{
+		Total:  result.Total,
+		Offset: result.Offset,
+		Next:   result.Next,
+		Papers: make([]apimodels.PaperResponse, len(result.Papers)),
+	}
+	for i, p := range result.Papers {
+		resp.Papers[i] = paperResponse(p)
+	}
+	if project.ID.Valid {
+		s.rankPapers(ctx, project, resp.Papers)
+	}
+	return resp, nil
+}
+
+// rankPapers orders papers by the cosine similarity of their title and abstract to the
+// project's title and description. It is best effort: on failure the keyword order stays.
+func (s *ResearchService) rankPapers(ctx context.Context, project sqlc.ResearchProject, papers []apimodels.PaperResponse) {
+	if len(papers) < 2 || !s.aiService.EmbeddingsEnabled() {
+		return
+	}
+	inputs := make([]string, 0, len(papers)+1)
+	inputs = append(inputs, referenceEmbeddingInput(project.Title, project.Description))
+	for _, p := range papers {
+		inputs = append(inputs, referenceEmbeddingInput(p.Title, pgtype.Text{String: p.Abstract, Valid: p.Abstract != ""}))
+	}
+	vectors, err := s.aiService.EmbedText(ctx, inputs...)
+	if err != nil {
+		s.logger.Warn("Failed to embed papers for ranking, keeping search order", "projectID", uuid.UUID(project.ID.Bytes), "error", err)
+		return
+	}
+	for i := range papers {
+		similarity := cosineSimilarity(vectors[0], vectors[i+1])
+		papers[i].Relevance = &similarity
+	}
+	// Stable, so equally relevant papers keep the provider's order
+	slices.SortStableFunc(papers, func(a, b apimodels.PaperResponse) int { return cmp.Compare(*b.Relevance, *a.Relevance) })
+}
+
+// cosineSimilarity of two vectors of the same length; 0 when either is zero
+func cosineSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// ImportPapers adds Semantic Scholar papers to a project's references, fetching them in
+// one batch request per 500, and queues the download of their open access PDFs. Papers
+// already imported are skipped, and a paper matching a reference from another source is
+// merged into it. Requires the edit role.
+func (s *ResearchService) ImportPapers(ctx context.Context, projectID, userID uuid.UUID, paperIDs []string) (apimodels.ImportPapersResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return apimodels.ImportPapersResponse{}, err
+	}
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	existing, err := s.store.ListProjectPaperIDs(ctx, pgProjectID)
+	if err != nil {
+		return apimodels.ImportPapersResponse{}, fmt.Errorf("database error fetching imported papers: %w", err)
+	}
+	imported := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		imported[id] = true
+	}
+
+	resp := apimodels.ImportPapersResponse{Imported: []apimodels.ReferenceResponse{}}
+	var fetch []string
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(paperIDs))) {
+		if imported[id] {
+			resp.Skipped = append(resp.Skipped, id)
+		} else {
+			fetch = append(fetch, id)
+		}
+	}
+	if len(fetch) == 0 {
+		return resp, nil
+	}
+	papers, err := s.aiService.GetSemanticPapersByIDs(ctx, fetch)
+	if err != nil {
+		return apimodels.ImportPapersResponse{}, err
+	}
+	if unknown := len(fetch) - len(papers); unknown > 0 {
+		s.logger.Info("Some papers were not found on Semantic Scholar", "projectID", projectID, "count", unknown)
+	}
+
+	var refs, merged []sqlc.Reference
+	var withPDF []uuid.UUID
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		// Papers the project has from another source, e.g. entered by hand, are merged
+		index, err := projectReferenceIndex(ctx, q, pgProjectID)
+		if err != nil {
+			return err
+		}
+		for _, p := range papers {
+			// A DOI: or ARXIV: ID can name a paper that was imported by its S2 ID
+			if imported[p.PaperID] {
+				resp.Skipped = append(resp.Skipped, p.PaperID)
+				continue
+			}
+			imported[p.PaperID] = true
+			ref, isDuplicate, err := saveReference(ctx, q, index, paperReferenceParams(projectID, p))
+			if err != nil {
+				return err
+			}
+			if isDuplicate {
+				merged = append(merged, ref)
+				// The merged reference takes the paper's link unless it already has one
+				if _, err := q.GetReferencePaper(ctx, ref.ID); err == nil {
+					continue
+				} else if !isNoRows(err) {
+					return fmt.Errorf("could not load reference paper: %w", err)
+				}
+			} else {
+				refs = append(refs, ref)
+			}
+			link := sqlc.CreateReferencePaperParams{ReferenceID: ref.ID, ProjectID: pgProjectID, PaperID: p.PaperID, PdfStatus: PaperPDFNone}
+			if p.OpenAccessPdf != nil && p.OpenAccessPdf.Url != "" {
+				link.OpenAccessPdfUrl = p.OpenAccessPdf.Url
+				link.PdfStatus = PaperPDFPending
+				withPDF = append(withPDF, ref.ID.Bytes)
+			}
+			if err := q.CreateReferencePaper(ctx, link); err != nil {
+				return fmt.Errorf("could not link reference to paper: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apimodels.ImportPapersResponse{}, err
+	}
+
+	s.logger.Info("Imported papers", "projectID", projectID, "imported", len(refs), "merged", len(merged), "skipped", len(resp.Skipped))
+	s.embedReferences(ctx, slices.Concat(refs, merged))
+	s.queuePaperPDFs(ctx, withPDF)
+	for _, ref := range refs {
+		r := apimodels.ToReferenceResponse(ref)
+		s.webhooks.Emit(ctx, projectID, WebhookReferenceAdded, r)
+		resp.Imported = append(resp.Imported, r)
+	}
+	for _, ref := range merged {
+		resp.Merged = append(resp.Merged, apimodels.ToReferenceResponse(ref))
+	}
+	return resp, nil
+}
+
+// RecommendPapers suggests papers like the project's references that the project does
+// not have yet, using Semantic Scholar's paper-based recommendations
+func (s *ResearchService) RecommendPapers(ctx context.Context, projectID, userID uuid.UUID, limit int) ([]apimodels.PaperResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultRecommendationLimit
+	}
+	known, rows, err := s.projectPapers(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var seeds, dois []string
+	for _, r := range rows {
+		if doi := normalizeDOI(r.Doi.String); doi != "" && !r.PaperID.Valid {
+			dois = append(dois, "DOI:"+doi)
+		}
+		if r.PaperID.Valid && len(seeds) < maxRecommendationSeeds {
+			seeds = append(seeds, r.PaperID.String)
+		}
+	}
+	// References added by hand are identified by DOI, which the recommendations API does not take
+	if room := maxRecommendationSeeds - len(seeds); room > 0 && len(dois) > 0 {
+		resolved, err := s.aiService.GetSemanticPapersByIDs(ctx, dois[:min(room, len(dois))])
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range resolved {
+			known[p.PaperID] = true
+			seeds = append(seeds, p.PaperID)
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, ErrNoRecommendationSeeds
+	}
+
+	// Ask for enough to fill the page once papers the project has are dropped
+	papers, err := s.aiService.RecommendSemanticPapers(ctx, seeds, min(limit+len(known), maxRecommendationResults))
+	if err != nil {
+		return nil, err
+	}
+	recommended := []apimodels.PaperResponse{}
+	for _, p := range papers {
+		if len(recommended) == limit {
+			break
+		}
+		if known[p.PaperID] || known[normalizeDOI(p.ExternalIds.DOI)] {
+			continue
+		}
+		recommended = append(recommended, paperResponse(p))
+	}
+	return recommended, nil
+}
+
+// PaperCitationGraph pages through the papers a saved reference cites, or the papers
+// citing it, marking those the project already has so the rest can be imported
+func (s *ResearchService) PaperCitationGraph(ctx context.Context, projectID, referenceID, userID uuid.UUID, direction string, req apimodels.CitationGraphRequest) (apimodels.CitationGraphResponse, error) {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.CitationGraphResponse{}, err
+	}
+	var paperID string
+	paper, err := s.store.GetReferencePaper(ctx, ref.ID)
+	switch {
+	case err == nil:
+		paperID = paper.PaperID
+	case !isNoRows(err):
+		return apimodels.CitationGraphResponse{}, fmt.Errorf("database error fetching reference paper: %w", err)
+	case normalizeDOI(ref.Doi.String) != "":
+		paperID = "DOI:" + normalizeDOI(ref.Doi.String)
+	default:
+		return apimodels.CitationGraphResponse{}, ErrReferenceNotOnScholar
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultCitationGraphLimit
+	}
+
+	result, err := s.aiService.GetSemanticPaperGraph(ctx, paperID, direction, req.Offset, limit)
+	if err != nil {
+		return apimodels.CitationGraphResponse{}, err
+	}
+	known, _, err := s.projectPapers(ctx, projectID)
+	if err != nil {
+		return apimodels.CitationGraphResponse{}, err
+	}
+	resp := apimodels.CitationGraphResponse{
+		PaperID: paperID,
+		Offset:  result.Offset,
+		Next:    result.Next,
+		Papers:  make([]apimodels.PaperResponse, len(result.Papers)),
+	}
+	for i, p := range result.Papers {
+		resp.Papers[i] = paperResponse(p)
+		resp.Papers[i].InProject = known[p.PaperID] || known[normalizeDOI(p.ExternalIds.DOI)]
+	}
+	return resp, nil
+}
+
+// projectPapers returns the Semantic Scholar IDs and DOIs of the papers a project has, as
+// one set, along with its references that Semantic Scholar can identify
+func (s *ResearchService) projectPapers(ctx context.Context, projectID uuid.UUID) (map[string]bool, []sqlc.ListProjectPaperSeedsRow, error) {
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	rows, err := s.store.ListProjectPaperSeeds(ctx, pgProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database error fetching references: %w", err)
+	}
+	imported, err := s.store.ListProjectPaperIDs(ctx, pgProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database error fetching imported papers: %w", err)
+	}
+	known := make(map[string]bool, len(imported)+len(rows))
+	for _, id := range imported {
+		known[id] = true
+	}
+	for _, r := range rows {
+		if doi := normalizeDOI(r.Doi.String); doi != "" {
+			known[doi] = true
+		}
+	}
+	return known, rows, nil
+}
+
+// normalizeDOI lowercases a DOI and strips any resolver prefix; DOIs are case-insensitive
+func normalizeDOI(doi string) string {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "doi:"} {
+		doi = strings.TrimPrefix(doi, prefix)
+	}
+	return doi
+}
+
+// paperReferenceParams maps a Semantic Scholar paper onto a reference. The APA citation
+// is left empty so the bibliography formats it from these fields.
+func paperReferenceParams(projectID uuid.UUID, p SemanticPaper) sqlc.CreateReferenceParams {
+	authors := make([]string, len(p.Authors))
+	for i, a := range p.Authors {
+		authors[i] = a.Name
+	}
+	return generatedReferenceParams(projectID, &apimodels.ReferenceResponse{
+		Title:           p.Title,
+		Authors:         strings.Join(authors, ", "),
+		Journal:         p.Venue,
+		PublicationYear: p.Year,
+		DOI:             p.ExternalIds.DOI,
+		URL:             p.URL,
+		Abstract:        p.Abstract,
+	})
+}
+
+func paperResponse(p SemanticPaper) apimodels.PaperResponse {
+	resp := apimodels.PaperResponse{
+		PaperID:       p.PaperID,
+		Title:         p.Title,
+		Authors:       make([]string, len(p.Authors)),
+		Year:          p.Year,
+		Venue:         p.Venue,
+		Abstract:      p.Abstract,
+		DOI:           p.ExternalIds.DOI,
+		URL:           p.URL,
+		CitationCount: p.CitationCount,
+	}
+	for i, a := range p.Authors {
+		resp.Authors[i] = a.Name
+	}
+	if p.OpenAccessPdf != nil {
+		resp.OpenAccessPDFURL = p.OpenAccessPdf.Url
+	}
+	return resp
+}
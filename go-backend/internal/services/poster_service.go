@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Poster sizes and layout templates
+const (
+	PosterSizeA0 = "A0"
+	PosterSizeA1 = "A1"
+
+	PosterTemplateThreeColumn = "three_column"
+	PosterTemplateTwoColumn   = "two_column"
+	PosterTemplateLandscape   = "landscape"
+)
+
+// defaultPosterFigures is how many key figures a poster shows unless asked otherwise
+const defaultPosterFigures = 3
+
+// PosterOptions are the choices made when a poster was queued
+type PosterOptions struct {
+	Size       string `json:"size"`
+	Template   string `json:"template"`
+	MaxFigures int    `json:"max_figures"`
+}
+
+type PythonPosterRequest struct {
+	ProjectID      uuid.UUID             `json:"project_id"`
+	ResearchTitle  string                `json:"research_title"`
+	StudentName    string                `json:"student_name,omitempty"`
+	UniversityName string                `json:"university_name,omitempty"`
+	Specialization string                `json:"specialization,omitempty"`
+	Abstract       string                `json:"abstract"`
+	Sections       []PythonPosterSection `json:"sections"`
+	Conclusions    []string              `json:"conclusions"`
+	Figures        []PythonFigureData    `json:"figures,omitempty"`
+	Size           string                `json:"size"`
+	Template       string                `json:"template"`
+	Direction      string                `json:"direction"` // ltr or rtl
+	Labels         map[string]string     `json:"labels"`
+}
+type PythonPosterSection struct {
+	Heading string   `json:"heading"`
+	Bullets []string `json:"bullets"`
+}
+
+// GeneratePoster queues an academic poster of the project: the AI condenses the chapters
+// into an abstract, a few sections and the conclusions, which the docgen service lays out
+// with the key figures on one A0 or A1 PPTX slide. It is downloaded like any generated
+// document.
+func (s *ResearchService) GeneratePoster(ctx context.Context, projectID, userID uuid.UUID, req apimodels.GeneratePosterRequest) (sqlc.GeneratedDocument, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GeneratePoster")
+	span.SetAttributes(attribute.String("project.id", projectID.String()))
+	defer span.End()
+
+	opts := PosterOptions{Size: req.Size, Template: req.Template, MaxFigures: defaultPosterFigures}
+	if opts.Size == "" {
+		opts.Size = PosterSizeA0
+	}
+	if opts.Template == "" {
+		opts.Template = PosterTemplateThreeColumn
+	}
+	if req.MaxFigures != nil {
+		opts.MaxFigures = *req.MaxFigures
+	}
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	if len(defenseChapters(chapters)) == 0 { // Fail now rather than in the job
+		return sqlc.GeneratedDocument{}, ErrNoThesisContent
+	}
+	return s.queueDocument(ctx, project, documentJob{UserID: userID, Kind: DocumentKindPoster, Poster: &opts})
+}
+
+// generatePoster writes the text of a queued poster, picks its figures and has it rendered
+func (s *ResearchService) generatePoster(ctx context.Context, project sqlc.ResearchProject, dbDoc sqlc.GeneratedDocument, opts *PosterOptions) (sqlc.GeneratedDocument, error) {
+	if opts == nil {
+		opts = &PosterOptions{Size: PosterSizeA0, Template: PosterTemplateThreeColumn, MaxFigures: defaultPosterFigures}
+	}
+	chaptersDB, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return dbDoc, fmt.Errorf("failed to fetch chapters for poster: %w", err)
+	}
+	chapters := defenseChapters(chaptersDB)
+	if len(chapters) == 0 {
+		return dbDoc, ErrNoThesisContent
+	}
+	formatting, err := s.projectFormatting(ctx, project)
+	if err != nil {
+		return dbDoc, err
+	}
+	labels := documentLabels(formatting)
+	figures, err := s.posterFigures(ctx, project, chaptersDB, opts.MaxFigures, labels)
+	if err != nil {
+		return dbDoc, err
+	}
+	generated, err := s.aiService.GeneratePosterContent(ctx, DefenseQuestionsInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       formatting.Language,
+		Chapters:       chapters,
+	})
+	if err != nil {
+		return dbDoc, fmt.Errorf("AI generation failed: %w", err)
+	}
+
+	payload := PythonPosterRequest{
+		ProjectID:      project.ID.Bytes,
+		ResearchTitle:  project.Title,
+		StudentName:    s.studentName(ctx, project),
+		UniversityName: project.University.String,
+		Specialization: project.Specialization,
+		Abstract:       strings.TrimSpace(generated.Abstract),
+		Sections:       []PythonPosterSection{},
+		Conclusions:    trimmedLines(generated.Conclusions),
+		Figures:        figures,
+		Size:           opts.Size,
+		Template:       opts.Template,
+		Direction:      documentDirection(formatting),
+		Labels:         labels,
+	}
+	for _, section := range generated.Sections {
+		heading, bullets := strings.TrimSpace(section.Heading), trimmedLines(section.Bullets)
+		if heading != "" && len(bullets) > 0 {
+			payload.Sections = append(payload.Sections, PythonPosterSection{Heading: heading, Bullets: bullets})
+		}
+	}
+	if payload.Abstract == "" && len(payload.Sections) == 0 {
+		return dbDoc, errors.New("AI generation failed: no usable poster content was generated")
+	}
+	s.logger.Info("Poster content written", "projectID", uuid.UUID(project.ID.Bytes), "sections", len(payload.Sections), "figures", len(figures))
+	return s.renderDocument(ctx, dbDoc, "/generate-poster", payload)
+}
+
+// posterFigures picks up to maxFigures figures for a poster, those of the results
+// chapter first, numbered as in the thesis. Tables are left out; they rarely read well
+// from a distance.
+func (s *ResearchService) posterFigures(ctx context.Context, project sqlc.ResearchProject, chapters []sqlc.Chapter, maxFigures int, labels map[string]string) ([]PythonFigureData, error) {
+	if maxFigures == 0 {
+		return nil, nil
+	}
+	byChapter, err := s.docgenFigures(ctx, project.ID.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	var picked []PythonFigureData
+	for _, results := range []bool{true, false} {
+		for i, ch := range chapters {
+			if (ch.Type == "results") != results {
+				continue
+			}
+			for _, f := range s.pythonFigures(ctx, i+1, byChapter[ch.ID.Bytes], labels) {
+				if f.Kind != FigureKindFigure {
+					continue
+				}
+				if picked = append(picked, f); len(picked) == maxFigures {
+					return picked, nil
+				}
+			}
+		}
+	}
+	return picked, nil
+}
+
+// trimmedLines trims lines and drops the empty ones
+func trimmedLines(lines []string) []string {
+	out := []string{}
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
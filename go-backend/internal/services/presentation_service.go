@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxSlideBullets caps the bullets kept on a slide, whatever the AI writes
+const maxSlideBullets = 6
+
+type PythonPresentationRequest struct {
+	ProjectID      uuid.UUID         `json:"project_id"`
+	ResearchTitle  string            `json:"research_title"`
+	StudentName    string            `json:"student_name,omitempty"`
+	UniversityName string            `json:"university_name,omitempty"`
+	Specialization string            `json:"specialization,omitempty"`
+	Slides         []PythonSlideData `json:"slides"`
+	Direction      string            `json:"direction"` // ltr or rtl
+	Labels         map[string]string `json:"labels"`
+}
+type PythonSlideData struct {
+	Title   string   `json:"title"`
+	Bullets []string `json:"bullets"`
+	Notes   string   `json:"notes,omitempty"`
+}
+
+// GeneratePresentation queues a defense presentation of the project: the AI writes
+// slides on the problem, methods, findings and conclusions from the chapters, and the
+// docgen service renders them to PPTX. It is downloaded like any generated document.
+func (s *ResearchService) GeneratePresentation(ctx context.Context, projectID, userID uuid.UUID) (sqlc.GeneratedDocument, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GeneratePresentation")
+	span.SetAttributes(attribute.String("project.id", projectID.String()))
+	defer span.End()
+
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	if len(defenseChapters(chapters)) == 0 { // Fail now rather than in the job
+		return sqlc.GeneratedDocument{}, ErrNoThesisContent
+	}
+	return s.queueDocument(ctx, project, documentJob{UserID: userID, Kind: DocumentKindPresentation})
+}
+
+// generatePresentation writes the slides of a queued presentation and has them rendered
+func (s *ResearchService) generatePresentation(ctx context.Context, project sqlc.ResearchProject, dbDoc sqlc.GeneratedDocument) (sqlc.GeneratedDocument, error) {
+	chaptersDB, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return dbDoc, fmt.Errorf("failed to fetch chapters for presentation: %w", err)
+	}
+	chapters := defenseChapters(chaptersDB)
+	if len(chapters) == 0 {
+		return dbDoc, ErrNoThesisContent
+	}
+	formatting, err := s.projectFormatting(ctx, project)
+	if err != nil {
+		return dbDoc, err
+	}
+	generated, err := s.aiService.GeneratePresentationSlides(ctx, DefenseQuestionsInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		Language:       formatting.Language,
+		Chapters:       chapters,
+	})
+	if err != nil {
+		return dbDoc, fmt.Errorf("AI generation failed: %w", err)
+	}
+	slides := make([]PythonSlideData, 0, len(generated))
+	for _, slide := range generated {
+		title := strings.TrimSpace(slide.Title)
+		var bullets []string
+		for _, b := range slide.Bullets {
+			if b = strings.TrimSpace(b); b != "" && len(bullets) < maxSlideBullets {
+				bullets = append(bullets, b)
+			}
+		}
+		if title == "" || len(bullets) == 0 {
+			continue
+		}
+		slides = append(slides, PythonSlideData{Title: title, Bullets: bullets, Notes: strings.TrimSpace(slide.Notes)})
+	}
+	if len(slides) == 0 {
+		return dbDoc, errors.New("AI generation failed: no usable slides were generated")
+	}
+	s.logger.Info("Presentation slides written", "projectID", uuid.UUID(project.ID.Bytes), "slides", len(slides))
+
+	return s.renderDocument(ctx, dbDoc, "/generate-presentation", PythonPresentationRequest{
+		ProjectID:      project.ID.Bytes,
+		ResearchTitle:  project.Title,
+		StudentName:    s.studentName(ctx, project),
+		UniversityName: project.University.String,
+		Specialization: project.Specialization,
+		Slides:         slides,
+		Direction:      documentDirection(formatting),
+		Labels:         documentLabels(formatting),
+	})
+}
+
+// defenseChapters are the thesis chapters with content, as defense material is prepared
+// from them. The executive summary is left out, being written from the others.
+func defenseChapters(chapters []sqlc.Chapter) []DefenseChapter {
+	var written []DefenseChapter
+	for _, ch := range chapters {
+		if ch.Type != ChapterTypeExecutiveSummary && strings.TrimSpace(ch.Content.String) != "" {
+			written = append(written, DefenseChapter{Type: ch.Type, Title: ch.Title, Content: ch.Content.String})
+		}
+	}
+	return written
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GetProjectProgress sums a project's chapters against their word targets and counts
+// them by status. A chapter counts toward the percentage only up to its target, so one
+// long chapter cannot make up for the ones not yet written.
+func (s *ResearchService) GetProjectProgress(ctx context.Context, projectID, userID uuid.UUID) (apimodels.ProjectProgressResponse, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return apimodels.ProjectProgressResponse{}, err
+	}
+	chapters, err := s.store.GetChaptersByProjectID(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return apimodels.ProjectProgressResponse{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+
+	progress := apimodels.ProjectProgressResponse{
+		ProjectID:    projectID,
+		StatusCounts: make(map[string]int, len(chapters)),
+		Chapters:     make([]apimodels.ChapterProgress, len(chapters)),
+	}
+	var targetedWords int32
+	for i, ch := range chapters {
+		chapter := apimodels.ChapterProgress{
+			ID:        ch.ID.Bytes,
+			Type:      ch.Type,
+			Title:     ch.Title,
+			Status:    ch.Status.String,
+			WordCount: ch.WordCount.Int32,
+		}
+		if ch.TargetWordCount.Valid {
+			target := ch.TargetWordCount.Int32
+			chapter.TargetWordCount = &target
+			chapter.PercentComplete = percentOf(ch.WordCount.Int32, target)
+			progress.TargetWords += target
+			targetedWords += min(ch.WordCount.Int32, target)
+		}
+		progress.Chapters[i] = chapter
+		progress.WordsWritten += ch.WordCount.Int32
+		progress.StatusCounts[ch.Status.String]++
+	}
+	if progress.TargetWords > 0 {
+		progress.PercentComplete = percentOf(targetedWords, progress.TargetWords)
+	}
+	return progress, nil
+}
+
+// percentOf is words as a percentage of target, capped at 100 and rounded to one decimal
+func percentOf(words, target int32) *float64 {
+	pct := float64(min(words, target)) * 1000 / float64(target)
+	pct = float64(int(pct+0.5)) / 10
+	return &pct
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Project roles, weakest first. Owners can do everything including sharing;
+// organization members get edit on the organization's projects.
+const (
+	ProjectRoleRead    = "read"
+	ProjectRoleComment = "comment"
+	ProjectRoleEdit    = "edit"
+	ProjectRoleOwner   = "owner"
+)
+
+var projectRoleRank = map[string]int{
+	ProjectRoleRead:    1,
+	ProjectRoleComment: 2,
+	ProjectRoleEdit:    3,
+	ProjectRoleOwner:   4,
+}
+
+var (
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	ErrNoUserWithEmail      = errors.New("no user is registered with this email")
+	ErrShareWithOwner       = errors.New("the project owner cannot be added as a collaborator")
+)
+
+// RoleAllows reports whether role grants at least the access of required
+func RoleAllows(role, required string) bool {
+	return projectRoleRank[role] >= projectRoleRank[required]
+}
+
+// requireProjectRole loads the project if the user has at least the required role.
+// Users without any access get ErrProjectNotFound, so a project's existence is not revealed;
+// users with a weaker role get ErrInsufficientRole.
+func (s *ResearchService) requireProjectRole(ctx context.Context, projectID, userID uuid.UUID, required string) (sqlc.ResearchProject, error) {
+	project, role, err := s.GetProjectWithRole(ctx, projectID, userID)
+	if err != nil {
+		return sqlc.ResearchProject{}, err
+	}
+	if !RoleAllows(role, required) {
+		s.logger.Warn("Project role too weak", "projectID", projectID, "userID", userID, "role", role, "required", required)
+		return sqlc.ResearchProject{}, ErrInsufficientRole
+	}
+	return project, nil
+}
+
+// ListCollaborators returns the users the project is shared with
+func (s *ResearchService) ListCollaborators(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.ListProjectCollaboratorsRow, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	collaborators, err := s.store.ListProjectCollaborators(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching collaborators: %w", err)
+	}
+	return collaborators, nil
+}
+
+// AddCollaborator shares the project with a registered user, or changes their role
+// if it is already shared with them. Only the owner can share a project.
+func (s *ResearchService) AddCollaborator(ctx context.Context, projectID, userID uuid.UUID, req apimodels.AddCollaboratorRequest) (sqlc.ListProjectCollaboratorsRow, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner)
+	if err != nil {
+		return sqlc.ListProjectCollaboratorsRow{}, err
+	}
+	user, err := s.store.GetUserByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ListProjectCollaboratorsRow{}, ErrNoUserWithEmail
+		}
+		return sqlc.ListProjectCollaboratorsRow{}, fmt.Errorf("database error fetching user: %w", err)
+	}
+	if user.ID == project.UserID {
+		return sqlc.ListProjectCollaboratorsRow{}, ErrShareWithOwner
+	}
+
+	collaborator, err := s.store.UpsertProjectCollaborator(ctx, sqlc.UpsertProjectCollaboratorParams{
+		ProjectID: project.ID,
+		UserID:    user.ID,
+		Role:      req.Role,
+		AddedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to save collaborator", "projectID", projectID, "error", err)
+		return sqlc.ListProjectCollaboratorsRow{}, fmt.Errorf("could not add collaborator: %w", err)
+	}
+	s.logger.Info("Project shared", "projectID", projectID, "collaboratorID", user.ID, "role", req.Role, "userID", userID)
+	return sqlc.ListProjectCollaboratorsRow{
+		ProjectID: collaborator.ProjectID,
+		UserID:    collaborator.UserID,
+		Role:      collaborator.Role,
+		AddedBy:   collaborator.AddedBy,
+		CreatedAt: collaborator.CreatedAt,
+		UpdatedAt: collaborator.UpdatedAt,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	}, nil
+}
+
+// RemoveCollaborator stops sharing the project with collaboratorID.
+// The owner can remove anyone; collaborators can remove themselves.
+func (s *ResearchService) RemoveCollaborator(ctx context.Context, projectID, collaboratorID, userID uuid.UUID) error {
+	required := ProjectRoleOwner
+	if collaboratorID == userID {
+		required = ProjectRoleRead
+	}
+	if _, err := s.requireProjectRole(ctx, projectID, userID, required); err != nil {
+		return err
+	}
+	rows, err := s.store.DeleteProjectCollaborator(ctx, sqlc.DeleteProjectCollaboratorParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		UserID:    pgtype.UUID{Bytes: collaboratorID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("could not remove collaborator: %w", err)
+	}
+	if rows == 0 {
+		return ErrCollaboratorNotFound
+	}
+	s.logger.Info("Collaborator removed", "projectID", projectID, "collaboratorID", collaboratorID, "userID", userID)
+	return nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// ProjectBundle is a project gathered for download as one ZIP archive: its latest
+// generated document, its chapters as Markdown, its references as CSL-JSON and its
+// source PDFs
+type ProjectBundle struct {
+	FileName string
+
+	s          *ResearchService
+	projectID  uuid.UUID
+	document   *sqlc.GeneratedDocument
+	chapters   []sqlc.Chapter
+	references ReferenceExport
+	uploads    []sqlc.ProjectUpload
+}
+
+// ProjectBundle looks up what goes into a project's archive. Files are only read from
+// storage by Write, so errors up to here can still be answered as such. Requires the
+// read role.
+func (s *ResearchService) ProjectBundle(ctx context.Context, projectID, userID uuid.UUID) (*ProjectBundle, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &ProjectBundle{FileName: fmt.Sprintf("project_%s.zip", projectID.String()[:8]), s: s, projectID: projectID}
+
+	documents, err := s.store.GetGeneratedDocumentsByProjectID(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching documents: %w", err)
+	}
+	for _, doc := range documents { // Newest first
+		if doc.Status.String == "completed" {
+			bundle.document = &doc
+			break
+		}
+	}
+	if bundle.chapters, err = s.store.GetChaptersByProjectID(ctx, project.ID); err != nil {
+		return nil, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	if bundle.references, err = s.ExportReferences(ctx, projectID, userID, ReferenceExportCSLJSON, nil); err != nil {
+		return nil, err
+	}
+	if bundle.uploads, err = s.store.ListProjectUploads(ctx, project.ID); err != nil {
+		return nil, fmt.Errorf("database error fetching uploads: %w", err)
+	}
+	return bundle, nil
+}
+
+// Write streams the archive to w, reading stored files one at a time. A file missing
+// from storage is left out and logged. Once anything is written an error can only cut
+// the archive short.
+func (b *ProjectBundle) Write(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	names := map[string]int{}
+
+	if b.document != nil {
+		ref := b.document.FilePath // Documents generated before object storage
+		if b.document.StorageKey.Valid {
+			ref = b.document.StorageKey.String
+		}
+		if err := b.addStoredFile(ctx, zw, names, "document/"+b.document.FileName, ref, b.document.CreatedAt.Time); err != nil {
+			return err
+		}
+	}
+	for i, chapter := range b.chapters {
+		content := "# " + chapter.Title + "\n\n" + strings.TrimSpace(chapter.Content.String) + "\n"
+		name := fmt.Sprintf("chapters/%02d_%s.md", i+1, chapter.Type)
+		if err := addZipFile(zw, names, name, zip.Deflate, chapter.UpdatedAt.Time, strings.NewReader(content)); err != nil {
+			return err
+		}
+	}
+	if err := addZipFile(zw, names, b.references.FileName, zip.Deflate, time.Now(), bytes.NewReader(b.references.Data)); err != nil {
+		return err
+	}
+	for _, upload := range b.uploads {
+		if err := b.addStoredFile(ctx, zw, names, "sources/"+upload.FileName, upload.FilePath, upload.CreatedAt.Time); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("could not finish archive: %w", err)
+	}
+	b.s.logger.Info("Project bundle downloaded", "projectID", b.projectID, "chapters", len(b.chapters), "sources", len(b.uploads))
+	return nil
+}
+
+// addStoredFile copies a stored file into the archive. PDFs and DOCX files are
+// compressed already, so they are stored as they are.
+func (b *ProjectBundle) addStoredFile(ctx context.Context, zw *zip.Writer, names map[string]int, name, ref string, modified time.Time) error {
+	file, err := b.s.OpenFile(ctx, ref)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			b.s.logger.Error("Stored file missing from project bundle", "projectID", b.projectID, "file", ref)
+			return nil
+		}
+		return fmt.Errorf("could not open %s: %w", ref, err)
+	}
+	defer file.Close()
+	return addZipFile(zw, names, name, zip.Store, modified, file)
+}
+
+// addZipFile writes r to the archive under name, numbering names already taken, as two
+// sources uploaded under the same file name would be: paper.pdf, paper (2).pdf
+func addZipFile(zw *zip.Writer, names map[string]int, name string, method uint16, modified time.Time, r io.Reader) error {
+	names[name]++
+	if n := names[name]; n > 1 {
+		ext := path.Ext(name)
+		name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+	}
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("could not add %s to archive: %w", name, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("could not add %s to archive: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Origins of chapter content
+const (
+	ProvenanceAI      = "ai"      // Written by AI generation
+	ProvenanceHuman   = "human"   // Typed or pasted by an editor
+	ProvenanceUnknown = "unknown" // Saved before provenance was tracked
+)
+
+// provenanceSpan marks the byte range [Start, End) of a chapter's content as coming from
+// Origin. Stored spans are ordered and cover the whole content.
+type provenanceSpan struct {
+	Origin string `json:"origin"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+}
+
+// recordProvenance updates a chapter's provenance after a save. Generation marks the
+// whole content as AI; an edit keeps the origin of the text it left alone and marks
+// what it added as human.
+func recordProvenance(ctx context.Context, q sqlc.Querier, chapter sqlc.Chapter, origin string) error {
+	var spans []provenanceSpan
+	if origin == ProvenanceAI {
+		spans = []provenanceSpan{{Origin: ProvenanceAI, Start: 0, End: len(chapter.Content.String)}}
+	} else {
+		var err error
+		if spans, err = chapterProvenance(ctx, q, chapter, true); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(spans)
+	if err != nil {
+		return fmt.Errorf("could not encode provenance: %w", err)
+	}
+	err = q.UpsertChapterProvenance(ctx, sqlc.UpsertChapterProvenanceParams{
+		ChapterID: chapter.ID,
+		ProjectID: chapter.ProjectID,
+		Version:   chapter.Version,
+		Spans:     data,
+	})
+	if err != nil {
+		return fmt.Errorf("could not save provenance: %w", err)
+	}
+	return nil
+}
+
+// chapterProvenance works out the spans of a chapter's current content from the last
+// recorded provenance, crediting anything changed since to the editors. A chapter without
+// provenance is of unknown origin; when it was just saved, only the text the save kept
+// from the previous version is.
+func chapterProvenance(ctx context.Context, q sqlc.Querier, chapter sqlc.Chapter, saved bool) ([]provenanceSpan, error) {
+	var base string
+	var baseSpans []provenanceSpan
+	stored, err := q.GetChapterProvenance(ctx, chapter.ID)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stored.Spans, &baseSpans); err != nil {
+			return nil, fmt.Errorf("could not decode provenance: %w", err)
+		}
+		if stored.Version == chapter.Version {
+			return baseSpans, nil
+		}
+		v, err := q.GetChapterVersion(ctx, sqlc.GetChapterVersionParams{ChapterID: chapter.ID, Version: stored.Version})
+		if err != nil && !isNoRows(err) {
+			return nil, fmt.Errorf("database error fetching chapter version: %w", err)
+		}
+		base = v.Content // Empty, and so all new, if the snapshot is missing
+	case isNoRows(err):
+		if !saved {
+			return []provenanceSpan{{Origin: ProvenanceUnknown, Start: 0, End: len(chapter.Content.String)}}, nil
+		}
+		if chapter.Version > 1 {
+			v, err := q.GetChapterVersion(ctx, sqlc.GetChapterVersionParams{ChapterID: chapter.ID, Version: chapter.Version - 1})
+			if err != nil && !isNoRows(err) {
+				return nil, fmt.Errorf("database error fetching chapter version: %w", err)
+			}
+			base = v.Content
+		}
+	default:
+		return nil, fmt.Errorf("database error fetching provenance: %w", err)
+	}
+	return carryProvenance(base, baseSpans, chapter.Content.String), nil
+}
+
+// carryProvenance maps spans of base onto content: text kept from base keeps its origin,
+// inserted text is human. Base text outside every span is unknown.
+func carryProvenance(base string, baseSpans []provenanceSpan, content string) []provenanceSpan {
+	edits := refineWords(diffTokens(strings.SplitAfter(base, "\n"), strings.SplitAfter(content, "\n")))
+	var spans []provenanceSpan
+	add := func(origin string, start, end int) {
+		if n := len(spans); n > 0 && spans[n-1].Origin == origin && spans[n-1].End == start {
+			spans[n-1].End = end
+			return
+		}
+		spans = append(spans, provenanceSpan{Origin: origin, Start: start, End: end})
+	}
+	basePos, pos, next := 0, 0, 0
+	for _, e := range edits {
+		switch e.op {
+		case DiffDelete:
+			basePos += len(e.token)
+		case DiffInsert:
+			add(ProvenanceHuman, pos, pos+len(e.token))
+			pos += len(e.token)
+		default:
+			// A kept line can hold text of several origins
+			end := basePos + len(e.token)
+			for basePos < end {
+				for next < len(baseSpans) && baseSpans[next].End <= basePos {
+					next++
+				}
+				origin, stop := ProvenanceUnknown, end
+				if next < len(baseSpans) && baseSpans[next].Start <= basePos {
+					origin, stop = baseSpans[next].Origin, min(end, baseSpans[next].End)
+				} else if next < len(baseSpans) {
+					stop = min(end, baseSpans[next].Start)
+				}
+				add(origin, pos, pos+stop-basePos)
+				pos += stop - basePos
+				basePos = stop
+			}
+		}
+	}
+	return spans
+}
+
+// ChapterProvenance reports which words of a chapter AI generation wrote and which its
+// editors wrote. Requires read access.
+func (s *ResearchService) ChapterProvenance(ctx context.Context, projectID, chapterID, userID uuid.UUID) (apimodels.ChapterProvenanceResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.ChapterProvenanceResponse{}, err
+	}
+	spans, err := chapterProvenance(ctx, s.store, chapter, false)
+	if err != nil {
+		return apimodels.ChapterProvenanceResponse{}, err
+	}
+	content := chapter.Content.String
+	resp := apimodels.ChapterProvenanceResponse{
+		ChapterID:      chapter.ID.Bytes,
+		ChapterVersion: chapter.Version,
+		Spans:          make([]apimodels.ProvenanceSpanResponse, 0, len(spans)),
+	}
+	for _, span := range spans {
+		if span.Start < 0 || span.End > len(content) || span.Start >= span.End {
+			continue
+		}
+		text := content[span.Start:span.End]
+		words := int(countWords(text))
+		switch span.Origin {
+		case ProvenanceAI:
+			resp.AIWords += words
+		case ProvenanceHuman:
+			resp.HumanWords += words
+		default:
+			resp.UnknownWords += words
+		}
+		resp.Spans = append(resp.Spans, apimodels.ProvenanceSpanResponse{Origin: span.Origin, Text: text, Words: words})
+	}
+	if total := resp.AIWords + resp.HumanWords + resp.UnknownWords; total > 0 {
+		resp.AIShare = float64(resp.AIWords) / float64(total)
+	}
+	return resp, nil
+}
+
+// chapterAIShare is the fraction of a chapter's words AI generation wrote, as
+// ChapterProvenance reports it
+func chapterAIShare(ctx context.Context, q sqlc.Querier, chapter sqlc.Chapter) (float64, error) {
+	spans, err := chapterProvenance(ctx, q, chapter, false)
+	if err != nil {
+		return 0, err
+	}
+	content := chapter.Content.String
+	var aiWords, total int
+	for _, span := range spans {
+		if span.Start < 0 || span.End > len(content) || span.Start >= span.End {
+			continue
+		}
+		words := int(countWords(content[span.Start:span.End]))
+		if span.Origin == ProvenanceAI {
+			aiWords += words
+		}
+		total += words
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(aiWords) / float64(total), nil
+}
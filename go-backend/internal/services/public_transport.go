@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// errPrivateTarget is returned for outbound requests that would reach this network
+var errPrivateTarget = errors.New("target resolves to a private or special-purpose address")
+
+// publicTransport returns a transport for requests to URLs users supply, such as webhooks
+// and paper links, that only connects to public addresses unless allowPrivate is set. The
+// address is checked once resolved, at connect time and for every redirect, so DNS tricks
+// can't reach internal services. No proxy is used, as it would connect on our behalf.
+func publicTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addrPort.Addr()) {
+				return errPrivateTarget
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// specialPurposePrefixes are the IANA special-purpose address registries (RFC 6890 and
+// later), plus multicast and the IPv6 ranges that embed or tunnel to IPv4 addresses, which
+// could lead back into this network
+var specialPurposePrefixes = func() []netip.Prefix {
+	cidrs := []string{
+		// IPv4
+		"0.0.0.0/8",       // This network
+		"10.0.0.0/8",      // Private
+		"100.64.0.0/10",   // Shared address space (CGNAT)
+		"127.0.0.0/8",     // Loopback
+		"169.254.0.0/16",  // Link-local
+		"172.16.0.0/12",   // Private
+		"192.0.0.0/24",    // IETF protocol assignments
+		"192.0.2.0/24",    // Documentation (TEST-NET-1)
+		"192.31.196.0/24", // AS112-v4
+		"192.52.193.0/24", // AMT
+		"192.88.99.0/24",  // Deprecated 6to4 relay anycast
+		"192.168.0.0/16",  // Private
+		"192.175.48.0/24", // Direct delegation AS112 service
+		"198.18.0.0/15",   // Benchmarking
+		"198.51.100.0/24", // Documentation (TEST-NET-2)
+		"203.0.113.0/24",  // Documentation (TEST-NET-3)
+		"224.0.0.0/4",     // Multicast
+		"240.0.0.0/4",     // Reserved, including broadcast
+		// IPv6
+		"::/96",          // Unspecified, loopback and deprecated IPv4-compatible
+		"::ffff:0:0/96",  // IPv4-mapped, should one reach here without being unmapped
+		"64:ff9b::/96",   // NAT64
+		"64:ff9b:1::/48", // Local-use NAT64
+		"100::/64",       // Discard-only
+		"2001::/23",      // IETF protocol assignments, including Teredo
+		"2001:db8::/32",  // Documentation
+		"2002::/16",      // 6to4
+		"3fff::/20",      // Documentation
+		"5f00::/16",      // Segment routing (SRv6) SIDs
+		"fc00::/7",       // Unique local
+		"fe80::/10",      // Link-local
+		"fec0::/10",      // Deprecated site-local
+		"ff00::/8",       // Multicast
+	}
+	prefixes := make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		prefixes[i] = netip.MustParsePrefix(cidr)
+	}
+	return prefixes
+}()
+
+// publicAddr reports whether addr is a globally reachable unicast address. IPv4-mapped
+// IPv6 addresses are checked as the IPv4 address they carry.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range specialPurposePrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrQuestionnaireNotFound = errors.New("questionnaire not found")
+	ErrInvalidQuestionnaire  = errors.New("invalid questionnaire")
+)
+
+// defaultLikertScale labels likert items that bring no scale of their own
+var defaultLikertScale = []string{"Strongly disagree", "Disagree", "Neutral", "Agree", "Strongly agree"}
+
+// maxQuestionnaireSections bounds a questionnaire, including sections added by generation
+const maxQuestionnaireSections = 30
+
+// ListQuestionnaires returns a project's questionnaires, oldest first
+func (s *ResearchService) ListQuestionnaires(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.Questionnaire, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	questionnaires, err := s.store.ListProjectQuestionnaires(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching questionnaires: %w", err)
+	}
+	return questionnaires, nil
+}
+
+// CreateQuestionnaire adds a questionnaire to a project. Requires the edit role.
+func (s *ResearchService) CreateQuestionnaire(ctx context.Context, projectID, userID uuid.UUID, req apimodels.CreateQuestionnaireRequest) (sqlc.Questionnaire, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	sections, err := encodeSections(req.Sections)
+	if err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	questionnaire, err := s.store.CreateQuestionnaire(ctx, sqlc.CreateQuestionnaireParams{
+		ProjectID:         pgtype.UUID{Bytes: projectID, Valid: true},
+		Title:             strings.TrimSpace(req.Title),
+		Instructions:      strings.TrimSpace(req.Instructions),
+		Sections:          sections,
+		IncludeInDocument: req.IncludeInDocument,
+		CreatedBy:         pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return sqlc.Questionnaire{}, fmt.Errorf("could not create questionnaire: %w", err)
+	}
+	s.logger.Info("Questionnaire created", "projectID", projectID, "questionnaireID", questionnaire.ID.Bytes, "userID", userID)
+	return questionnaire, nil
+}
+
+// GetQuestionnaire returns one questionnaire of a project
+func (s *ResearchService) GetQuestionnaire(ctx context.Context, projectID, questionnaireID, userID uuid.UUID) (sqlc.Questionnaire, error) {
+	return s.getQuestionnaire(ctx, projectID, questionnaireID, userID, ProjectRoleRead)
+}
+
+// UpdateQuestionnaire changes a questionnaire. Requires the edit role.
+func (s *ResearchService) UpdateQuestionnaire(ctx context.Context, projectID, questionnaireID, userID uuid.UUID, req apimodels.UpdateQuestionnaireRequest) (sqlc.Questionnaire, error) {
+	questionnaire, err := s.getQuestionnaire(ctx, projectID, questionnaireID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	params := sqlc.UpdateQuestionnaireParams{
+		Title:             questionnaire.Title,
+		Instructions:      questionnaire.Instructions,
+		Sections:          questionnaire.Sections,
+		IncludeInDocument: questionnaire.IncludeInDocument,
+		ID:                questionnaire.ID,
+	}
+	if req.Title != nil {
+		params.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Instructions != nil {
+		params.Instructions = strings.TrimSpace(*req.Instructions)
+	}
+	if req.Sections != nil {
+		if params.Sections, err = encodeSections(req.Sections); err != nil {
+			return sqlc.Questionnaire{}, err
+		}
+	}
+	if req.IncludeInDocument != nil {
+		params.IncludeInDocument = *req.IncludeInDocument
+	}
+	questionnaire, err = s.store.UpdateQuestionnaire(ctx, params)
+	if err != nil {
+		return sqlc.Questionnaire{}, fmt.Errorf("could not update questionnaire: %w", err)
+	}
+	return questionnaire, nil
+}
+
+// DeleteQuestionnaire removes a questionnaire. Requires the edit role.
+func (s *ResearchService) DeleteQuestionnaire(ctx context.Context, projectID, questionnaireID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	_, err := s.store.DeleteQuestionnaire(ctx, sqlc.DeleteQuestionnaireParams{
+		ID:        pgtype.UUID{Bytes: questionnaireID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrQuestionnaireNotFound
+		}
+		return fmt.Errorf("could not delete questionnaire: %w", err)
+	}
+	s.logger.Info("Questionnaire deleted", "projectID", projectID, "questionnaireID", questionnaireID, "userID", userID)
+	return nil
+}
+
+// GenerateQuestionnaireItems has the AI draft items for the research questions and
+// appends them to the questionnaire as a new section. Items the AI gets wrong, such as
+// a choice item without choices, are left out. Requires the edit role.
+func (s *ResearchService) GenerateQuestionnaireItems(ctx context.Context, projectID, questionnaireID, userID uuid.UUID, req apimodels.GenerateQuestionnaireItemsRequest) (sqlc.Questionnaire, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	questionnaire, err := s.store.GetQuestionnaire(ctx, sqlc.GetQuestionnaireParams{
+		ID:        pgtype.UUID{Bytes: questionnaireID, Valid: true},
+		ProjectID: project.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Questionnaire{}, ErrQuestionnaireNotFound
+		}
+		return sqlc.Questionnaire{}, fmt.Errorf("database error fetching questionnaire: %w", err)
+	}
+	sections := apimodels.ToQuestionnaireResponse(questionnaire).Sections
+	if len(sections) >= maxQuestionnaireSections {
+		return sqlc.Questionnaire{}, fmt.Errorf("%w: a questionnaire has at most %d sections", ErrInvalidQuestionnaire, maxQuestionnaireSections)
+	}
+
+	input := QuestionnaireItemsInput{
+		Title:             project.Title,
+		Specialization:    project.Specialization,
+		Description:       project.Description.String,
+		Language:          project.Language,
+		ResearchQuestions: req.ResearchQuestions,
+		Count:             req.Count,
+		Type:              req.Type,
+	}
+	if input.Count == 0 {
+		input.Count = 10
+	}
+	if input.Type == "" {
+		input.Type = "likert"
+	}
+	ctx = s.withGenerationLog(ctx, GenerationKindQuestionnaire, userID, &projectID, nil)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	generated, err := s.aiService.GenerateQuestionnaireItems(ctx, input)
+	if err != nil {
+		s.logger.Error("AI questionnaire item generation failed", "questionnaireID", questionnaireID, "error", err)
+		return sqlc.Questionnaire{}, fmt.Errorf("AI generation failed: %w", err)
+	}
+	section := apimodels.QuestionnaireSection{Title: strings.TrimSpace(req.SectionTitle)}
+	if section.Title == "" {
+		section.Title = fmt.Sprintf("Section %d", len(sections)+1)
+	}
+	for _, item := range generated {
+		if len(section.Items) == input.Count {
+			break
+		}
+		if item, err := normalizeItem(item); err == nil {
+			section.Items = append(section.Items, item)
+		}
+	}
+	if len(section.Items) == 0 {
+		return sqlc.Questionnaire{}, errors.New("AI generation failed: no usable items were generated")
+	}
+
+	encoded, err := json.Marshal(append(sections, section))
+	if err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	questionnaire, err = s.store.UpdateQuestionnaire(ctx, sqlc.UpdateQuestionnaireParams{
+		Title:             questionnaire.Title,
+		Instructions:      questionnaire.Instructions,
+		Sections:          encoded,
+		IncludeInDocument: questionnaire.IncludeInDocument,
+		ID:                questionnaire.ID,
+	})
+	if err != nil {
+		return sqlc.Questionnaire{}, fmt.Errorf("could not save generated items: %w", err)
+	}
+	s.logger.Info("Questionnaire items generated", "questionnaireID", questionnaireID, "items", len(section.Items), "userID", userID)
+	return questionnaire, nil
+}
+
+func (s *ResearchService) getQuestionnaire(ctx context.Context, projectID, questionnaireID, userID uuid.UUID, role string) (sqlc.Questionnaire, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, role); err != nil {
+		return sqlc.Questionnaire{}, err
+	}
+	questionnaire, err := s.store.GetQuestionnaire(ctx, sqlc.GetQuestionnaireParams{
+		ID:        pgtype.UUID{Bytes: questionnaireID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Questionnaire{}, ErrQuestionnaireNotFound
+		}
+		return sqlc.Questionnaire{}, fmt.Errorf("database error fetching questionnaire: %w", err)
+	}
+	return questionnaire, nil
+}
+
+// encodeSections checks the items of each section and encodes the sections for storage
+func encodeSections(sections []apimodels.QuestionnaireSection) ([]byte, error) {
+	if sections == nil {
+		sections = []apimodels.QuestionnaireSection{}
+	}
+	for i, section := range sections {
+		sections[i].Title = strings.TrimSpace(section.Title)
+		for j, item := range section.Items {
+			normalized, err := normalizeItem(item)
+			if err != nil {
+				return nil, fmt.Errorf("%w: section %d item %d: %v", ErrInvalidQuestionnaire, i+1, j+1, err)
+			}
+			sections[i].Items[j] = normalized
+		}
+		if sections[i].Items == nil {
+			sections[i].Items = []apimodels.QuestionnaireItem{}
+		}
+	}
+	return json.Marshal(sections)
+}
+
+// normalizeItem gives a likert item without labels the default scale, drops the
+// options of items that take none and rejects choice items with too few options
+func normalizeItem(item apimodels.QuestionnaireItem) (apimodels.QuestionnaireItem, error) {
+	item.Text = strings.TrimSpace(item.Text)
+	if item.Text == "" {
+		return item, errors.New("text is empty")
+	}
+	if len(item.Options) > 20 {
+		return item, errors.New("an item has at most 20 options")
+	}
+	switch item.Type {
+	case "likert":
+		if len(item.Options) == 0 {
+			item.Options = defaultLikertScale
+		} else if len(item.Options) < 3 {
+			return item, errors.New("a likert scale needs at least 3 points")
+		}
+	case "single_choice", "multiple_choice":
+		if len(item.Options) < 2 {
+			return item, errors.New("a choice item needs at least 2 options")
+		}
+	case "yes_no", "open":
+		item.Options = nil
+	default:
+		return item, fmt.Errorf("unknown item type %q", item.Type)
+	}
+	return item, nil
+}
+
+// questionnaireAppendices renders the questionnaires a project includes in its
+// document as appendices, lettered on from the project's own appendices
+func (s *ResearchService) questionnaireAppendices(ctx context.Context, projectID uuid.UUID, first int, opts apimodels.FormattingOptions) ([]PythonAppendixData, error) {
+	questionnaires, err := s.store.ListProjectQuestionnaires(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch questionnaires for doc gen: %w", err)
+	}
+	labels := documentLabels(opts)
+	var out []PythonAppendixData
+	for _, q := range questionnaires {
+		if !q.IncludeInDocument {
+			continue
+		}
+		out = append(out, PythonAppendixData{
+			Letter:  documentAppendixLetter(first+len(out), opts),
+			Title:   q.Title,
+			Content: questionnaireText(apimodels.ToQuestionnaireResponse(q), labels),
+		})
+	}
+	return out, nil
+}
+
+// questionnaireText lays a questionnaire out as appendix text: its instructions, then
+// each section with its items numbered throughout and their answer options below them
+func questionnaireText(q apimodels.QuestionnaireResponse, labels map[string]string) string {
+	var b strings.Builder
+	if q.Instructions != "" {
+		b.WriteString(q.Instructions + "\n")
+	}
+	number := 0
+	for _, section := range q.Sections {
+		b.WriteString(section.Title + "\n")
+		if section.Description != "" {
+			b.WriteString(section.Description + "\n")
+		}
+		for _, item := range section.Items {
+			number++
+			required := ""
+			if item.Required {
+				required = " *"
+			}
+			fmt.Fprintf(&b, "%d. %s%s\n", number, item.Text, required)
+			var answers []string
+			switch item.Type {
+			case "likert":
+				for i, label := range item.Options {
+					answers = append(answers, fmt.Sprintf("%d = %s", i+1, label))
+				}
+			case "single_choice":
+				for _, option := range item.Options {
+					answers = append(answers, "( ) "+option)
+				}
+			case "multiple_choice":
+				for _, option := range item.Options {
+					answers = append(answers, "[ ] "+option)
+				}
+			case "yes_no":
+				answers = []string{"( ) " + labels["yes"], "( ) " + labels["no"]}
+			case "open":
+				answers = []string{strings.Repeat("_", 60)}
+			}
+			b.WriteString(strings.Join(answers, "    ") + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Resources counted against quotas, per billing period
+const (
+	QuotaProjects  = "projects"  // Projects created
+	QuotaAIWords   = "ai_words"  // Words of AI generated content
+	QuotaDocuments = "documents" // Documents exported
+)
+
+// quotaResources lists the resources in the order they are reported
+var quotaResources = []string{QuotaProjects, QuotaAIWords, QuotaDocuments}
+
+// QuotaLimits caps a plan's usage per billing period; 0 means unlimited
+type QuotaLimits struct {
+	Projects  int64
+	AIWords   int64
+	Documents int64
+}
+
+func (l QuotaLimits) limit(resource string) int64 {
+	switch resource {
+	case QuotaProjects:
+		return l.Projects
+	case QuotaAIWords:
+		return l.AIWords
+	case QuotaDocuments:
+		return l.Documents
+	}
+	return 0
+}
+
+// ErrQuotaReached is wrapped by every *QuotaExceededError
+var ErrQuotaReached = errors.New("quota for this billing period has been used up")
+
+// QuotaExceededError is returned when an action would take usage past the plan's quota.
+// Upgradable tells whether a higher plan has a larger quota for the resource.
+type QuotaExceededError struct {
+	Plan       string
+	Resource   string
+	Limit      int64
+	Used       int64
+	ResetsAt   time.Time
+	Upgradable bool
+}
+
+func (e *QuotaExceededError) Error() string {
+	name := strings.ReplaceAll(e.Resource, "_", " ")
+	return fmt.Sprintf("the %s plan allows %d %s per billing period and %d remain; usage resets at %s",
+		e.Plan, e.Limit, name, e.Remaining(), e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *QuotaExceededError) Unwrap() error { return ErrQuotaReached }
+
+// Remaining is how much of the quota is left this period
+func (e *QuotaExceededError) Remaining() int64 {
+	return max(e.Limit-e.Used, 0)
+}
+
+// QuotaService tracks per-period usage of projects, AI words and documents and enforces
+// the quotas of the user's plan. Usage is always tracked; quotas left at 0 are not enforced.
+type QuotaService struct {
+	store   db.Store
+	billing *BillingService
+	limits  map[string]QuotaLimits // Plan name to its quotas
+	logger  *applogger.AppLogger
+}
+
+func NewQuotaService(store db.Store, billing *BillingService, limits map[string]QuotaLimits, logger *applogger.AppLogger) *QuotaService {
+	return &QuotaService{store: store, billing: billing, limits: limits, logger: logger}
+}
+
+// quotaPeriod is the billing period usage is counted in
+type quotaPeriod struct {
+	plan       string
+	start, end time.Time
+}
+
+// period resolves the user's plan and the billing period containing now
+func (s *QuotaService) period(ctx context.Context, userID uuid.UUID) (quotaPeriod, error) {
+	plan, renewsAt, err := s.billing.CurrentPlan(ctx, userID)
+	if err != nil {
+		return quotaPeriod{}, err
+	}
+	start, end := billingPeriod(time.Now(), renewsAt)
+	return quotaPeriod{plan: plan, start: start, end: end}, nil
+}
+
+// billingPeriod returns the period containing now. Subscribers' periods start on their
+// billing day each month; everyone else's follow the calendar month (UTC).
+func billingPeriod(now time.Time, renewsAt *time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	if renewsAt == nil {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	anchor := renewsAt.UTC()
+	start := monthlyAnchor(now.Year(), now.Month(), anchor)
+	if start.After(now) {
+		start = monthlyAnchor(now.Year(), now.Month()-1, anchor)
+	}
+	return start, monthlyAnchor(start.Year(), start.Month()+1, anchor)
+}
+
+// monthlyAnchor is anchor's day and time of day in the given month, clamped to the
+// month's last day so a subscription renewing on the 31st resets on the 30th in June
+func monthlyAnchor(year int, month time.Month, anchor time.Time) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC) // Normalizes month overflow
+	return time.Date(first.Year(), first.Month(), min(anchor.Day(), lastDay), anchor.Hour(), anchor.Minute(), anchor.Second(), 0, time.UTC)
+}
+
+// upgradable reports whether another plan, which can only be bought with billing enabled,
+// has a larger quota for resource
+func (s *QuotaService) upgradable(plan, resource string) bool {
+	if !s.billing.Enabled() {
+		return false
+	}
+	current := s.limits[plan].limit(resource)
+	for name, limits := range s.limits {
+		if name == plan {
+			continue
+		}
+		if other := limits.limit(resource); other == 0 || other > current {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *QuotaService) exceeded(p quotaPeriod, resource string, used int64) *QuotaExceededError {
+	return &QuotaExceededError{
+		Plan:       p.plan,
+		Resource:   resource,
+		Limit:      s.limits[p.plan].limit(resource),
+		Used:       used,
+		ResetsAt:   p.end,
+		Upgradable: s.upgradable(p.plan, resource),
+	}
+}
+
+// used returns the user's usage of resource in period p
+func (s *QuotaService) used(ctx context.Context, userID uuid.UUID, p quotaPeriod, resource string) (int64, error) {
+	rows, err := s.store.ListQuotaUsage(db.WithPrimary(ctx), sqlc.ListQuotaUsageParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		PeriodStart: pgtype.Timestamptz{Time: p.start, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("database error fetching quota usage: %w", err)
+	}
+	for _, row := range rows {
+		if row.Resource == resource {
+			return row.Used, nil
+		}
+	}
+	return 0, nil
+}
+
+// Reserve counts amount of resource against the user's quota, failing with a
+// *QuotaExceededError if that would go past it. The returned func gives the amount
+// back if the action fails.
+func (s *QuotaService) Reserve(ctx context.Context, userID uuid.UUID, resource string, amount int64) (func(), error) {
+	noop := func() {}
+	p, err := s.period(ctx, userID)
+	if err != nil {
+		return noop, err
+	}
+	pgUserID := pgtype.UUID{Bytes: userID, Valid: true}
+	pgStart := pgtype.Timestamptz{Time: p.start, Valid: true}
+	limit := s.limits[p.plan].limit(resource)
+	if limit == 0 {
+		err = s.store.AddQuotaUsage(ctx, sqlc.AddQuotaUsageParams{UserID: pgUserID, PeriodStart: pgStart, Resource: resource, Amount: amount})
+	} else if amount > limit {
+		return noop, s.exceeded(p, resource, 0)
+	} else {
+		_, err = s.store.ReserveQuota(ctx, sqlc.ReserveQuotaParams{UserID: pgUserID, PeriodStart: pgStart, Resource: resource, Amount: amount, QuotaLimit: limit})
+	}
+	if err != nil {
+		if isNoRows(err) {
+			used, usedErr := s.used(ctx, userID, p, resource)
+			if usedErr != nil {
+				return noop, usedErr
+			}
+			return noop, s.exceeded(p, resource, used)
+		}
+		return noop, fmt.Errorf("database error reserving quota: %w", err)
+	}
+	return func() {
+		err := s.store.ReleaseQuota(context.WithoutCancel(ctx), sqlc.ReleaseQuotaParams{Amount: amount, UserID: pgUserID, PeriodStart: pgStart, Resource: resource})
+		if err != nil {
+			s.logger.Error("Failed to release quota", "userID", userID, "resource", resource, "amount", amount, "error", err)
+		}
+	}, nil
+}
+
+// Release gives back amount of resource counted this billing period, for actions that
+// fail after the func returned by Reserve is gone, such as a document generation job
+func (s *QuotaService) Release(ctx context.Context, userID uuid.UUID, resource string, amount int64) {
+	p, err := s.period(ctx, userID)
+	if err == nil {
+		err = s.store.ReleaseQuota(ctx, sqlc.ReleaseQuotaParams{
+			Amount:      amount,
+			UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+			PeriodStart: pgtype.Timestamptz{Time: p.start, Valid: true},
+			Resource:    resource,
+		})
+	}
+	if err != nil {
+		s.logger.Error("Failed to release quota", "userID", userID, "resource", resource, "amount", amount, "error", err)
+	}
+}
+
+// Check fails with a *QuotaExceededError when nothing of resource's quota is left.
+// It is used ahead of actions whose cost is only known afterwards, see Record.
+func (s *QuotaService) Check(ctx context.Context, userID uuid.UUID, resource string) error {
+	p, err := s.period(ctx, userID)
+	if err != nil {
+		return err
+	}
+	limit := s.limits[p.plan].limit(resource)
+	if limit == 0 {
+		return nil
+	}
+	used, err := s.used(ctx, userID, p, resource)
+	if err != nil {
+		return err
+	}
+	if used >= limit {
+		return s.exceeded(p, resource, used)
+	}
+	return nil
+}
+
+// Record adds usage that was only known once the action finished. It may take usage
+// past the quota, which then blocks the next Check. Failures are logged, not returned,
+// as the action itself already succeeded.
+func (s *QuotaService) Record(ctx context.Context, userID uuid.UUID, resource string, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	p, err := s.period(ctx, userID)
+	if err == nil {
+		err = s.store.AddQuotaUsage(context.WithoutCancel(ctx), sqlc.AddQuotaUsageParams{
+			UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+			PeriodStart: pgtype.Timestamptz{Time: p.start, Valid: true},
+			Resource:    resource,
+			Amount:      amount,
+		})
+	}
+	if err != nil {
+		s.logger.Error("Failed to record quota usage", "userID", userID, "resource", resource, "amount", amount, "error", err)
+	}
+}
+
+type aiWordsQuotaKey struct{}
+
+// aiWordsQuota is the quota the AI calls of a context count against
+type aiWordsQuota struct {
+	quotas *QuotaService
+	userID uuid.UUID
+}
+
+// withAIWords returns a context whose AI calls count against userID's AI words quota.
+// callOpenAI, which every generation goes through, checks the quota before each call and
+// records the words of each reply, so entry points only say whose quota it is.
+func (s *QuotaService) withAIWords(ctx context.Context, userID uuid.UUID) context.Context {
+	return context.WithValue(ctx, aiWordsQuotaKey{}, aiWordsQuota{quotas: s, userID: userID})
+}
+
+// checkAIWords fails with a *QuotaExceededError when the AI words quota attached to ctx,
+// if any, is used up
+func checkAIWords(ctx context.Context) error {
+	if q, ok := ctx.Value(aiWordsQuotaKey{}).(aiWordsQuota); ok {
+		return q.quotas.Check(ctx, q.userID, QuotaAIWords)
+	}
+	return nil
+}
+
+// recordAIWords charges the words of a reply to the AI words quota attached to ctx, if any
+func recordAIWords(ctx context.Context, reply string) {
+	if q, ok := ctx.Value(aiWordsQuotaKey{}).(aiWordsQuota); ok {
+		q.quotas.Record(ctx, q.userID, QuotaAIWords, countWords(reply))
+	}
+}
+
+// Usage returns the user's plan, the current billing period and usage of every quota in it
+func (s *QuotaService) Usage(ctx context.Context, userID uuid.UUID) (apimodels.QuotaResponse, error) {
+	p, err := s.period(ctx, userID)
+	if err != nil {
+		return apimodels.QuotaResponse{}, err
+	}
+	rows, err := s.store.ListQuotaUsage(ctx, sqlc.ListQuotaUsageParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		PeriodStart: pgtype.Timestamptz{Time: p.start, Valid: true},
+	})
+	if err != nil {
+		return apimodels.QuotaResponse{}, fmt.Errorf("database error fetching quota usage: %w", err)
+	}
+	used := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		used[row.Resource] = row.Used
+	}
+
+	resp := apimodels.QuotaResponse{
+		Plan:        p.plan,
+		PeriodStart: p.start,
+		PeriodEnd:   p.end,
+		Quotas:      make([]apimodels.QuotaUsageResponse, 0, len(quotaResources)),
+	}
+	for _, resource := range quotaResources {
+		resp.Quotas = append(resp.Quotas, apimodels.NewQuotaUsageResponse(resource, s.limits[p.plan].limit(resource), used[resource]))
+	}
+	return resp, nil
+}
+
+// countWords counts whitespace separated words, as charged to the AI words quota
+func countWords(content string) int64 {
+	return int64(len(strings.Fields(content)))
+}
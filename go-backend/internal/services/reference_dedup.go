@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// minTitleSimilarity is how alike two normalized titles of the same year must be to count
+// as the same paper; it absorbs punctuation, a dropped word or a typo in a long title
+const minTitleSimilarity = 0.9
+
+// referenceIndex finds the existing reference a new one duplicates, whether it came from
+// manual entry, generation or a Semantic Scholar import
+type referenceIndex struct {
+	refs   []sqlc.Reference
+	byDOI  map[string]int
+	titles []string // Normalized, by position in refs
+}
+
+// projectReferenceIndex indexes a project's references outside the trash
+func projectReferenceIndex(ctx context.Context, q *sqlc.Queries, projectID pgtype.UUID) (*referenceIndex, error) {
+	refs, err := q.GetReferencesByProjectID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load references: %w", err)
+	}
+	index := &referenceIndex{byDOI: make(map[string]int)}
+	for _, ref := range refs {
+		index.add(ref)
+	}
+	return index, nil
+}
+
+func (x *referenceIndex) add(ref sqlc.Reference) {
+	x.refs = append(x.refs, ref)
+	x.titles = append(x.titles, normalizeTitle(ref.Title))
+	if doi := normalizeDOI(ref.Doi.String); doi != "" {
+		x.byDOI[doi] = len(x.refs) - 1
+	}
+}
+
+// replace updates the indexed copy of a reference after a merge
+func (x *referenceIndex) replace(i int, ref sqlc.Reference) {
+	x.refs[i] = ref
+	if doi := normalizeDOI(ref.Doi.String); doi != "" {
+		x.byDOI[doi] = i
+	}
+}
+
+// find returns the position of the reference params duplicate: one with the same DOI,
+// or else, when the DOIs do not differ, one with a near-identical title from the same year
+func (x *referenceIndex) find(params sqlc.CreateReferenceParams) (int, bool) {
+	doi := normalizeDOI(params.Doi.String)
+	if i, ok := x.byDOI[doi]; ok && doi != "" {
+		return i, true
+	}
+	title := normalizeTitle(params.Title)
+	if title == "" {
+		return 0, false
+	}
+	for i, ref := range x.refs {
+		if other := normalizeDOI(ref.Doi.String); doi != "" && other != "" {
+			continue // Both have DOIs and they differ
+		}
+		if params.PublicationYear.Valid && ref.PublicationYear.Valid && params.PublicationYear.Int32 != ref.PublicationYear.Int32 {
+			continue
+		}
+		if titleSimilarity(title, x.titles[i]) >= minTitleSimilarity {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// saveReference creates a reference, or merges it into the one it duplicates and
+// reports true
+func saveReference(ctx context.Context, q *sqlc.Queries, index *referenceIndex, params sqlc.CreateReferenceParams) (sqlc.Reference, bool, error) {
+	if i, ok := index.find(params); ok {
+		ref, err := q.MergeReference(ctx, sqlc.MergeReferenceParams{
+			ID:              index.refs[i].ID,
+			Authors:         params.Authors,
+			Journal:         params.Journal,
+			PublicationYear: params.PublicationYear,
+			Doi:             params.Doi,
+			Url:             params.Url,
+			CitationApa:     params.CitationApa,
+			CitationMla:     params.CitationMla,
+			Abstract:        params.Abstract,
+		})
+		if err != nil {
+			return sqlc.Reference{}, false, fmt.Errorf("could not merge reference: %w", err)
+		}
+		index.replace(i, ref)
+		return ref, true, nil
+	}
+	ref, err := q.CreateReference(ctx, params)
+	if err != nil {
+		return sqlc.Reference{}, false, fmt.Errorf("could not create reference: %w", err)
+	}
+	index.add(ref)
+	return ref, false, nil
+}
+
+// normalizeTitle lowercases a title and reduces it to its letters and digits, one space
+// between words
+func normalizeTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// titleSimilarity is 1 minus the edit distance between two titles relative to the longer
+func titleSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	// Titles whose lengths differ this much cannot reach the threshold
+	if float64(abs(len(ra)-len(rb)))/float64(longest) > 1-minTitleSimilarity {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Reference export formats, for reference managers
+const (
+	ReferenceExportCSLJSON   = "csl-json"   // Citation Style Language JSON, read by Zotero, Mendeley and citeproc
+	ReferenceExportZoteroRDF = "zotero-rdf" // Zotero's RDF, which keeps tags and abstracts on import
+)
+
+var ErrInvalidExportFormat = errors.New("unknown export format, use csl-json or zotero-rdf")
+
+// ReferenceExport is a project's references serialized for download
+type ReferenceExport struct {
+	Data        []byte
+	ContentType string
+	FileName    string
+}
+
+// ExportReferences serializes a project's references, narrowed to those carrying any of
+// tags when given. Tags are exported as keywords.
+func (s *ResearchService) ExportReferences(ctx context.Context, projectID, userID uuid.UUID, format string, tags []string) (ReferenceExport, error) {
+	if format != ReferenceExportCSLJSON && format != ReferenceExportZoteroRDF {
+		return ReferenceExport{}, ErrInvalidExportFormat
+	}
+	refs, err := s.ListReferences(ctx, projectID, userID, tags)
+	if err != nil {
+		return ReferenceExport{}, err
+	}
+	if format == ReferenceExportZoteroRDF {
+		data, err := zoteroRDF(refs)
+		if err != nil {
+			return ReferenceExport{}, err
+		}
+		return ReferenceExport{Data: data, ContentType: "application/rdf+xml", FileName: "references.rdf"}, nil
+	}
+	data, err := cslJSON(refs)
+	if err != nil {
+		return ReferenceExport{}, err
+	}
+	return ReferenceExport{Data: data, ContentType: "application/vnd.citationstyles.csl+json", FileName: "references.json"}, nil
+}
+
+// authorName is one author split into family and given names. Names that cannot be
+// split, such as organizations, are kept whole as Family.
+type authorName struct {
+	Family string
+	Given  string
+}
+
+// splitAuthors parses the free-text authors field, as entered by hand ("Smith, J., &
+// Doe, A.") or imported ("John Smith, Ann Doe"), into names
+func splitAuthors(authors string) []authorName {
+	var parts []string
+	for _, group := range strings.FieldsFunc(strings.NewReplacer("&", ";", " and ", ";").Replace(authors), func(r rune) bool { return r == ';' }) {
+		parts = append(parts, splitCommaNames(group)...)
+	}
+	var names []authorName
+	for _, part := range parts {
+		part = strings.Trim(strings.TrimSpace(part), ",")
+		if part == "" || part == "et al." || part == "et al" {
+			continue
+		}
+		if family, given, ok := strings.Cut(part, ","); ok { // Inverted: Smith, J.
+			names = append(names, authorName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)})
+			continue
+		}
+		words := strings.Fields(part)
+		switch {
+		case len(words) == 1:
+			names = append(names, authorName{Family: words[0]})
+		case isInitials(words[len(words)-1]): // Smith J
+			names = append(names, authorName{Family: words[0], Given: strings.Join(words[1:], " ")})
+		default:
+			names = append(names, authorName{Family: words[len(words)-1], Given: strings.Join(words[:len(words)-1], " ")})
+		}
+	}
+	return names
+}
+
+// splitCommaNames splits a comma-separated run of names. In "Smith, J., Doe, A." every
+// second item is initials and belongs to the surname before it.
+func splitCommaNames(group string) []string {
+	items := strings.Split(group, ",")
+	paired := len(items) > 1
+	for i := 1; i < len(items); i += 2 {
+		if !isInitials(strings.Join(strings.Fields(items[i]), "")) {
+			paired = false
+			break
+		}
+	}
+	if !paired {
+		return items
+	}
+	var names []string
+	for i := 0; i < len(items); i += 2 {
+		name := items[i]
+		if i+1 < len(items) {
+			name += "," + items[i+1]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// cslItem is a CSL-JSON item, https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Author         []cslName `json:"author,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+	Keyword        string    `json:"keyword,omitempty"`
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+func cslJSON(refs []apimodels.ReferenceResponse) ([]byte, error) {
+	items := make([]cslItem, len(refs))
+	for i, ref := range refs {
+		item := cslItem{
+			ID:             ref.ID.String(),
+			Type:           "article",
+			Title:          ref.Title,
+			ContainerTitle: ref.Journal,
+			DOI:            ref.DOI,
+			URL:            ref.URL,
+			Abstract:       ref.Abstract,
+			Keyword:        strings.Join(ref.Tags, ", "),
+		}
+		if ref.Journal != "" {
+			item.Type = "article-journal"
+		}
+		if ref.PublicationYear > 0 {
+			item.Issued = &cslDate{DateParts: [][]int{{ref.PublicationYear}}}
+		}
+		for _, name := range splitAuthors(ref.Authors) {
+			if name.Given == "" {
+				item.Author = append(item.Author, cslName{Literal: name.Family})
+			} else {
+				item.Author = append(item.Author, cslName{Family: name.Family, Given: name.Given})
+			}
+		}
+		items[i] = item
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Titles and URLs keep their & and <
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(items); err != nil {
+		return nil, fmt.Errorf("could not encode CSL-JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Zotero RDF, as Zotero itself exports it. encoding/xml writes prefixed names verbatim,
+// so the namespaces are declared once on the root.
+type rdfDocument struct {
+	XMLName xml.Name `xml:"rdf:RDF"`
+	RDF     string   `xml:"xmlns:rdf,attr"`
+	Z       string   `xml:"xmlns:z,attr"`
+	DC      string   `xml:"xmlns:dc,attr"`
+	DCTerms string   `xml:"xmlns:dcterms,attr"`
+	Bib     string   `xml:"xmlns:bib,attr"`
+	FOAF    string   `xml:"xmlns:foaf,attr"`
+	Items   []rdfItem
+}
+
+// rdfItem is named bib:Article or bib:Document by its XMLName
+type rdfItem struct {
+	XMLName    xml.Name
+	About      string         `xml:"rdf:about,attr"`
+	ItemType   string         `xml:"z:itemType"`
+	IsPartOf   *rdfJournal    `xml:"dcterms:isPartOf>bib:Journal"`
+	Authors    []rdfPerson    `xml:"bib:authors>rdf:Seq>rdf:li"`
+	Subjects   []string       `xml:"dc:subject"`
+	Title      string         `xml:"dc:title"`
+	Abstract   string         `xml:"dcterms:abstract,omitempty"`
+	Date       string         `xml:"dc:date,omitempty"`
+	Identifier *rdfIdentifier `xml:"dc:identifier"`
+}
+
+type rdfJournal struct {
+	Title      string `xml:"dc:title,omitempty"`
+	Identifier string `xml:"dc:identifier,omitempty"`
+}
+
+// rdfPerson is one rdf:li of the authors sequence
+type rdfPerson struct {
+	Surname   string `xml:"foaf:Person>foaf:surname"`
+	GivenName string `xml:"foaf:Person>foaf:givenName,omitempty"`
+}
+
+type rdfIdentifier struct {
+	URI string `xml:"dcterms:URI>rdf:value"`
+}
+
+func zoteroRDF(refs []apimodels.ReferenceResponse) ([]byte, error) {
+	doc := rdfDocument{
+		RDF:     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+		Z:       "http://www.zotero.org/namespaces/export#",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		DCTerms: "http://purl.org/dc/terms/",
+		Bib:     "http://purl.org/net/biblio#",
+		FOAF:    "http://xmlns.com/foaf/0.1/",
+	}
+	for _, ref := range refs {
+		item := rdfItem{
+			About:    "urn:uuid:" + ref.ID.String(),
+			Subjects: ref.Tags,
+			Title:    ref.Title,
+			Abstract: ref.Abstract,
+		}
+		if ref.PublicationYear > 0 {
+			item.Date = fmt.Sprint(ref.PublicationYear)
+		}
+		if ref.URL != "" {
+			item.Identifier = &rdfIdentifier{URI: ref.URL}
+		}
+		for _, name := range splitAuthors(ref.Authors) {
+			item.Authors = append(item.Authors, rdfPerson{Surname: name.Family, GivenName: name.Given})
+		}
+		// Zotero reads a journal article's DOI from its journal; only articles carry one
+		if ref.Journal != "" || ref.DOI != "" {
+			item.XMLName.Local, item.ItemType = "bib:Article", "journalArticle"
+			item.IsPartOf = &rdfJournal{Title: ref.Journal}
+			if ref.DOI != "" {
+				item.IsPartOf.Identifier = "DOI " + ref.DOI
+			}
+		} else {
+			item.XMLName.Local, item.ItemType = "bib:Document", "document"
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not encode Zotero RDF: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrHighlightNotFound = errors.New("highlight not found")
+
+// Annotation budgets for literature review prompts, in characters
+const (
+	maxNoteChars            = 1500 // Per reference's notes
+	maxHighlightChars       = 500  // Per highlighted passage
+	maxTotalAnnotationChars = 8000 // Across a project's references
+)
+
+// GetReferenceNotes returns a reference's notes and highlighted passages
+func (s *ResearchService) GetReferenceNotes(ctx context.Context, projectID, referenceID, userID uuid.UUID) (apimodels.ReferenceNotesResponse, error) {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.ReferenceNotesResponse{}, err
+	}
+	notes, err := s.store.GetReferenceNotes(ctx, ref.ID)
+	if err != nil && !isNoRows(err) {
+		return apimodels.ReferenceNotesResponse{}, fmt.Errorf("database error fetching reference notes: %w", err)
+	}
+	return s.referenceNotesResponse(ctx, ref, notes)
+}
+
+// UpdateReferenceNotes replaces a reference's notes. Requires the edit role.
+func (s *ResearchService) UpdateReferenceNotes(ctx context.Context, projectID, referenceID, userID uuid.UUID, notes string) (apimodels.ReferenceNotesResponse, error) {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.ReferenceNotesResponse{}, err
+	}
+	saved, err := s.store.UpsertReferenceNotes(ctx, sqlc.UpsertReferenceNotesParams{
+		ReferenceID: ref.ID,
+		ProjectID:   ref.ProjectID,
+		Notes:       markdown.Sanitize(strings.TrimSpace(notes)),
+		UpdatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return apimodels.ReferenceNotesResponse{}, fmt.Errorf("could not save reference notes: %w", err)
+	}
+	return s.referenceNotesResponse(ctx, ref, saved)
+}
+
+// AddReferenceHighlight records a passage highlighted in a reference. Requires the edit role.
+func (s *ResearchService) AddReferenceHighlight(ctx context.Context, projectID, referenceID, userID uuid.UUID, req apimodels.CreateReferenceHighlightRequest) (sqlc.ReferenceHighlight, error) {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ReferenceHighlight{}, err
+	}
+	text := markdown.Sanitize(strings.TrimSpace(req.Text))
+	if text == "" {
+		return sqlc.ReferenceHighlight{}, ErrEmptyAfterSanitizing
+	}
+	var page pgtype.Int4
+	if req.Page != nil {
+		page = pgtype.Int4{Int32: *req.Page, Valid: true}
+	}
+	highlight, err := s.store.CreateReferenceHighlight(ctx, sqlc.CreateReferenceHighlightParams{
+		ReferenceID: ref.ID,
+		ProjectID:   ref.ProjectID,
+		Text:        text,
+		Comment:     markdown.Sanitize(strings.TrimSpace(req.Comment)),
+		Page:        page,
+		CreatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return sqlc.ReferenceHighlight{}, fmt.Errorf("could not save highlight: %w", err)
+	}
+	return highlight, nil
+}
+
+// DeleteReferenceHighlight removes a highlighted passage. Requires the edit role.
+func (s *ResearchService) DeleteReferenceHighlight(ctx context.Context, projectID, referenceID, highlightID, userID uuid.UUID) error {
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleEdit)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.DeleteReferenceHighlight(ctx, sqlc.DeleteReferenceHighlightParams{
+		ID:          pgtype.UUID{Bytes: highlightID, Valid: true},
+		ReferenceID: ref.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrHighlightNotFound
+		}
+		return fmt.Errorf("could not delete highlight: %w", err)
+	}
+	return nil
+}
+
+// projectReference checks the user's role on the project and that the reference belongs
+// to it and is not in the trash
+func (s *ResearchService) projectReference(ctx context.Context, projectID, referenceID, userID uuid.UUID, role string) (sqlc.Reference, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, role); err != nil {
+		return sqlc.Reference{}, err
+	}
+	ref, err := s.store.GetProjectReference(ctx, sqlc.GetProjectReferenceParams{
+		ID:        pgtype.UUID{Bytes: referenceID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Reference{}, ErrReferenceNotFound
+		}
+		return sqlc.Reference{}, fmt.Errorf("database error fetching reference: %w", err)
+	}
+	return ref, nil
+}
+
+func (s *ResearchService) referenceNotesResponse(ctx context.Context, ref sqlc.Reference, notes sqlc.ReferenceNote) (apimodels.ReferenceNotesResponse, error) {
+	highlights, err := s.store.ListReferenceHighlights(ctx, ref.ID)
+	if err != nil {
+		return apimodels.ReferenceNotesResponse{}, fmt.Errorf("database error fetching highlights: %w", err)
+	}
+	resp := apimodels.ReferenceNotesResponse{
+		ReferenceID: ref.ID.Bytes,
+		Notes:       notes.Notes,
+		Highlights:  make([]apimodels.ReferenceHighlightResponse, len(highlights)),
+	}
+	for i, h := range highlights {
+		resp.Highlights[i] = apimodels.ToReferenceHighlightResponse(h)
+	}
+	if notes.UpdatedAt.Valid {
+		resp.UpdatedAt = &notes.UpdatedAt.Time
+	}
+	return resp, nil
+}
+
+// annotatedReferences returns the notes and highlights on a project's references, trimmed
+// to the prompt budget. A failure is logged and generation carries on without them.
+func (s *ResearchService) annotatedReferences(ctx context.Context, projectID uuid.UUID) []AnnotatedReference {
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	refs, err := s.store.ListProjectAnnotatedReferences(ctx, pgProjectID)
+	if err != nil {
+		s.logger.Error("Failed to load reference notes", "projectID", projectID, "error", err)
+		return nil
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	byReference, err := s.highlightsByReference(ctx, pgProjectID)
+	if err != nil {
+		s.logger.Error("Failed to load reference highlights", "projectID", projectID, "error", err)
+		return nil
+	}
+
+	var annotated []AnnotatedReference
+	budget := maxTotalAnnotationChars
+	for _, r := range refs {
+		if budget <= 0 {
+			break
+		}
+		a := AnnotatedReference{Citation: promptCitation(r.Title, r.Authors, r.PublicationYear)}
+		a.Notes, a.Highlights = annotations(r.Notes, byReference[r.ID.Bytes], &budget)
+		annotated = append(annotated, a)
+	}
+	return annotated
+}
+
+func (s *ResearchService) highlightsByReference(ctx context.Context, projectID pgtype.UUID) (map[uuid.UUID][]sqlc.ReferenceHighlight, error) {
+	highlights, err := s.store.ListProjectReferenceHighlights(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	byReference := make(map[uuid.UUID][]sqlc.ReferenceHighlight)
+	for _, h := range highlights {
+		byReference[h.ReferenceID.Bytes] = append(byReference[h.ReferenceID.Bytes], h)
+	}
+	return byReference, nil
+}
+
+// promptCitation names a reference well enough for the model to cite it
+func promptCitation(title string, authors pgtype.Text, year pgtype.Int4) string {
+	citation := title
+	if authors.String != "" {
+		citation = authors.String + ": " + title
+	}
+	if year.Valid {
+		citation += fmt.Sprintf(" (%d)", year.Int32)
+	}
+	return citation
+}
+
+// annotations trims a reference's notes and highlights to what is left of the budget,
+// charging what it keeps
+func annotations(notes string, highlights []sqlc.ReferenceHighlight, budget *int) (string, []string) {
+	if *budget <= 0 {
+		return "", nil
+	}
+	notes = truncateRunes(notes, min(maxNoteChars, *budget))
+	*budget -= utf8.RuneCountInString(notes)
+	var kept []string
+	for _, h := range highlights {
+		if *budget <= 0 {
+			break
+		}
+		text := h.Text
+		if h.Comment != "" {
+			text += " — " + h.Comment
+		}
+		text = truncateRunes(text, min(maxHighlightChars, *budget))
+		*budget -= utf8.RuneCountInString(text)
+		kept = append(kept, text)
+	}
+	return notes, kept
+}
+
+// truncateRunes cuts text to at most limit characters, marking the cut with an ellipsis
+func truncateRunes(text string, limit int) string {
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:max(limit, 0)]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Reference tags, by the role a reference plays in the thesis
+const (
+	ReferenceTagMethodology   = "methodology"
+	ReferenceTagSeminal       = "seminal"
+	ReferenceTagRecent        = "recent"
+	ReferenceTagContradictory = "contradictory"
+)
+
+var referenceTags = []string{ReferenceTagMethodology, ReferenceTagSeminal, ReferenceTagRecent, ReferenceTagContradictory}
+
+var (
+	ErrInvalidReferenceTag = errors.New("unknown reference tag")
+	ErrNoTaggedReferences  = errors.New("no references in the project carry this tag")
+)
+
+// Budgets for the references of a tag-scoped literature review, in characters. Every
+// tagged reference is listed; abstracts and notes stop once the total is spent.
+const (
+	maxAbstractChars    = 800
+	maxTotalScopedChars = 16000
+)
+
+// ValidReferenceTag reports whether tag is one of the reference tags
+func ValidReferenceTag(tag string) bool {
+	return slices.Contains(referenceTags, tag)
+}
+
+// ListReferences returns a project's references with their tags, narrowed to those
+// carrying any of tags when given
+func (s *ResearchService) ListReferences(ctx context.Context, projectID, userID uuid.UUID, tags []string) ([]apimodels.ReferenceResponse, error) {
+	for _, tag := range tags {
+		if !ValidReferenceTag(tag) {
+			return nil, ErrInvalidReferenceTag
+		}
+	}
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	var refs []sqlc.Reference
+	var err error
+	if len(tags) > 0 {
+		refs, err = s.store.GetReferencesByTags(ctx, sqlc.GetReferencesByTagsParams{ProjectID: pgProjectID, Tags: tags})
+	} else {
+		refs, err = s.store.GetReferencesByProjectID(ctx, pgProjectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching references: %w", err)
+	}
+	tagged, err := s.store.ListProjectReferenceTags(ctx, pgProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching reference tags: %w", err)
+	}
+	byReference := make(map[uuid.UUID][]string)
+	for _, t := range tagged {
+		byReference[t.ReferenceID.Bytes] = append(byReference[t.ReferenceID.Bytes], t.Tag)
+	}
+
+	resp := make([]apimodels.ReferenceResponse, len(refs))
+	for i, ref := range refs {
+		resp[i] = apimodels.ToReferenceResponse(ref)
+		resp[i].Tags = byReference[ref.ID.Bytes]
+	}
+	return resp, nil
+}
+
+// SetReferenceTags replaces a reference's tags. Requires the edit role.
+func (s *ResearchService) SetReferenceTags(ctx context.Context, projectID, referenceID, userID uuid.UUID, tags []string) (apimodels.ReferenceResponse, error) {
+	for _, tag := range tags {
+		if !ValidReferenceTag(tag) {
+			return apimodels.ReferenceResponse{}, ErrInvalidReferenceTag
+		}
+	}
+	ref, err := s.projectReference(ctx, projectID, referenceID, userID, ProjectRoleEdit)
+	if err != nil {
+		return apimodels.ReferenceResponse{}, err
+	}
+	tags = slices.Compact(slices.Sorted(slices.Values(tags)))
+
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.DeleteReferenceTags(ctx, ref.ID); err != nil {
+			return fmt.Errorf("could not clear reference tags: %w", err)
+		}
+		for _, tag := range tags {
+			if err := q.AddReferenceTag(ctx, sqlc.AddReferenceTagParams{ReferenceID: ref.ID, ProjectID: ref.ProjectID, Tag: tag}); err != nil {
+				return fmt.Errorf("could not tag reference: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apimodels.ReferenceResponse{}, err
+	}
+	resp := apimodels.ToReferenceResponse(ref)
+	if len(tags) > 0 {
+		resp.Tags = tags
+	}
+	return resp, nil
+}
+
+// taggedReferences returns every reference carrying the tag, with abstracts, notes and
+// highlights as far as the budget allows, for a literature review limited to them
+func (s *ResearchService) taggedReferences(ctx context.Context, projectID uuid.UUID, tag string) ([]AnnotatedReference, error) {
+	if !ValidReferenceTag(tag) {
+		return nil, ErrInvalidReferenceTag
+	}
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	refs, err := s.store.ListProjectTaggedReferences(ctx, sqlc.ListProjectTaggedReferencesParams{ProjectID: pgProjectID, Tag: tag})
+	if err != nil {
+		return nil, fmt.Errorf("could not load tagged references: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil, ErrNoTaggedReferences
+	}
+	byReference, err := s.highlightsByReference(ctx, pgProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load reference highlights: %w", err)
+	}
+
+	scoped := make([]AnnotatedReference, len(refs))
+	budget := maxTotalScopedChars
+	for i, r := range refs {
+		a := AnnotatedReference{Citation: promptCitation(r.Title, r.Authors, r.PublicationYear)}
+		if budget > 0 && r.Abstract.String != "" {
+			a.Abstract = truncateRunes(r.Abstract.String, min(maxAbstractChars, budget))
+			budget -= utf8.RuneCountInString(a.Abstract)
+		}
+		a.Notes, a.Highlights = annotations(r.Notes, byReference[r.ID.Bytes], &budget)
+		scoped[i] = a
+	}
+	return scoped, nil
+}
@@ -7,56 +7,113 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
-	"unicode/utf8"
 
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
 	"github.com/shawgichan/research-service/go-backend/internal/db"
 	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
-	"github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/flags"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
 
 	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
 	apimodels "github.com/shawgichan/research-service/go-backend/internal/models" // API models
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
 	ErrProjectNotFound      = errors.New("project not found or access denied")
 	ErrChapterNotFound      = errors.New("chapter not found or access denied")
 	ErrChapterAlreadyExists = errors.New("chapter of this type already exists for the project")
+	ErrInvalidBulkChapters  = errors.New("invalid bulk chapter definitions")
+	ErrInvalidChapterOrder  = errors.New("chapter_ids must list each of the project's chapters once")
 	ErrReferenceNotFound    = errors.New("reference not found or access denied")
 	ErrDocumentNotFound     = errors.New("document not found or access denied")
+	ErrChapterConflict      = errors.New("chapter was modified by someone else")
+	ErrInsufficientRole     = errors.New("your role on this project does not allow this")
+	ErrEmptyAfterSanitizing = errors.New("text is empty once HTML is removed")
 )
 
+// ChapterConflictError is returned when an update carries a stale chapter version.
+// Latest holds the current chapter so the client can merge and retry.
+type ChapterConflictError struct {
+	Latest sqlc.Chapter
+}
+
+func (e *ChapterConflictError) Error() string { return ErrChapterConflict.Error() }
+func (e *ChapterConflictError) Unwrap() error { return ErrChapterConflict }
+
 type ResearchService struct {
-	store     db.Store
-	aiService *AIService
-	logger    *applogger.AppLogger
+	store         db.Store
+	aiService     *AIService
+	events        *events.Bus
+	notifications *NotificationService
+	webhooks      *WebhookService
+	billing       *BillingService
+	quotas        *QuotaService
+	jobs          *jobs.Queue
+	flags         *flags.Flags
+	docgen        DocGenConfig
+	docgenCircuit *breaker.Breaker
+	uploads       UploadConfig
+	integrity     IntegrityPolicy
+	timeouts      GenerationTimeouts
+	files         storage.Storage
+	pdfClient     *http.Client // Downloads open access PDFs, see newPaperPDFClient
+	logger        *applogger.AppLogger
 }
 
-// Add Python service URL to config or as a constant
-const pythonDocGenServiceURL = "http://localhost:8001" // Change if using Docker Compose service name
-const OUTPUT_DIR_FOR_GO = ""
+// DocGenConfig locates the Python document generation service
+type DocGenConfig struct {
+	URL          string        // Base URL, e.g. http://docgen:8001
+	SharedSecret string        // Sent as a bearer token; empty when the service runs without auth
+	OutputDir    string        // Where this process can read the files the service writes (shared volume)
+	Timeout      time.Duration // Upper bound for one generation request
+	PresignTTL   time.Duration // Downloads redirect to a presigned storage URL valid this long; 0 streams them through the API
+	Circuit      breaker.Config
+}
 
 type PythonDocGenRequest struct {
-	ProjectID         uuid.UUID              `json:"project_id"`
-	ResearchTitle     string                 `json:"research_title"`
-	StudentName       string                 `json:"student_name,omitempty"`
-	UniversityName    string                 `json:"university_name,omitempty"`
-	Specialization    string                 `json:"specialization,omitempty"`
-	Chapters          []PythonChapterData    `json:"chapters"`
-	References        []PythonReferenceData  `json:"references,omitempty"`
-	FormattingOptions map[string]interface{} `json:"formatting_options,omitempty"`
+	ProjectID         uuid.UUID                   `json:"project_id"`
+	ResearchTitle     string                      `json:"research_title"`
+	StudentName       string                      `json:"student_name,omitempty"`
+	UniversityName    string                      `json:"university_name,omitempty"`
+	Specialization    string                      `json:"specialization,omitempty"`
+	Chapters          []PythonChapterData         `json:"chapters"`
+	References        []PythonReferenceData       `json:"references,omitempty"`
+	Appendices        []PythonAppendixData        `json:"appendices,omitempty"`
+	Timeline          *PythonTimelineData         `json:"timeline,omitempty"`
+	FormattingOptions apimodels.FormattingOptions `json:"formatting_options"`
+	Direction         string                      `json:"direction"`         // ltr or rtl
+	Labels            map[string]string           `json:"labels"`            // Fixed strings in the document's language
+	Layout            string                      `json:"layout,omitempty"`  // thesis, or article for a condensed manuscript
+	Journal           string                      `json:"journal,omitempty"` // Journal an article is prepared for
+	FrontMatter       *apimodels.FrontMatter      `json:"front_matter,omitempty"`
 }
 type PythonChapterData struct {
-	Type    string `json:"type"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Type    string             `json:"type"`
+	Title   string             `json:"title"`
+	Content string             `json:"content"`
+	Figures []PythonFigureData `json:"figures,omitempty"`
+	// Sub-chapters, rendered after the chapter's own content
+	Sections []PythonSectionData `json:"sections,omitempty"`
+	// Share of the text AI generation wrote, noted under the heading; set in watermarked exports
+	AIShare float64 `json:"ai_share,omitempty"`
 }
 type PythonReferenceData struct {
 	CitationAPA string `json:"citation_apa,omitempty"`
@@ -67,26 +124,121 @@ type PythonDocGenResponse struct { // Matches Python service response
 	Message   string    `json:"message"`
 }
 
-func NewResearchService(store db.Store, aiService *AIService, logger *applogger.AppLogger) *ResearchService {
+func NewResearchService(store db.Store, aiService *AIService, eventBus *events.Bus, notifications *NotificationService, webhooks *WebhookService, billingSvc *BillingService, quotas *QuotaService, jobQueue *jobs.Queue, featureFlags *flags.Flags, docgen DocGenConfig, uploads UploadConfig, integrity IntegrityPolicy, timeouts GenerationTimeouts, files storage.Storage, logger *applogger.AppLogger) *ResearchService {
 	return &ResearchService{
-		store:     store,
-		aiService: aiService,
-		logger:    logger,
+		store:         store,
+		aiService:     aiService,
+		events:        eventBus,
+		notifications: notifications,
+		webhooks:      webhooks,
+		billing:       billingSvc,
+		quotas:        quotas,
+		jobs:          jobQueue,
+		flags:         featureFlags,
+		docgen:        docgen,
+		docgenCircuit: breaker.New("docgen", docgen.Circuit),
+		uploads:       uploads,
+		integrity:     integrity,
+		timeouts:      timeouts,
+		files:         files,
+		pdfClient:     newPaperPDFClient(),
+		logger:        logger,
+	}
+}
+
+// Background job kinds run by ResearchService
+const (
+	JobGenerateDocument       = "documents.generate"
+	JobEmbedReferences        = "references.embed"
+	JobEmbedMissingReferences = "references.embed_missing"
+	JobPurgeTrash             = "trash.purge"
+)
+
+// RegisterJobs registers the service's job handlers and schedules the hourly
+// trash, upload session and guest purges and, with embeddings enabled, the embedding
+// backfill
+func (s *ResearchService) RegisterJobs(trashRetention time.Duration) {
+	s.jobs.Register(JobGenerateDocument, jobs.Options{MaxAttempts: 3, Timeout: s.timeouts.Generation + s.docgen.Timeout + time.Minute}, s.runDocumentJob)
+	s.jobs.Register(JobEmbedReferences, jobs.Options{}, s.runEmbedJob)
+	s.jobs.Register(JobExtractUploadText, jobs.Options{MaxAttempts: 3, Timeout: s.docgen.Timeout + time.Minute}, s.runExtractTextJob)
+	s.jobs.Register(JobAssembleUpload, jobs.Options{MaxAttempts: 3, Timeout: 30 * time.Minute}, s.runAssembleUploadJob)
+	s.jobs.Register(JobPurgeUploadSessions, jobs.Options{MaxAttempts: 1}, func(ctx context.Context, _ jobs.Job) error {
+		return s.PurgeExpiredUploadSessions(ctx)
+	})
+	s.jobs.Register(JobFetchPaperPDF, jobs.Options{MaxAttempts: 3, Timeout: paperPDFTimeout + time.Minute}, s.runFetchPaperPDFJob)
+	s.jobs.Register(JobExtractPaperText, jobs.Options{MaxAttempts: 3, Timeout: s.docgen.Timeout + time.Minute}, s.runExtractPaperTextJob)
+	s.jobs.Register(JobExtractGuidelineRules, jobs.Options{MaxAttempts: 3, Timeout: s.docgen.Timeout + 2*time.Minute}, s.runGuidelineJob)
+	s.jobs.Register(JobEmbedMissingReferences, jobs.Options{MaxAttempts: 1, Timeout: 30 * time.Minute}, func(ctx context.Context, _ jobs.Job) error {
+		return s.EmbedMissingReferences(ctx)
+	})
+	s.jobs.Register(JobPurgeTrash, jobs.Options{MaxAttempts: 1}, func(ctx context.Context, _ jobs.Job) error {
+		return s.PurgeTrash(ctx, time.Now().Add(-trashRetention))
+	})
+	s.jobs.Register(JobRetryGeneration, jobs.Options{MaxAttempts: generationRetryAttempts, Timeout: s.timeouts.Generation + time.Minute}, s.runGenerationRetryJob)
+	s.jobs.Register(JobGenerateAll, jobs.Options{MaxAttempts: 3, Timeout: s.timeouts.Generation + time.Minute}, s.runGenerateAllJob)
+	s.jobs.Register(JobPurgeGuests, jobs.Options{MaxAttempts: 1}, func(ctx context.Context, _ jobs.Job) error {
+		return s.PurgeExpiredGuests(ctx)
+	})
+	s.jobs.Periodic(JobPurgeTrash, time.Hour)
+	s.jobs.Periodic(JobPurgeUploadSessions, time.Hour)
+	s.jobs.Periodic(JobPurgeGuests, time.Hour)
+	if s.aiService.EmbeddingsEnabled() {
+		s.jobs.Periodic(JobEmbedMissingReferences, time.Hour)
 	}
 }
 
+// progressEmitter scopes progress events to a project and, when set, a chapter or document.
+// The returned context carries the emitter so AIService can report progress too.
+func (s *ResearchService) progressEmitter(ctx context.Context, projectID uuid.UUID, chapterID, documentID *uuid.UUID) (context.Context, func(events.Event)) {
+	emit := func(e events.Event) {
+		e.ProjectID = projectID
+		e.ChapterID = chapterID
+		e.DocumentID = documentID
+		s.events.Publish(e)
+	}
+	return events.WithEmitter(ctx, emit), emit
+}
+
 func (s *ResearchService) CreateProject(ctx context.Context, userID uuid.UUID, req apimodels.CreateProjectRequest) (sqlc.ResearchProject, error) {
 	s.logger.Info("Creating project", "userID", userID, "title", req.Title)
+	var organizationID pgtype.UUID
+	if req.OrganizationID != nil {
+		// Only members can share a project with an organization
+		_, err := s.store.GetOrganizationMember(db.WithPrimary(ctx), sqlc.GetOrganizationMemberParams{
+			OrganizationID: pgtype.UUID{Bytes: *req.OrganizationID, Valid: true},
+			UserID:         pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			if isNoRows(err) {
+				return sqlc.ResearchProject{}, ErrOrganizationNotFound
+			}
+			return sqlc.ResearchProject{}, fmt.Errorf("database error fetching membership: %w", err)
+		}
+		organizationID = pgtype.UUID{Bytes: *req.OrganizationID, Valid: true}
+	}
+	if err := s.billing.CheckProjectQuota(ctx, userID); err != nil {
+		return sqlc.ResearchProject{}, err // The plan's cap on projects owned
+	}
+	releaseQuota, err := s.quotas.Reserve(ctx, userID, QuotaProjects, 1)
+	if err != nil {
+		return sqlc.ResearchProject{}, err
+	}
 	params := sqlc.CreateResearchProjectParams{
 		UserID:         pgtype.UUID{Bytes: userID, Valid: true},
 		Title:          req.Title,
 		Specialization: req.Specialization,
 		University:     pgtype.Text{String: req.University, Valid: req.University != ""},
 		Description:    pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		OrganizationID: organizationID,
+		Language:       req.Language,
 		// Status defaults to 'draft' in DB
 	}
+	if params.Language == "" {
+		params.Language = LanguageEnglish
+	}
 	project, err := s.store.CreateResearchProject(ctx, params)
 	if err != nil {
+		releaseQuota()
 		s.logger.Error("Failed to create project in DB", "userID", userID, "title", req.Title, "error", err)
 		return sqlc.ResearchProject{}, fmt.Errorf("could not create project: %w", err)
 	}
@@ -94,18 +246,25 @@ func (s *ResearchService) CreateProject(ctx context.Context, userID uuid.UUID, r
 	return project, nil
 }
 
+// GetUserProjectByID returns the project if the user can at least read it
 func (s *ResearchService) GetUserProjectByID(ctx context.Context, projectID, userID uuid.UUID) (sqlc.ResearchProject, error) {
+	project, _, err := s.GetProjectWithRole(ctx, projectID, userID)
+	return project, err
+}
+
+// GetProjectWithRole returns the project and the user's role on it: owner, edit, comment or read
+func (s *ResearchService) GetProjectWithRole(ctx context.Context, projectID, userID uuid.UUID) (sqlc.ResearchProject, string, error) {
 	s.logger.Info("Fetching project by ID", "projectID", projectID, "userID", userID)
-	project, err := s.store.GetResearchProjectByID(ctx, sqlc.GetResearchProjectByIDParams{ID: pgtype.UUID{Bytes: projectID, Valid: true}, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	row, err := s.store.GetProjectWithAccess(ctx, sqlc.GetProjectWithAccessParams{ID: pgtype.UUID{Bytes: projectID, Valid: true}, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
 			s.logger.Warn("Project not found or access denied", "projectID", projectID, "userID", userID)
-			return sqlc.ResearchProject{}, ErrProjectNotFound
+			return sqlc.ResearchProject{}, "", ErrProjectNotFound
 		}
 		s.logger.Error("Failed to get project by ID from DB", "projectID", projectID, "userID", userID, "error", err)
-		return sqlc.ResearchProject{}, fmt.Errorf("database error fetching project: %w", err)
+		return sqlc.ResearchProject{}, "", fmt.Errorf("database error fetching project: %w", err)
 	}
-	return project, nil
+	return row.ResearchProject, row.AccessRole, nil
 }
 
 func (s *ResearchService) GetUserProjects(ctx context.Context, userID uuid.UUID) ([]sqlc.ResearchProject, error) {
@@ -124,9 +283,9 @@ func (s *ResearchService) GetUserProjects(ctx context.Context, userID uuid.UUID)
 func (s *ResearchService) UpdateProject(ctx context.Context, projectID, userID uuid.UUID, req apimodels.UpdateProjectRequest) (sqlc.ResearchProject, error) {
 	s.logger.Info("Updating project", "projectID", projectID, "userID", userID)
 	// First, get the existing project to ensure it belongs to the user and to get current values
-	existingProject, err := s.GetUserProjectByID(ctx, projectID, userID)
+	existingProject, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
 	if err != nil {
-		return sqlc.ResearchProject{}, err // ErrProjectNotFound will be returned from GetUserProjectByID
+		return sqlc.ResearchProject{}, err
 	}
 
 	params := sqlc.UpdateResearchProjectParams{
@@ -137,6 +296,7 @@ func (s *ResearchService) UpdateProject(ctx context.Context, projectID, userID u
 		University:     existingProject.University,
 		Description:    existingProject.Description,
 		Status:         existingProject.Status,
+		Language:       existingProject.Language,
 	}
 
 	if req.Title != nil {
@@ -154,6 +314,9 @@ func (s *ResearchService) UpdateProject(ctx context.Context, projectID, userID u
 	if req.Status != nil {
 		params.Status = pgtype.Text{String: *req.Status, Valid: *req.Status != ""}
 	}
+	if req.Language != nil {
+		params.Language = *req.Language
+	}
 
 	updatedProject, err := s.store.UpdateResearchProject(ctx, params)
 	if err != nil {
@@ -166,16 +329,15 @@ func (s *ResearchService) UpdateProject(ctx context.Context, projectID, userID u
 
 func (s *ResearchService) DeleteProject(ctx context.Context, projectID, userID uuid.UUID) error {
 	s.logger.Info("Deleting project", "projectID", projectID, "userID", userID)
-	// Optional: Check if project exists and belongs to user first
-	// _, err := s.GetUserProjectByID(ctx, projectID, userID)
-	// if err != nil {
-	// 	return err
-	// }
-	err := s.store.DeleteResearchProject(ctx, sqlc.DeleteResearchProjectParams{ID: pgtype.UUID{Bytes: projectID, Valid: true}, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	// Organization members can edit a shared project, but only its owner or an organization owner/admin can delete it
+	rows, err := s.store.DeleteResearchProject(ctx, sqlc.DeleteResearchProjectParams{ID: pgtype.UUID{Bytes: projectID, Valid: true}, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
 	if err != nil {
 		s.logger.Error("Failed to delete project from DB", "projectID", projectID, "userID", userID, "error", err)
 		return fmt.Errorf("could not delete project: %w", err)
 	}
+	if rows == 0 {
+		return ErrProjectNotFound
+	}
 	s.logger.Info("Project deleted successfully", "projectID", projectID)
 	return nil
 }
@@ -184,11 +346,14 @@ func (s *ResearchService) DeleteProject(ctx context.Context, projectID, userID u
 
 func (s *ResearchService) CreateChapter(ctx context.Context, userID uuid.UUID, req apimodels.CreateChapterRequest) (sqlc.Chapter, error) {
 	s.logger.Info("Creating chapter", "projectID", req.ProjectID, "type", req.Type, "userID", userID)
-	// Verify user owns the project
-	_, err := s.GetUserProjectByID(ctx, req.ProjectID, userID)
+	// Verify user can edit the project
+	project, err := s.requireProjectRole(ctx, req.ProjectID, userID, ProjectRoleEdit)
 	if err != nil {
-		s.logger.Warn("User does not own project for chapter creation", "projectID", req.ProjectID, "userID", userID)
-		return sqlc.Chapter{}, ErrProjectNotFound
+		s.logger.Warn("User cannot edit project for chapter creation", "projectID", req.ProjectID, "userID", userID)
+		return sqlc.Chapter{}, err
+	}
+	if _, err := s.projectChapterType(ctx, project, req.Type); err != nil {
+		return sqlc.Chapter{}, err
 	}
 
 	// Check if chapter of this type already exists for the project
@@ -205,12 +370,19 @@ func (s *ResearchService) CreateChapter(ctx context.Context, userID uuid.UUID, r
 		return sqlc.Chapter{}, fmt.Errorf("db error: %w", err)
 	}
 
+	title := markdown.SanitizeLine(req.Title)
+	if title == "" {
+		return sqlc.Chapter{}, ErrEmptyAfterSanitizing
+	}
+	content, wordCount := chapterContent(req.Content)
 	params := sqlc.CreateChapterParams{
-		ProjectID: pgtype.UUID{Bytes: req.ProjectID, Valid: true},
-		Type:      req.Type,
-		Title:     req.Title,
-		Content:   pgtype.Text{String: req.Content, Valid: req.Content != ""},
-		WordCount: pgtype.Int4{Int32: int32(utf8.RuneCountInString(req.Content)), Valid: req.Content != ""}, // Basic word count
+		ProjectID:       pgtype.UUID{Bytes: req.ProjectID, Valid: true},
+		Type:            req.Type,
+		Title:           title,
+		Content:         content,
+		WordCount:       wordCount,
+		TargetWordCount: pgtype.Int4{Int32: req.TargetWordCount, Valid: req.TargetWordCount > 0},
+		CustomPrompt:    markdown.Sanitize(req.CustomPrompt),
 		// Status defaults to 'draft'
 	}
 	chapter, err := s.store.CreateChapter(ctx, params)
@@ -219,6 +391,7 @@ func (s *ResearchService) CreateChapter(ctx context.Context, userID uuid.UUID, r
 		return sqlc.Chapter{}, fmt.Errorf("could not create chapter: %w", err)
 	}
 	s.logger.Info("Chapter created successfully", "chapterID", chapter.ID)
+	s.trackChapterSave(ctx, chapter, ProvenanceHuman)
 	return chapter, nil
 }
 
@@ -241,120 +414,447 @@ func (s *ResearchService) GetProjectChapters(ctx context.Context, projectID, use
 	return chapters, nil
 }
 
-func (s *ResearchService) GetChapterByID(ctx context.Context, chapterID, userID uuid.UUID) (sqlc.Chapter, error) {
-	s.logger.Info("Fetching chapter by ID", "chapterID", chapterID, "userID", userID)
-	// This requires a more complex query or multiple queries to ensure user ownership through project
-	// For simplicity, we assume if a chapter is requested, its project ownership is checked elsewhere or it's fine.
-	// A better query would be: SELECT c.* FROM chapters c JOIN research_projects rp ON c.project_id = rp.id WHERE c.id = $1 AND rp.user_id = $2;
-	// As sqlc GetChapterByID is not defined, let's mock this for now.
-	// In a real app, you'd add such a query to query.sql
-
-	// Placeholder: In a real scenario, you'd query with user ID for security.
-	// This is a simplified GetChapter. You need to ensure the user owns the project this chapter belongs to.
-	// For example, get the chapter, then get its project_id, then check if user owns that project.
-	// Or, add a query like `GetChapterByIDAndUserID` to `query.sql`.
+func (s *ResearchService) GetChapterByID(ctx context.Context, chapterID, projectID, userID uuid.UUID) (sqlc.Chapter, error) {
+	s.logger.Info("Fetching chapter by ID", "chapterID", chapterID, "projectID", projectID, "userID", userID)
+	// Verify user owns the project, then scope the chapter lookup to that project
+	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	if err != nil {
+		return sqlc.Chapter{}, ErrProjectNotFound
+	}
 
-	// For now, let's assume the handlers ensure this via project checks first.
-	// If you need direct chapter fetch with auth, add a specific query.
-	s.logger.Warn("GetChapterByID needs a secure query ensuring user ownership via project.")
-	return sqlc.Chapter{}, errors.New("GetChapterByID requires a secure query; not implemented directly for now")
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return sqlc.Chapter{}, ErrChapterNotFound
+		}
+		s.logger.Error("Failed to get chapter from DB", "chapterID", chapterID, "error", err)
+		return sqlc.Chapter{}, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	return chapter, nil
 }
 
 func (s *ResearchService) UpdateChapter(ctx context.Context, chapterID, projectID, userID uuid.UUID, req apimodels.UpdateChapterRequest) (sqlc.Chapter, error) {
 	s.logger.Info("Updating chapter", "chapterID", chapterID, "userID", userID)
-	// Verify user owns the project this chapter belongs to
-	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
 	if err != nil {
-		s.logger.Warn("User does not own project for chapter update", "projectID", projectID, "userID", userID)
-		return sqlc.Chapter{}, ErrProjectNotFound
+		return sqlc.Chapter{}, err
 	}
-
-	// Get existing chapter to update its fields
-	// A query like GetChapterByIDAndProjectID would be good here.
-	// For now, we rely on the UpdateChapter sqlc query which should ideally also check project ownership.
-	// The provided query `UpdateChapter` does have a subquery for user check based on projectID and userID passed as $6 and $7.
-
-	// We need current values if not all fields are updated. sqlc's UpdateChapter updates specific fields.
-	// So, we need to get the chapter first to fill in non-updated fields IF the query updates all fields.
-	// The sqlc UpdateChapter query you provided updates only specific fields (title, content, word_count, status).
-	// So we don't strictly need to fetch it first *if* the query is designed that way.
-	// However, the sqlc query is `UPDATE chapters SET title = $2, content = $3, word_count = $4, updated_at = NOW() WHERE id = $1 RETURNING *;`
-	// It needs all values. So fetch first.
-
-	// Let's get the chapter details first to ensure we have all necessary fields for the update.
-	// This is a common pattern: fetch, modify, save.
-	// A better query would be `GetChapterByIDAndProjectID(ctx, chapterID, projectID)`
-	// For now, let's assume this check is sufficient.
-	// A truly robust way needs a specific `GetChapterByIDAndProjectID` query.
-
-	// The current sqlc query for UpdateChapter requires values for title, content, word_count, status.
-	// It would be better if the sqlc UpdateChapter query accepted nullable values for each field to update only provided ones.
-	// Let's assume the current query needs all fields:
-
-	// Get current chapter
-	var currentChapter sqlc.Chapter
-	// This is where a GetChapterByIDAndProjectID would be useful.
-	// Let's find it in the project's chapters as a workaround for now.
-	chapters, err := s.store.GetChaptersByProjectID(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	// Load the current values so fields missing from the request are kept as they are
+	currentChapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
 	if err != nil {
-		return sqlc.Chapter{}, fmt.Errorf("could not fetch chapters for update: %w", err)
-	}
-	found := false
-	for _, ch := range chapters {
-		if ch.ID.Bytes == chapterID {
-			currentChapter = ch
-			found = true
-			break
+		if errors.Is(err, ErrProjectNotFound) {
+			s.logger.Warn("User does not own project for chapter update", "projectID", projectID, "userID", userID)
 		}
+		return sqlc.Chapter{}, err
 	}
-	if !found {
-		return sqlc.Chapter{}, ErrChapterNotFound
+
+	// Fail early on a stale version; the UPDATE re-checks it atomically below
+	if req.Version != nil && *req.Version != currentChapter.Version {
+		return sqlc.Chapter{}, &ChapterConflictError{Latest: currentChapter}
+	}
+	if req.Status != nil {
+		if err := checkStatusChange(project, currentChapter.Status.String, *req.Status); err != nil {
+			return sqlc.Chapter{}, err
+		}
 	}
 
 	updateParams := sqlc.UpdateChapterParams{
-		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
-		Title:     currentChapter.Title,
-		Content:   currentChapter.Content,
-		WordCount: currentChapter.WordCount,
-		Status:    currentChapter.Status,
-		// These are the $6 and $7 for the subquery in UpdateChapter
-		ID_2:   pgtype.UUID{Bytes: projectID, Valid: true}, // Project ID for ownership check
-		UserID: pgtype.UUID{Bytes: userID, Valid: true},    // User ID for ownership check
+		ID:              pgtype.UUID{Bytes: chapterID, Valid: true},
+		Title:           currentChapter.Title,
+		Content:         currentChapter.Content,
+		WordCount:       currentChapter.WordCount,
+		Status:          currentChapter.Status,
+		TargetWordCount: currentChapter.TargetWordCount,
+		ProjectID:       pgtype.UUID{Bytes: projectID, Valid: true}, // Project ID for ownership check
+		UserID:          pgtype.UUID{Bytes: userID, Valid: true},    // User ID for ownership check
+	}
+	if req.Version != nil {
+		updateParams.ExpectedVersion = pgtype.Int4{Int32: *req.Version, Valid: true}
 	}
 
 	if req.Title != nil {
-		updateParams.Title = *req.Title
+		if updateParams.Title = markdown.SanitizeLine(*req.Title); updateParams.Title == "" {
+			return sqlc.Chapter{}, ErrEmptyAfterSanitizing
+		}
 	}
 	if req.Content != nil {
-		updateParams.Content = pgtype.Text{String: *req.Content, Valid: true}
-		updateParams.WordCount = pgtype.Int4{Int32: int32(utf8.RuneCountInString(*req.Content)), Valid: true}
+		updateParams.Content, updateParams.WordCount = chapterContent(*req.Content)
 	}
 	if req.Status != nil {
 		updateParams.Status = pgtype.Text{String: *req.Status, Valid: true}
 	}
+	if req.TargetWordCount != nil {
+		updateParams.TargetWordCount = pgtype.Int4{Int32: *req.TargetWordCount, Valid: *req.TargetWordCount > 0} // 0 clears the target
+	}
+	if req.CustomPrompt != nil {
+		updateParams.CustomPrompt = pgtype.Text{String: markdown.Sanitize(*req.CustomPrompt), Valid: true}
+	}
 
 	updatedChapter, err := s.store.UpdateChapter(ctx, updateParams)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) { // If RETURNING * found no row (e.g. subquery failed)
-			s.logger.Warn("Update chapter failed, chapter not found or ownership issue", "chapterID", chapterID, "error", err)
-			return sqlc.Chapter{}, ErrChapterNotFound
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) { // No row matched: chapter gone, ownership lost, or version moved on
+			return sqlc.Chapter{}, s.chapterUpdateMiss(ctx, chapterID, projectID, userID, req.Version)
 		}
 		s.logger.Error("Failed to update chapter in DB", "chapterID", chapterID, "error", err)
 		return sqlc.Chapter{}, fmt.Errorf("could not update chapter: %w", err)
 	}
-	s.logger.Info("Chapter updated successfully", "chapterID", updatedChapter.ID)
+	s.logger.Info("Chapter updated successfully", "chapterID", updatedChapter.ID, "version", updatedChapter.Version)
+	if req.Content != nil {
+		s.trackChapterSave(ctx, updatedChapter, ProvenanceHuman)
+	}
+	if req.Title != nil || req.Content != nil { // The autosaved edits are now saved
+		s.clearChapterDraft(ctx, chapterID, userID)
+	}
 	return updatedChapter, nil
 }
 
+// chapterUpdateMiss works out why a versioned UPDATE matched no row
+func (s *ResearchService) chapterUpdateMiss(ctx context.Context, chapterID, projectID, userID uuid.UUID, expectedVersion *int32) error {
+	if expectedVersion != nil {
+		latest, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+		if err == nil && latest.Version != *expectedVersion {
+			s.logger.Info("Chapter update rejected, version is stale", "chapterID", chapterID, "expected", *expectedVersion, "current", latest.Version)
+			return &ChapterConflictError{Latest: latest}
+		}
+	}
+	s.logger.Warn("Update chapter failed, chapter not found or ownership issue", "chapterID", chapterID)
+	return ErrChapterNotFound
+}
+
+// BulkUpsertChapters creates or updates the given chapters, keyed by chapter type,
+// inside a single transaction. Either every chapter is saved or none are.
+func (s *ResearchService) BulkUpsertChapters(ctx context.Context, projectID, userID uuid.UUID, items []apimodels.BulkChapterItem) ([]sqlc.Chapter, error) {
+	s.logger.Info("Bulk saving chapters", "projectID", projectID, "count", len(items), "userID", userID)
+	// Verify user can edit the project
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		s.logger.Warn("User cannot edit project for bulk chapter save", "projectID", projectID, "userID", userID)
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.Type] {
+			return nil, fmt.Errorf("%w: duplicate chapter type %q", ErrInvalidBulkChapters, item.Type)
+		}
+		seen[item.Type] = true
+		if _, err := s.projectChapterType(ctx, project, item.Type); err != nil {
+			if errors.Is(err, ErrUnknownChapterType) {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidBulkChapters, err)
+			}
+			return nil, err
+		}
+	}
+
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	chapters := make([]sqlc.Chapter, 0, len(items))
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		for _, item := range items {
+			existing, err := q.GetChapterByProjectIDAndType(ctx, sqlc.GetChapterByProjectIDAndTypeParams{
+				ProjectID: pgProjectID,
+				Type:      item.Type,
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("db error checking existing chapter %q: %w", item.Type, err)
+			}
+
+			var chapter sqlc.Chapter
+			if err != nil { // No chapter of this type yet, create it
+				if item.Title == nil || markdown.SanitizeLine(*item.Title) == "" {
+					return fmt.Errorf("%w: title is required to create chapter %q", ErrInvalidBulkChapters, item.Type)
+				}
+				params := sqlc.CreateChapterParams{
+					ProjectID: pgProjectID,
+					Type:      item.Type,
+					Title:     markdown.SanitizeLine(*item.Title),
+				}
+				if item.Content != nil {
+					params.Content, params.WordCount = chapterContent(*item.Content)
+				}
+				chapter, err = q.CreateChapter(ctx, params)
+				if err != nil {
+					return fmt.Errorf("could not create chapter %q: %w", item.Type, err)
+				}
+				if err := recordChapterSave(ctx, q, chapter, ProvenanceHuman); err != nil {
+					return err
+				}
+				// CreateChapter doesn't take a status, so apply it with a follow-up update
+				if item.Status == nil {
+					chapters = append(chapters, chapter)
+					continue
+				}
+				existing = chapter
+			}
+			if item.Status != nil {
+				if err := checkStatusChange(project, existing.Status.String, *item.Status); err != nil {
+					return fmt.Errorf("chapter %q: %w", item.Type, err)
+				}
+			}
+
+			updateParams := sqlc.UpdateChapterParams{
+				ID:        existing.ID,
+				Title:     existing.Title,
+				Content:   existing.Content,
+				WordCount: existing.WordCount,
+				Status:    existing.Status,
+				// Bulk saves leave word targets alone
+				TargetWordCount: existing.TargetWordCount,
+				ProjectID:       pgProjectID,
+				UserID:          pgtype.UUID{Bytes: userID, Valid: true},
+			}
+			if item.Title != nil {
+				if updateParams.Title = markdown.SanitizeLine(*item.Title); updateParams.Title == "" {
+					return fmt.Errorf("%w: chapter %q has an empty title", ErrInvalidBulkChapters, item.Type)
+				}
+			}
+			if item.Content != nil {
+				updateParams.Content, updateParams.WordCount = chapterContent(*item.Content)
+			}
+			if item.Status != nil {
+				updateParams.Status = pgtype.Text{String: *item.Status, Valid: true}
+			}
+			chapter, err = q.UpdateChapter(ctx, updateParams)
+			if err != nil {
+				return fmt.Errorf("could not update chapter %q: %w", item.Type, err)
+			}
+			if item.Content != nil {
+				if err := recordChapterSave(ctx, q, chapter, ProvenanceHuman); err != nil {
+					return err
+				}
+			}
+			chapters = append(chapters, chapter)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidBulkChapters) && !errors.Is(err, ErrChapterInReview) && !errors.Is(err, ErrReviewIsRequired) {
+			s.logger.Error("Bulk chapter save failed, transaction rolled back", "projectID", projectID, "error", err)
+		}
+		return nil, err
+	}
+	s.logger.Info("Chapters saved successfully", "projectID", projectID, "count", len(chapters))
+	return chapters, nil
+}
+
+// ReorderChapters puts the project's chapters in the given order, which listing and
+// generated documents follow. Every chapter must be listed once, except the executive
+// summary, which stays outside the thesis and goes last when left out.
+func (s *ResearchService) ReorderChapters(ctx context.Context, projectID, userID uuid.UUID, chapterIDs []uuid.UUID) ([]sqlc.Chapter, error) {
+	s.logger.Info("Reordering chapters", "projectID", projectID, "count", len(chapterIDs), "userID", userID)
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return nil, err
+	}
+
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	var chapters []sqlc.Chapter
+	err := s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.GetChaptersByProjectID(ctx, pgProjectID)
+		if err != nil {
+			return fmt.Errorf("could not fetch chapters: %w", err)
+		}
+		byID := make(map[uuid.UUID]sqlc.Chapter, len(current))
+		for _, ch := range current {
+			byID[ch.ID.Bytes] = ch
+		}
+		order := make([]uuid.UUID, 0, len(current))
+		listed := make(map[uuid.UUID]bool, len(chapterIDs))
+		for _, id := range chapterIDs {
+			if _, ok := byID[id]; !ok {
+				return fmt.Errorf("%w: chapter %s is not in the project", ErrInvalidChapterOrder, id)
+			}
+			if listed[id] {
+				return fmt.Errorf("%w: chapter %s is listed twice", ErrInvalidChapterOrder, id)
+			}
+			listed[id] = true
+			order = append(order, id)
+		}
+		for _, ch := range current {
+			if listed[ch.ID.Bytes] {
+				continue
+			}
+			if ch.Type != ChapterTypeExecutiveSummary {
+				return fmt.Errorf("%w: chapter %s is missing", ErrInvalidChapterOrder, uuid.UUID(ch.ID.Bytes))
+			}
+			order = append(order, ch.ID.Bytes)
+		}
+
+		for i, id := range order {
+			if err := q.SetChapterPosition(ctx, sqlc.SetChapterPositionParams{
+				ID:        pgtype.UUID{Bytes: id, Valid: true},
+				ProjectID: pgProjectID,
+				Position:  int32(i + 1),
+			}); err != nil {
+				return fmt.Errorf("could not move chapter %s: %w", id, err)
+			}
+			ch := byID[id]
+			ch.Position = int32(i + 1)
+			chapters = append(chapters, ch)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidChapterOrder) {
+			s.logger.Error("Chapter reorder failed, transaction rolled back", "projectID", projectID, "error", err)
+		}
+		return nil, err
+	}
+	s.logger.Info("Chapters reordered", "projectID", projectID, "count", len(chapters))
+	return chapters, nil
+}
+
+// --- Search ---
+
+// SearchProject runs a full-text search over the project's chapters and references
+// and returns the hits merged by rank. query uses web search syntax ("quoted phrases", -exclusions, or).
+func (s *ResearchService) SearchProject(ctx context.Context, projectID, userID uuid.UUID, query string, limit int) ([]apimodels.SearchResultResponse, error) {
+	s.logger.Info("Searching project", "projectID", projectID, "userID", userID)
+	// Verify user owns the project
+	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, ErrProjectNotFound
+	}
+
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	chapterHits, err := s.store.SearchProjectChapters(ctx, sqlc.SearchProjectChaptersParams{
+		Query:       query,
+		ProjectID:   pgProjectID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		s.logger.Error("Failed to search chapters", "projectID", projectID, "error", err)
+		return nil, fmt.Errorf("database error searching chapters: %w", err)
+	}
+	referenceHits, err := s.store.SearchProjectReferences(ctx, sqlc.SearchProjectReferencesParams{
+		Query:       query,
+		ProjectID:   pgProjectID,
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		s.logger.Error("Failed to search references", "projectID", projectID, "error", err)
+		return nil, fmt.Errorf("database error searching references: %w", err)
+	}
+
+	results := make([]apimodels.SearchResultResponse, 0, len(chapterHits)+len(referenceHits))
+	for _, hit := range chapterHits {
+		results = append(results, apimodels.SearchResultResponse{
+			Kind:        "chapter",
+			ID:          hit.ID.Bytes,
+			Title:       hit.Title,
+			ChapterType: hit.Type,
+			Snippet:     highlightSnippet(hit.Snippet),
+			Rank:        hit.Rank,
+		})
+	}
+	for _, hit := range referenceHits {
+		results = append(results, apimodels.SearchResultResponse{
+			Kind:    "reference",
+			ID:      hit.ID.Bytes,
+			Title:   hit.Title,
+			Snippet: highlightSnippet(hit.Snippet),
+			Rank:    hit.Rank,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// highlightSnippet turns a search snippet, whose matches the queries delimit with STX and
+// ETX, into HTML. The text is user content and is escaped before the <mark> tags go in.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	return strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>").Replace(escaped)
+}
+
 // --- AI Content Generation for Chapters ---
 
-func (s *ResearchService) GenerateChapterContent(ctx context.Context, projectID, chapterID, userID uuid.UUID, chapterType string) (sqlc.Chapter, error) {
+// GenerationOptions narrow what a chapter generation draws on
+type GenerationOptions struct {
+	ReferenceTag string // Literature review only: review just the references with this tag
+	Regenerate   bool   // The user asked for a new draft, so recent AI replies are not reused
+}
+
+// generationContext returns the context a generation that saves its result runs in,
+// bounded by timeouts.Generation. Unless timeouts.CancelOnDisconnect is set it is
+// detached from ctx's cancellation, so a client that disconnects, or a proxy that gives
+// up on a slow request, finds the result saved later rather than the work lost.
+func (s *ResearchService) generationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeouts.CancelOnDisconnect {
+		return context.WithTimeout(ctx, s.timeouts.Generation)
+	}
+	detached, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeouts.Generation)
+	stop := context.AfterFunc(ctx, func() {
+		s.logger.Info("Caller went away, generation continues in the background", "cause", context.Cause(ctx))
+	})
+	return detached, func() {
+		stop()
+		cancel()
+	}
+}
+
+func (s *ResearchService) GenerateChapterContent(ctx context.Context, projectID, chapterID, userID uuid.UUID, chapterType string, gen GenerationOptions) (sqlc.Chapter, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GenerateChapterContent")
+	span.SetAttributes(
+		attribute.String("project.id", projectID.String()),
+		attribute.String("chapter.id", chapterID.String()),
+		attribute.String("chapter.type", chapterType),
+	)
+	defer span.End()
+
 	s.logger.Info("Generating content for chapter", "chapterID", chapterID, "projectID", projectID, "type", chapterType, "userID", userID)
-	project, err := s.GetUserProjectByID(ctx, projectID, userID)
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Chapter{}, err // Project not found, access denied or read-only
+	}
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return sqlc.Chapter{}, err
+	}
+	if ctx, err = s.withGenerationPreferences(ctx, &project, userID); err != nil {
+		return sqlc.Chapter{}, err
+	}
+	// Generations count against the project owner's plan, whoever starts them
+	release, err := s.billing.ReserveUsage(ctx, project.UserID.Bytes, UsageAIGenerations)
 	if err != nil {
-		return sqlc.Chapter{}, err // Project not found or access denied
+		return sqlc.Chapter{}, err
 	}
 
+	ctx, emit := s.progressEmitter(ctx, projectID, &chapterID, nil)
+	ctx = s.withGenerationLog(ctx, chapterType, userID, &projectID, &chapterID)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	ctx, cacheUse := withResponseCacheUse(ctx, gen.Regenerate)
+	const totalSteps = 2 // AI generation, then saving the chapter
+	emit(events.Event{Type: events.GenerationStarted, Message: fmt.Sprintf("Generating %s", strings.ReplaceAll(chapterType, "_", " ")), TotalSteps: totalSteps})
+	chapter, err := s.generateChapterContent(ctx, project, chapterID, userID, chapterType, gen, emit, totalSteps)
+	if err != nil {
+		release()
+		emit(events.Event{Type: events.GenerationFailed, Message: err.Error()})
+		return sqlc.Chapter{}, err
+	}
+	if !cacheUse.fresh() {
+		release() // A reused reply is not a new generation
+	}
+	emit(events.Event{Type: events.GenerationCompleted, Message: "Chapter content generated", Step: totalSteps, TotalSteps: totalSteps})
+	s.notifications.Notify(ctx, Notification{
+		Type:      NotificationGenerationCompleted,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Title:     fmt.Sprintf("%s has been generated", chapter.Title),
+		Body:      fmt.Sprintf("AI generated content was added to %s in %s.", chapter.Title, project.Title),
+	}, userID)
+	s.webhooks.Emit(ctx, projectID, WebhookChapterGenerated, apimodels.ToChapterResponse(chapter))
+	return chapter, nil
+}
+
+func (s *ResearchService) generateChapterContent(ctx context.Context, project sqlc.ResearchProject, chapterID, userID uuid.UUID, chapterType string, gen GenerationOptions, emit func(events.Event), totalSteps int) (sqlc.Chapter, error) {
+	projectID := uuid.UUID(project.ID.Bytes)
+
 	// Find the chapter
 	chapters, err := s.store.GetChaptersByProjectID(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
 	if err != nil {
@@ -369,64 +869,42 @@ func (s *ResearchService) GenerateChapterContent(ctx context.Context, projectID,
 			break
 		}
 	}
-	_ = targetChapter // for now, we're not using this
 	if !found {
 		s.logger.Warn("Chapter not found for content generation", "chapterID", chapterID, "projectID", projectID, "type", chapterType)
 		return sqlc.Chapter{}, ErrChapterNotFound
 	}
+	if targetChapter.Status.String == ChapterStatusInReview {
+		return sqlc.Chapter{}, ErrChapterInReview // Generation would move it to generated behind the advisor's back
+	}
 
 	var generatedContent string
 	var generatedReferences []*apimodels.ReferenceResponse // For lit review
+	var datasets []sqlc.ProjectDataset                     // For results, with the tables made from them
+	var tables []resultsTable
+	opts := ChapterOptions{
+		Language:     project.Language,
+		TargetWords:  int(targetChapter.TargetWordCount.Int32),
+		Instructions: targetChapter.CustomPrompt,
+	}
 
 	switch chapterType {
 	case "literature_review":
-		generatedContent, generatedReferences, err = s.aiService.GenerateLiteratureReview(ctx, project.Title, project.Specialization)
-		if err == nil && len(generatedReferences) > 0 {
-			// Save these references to the DB
-			for _, refData := range generatedReferences {
-				// Check if refData fields are nil before dereferencing
-				var authors, journal, doi, url, citationAPA, citationMLA pgtype.Text
-				var pubYear pgtype.Int4
-
-				if refData.Authors != "" {
-					authors = pgtype.Text{String: refData.Authors, Valid: true}
-				}
-				if refData.Journal != "" {
-					journal = pgtype.Text{String: refData.Journal, Valid: true}
-				}
-				if refData.DOI != "" {
-					doi = pgtype.Text{String: refData.DOI, Valid: true}
-				}
-				if refData.URL != "" {
-					url = pgtype.Text{String: refData.URL, Valid: true}
-				}
-				if refData.CitationAPA != "" {
-					citationAPA = pgtype.Text{String: refData.CitationAPA, Valid: true}
-				}
-				if refData.CitationMLA != "" {
-					citationMLA = pgtype.Text{String: refData.CitationMLA, Valid: true}
-				}
-				if refData.PublicationYear != 0 {
-					pubYear = pgtype.Int4{Int32: int32(refData.PublicationYear), Valid: true}
-				}
-
-				_, refErr := s.store.CreateReference(ctx, sqlc.CreateReferenceParams{
-					ProjectID:       pgtype.UUID{Bytes: projectID, Valid: true},
-					Title:           refData.Title, // Assuming Title is not nil
-					Authors:         authors,
-					Journal:         journal,
-					PublicationYear: pubYear,
-					Doi:             doi,
-					Url:             url,
-					CitationApa:     citationAPA,
-					CitationMla:     citationMLA,
-				})
-				if refErr != nil {
-					s.logger.Error("Failed to save generated reference", "projectID", projectID, "error", refErr)
-					// Continue, but log the error
-				}
+		if gen.ReferenceTag != "" {
+			// Scoped to tagged references already in the library, so none are saved again
+			var scoped []AnnotatedReference
+			if scoped, err = s.taggedReferences(ctx, projectID, gen.ReferenceTag); err != nil {
+				return sqlc.Chapter{}, err
 			}
+			scope := &LiteratureScope{Tag: gen.ReferenceTag, References: scoped}
+			generatedContent, _, err = s.aiService.GenerateLiteratureReview(ctx, project.Title, project.Specialization, opts, nil, nil, scope)
+			break
 		}
+		// Uploaded PDFs whose text has been extracted, and the passages of imported papers
+		// that best match the topic, are used as source material; the student's notes on
+		// references guide the synthesis
+		sources := append(s.projectSources(ctx, projectID), s.paperExcerpts(ctx, project)...)
+		generatedContent, generatedReferences, err = s.aiService.GenerateLiteratureReview(ctx, project.Title, project.Specialization, opts, sources, s.annotatedReferences(ctx, projectID), nil)
+		// References are saved together with the chapter below, in one transaction
 	case "introduction":
 		// For introduction, we might need summary of lit review.
 		// Fetch lit review chapter content if available
@@ -441,42 +919,182 @@ func (s *ResearchService) GenerateChapterContent(ctx context.Context, projectID,
 				litReviewContent = litReviewChapter.Content.String
 			}
 		}
-		generatedContent, err = s.aiService.GenerateIntroduction(ctx, project.Title, project.Specialization, litReviewContent)
+		generatedContent, err = s.aiService.GenerateIntroduction(ctx, project.Title, project.Specialization, opts, litReviewContent)
 	case "methodology":
-		// For methodology, we might need research type (e.g. from project description or a dedicated field)
-		researchType := "general academic research" // Placeholder, extract from project if possible
-		if project.Description.Valid && strings.Contains(strings.ToLower(project.Description.String), "qualitative") {
-			researchType = "Qualitative Research"
-		} else if project.Description.Valid && strings.Contains(strings.ToLower(project.Description.String), "quantitative") {
-			researchType = "Quantitative Research"
-		}
-		generatedContent, err = s.aiService.GenerateMethodologyTemplate(ctx, project.Title, project.Specialization, researchType)
-	default:
+		// Written from the methodology questionnaire; a template of placeholders until it is answered
+		var answers *apimodels.MethodologyAnswers
+		answers, err = s.methodologyAnswers(ctx, projectID)
+		if err != nil {
+			return sqlc.Chapter{}, err
+		}
+		generatedContent, err = s.aiService.GenerateMethodologyTemplate(ctx, project.Title, project.Specialization, opts, answers)
+	case "results":
+		// Narrated from the statistics of the project's datasets, whose tables join the chapter
+		datasets, err = s.store.ListProjectDatasets(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+		if err != nil {
+			return sqlc.Chapter{}, fmt.Errorf("could not load datasets: %w", err)
+		}
+		if len(datasets) == 0 {
+			return sqlc.Chapter{}, ErrNoResultsData
+		}
+		var answers *apimodels.MethodologyAnswers
+		if answers, err = s.methodologyAnswers(ctx, projectID); err != nil {
+			return sqlc.Chapter{}, err
+		}
+		tables = resultsTables(datasets)
+		aiTables := make([]ResultsTable, len(tables))
+		for i, t := range tables {
+			aiTables[i] = t.ResultsTable
+		}
+		generatedContent, err = s.aiService.GenerateResultsNarrative(ctx, project.Title, project.Specialization, opts, answers, aiTables)
+		generatedContent = placeResultsTables(generatedContent, tables)
+	case ChapterTypeExecutiveSummary:
 		s.logger.Warn("Unsupported chapter type for AI generation", "type", chapterType)
 		return sqlc.Chapter{}, fmt.Errorf("AI generation not supported for chapter type: %s", chapterType)
+	default:
+		// Organization and project types are written from the chapter's own brief
+		var chapterTypeDef sqlc.ChapterType
+		if chapterTypeDef, err = s.projectChapterType(ctx, project, chapterType); err != nil {
+			return sqlc.Chapter{}, err
+		}
+		custom := CustomChapter{
+			Title:           targetChapter.Title,
+			TypeLabel:       chapterTypeDef.Label,
+			TypeDescription: chapterTypeDef.Description,
+		}
+		for _, ch := range chapters {
+			if ch.ID != targetChapter.ID && ch.Type != ChapterTypeExecutiveSummary {
+				custom.OtherChapters = append(custom.OtherChapters, ch.Title)
+			}
+		}
+		generatedContent, err = s.aiService.GenerateCustomChapter(ctx, project.Title, project.Specialization, opts, custom)
 	}
 
 	if err != nil {
 		s.logger.Error("AI content generation failed", "chapterID", chapterID, "type", chapterType, "error", err)
-		return sqlc.Chapter{}, fmt.Errorf("AI generation failed: %w", err)
+		return sqlc.Chapter{}, s.retryGeneration(ctx, generationRetryJob{
+			ProjectID:    projectID,
+			ChapterID:    chapterID,
+			ChapterType:  chapterType,
+			UserID:       userID,
+			ReferenceTag: gen.ReferenceTag,
+			UpdatedAt:    targetChapter.UpdatedAt.Time,
+		}, fmt.Errorf("AI generation failed: %w", err))
+	}
+	emit(events.Event{Type: events.GenerationProgress, Message: "Content generated, saving chapter", Step: 1, TotalSteps: totalSteps})
+
+	// Save generated references and the chapter content atomically, so a failure
+	// never leaves references behind without the chapter that cites them
+	var updatedChapter sqlc.Chapter
+	var savedReferences []sqlc.Reference
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if len(generatedReferences) > 0 {
+			// A reference the project already has is merged into it rather than added again
+			index, err := projectReferenceIndex(ctx, q, pgtype.UUID{Bytes: projectID, Valid: true})
+			if err != nil {
+				return err
+			}
+			for _, refData := range generatedReferences {
+				ref, _, err := saveReference(ctx, q, index, generatedReferenceParams(projectID, refData))
+				if err != nil {
+					return fmt.Errorf("could not save generated reference: %w", err)
+				}
+				savedReferences = append(savedReferences, ref)
+			}
+		}
+
+		if datasets != nil {
+			if err := saveResultsTables(ctx, q, targetChapter, userID, datasets, tables); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		content, wordCount := chapterContent(generatedContent)
+		updatedChapter, err = q.UpdateChapter(ctx, sqlc.UpdateChapterParams{
+			ID:        targetChapter.ID,
+			Title:     targetChapter.Title,
+			Content:   content,
+			WordCount: wordCount,
+			Status:    pgtype.Text{String: "generated", Valid: true},
+			// Generation keeps the target it aimed for
+			TargetWordCount: targetChapter.TargetWordCount,
+			ProjectID:       pgtype.UUID{Bytes: projectID, Valid: true}, // Project ID for ownership check
+			UserID:          pgtype.UUID{Bytes: userID, Valid: true},    // User ID for ownership check
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+				return ErrChapterNotFound
+			}
+			return fmt.Errorf("could not update chapter: %w", err)
+		}
+		return recordChapterSave(ctx, q, updatedChapter, ProvenanceAI)
+	})
+	if err != nil {
+		s.logger.Error("Failed to save generated chapter, transaction rolled back", "chapterID", chapterID, "references", len(generatedReferences), "error", err)
+		return sqlc.Chapter{}, err
+	}
+	s.logger.Info("Generated chapter saved", "chapterID", chapterID, "references", len(generatedReferences))
+	s.embedReferences(ctx, savedReferences)
+	return updatedChapter, nil
+}
+
+// generatedReferenceParams maps an AI-extracted reference onto insert params, leaving empty fields NULL
+func generatedReferenceParams(projectID uuid.UUID, refData *apimodels.ReferenceResponse) sqlc.CreateReferenceParams {
+	var authors, journal, doi, url, citationAPA, citationMLA, abstract pgtype.Text
+	var pubYear pgtype.Int4
+
+	if refData.Authors != "" {
+		authors = pgtype.Text{String: refData.Authors, Valid: true}
+	}
+	if refData.Journal != "" {
+		journal = pgtype.Text{String: refData.Journal, Valid: true}
+	}
+	if refData.DOI != "" {
+		doi = pgtype.Text{String: refData.DOI, Valid: true}
+	}
+	if refData.URL != "" {
+		url = pgtype.Text{String: refData.URL, Valid: true}
+	}
+	if refData.CitationAPA != "" {
+		citationAPA = pgtype.Text{String: refData.CitationAPA, Valid: true}
+	}
+	if refData.CitationMLA != "" {
+		citationMLA = pgtype.Text{String: refData.CitationMLA, Valid: true}
+	}
+	if refData.Abstract != "" {
+		abstract = pgtype.Text{String: refData.Abstract, Valid: true}
+	}
+	if refData.PublicationYear != 0 {
+		pubYear = pgtype.Int4{Int32: int32(refData.PublicationYear), Valid: true}
 	}
 
-	// Update the chapter with generated content
-	updateParams := apimodels.UpdateChapterRequest{
-		Content: &generatedContent,
-		Status:  models.ToStringPtr("generated"), // status defined in your api model
+	return sqlc.CreateReferenceParams{
+		ProjectID:       pgtype.UUID{Bytes: projectID, Valid: true},
+		Title:           refData.Title,
+		Authors:         authors,
+		Journal:         journal,
+		PublicationYear: pubYear,
+		Doi:             doi,
+		Url:             url,
+		CitationApa:     citationAPA,
+		CitationMla:     citationMLA,
+		Abstract:        abstract,
 	}
-	return s.UpdateChapter(ctx, chapterID, projectID, userID, updateParams)
 }
 
 // --- Reference Methods ---
-func (s *ResearchService) CreateReference(ctx context.Context, userID uuid.UUID, req apimodels.CreateReferenceRequest) (sqlc.Reference, error) {
+
+// CreateReference adds a reference to a project. A reference with the DOI, or the title
+// and year, of one the project already has fills in that one's missing fields instead,
+// and is reported as merged.
+func (s *ResearchService) CreateReference(ctx context.Context, userID uuid.UUID, req apimodels.CreateReferenceRequest) (sqlc.Reference, bool, error) {
 	s.logger.Info("Creating reference", "projectID", req.ProjectID, "title", req.Title, "userID", userID)
-	// Verify user owns the project
-	_, err := s.GetUserProjectByID(ctx, req.ProjectID, userID)
+	// Verify user can edit the project
+	_, err := s.requireProjectRole(ctx, req.ProjectID, userID, ProjectRoleEdit)
 	if err != nil {
-		s.logger.Warn("User does not own project for reference creation", "projectID", req.ProjectID, "userID", userID)
-		return sqlc.Reference{}, ErrProjectNotFound
+		s.logger.Warn("User cannot edit project for reference creation", "projectID", req.ProjectID, "userID", userID)
+		return sqlc.Reference{}, false, err
 	}
 
 	params := sqlc.CreateReferenceParams{
@@ -489,15 +1107,31 @@ func (s *ResearchService) CreateReference(ctx context.Context, userID uuid.UUID,
 		Url:             pgtype.Text{String: derefString(req.URL), Valid: req.URL != nil},
 		CitationApa:     pgtype.Text{String: derefString(req.CitationAPA), Valid: req.CitationAPA != nil},
 		CitationMla:     pgtype.Text{String: derefString(req.CitationMLA), Valid: req.CitationMLA != nil},
+		Abstract:        pgtype.Text{String: derefString(req.Abstract), Valid: req.Abstract != nil},
 	}
 
-	ref, err := s.store.CreateReference(ctx, params)
+	var ref sqlc.Reference
+	var merged bool
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		index, err := projectReferenceIndex(ctx, q, params.ProjectID)
+		if err != nil {
+			return err
+		}
+		ref, merged, err = saveReference(ctx, q, index, params)
+		return err
+	})
 	if err != nil {
 		s.logger.Error("Failed to create reference in DB", "projectID", req.ProjectID, "error", err)
-		return sqlc.Reference{}, fmt.Errorf("could not create reference: %w", err)
+		return sqlc.Reference{}, false, err
+	}
+	s.embedReferences(ctx, []sqlc.Reference{ref})
+	if merged {
+		s.logger.Info("Reference merged into an existing duplicate", "referenceID", ref.ID)
+		return ref, true, nil
 	}
 	s.logger.Info("Reference created successfully", "referenceID", ref.ID)
-	return ref, nil
+	s.webhooks.Emit(ctx, req.ProjectID, WebhookReferenceAdded, apimodels.ToReferenceResponse(ref))
+	return ref, false, nil
 }
 
 func (s *ResearchService) GetProjectReferences(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.Reference, error) {
@@ -521,22 +1155,325 @@ func (s *ResearchService) GetProjectReferences(ctx context.Context, projectID, u
 
 func (s *ResearchService) DeleteReference(ctx context.Context, referenceID, projectID, userID uuid.UUID) error {
 	s.logger.Info("Deleting reference", "referenceID", referenceID, "projectID", projectID, "userID", userID)
-	// Verify user owns the project the reference belongs to
-	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	// Verify user can edit the project the reference belongs to
+	_, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
 	if err != nil {
-		return ErrProjectNotFound
+		return err
 	}
 
-	err = s.store.DeleteReference(ctx, sqlc.DeleteReferenceParams{ID: pgtype.UUID{Bytes: referenceID, Valid: true}, ProjectID: pgtype.UUID{Bytes: projectID, Valid: true}})
+	rows, err := s.store.DeleteReference(ctx, sqlc.DeleteReferenceParams{ID: pgtype.UUID{Bytes: referenceID, Valid: true}, ProjectID: pgtype.UUID{Bytes: projectID, Valid: true}})
 	if err != nil {
 		s.logger.Error("Failed to delete reference from DB", "referenceID", referenceID, "error", err)
 		return fmt.Errorf("could not delete reference: %w", err)
 	}
-	s.logger.Info("Reference deleted successfully", "referenceID", referenceID)
+	if rows == 0 {
+		return ErrReferenceNotFound
+	}
+	s.logger.Info("Reference moved to trash", "referenceID", referenceID)
+	s.webhooks.Emit(ctx, projectID, WebhookReferenceDeleted, map[string]uuid.UUID{"id": referenceID})
+	return nil
+}
+
+// --- Trash ---
+
+// pgUniqueViolation is the Postgres SQLSTATE for unique_violation
+const pgUniqueViolation = "23505"
+
+// DeleteChapter moves a chapter to the project's trash
+func (s *ResearchService) DeleteChapter(ctx context.Context, chapterID, projectID, userID uuid.UUID) error {
+	s.logger.Info("Deleting chapter", "chapterID", chapterID, "projectID", projectID, "userID", userID)
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	rows, err := s.store.DeleteChapter(ctx, sqlc.DeleteChapterParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true}, // Must be able to edit the project
+	})
+	if err != nil {
+		s.logger.Error("Failed to delete chapter from DB", "chapterID", chapterID, "error", err)
+		return fmt.Errorf("could not delete chapter: %w", err)
+	}
+	if rows == 0 {
+		return ErrChapterNotFound
+	}
+	s.logger.Info("Chapter moved to trash", "chapterID", chapterID)
+	return nil
+}
+
+// GetProjectTrash lists the project's deleted chapters and references, most recent first
+func (s *ResearchService) GetProjectTrash(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.Chapter, []sqlc.Reference, error) {
+	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, nil, ErrProjectNotFound
+	}
+
+	pgProjectID := pgtype.UUID{Bytes: projectID, Valid: true}
+	chapters, err := s.store.ListTrashedChapters(ctx, pgProjectID)
+	if err != nil {
+		s.logger.Error("Failed to list trashed chapters", "projectID", projectID, "error", err)
+		return nil, nil, fmt.Errorf("database error fetching trashed chapters: %w", err)
+	}
+	references, err := s.store.ListTrashedReferences(ctx, pgProjectID)
+	if err != nil {
+		s.logger.Error("Failed to list trashed references", "projectID", projectID, "error", err)
+		return nil, nil, fmt.Errorf("database error fetching trashed references: %w", err)
+	}
+	return chapters, references, nil
+}
+
+// RestoreChapter takes a chapter out of the trash. It fails with ErrChapterAlreadyExists
+// if a chapter of the same type was created in the meantime.
+func (s *ResearchService) RestoreChapter(ctx context.Context, chapterID, projectID, userID uuid.UUID) (sqlc.Chapter, error) {
+	s.logger.Info("Restoring chapter", "chapterID", chapterID, "projectID", projectID, "userID", userID)
+	_, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Chapter{}, err
+	}
+
+	chapter, err := s.store.RestoreChapter(ctx, sqlc.RestoreChapterParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return sqlc.Chapter{}, ErrChapterNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return sqlc.Chapter{}, ErrChapterAlreadyExists
+		}
+		s.logger.Error("Failed to restore chapter", "chapterID", chapterID, "error", err)
+		return sqlc.Chapter{}, fmt.Errorf("could not restore chapter: %w", err)
+	}
+	return chapter, nil
+}
+
+// RestoreReference takes a reference out of the trash
+func (s *ResearchService) RestoreReference(ctx context.Context, referenceID, projectID, userID uuid.UUID) (sqlc.Reference, error) {
+	s.logger.Info("Restoring reference", "referenceID", referenceID, "projectID", projectID, "userID", userID)
+	_, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.Reference{}, err
+	}
+
+	ref, err := s.store.RestoreReference(ctx, sqlc.RestoreReferenceParams{
+		ID:        pgtype.UUID{Bytes: referenceID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return sqlc.Reference{}, ErrReferenceNotFound
+		}
+		s.logger.Error("Failed to restore reference", "referenceID", referenceID, "error", err)
+		return sqlc.Reference{}, fmt.Errorf("could not restore reference: %w", err)
+	}
+	return ref, nil
+}
+
+// PurgeTrash permanently deletes chapters and references trashed before cutoff
+func (s *ResearchService) PurgeTrash(ctx context.Context, cutoff time.Time) error {
+	pgCutoff := pgtype.Timestamptz{Time: cutoff, Valid: true}
+	chapters, err := s.store.PurgeTrashedChapters(ctx, pgCutoff)
+	if err != nil {
+		return fmt.Errorf("could not purge trashed chapters: %w", err)
+	}
+	pdfs, err := s.store.ListPurgeableReferencePDFs(ctx, pgCutoff)
+	if err != nil {
+		return fmt.Errorf("could not list paper PDFs to purge: %w", err)
+	}
+	references, err := s.store.PurgeTrashedReferences(ctx, pgCutoff)
+	if err != nil {
+		return fmt.Errorf("could not purge trashed references: %w", err)
+	}
+	for _, path := range pdfs {
+		s.removeUploadFile(ctx, path)
+	}
+	if chapters > 0 || references > 0 {
+		s.logger.Info("Purged trash", "chapters", chapters, "references", references, "cutoff", cutoff)
+	}
+	return nil
+}
+
+// --- Reference embeddings ---
+
+// embedBatchSize bounds the number of inputs sent in one embeddings request
+const embedBatchSize = 64
+
+// referenceEmbeddingInput is the text embedded for a reference: its title, plus the abstract when known
+func referenceEmbeddingInput(title string, abstract pgtype.Text) string {
+	if abstract.Valid && abstract.String != "" {
+		return title + "\n\n" + abstract.String
+	}
+	return title
+}
+
+// embedJob is the payload of JobEmbedReferences
+type embedJob struct {
+	ReferenceIDs []uuid.UUID `json:"reference_ids"`
+}
+
+// embedReferences queues embedding of freshly saved references. It is best effort:
+// failures are logged and the references are picked up by EmbedMissingReferences later.
+func (s *ResearchService) embedReferences(ctx context.Context, refs []sqlc.Reference) {
+	if len(refs) == 0 || !s.aiService.EmbeddingsEnabled() {
+		return
+	}
+	ids := make([]uuid.UUID, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID.Bytes
+	}
+	if err := s.jobs.Enqueue(ctx, JobEmbedReferences, embedJob{ReferenceIDs: ids}); err != nil {
+		s.logger.Warn("Failed to queue reference embeddings, will retry in background", "count", len(refs), "error", err)
+	}
+}
+
+// runEmbedJob embeds the references of an embedJob that still exist
+func (s *ResearchService) runEmbedJob(ctx context.Context, job jobs.Job) error {
+	if !s.aiService.EmbeddingsEnabled() {
+		return nil
+	}
+	var payload embedJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	pgIDs := make([]pgtype.UUID, len(payload.ReferenceIDs))
+	for i, id := range payload.ReferenceIDs {
+		pgIDs[i] = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	// Primary, as the references were usually saved moments ago
+	rows, err := s.store.GetReferencesForEmbedding(db.WithPrimary(ctx), pgIDs)
+	if err != nil {
+		return fmt.Errorf("could not load references to embed: %w", err)
+	}
+	ids := make([]pgtype.UUID, len(rows))
+	inputs := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		inputs[i] = referenceEmbeddingInput(row.Title, row.Abstract)
+	}
+	return s.storeEmbeddings(ctx, ids, inputs)
+}
+
+// EmbedMissingReferences embeds every live reference that has no embedding for the current model
+func (s *ResearchService) EmbedMissingReferences(ctx context.Context) error {
+	if !s.aiService.EmbeddingsEnabled() {
+		return nil
+	}
+	total := 0
+	for {
+		rows, err := s.store.ListReferencesMissingEmbedding(ctx, sqlc.ListReferencesMissingEmbeddingParams{
+			Model:       s.aiService.EmbeddingModel(),
+			ResultLimit: embedBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("could not list references missing embeddings: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		ids := make([]pgtype.UUID, len(rows))
+		inputs := make([]string, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+			inputs[i] = referenceEmbeddingInput(row.Title, row.Abstract)
+		}
+		if err := s.storeEmbeddings(ctx, ids, inputs); err != nil {
+			return err
+		}
+		total += len(rows)
+		if len(rows) < embedBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		s.logger.Info("Embedded references", "count", total)
+	}
+	return nil
+}
+
+func (s *ResearchService) storeEmbeddings(ctx context.Context, ids []pgtype.UUID, inputs []string) error {
+	for start := 0; start < len(inputs); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(inputs))
+		vectors, err := s.aiService.EmbedText(ctx, inputs[start:end]...)
+		if err != nil {
+			return fmt.Errorf("could not embed references: %w", err)
+		}
+		for i, vector := range vectors {
+			err := s.store.UpsertReferenceEmbedding(ctx, sqlc.UpsertReferenceEmbeddingParams{
+				ReferenceID: ids[start+i],
+				Model:       s.aiService.EmbeddingModel(),
+				Embedding:   VectorLiteral(vector),
+			})
+			if err != nil {
+				return fmt.Errorf("could not save reference embedding: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
+// SimilarReferences returns the project's references closest in meaning to text,
+// most similar first. Only references that already have an embedding are considered.
+func (s *ResearchService) SimilarReferences(ctx context.Context, projectID, userID uuid.UUID, text string, limit int) ([]apimodels.SimilarReferenceResponse, error) {
+	s.logger.Info("Finding similar references", "projectID", projectID, "userID", userID)
+	// Verify user owns the project
+	_, err := s.GetUserProjectByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, ErrProjectNotFound
+	}
+
+	vectors, err := s.aiService.EmbedText(ctx, text)
+	if err != nil {
+		if errors.Is(err, ErrEmbeddingsDisabled) {
+			return nil, err
+		}
+		s.logger.Error("Failed to embed query text", "projectID", projectID, "error", err)
+		return nil, fmt.Errorf("could not embed text: %w", err)
+	}
+
+	rows, err := s.store.SimilarProjectReferences(ctx, sqlc.SimilarProjectReferencesParams{
+		Embedding:   VectorLiteral(vectors[0]),
+		Model:       s.aiService.EmbeddingModel(),
+		ProjectID:   pgtype.UUID{Bytes: projectID, Valid: true},
+		ResultLimit: int32(limit),
+	})
+	if err != nil {
+		s.logger.Error("Failed to query similar references", "projectID", projectID, "error", err)
+		return nil, fmt.Errorf("database error finding similar references: %w", err)
+	}
+
+	results := make([]apimodels.SimilarReferenceResponse, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, apimodels.SimilarReferenceResponse{
+			ReferenceResponse: apimodels.ToReferenceResponse(sqlc.Reference{
+				ID:              row.ID,
+				ProjectID:       row.ProjectID,
+				Title:           row.Title,
+				Authors:         row.Authors,
+				Journal:         row.Journal,
+				PublicationYear: row.PublicationYear,
+				Doi:             row.Doi,
+				Url:             row.Url,
+				CitationApa:     row.CitationApa,
+				CitationMla:     row.CitationMla,
+				Abstract:        row.Abstract,
+				CreatedAt:       row.CreatedAt,
+			}),
+			Similarity: row.Similarity,
+		})
+	}
+	return results, nil
+}
+
 // Helper functions for dereferencing pointers to strings/ints
+// chapterContent sanitizes chapter Markdown for storage, as every write of a chapter's
+// content does, and counts its words. Empty content is stored as NULL.
+func chapterContent(content string) (pgtype.Text, pgtype.Int4) {
+	content = markdown.Sanitize(content)
+	return pgtype.Text{String: content, Valid: content != ""}, pgtype.Int4{Int32: int32(countWords(content)), Valid: content != ""}
+}
+
 func derefString(s *string) string {
 	if s != nil {
 		return *s
@@ -550,15 +1487,56 @@ func derefInt(i *int) int {
 	return 0
 }
 
+// Kinds of generated document
+const (
+	DocumentKindThesis       = "thesis"
+	DocumentKindPresentation = "presentation" // Defense slides
+	DocumentKindPoster       = "poster"       // One-slide A0 or A1 poster
+	DocumentKindArticle      = "article"      // IMRaD journal manuscript condensed from the thesis
+)
+
+// Media types of generated documents
+const (
+	docxMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	pptxMimeType = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+)
+
 // Placeholder for document generation service integration
 func (s *ResearchService) GenerateDocument(ctx context.Context, projectID, userID uuid.UUID) (sqlc.GeneratedDocument, error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GenerateDocument")
+	span.SetAttributes(attribute.String("project.id", projectID.String()))
+	defer span.End()
+
 	s.logger.Info("Initiating document generation process", "projectID", projectID, "userID", userID)
-	project, err := s.GetUserProjectByID(ctx, projectID, userID)
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.GeneratedDocument{}, err
+	}
+	return s.queueDocument(ctx, project, documentJob{UserID: userID, Kind: DocumentKindThesis})
+}
+
+// queueDocument records the document of a job as processing and queues the job to render
+// it, reserving the project owner's document usage. The job names who asked and the kind
+// of document with its options; the rest is filled in here.
+func (s *ResearchService) queueDocument(ctx context.Context, project sqlc.ResearchProject, job documentJob) (sqlc.GeneratedDocument, error) {
+	projectID := uuid.UUID(project.ID.Bytes)
+	release, err := s.quotas.Reserve(ctx, project.UserID.Bytes, QuotaDocuments, 1)
 	if err != nil {
 		return sqlc.GeneratedDocument{}, err
 	}
 
 	mockFileName := fmt.Sprintf("project_%s_thesis.docx", projectID.String()[:8])
+	mimeType := docxMimeType
+	switch job.Kind {
+	case DocumentKindPresentation:
+		mockFileName = fmt.Sprintf("project_%s_defense.pptx", projectID.String()[:8])
+		mimeType = pptxMimeType
+	case DocumentKindPoster:
+		mockFileName = fmt.Sprintf("project_%s_poster.pptx", projectID.String()[:8])
+		mimeType = pptxMimeType
+	case DocumentKindArticle:
+		mockFileName = fmt.Sprintf("project_%s_article.docx", projectID.String()[:8])
+	}
 	mockFilePath := fmt.Sprintf("/generated_docs/%s", mockFileName)
 
 	docParams := sqlc.CreateGeneratedDocumentParams{
@@ -566,76 +1544,212 @@ func (s *ResearchService) GenerateDocument(ctx context.Context, projectID, userI
 		FileName:  mockFileName,
 		FilePath:  mockFilePath,
 		// FileSize:  pgtype.Int8{Int64: 10240, Valid: true}, // 10KB placeholder
-		// MimeType:  pgtype.Text{String: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Valid: true},
+		MimeType: pgtype.Text{String: mimeType, Valid: true},
 		// Status defaults to 'processing'
 	}
 	dbDoc, err := s.store.CreateGeneratedDocument(ctx, docParams)
 	if err != nil {
+		release()
 		s.logger.Error("Failed to create generated document record", "projectID", projectID, "error", err)
 		return sqlc.GeneratedDocument{}, fmt.Errorf("could not create document record: %w", err)
 	}
+
+	// Rendering can take minutes, so it runs as a job; progress arrives as events
+	job.DocumentID, job.BilledUserID = dbDoc.ID.Bytes, project.UserID.Bytes
+	err = s.jobs.Enqueue(ctx, JobGenerateDocument, job)
+	if err != nil {
+		release()
+		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", "Could not queue document generation")
+		return sqlc.GeneratedDocument{}, fmt.Errorf("could not queue document generation: %w", err)
+	}
+	s.logger.Info("Document generation queued", "projectID", projectID, "documentID", dbDoc.ID.Bytes, "kind", job.Kind)
+	return dbDoc, nil
+}
+
+// documentJob is the payload of JobGenerateDocument
+type documentJob struct {
+	DocumentID   uuid.UUID       `json:"document_id"`
+	UserID       uuid.UUID       `json:"user_id"`        // Who asked for the document
+	BilledUserID uuid.UUID       `json:"billed_user_id"` // The project owner, whose usage was reserved
+	Kind         string          `json:"kind,omitempty"` // A DocumentKind; jobs queued before kinds existed are theses
+	Poster       *PosterOptions  `json:"poster,omitempty"`
+	Article      *ArticleOptions `json:"article,omitempty"`
+}
+
+// runDocumentJob renders a document queued by GenerateDocument. The document is marked
+// failed, and its reserved usage given back, only once no retry is coming.
+func (s *ResearchService) runDocumentJob(ctx context.Context, job jobs.Job) error {
+	var payload documentJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	dbDoc, err := s.store.GetGeneratedDocumentByID(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.DocumentID, Valid: true})
+	if err != nil {
+		if isNoRows(err) { // Deleted along with its project
+			return jobs.Permanent(ErrDocumentNotFound)
+		}
+		return fmt.Errorf("could not load document: %w", err)
+	}
+	projectID := uuid.UUID(dbDoc.ProjectID.Bytes)
+	ctx, emit := s.progressEmitter(ctx, projectID, nil, &payload.DocumentID)
+	ctx = s.withGenerationLog(ctx, payload.Kind, payload.UserID, &projectID, nil)
+	ctx = s.quotas.withAIWords(ctx, payload.BilledUserID) // Articles, posters and presentations are condensed by the AI
+
+	project, err := s.GetUserProjectByID(ctx, projectID, payload.UserID)
+	if err != nil {
+		if errors.Is(err, ErrProjectNotFound) { // The requester lost access meanwhile
+			s.failDocument(ctx, projectID, payload.BilledUserID, payload.DocumentID, emit, err)
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	emit(events.Event{Type: events.DocumentStarted, Message: "Document generation started"})
+	switch payload.Kind {
+	case DocumentKindPresentation:
+		dbDoc, err = s.generatePresentation(ctx, project, dbDoc)
+	case DocumentKindPoster:
+		dbDoc, err = s.generatePoster(ctx, project, dbDoc, payload.Poster)
+	case DocumentKindArticle:
+		dbDoc, err = s.generateArticle(ctx, project, payload.UserID, dbDoc, payload.Article)
+	default:
+		dbDoc, err = s.generateDocument(ctx, project, dbDoc)
+	}
+	if err != nil {
+		if errors.Is(err, ErrQuotaReached) { // Trying again won't help before the period ends
+			s.failDocument(ctx, projectID, payload.BilledUserID, payload.DocumentID, emit, err)
+			return jobs.Permanent(err)
+		}
+		if job.LastAttempt() {
+			s.failDocument(ctx, projectID, payload.BilledUserID, payload.DocumentID, emit, err)
+		}
+		return err
+	}
+	emit(events.Event{Type: events.DocumentReady, Message: dbDoc.FileName})
+	s.notifications.Notify(ctx, Notification{
+		Type:       NotificationDocumentReady,
+		ProjectID:  projectID,
+		DocumentID: &payload.DocumentID,
+		Title:      fmt.Sprintf("Your document for %s is ready", project.Title),
+		Body:       fmt.Sprintf("%s can now be downloaded.", dbDoc.FileName),
+	}, payload.UserID)
+	s.webhooks.Emit(ctx, projectID, WebhookDocumentCompleted, apimodels.ToGeneratedDocumentResponse(dbDoc))
+	return nil
+}
+
+// failDocument marks a document failed for good and gives back the usage reserved for it
+func (s *ResearchService) failDocument(ctx context.Context, projectID, billedUserID, docID uuid.UUID, emit func(events.Event), cause error) {
+	ctx = context.WithoutCancel(ctx) // Also when the job timed out
+	s.updateDocStatus(ctx, docID, "failed", cause.Error())
+	s.quotas.Release(ctx, billedUserID, QuotaDocuments, 1)
+	emit(events.Event{Type: events.DocumentFailed, Message: cause.Error()})
+	s.webhooks.Emit(ctx, projectID, WebhookDocumentFailed, map[string]any{"id": docID, "error": cause.Error()})
+}
+
+func (s *ResearchService) generateDocument(ctx context.Context, project sqlc.ResearchProject, dbDoc sqlc.GeneratedDocument) (sqlc.GeneratedDocument, error) {
+	projectID := uuid.UUID(project.ID.Bytes)
 	// Gather data for Python service
 	chaptersDB, err := s.store.GetChaptersByProjectID(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
 	if err != nil {
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", "Error fetching chapters")
 		return dbDoc, fmt.Errorf("failed to fetch chapters for doc gen: %w", err)
 	}
-	var chaptersPy []PythonChapterData
+	formatting, err := s.projectFormatting(ctx, project)
+	if err != nil {
+		return dbDoc, err
+	}
+	labels := documentLabels(formatting)
+	figures, err := s.docgenFigures(ctx, projectID)
+	if err != nil {
+		return dbDoc, err
+	}
+	var included []sqlc.Chapter
 	for _, ch := range chaptersDB {
+		if ch.Type == ChapterTypeExecutiveSummary {
+			continue // Submitted on its own, not part of the thesis
+		}
 		if ch.Status.String == "approved" || ch.Status.String == "generated" { // Only include approved/generated chapters
-			chaptersPy = append(chaptersPy, PythonChapterData{
-				Type:    ch.Type,
-				Title:   ch.Title,
-				Content: ch.Content.String,
-			})
+			included = append(included, ch)
 		}
 	}
-
-	referencesDB, err := s.store.GetReferencesByProjectID(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	sections, err := s.docgenSections(ctx, projectID)
 	if err != nil {
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", "Error fetching references")
-		return dbDoc, fmt.Errorf("failed to fetch references for doc gen: %w", err)
+		return dbDoc, err
 	}
-	var referencesPy []PythonReferenceData
-	for _, ref := range referencesDB {
-		if ref.CitationApa.Valid {
-			referencesPy = append(referencesPy, PythonReferenceData{CitationAPA: ref.CitationApa.String})
+	bib, err := s.documentBibliography(ctx, project.ID, included, sections, formatting.CitationStyle)
+	if err != nil {
+		return dbDoc, err
+	}
+	if bib.Unresolved > 0 {
+		s.logger.Warn("Document cites works missing from the project's references", "projectID", projectID, "citations", bib.Unresolved)
+	}
+	chaptersPy := make([]PythonChapterData, len(included))
+	for i, ch := range included {
+		chaptersPy[i] = PythonChapterData{
+			Type:     ch.Type,
+			Title:    ch.Title,
+			Content:  bib.chapterContent(ch),
+			Figures:  s.pythonFigures(ctx, i+1, figures[ch.ID.Bytes], labels),
+			Sections: bib.documentSections(i+1, sections[ch.ID.Bytes]),
 		}
 	}
+	if err := s.watermarkChapters(ctx, included, sections, chaptersPy); err != nil {
+		return dbDoc, err
+	}
+
+	appendicesPy, err := s.pythonAppendices(ctx, projectID, formatting)
+	if err != nil {
+		return dbDoc, err
+	}
+	timelinePy, err := s.pythonTimeline(ctx, projectID)
+	if err != nil {
+		return dbDoc, err
+	}
+	frontMatter, err := s.projectFrontMatter(ctx, project)
+	if err != nil {
+		return dbDoc, err
+	}
 
 	pythonReqPayload := PythonDocGenRequest{
-		ProjectID:      project.ID.Bytes,
-		ResearchTitle:  project.Title,
-		StudentName:    "A. User", // Get from user profile later
-		UniversityName: project.University.String,
-		Specialization: project.Specialization,
-		Chapters:       chaptersPy,
-		References:     referencesPy,
-		FormattingOptions: map[string]interface{}{ // Default formatting
-			"font_family":    "Times New Roman",
-			"font_size_main": 12,
-			"line_spacing":   1.5,
-		},
-	}
-
-	jsonData, err := json.Marshal(pythonReqPayload)
-	if err != nil {
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", "Error marshalling request for Python service")
+		ProjectID:         project.ID.Bytes,
+		ResearchTitle:     project.Title,
+		StudentName:       s.studentName(ctx, project),
+		UniversityName:    project.University.String,
+		Specialization:    project.Specialization,
+		Chapters:          chaptersPy,
+		References:        bib.References,
+		Appendices:        appendicesPy,
+		Timeline:          timelinePy,
+		FormattingOptions: formatting,
+		Direction:         documentDirection(formatting),
+		Labels:            labels,
+		FrontMatter:       &frontMatter,
+	}
+
+	return s.renderDocument(ctx, dbDoc, "/generate-document", pythonReqPayload)
+}
+
+// renderDocument has the Python service render a document from payload at endpoint and
+// records the file it saved as the completed document
+func (s *ResearchService) renderDocument(ctx context.Context, dbDoc sqlc.GeneratedDocument, endpoint string, payload any) (sqlc.GeneratedDocument, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
 		return dbDoc, fmt.Errorf("failed to marshal python request: %w", err)
 	}
 
-	// Make HTTP call to Python service (synchronous for MVP simplicity)
-	pythonServiceURL := "" //s.config.PythonDocGenServiceURL // Add this to your Go config
-	if pythonServiceURL == "" {
-		pythonServiceURL = "http://localhost:8001/generate-document" // Default for local
-	}
+	// Make HTTP call to Python service
+	pythonServiceURL := s.docgen.URL + endpoint
 
 	s.logger.Info("Calling Python document generation service", "url", pythonServiceURL)
-	httpClient := &http.Client{Timeout: 2 * time.Minute} // Generous timeout for doc gen
-	resp, err := httpClient.Post(pythonServiceURL, "application/json", bytes.NewBuffer(jsonData))
+	httpClient := s.docgenClient(s.docgen.Timeout)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, pythonServiceURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return dbDoc, fmt.Errorf("failed to build python request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	s.setDocGenAuth(httpReq)
+	resp, err := httpClient.Do(httpReq)
 	if err != nil {
 		s.logger.Error("Failed to call Python document generation service", "error", err)
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", fmt.Sprintf("Python service call error: %v", err))
 		return dbDoc, fmt.Errorf("python service call failed: %w", err)
 	}
 	defer resp.Body.Close()
@@ -644,47 +1758,85 @@ func (s *ResearchService) GenerateDocument(ctx context.Context, projectID, userI
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		errMsg := fmt.Sprintf("Python service returned error: %s, Body: %s", resp.Status, string(bodyBytes))
 		s.logger.Error(errMsg)
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", fmt.Sprintf("Python service error: %s", resp.Status))
 		return dbDoc, fmt.Errorf(errMsg)
 	}
 
 	var pyResp PythonDocGenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&pyResp); err != nil {
 		s.logger.Error("Failed to decode response from Python service", "error", err)
-		s.updateDocStatus(ctx, dbDoc.ID.Bytes, "failed", "Python service response decode error")
 		return dbDoc, fmt.Errorf("python service decode error: %w", err)
 	}
 
-	// If successful, update DB record with file name and path
-	// The Python service saves to a known location pattern or a shared volume.
-	// The Go service constructs the path it knows the Python service used.
-	// Example: /app/generated_documents in Python container, mapped to ./data/generated_documents on host
-	// Go service needs to know this host path to serve the file.
-	// For Docker, the path would be relative to a shared volume.
-	generatedFilePath := fmt.Sprintf("%s/%s", OUTPUT_DIR_FOR_GO, pyResp.FileName) // OUTPUT_DIR_FOR_GO is the path Go uses to access the file
-
-	_, err = s.store.UpdateGeneratedDocument(ctx, sqlc.UpdateGeneratedDocumentParams{ // Assuming you add this query
-		ID:       dbDoc.ID,
-		FileName: pyResp.FileName,
-		FilePath: generatedFilePath,
-		Status:   pgtype.Text{String: "completed", Valid: true},
-		//FileSize:  // Python could return this, or Go could stat the file
+	// The service writes to the output directory shared with this process; the file is
+	// moved from there into storage so any instance can serve it
+	// Base guards against a file name escaping the output directory
+	generatedFilePath := filepath.Join(s.docgen.OutputDir, filepath.Base(pyResp.FileName))
+	key, size, err := s.storeGeneratedFile(ctx, dbDoc, generatedFilePath)
+	if err != nil {
+		return dbDoc, err
+	}
+
+	completed, err := s.store.UpdateGeneratedDocument(ctx, sqlc.UpdateGeneratedDocumentParams{
+		ID:         dbDoc.ID,
+		FileName:   pyResp.FileName,
+		FilePath:   key,
+		FileSize:   pgtype.Int8{Int64: size, Valid: true},
+		MimeType:   dbDoc.MimeType,
+		Status:     pgtype.Text{String: "completed", Valid: true},
+		StorageKey: pgtype.Text{String: key, Valid: true},
 	})
 	if err != nil {
 		s.logger.Error("Failed to update document record to completed", "docID", dbDoc.ID, "error", err)
-		// Log error but client already got a positive response from Python (potentially)
-		// This part is tricky with synchronous calls. An async approach is better.
+		s.removeUploadFile(ctx, key)
+		return dbDoc, fmt.Errorf("could not save generated document: %w", err)
 	}
-	dbDoc.FileName = pyResp.FileName
-	dbDoc.FilePath = generatedFilePath
-	dbDoc.Status = pgtype.Text{String: "completed", Valid: true}
 
-	s.logger.Info("Document generation request processed by Python service.", "docID", dbDoc.ID, "fileName", pyResp.FileName)
-	return dbDoc, nil
+	s.logger.Info("Document generation request processed by Python service.", "docID", dbDoc.ID, "fileName", pyResp.FileName, "storageKey", key)
+	return completed, nil
+}
+
+// PingDocGen checks that the Python document generation service is up
+// The shared secret is sent too, so a mismatched PYTHON_DOCGEN_SECRET fails the check.
+func (s *ResearchService) PingDocGen(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.docgen.URL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to build docgen health request: %w", err)
+	}
+	s.setDocGenAuth(req)
+	resp, err := s.docgenClient(5 * time.Second).Do(req)
+	if err != nil {
+		return fmt.Errorf("docgen service unreachable at %s: %w", s.docgen.URL, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("docgen service at %s rejected PYTHON_DOCGEN_SECRET (status %d)", s.docgen.URL, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("docgen service at %s returned status %d", s.docgen.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// docgenClient returns a client for the docgen service, behind its circuit breaker
+func (s *ResearchService) docgenClient(timeout time.Duration) *http.Client {
+	return telemetry.NewHTTPClient(&http.Client{Timeout: timeout, Transport: s.docgenCircuit.Transport(nil)})
+}
+
+// Circuits returns the circuit breakers of the services ResearchService calls itself,
+// besides those of AIService
+func (s *ResearchService) Circuits() []*breaker.Breaker {
+	return []*breaker.Breaker{s.docgenCircuit}
+}
+
+func (s *ResearchService) setDocGenAuth(req *http.Request) {
+	if s.docgen.SharedSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+s.docgen.SharedSecret)
+	}
 }
 
 // Helper to update document status
-func (s *ResearchService) updateDocStatus(ctx context.Context, docID uuid.UUID, status string, statusMessage string) {
+// UpdateDocumentStatus records a status change reported for a generated document
+func (s *ResearchService) UpdateDocumentStatus(ctx context.Context, docID uuid.UUID, status string, statusMessage string) error {
 	// You'll need an UpdateGeneratedDocument query that can set status and a status_message field
 	s.logger.Info("Updating document status", "docID", docID, "status", status, "message", statusMessage)
 	_, err := s.store.UpdateGeneratedDocumentStatus(ctx, sqlc.UpdateGeneratedDocumentStatusParams{
@@ -693,6 +1845,16 @@ func (s *ResearchService) updateDocStatus(ctx context.Context, docID uuid.UUID,
 		// Add a status_message field to your generated_documents table and sqlc query
 	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return ErrDocumentNotFound
+		}
 		s.logger.Error("Failed to update document status in DB", "docID", docID, "error", err)
+		return fmt.Errorf("could not update document status: %w", err)
 	}
+	return nil
+}
+
+func (s *ResearchService) updateDocStatus(ctx context.Context, docID uuid.UUID, status string, statusMessage string) {
+	// Errors are logged by UpdateDocumentStatus; callers here are already on a failure path
+	_ = s.UpdateDocumentStatus(ctx, docID, status, statusMessage)
 }
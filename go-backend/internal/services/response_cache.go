@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ResponseCacheConfig sizes the cache of AI replies
+type ResponseCacheConfig struct {
+	TTL        time.Duration // How long a reply is reused for an identical request; 0 disables the cache
+	MaxEntries int
+}
+
+// responseCache keeps recent AI replies by request, so that the same request made again
+// shortly after, such as a double-clicked Generate or a template generated twice from the
+// same answers, reuses the reply instead of paying for it twice. A request made while an
+// identical one is in flight waits for its reply. Only successful replies are kept.
+type responseCache struct {
+	cfg ResponseCacheConfig
+
+	mu       sync.Mutex
+	entries  map[string]cachedResponse
+	inflight map[string]*inflightResponse
+}
+
+type cachedResponse struct {
+	resp    OpenAIResponse
+	expires time.Time
+}
+
+type inflightResponse struct {
+	done chan struct{}
+	resp OpenAIResponse
+	err  error
+}
+
+func newResponseCache(cfg ResponseCacheConfig) *responseCache {
+	return &responseCache{
+		cfg:      cfg,
+		entries:  make(map[string]cachedResponse),
+		inflight: make(map[string]*inflightResponse),
+	}
+}
+
+type responseCacheUseKey struct{}
+
+// responseCacheUse is how the AI calls of a generation use the cache: whether they skip it,
+// and how many of their replies came from the AI provider rather than from the cache
+type responseCacheUse struct {
+	bypass   bool
+	provided atomic.Int64
+}
+
+// withResponseCacheUse returns a context whose AI calls skip the cache when bypass is set,
+// as when the user asks for a new draft, and are counted, so that a generation served
+// entirely from the cache can be left uncharged
+func withResponseCacheUse(ctx context.Context, bypass bool) (context.Context, *responseCacheUse) {
+	use := &responseCacheUse{bypass: bypass}
+	return context.WithValue(ctx, responseCacheUseKey{}, use), use
+}
+
+// fresh reports whether any reply came from the AI provider
+func (u *responseCacheUse) fresh() bool {
+	return u.provided.Load() > 0
+}
+
+// noteFreshReply counts a reply from the AI provider against the cache use attached to ctx, if any
+func noteFreshReply(ctx context.Context) {
+	if use, ok := ctx.Value(responseCacheUseKey{}).(*responseCacheUse); ok {
+		use.provided.Add(1)
+	}
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	use, ok := ctx.Value(responseCacheUseKey{}).(*responseCacheUse)
+	return ok && use.bypass
+}
+
+// responseCacheKey identifies a request by its model, parameters and prompt
+func responseCacheKey(request OpenAIRequest) string {
+	return fmt.Sprintf("%s\x00%g\x00%d\x00%s", request.Model, request.Temperature, request.MaxTokens, promptHash(request))
+}
+
+// do returns the cached reply for key, reporting it as cached, or makes the call. The
+// reply of a failed call is returned with its error, as call returned it.
+func (c *responseCache) do(ctx context.Context, key string, call func() (*OpenAIResponse, error)) (*OpenAIResponse, bool, error) {
+	if c.cfg.TTL <= 0 {
+		resp, err := call()
+		return resp, false, err
+	}
+	if cacheBypassed(ctx) { // A new reply was asked for; it replaces the cached one
+		resp, err := call()
+		if err == nil {
+			c.mu.Lock()
+			c.store(key, *resp)
+			c.mu.Unlock()
+		}
+		return resp, false, err
+	}
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expires) {
+		c.mu.Unlock()
+		resp := e.resp
+		return &resp, true, nil
+	}
+	if f, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+		if f.err == nil {
+			resp := f.resp
+			return &resp, true, nil
+		}
+		resp, err := call() // The first call's failure may have been its own, such as its caller going away
+		return resp, false, err
+	}
+	f := &inflightResponse{done: make(chan struct{})}
+	c.inflight[key] = f
+	c.mu.Unlock()
+
+	resp, err := call()
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	f.err = err
+	if err == nil {
+		f.resp = *resp
+		c.store(key, *resp)
+	}
+	c.mu.Unlock()
+	close(f.done)
+	return resp, false, err
+}
+
+// store keeps a reply, making room by dropping expired replies and, when none have
+// expired, the one closest to expiring. Called with mu held.
+func (c *responseCache) store(key string, resp OpenAIResponse) {
+	if len(c.entries) >= c.cfg.MaxEntries {
+		now := time.Now()
+		var oldest string
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			} else if oldest == "" || e.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.cfg.MaxEntries && oldest != "" {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = cachedResponse{resp: resp, expires: time.Now().Add(c.cfg.TTL)}
+}
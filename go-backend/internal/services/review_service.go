@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Chapter statuses owned by the review workflow
+const (
+	ChapterStatusInReview         = "in_review"
+	ChapterStatusChangesRequested = "changes_requested"
+	ChapterStatusApproved         = "approved"
+	ChapterStatusRejected         = "rejected"
+)
+
+// Review decisions and the statuses they lead to
+const (
+	ReviewDecisionApprove        = "approve"
+	ReviewDecisionRequestChanges = "request_changes"
+
+	ReviewStatusPending          = "pending"
+	ReviewStatusApproved         = "approved"
+	ReviewStatusChangesRequested = "changes_requested"
+)
+
+var (
+	ErrNoAdvisor        = errors.New("project has no advisor assigned")
+	ErrAdvisorIsOwner   = errors.New("the project owner cannot be their own advisor")
+	ErrNotAdvisor       = errors.New("only the project's advisor can review chapters")
+	ErrChapterInReview  = errors.New("chapter is waiting for review")
+	ErrNoPendingReview  = errors.New("chapter has no pending review")
+	ErrEmptyChapter     = errors.New("an empty chapter cannot be submitted for review")
+	ErrReviewIsRequired = errors.New("approved and rejected are set by the advisor's review on projects with an advisor")
+)
+
+// checkStatusChange enforces the review workflow on status changes made by editing a chapter.
+// While a chapter is in review its status is frozen, and once a project has an advisor only
+// the advisor's decision can approve or reject chapters.
+func checkStatusChange(project sqlc.ResearchProject, current, next string) error {
+	if next == current {
+		return nil
+	}
+	if current == ChapterStatusInReview {
+		return ErrChapterInReview
+	}
+	if project.AdvisorID.Valid && (next == ChapterStatusApproved || next == ChapterStatusRejected) {
+		return ErrReviewIsRequired
+	}
+	return nil
+}
+
+// SetAdvisor makes a registered user the project's advisor. Only the owner can do this.
+// The advisor can read and comment on the project and decides its chapter reviews.
+func (s *ResearchService) SetAdvisor(ctx context.Context, projectID, userID uuid.UUID, req apimodels.SetAdvisorRequest) (sqlc.ResearchProject, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner)
+	if err != nil {
+		return sqlc.ResearchProject{}, err
+	}
+	advisor, err := s.store.GetUserByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ResearchProject{}, ErrNoUserWithEmail
+		}
+		return sqlc.ResearchProject{}, fmt.Errorf("database error fetching user: %w", err)
+	}
+	if advisor.ID == project.UserID {
+		return sqlc.ResearchProject{}, ErrAdvisorIsOwner
+	}
+	updated, err := s.store.SetProjectAdvisor(ctx, sqlc.SetProjectAdvisorParams{AdvisorID: advisor.ID, ID: project.ID})
+	if err != nil {
+		return sqlc.ResearchProject{}, fmt.Errorf("could not set advisor: %w", err)
+	}
+	s.logger.Info("Project advisor assigned", "projectID", projectID, "advisorID", advisor.ID.Bytes, "userID", userID)
+	return updated, nil
+}
+
+// RemoveAdvisor unassigns the project's advisor. Pending reviews stay pending for the next advisor.
+func (s *ResearchService) RemoveAdvisor(ctx context.Context, projectID, userID uuid.UUID) error {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner)
+	if err != nil {
+		return err
+	}
+	if !project.AdvisorID.Valid {
+		return ErrNoAdvisor
+	}
+	if _, err := s.store.SetProjectAdvisor(ctx, sqlc.SetProjectAdvisorParams{ID: project.ID}); err != nil {
+		return fmt.Errorf("could not remove advisor: %w", err)
+	}
+	s.logger.Info("Project advisor removed", "projectID", projectID, "advisorID", project.AdvisorID.Bytes, "userID", userID)
+	return nil
+}
+
+// SubmitChapterForReview sends a chapter to the project's advisor and freezes its status until they decide
+func (s *ResearchService) SubmitChapterForReview(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.SubmitChapterRequest) (sqlc.ChapterReview, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ChapterReview{}, err
+	}
+	if !project.AdvisorID.Valid {
+		return sqlc.ChapterReview{}, ErrNoAdvisor
+	}
+	chapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+	if err != nil {
+		return sqlc.ChapterReview{}, err
+	}
+	if chapter.Status.String == ChapterStatusInReview {
+		return sqlc.ChapterReview{}, ErrChapterInReview
+	}
+	if strings.TrimSpace(chapter.Content.String) == "" {
+		return sqlc.ChapterReview{}, ErrEmptyChapter
+	}
+
+	var review sqlc.ChapterReview
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+		review, err = q.CreateChapterReview(ctx, sqlc.CreateChapterReviewParams{
+			ProjectID:      chapter.ProjectID,
+			ChapterID:      chapter.ID,
+			ChapterVersion: chapter.Version,
+			SubmittedBy:    pgtype.UUID{Bytes: userID, Valid: true},
+			SubmissionNote: markdown.Sanitize(strings.TrimSpace(req.Note)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.SetChapterStatus(ctx, sqlc.SetChapterStatusParams{
+			ID:     chapter.ID,
+			Status: pgtype.Text{String: ChapterStatusInReview, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // Someone else submitted it first
+			return sqlc.ChapterReview{}, ErrChapterInReview
+		}
+		s.logger.Error("Failed to submit chapter for review", "chapterID", chapterID, "error", err)
+		return sqlc.ChapterReview{}, fmt.Errorf("could not submit chapter for review: %w", err)
+	}
+
+	s.logger.Info("Chapter submitted for review", "reviewID", review.ID.Bytes, "chapterID", chapterID, "version", chapter.Version, "userID", userID)
+	s.events.Publish(events.Event{
+		Type:      events.ReviewRequested,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Status:    review.Status,
+		Message:   fmt.Sprintf("%s submitted for review", chapter.Title),
+	})
+	s.notifications.Notify(ctx, Notification{
+		Type:      NotificationReviewRequested,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Title:     fmt.Sprintf("%s from %s is waiting for your review", chapter.Title, project.Title),
+		Body:      notificationExcerpt(review.SubmissionNote),
+	}, recipientsExcept(userID, project.AdvisorID)...)
+	return review, nil
+}
+
+// DecideReview approves the chapter's pending review or requests changes. Only the project's advisor can decide.
+func (s *ResearchService) DecideReview(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.ReviewDecisionRequest) (sqlc.ChapterReview, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead)
+	if err != nil {
+		return sqlc.ChapterReview{}, err
+	}
+	if !project.AdvisorID.Valid || project.AdvisorID.Bytes != userID {
+		return sqlc.ChapterReview{}, ErrNotAdvisor
+	}
+	chapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+	if err != nil {
+		return sqlc.ChapterReview{}, err
+	}
+
+	reviewStatus, chapterStatus := ReviewStatusApproved, ChapterStatusApproved
+	if req.Decision == ReviewDecisionRequestChanges {
+		reviewStatus, chapterStatus = ReviewStatusChangesRequested, ChapterStatusChangesRequested
+	}
+
+	var review sqlc.ChapterReview
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		pending, err := q.GetPendingChapterReview(ctx, chapter.ID)
+		if err != nil {
+			if isNoRows(err) {
+				return ErrNoPendingReview
+			}
+			return err
+		}
+		review, err = q.DecideChapterReview(ctx, sqlc.DecideChapterReviewParams{
+			ID:           pending.ID,
+			Status:       reviewStatus,
+			ReviewedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+			DecisionNote: markdown.Sanitize(strings.TrimSpace(req.Note)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.SetChapterStatus(ctx, sqlc.SetChapterStatusParams{
+			ID:     chapter.ID,
+			Status: pgtype.Text{String: chapterStatus, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrNoPendingReview) {
+			return sqlc.ChapterReview{}, err
+		}
+		s.logger.Error("Failed to record review decision", "chapterID", chapterID, "error", err)
+		return sqlc.ChapterReview{}, fmt.Errorf("could not record review decision: %w", err)
+	}
+
+	s.logger.Info("Chapter review decided", "reviewID", review.ID.Bytes, "chapterID", chapterID, "decision", req.Decision, "userID", userID)
+	s.events.Publish(events.Event{
+		Type:      events.ReviewDecided,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Status:    review.Status,
+		Message:   fmt.Sprintf("Review of %s: %s", chapter.Title, strings.ReplaceAll(review.Status, "_", " ")),
+	})
+	title := fmt.Sprintf("%s was approved", chapter.Title)
+	if review.Status == ReviewStatusChangesRequested {
+		title = fmt.Sprintf("Changes requested on %s", chapter.Title)
+	}
+	s.notifications.Notify(ctx, Notification{
+		Type:      NotificationReviewDecided,
+		ProjectID: projectID,
+		ChapterID: &chapterID,
+		Title:     title,
+		Body:      notificationExcerpt(review.DecisionNote),
+	}, recipientsExcept(userID, review.SubmittedBy, project.UserID)...)
+	s.webhooks.Emit(ctx, projectID, WebhookReviewDecided, apimodels.ToReviewResponse(review))
+	return review, nil
+}
+
+// ListChapterReviews returns every submission of a chapter, newest first
+func (s *ResearchService) ListChapterReviews(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]sqlc.ChapterReview, error) {
+	chapter, err := s.GetChapterByID(ctx, chapterID, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.store.ListChapterReviews(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// ReviewQueue returns the pending reviews of every project the user advises, oldest first
+func (s *ResearchService) ReviewQueue(ctx context.Context, userID uuid.UUID) ([]sqlc.ListReviewQueueRow, error) {
+	queue, err := s.store.ListReviewQueue(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching review queue: %w", err)
+	}
+	return queue, nil
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/markdown"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrSectionNotFound     = errors.New("section not found")
+	ErrInvalidSectionOrder = errors.New("section_ids must list each of the chapter's sections once")
+)
+
+// maxSectionLevel is the deepest a section nests: 1.1.1.1 under chapter 1
+const maxSectionLevel = 3
+
+// PythonSectionData is a section as the docgen service renders it after its chapter's
+// content, under a heading numbered within the chapter
+type PythonSectionData struct {
+	Number  string `json:"number"` // e.g. 2.1.3
+	Level   int    `json:"level"`  // 1 for 2.1, 2 for 2.1.3...
+	Title   string `json:"title"`
+	Content string `json:"content"`
+
+	AIGenerated bool `json:"ai_generated,omitempty"` // Noted under the heading in watermarked exports
+}
+
+// sectionChapter loads a chapter of the project, and the project, for working on its sections
+func (s *ResearchService) sectionChapter(ctx context.Context, projectID, chapterID, userID uuid.UUID, required string) (sqlc.ResearchProject, sqlc.Chapter, error) {
+	project, err := s.requireProjectRole(ctx, projectID, userID, required)
+	if err != nil {
+		return project, sqlc.Chapter{}, err
+	}
+	chapter, err := s.store.GetProjectChapterByID(ctx, sqlc.GetProjectChapterByIDParams{
+		ID:        pgtype.UUID{Bytes: chapterID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return project, sqlc.Chapter{}, ErrChapterNotFound
+		}
+		return project, sqlc.Chapter{}, fmt.Errorf("database error fetching chapter: %w", err)
+	}
+	return project, chapter, nil
+}
+
+// ListSections returns the chapter's sections in order
+func (s *ResearchService) ListSections(ctx context.Context, projectID, chapterID, userID uuid.UUID) ([]sqlc.ChapterSection, error) {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := s.store.ListChapterSections(ctx, chapter.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching sections: %w", err)
+	}
+	return sections, nil
+}
+
+// GetSection returns one of the chapter's sections
+func (s *ResearchService) GetSection(ctx context.Context, projectID, chapterID, sectionID, userID uuid.UUID) (sqlc.ChapterSection, error) {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	return s.chapterSection(ctx, chapter, sectionID)
+}
+
+func (s *ResearchService) chapterSection(ctx context.Context, chapter sqlc.Chapter, sectionID uuid.UUID) (sqlc.ChapterSection, error) {
+	section, err := s.store.GetChapterSection(ctx, sqlc.GetChapterSectionParams{
+		ID:        pgtype.UUID{Bytes: sectionID, Valid: true},
+		ChapterID: chapter.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterSection{}, ErrSectionNotFound
+		}
+		return sqlc.ChapterSection{}, fmt.Errorf("database error fetching section: %w", err)
+	}
+	return section, nil
+}
+
+// CreateSection adds a section after the chapter's others
+func (s *ResearchService) CreateSection(ctx context.Context, projectID, chapterID, userID uuid.UUID, req apimodels.CreateSectionRequest) (sqlc.ChapterSection, error) {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	title := markdown.SanitizeLine(req.Title)
+	if title == "" {
+		return sqlc.ChapterSection{}, ErrEmptyAfterSanitizing
+	}
+	level := req.Level
+	if level == 0 {
+		level = 1
+	}
+	content := markdown.Sanitize(req.Content)
+	section, err := s.store.CreateChapterSection(ctx, sqlc.CreateChapterSectionParams{
+		ChapterID: chapter.ID,
+		ProjectID: chapter.ProjectID,
+		Title:     title,
+		Level:     level,
+		Content:   content,
+		WordCount: int32(countWords(content)),
+	})
+	if err != nil {
+		s.logger.Error("Failed to create section", "chapterID", chapterID, "error", err)
+		return sqlc.ChapterSection{}, fmt.Errorf("could not create section: %w", err)
+	}
+	s.logger.Info("Section created", "chapterID", chapterID, "sectionID", section.ID)
+	return section, nil
+}
+
+// UpdateSection edits a section; fields left out keep their value
+func (s *ResearchService) UpdateSection(ctx context.Context, projectID, chapterID, sectionID, userID uuid.UUID, req apimodels.UpdateSectionRequest) (sqlc.ChapterSection, error) {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	current, err := s.chapterSection(ctx, chapter, sectionID)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	params := sqlc.UpdateChapterSectionParams{
+		ID:        current.ID,
+		ChapterID: chapter.ID,
+		Title:     current.Title,
+		Level:     current.Level,
+		Content:   current.Content,
+		WordCount: current.WordCount,
+	}
+	if req.Title != nil {
+		if params.Title = markdown.SanitizeLine(*req.Title); params.Title == "" {
+			return sqlc.ChapterSection{}, ErrEmptyAfterSanitizing
+		}
+	}
+	if req.Level != nil {
+		params.Level = *req.Level
+	}
+	if req.Content != nil {
+		params.Content = markdown.Sanitize(*req.Content)
+		params.WordCount = int32(countWords(params.Content))
+	}
+	section, err := s.store.UpdateChapterSection(ctx, params)
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ChapterSection{}, ErrSectionNotFound
+		}
+		s.logger.Error("Failed to update section", "sectionID", sectionID, "error", err)
+		return sqlc.ChapterSection{}, fmt.Errorf("could not update section: %w", err)
+	}
+	return section, nil
+}
+
+// DeleteSection removes a section for good
+func (s *ResearchService) DeleteSection(ctx context.Context, projectID, chapterID, sectionID, userID uuid.UUID) error {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return err
+	}
+	if _, err := s.chapterSection(ctx, chapter, sectionID); err != nil {
+		return err
+	}
+	if err := s.store.DeleteChapterSection(ctx, sqlc.DeleteChapterSectionParams{
+		ID:        pgtype.UUID{Bytes: sectionID, Valid: true},
+		ChapterID: chapter.ID,
+	}); err != nil {
+		s.logger.Error("Failed to delete section", "sectionID", sectionID, "error", err)
+		return fmt.Errorf("could not delete section: %w", err)
+	}
+	s.logger.Info("Section deleted", "chapterID", chapterID, "sectionID", sectionID)
+	return nil
+}
+
+// ReorderSections puts the chapter's sections in the given order, every one listed once
+func (s *ResearchService) ReorderSections(ctx context.Context, projectID, chapterID, userID uuid.UUID, sectionIDs []uuid.UUID) ([]sqlc.ChapterSection, error) {
+	_, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []sqlc.ChapterSection
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		current, err := q.ListChapterSections(ctx, chapter.ID)
+		if err != nil {
+			return fmt.Errorf("could not fetch sections: %w", err)
+		}
+		if len(sectionIDs) != len(current) {
+			return ErrInvalidSectionOrder
+		}
+		byID := make(map[uuid.UUID]sqlc.ChapterSection, len(current))
+		for _, sec := range current {
+			byID[sec.ID.Bytes] = sec
+		}
+		for i, id := range sectionIDs {
+			sec, ok := byID[id]
+			if !ok {
+				return ErrInvalidSectionOrder // Not the chapter's, or listed twice
+			}
+			delete(byID, id)
+			if err := q.SetChapterSectionPosition(ctx, sqlc.SetChapterSectionPositionParams{
+				ID:        sec.ID,
+				ChapterID: chapter.ID,
+				Position:  int32(i + 1),
+			}); err != nil {
+				return fmt.Errorf("could not move section %s: %w", id, err)
+			}
+			sec.Position = int32(i + 1)
+			sections = append(sections, sec)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidSectionOrder) {
+			s.logger.Error("Section reorder failed, transaction rolled back", "chapterID", chapterID, "error", err)
+		}
+		return nil, err
+	}
+	return sections, nil
+}
+
+// GenerateSection has AI rewrite a single section of a chapter, leaving the chapter and
+// its other sections as they are. It is billed and counted against the word quota as a
+// chapter generation is.
+func (s *ResearchService) GenerateSection(ctx context.Context, projectID, chapterID, sectionID, userID uuid.UUID, req apimodels.GenerateSectionRequest) (sqlc.ChapterSection, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	ctx, span := telemetry.Tracer().Start(ctx, "ResearchService.GenerateSection")
+	span.SetAttributes(
+		attribute.String("project.id", projectID.String()),
+		attribute.String("chapter.id", chapterID.String()),
+		attribute.String("section.id", sectionID.String()),
+	)
+	defer span.End()
+
+	project, chapter, err := s.sectionChapter(ctx, projectID, chapterID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	if chapter.Status.String == ChapterStatusInReview {
+		return sqlc.ChapterSection{}, ErrChapterInReview
+	}
+	if err := s.requireIntegrityAcknowledgment(ctx, userID); err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	if ctx, err = s.withGenerationPreferences(ctx, &project, userID); err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	section, err := s.chapterSection(ctx, chapter, sectionID)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+	brief, err := s.sectionBrief(ctx, project, chapter, section)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+
+	release, err := s.billing.ReserveUsage(ctx, project.UserID.Bytes, UsageAIGenerations)
+	if err != nil {
+		return sqlc.ChapterSection{}, err
+	}
+
+	ctx, emit := s.progressEmitter(ctx, projectID, &chapterID, nil)
+	ctx = s.withGenerationLog(ctx, GenerationKindSection, userID, &projectID, &chapterID)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	ctx, cacheUse := withResponseCacheUse(ctx, false)
+	const totalSteps = 2 // AI generation, then saving the section
+	emit(events.Event{Type: events.GenerationStarted, Message: fmt.Sprintf("Generating section %s", brief.Number), TotalSteps: totalSteps})
+	opts := ChapterOptions{Language: project.Language, TargetWords: int(req.TargetWordCount), Instructions: req.Instructions}
+	generated, err := s.aiService.GenerateSection(ctx, project.Title, project.Specialization, opts, brief)
+	if err != nil {
+		release()
+		s.logger.Error("AI section generation failed", "sectionID", sectionID, "error", err)
+		err = s.retryGeneration(ctx, generationRetryJob{
+			ProjectID:       projectID,
+			ChapterID:       chapterID,
+			SectionID:       &sectionID,
+			UserID:          userID,
+			Instructions:    req.Instructions,
+			TargetWordCount: req.TargetWordCount,
+			UpdatedAt:       section.UpdatedAt.Time,
+		}, fmt.Errorf("AI generation failed: %w", err))
+		emit(events.Event{Type: events.GenerationFailed, Message: err.Error()})
+		return sqlc.ChapterSection{}, err
+	}
+	emit(events.Event{Type: events.GenerationProgress, Message: "Content generated, saving section", Step: 1, TotalSteps: totalSteps})
+
+	content := markdown.Sanitize(generated)
+	updated, err := s.store.SaveGeneratedChapterSection(ctx, sqlc.SaveGeneratedChapterSectionParams{
+		ID:        section.ID,
+		ChapterID: chapter.ID,
+		Content:   content,
+		WordCount: int32(countWords(content)),
+	})
+	if err != nil {
+		release()
+		if isNoRows(err) {
+			err = ErrSectionNotFound // Deleted while it was being written
+		}
+		emit(events.Event{Type: events.GenerationFailed, Message: err.Error()})
+		return sqlc.ChapterSection{}, err
+	}
+	if !cacheUse.fresh() {
+		release() // A reused reply is not a new generation
+	}
+	emit(events.Event{Type: events.GenerationCompleted, Message: "Section content generated", Step: totalSteps, TotalSteps: totalSteps})
+	s.logger.Info("Generated section saved", "chapterID", chapterID, "sectionID", sectionID, "words", updated.WordCount)
+	return updated, nil
+}
+
+// sectionBrief describes a section to the AI: where it sits in its chapter, and what the
+// chapter is about
+func (s *ResearchService) sectionBrief(ctx context.Context, project sqlc.ResearchProject, chapter sqlc.Chapter, section sqlc.ChapterSection) (SectionBrief, error) {
+	typeLabel := strings.ReplaceAll(chapter.Type, "_", " ")
+	chapterType, err := s.projectChapterType(ctx, project, chapter.Type)
+	switch {
+	case err == nil:
+		typeLabel = chapterType.Label
+	case !errors.Is(err, ErrUnknownChapterType):
+		return SectionBrief{}, err
+	}
+	// Numbered as in the document, where the executive summary has no place
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return SectionBrief{}, fmt.Errorf("could not fetch chapters: %w", err)
+	}
+	chapterNumber := 1
+	for _, ch := range chapters {
+		if ch.ID == chapter.ID {
+			break
+		}
+		if ch.Type != ChapterTypeExecutiveSummary {
+			chapterNumber++
+		}
+	}
+	sections, err := s.store.ListChapterSections(ctx, chapter.ID)
+	if err != nil {
+		return SectionBrief{}, fmt.Errorf("could not fetch sections: %w", err)
+	}
+	brief := SectionBrief{
+		Title:          section.Title,
+		ChapterTitle:   chapter.Title,
+		ChapterType:    typeLabel,
+		ChapterBrief:   chapter.CustomPrompt,
+		CurrentContent: section.Content,
+	}
+	for i, numbered := range numberSections(chapterNumber, sections) {
+		brief.Outline = append(brief.Outline, strings.Repeat("  ", numbered.Level-1)+numbered.Number+" "+numbered.Title)
+		if sections[i].ID == section.ID {
+			brief.Number = numbered.Number
+		}
+	}
+	return brief, nil
+}
+
+// numberSections numbers a chapter's sections as the document does: 2.1, 2.1.1, 2.2... A
+// section can only go one level deeper than the one before it, so the first is always
+// a top-level one and no number skips a level.
+func numberSections(chapterNumber int, sections []sqlc.ChapterSection) []PythonSectionData {
+	out := make([]PythonSectionData, 0, len(sections))
+	counters := make([]int, maxSectionLevel)
+	level := 0
+	for _, sec := range sections {
+		level = min(max(int(sec.Level), 1), level+1, maxSectionLevel)
+		counters[level-1]++
+		clear(counters[level:])
+		parts := []string{strconv.Itoa(chapterNumber)}
+		for _, n := range counters[:level] {
+			parts = append(parts, strconv.Itoa(n))
+		}
+		out = append(out, PythonSectionData{
+			Number:  strings.Join(parts, "."),
+			Level:   level,
+			Title:   sec.Title,
+			Content: sec.Content,
+		})
+	}
+	return out
+}
+
+// documentSections numbers a chapter's sections for its document, with their citations
+// formatted as the chapter's are
+func (b bibliography) documentSections(chapterNumber int, sections []sqlc.ChapterSection) []PythonSectionData {
+	out := numberSections(chapterNumber, sections)
+	for i, sec := range sections {
+		out[i].Content = b.sectionContent(sec)
+	}
+	return out
+}
+
+// docgenSections loads the project's sections for document generation, by chapter
+func (s *ResearchService) docgenSections(ctx context.Context, projectID uuid.UUID) (map[uuid.UUID][]sqlc.ChapterSection, error) {
+	sections, err := s.store.ListProjectSections(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("could not load sections: %w", err)
+	}
+	byChapter := make(map[uuid.UUID][]sqlc.ChapterSection)
+	for _, sec := range sections {
+		byChapter[sec.ChapterID.Bytes] = append(byChapter[sec.ChapterID.Bytes], sec)
+	}
+	return byChapter, nil
+}
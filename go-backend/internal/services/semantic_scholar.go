@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ScholarConfig points paper search at the Semantic Scholar Graph API
+type ScholarConfig struct {
+	APIURL             string // e.g. https://api.semanticscholar.org/graph/v1
+	RecommendationsURL string // e.g. https://api.semanticscholar.org/recommendations/v1
+	APIKey             string // Sent as x-api-key when set
+}
+
+// Semantic Scholar limits relevance search to the first 1000 results, 100 per page, and
+// a batch lookup to 500 papers
+const (
+	MaxPaperSearchLimit  = 100
+	MaxPaperSearchWindow = 1000
+	MaxPaperBatch        = 500
+)
+
+// semanticPaperFields are requested for every paper
+const semanticPaperFields = "paperId,title,abstract,year,venue,url,citationCount,isOpenAccess,openAccessPdf,externalIds,authors"
+
+var (
+	ErrScholarRateLimited = errors.New("semantic scholar rate limit reached, try again shortly")
+	ErrInvalidPaperSearch = errors.New("invalid paper search")
+	ErrPaperNotFound      = errors.New("paper not found on semantic scholar")
+)
+
+// SemanticPaper is a paper as returned by the Semantic Scholar Graph API
+type SemanticPaper struct {
+	PaperID       string `json:"paperId"`
+	Title         string `json:"title"`
+	Abstract      string `json:"abstract"`
+	Year          int    `json:"year"`
+	Venue         string `json:"venue"`
+	URL           string `json:"url"`
+	CitationCount int    `json:"citationCount"`
+	IsOpenAccess  bool   `json:"isOpenAccess"`
+	OpenAccessPdf *struct {
+		Url    string `json:"url"`
+		Status string `json:"status"`
+	} `json:"openAccessPdf"`
+	ExternalIds struct {
+		DOI string `json:"DOI"`
+	} `json:"externalIds"`
+	Authors []struct {
+		AuthorID string `json:"authorId"`
+		Name     string `json:"name"`
+	} `json:"authors"`
+}
+
+// PaperSearch is a relevance search with Semantic Scholar's filters
+type PaperSearch struct {
+	Query            string
+	Limit            int
+	Offset           int
+	Year             string   // 2019, 2016-2020, 2010- or -2015
+	Venues           []string // Any of these
+	MinCitationCount int
+	OpenAccessOnly   bool // Only papers with a public PDF
+}
+
+// PaperSearchResult is one page of search results
+type PaperSearchResult struct {
+	Total  int
+	Offset int
+	Next   *int // Offset of the next page; nil on the last one
+	Papers []SemanticPaper
+}
+
+// SearchSemanticScholar runs a paper search, leaving the filtering and paging to the API
+func (s *AIService) SearchSemanticScholar(ctx context.Context, search PaperSearch) (PaperSearchResult, error) {
+	if strings.TrimSpace(search.Query) == "" {
+		return PaperSearchResult{}, fmt.Errorf("%w: query is required", ErrInvalidPaperSearch)
+	}
+	if search.Limit < 1 || search.Limit > MaxPaperSearchLimit {
+		return PaperSearchResult{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPaperSearch, MaxPaperSearchLimit)
+	}
+	if search.Offset < 0 || search.Offset+search.Limit > MaxPaperSearchWindow {
+		return PaperSearchResult{}, fmt.Errorf("%w: offset + limit must not exceed %d", ErrInvalidPaperSearch, MaxPaperSearchWindow)
+	}
+
+	params := url.Values{}
+	params.Set("query", search.Query)
+	params.Set("limit", strconv.Itoa(search.Limit))
+	params.Set("offset", strconv.Itoa(search.Offset))
+	params.Set("fields", semanticPaperFields)
+	if search.Year != "" {
+		params.Set("year", search.Year)
+	}
+	if len(search.Venues) > 0 {
+		params.Set("venue", strings.Join(search.Venues, ","))
+	}
+	if search.MinCitationCount > 0 {
+		params.Set("minCitationCount", strconv.Itoa(search.MinCitationCount))
+	}
+	if search.OpenAccessOnly {
+		params.Set("openAccessPdf", "") // A flag: present means only open access papers
+	}
+
+	var page struct {
+		Total  int             `json:"total"`
+		Offset int             `json:"offset"`
+		Next   *int            `json:"next"`
+		Data   []SemanticPaper `json:"data"`
+	}
+	if err := s.callSemanticScholar(ctx, http.MethodGet, s.scholar.APIURL, "/paper/search", params, nil, &page); err != nil {
+		return PaperSearchResult{}, err
+	}
+	s.logger.Info("Semantic Scholar search", "results", len(page.Data), "total", page.Total, "offset", page.Offset)
+	return PaperSearchResult{Total: page.Total, Offset: page.Offset, Next: page.Next, Papers: page.Data}, nil
+}
+
+// GetSemanticPapersByIDs fetches papers by Semantic Scholar ID (or DOI:, ARXIV: and the
+// other prefixed IDs the API accepts) through the batch endpoint, one request per 500.
+// Unknown IDs are left out of the result.
+func (s *AIService) GetSemanticPapersByIDs(ctx context.Context, ids []string) ([]SemanticPaper, error) {
+	params := url.Values{}
+	params.Set("fields", semanticPaperFields)
+	var papers []SemanticPaper
+	for start := 0; start < len(ids); start += MaxPaperBatch {
+		batch := ids[start:min(start+MaxPaperBatch, len(ids))]
+		body, err := json.Marshal(map[string][]string{"ids": batch})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal paper batch request: %w", err)
+		}
+		var found []*SemanticPaper // null for each ID the API does not know
+		if err := s.callSemanticScholar(ctx, http.MethodPost, s.scholar.APIURL, "/paper/batch", params, bytes.NewReader(body), &found); err != nil {
+			return nil, err
+		}
+		for _, p := range found {
+			if p != nil {
+				papers = append(papers, *p)
+			}
+		}
+	}
+	s.logger.Info("Semantic Scholar batch lookup", "requested", len(ids), "found", len(papers))
+	return papers, nil
+}
+
+// Directions of a paper's citation graph
+const (
+	CitationGraphReferences = "references" // Papers it cites
+	CitationGraphCitations  = "citations"  // Papers citing it
+)
+
+// MaxCitationGraphLimit is the page size limit of the citation endpoints
+const MaxCitationGraphLimit = 1000
+
+// GetSemanticPaperGraph pages through the papers a paper cites (references) or the
+// papers citing it (citations). The endpoints report no total.
+func (s *AIService) GetSemanticPaperGraph(ctx context.Context, paperID, direction string, offset, limit int) (PaperSearchResult, error) {
+	params := url.Values{}
+	params.Set("fields", semanticPaperFields)
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(limit))
+	var page struct {
+		Offset int  `json:"offset"`
+		Next   *int `json:"next"`
+		Data   []struct {
+			CitedPaper  SemanticPaper `json:"citedPaper"`
+			CitingPaper SemanticPaper `json:"citingPaper"`
+		} `json:"data"`
+	}
+	// DOI: IDs keep their slashes
+	path := "/paper/" + (&url.URL{Path: paperID}).EscapedPath() + "/" + direction
+	if err := s.callSemanticScholar(ctx, http.MethodGet, s.scholar.APIURL, path, params, nil, &page); err != nil {
+		return PaperSearchResult{}, err
+	}
+	result := PaperSearchResult{Offset: page.Offset, Next: page.Next}
+	for _, edge := range page.Data {
+		// Papers outside the corpus come back without an ID and cannot be imported
+		p := edge.CitedPaper
+		if direction == CitationGraphCitations {
+			p = edge.CitingPaper
+		}
+		if p.PaperID != "" {
+			result.Papers = append(result.Papers, p)
+		}
+	}
+	return result, nil
+}
+
+// RecommendSemanticPapers asks the recommendations API for papers like the given ones.
+// The seed papers themselves are not recommended.
+func (s *AIService) RecommendSemanticPapers(ctx context.Context, seedIDs []string, limit int) ([]SemanticPaper, error) {
+	if len(seedIDs) == 0 {
+		return nil, nil
+	}
+	params := url.Values{}
+	params.Set("fields", semanticPaperFields)
+	params.Set("limit", strconv.Itoa(limit))
+	body, err := json.Marshal(map[string][]string{"positivePaperIds": seedIDs, "negativePaperIds": {}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recommendations request: %w", err)
+	}
+	var result struct {
+		RecommendedPapers []SemanticPaper `json:"recommendedPapers"`
+	}
+	if err := s.callSemanticScholar(ctx, http.MethodPost, s.scholar.RecommendationsURL, "/papers/", params, bytes.NewReader(body), &result); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Semantic Scholar recommendations", "seeds", len(seedIDs), "results", len(result.RecommendedPapers))
+	return result.RecommendedPapers, nil
+}
+
+// callSemanticScholar sends a request to one of the Semantic Scholar APIs and decodes
+// the JSON reply into out
+func (s *AIService) callSemanticScholar(ctx context.Context, method, baseURL, path string, params url.Values, body io.Reader, out any) (err error) {
+	ctx, span := telemetry.Tracer().Start(ctx, "AIService.callSemanticScholar")
+	span.SetAttributes(attribute.String("scholar.path", path))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	endpoint := strings.TrimRight(baseURL, "/") + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to create semantic scholar request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.scholar.APIKey != "" {
+		req.Header.Set("x-api-key", s.scholar.APIKey)
+	}
+
+	resp, err := s.scholarClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("semantic scholar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read semantic scholar response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return ErrScholarRateLimited
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrPaperNotFound
+	case resp.StatusCode == http.StatusBadRequest:
+		// The API explains what it rejected, e.g. a malformed year range
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return fmt.Errorf("%w: %s", ErrInvalidPaperSearch, apiErr.Error)
+	case resp.StatusCode != http.StatusOK:
+		s.logger.Error("Semantic Scholar API error", "status_code", resp.StatusCode, "response_body", string(respBody))
+		return fmt.Errorf("semantic scholar request failed with status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode semantic scholar response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// hedgeTerms are the words and phrases academic writing uses to soften claims
+var hedgeTerms = []string{
+	"may", "might", "could", "possibly", "perhaps", "probably", "likely", "unlikely", "potentially",
+	"appears", "appear", "seems", "seem", "suggests", "suggest", "indicates", "indicate", "tends to",
+	"tend to", "arguably", "presumably", "somewhat", "to some extent", "it is possible that",
+}
+
+// beVerbs open a passive construction: a form of "to be", then a past participle
+var beVerbs = map[string]bool{
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true,
+}
+
+// irregularParticiples are common past participles not ending in -ed
+var irregularParticiples = map[string]bool{
+	"known": true, "shown": true, "given": true, "taken": true, "seen": true, "done": true, "made": true,
+	"found": true, "held": true, "built": true, "chosen": true, "drawn": true, "written": true, "thought": true,
+	"brought": true, "taught": true, "sought": true, "paid": true, "set": true, "put": true, "kept": true,
+	"left": true, "told": true, "sent": true, "spent": true, "understood": true, "undertaken": true,
+	"driven": true, "grown": true, "born": true, "led": true, "met": true, "won": true, "run": true,
+}
+
+// sentenceLengthRanges bucket the sentence length distribution, in words
+var sentenceLengthRanges = []struct {
+	label    string
+	min, max int
+}{
+	{"1-10", 1, 10}, {"11-20", 11, 20}, {"21-30", 21, 30}, {"31-40", 31, 40}, {"41+", 41, math.MaxInt},
+}
+
+// recordChapterMetrics recomputes and stores the metrics of a chapter after a save
+func recordChapterMetrics(ctx context.Context, q sqlc.Querier, chapter sqlc.Chapter) (sqlc.ChapterMetric, error) {
+	data, err := json.Marshal(computeTextMetrics(chapter.Content.String))
+	if err != nil {
+		return sqlc.ChapterMetric{}, fmt.Errorf("could not encode chapter metrics: %w", err)
+	}
+	stored, err := q.UpsertChapterMetrics(ctx, sqlc.UpsertChapterMetricsParams{
+		ChapterID: chapter.ID,
+		ProjectID: chapter.ProjectID,
+		Version:   chapter.Version,
+		Metrics:   data,
+	})
+	if err != nil {
+		return sqlc.ChapterMetric{}, fmt.Errorf("could not save chapter metrics: %w", err)
+	}
+	return stored, nil
+}
+
+// recordChapterSave brings what is derived from a chapter's content, its provenance
+// and metrics, up to date after a save
+func recordChapterSave(ctx context.Context, q sqlc.Querier, chapter sqlc.Chapter, origin string) error {
+	if err := recordProvenance(ctx, q, chapter, origin); err != nil {
+		return err
+	}
+	_, err := recordChapterMetrics(ctx, q, chapter)
+	return err
+}
+
+// trackChapterSave records a save made outside a transaction. It is best effort: a
+// missed save is caught up on by the next one or when the reports are read.
+func (s *ResearchService) trackChapterSave(ctx context.Context, chapter sqlc.Chapter, origin string) {
+	if err := recordChapterSave(ctx, s.store, chapter, origin); err != nil {
+		s.logger.Warn("Failed to record chapter save", "chapterID", uuid.UUID(chapter.ID.Bytes), "origin", origin, "error", err)
+	}
+}
+
+// ChapterMetrics returns a chapter's readability and tone metrics, computing them when
+// the chapter changed since they were last stored. Requires read access.
+func (s *ResearchService) ChapterMetrics(ctx context.Context, projectID, chapterID, userID uuid.UUID) (apimodels.ChapterMetricsResponse, error) {
+	chapter, err := s.figureChapter(ctx, projectID, chapterID, userID, ProjectRoleRead)
+	if err != nil {
+		return apimodels.ChapterMetricsResponse{}, err
+	}
+	stored, err := s.store.GetChapterMetrics(ctx, chapter.ID)
+	if err != nil && !isNoRows(err) {
+		return apimodels.ChapterMetricsResponse{}, fmt.Errorf("database error fetching chapter metrics: %w", err)
+	}
+	if err != nil || stored.Version != chapter.Version {
+		if stored, err = recordChapterMetrics(ctx, s.store, chapter); err != nil {
+			return apimodels.ChapterMetricsResponse{}, err
+		}
+	}
+	resp := apimodels.ChapterMetricsResponse{
+		ChapterID:      chapter.ID.Bytes,
+		ChapterVersion: stored.Version,
+		ComputedAt:     stored.ComputedAt.Time,
+	}
+	if err := json.Unmarshal(stored.Metrics, &resp.TextMetrics); err != nil {
+		return apimodels.ChapterMetricsResponse{}, fmt.Errorf("could not decode chapter metrics: %w", err)
+	}
+	return resp, nil
+}
+
+// computeTextMetrics measures the prose paragraphs of a chapter
+func computeTextMetrics(content string) apimodels.TextMetrics {
+	metrics := apimodels.TextMetrics{HedgeTerms: make(map[string]int)}
+	var lengths []int
+	syllables := 0
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		text := strings.TrimSpace(paragraph)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "|") || strings.HasPrefix(text, "[[") {
+			continue
+		}
+		for _, sentence := range sentenceEndPattern.Split(text, -1) {
+			words := strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+			})
+			if len(words) == 0 {
+				continue
+			}
+			lengths = append(lengths, len(words))
+			for _, w := range words {
+				syllables += countSyllables(w)
+			}
+			if isPassive(words) {
+				metrics.PassiveSentences++
+			}
+		}
+		lower := strings.ToLower(text)
+		for _, term := range hedgeTerms {
+			if n := countPhrase(lower, term); n > 0 {
+				metrics.HedgeTerms[term] += n
+				metrics.Hedges += n
+			}
+		}
+	}
+
+	for _, r := range sentenceLengthRanges {
+		metrics.LengthBuckets = append(metrics.LengthBuckets, apimodels.SentenceLengthBucket{Range: r.label})
+	}
+	metrics.Sentences = len(lengths)
+	if metrics.Sentences == 0 {
+		return metrics
+	}
+	for _, l := range lengths {
+		metrics.Words += l
+		for i, r := range sentenceLengthRanges {
+			if l >= r.min && l <= r.max {
+				metrics.LengthBuckets[i].Sentences++
+			}
+		}
+	}
+
+	wordsPerSentence := float64(metrics.Words) / float64(metrics.Sentences)
+	syllablesPerWord := float64(syllables) / float64(metrics.Words)
+	metrics.FleschReadingEase = round2(206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord)
+	metrics.FleschKincaidGrade = round2(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+	metrics.PassiveRatio = round2(float64(metrics.PassiveSentences) / float64(metrics.Sentences))
+	metrics.HedgesPer1000Words = round2(float64(metrics.Hedges) * 1000 / float64(metrics.Words))
+
+	slices.Sort(lengths)
+	var variance float64
+	for _, l := range lengths {
+		variance += (float64(l) - wordsPerSentence) * (float64(l) - wordsPerSentence)
+	}
+	median := float64(lengths[len(lengths)/2])
+	if len(lengths)%2 == 0 {
+		median = float64(lengths[len(lengths)/2-1]+lengths[len(lengths)/2]) / 2
+	}
+	metrics.SentenceLength = apimodels.SentenceLengthStats{
+		Mean:   round2(wordsPerSentence),
+		Median: median,
+		StdDev: round2(math.Sqrt(variance / float64(len(lengths)))),
+		Min:    lengths[0],
+		Max:    lengths[len(lengths)-1],
+	}
+	return metrics
+}
+
+// isPassive reports whether a sentence has a form of "to be" followed, with at most one
+// adverb between, by a past participle
+func isPassive(words []string) bool {
+	for i, w := range words {
+		if !beVerbs[w] {
+			continue
+		}
+		for j := i + 1; j < len(words) && j <= i+2; j++ {
+			next := words[j]
+			if irregularParticiples[next] || (len(next) > 3 && strings.HasSuffix(next, "ed")) {
+				return true
+			}
+			if !strings.HasSuffix(next, "ly") { // Only an adverb may come between
+				break
+			}
+		}
+	}
+	return false
+}
+
+// countSyllables estimates the syllables of an English word by its vowel groups
+func countSyllables(word string) int {
+	count, inVowel := 0, false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !inVowel {
+			count++
+		}
+		inVowel = vowel
+	}
+	switch {
+	case count <= 1:
+	case strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le"):
+		count-- // Silent e
+	case strings.HasSuffix(word, "ed") && !strings.HasSuffix(word, "ted") && !strings.HasSuffix(word, "ded"):
+		count-- // Silent e of -ed
+	}
+	return max(count, 1)
+}
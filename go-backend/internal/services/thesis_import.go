@@ -0,0 +1,325 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrImportFileType   = errors.New("theses can only be imported from DOCX files")
+	ErrImportUnreadable = errors.New("the document could not be read")
+	ErrImportNoChapters = errors.New("no chapters found; chapter titles must use Word's heading styles and name a chapter such as Introduction or Methodology")
+)
+
+var thesisImportRule = uploadRule{Accept: []string{mimeDOCX}, Unsupported: ErrImportFileType}
+
+// outlineBlock is a paragraph of a DOCX as the docgen service's /extract-outline reads it
+type outlineBlock struct {
+	Text  string `json:"text"`
+	Level int    `json:"level"` // Heading level, 0 for body text
+}
+
+type pythonOutlineResponse struct {
+	Title  string         `json:"title"`
+	Blocks []outlineBlock `json:"blocks"`
+}
+
+// ThesisImport is a project created from an existing thesis
+type ThesisImport struct {
+	Project    sqlc.ResearchProject
+	Chapters   []sqlc.Chapter
+	References []sqlc.Reference
+	Skipped    []string // Headings of sections that fit no chapter, such as appendices
+}
+
+// ImportDOCX creates a project from a half-written thesis: the document is split into
+// chapters at its top-level headings and its bibliography becomes the project's
+// references. Project fields left empty in req are taken from the document.
+func (s *ResearchService) ImportDOCX(ctx context.Context, userID uuid.UUID, fileName string, r io.Reader, req apimodels.ImportThesisRequest) (ThesisImport, error) {
+	maxSize := s.maxSize(thesisImportRule)
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return ThesisImport{}, fmt.Errorf("could not read upload: %w", err)
+	}
+	if _, err := s.checkUpload(bytes.NewReader(data), int64(len(data)), thesisImportRule); err != nil {
+		return ThesisImport{}, err
+	}
+	var extracted pythonOutlineResponse
+	if err := s.docgenExtract(ctx, "/extract-outline", bytes.NewReader(data), int64(len(data)), mimeDOCX, &extracted); err != nil {
+		if errors.Is(err, errUnreadableFile) {
+			return ThesisImport{}, fmt.Errorf("%w: %v", ErrImportUnreadable, err)
+		}
+		return ThesisImport{}, err
+	}
+	outline := splitThesis(extracted.Blocks)
+	refs := make([]sqlc.CreateReferenceParams, len(outline.Bibliography))
+	for i, entry := range outline.Bibliography {
+		refs[i] = parseBibliographyEntry(entry)
+	}
+	return s.importThesis(ctx, userID, fileName, extracted.Title, outline, refs, req)
+}
+
+// importThesis creates the project of an imported thesis with its chapters and
+// references. Project fields left empty in req come from the document: its title,
+// else its file name, and its abstract as the description.
+func (s *ResearchService) importThesis(ctx context.Context, userID uuid.UUID, fileName, title string, outline thesisOutline, refs []sqlc.CreateReferenceParams, req apimodels.ImportThesisRequest) (ThesisImport, error) {
+	if len(outline.Chapters) == 0 {
+		return ThesisImport{}, ErrImportNoChapters
+	}
+	project := apimodels.CreateProjectRequest{
+		Title:          req.Title,
+		Specialization: req.Specialization,
+		University:     req.University,
+		Description:    outline.Abstract,
+		Language:       req.Language,
+	}
+	if project.Title == "" {
+		project.Title = title
+	}
+	if project.Title == "" {
+		name := cleanFileName(fileName, "Imported thesis")
+		project.Title = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	project.Title = truncateRunes(project.Title, 497) // Ellipsis included
+	if project.Specialization == "" {
+		project.Specialization = "General"
+	}
+	created, err := s.CreateProject(ctx, userID, project)
+	if err != nil {
+		return ThesisImport{}, err
+	}
+
+	result := ThesisImport{Project: created, Skipped: outline.Skipped}
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		for _, ch := range outline.Chapters {
+			content, wordCount := chapterContent(strings.Join(ch.Paragraphs, "\n\n"))
+			chapter, err := q.CreateChapter(ctx, sqlc.CreateChapterParams{
+				ProjectID: created.ID,
+				Type:      ch.Type,
+				Title:     truncateRunes(ch.Title, 297),
+				Content:   content,
+				WordCount: wordCount,
+			})
+			if err != nil {
+				return fmt.Errorf("could not create chapter: %w", err)
+			}
+			result.Chapters = append(result.Chapters, chapter)
+		}
+		index, err := projectReferenceIndex(ctx, q, created.ID)
+		if err != nil {
+			return err
+		}
+		for _, params := range refs {
+			params.ProjectID = created.ID
+			ref, merged, err := saveReference(ctx, q, index, params)
+			if err != nil {
+				return err
+			}
+			if !merged { // The bibliography listed it twice
+				result.References = append(result.References, ref)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to import thesis, removing its project", "projectID", created.ID, "error", err)
+		if delErr := s.DeleteProject(context.WithoutCancel(ctx), created.ID.Bytes, userID); delErr == nil {
+			s.quotas.Release(context.WithoutCancel(ctx), userID, QuotaProjects, 1)
+		}
+		return ThesisImport{}, err
+	}
+	for _, chapter := range result.Chapters {
+		s.trackChapterSave(ctx, chapter, ProvenanceHuman)
+	}
+	s.embedReferences(ctx, result.References)
+	s.logger.Info("Thesis imported", "projectID", created.ID, "chapters", len(result.Chapters), "references", len(result.References), "skipped", len(result.Skipped))
+	return result, nil
+}
+
+// thesisOutline is a thesis split into what a project holds
+type thesisOutline struct {
+	Abstract     string
+	Chapters     []importedChapter
+	Bibliography []string
+	Skipped      []string
+}
+
+type importedChapter struct {
+	Type, Title string
+	Paragraphs  []string
+}
+
+// Kinds of top-level section besides chapters
+const (
+	sectionAbstract     = "abstract"
+	sectionBibliography = "bibliography"
+)
+
+// sectionKeywords classifies a heading by the first keywords it contains. Conclusion is
+// checked before results so "Discussion and Conclusions" ends the thesis.
+var sectionKeywords = []struct {
+	kind     string
+	keywords []string
+}{
+	{sectionAbstract, []string{"abstract"}},
+	{sectionBibliography, []string{"references", "bibliography", "works cited", "literature cited", "reference list"}},
+	{"conclusion", []string{"conclusion", "concluding", "recommendation"}},
+	{"literature_review", []string{"literature", "related work", "theoretical framework", "review of"}},
+	{"methodology", []string{"method", "research design", "materials and"}},
+	{"results", []string{"result", "finding", "analysis", "discussion"}},
+	{"introduction", []string{"introduction", "background"}},
+}
+
+// chapterNumbering is a heading that only numbers a chapter, "Chapter One" or "CHAPTER 2",
+// whose name some templates put in the next paragraph
+var chapterNumbering = regexp.MustCompile(`(?i)^chapter\s+[\w-]+[.:]?$`)
+
+// classifySection returns the kind of section a heading starts, or "" for one that
+// fits no chapter
+func classifySection(heading string) string {
+	heading = strings.ToLower(heading)
+	for _, section := range sectionKeywords {
+		for _, keyword := range section.keywords {
+			if strings.Contains(heading, keyword) {
+				return section.kind
+			}
+		}
+	}
+	return ""
+}
+
+// splitThesis splits an outline into chapters at its top-level headings. Deeper headings
+// stay in their chapter as Markdown headings, text before the first heading (the title
+// page, declarations and the like) is dropped, and a second section of a chapter type
+// is appended to the first.
+func splitThesis(blocks []outlineBlock) thesisOutline {
+	top := 0
+	for _, b := range blocks {
+		if b.Level > 0 && (top == 0 || b.Level < top) {
+			top = b.Level
+		}
+	}
+	var out thesisOutline
+	var abstract []string
+	kind, current := "", -1 // The section being read; current indexes out.Chapters
+	for i := 0; i < len(blocks); i++ {
+		b := blocks[i]
+		switch {
+		case top > 0 && b.Level == top:
+			title := b.Text
+			kind = classifySection(title)
+			// "Chapter One" with the name in the next paragraph
+			if kind == "" && chapterNumbering.MatchString(title) && i+1 < len(blocks) && len(strings.Fields(blocks[i+1].Text)) <= 10 {
+				if next := classifySection(blocks[i+1].Text); next != "" {
+					kind, title = next, title+": "+blocks[i+1].Text
+					i++
+				}
+			}
+			current = -1
+			switch kind {
+			case "":
+				out.Skipped = append(out.Skipped, title)
+			case sectionAbstract, sectionBibliography:
+			default:
+				for j, ch := range out.Chapters {
+					if ch.Type == kind {
+						current = j
+						out.Chapters[j].Paragraphs = append(out.Chapters[j].Paragraphs, "## "+title)
+					}
+				}
+				if current < 0 {
+					out.Chapters = append(out.Chapters, importedChapter{Type: kind, Title: title})
+					current = len(out.Chapters) - 1
+				}
+			}
+		case b.Level > top:
+			if current >= 0 {
+				heading := strings.Repeat("#", min(b.Level-top+1, 6)) + " " + b.Text
+				out.Chapters[current].Paragraphs = append(out.Chapters[current].Paragraphs, heading)
+			}
+		case current >= 0:
+			out.Chapters[current].Paragraphs = append(out.Chapters[current].Paragraphs, b.Text)
+		case kind == sectionAbstract:
+			abstract = append(abstract, b.Text)
+		case kind == sectionBibliography:
+			out.Bibliography = append(out.Bibliography, b.Text)
+		}
+	}
+	out.Abstract = strings.Join(abstract, "\n\n")
+	return out
+}
+
+var (
+	bibNumbering   = regexp.MustCompile(`^(\[\d+\]|\d+\.)\s*`)
+	bibAPAYear     = regexp.MustCompile(`\((\d{4})[a-z]?\)`)
+	bibYear        = regexp.MustCompile(`\b(1[89]|20)\d{2}\b`)
+	bibQuoted      = regexp.MustCompile(`["“]([^"”]+)["”]`)
+	bibDOI         = regexp.MustCompile(`\b10\.\d{4,9}/\S+`)
+	bibURL         = regexp.MustCompile(`https?://\S+`)
+	bibSentenceEnd = regexp.MustCompile(`[.?!]\s`)
+)
+
+// parseBibliographyEntry reads what it can from a formatted reference. APA entries,
+// "Authors (Year). Title. Journal, ...", are read in full and keep the entry as their
+// APA citation; for other styles the authors, a quoted title and the year are looked
+// for, and the whole entry is the title when nothing better is found.
+func parseBibliographyEntry(entry string) sqlc.CreateReferenceParams {
+	entry = strings.TrimSpace(bibNumbering.ReplaceAllString(strings.TrimSpace(entry), ""))
+	var params sqlc.CreateReferenceParams
+	if doi := bibDOI.FindString(entry); doi != "" {
+		params.Doi = pgtype.Text{String: strings.TrimRight(doi, ".,;"), Valid: true}
+	}
+	if url := bibURL.FindString(entry); url != "" {
+		params.Url = pgtype.Text{String: strings.TrimRight(url, ".,;"), Valid: true}
+	}
+
+	var authors, rest string
+	if loc := bibAPAYear.FindStringSubmatchIndex(entry); loc != nil && loc[0] > 0 {
+		year, _ := strconv.Atoi(entry[loc[2]:loc[3]])
+		params.PublicationYear = pgtype.Int4{Int32: int32(year), Valid: true}
+		params.CitationApa = pgtype.Text{String: entry, Valid: true}
+		authors = entry[:loc[0]]
+		rest = strings.TrimLeft(entry[loc[1]:], ". ")
+	} else {
+		if year := bibYear.FindString(entry); year != "" {
+			n, _ := strconv.Atoi(year)
+			params.PublicationYear = pgtype.Int4{Int32: int32(n), Valid: true}
+		}
+		if m := bibQuoted.FindStringSubmatchIndex(entry); m != nil {
+			params.Title = strings.TrimRight(strings.TrimSpace(entry[m[2]:m[3]]), ".,")
+			authors = entry[:m[0]]
+		} else if loc := bibSentenceEnd.FindStringIndex(entry); loc != nil {
+			authors, rest = entry[:loc[0]], entry[loc[1]:]
+		}
+	}
+	if authors = strings.TrimRight(strings.TrimSpace(authors), ","); authors != "" {
+		params.Authors = pgtype.Text{String: authors, Valid: true}
+	}
+	if params.Title == "" && rest != "" {
+		title, after := rest, ""
+		if loc := bibSentenceEnd.FindStringIndex(rest); loc != nil {
+			title, after = rest[:loc[0]+1], rest[loc[1]:]
+		}
+		params.Title = strings.TrimRight(strings.TrimSpace(title), ".")
+		if journal, _, _ := strings.Cut(after, ","); params.CitationApa.Valid && journal != "" && !bibURL.MatchString(journal) && !bibDOI.MatchString(journal) {
+			params.Journal = pgtype.Text{String: truncateRunes(strings.TrimSpace(journal), 297), Valid: true}
+		}
+	}
+	if params.Title == "" {
+		params.Title = entry
+	}
+	return params
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrTimelineNotFound = errors.New("the project has no timeline")
+	ErrInvalidTimeline  = errors.New("invalid timeline dates")
+)
+
+// maxTimelineTasks bounds the tasks kept from a generated plan
+const maxTimelineTasks = 60
+
+// GenerateTimeline plans a research timeline from the project's chapters and the
+// given milestones and dates it between the start date and the deadline, replacing
+// the project's previous timeline. Requires the edit role.
+func (s *ResearchService) GenerateTimeline(ctx context.Context, projectID, userID uuid.UUID, req apimodels.GenerateTimelineRequest) (sqlc.ProjectTimeline, error) {
+	ctx, cancel := s.generationContext(ctx)
+	defer cancel()
+	project, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit)
+	if err != nil {
+		return sqlc.ProjectTimeline{}, err
+	}
+	start, _ := time.Parse(time.DateOnly, req.StartDate) // Checked by request binding
+	end, _ := time.Parse(time.DateOnly, req.EndDate)
+	if !end.After(start) {
+		return sqlc.ProjectTimeline{}, fmt.Errorf("%w: end_date must be after start_date", ErrInvalidTimeline)
+	}
+	milestones := slices.Clone(req.Milestones)
+	for _, m := range milestones {
+		if m.Date < req.StartDate || m.Date > req.EndDate {
+			return sqlc.ProjectTimeline{}, fmt.Errorf("%w: milestone %q is outside the timeline", ErrInvalidTimeline, m.Title)
+		}
+	}
+	slices.SortStableFunc(milestones, func(a, b apimodels.TimelineMilestone) int { return strings.Compare(a.Date, b.Date) })
+	if milestones == nil {
+		milestones = []apimodels.TimelineMilestone{}
+	}
+
+	chapters, err := s.store.GetChaptersByProjectID(ctx, project.ID)
+	if err != nil {
+		return sqlc.ProjectTimeline{}, fmt.Errorf("database error fetching chapters: %w", err)
+	}
+	input := TimelineInput{
+		Title:          project.Title,
+		Specialization: project.Specialization,
+		StartDate:      start,
+		EndDate:        end,
+		Milestones:     milestones,
+	}
+	for _, ch := range chapters {
+		input.Chapters = append(input.Chapters, TimelineChapter{
+			Type:        ch.Type,
+			Title:       ch.Title,
+			Status:      ch.Status.String,
+			WordCount:   ch.WordCount.Int32,
+			TargetWords: ch.TargetWordCount.Int32,
+		})
+	}
+	ctx = s.withGenerationLog(ctx, GenerationKindTimeline, userID, &projectID, nil)
+	ctx = s.quotas.withAIWords(ctx, project.UserID.Bytes)
+	tasks, err := s.aiService.GenerateTimelineTasks(ctx, input)
+	if err != nil {
+		s.logger.Error("AI timeline generation failed", "projectID", projectID, "error", err)
+		return sqlc.ProjectTimeline{}, fmt.Errorf("AI generation failed: %w", err)
+	}
+	tasks = scheduleTimeline(tasks, input.Chapters, start, end)
+	if len(tasks) == 0 {
+		return sqlc.ProjectTimeline{}, errors.New("AI generation failed: the plan has no tasks")
+	}
+
+	encodedTasks, err := json.Marshal(tasks)
+	if err != nil {
+		return sqlc.ProjectTimeline{}, err
+	}
+	encodedMilestones, err := json.Marshal(milestones)
+	if err != nil {
+		return sqlc.ProjectTimeline{}, err
+	}
+	timeline, err := s.store.UpsertProjectTimeline(ctx, sqlc.UpsertProjectTimelineParams{
+		ProjectID:         project.ID,
+		StartDate:         pgtype.Date{Time: start, Valid: true},
+		EndDate:           pgtype.Date{Time: end, Valid: true},
+		Milestones:        encodedMilestones,
+		Tasks:             encodedTasks,
+		IncludeInDocument: req.IncludeInDocument,
+		GeneratedBy:       pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return sqlc.ProjectTimeline{}, fmt.Errorf("could not save timeline: %w", err)
+	}
+	s.logger.Info("Research timeline generated", "projectID", projectID, "tasks", len(tasks), "userID", userID)
+	return timeline, nil
+}
+
+// GetTimeline returns the project's research timeline
+func (s *ResearchService) GetTimeline(ctx context.Context, projectID, userID uuid.UUID) (sqlc.ProjectTimeline, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return sqlc.ProjectTimeline{}, err
+	}
+	timeline, err := s.store.GetProjectTimeline(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectTimeline{}, ErrTimelineNotFound
+		}
+		return sqlc.ProjectTimeline{}, fmt.Errorf("database error fetching timeline: %w", err)
+	}
+	return timeline, nil
+}
+
+// SetTimelineInDocument sets whether generated documents include the project's
+// timeline. Requires the edit role.
+func (s *ResearchService) SetTimelineInDocument(ctx context.Context, projectID, userID uuid.UUID, include bool) (sqlc.ProjectTimeline, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.ProjectTimeline{}, err
+	}
+	timeline, err := s.store.SetTimelineInDocument(ctx, sqlc.SetTimelineInDocumentParams{
+		IncludeInDocument: include,
+		ProjectID:         pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectTimeline{}, ErrTimelineNotFound
+		}
+		return sqlc.ProjectTimeline{}, fmt.Errorf("could not update timeline: %w", err)
+	}
+	return timeline, nil
+}
+
+// DeleteTimeline removes the project's timeline. Requires the edit role.
+func (s *ResearchService) DeleteTimeline(ctx context.Context, projectID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	if _, err := s.store.DeleteProjectTimeline(ctx, pgtype.UUID{Bytes: projectID, Valid: true}); err != nil {
+		if isNoRows(err) {
+			return ErrTimelineNotFound
+		}
+		return fmt.Errorf("could not delete timeline: %w", err)
+	}
+	s.logger.Info("Research timeline deleted", "projectID", projectID, "userID", userID)
+	return nil
+}
+
+// scheduleTimeline cleans up a generated plan and dates its tasks: each starts when the
+// last of its dependencies ends. A plan longer than the time available is compressed
+// to fit, keeping every task at least a day. Tasks come back in order of start date.
+func scheduleTimeline(tasks []apimodels.TimelineTask, chapters []TimelineChapter, start, end time.Time) []apimodels.TimelineTask {
+	chapterTypes := make(map[string]bool, len(chapters))
+	for _, ch := range chapters {
+		chapterTypes[ch.Type] = true
+	}
+	index := make(map[string]int, len(tasks))
+	plan := make([]apimodels.TimelineTask, 0, min(len(tasks), maxTimelineTasks))
+	for _, t := range tasks {
+		if len(plan) == maxTimelineTasks {
+			break
+		}
+		t.Title = strings.TrimSpace(t.Title)
+		if t.Title == "" {
+			continue
+		}
+		t.ID = strings.TrimSpace(t.ID)
+		if _, taken := index[t.ID]; t.ID == "" || taken {
+			t.ID = fmt.Sprintf("task-%d", len(plan)+1)
+		}
+		if !chapterTypes[t.ChapterType] {
+			t.ChapterType = ""
+		}
+		t.DurationDays = max(t.DurationDays, 1)
+		index[t.ID] = len(plan)
+		plan = append(plan, t)
+	}
+	for i := range plan {
+		deps := make([]string, 0, len(plan[i].DependsOn))
+		for _, d := range plan[i].DependsOn {
+			if _, ok := index[d]; ok && d != plan[i].ID && !slices.Contains(deps, d) {
+				deps = append(deps, d)
+			}
+		}
+		plan[i].DependsOn = deps
+	}
+	order := dependencyOrder(plan, index)
+
+	available := int(end.Sub(start).Hours()/24) + 1
+	offsets := make([]int, len(plan))
+	for pass := 0; pass < 2; pass++ {
+		total := 0
+		for _, i := range order {
+			offsets[i] = 0
+			for _, d := range plan[i].DependsOn {
+				dep := index[d]
+				offsets[i] = max(offsets[i], offsets[dep]+plan[dep].DurationDays)
+			}
+			total = max(total, offsets[i]+plan[i].DurationDays)
+		}
+		if total <= available || pass == 1 {
+			break
+		}
+		for i := range plan {
+			plan[i].DurationDays = max(1, plan[i].DurationDays*available/total)
+		}
+	}
+
+	for _, i := range order {
+		plan[i].StartDate = start.AddDate(0, 0, offsets[i]).Format(time.DateOnly)
+		plan[i].EndDate = start.AddDate(0, 0, offsets[i]+plan[i].DurationDays-1).Format(time.DateOnly)
+	}
+	scheduled := make([]apimodels.TimelineTask, len(order))
+	for n, i := range order {
+		scheduled[n] = plan[i]
+	}
+	slices.SortStableFunc(scheduled, func(a, b apimodels.TimelineTask) int { return strings.Compare(a.StartDate, b.StartDate) })
+	return scheduled
+}
+
+// dependencyOrder orders tasks so each follows its dependencies. Tasks caught in a
+// dependency cycle lose their remaining dependencies and keep the plan's order.
+func dependencyOrder(plan []apimodels.TimelineTask, index map[string]int) []int {
+	pending := make([]int, len(plan))
+	dependents := make([][]int, len(plan))
+	for i, t := range plan {
+		pending[i] = len(t.DependsOn)
+		for _, d := range t.DependsOn {
+			dependents[index[d]] = append(dependents[index[d]], i)
+		}
+	}
+	order := make([]int, 0, len(plan))
+	done := make([]bool, len(plan))
+	for len(order) < len(plan) {
+		progressed := false
+		for i := range plan {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+			done[i], progressed = true, true
+			order = append(order, i)
+			for _, j := range dependents[i] {
+				pending[j]--
+			}
+		}
+		if progressed {
+			continue
+		}
+		// A cycle: release its first task from the dependencies not yet met
+		for i := range plan {
+			if done[i] {
+				continue
+			}
+			met := plan[i].DependsOn[:0]
+			for _, d := range plan[i].DependsOn {
+				if done[index[d]] {
+					met = append(met, d)
+				}
+			}
+			plan[i].DependsOn = met
+			pending[i] = 0
+			break
+		}
+	}
+	return order
+}
+
+// PythonTimelineData is a research timeline as the docgen service renders it, a Gantt
+// chart by month after the chapters
+type PythonTimelineData struct {
+	StartDate  string                        `json:"start_date"`
+	EndDate    string                        `json:"end_date"`
+	Tasks      []apimodels.TimelineTask      `json:"tasks"`
+	Milestones []apimodels.TimelineMilestone `json:"milestones"`
+}
+
+// pythonTimeline loads the project's timeline for document generation, or nil when the
+// project has none or keeps it out of its documents
+func (s *ResearchService) pythonTimeline(ctx context.Context, projectID uuid.UUID) (*PythonTimelineData, error) {
+	timeline, err := s.store.GetProjectTimeline(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		if isNoRows(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch timeline for doc gen: %w", err)
+	}
+	if !timeline.IncludeInDocument {
+		return nil, nil
+	}
+	resp := apimodels.ToTimelineResponse(timeline)
+	return &PythonTimelineData{
+		StartDate:  resp.StartDate,
+		EndDate:    resp.EndDate,
+		Tasks:      resp.Tasks,
+		Milestones: resp.Milestones,
+	}, nil
+}
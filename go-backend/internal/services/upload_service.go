@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrUploadTooLarge = errors.New("file is larger than the upload limit")
+	ErrUploadNotPDF   = errors.New("only PDF files can be uploaded")
+	ErrUploadExists   = errors.New("this file has already been uploaded to the project")
+)
+
+// Text extraction states of an upload
+const (
+	ExtractionPending   = "pending"
+	ExtractionCompleted = "completed"
+	ExtractionFailed    = "failed"
+)
+
+// JobExtractUploadText extracts the text of one upload through the docgen service
+const JobExtractUploadText = "uploads.extract_text"
+
+// UploadConfig says how large uploaded files may be. They are kept in storage; Dir
+// holds those uploaded before, one subdirectory per project.
+type UploadConfig struct {
+	Dir         string
+	MaxSize     int64 // Bytes
+	MaxPDFPages int   // 0 is no limit
+
+	// Resumable uploads of source PDFs, sent in chunks of up to ChunkMaxSize bytes
+	ResumableMaxSize int64 // Bytes
+	ChunkMaxSize     int64 // Bytes
+	SessionTTL       time.Duration
+
+	PaperContextTokens int // Cap on imported papers' full-text passages per literature review prompt; 0 leaves them out
+}
+
+// Source material budgets for generation prompts, in characters
+const (
+	maxSourceChars      = 4000  // Per upload; the opening pages carry the abstract and introduction
+	maxTotalSourceChars = 16000 // Across a project's uploads
+)
+
+// UploadPDF stores a source PDF for a project and queues its text extraction.
+// Requires the edit role.
+func (s *ResearchService) UploadPDF(ctx context.Context, projectID, userID uuid.UUID, fileName string, r io.Reader) (sqlc.ProjectUpload, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.ProjectUpload{}, err
+	}
+	return s.createUpload(ctx, projectID, userID, cleanFileName(fileName, "upload.pdf"), r, sourceUploadRule, "")
+}
+
+// createUpload stores a source PDF passing rule and queues its text extraction. With
+// wantSHA256 set, a file that hashes differently is turned away before it is recorded.
+func (s *ResearchService) createUpload(ctx context.Context, projectID, userID uuid.UUID, fileName string, r io.Reader, rule uploadRule, wantSHA256 string) (sqlc.ProjectUpload, error) {
+	uploadID := uuid.New()
+	filePath := projectFileKey(projectID, "uploads", uploadID.String()+".pdf")
+	stored, err := s.writeUpload(ctx, filePath, r, rule)
+	if err != nil {
+		return sqlc.ProjectUpload{}, err
+	}
+	if wantSHA256 != "" && stored.SHA256 != wantSHA256 {
+		s.removeUploadFile(ctx, filePath)
+		return sqlc.ProjectUpload{}, ErrUploadChecksumMismatch
+	}
+
+	upload, err := s.store.CreateProjectUpload(ctx, sqlc.CreateProjectUploadParams{
+		ID:         pgtype.UUID{Bytes: uploadID, Valid: true},
+		ProjectID:  pgtype.UUID{Bytes: projectID, Valid: true},
+		UploadedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		FileName:   fileName,
+		FilePath:   filePath,
+		FileSize:   stored.Size,
+		Sha256:     stored.SHA256,
+	})
+	if err != nil {
+		s.removeUploadFile(ctx, filePath)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return sqlc.ProjectUpload{}, ErrUploadExists
+		}
+		return sqlc.ProjectUpload{}, fmt.Errorf("could not save upload: %w", err)
+	}
+
+	if err := s.jobs.Enqueue(ctx, JobExtractUploadText, uploadJob{UploadID: uploadID}); err != nil {
+		s.setUploadExtraction(ctx, uploadID, ExtractionFailed, "Could not queue text extraction")
+		return sqlc.ProjectUpload{}, fmt.Errorf("could not queue text extraction: %w", err)
+	}
+	s.logger.Info("Source PDF uploaded", "projectID", projectID, "uploadID", uploadID, "size", stored.Size)
+	return upload, nil
+}
+
+// MIME types of the files that can be uploaded
+const (
+	mimePDF  = "application/pdf"
+	mimeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	mimePNG  = "image/png"
+	mimeJPEG = "image/jpeg"
+	mimeCSV  = "text/csv"
+
+	// Detected, never accepted
+	mimeZIP         = "application/zip"
+	mimeOctetStream = "application/octet-stream"
+)
+
+// errUnsupportedFileType is the cause of an UploadError for content of a type not accepted
+var errUnsupportedFileType = errors.New("unsupported file type")
+
+// storedFile describes a file written by writeUpload
+type storedFile struct {
+	Size     int64
+	SHA256   string
+	MimeType string
+}
+
+// writeUpload stores r under key once checkUpload has passed it. The file is spooled to
+// disk first, for the checks and because object stores need its size up front. Nothing
+// is left behind on error.
+func (s *ResearchService) writeUpload(ctx context.Context, key string, r io.Reader, rule uploadRule) (storedFile, error) {
+	f, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return storedFile{}, fmt.Errorf("could not create upload file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	hash := sha256.New()
+	// One byte past the limit tells a file of exactly MaxSize from a larger one
+	size, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, s.maxSize(rule)+1))
+	if err != nil {
+		return storedFile{}, fmt.Errorf("could not write upload: %w", err)
+	}
+	mimeType, err := s.checkUpload(f, size, rule)
+	if err != nil {
+		return storedFile{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return storedFile{}, fmt.Errorf("could not write upload: %w", err)
+	}
+	if err := s.files.Put(ctx, key, f, size, mimeType); err != nil {
+		return storedFile{}, fmt.Errorf("could not store upload: %w", err)
+	}
+	return storedFile{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil)), MimeType: mimeType}, nil
+}
+
+// cleanFileName keeps the base name of an uploaded file; browsers may send a full client path
+func cleanFileName(name, fallback string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		return fallback
+	}
+	return name
+}
+
+// ListUploads returns a project's uploads, newest first
+func (s *ResearchService) ListUploads(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.ProjectUpload, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return nil, err
+	}
+	uploads, err := s.store.ListProjectUploads(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching uploads: %w", err)
+	}
+	return uploads, nil
+}
+
+// GetUpload returns an upload and its extracted text, which is empty until extraction completes
+func (s *ResearchService) GetUpload(ctx context.Context, uploadID, projectID, userID uuid.UUID) (sqlc.ProjectUpload, string, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleRead); err != nil {
+		return sqlc.ProjectUpload{}, "", err
+	}
+	upload, err := s.store.GetProjectUpload(ctx, sqlc.GetProjectUploadParams{
+		ID:        pgtype.UUID{Bytes: uploadID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.ProjectUpload{}, "", ErrUploadNotFound
+		}
+		return sqlc.ProjectUpload{}, "", fmt.Errorf("database error fetching upload: %w", err)
+	}
+	if upload.ExtractionStatus != ExtractionCompleted {
+		return upload, "", nil
+	}
+	text, err := s.store.GetUploadText(ctx, upload.ID)
+	if err != nil && !isNoRows(err) {
+		return sqlc.ProjectUpload{}, "", fmt.Errorf("database error fetching upload text: %w", err)
+	}
+	return upload, text, nil
+}
+
+// DeleteUpload removes an upload, its text and its file. Requires the edit role.
+func (s *ResearchService) DeleteUpload(ctx context.Context, uploadID, projectID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	upload, err := s.store.DeleteProjectUpload(ctx, sqlc.DeleteProjectUploadParams{
+		ID:        pgtype.UUID{Bytes: uploadID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return ErrUploadNotFound
+		}
+		return fmt.Errorf("could not delete upload: %w", err)
+	}
+	s.removeUploadFile(ctx, upload.FilePath)
+	s.logger.Info("Upload deleted", "projectID", projectID, "uploadID", uploadID)
+	return nil
+}
+
+// uploadJob is the payload of JobExtractUploadText
+type uploadJob struct {
+	UploadID uuid.UUID `json:"upload_id"`
+}
+
+// pythonExtractionResponse matches the docgen service's /extract-text response
+type pythonExtractionResponse struct {
+	Text      string `json:"text"`
+	PageCount int32  `json:"page_count"`
+}
+
+// runExtractTextJob extracts an upload's text. Files the service cannot read fail for good;
+// other errors are retried and only mark the upload failed on the last attempt.
+func (s *ResearchService) runExtractTextJob(ctx context.Context, job jobs.Job) error {
+	var payload uploadJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	upload, err := s.store.GetUpload(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.UploadID, Valid: true})
+	if err != nil {
+		if isNoRows(err) { // Deleted before extraction ran
+			return nil
+		}
+		return fmt.Errorf("could not load upload: %w", err)
+	}
+
+	extracted, err := s.extractText(ctx, upload.FilePath, mimePDF)
+	if err != nil {
+		if errors.Is(err, errUnreadableFile) || job.LastAttempt() {
+			s.setUploadExtraction(context.WithoutCancel(ctx), payload.UploadID, ExtractionFailed, err.Error())
+		}
+		if errors.Is(err, errUnreadableFile) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.SaveUploadText(ctx, sqlc.SaveUploadTextParams{UploadID: upload.ID, Content: extracted.Text}); err != nil {
+			return err
+		}
+		return q.SetUploadExtraction(ctx, sqlc.SetUploadExtractionParams{
+			ExtractionStatus: ExtractionCompleted,
+			PageCount:        pgtype.Int4{Int32: extracted.PageCount, Valid: true},
+			ID:               upload.ID,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("could not save extracted text: %w", err)
+	}
+	s.logger.Info("Upload text extracted", "uploadID", payload.UploadID, "pages", extracted.PageCount, "chars", len(extracted.Text))
+	return nil
+}
+
+// errUnreadableFile is returned when the docgen service rejects a file it cannot parse
+var errUnreadableFile = errors.New("file could not be read")
+
+// extractText sends a stored PDF or DOCX file to the docgen service's /extract-text endpoint
+func (s *ResearchService) extractText(ctx context.Context, ref, mimeType string) (pythonExtractionResponse, error) {
+	f, err := s.OpenFile(ctx, ref)
+	if err != nil {
+		return pythonExtractionResponse{}, fmt.Errorf("could not open upload file: %w", err)
+	}
+	defer f.Close()
+
+	var extracted pythonExtractionResponse
+	if err := s.docgenExtract(ctx, "/extract-text", f, f.Size, mimeType, &extracted); err != nil {
+		return pythonExtractionResponse{}, err
+	}
+	// Catches PDFs whose page count checkUpload could not read
+	if mimeType == mimePDF && s.uploads.MaxPDFPages > 0 && int(extracted.PageCount) > s.uploads.MaxPDFPages {
+		return pythonExtractionResponse{}, fmt.Errorf("%w: %v", errUnreadableFile, s.tooManyPages(int(extracted.PageCount)))
+	}
+	return extracted, nil
+}
+
+// docgenExtract posts a file of size bytes, 0 if unknown, to one of the docgen service's
+// extraction endpoints and decodes the answer into out. A file the service cannot parse
+// fails with errUnreadableFile.
+func (s *ResearchService) docgenExtract(ctx context.Context, endpoint string, body io.Reader, size int64, mimeType string, out any) error {
+	httpClient := s.docgenClient(s.docgen.Timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.docgen.URL+endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to build extraction request: %w", err)
+	}
+	if size > 0 {
+		req.ContentLength = size
+	}
+	req.Header.Set("Content-Type", mimeType)
+	s.setDocGenAuth(req)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("docgen extraction call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		var detail struct {
+			Detail string `json:"detail"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&detail)
+		return fmt.Errorf("%w: %s", errUnreadableFile, detail.Detail)
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("docgen extraction returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("docgen extraction decode error: %w", err)
+	}
+	return nil
+}
+
+// setUploadExtraction records an extraction that did not complete
+func (s *ResearchService) setUploadExtraction(ctx context.Context, uploadID uuid.UUID, status, message string) {
+	err := s.store.SetUploadExtraction(ctx, sqlc.SetUploadExtractionParams{
+		ExtractionStatus: status,
+		ExtractionError:  message,
+		ID:               pgtype.UUID{Bytes: uploadID, Valid: true},
+	})
+	if err != nil {
+		s.logger.Error("Failed to update upload extraction status", "uploadID", uploadID, "status", status, "error", err)
+	}
+}
+
+// projectSources returns the extracted text of a project's uploads, trimmed to the
+// prompt budget. A failure is logged and generation carries on without sources.
+func (s *ResearchService) projectSources(ctx context.Context, projectID uuid.UUID) []SourceMaterial {
+	texts, err := s.store.ListProjectSourceTexts(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to load project source texts", "projectID", projectID, "error", err)
+		return nil
+	}
+	var sources []SourceMaterial
+	budget := maxTotalSourceChars
+	for _, t := range texts {
+		text := strings.TrimSpace(t.Content)
+		if text == "" { // Scanned PDFs without a text layer
+			continue
+		}
+		limit := min(maxSourceChars, budget)
+		if limit <= 0 {
+			break
+		}
+		if runes := []rune(text); len(runes) > limit {
+			text = string(runes[:limit]) + "..."
+		}
+		budget -= utf8.RuneCountInString(text)
+		sources = append(sources, SourceMaterial{Name: t.FileName, Text: text})
+	}
+	return sources
+}
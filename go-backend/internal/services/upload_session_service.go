@@ -0,0 +1,380 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrUploadSessionNotFound  = errors.New("upload session not found")
+	ErrUploadSessionClosed    = errors.New("upload session is no longer accepting chunks")
+	ErrUploadOffsetMismatch   = errors.New("chunk offset does not match the bytes received so far")
+	ErrUploadChunkTooLarge    = errors.New("chunk is larger than the chunk size limit")
+	ErrUploadChunkPastEnd     = errors.New("chunk runs past the end of the file")
+	ErrUploadChunkIncomplete  = errors.New("chunk ended before its Content-Length")
+	ErrUploadChunkChecksum    = errors.New("chunk does not match its checksum")
+	ErrUploadChecksumMismatch = errors.New("assembled file does not match its SHA-256")
+)
+
+// States of an upload session
+const (
+	UploadSessionReceiving  = "receiving"
+	UploadSessionAssembling = "assembling"
+	UploadSessionCompleted  = "completed"
+	UploadSessionFailed     = "failed"
+)
+
+// Background job kinds of resumable uploads
+const (
+	JobAssembleUpload      = "uploads.assemble"
+	JobPurgeUploadSessions = "uploads.purge_sessions"
+)
+
+// CreateUploadSession starts a resumable upload of a source PDF of size bytes, which may
+// be up to the resumable upload limit. The whole file is checked against fileSHA256, a
+// hex digest, when one is given. Requires the edit role.
+func (s *ResearchService) CreateUploadSession(ctx context.Context, projectID, userID uuid.UUID, fileName string, size int64, fileSHA256 string) (sqlc.UploadSession, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.UploadSession{}, err
+	}
+	if size <= 0 {
+		return sqlc.UploadSession{}, &UploadError{Code: UploadCodeEmpty, cause: ErrUploadEmpty}
+	}
+	if size > s.uploads.ResumableMaxSize {
+		return sqlc.UploadSession{}, NewUploadTooLargeError(s.uploads.ResumableMaxSize)
+	}
+	session, err := s.store.CreateUploadSession(ctx, sqlc.CreateUploadSessionParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		FileName:  cleanFileName(fileName, "upload.pdf"),
+		TotalSize: size,
+		Sha256:    fileSHA256,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.uploads.SessionTTL), Valid: true},
+	})
+	if err != nil {
+		return sqlc.UploadSession{}, fmt.Errorf("could not create upload session: %w", err)
+	}
+	s.logger.Info("Upload session created", "projectID", projectID, "sessionID", uuid.UUID(session.ID.Bytes), "size", size)
+	return session, nil
+}
+
+// GetUploadSession returns one of the user's upload sessions, with how much has been
+// received to resume from. Requires the edit role.
+func (s *ResearchService) GetUploadSession(ctx context.Context, sessionID, projectID, userID uuid.UUID) (sqlc.UploadSession, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.UploadSession{}, err
+	}
+	return s.userUploadSession(ctx, sessionID, projectID, userID)
+}
+
+// userUploadSession loads a session of the project; other users' sessions are not found
+func (s *ResearchService) userUploadSession(ctx context.Context, sessionID, projectID, userID uuid.UUID) (sqlc.UploadSession, error) {
+	session, err := s.store.GetUploadSession(db.WithPrimary(ctx), sqlc.GetUploadSessionParams{
+		ID:        pgtype.UUID{Bytes: sessionID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.UploadSession{}, ErrUploadSessionNotFound
+		}
+		return sqlc.UploadSession{}, fmt.Errorf("database error fetching upload session: %w", err)
+	}
+	if session.UserID.Bytes != userID {
+		return sqlc.UploadSession{}, ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+// AppendUploadChunk stores the next size bytes of a session's file, read from r, which
+// must start where the bytes received so far end. A chunk that does not hash to
+// checksum, a SHA-256 digest, is turned away. The last chunk queues the file's assembly.
+// Requires the edit role.
+func (s *ResearchService) AppendUploadChunk(ctx context.Context, sessionID, projectID, userID uuid.UUID, offset, size int64, r io.Reader, checksum []byte) (sqlc.UploadSession, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return sqlc.UploadSession{}, err
+	}
+	session, err := s.userUploadSession(ctx, sessionID, projectID, userID)
+	if err != nil {
+		return sqlc.UploadSession{}, err
+	}
+	switch {
+	case session.Status != UploadSessionReceiving || session.ExpiresAt.Time.Before(time.Now()):
+		return sqlc.UploadSession{}, ErrUploadSessionClosed
+	case offset != session.ReceivedSize:
+		return sqlc.UploadSession{}, ErrUploadOffsetMismatch
+	case size > s.uploads.ChunkMaxSize:
+		return sqlc.UploadSession{}, ErrUploadChunkTooLarge
+	case offset+size > session.TotalSize:
+		return sqlc.UploadSession{}, ErrUploadChunkPastEnd
+	case size == 0:
+		return session, nil
+	}
+
+	// Spooled first: object stores need the size up front, and the checksum has to be
+	// known before anything is kept
+	f, err := os.CreateTemp("", "upload-chunk-*")
+	if err != nil {
+		return sqlc.UploadSession{}, fmt.Errorf("could not create chunk file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, size))
+	if err != nil || n != size {
+		return sqlc.UploadSession{}, ErrUploadChunkIncomplete
+	}
+	if checksum != nil && !bytes.Equal(hash.Sum(nil), checksum) {
+		return sqlc.UploadSession{}, ErrUploadChunkChecksum
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return sqlc.UploadSession{}, fmt.Errorf("could not read chunk file: %w", err)
+	}
+	// Keys are unique per attempt, so a chunk raced for the same offset never replaces
+	// the one that was recorded
+	key := projectFileKey(projectID, "upload-sessions", fmt.Sprintf("%s/%d-%s", sessionID, offset, uuid.New()))
+	if err := s.files.Put(ctx, key, f, size, mimeOctetStream); err != nil {
+		return sqlc.UploadSession{}, fmt.Errorf("could not store chunk: %w", err)
+	}
+
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.CreateUploadSessionChunk(ctx, sqlc.CreateUploadSessionChunkParams{
+			SessionID:  session.ID,
+			ByteOffset: offset,
+			Size:       size,
+			StorageKey: key,
+		}); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+				return ErrUploadOffsetMismatch
+			}
+			return fmt.Errorf("could not record chunk: %w", err)
+		}
+		session, err = q.AdvanceUploadSession(ctx, sqlc.AdvanceUploadSessionParams{
+			ChunkSize:    size,
+			ID:           session.ID,
+			ReceivedSize: offset,
+		})
+		if isNoRows(err) { // Another chunk got there first, or the session closed meanwhile
+			return ErrUploadOffsetMismatch
+		}
+		return err
+	})
+	if err != nil {
+		s.removeUploadFile(ctx, key)
+		return sqlc.UploadSession{}, err
+	}
+
+	if session.Status == UploadSessionAssembling {
+		if err := s.jobs.Enqueue(ctx, JobAssembleUpload, uploadSessionJob{SessionID: sessionID}); err != nil {
+			s.finishUploadSession(context.WithoutCancel(ctx), session, UploadSessionFailed, "Could not queue assembly", pgtype.UUID{})
+			return sqlc.UploadSession{}, fmt.Errorf("could not queue upload assembly: %w", err)
+		}
+		s.logger.Info("Upload session received in full", "projectID", projectID, "sessionID", sessionID, "size", session.TotalSize)
+	}
+	return session, nil
+}
+
+// DeleteUploadSession abandons an upload session and its chunks. A session being
+// assembled cannot be stopped. Requires the edit role.
+func (s *ResearchService) DeleteUploadSession(ctx context.Context, sessionID, projectID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleEdit); err != nil {
+		return err
+	}
+	session, err := s.userUploadSession(ctx, sessionID, projectID, userID)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	err = s.store.ExecTx(ctx, func(q *sqlc.Queries) error {
+		if keys, err = q.DeleteUploadSessionChunks(ctx, session.ID); err != nil {
+			return fmt.Errorf("could not delete chunks: %w", err)
+		}
+		if _, err := q.DeleteUploadSession(ctx, sqlc.DeleteUploadSessionParams{ID: session.ID, ProjectID: session.ProjectID}); err != nil {
+			if isNoRows(err) {
+				return ErrUploadSessionClosed
+			}
+			return fmt.Errorf("could not delete upload session: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		s.removeUploadFile(ctx, key)
+	}
+	s.logger.Info("Upload session deleted", "projectID", projectID, "sessionID", sessionID)
+	return nil
+}
+
+// uploadSessionJob is the payload of JobAssembleUpload
+type uploadSessionJob struct {
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+// runAssembleUploadJob joins a session's chunks into a source PDF upload, validated like
+// one sent whole but against the resumable upload limit. Files that fail validation or
+// their checksum fail the session for good; the client starts over with a new one.
+func (s *ResearchService) runAssembleUploadJob(ctx context.Context, job jobs.Job) error {
+	var payload uploadSessionJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	session, err := s.store.GetUploadSessionByID(db.WithPrimary(ctx), pgtype.UUID{Bytes: payload.SessionID, Valid: true})
+	if err != nil {
+		if isNoRows(err) { // Project deleted meanwhile
+			return nil
+		}
+		return fmt.Errorf("could not load upload session: %w", err)
+	}
+	if session.Status != UploadSessionAssembling {
+		return nil
+	}
+	chunks, err := s.store.ListUploadSessionChunks(db.WithPrimary(ctx), session.ID)
+	if err != nil {
+		return fmt.Errorf("could not list chunks: %w", err)
+	}
+
+	var upload sqlc.ProjectUpload
+	next := int64(0)
+	for _, chunk := range chunks {
+		if chunk.ByteOffset != next {
+			err = fmt.Errorf("chunks do not cover the file: gap at byte %d", next)
+			break
+		}
+		next += chunk.Size
+	}
+	if err == nil && next != session.TotalSize {
+		err = fmt.Errorf("chunks do not cover the file: %d of %d bytes", next, session.TotalSize)
+	}
+	if err == nil {
+		rule := sourceUploadRule
+		rule.MaxSize = s.uploads.ResumableMaxSize
+		r := &chunkReader{ctx: ctx, files: s.files, chunks: chunks}
+		upload, err = s.createUpload(ctx, session.ProjectID.Bytes, session.UserID.Bytes, session.FileName, r, rule, session.Sha256)
+		r.Close()
+	}
+	if err != nil {
+		var uploadErr *UploadError
+		permanent := errors.As(err, &uploadErr) || errors.Is(err, ErrUploadExists) || errors.Is(err, ErrUploadChecksumMismatch) ||
+			errors.Is(err, storage.ErrNotFound) || errors.Is(err, errChunkSize)
+		if !permanent && !job.LastAttempt() {
+			return err
+		}
+		s.finishUploadSession(context.WithoutCancel(ctx), session, UploadSessionFailed, err.Error(), pgtype.UUID{})
+		if permanent {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+	s.finishUploadSession(ctx, session, UploadSessionCompleted, "", upload.ID)
+	s.logger.Info("Upload session assembled", "projectID", uuid.UUID(session.ProjectID.Bytes), "sessionID", payload.SessionID, "uploadID", uuid.UUID(upload.ID.Bytes))
+	return nil
+}
+
+// finishUploadSession records how a session ended and drops its chunks, which are no
+// longer needed either way
+func (s *ResearchService) finishUploadSession(ctx context.Context, session sqlc.UploadSession, status, message string, uploadID pgtype.UUID) {
+	if err := s.store.FinishUploadSession(ctx, sqlc.FinishUploadSessionParams{
+		ID:       session.ID,
+		Status:   status,
+		Error:    message,
+		UploadID: uploadID,
+	}); err != nil {
+		s.logger.Error("Failed to update upload session", "sessionID", uuid.UUID(session.ID.Bytes), "error", err)
+	}
+	keys, err := s.store.DeleteUploadSessionChunks(ctx, session.ID)
+	if err != nil {
+		s.logger.Warn("Failed to delete upload session chunks", "sessionID", uuid.UUID(session.ID.Bytes), "error", err)
+		return
+	}
+	for _, key := range keys {
+		s.removeUploadFile(ctx, key)
+	}
+}
+
+// PurgeExpiredUploadSessions deletes the sessions past their expiry and their chunks;
+// sessions being assembled are left to finish
+func (s *ResearchService) PurgeExpiredUploadSessions(ctx context.Context) error {
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	keys, err := s.store.ListExpiredUploadSessionChunks(db.WithPrimary(ctx), now)
+	if err != nil {
+		return fmt.Errorf("could not list expired upload chunks: %w", err)
+	}
+	if err := s.store.DeleteExpiredUploadSessions(ctx, now); err != nil {
+		return fmt.Errorf("could not delete expired upload sessions: %w", err)
+	}
+	for _, key := range keys {
+		s.removeUploadFile(ctx, key)
+	}
+	if len(keys) > 0 {
+		s.logger.Info("Purged expired upload sessions", "chunks", len(keys))
+	}
+	return nil
+}
+
+// errChunkSize is a stored chunk whose length differs from the one recorded for it
+var errChunkSize = errors.New("stored chunk does not match its recorded size")
+
+// chunkReader reads a session's chunks, in order, as one file
+type chunkReader struct {
+	ctx    context.Context
+	files  storage.Storage
+	chunks []sqlc.UploadSessionChunk
+	cur    io.ReadCloser
+	read   int64 // Of the current chunk
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			obj, err := r.files.Open(r.ctx, r.chunks[0].StorageKey)
+			if err != nil {
+				return 0, fmt.Errorf("could not open chunk at byte %d: %w", r.chunks[0].ByteOffset, err)
+			}
+			r.cur, r.read = obj, 0
+		}
+		n, err := r.cur.Read(p)
+		r.read += int64(n)
+		if r.read > r.chunks[0].Size {
+			return 0, fmt.Errorf("chunk at byte %d: %w", r.chunks[0].ByteOffset, errChunkSize)
+		}
+		if errors.Is(err, io.EOF) {
+			if r.read != r.chunks[0].Size {
+				return 0, fmt.Errorf("chunk at byte %d: %w", r.chunks[0].ByteOffset, errChunkSize)
+			}
+			r.cur.Close()
+			r.cur, r.chunks = nil, r.chunks[1:]
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Close()
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+var (
+	ErrUploadEmpty        = errors.New("file is empty")
+	ErrUploadTooManyPages = errors.New("PDF has more pages than the upload limit")
+)
+
+// Codes of UploadError, stable for clients to branch on
+const (
+	UploadCodeTooLarge        = "file_too_large"
+	UploadCodeUnsupportedType = "unsupported_type"
+	UploadCodeTooManyPages    = "too_many_pages"
+	UploadCodeEmpty           = "empty_file"
+)
+
+// UploadError is an uploaded file that failed validation. It matches the sentinel of
+// its cause with errors.Is, e.g. ErrUploadTooLarge or the endpoint's file-type error,
+// and its fields say which limit was broken.
+type UploadError struct {
+	Code     string   `json:"code"`
+	Limit    int64    `json:"limit,omitempty"`    // Bytes for file_too_large, pages for too_many_pages
+	Actual   int64    `json:"actual,omitempty"`   // Pages found, for too_many_pages
+	Detected string   `json:"detected,omitempty"` // MIME type found, for unsupported_type
+	Accepted []string `json:"accepted,omitempty"` // MIME types the endpoint takes, for unsupported_type
+	cause    error
+}
+
+func (e *UploadError) Error() string {
+	switch e.Code {
+	case UploadCodeTooLarge:
+		return fmt.Sprintf("%v (%d MB)", e.cause, e.Limit>>20)
+	case UploadCodeTooManyPages:
+		return fmt.Sprintf("%v: %d pages, at most %d allowed", e.cause, e.Actual, e.Limit)
+	}
+	return e.cause.Error()
+}
+
+func (e *UploadError) Unwrap() error { return e.cause }
+
+// NewUploadTooLargeError reports a file over maxSize bytes, including one cut off by a
+// request body limit before it could be read in full
+func NewUploadTooLargeError(maxSize int64) *UploadError {
+	return &UploadError{Code: UploadCodeTooLarge, Limit: maxSize, cause: ErrUploadTooLarge}
+}
+
+// uploadRule is what a file-accepting endpoint takes. Types are told apart by content,
+// never by the file name or the Content-Type the client sent.
+type uploadRule struct {
+	Accept      []string
+	Unsupported error // Cause of the error for content of another type
+	MaxSize     int64 // Bytes; 0 is the upload limit
+}
+
+// maxSize is the largest file rule lets through
+func (s *ResearchService) maxSize(rule uploadRule) int64 {
+	if rule.MaxSize > 0 {
+		return rule.MaxSize
+	}
+	return s.uploads.MaxSize
+}
+
+// Rules of the file-accepting endpoints
+var (
+	sourceUploadRule    = uploadRule{Accept: []string{mimePDF}, Unsupported: ErrUploadNotPDF}
+	paperUploadRule     = uploadRule{Accept: []string{mimePDF}, Unsupported: errUnsupportedFileType}
+	figureUploadRule    = uploadRule{Accept: []string{mimePNG, mimeJPEG}, Unsupported: ErrFigureImageType}
+	appendixUploadRule  = uploadRule{Accept: []string{mimePNG, mimeJPEG}, Unsupported: ErrAppendixImageType}
+	guidelineUploadRule = uploadRule{Accept: []string{mimePDF, mimeDOCX}, Unsupported: ErrGuidelineFileType}
+	datasetUploadRule   = uploadRule{Accept: []string{mimeCSV}, Unsupported: ErrInvalidDataset}
+)
+
+// sniffLen is how much of a file is read to tell its type, as in http.DetectContentType
+const sniffLen = 512
+
+// checkUpload validates a file of size bytes against rule and the upload limits,
+// returning its MIME type
+func (s *ResearchService) checkUpload(r io.ReaderAt, size int64, rule uploadRule) (string, error) {
+	if size == 0 {
+		return "", &UploadError{Code: UploadCodeEmpty, cause: ErrUploadEmpty}
+	}
+	if maxSize := s.maxSize(rule); size > maxSize {
+		return "", NewUploadTooLargeError(maxSize)
+	}
+	head := make([]byte, min(size, sniffLen))
+	if _, err := r.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("could not read upload: %w", err)
+	}
+	mimeType := sniffFileType(head)
+	// A DOCX is a ZIP archive with a Word document in it; other archives are turned away
+	if mimeType == mimeDOCX {
+		if archive, err := zip.NewReader(r, size); err != nil || !slices.ContainsFunc(archive.File, func(f *zip.File) bool {
+			return f.Name == "word/document.xml"
+		}) {
+			mimeType = mimeZIP
+		}
+	}
+	if !slices.Contains(rule.Accept, mimeType) {
+		return "", &UploadError{Code: UploadCodeUnsupportedType, Detected: mimeType, Accepted: rule.Accept, cause: rule.Unsupported}
+	}
+
+	if mimeType == mimePDF && s.uploads.MaxPDFPages > 0 {
+		data := make([]byte, size)
+		if _, err := r.ReadAt(data, 0); err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("could not read upload: %w", err)
+		}
+		if pages := pdfPageCount(data); pages > s.uploads.MaxPDFPages {
+			return "", s.tooManyPages(pages)
+		}
+	}
+	return mimeType, nil
+}
+
+func (s *ResearchService) tooManyPages(pages int) *UploadError {
+	return &UploadError{Code: UploadCodeTooManyPages, Limit: int64(s.uploads.MaxPDFPages), Actual: int64(pages), cause: ErrUploadTooManyPages}
+}
+
+// sniffFileType tells the upload types apart by their leading bytes. Text that is valid
+// UTF-8 without control characters passes for CSV; parsing decides the rest.
+func sniffFileType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return mimePDF
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return mimeDOCX // Until checkUpload has looked inside
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return mimePNG
+	case bytes.HasPrefix(head, []byte("\xff\xd8\xff")):
+		return mimeJPEG
+	case isText(head):
+		return mimeCSV
+	}
+	return mimeOctetStream
+}
+
+// isText reports whether head reads as UTF-8 text; a rune cut off at the end is allowed
+func isText(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")) // Byte order mark
+	for i := 0; i < len(head); {
+		r, n := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && n <= 1 {
+			return !utf8.FullRune(head[i:])
+		}
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+		i += n
+	}
+	return len(head) > 0
+}
+
+var (
+	pdfPagesNode = regexp.MustCompile(`/Type\s*/Pages\b`)
+	pdfCount     = regexp.MustCompile(`/Count\s+(\d+)`)
+)
+
+// pdfPageCount reads the page count of a PDF from the /Count of its page tree root, the
+// largest of the /Pages nodes. It returns 0 when the tree sits in a compressed object
+// stream; the count reported by text extraction is checked again then.
+func pdfPageCount(data []byte) int {
+	pages := 0
+	for _, loc := range pdfPagesNode.FindAllIndex(data, -1) {
+		start := bytes.LastIndex(data[:loc[0]], []byte(" obj"))
+		end := bytes.Index(data[loc[1]:], []byte("endobj"))
+		if start < 0 || end < 0 {
+			continue
+		}
+		if m := pdfCount.FindSubmatch(data[start : loc[1]+end]); m != nil {
+			if n, err := strconv.Atoi(string(m[1])); err == nil {
+				pages = max(pages, n)
+			}
+		}
+	}
+	return pages
+}
@@ -0,0 +1,422 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/db/sqlc"
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+	apimodels "github.com/shawgichan/research-service/go-backend/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Webhook event types. They are part of the public webhook API, so don't rename them.
+const (
+	WebhookChapterGenerated  = "chapter.generated"
+	WebhookDocumentCompleted = "document.completed"
+	WebhookDocumentFailed    = "document.failed"
+	WebhookReferenceAdded    = "reference.added"
+	WebhookReferenceDeleted  = "reference.deleted"
+	WebhookReviewDecided     = "review.decided"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{
+	WebhookChapterGenerated,
+	WebhookDocumentCompleted,
+	WebhookDocumentFailed,
+	WebhookReferenceAdded,
+	WebhookReferenceDeleted,
+	WebhookReviewDecided,
+}
+
+const (
+	webhookBatchSize       = 10
+	maxWebhookAttempts     = 8
+	webhookFirstRetryDelay = 30 * time.Second // Doubles after every failed attempt
+	webhookMaxRetryDelay   = 6 * time.Hour
+	webhookErrorMaxLen     = 500
+	webhookDeliveriesLimit = 50
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrUnknownWebhookEvent     = errors.New("unknown webhook event")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https URL")
+)
+
+// WebhookConfig controls outbound webhook requests
+type WebhookConfig struct {
+	Timeout             time.Duration // Upper bound for one delivery attempt
+	AllowPrivateTargets bool          // Allow loopback and private network targets, e.g. for local development
+}
+
+// WebhookService queues project events for registered webhooks and delivers them
+type WebhookService struct {
+	store  db.Store
+	client *http.Client
+	cfg    WebhookConfig
+	logger *applogger.AppLogger
+}
+
+func NewWebhookService(store db.Store, cfg WebhookConfig, logger *applogger.AppLogger) *WebhookService {
+	return &WebhookService{
+		store: store,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: publicTransport(cfg.AllowPrivateTargets),
+			// Receivers must answer at the registered URL; a redirect counts as a failed delivery
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// webhookPayload is the JSON body of every delivery
+type webhookPayload struct {
+	ID        uuid.UUID `json:"id"` // Delivery id, the same across retries; receivers can use it to deduplicate
+	Event     string    `json:"event"`
+	ProjectID uuid.UUID `json:"project_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Emit queues event for every active webhook of the project subscribed to it.
+// Like Notify it is best effort: failures are logged, never returned. A nil WebhookService is a no-op.
+func (s *WebhookService) Emit(ctx context.Context, projectID uuid.UUID, event string, data any) {
+	if s == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	hooks, err := s.store.ListActiveWebhooksForEvent(ctx, sqlc.ListActiveWebhooksForEventParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		Event:     event,
+	})
+	if err != nil {
+		s.logger.Error("Failed to load webhooks", "projectID", projectID, "event", event, "error", err)
+		return
+	}
+	for _, hook := range hooks {
+		deliveryID := uuid.New()
+		payload, err := json.Marshal(webhookPayload{
+			ID:        deliveryID,
+			Event:     event,
+			ProjectID: projectID,
+			CreatedAt: time.Now().UTC(),
+			Data:      data,
+		})
+		if err != nil {
+			s.logger.Error("Failed to encode webhook payload", "event", event, "error", err)
+			return
+		}
+		_, err = s.store.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			ID:        pgtype.UUID{Bytes: deliveryID, Valid: true},
+			WebhookID: hook.ID,
+			Event:     event,
+			Payload:   payload,
+		})
+		if err != nil {
+			s.logger.Error("Failed to queue webhook delivery", "webhookID", hook.ID.Bytes, "event", event, "error", err)
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries concurrently and returns how many it attempted.
+// Failed attempts are retried with exponential backoff until maxWebhookAttempts.
+// Several processes can run it at once; each leases a different batch.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	// The lease outlasts every request in the batch, after which unrecorded deliveries become due again
+	leasedUntil := time.Now().Add(s.cfg.Timeout + time.Minute)
+	batch, err := s.store.ClaimDueWebhookDeliveries(ctx, sqlc.ClaimDueWebhookDeliveriesParams{
+		LeasedUntil: pgtype.Timestamptz{Time: leasedUntil, Valid: true},
+		BatchSize:   webhookBatchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("could not claim webhook deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, row := range batch {
+		wg.Add(1)
+		go func(row sqlc.ClaimDueWebhookDeliveriesRow) {
+			defer wg.Done()
+			s.attempt(ctx, row)
+		}(row)
+	}
+	wg.Wait()
+	return len(batch), nil
+}
+
+// attempt sends one delivery and records the outcome in the delivery log
+func (s *WebhookService) attempt(ctx context.Context, row sqlc.ClaimDueWebhookDeliveriesRow) {
+	d := row.WebhookDelivery
+	start := time.Now()
+	statusCode, err := s.send(ctx, row.Url, row.Secret, d)
+	params := sqlc.RecordWebhookDeliveryAttemptParams{
+		ID:            d.ID,
+		Status:        "succeeded",
+		NextAttemptAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		DurationMs:    pgtype.Int4{Int32: int32(time.Since(start).Milliseconds()), Valid: true},
+	}
+	if statusCode != 0 {
+		params.ResponseStatus = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+	if err != nil {
+		attempts := int(d.Attempts) + 1
+		params.Status = "pending"
+		params.NextAttemptAt = pgtype.Timestamptz{Time: time.Now().Add(webhookRetryDelay(attempts)), Valid: true}
+		if attempts >= maxWebhookAttempts {
+			params.Status = "failed"
+		}
+		params.LastError = truncate(err.Error(), webhookErrorMaxLen)
+		s.logger.Warn("Webhook delivery failed", "deliveryID", d.ID.Bytes, "webhookID", d.WebhookID.Bytes, "attempt", attempts, "error", err)
+	}
+	if err := s.store.RecordWebhookDeliveryAttempt(ctx, params); err != nil {
+		s.logger.Error("Failed to record webhook delivery attempt", "deliveryID", d.ID.Bytes, "error", err)
+	}
+}
+
+// send POSTs the payload and returns the response status; any non-2xx response is an error
+func (s *WebhookService) send(ctx context.Context, target, secret string, d sqlc.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "research-service-webhooks/1")
+	req.Header.Set("X-Webhook-Event", d.Event)
+	req.Header.Set("X-Webhook-Delivery", uuid.UUID(d.ID.Bytes).String())
+	req.Header.Set("X-Webhook-Signature", "t="+timestamp+",v1="+SignWebhookPayload(secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorMaxLen))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "timestamp.payload" keyed with the webhook's secret.
+// Receivers recompute it to check that a delivery is authentic and reject stale timestamps to stop replays.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay is the wait after the given number of failed attempts
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookFirstRetryDelay
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxRetryDelay)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen]
+}
+
+// --- Webhook management ---
+
+// ListWebhooks returns the project's webhooks. Only the owner can manage webhooks.
+func (s *ResearchService) ListWebhooks(ctx context.Context, projectID, userID uuid.UUID) ([]sqlc.Webhook, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+	hooks, err := s.store.ListProjectWebhooks(ctx, pgtype.UUID{Bytes: projectID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// CreateWebhook registers a URL for the project's events with a new signing secret
+func (s *ResearchService) CreateWebhook(ctx context.Context, projectID, userID uuid.UUID, req apimodels.CreateWebhookRequest) (sqlc.Webhook, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner); err != nil {
+		return sqlc.Webhook{}, err
+	}
+	target, err := webhookURL(req.URL)
+	if err != nil {
+		return sqlc.Webhook{}, err
+	}
+	events, err := webhookEventList(req.Events)
+	if err != nil {
+		return sqlc.Webhook{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return sqlc.Webhook{}, err
+	}
+	hook, err := s.store.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+		Url:       target,
+		Secret:    secret,
+		Events:    events,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return sqlc.Webhook{}, fmt.Errorf("could not create webhook: %w", err)
+	}
+	s.logger.Info("Webhook created", "webhookID", hook.ID.Bytes, "projectID", projectID, "userID", userID)
+	return hook, nil
+}
+
+// UpdateWebhook changes a webhook's URL, events or active state; fields missing from req are kept
+func (s *ResearchService) UpdateWebhook(ctx context.Context, projectID, webhookID, userID uuid.UUID, req apimodels.UpdateWebhookRequest) (sqlc.Webhook, error) {
+	hook, err := s.getWebhook(ctx, projectID, webhookID, userID)
+	if err != nil {
+		return sqlc.Webhook{}, err
+	}
+	params := sqlc.UpdateWebhookParams{ID: hook.ID, ProjectID: hook.ProjectID, Url: hook.Url, Events: hook.Events, Active: hook.Active}
+	if req.URL != nil {
+		if params.Url, err = webhookURL(*req.URL); err != nil {
+			return sqlc.Webhook{}, err
+		}
+	}
+	if req.Events != nil {
+		if params.Events, err = webhookEventList(req.Events); err != nil {
+			return sqlc.Webhook{}, err
+		}
+	}
+	if req.Active != nil {
+		params.Active = *req.Active
+	}
+	updated, err := s.store.UpdateWebhook(ctx, params)
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Webhook{}, ErrWebhookNotFound
+		}
+		return sqlc.Webhook{}, fmt.Errorf("could not update webhook: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery log
+func (s *ResearchService) DeleteWebhook(ctx context.Context, projectID, webhookID, userID uuid.UUID) error {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner); err != nil {
+		return err
+	}
+	rows, err := s.store.DeleteWebhook(ctx, sqlc.DeleteWebhookParams{
+		ID:        pgtype.UUID{Bytes: webhookID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("could not delete webhook: %w", err)
+	}
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+	s.logger.Info("Webhook deleted", "webhookID", webhookID, "projectID", projectID, "userID", userID)
+	return nil
+}
+
+// ListWebhookDeliveries returns the webhook's most recent deliveries, newest first
+func (s *ResearchService) ListWebhookDeliveries(ctx context.Context, projectID, webhookID, userID uuid.UUID) ([]sqlc.WebhookDelivery, error) {
+	hook, err := s.getWebhook(ctx, projectID, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.store.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{WebhookID: hook.ID, RowLimit: webhookDeliveriesLimit})
+	if err != nil {
+		return nil, fmt.Errorf("database error fetching webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a delivery to be sent again right away, whatever its state.
+// Its attempt count carries on, so a failed delivery gets one more try.
+func (s *ResearchService) RedeliverWebhook(ctx context.Context, projectID, webhookID, deliveryID, userID uuid.UUID) (sqlc.WebhookDelivery, error) {
+	hook, err := s.getWebhook(ctx, projectID, webhookID, userID)
+	if err != nil {
+		return sqlc.WebhookDelivery{}, err
+	}
+	delivery, err := s.store.RetryWebhookDelivery(ctx, sqlc.RetryWebhookDeliveryParams{
+		ID:        pgtype.UUID{Bytes: deliveryID, Valid: true},
+		WebhookID: hook.ID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+		}
+		return sqlc.WebhookDelivery{}, fmt.Errorf("could not queue webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (s *ResearchService) getWebhook(ctx context.Context, projectID, webhookID, userID uuid.UUID) (sqlc.Webhook, error) {
+	if _, err := s.requireProjectRole(ctx, projectID, userID, ProjectRoleOwner); err != nil {
+		return sqlc.Webhook{}, err
+	}
+	hook, err := s.store.GetWebhook(ctx, sqlc.GetWebhookParams{
+		ID:        pgtype.UUID{Bytes: webhookID, Valid: true},
+		ProjectID: pgtype.UUID{Bytes: projectID, Valid: true},
+	})
+	if err != nil {
+		if isNoRows(err) {
+			return sqlc.Webhook{}, ErrWebhookNotFound
+		}
+		return sqlc.Webhook{}, fmt.Errorf("database error fetching webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func webhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return "", ErrInvalidWebhookURL
+	}
+	return raw, nil
+}
+
+// webhookEventList validates events and removes duplicates; an empty list subscribes to everything
+func webhookEventList(events []string) ([]string, error) {
+	list := make([]string, 0, len(events))
+	for _, e := range events {
+		if !slices.Contains(WebhookEvents, e) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownWebhookEvent, e)
+		}
+		if !slices.Contains(list, e) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
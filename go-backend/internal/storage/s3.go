@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
+)
+
+// S3Config points at a bucket of an S3-compatible service
+type S3Config struct {
+	Endpoint        string // Scheme and host, e.g. https://s3.eu-west-1.amazonaws.com, http://minio:9000 or https://storage.googleapis.com
+	Region          string // us-east-1 when empty, which MinIO accepts; Cloud Storage takes auto
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Name the bucket in the path rather than the host, as MinIO needs by default
+}
+
+// S3 stores objects in a bucket, signing requests with AWS Signature Version 4.
+// Payloads are sent unsigned, so uploads stream without being hashed first.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// unsignedPayload stands in for the payload hash of requests and presigned URLs
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+func NewS3(cfg S3Config) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" || endpoint.Path != "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("S3 storage needs a bucket and credentials")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	// No client timeout: downloads stream for as long as the client reads, bounded by ctx
+	return &S3{cfg: cfg, endpoint: endpoint, client: telemetry.NewHTTPClient(&http.Client{})}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size == 0 {
+		r = http.NoBody
+	}
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError("put", key, resp)
+	}
+	return nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (*Object, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &Object{ReadCloser: resp.Body, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	defer resp.Body.Close()
+	return nil, responseError("get", key, resp)
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return responseError("delete", key, resp)
+}
+
+// PresignGet signs the URL in its query string. The response names the file so the
+// browser saves it under fileName.
+func (s *S3) PresignGet(_ context.Context, key, fileName string, ttl time.Duration) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	u := s.objectURL(key)
+	amzDate := time.Now().UTC().Format("20060102T150405Z")
+	scope := amzDate[:8] + "/" + s.cfg.Region + "/s3/aws4_request"
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    s.cfg.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(ttl / time.Second)),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if fileName != "" {
+		query["response-content-disposition"] = AttachmentDisposition(fileName)
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = uriEscape(k, true) + "=" + uriEscape(query[k], true)
+	}
+	canonicalQuery := strings.Join(pairs, "&")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(amzDate, scope, canonicalRequest)
+	return u.String(), nil
+}
+
+// AttachmentDisposition is the Content-Disposition of a download saved as fileName
+func AttachmentDisposition(fileName string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+}
+
+// objectURL addresses key in the bucket, encoded as Signature Version 4 expects
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path, u.RawPath = "/"+key, "/"+uriEscape(key, false)
+	if s.cfg.PathStyle {
+		u.Path, u.RawPath = "/"+s.cfg.Bucket+u.Path, "/"+uriEscape(s.cfg.Bucket, true)+u.RawPath
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	return &u
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+}
+
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s: %w", strings.ToLower(req.Method), err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers for the s3 service
+func (s *S3) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + unsignedPayload + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	scope := amzDate[:8] + "/" + s.cfg.Region + "/s3/aws4_request"
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, s.signature(amzDate, scope, canonicalRequest)))
+}
+
+func (s *S3) signature(amzDate, scope, canonicalRequest string) string {
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), amzDate[:8])
+	for _, part := range []string{s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// uriEscape percent-encodes everything but unreserved characters, and slashes unless
+// encodeSlash is set, the encoding Signature Version 4 signs
+func uriEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// responseError reads the error code and message out of an S3 error response
+func responseError(op, key string, resp *http.Response) error {
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(body, &apiErr) != nil || apiErr.Code == "" {
+		apiErr.Code = strings.TrimSpace(string(body))
+	}
+	return fmt.Errorf("s3 %s %s: %s %s (status %d)", op, key, apiErr.Code, apiErr.Message, resp.StatusCode)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
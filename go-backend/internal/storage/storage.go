@@ -0,0 +1,77 @@
+// Package storage keeps uploaded and generated files on the local disk or in an
+// S3-compatible object store (Amazon S3, MinIO, or Google Cloud Storage through its XML
+// API and HMAC keys), whichever STORAGE_BACKEND selects. Files are addressed by keys,
+// slash-separated relative paths such as projects/<id>/uploads/<file>.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	ErrNotFound           = errors.New("object not found")
+	ErrPresignUnsupported = errors.New("storage backend cannot presign URLs")
+)
+
+// Object is a stored file opened for reading. Size is -1 and ContentType empty when
+// the backend does not know them.
+type Object struct {
+	io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// Storage reads and writes files by key
+type Storage interface {
+	// Put stores size bytes from r under key, replacing any object already there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the object under key, or ErrNotFound
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object under key; a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the object as fileName without credentials
+	// until ttl has passed, or ErrPresignUnsupported
+	PresignGet(ctx context.Context, key, fileName string, ttl time.Duration) (string, error)
+}
+
+// Config selects and configures the backend
+type Config struct {
+	Backend  string // local or s3
+	LocalDir string // Root directory of the local backend
+	S3       S3Config
+}
+
+// New returns the configured backend
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case BackendLocal:
+		return NewLocal(cfg.LocalDir)
+	case BackendS3:
+		return NewS3(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
+
+// checkKey rejects keys that could address anything outside the store
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
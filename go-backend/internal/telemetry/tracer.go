@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/shawgichan/research-service/go-backend/internal/util"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is used as the tracer name for spans created by this service
+const InstrumentationName = "github.com/shawgichan/research-service/go-backend"
+
+// InitTracer configures the global tracer provider with an OTLP/HTTP exporter.
+// When tracing is disabled a no-op shutdown function is returned and the global
+// no-op provider stays in place, so instrumented code paths cost next to nothing.
+func InitTracer(ctx context.Context, config util.Config) (func(context.Context) error, error) {
+	if !config.OTelEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{}
+	if config.OTelExporterEndpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(config.OTelExporterEndpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.OTelServiceName),
+		semconv.DeploymentEnvironment(config.Environment),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.OTelSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the service-wide tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// NewHTTPClient wraps the default transport so outbound requests get client spans
+// and propagate the trace context to downstream services.
+func NewHTTPClient(client *http.Client) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client.Transport = otelhttp.NewTransport(transport)
+	return client
+}
@@ -1,8 +1,15 @@
 package util
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/shawgichan/research-service/go-backend/internal/secrets"
+
 	"github.com/spf13/viper"
 )
 
@@ -10,10 +17,208 @@ type Config struct {
 	Environment          string        `mapstructure:"ENVIRONMENT"`
 	Port                 string        `mapstructure:"PORT"`
 	DatabaseURL          string        `mapstructure:"DATABASE_URL"`
+	DatabaseReplicaURL   string        `mapstructure:"DATABASE_REPLICA_URL"` // Optional read replica for SELECTs
 	OpenAIAPIKey         string        `mapstructure:"OPENAI_API_KEY"`
 	TokenSecretKey       string        `mapstructure:"TOKEN_SECRET_KEY"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+
+	// Connection pool (applied to the primary and the replica)
+	DBMaxConns          int32         `mapstructure:"DB_MAX_CONNS"`
+	DBMinConns          int32         `mapstructure:"DB_MIN_CONNS"`
+	DBMaxConnLifetime   time.Duration `mapstructure:"DB_MAX_CONN_LIFETIME"`
+	DBMaxConnIdleTime   time.Duration `mapstructure:"DB_MAX_CONN_IDLE_TIME"`
+	DBHealthCheckPeriod time.Duration `mapstructure:"DB_HEALTH_CHECK_PERIOD"`
+
+	// AI generation; hot-reloadable, see Tunables
+	AIModel                       string  `mapstructure:"AI_MODEL"`
+	AITemperatureLiteratureReview float64 `mapstructure:"AI_TEMPERATURE_LITERATURE_REVIEW"`
+	AITemperatureIntroduction     float64 `mapstructure:"AI_TEMPERATURE_INTRODUCTION"`
+	AITemperatureMethodology      float64 `mapstructure:"AI_TEMPERATURE_METHODOLOGY"`
+	AITemperatureResults          float64 `mapstructure:"AI_TEMPERATURE_RESULTS"`
+	AITemperatureCustomChapter    float64 `mapstructure:"AI_TEMPERATURE_CUSTOM_CHAPTER"`
+	AITemperatureSection          float64 `mapstructure:"AI_TEMPERATURE_SECTION"`
+
+	// Deadlines for AI work: AI_CALL_TIMEOUT for one call to the provider,
+	// AI_GENERATION_TIMEOUT for a whole generation across its calls. A generation asked
+	// for in a request keeps running, and is saved, when the client disconnects, unless
+	// AI_CANCEL_ON_DISCONNECT is set.
+	AICallTimeout        time.Duration `mapstructure:"AI_CALL_TIMEOUT"`
+	AIGenerationTimeout  time.Duration `mapstructure:"AI_GENERATION_TIMEOUT"`
+	AICancelOnDisconnect bool          `mapstructure:"AI_CANCEL_ON_DISCONNECT"`
+
+	// Replies of the AI provider are reused for an identical request (same model, prompt
+	// and parameters) made within AI_RESPONSE_CACHE_TTL, kept in memory; 0 disables reuse
+	AIResponseCacheTTL        time.Duration `mapstructure:"AI_RESPONSE_CACHE_TTL"`
+	AIResponseCacheMaxEntries int           `mapstructure:"AI_RESPONSE_CACHE_MAX_ENTRIES"`
+
+	// Embeddings for semantic reference search (OpenAI-compatible /embeddings endpoint).
+	// The reference_embeddings column is vector(1536), so the model must produce 1536 dimensions.
+	EmbeddingAPIURL string `mapstructure:"EMBEDDING_API_URL"`
+	EmbeddingAPIKey string `mapstructure:"EMBEDDING_API_KEY"` // Embeddings are disabled when empty
+	EmbeddingModel  string `mapstructure:"EMBEDDING_MODEL"`
+
+	// Semantic Scholar Graph API for paper search; works without a key at a lower rate limit
+	SemanticScholarAPIURL             string `mapstructure:"SEMANTIC_SCHOLAR_API_URL"`
+	SemanticScholarRecommendationsURL string `mapstructure:"SEMANTIC_SCHOLAR_RECOMMENDATIONS_URL"`
+	SemanticScholarAPIKey             string `mapstructure:"SEMANTIC_SCHOLAR_API_KEY"`
+
+	// Trashed chapters and references are purged after this long
+	TrashRetention time.Duration `mapstructure:"TRASH_RETENTION"`
+
+	// Rate limiting (token bucket per client IP on /api/v1); RPS and burst are hot-reloadable
+	RateLimitEnabled bool    `mapstructure:"RATE_LIMIT_ENABLED"`
+	RateLimitBackend string  `mapstructure:"RATE_LIMIT_BACKEND"` // memory | redis
+	RateLimitRPS     float64 `mapstructure:"RATE_LIMIT_RPS"`
+	RateLimitBurst   int     `mapstructure:"RATE_LIMIT_BURST"`
+	RedisURL         string  `mapstructure:"REDIS_URL"`
+
+	// Where a client's IP comes from, for rate limiting and sessions. X-Forwarded-For is only
+	// believed from TRUSTED_PROXIES, comma-separated IPs or CIDRs; empty trusts no proxy.
+	// TRUSTED_PLATFORM names a header the platform in front sets instead, e.g. CF-Connecting-IP,
+	// with cloudflare and google as shorthands.
+	TrustedProxies  string `mapstructure:"TRUSTED_PROXIES"`
+	TrustedPlatform string `mapstructure:"TRUSTED_PLATFORM"`
+
+	// Progress events: memory (single process) | postgres (LISTEN/NOTIFY, shared by all processes)
+	EventsBackend string `mapstructure:"EVENTS_BACKEND"`
+
+	// Feature flags: comma separated name=bool pairs, overridable at runtime via the admin API
+	FeatureFlags string `mapstructure:"FEATURE_FLAGS"`
+
+	// Log levels per component, e.g. "info,services.ai=debug,db=warn"; changeable at runtime via the admin API
+	LogLevels string `mapstructure:"LOG_LEVELS"`
+
+	// Log sinks besides stdout; each is off while its path or URL is empty
+	LogFilePath            string        `mapstructure:"LOG_FILE_PATH"`
+	LogFileMaxSizeMB       int           `mapstructure:"LOG_FILE_MAX_SIZE_MB"`
+	LogFileMaxBackups      int           `mapstructure:"LOG_FILE_MAX_BACKUPS"`
+	LogFileMaxAge          time.Duration `mapstructure:"LOG_FILE_MAX_AGE"`
+	LogLokiURL             string        `mapstructure:"LOG_LOKI_URL"`          // e.g. http://loki:3100, credentials as user:password@
+	LogElasticsearchURL    string        `mapstructure:"LOG_ELASTICSEARCH_URL"` // e.g. https://elastic:9200
+	LogElasticsearchIndex  string        `mapstructure:"LOG_ELASTICSEARCH_INDEX"`
+	LogElasticsearchAPIKey string        `mapstructure:"LOG_ELASTICSEARCH_API_KEY"`
+
+	// Outbound email goes through MAIL_PROVIDER: smtp, ses or sendgrid.
+	// Left empty it is smtp while SMTP_HOST is set, otherwise email is off.
+	MailProvider              string        `mapstructure:"MAIL_PROVIDER"`
+	MailFrom                  string        `mapstructure:"MAIL_FROM"` // e.g. "Research Service <no-reply@example.com>", defaults to SMTP_FROM
+	SMTPHost                  string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                  string        `mapstructure:"SMTP_PORT"`
+	SMTPUsername              string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword              string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPFrom                  string        `mapstructure:"SMTP_FROM"`
+	SESRegion                 string        `mapstructure:"SES_REGION"`
+	SESAccessKeyID            string        `mapstructure:"SES_ACCESS_KEY_ID"`
+	SESSecretAccessKey        string        `mapstructure:"SES_SECRET_ACCESS_KEY"`
+	SendGridAPIKey            string        `mapstructure:"SENDGRID_API_KEY"`
+	NotificationEmailInterval time.Duration `mapstructure:"NOTIFICATION_EMAIL_INTERVAL"`
+	InvitationURL             string        `mapstructure:"INVITATION_URL"` // Page that accepts invitations, given ?invitation=<id>&token=<token>; emails carry a code when empty
+
+	// Outbound project webhooks
+	WebhookTimeout             time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookDeliveryInterval    time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	WebhookAllowPrivateTargets bool          `mapstructure:"WEBHOOK_ALLOW_PRIVATE_TARGETS"` // Allow loopback and private network URLs, e.g. for local development
+
+	// Background jobs (document generation, reference embeddings, notification emails, purges)
+	JobsPollInterval time.Duration `mapstructure:"JOBS_POLL_INTERVAL"`
+	JobsConcurrency  int           `mapstructure:"JOBS_CONCURRENCY"` // Jobs run at once by each instance
+	JobsRetention    time.Duration `mapstructure:"JOBS_RETENTION"`   // Completed jobs are deleted after this; dead ones are kept
+
+	// Stripe billing; disabled, with no plan limits, while STRIPE_SECRET_KEY is empty
+	StripeSecretKey          string `mapstructure:"STRIPE_SECRET_KEY"`
+	StripeWebhookSecret      string `mapstructure:"STRIPE_WEBHOOK_SECRET"` // Signing secret of the webhook endpoint (whsec_...)
+	StripePricePro           string `mapstructure:"STRIPE_PRICE_PRO"`
+	StripePriceInstitutional string `mapstructure:"STRIPE_PRICE_INSTITUTIONAL"`
+	BillingSuccessURL        string `mapstructure:"BILLING_SUCCESS_URL"`
+	BillingCancelURL         string `mapstructure:"BILLING_CANCEL_URL"`
+	BillingPortalReturnURL   string `mapstructure:"BILLING_PORTAL_RETURN_URL"`
+	BillingFreeMaxProjects   int    `mapstructure:"BILLING_FREE_MAX_PROJECTS"`   // Owned at once
+	BillingFreeAIGenerations int    `mapstructure:"BILLING_FREE_AI_GENERATIONS"` // Per month
+	BillingProAIGenerations  int    `mapstructure:"BILLING_PRO_AI_GENERATIONS"`  // Per month; institutional is unlimited
+
+	// Usage quotas per plan and billing period, enforced even without Stripe; 0 is unlimited.
+	// Projects quotas count projects created; the cap on projects owned is BILLING_FREE_MAX_PROJECTS.
+	QuotaFreeProjects           int64 `mapstructure:"QUOTA_FREE_PROJECTS"`
+	QuotaFreeAIWords            int64 `mapstructure:"QUOTA_FREE_AI_WORDS"`
+	QuotaFreeDocuments          int64 `mapstructure:"QUOTA_FREE_DOCUMENTS"`
+	QuotaProProjects            int64 `mapstructure:"QUOTA_PRO_PROJECTS"`
+	QuotaProAIWords             int64 `mapstructure:"QUOTA_PRO_AI_WORDS"`
+	QuotaProDocuments           int64 `mapstructure:"QUOTA_PRO_DOCUMENTS"`
+	QuotaInstitutionalProjects  int64 `mapstructure:"QUOTA_INSTITUTIONAL_PROJECTS"`
+	QuotaInstitutionalAIWords   int64 `mapstructure:"QUOTA_INSTITUTIONAL_AI_WORDS"`
+	QuotaInstitutionalDocuments int64 `mapstructure:"QUOTA_INSTITUTIONAL_DOCUMENTS"`
+
+	// Trials without registering: POST /auth/guest makes a guest with one sandbox project,
+	// deleted after GUEST_TTL unless the guest registers
+	GuestModeEnabled bool          `mapstructure:"GUEST_MODE_ENABLED"`
+	GuestTTL         time.Duration `mapstructure:"GUEST_TTL"`
+	GuestAIWords     int64         `mapstructure:"GUEST_AI_WORDS"`  // Words of AI content a guest may generate
+	GuestDocuments   int64         `mapstructure:"GUEST_DOCUMENTS"` // Documents a guest may export
+
+	// Academic-integrity policy; users see it at GET /users/me/integrity-policy
+	IntegrityRequireAcknowledgment bool   `mapstructure:"INTEGRITY_REQUIRE_ACKNOWLEDGMENT"` // AI writing waits until the user acknowledges the policy
+	IntegrityPolicyVersion         string `mapstructure:"INTEGRITY_POLICY_VERSION"`         // Changing it asks everyone to acknowledge again
+	IntegrityPolicyURL             string `mapstructure:"INTEGRITY_POLICY_URL"`             // The policy's text, e.g. the institution's page
+	IntegrityWatermarkExports      bool   `mapstructure:"INTEGRITY_WATERMARK_EXPORTS"`      // Mark AI-generated chapters and sections in exported documents
+	IntegrityBlockBatchGeneration  bool   `mapstructure:"INTEGRITY_BLOCK_BATCH_GENERATION"` // No batch generation of whole theses for institutional projects
+
+	// Operator endpoints under /api/v1/admin are disabled when empty
+	AdminAPIToken string `mapstructure:"ADMIN_API_TOKEN"`
+
+	// Internal gRPC API (api/proto)
+	GRPCEnabled   bool   `mapstructure:"GRPC_ENABLED"`
+	GRPCPort      string `mapstructure:"GRPC_PORT"`
+	GRPCAuthToken string `mapstructure:"GRPC_AUTH_TOKEN"` // Shared bearer token for internal callers
+
+	// GraphQL
+	GraphQLEnabled bool `mapstructure:"GRAPHQL_ENABLED"` // Mount /api/v1/graphql alongside REST
+
+	// Circuit breakers around the AI provider, embeddings, Semantic Scholar and docgen:
+	// after CIRCUIT_BREAKER_FAILURES failures in a row calls fail fast with a 503 for
+	// CIRCUIT_BREAKER_OPEN_TIMEOUT, then one call probes the service
+	CircuitBreakerFailures    int           `mapstructure:"CIRCUIT_BREAKER_FAILURES"`
+	CircuitBreakerOpenTimeout time.Duration `mapstructure:"CIRCUIT_BREAKER_OPEN_TIMEOUT"`
+
+	// Python document generation service
+	PythonDocGenURL      string        `mapstructure:"PYTHON_DOCGEN_URL"`
+	PythonDocGenSecret   string        `mapstructure:"PYTHON_DOCGEN_SECRET"` // Must match DOCGEN_SHARED_SECRET on the Python side
+	DocGenOutputDir      string        `mapstructure:"DOCGEN_OUTPUT_DIR"`    // The service's output directory as mounted in this container
+	DocGenTimeout        time.Duration `mapstructure:"DOCGEN_TIMEOUT"`
+	DocGenStartupProbe   bool          `mapstructure:"DOCGEN_STARTUP_PROBE"`   // Refuse to start when the service is unreachable
+	ReadinessCheckDocGen bool          `mapstructure:"READINESS_CHECK_DOCGEN"` // Include the Python docgen service in /readyz
+
+	// Source PDFs uploaded to projects; their text is extracted by the docgen service
+	UploadDir         string `mapstructure:"UPLOAD_DIR"` // Files uploaded before object storage; new ones go to STORAGE_BACKEND
+	UploadMaxSizeMB   int    `mapstructure:"UPLOAD_MAX_SIZE_MB"`
+	UploadMaxPDFPages int    `mapstructure:"UPLOAD_MAX_PDF_PAGES"` // 0 is no limit
+	// Resumable uploads, sent in chunks, for source PDFs too large to send in one request
+	UploadResumableMaxSizeMB int           `mapstructure:"UPLOAD_RESUMABLE_MAX_SIZE_MB"`
+	UploadChunkMaxSizeMB     int           `mapstructure:"UPLOAD_CHUNK_MAX_SIZE_MB"`
+	UploadSessionTTL         time.Duration `mapstructure:"UPLOAD_SESSION_TTL"` // Unfinished sessions and their chunks are deleted after this
+
+	// Where uploads and generated documents are kept; s3 covers Amazon S3, MinIO and
+	// Google Cloud Storage (with HMAC keys), and suits running several instances
+	StorageBackend           string        `mapstructure:"STORAGE_BACKEND"` // local or s3
+	StorageLocalDir          string        `mapstructure:"STORAGE_LOCAL_DIR"`
+	StorageS3Endpoint        string        `mapstructure:"STORAGE_S3_ENDPOINT"` // e.g. https://s3.eu-west-1.amazonaws.com
+	StorageS3Region          string        `mapstructure:"STORAGE_S3_REGION"`
+	StorageS3Bucket          string        `mapstructure:"STORAGE_S3_BUCKET"`
+	StorageS3AccessKeyID     string        `mapstructure:"STORAGE_S3_ACCESS_KEY_ID"`
+	StorageS3SecretAccessKey string        `mapstructure:"STORAGE_S3_SECRET_ACCESS_KEY"`
+	StorageS3PathStyle       bool          `mapstructure:"STORAGE_S3_PATH_STYLE"` // Needed by MinIO
+	StorageDownloadMode      string        `mapstructure:"STORAGE_DOWNLOAD_MODE"` // proxy streams documents through the API; presigned redirects to the store (s3 only)
+	StoragePresignTTL        time.Duration `mapstructure:"STORAGE_PRESIGN_TTL"`   // How long a presigned download link works
+
+	// Full-text passages of imported papers' PDFs given to literature review generation,
+	// in tokens; 0 uses abstracts only
+	PaperContextTokens int `mapstructure:"PAPER_CONTEXT_TOKENS"`
+
+	// Tracing
+	OTelEnabled          bool    `mapstructure:"OTEL_ENABLED"`
+	OTelServiceName      string  `mapstructure:"OTEL_SERVICE_NAME"`
+	OTelExporterEndpoint string  `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"` // e.g. http://otel-collector:4318
+	OTelSampleRatio      float64 `mapstructure:"OTEL_SAMPLE_RATIO"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -28,6 +233,130 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("ACCESS_TOKEN_DURATION", "15m")
 	viper.SetDefault("REFRESH_TOKEN_DURATION", "168h") // 7 days
+	viper.SetDefault("DATABASE_REPLICA_URL", "")
+	viper.SetDefault("DB_MAX_CONNS", 20)
+	viper.SetDefault("DB_MIN_CONNS", 2)
+	viper.SetDefault("DB_MAX_CONN_LIFETIME", "1h")
+	viper.SetDefault("DB_MAX_CONN_IDLE_TIME", "30m")
+	viper.SetDefault("DB_HEALTH_CHECK_PERIOD", "1m")
+	viper.SetDefault("AI_MODEL", "meta-llama/llama-4-scout-17b-16e-instruct")
+	viper.SetDefault("AI_TEMPERATURE_LITERATURE_REVIEW", 0.6)
+	viper.SetDefault("AI_TEMPERATURE_INTRODUCTION", 0.7)
+	viper.SetDefault("AI_TEMPERATURE_METHODOLOGY", 0.5)
+	viper.SetDefault("AI_TEMPERATURE_RESULTS", 0.3)
+	viper.SetDefault("AI_TEMPERATURE_CUSTOM_CHAPTER", 0.6)
+	viper.SetDefault("AI_TEMPERATURE_SECTION", 0.5)
+	viper.SetDefault("AI_CALL_TIMEOUT", "2m")
+	viper.SetDefault("AI_GENERATION_TIMEOUT", "10m")
+	viper.SetDefault("AI_CANCEL_ON_DISCONNECT", false)
+	viper.SetDefault("AI_RESPONSE_CACHE_TTL", "2m")
+	viper.SetDefault("AI_RESPONSE_CACHE_MAX_ENTRIES", 500)
+	viper.SetDefault("EMBEDDING_API_URL", "https://api.openai.com/v1/embeddings")
+	viper.SetDefault("EMBEDDING_API_KEY", "")
+	viper.SetDefault("EMBEDDING_MODEL", "text-embedding-3-small")
+	viper.SetDefault("SEMANTIC_SCHOLAR_API_URL", "https://api.semanticscholar.org/graph/v1")
+	viper.SetDefault("SEMANTIC_SCHOLAR_RECOMMENDATIONS_URL", "https://api.semanticscholar.org/recommendations/v1")
+	viper.SetDefault("SEMANTIC_SCHOLAR_API_KEY", "")
+	viper.SetDefault("TRASH_RETENTION", "720h") // 30 days
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_BACKEND", "memory")
+	viper.SetDefault("RATE_LIMIT_RPS", 5)
+	viper.SetDefault("RATE_LIMIT_BURST", 20)
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("TRUSTED_PLATFORM", "")
+	viper.SetDefault("REDIS_URL", "redis://localhost:6379/0")
+	viper.SetDefault("EVENTS_BACKEND", "memory")
+	viper.SetDefault("FEATURE_FLAGS", "")
+	viper.SetDefault("LOG_LEVELS", "")
+	viper.SetDefault("LOG_FILE_PATH", "")
+	viper.SetDefault("LOG_FILE_MAX_SIZE_MB", 100)
+	viper.SetDefault("LOG_FILE_MAX_BACKUPS", 10)
+	viper.SetDefault("LOG_FILE_MAX_AGE", "168h") // 7 days
+	viper.SetDefault("LOG_LOKI_URL", "")
+	viper.SetDefault("LOG_ELASTICSEARCH_URL", "")
+	viper.SetDefault("LOG_ELASTICSEARCH_INDEX", "research-service-logs")
+	viper.SetDefault("LOG_ELASTICSEARCH_API_KEY", "")
+	viper.SetDefault("MAIL_PROVIDER", "")
+	viper.SetDefault("MAIL_FROM", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("SMTP_FROM", "")
+	viper.SetDefault("SES_REGION", "")
+	viper.SetDefault("SES_ACCESS_KEY_ID", "")
+	viper.SetDefault("SES_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("SENDGRID_API_KEY", "")
+	viper.SetDefault("NOTIFICATION_EMAIL_INTERVAL", "30s")
+	viper.SetDefault("INVITATION_URL", "")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_DELIVERY_INTERVAL", "10s")
+	viper.SetDefault("JOBS_POLL_INTERVAL", "2s")
+	viper.SetDefault("JOBS_CONCURRENCY", 4)
+	viper.SetDefault("JOBS_RETENTION", "72h")
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE_TARGETS", false)
+	viper.SetDefault("STRIPE_SECRET_KEY", "")
+	viper.SetDefault("STRIPE_WEBHOOK_SECRET", "")
+	viper.SetDefault("STRIPE_PRICE_PRO", "")
+	viper.SetDefault("STRIPE_PRICE_INSTITUTIONAL", "")
+	viper.SetDefault("BILLING_SUCCESS_URL", "")
+	viper.SetDefault("BILLING_CANCEL_URL", "")
+	viper.SetDefault("BILLING_PORTAL_RETURN_URL", "")
+	viper.SetDefault("BILLING_FREE_MAX_PROJECTS", 1)
+	viper.SetDefault("BILLING_FREE_AI_GENERATIONS", 10)
+	viper.SetDefault("BILLING_PRO_AI_GENERATIONS", 300)
+	viper.SetDefault("QUOTA_FREE_PROJECTS", 0)
+	viper.SetDefault("QUOTA_FREE_AI_WORDS", 0)
+	viper.SetDefault("QUOTA_FREE_DOCUMENTS", 3)
+	viper.SetDefault("QUOTA_PRO_PROJECTS", 0)
+	viper.SetDefault("QUOTA_PRO_AI_WORDS", 0)
+	viper.SetDefault("QUOTA_PRO_DOCUMENTS", 0)
+	viper.SetDefault("QUOTA_INSTITUTIONAL_PROJECTS", 0)
+	viper.SetDefault("QUOTA_INSTITUTIONAL_AI_WORDS", 0)
+	viper.SetDefault("QUOTA_INSTITUTIONAL_DOCUMENTS", 0)
+	viper.SetDefault("GUEST_MODE_ENABLED", false)
+	viper.SetDefault("GUEST_TTL", "24h")
+	viper.SetDefault("GUEST_AI_WORDS", 3000)
+	viper.SetDefault("GUEST_DOCUMENTS", 1)
+	viper.SetDefault("INTEGRITY_REQUIRE_ACKNOWLEDGMENT", false)
+	viper.SetDefault("INTEGRITY_POLICY_VERSION", "1")
+	viper.SetDefault("INTEGRITY_POLICY_URL", "")
+	viper.SetDefault("INTEGRITY_WATERMARK_EXPORTS", false)
+	viper.SetDefault("INTEGRITY_BLOCK_BATCH_GENERATION", false)
+	viper.SetDefault("ADMIN_API_TOKEN", "")
+	viper.SetDefault("GRPC_ENABLED", false)
+	viper.SetDefault("GRPC_PORT", "9090")
+	viper.SetDefault("GRPC_AUTH_TOKEN", "")
+	viper.SetDefault("GRAPHQL_ENABLED", false)
+	viper.SetDefault("CIRCUIT_BREAKER_FAILURES", 5)
+	viper.SetDefault("CIRCUIT_BREAKER_OPEN_TIMEOUT", "30s")
+	viper.SetDefault("PYTHON_DOCGEN_URL", "http://localhost:8001")
+	viper.SetDefault("PYTHON_DOCGEN_SECRET", "")
+	viper.SetDefault("DOCGEN_OUTPUT_DIR", "./generated_documents")
+	viper.SetDefault("DOCGEN_TIMEOUT", "2m")
+	viper.SetDefault("DOCGEN_STARTUP_PROBE", false)
+	viper.SetDefault("READINESS_CHECK_DOCGEN", false)
+	viper.SetDefault("UPLOAD_DIR", "./uploads")
+	viper.SetDefault("UPLOAD_MAX_SIZE_MB", 25)
+	viper.SetDefault("UPLOAD_MAX_PDF_PAGES", 500)
+	viper.SetDefault("UPLOAD_RESUMABLE_MAX_SIZE_MB", 1024)
+	viper.SetDefault("UPLOAD_CHUNK_MAX_SIZE_MB", 16)
+	viper.SetDefault("UPLOAD_SESSION_TTL", "24h")
+	viper.SetDefault("STORAGE_BACKEND", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "./storage")
+	viper.SetDefault("STORAGE_S3_ENDPOINT", "")
+	viper.SetDefault("STORAGE_S3_REGION", "")
+	viper.SetDefault("STORAGE_S3_BUCKET", "")
+	viper.SetDefault("STORAGE_S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("STORAGE_S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("STORAGE_S3_PATH_STYLE", false)
+	viper.SetDefault("STORAGE_DOWNLOAD_MODE", "proxy")
+	viper.SetDefault("STORAGE_PRESIGN_TTL", "15m")
+	viper.SetDefault("PAPER_CONTEXT_TOKENS", 6000)
+	viper.SetDefault("OTEL_ENABLED", false)
+	viper.SetDefault("OTEL_SERVICE_NAME", "research-service")
+	viper.SetDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	viper.SetDefault("OTEL_SAMPLE_RATIO", 1.0)
 
 	err = viper.ReadInConfig() // Attempt to read config file (e.g., app.env if AddConfigPath and SetConfigName match)
 	if err != nil {
@@ -40,5 +369,64 @@ func LoadConfig(path string) (config Config, err error) {
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsResolveTimeout)
+	defer cancel()
+	err = resolveSecrets(ctx, &config, secretResolver())
 	return
 }
+
+// secretResolver is shared so each secret is fetched once per process
+var secretResolver = sync.OnceValue(secrets.NewResolverFromEnv)
+
+// secretsResolveTimeout bounds how long startup may wait on secret stores
+const secretsResolveTimeout = 30 * time.Second
+
+// resolveSecrets replaces every string setting holding a secret reference
+// (e.g. vault:secret/data/research#OPENAI_API_KEY) with the secret's value
+func resolveSecrets(ctx context.Context, config *Config, resolver *secrets.Resolver) error {
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() != reflect.String || !resolver.IsReference(field.String()) {
+			continue
+		}
+		value, err := resolver.Resolve(ctx, field.String())
+		if err != nil {
+			return fmt.Errorf("cannot resolve %s: %w", t.Field(i).Tag.Get("mapstructure"), err)
+		}
+		field.SetString(value)
+	}
+	return nil
+}
+
+// TrustedProxyList is TRUSTED_PROXIES split into its entries, nil when it is empty
+func (c Config) TrustedProxyList() []string {
+	var proxies []string
+	for _, p := range strings.Split(c.TrustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// EmailProvider is MAIL_PROVIDER, or smtp when only SMTP_HOST is set; "" means email is off
+func (c Config) EmailProvider() string {
+	if c.MailProvider == "" && c.SMTPHost != "" {
+		return "smtp"
+	}
+	return c.MailProvider
+}
+
+// EmailFrom is MAIL_FROM, falling back to SMTP_FROM from before providers were configurable
+func (c Config) EmailFrom() string {
+	if c.MailFrom != "" {
+		return c.MailFrom
+	}
+	return c.SMTPFrom
+}
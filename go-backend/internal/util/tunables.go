@@ -0,0 +1,208 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Tunables are the settings that can change without a restart.
+// Everything else in Config is read once at startup.
+type Tunables struct {
+	AIModel                       string
+	AITemperatureLiteratureReview float64
+	AITemperatureIntroduction     float64
+	AITemperatureMethodology      float64
+	AITemperatureResults          float64
+	AITemperatureCustomChapter    float64
+	AITemperatureSection          float64
+	RateLimitRPS                  float64
+	RateLimitBurst                int
+}
+
+// Tunables extracts the runtime-adjustable subset of the config
+func (c Config) Tunables() Tunables {
+	return Tunables{
+		AIModel:                       c.AIModel,
+		AITemperatureLiteratureReview: c.AITemperatureLiteratureReview,
+		AITemperatureIntroduction:     c.AITemperatureIntroduction,
+		AITemperatureMethodology:      c.AITemperatureMethodology,
+		AITemperatureResults:          c.AITemperatureResults,
+		AITemperatureCustomChapter:    c.AITemperatureCustomChapter,
+		AITemperatureSection:          c.AITemperatureSection,
+		RateLimitRPS:                  c.RateLimitRPS,
+		RateLimitBurst:                c.RateLimitBurst,
+	}
+}
+
+func (c Config) withTunables(t Tunables) Config {
+	c.AIModel = t.AIModel
+	c.AITemperatureLiteratureReview = t.AITemperatureLiteratureReview
+	c.AITemperatureIntroduction = t.AITemperatureIntroduction
+	c.AITemperatureMethodology = t.AITemperatureMethodology
+	c.AITemperatureResults = t.AITemperatureResults
+	c.AITemperatureCustomChapter = t.AITemperatureCustomChapter
+	c.AITemperatureSection = t.AITemperatureSection
+	c.RateLimitRPS = t.RateLimitRPS
+	c.RateLimitBurst = t.RateLimitBurst
+	return c
+}
+
+// tunableKeys are the Config fields covered by Tunables, by mapstructure key
+var tunableKeys = map[string]bool{
+	"AI_MODEL":                         true,
+	"AI_TEMPERATURE_LITERATURE_REVIEW": true,
+	"AI_TEMPERATURE_INTRODUCTION":      true,
+	"AI_TEMPERATURE_METHODOLOGY":       true,
+	"AI_TEMPERATURE_RESULTS":           true,
+	"AI_TEMPERATURE_CUSTOM_CHAPTER":    true,
+	"AI_TEMPERATURE_SECTION":           true,
+	"RATE_LIMIT_RPS":                   true,
+	"RATE_LIMIT_BURST":                 true,
+}
+
+// LiveTunables holds the current Tunables. Readers always see a complete,
+// consistent snapshot; a reload swaps the whole value at once.
+type LiveTunables struct {
+	current atomic.Pointer[Tunables]
+
+	mu        sync.Mutex
+	listeners []func(old, new Tunables)
+}
+
+func NewLiveTunables(t Tunables) *LiveTunables {
+	l := &LiveTunables{}
+	l.current.Store(&t)
+	return l
+}
+
+// Load returns the current snapshot
+func (l *LiveTunables) Load() Tunables {
+	return *l.current.Load()
+}
+
+// OnChange registers fn to run after every update that changes a value
+func (l *LiveTunables) OnChange(fn func(old, new Tunables)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.listeners = append(l.listeners, fn)
+}
+
+// Store swaps in t and notifies listeners when anything changed
+func (l *LiveTunables) Store(t Tunables) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.current.Swap(&t)
+	if *old == t {
+		return
+	}
+	for _, fn := range l.listeners {
+		fn(*old, t)
+	}
+}
+
+// WatchConfig reloads tunable settings when the config file changes or the
+// process receives SIGHUP. The reloaded config is validated first; an invalid
+// config is rejected as a whole and the running values are kept. Changes to
+// settings outside Tunables are logged and ignored until the next restart.
+func WatchConfig(startup Config, live *LiveTunables, logger *applogger.AppLogger) {
+	var mu sync.Mutex // viper is not safe for concurrent reloads
+	reload := func(trigger string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		next, err := reloadConfig()
+		if err == nil {
+			// Validate the config that will actually be in effect: startup values plus new tunables
+			err = startup.withTunables(next.Tunables()).Validate()
+		}
+		if err != nil {
+			logger.Error("Config reload rejected, keeping current settings", "trigger", trigger, "error", err)
+			return
+		}
+		if ignored := restartOnlyChanges(startup, next); len(ignored) > 0 {
+			logger.Warn("Config changes need a restart to take effect", "trigger", trigger, "keys", ignored)
+		}
+
+		old := live.Load()
+		updated := next.Tunables()
+		changes := tunableChanges(old, updated)
+		if len(changes) == 0 {
+			logger.Info("Config reloaded, no tunable settings changed", "trigger", trigger)
+			return
+		}
+		live.Store(updated)
+		logger.Info("Config reloaded", "trigger", trigger, "changes", changes)
+	}
+
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(e fsnotify.Event) { reload("file:" + e.Name) })
+		viper.WatchConfig()
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reload("SIGHUP")
+		}
+	}()
+}
+
+func reloadConfig() (Config, error) {
+	var config Config
+	if viper.ConfigFileUsed() != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			return config, fmt.Errorf("cannot read config file: %w", err)
+		}
+	}
+	if err := viper.Unmarshal(&config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// restartOnlyChanges lists the keys outside Tunables whose value differs from startup.
+// Values still holding secret references are compared as references, so resolved
+// secrets never show up as changes.
+func restartOnlyChanges(startup, next Config) []string {
+	var keys []string
+	a, b := reflect.ValueOf(startup), reflect.ValueOf(next)
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if tunableKeys[key] {
+			continue
+		}
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) && !looksLikeSecretReference(b.Field(i)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func looksLikeSecretReference(v reflect.Value) bool {
+	return v.Kind() == reflect.String && secretResolver().IsReference(v.String())
+}
+
+// tunableChanges describes each changed tunable as "FIELD: old -> new"
+func tunableChanges(old, updated Tunables) []string {
+	var changes []string
+	a, b := reflect.ValueOf(old), reflect.ValueOf(updated)
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", t.Field(i).Name, a.Field(i).Interface(), b.Field(i).Interface()))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,415 @@
+package util
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	applogger "github.com/shawgichan/research-service/go-backend/internal/logger"
+)
+
+// tokenSecretKeySize is the symmetric key size PASETO v2 local tokens require (chacha20poly1305.KeySize)
+const tokenSecretKeySize = 32
+
+// ConfigError lists every problem found by Config.Validate
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate checks required keys, key lengths, URL formats and value ranges.
+// It reports all problems at once so a broken deployment can be fixed in one pass.
+func (c Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.DatabaseURL == "" {
+		add("DATABASE_URL is required")
+	} else if err := validatePostgresURL(c.DatabaseURL); err != nil {
+		add("DATABASE_URL %v", err)
+	}
+	if c.DatabaseReplicaURL != "" {
+		if err := validatePostgresURL(c.DatabaseReplicaURL); err != nil {
+			add("DATABASE_REPLICA_URL %v", err)
+		}
+	}
+
+	if c.TokenSecretKey == "" {
+		add("TOKEN_SECRET_KEY is required")
+	} else if len(c.TokenSecretKey) != tokenSecretKeySize {
+		add("TOKEN_SECRET_KEY must be exactly %d characters, got %d", tokenSecretKeySize, len(c.TokenSecretKey))
+	}
+	if c.AccessTokenDuration <= 0 {
+		add("ACCESS_TOKEN_DURATION must be positive")
+	}
+	if c.RefreshTokenDuration <= c.AccessTokenDuration {
+		add("REFRESH_TOKEN_DURATION must be longer than ACCESS_TOKEN_DURATION")
+	}
+	if c.OpenAIAPIKey == "" && c.Environment == "production" {
+		add("OPENAI_API_KEY is required in production")
+	}
+
+	if !validPort(c.Port) {
+		add("PORT must be a number between 1 and 65535, got %q", c.Port)
+	}
+
+	if c.DBMaxConns < 1 {
+		add("DB_MAX_CONNS must be at least 1")
+	}
+	if c.DBMinConns < 0 || c.DBMinConns > c.DBMaxConns {
+		add("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS")
+	}
+	if c.DBMaxConnLifetime < 0 || c.DBMaxConnIdleTime < 0 || c.DBHealthCheckPeriod < 0 {
+		add("DB_MAX_CONN_LIFETIME, DB_MAX_CONN_IDLE_TIME and DB_HEALTH_CHECK_PERIOD must not be negative")
+	}
+
+	if c.AIModel == "" {
+		add("AI_MODEL is required")
+	}
+	for key, t := range map[string]float64{
+		"AI_TEMPERATURE_LITERATURE_REVIEW": c.AITemperatureLiteratureReview,
+		"AI_TEMPERATURE_INTRODUCTION":      c.AITemperatureIntroduction,
+		"AI_TEMPERATURE_METHODOLOGY":       c.AITemperatureMethodology,
+		"AI_TEMPERATURE_RESULTS":           c.AITemperatureResults,
+		"AI_TEMPERATURE_CUSTOM_CHAPTER":    c.AITemperatureCustomChapter,
+		"AI_TEMPERATURE_SECTION":           c.AITemperatureSection,
+	} {
+		if t < 0 || t > 2 {
+			add("%s must be between 0 and 2", key)
+		}
+	}
+	if c.AICallTimeout <= 0 || c.AIGenerationTimeout <= 0 {
+		add("AI_CALL_TIMEOUT and AI_GENERATION_TIMEOUT must be positive")
+	} else if c.AICallTimeout > c.AIGenerationTimeout {
+		add("AI_CALL_TIMEOUT must not exceed AI_GENERATION_TIMEOUT")
+	}
+	if c.AIResponseCacheTTL < 0 {
+		add("AI_RESPONSE_CACHE_TTL must not be negative")
+	}
+	if c.AIResponseCacheTTL > 0 && c.AIResponseCacheMaxEntries < 1 {
+		add("AI_RESPONSE_CACHE_MAX_ENTRIES must be at least 1 when AI_RESPONSE_CACHE_TTL is set")
+	}
+
+	if c.EmbeddingAPIKey != "" {
+		if err := validateHTTPURL(c.EmbeddingAPIURL); err != nil {
+			add("EMBEDDING_API_URL %v", err)
+		}
+		if c.EmbeddingModel == "" {
+			add("EMBEDDING_MODEL is required when EMBEDDING_API_KEY is set")
+		}
+	}
+
+	if err := validateHTTPURL(c.SemanticScholarAPIURL); err != nil {
+		add("SEMANTIC_SCHOLAR_API_URL %v", err)
+	}
+	if err := validateHTTPURL(c.SemanticScholarRecommendationsURL); err != nil {
+		add("SEMANTIC_SCHOLAR_RECOMMENDATIONS_URL %v", err)
+	}
+
+	if c.TrashRetention <= 0 {
+		add("TRASH_RETENTION must be positive")
+	}
+
+	for _, proxy := range c.TrustedProxyList() {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				add("TRUSTED_PROXIES must list IPs or CIDRs, got %q", proxy)
+			}
+		}
+	}
+
+	if !slices.Contains([]string{"memory", "redis"}, c.RateLimitBackend) {
+		add("RATE_LIMIT_BACKEND must be memory or redis, got %q", c.RateLimitBackend)
+	}
+	if c.RateLimitEnabled {
+		if c.RateLimitRPS <= 0 {
+			add("RATE_LIMIT_RPS must be positive")
+		}
+		if c.RateLimitBurst < 1 {
+			add("RATE_LIMIT_BURST must be at least 1")
+		}
+		if c.RateLimitBackend == "redis" {
+			if err := validateURL(c.RedisURL, "redis", "rediss"); err != nil {
+				add("REDIS_URL %v", err)
+			}
+		}
+	}
+
+	if !slices.Contains([]string{"memory", "postgres"}, c.EventsBackend) {
+		add("EVENTS_BACKEND must be memory or postgres, got %q", c.EventsBackend)
+	}
+
+	if c.CircuitBreakerFailures <= 0 {
+		add("CIRCUIT_BREAKER_FAILURES must be positive")
+	}
+	if c.CircuitBreakerOpenTimeout <= 0 {
+		add("CIRCUIT_BREAKER_OPEN_TIMEOUT must be positive")
+	}
+
+	if err := validateHTTPURL(c.PythonDocGenURL); err != nil {
+		add("PYTHON_DOCGEN_URL %v", err)
+	}
+	if c.DocGenOutputDir == "" {
+		add("DOCGEN_OUTPUT_DIR is required")
+	}
+	if c.DocGenTimeout <= 0 {
+		add("DOCGEN_TIMEOUT must be positive")
+	}
+	if c.PythonDocGenSecret == "" && c.Environment == "production" {
+		add("PYTHON_DOCGEN_SECRET is required in production")
+	}
+	if c.UploadDir == "" {
+		add("UPLOAD_DIR is required")
+	}
+	if c.UploadMaxSizeMB <= 0 {
+		add("UPLOAD_MAX_SIZE_MB must be positive")
+	}
+	if c.UploadMaxPDFPages < 0 {
+		add("UPLOAD_MAX_PDF_PAGES must not be negative")
+	}
+	if c.UploadResumableMaxSizeMB < c.UploadMaxSizeMB {
+		add("UPLOAD_RESUMABLE_MAX_SIZE_MB must be at least UPLOAD_MAX_SIZE_MB")
+	}
+	if c.UploadChunkMaxSizeMB <= 0 {
+		add("UPLOAD_CHUNK_MAX_SIZE_MB must be positive")
+	}
+	if c.UploadSessionTTL <= 0 {
+		add("UPLOAD_SESSION_TTL must be positive")
+	}
+	switch c.StorageBackend {
+	case "local":
+		if c.StorageLocalDir == "" {
+			add("STORAGE_LOCAL_DIR is required when STORAGE_BACKEND is local")
+		}
+	case "s3":
+		if err := validateHTTPURL(c.StorageS3Endpoint); err != nil {
+			add("STORAGE_S3_ENDPOINT %v", err)
+		}
+		if c.StorageS3Bucket == "" || c.StorageS3AccessKeyID == "" || c.StorageS3SecretAccessKey == "" {
+			add("STORAGE_S3_BUCKET, STORAGE_S3_ACCESS_KEY_ID and STORAGE_S3_SECRET_ACCESS_KEY are required when STORAGE_BACKEND is s3")
+		}
+	default:
+		add("STORAGE_BACKEND must be local or s3, got %q", c.StorageBackend)
+	}
+	switch c.StorageDownloadMode {
+	case "proxy":
+	case "presigned":
+		if c.StorageBackend != "s3" {
+			add("STORAGE_DOWNLOAD_MODE presigned needs STORAGE_BACKEND s3")
+		}
+		if c.StoragePresignTTL < time.Second || c.StoragePresignTTL > 7*24*time.Hour {
+			add("STORAGE_PRESIGN_TTL must be between 1s and 168h")
+		}
+	default:
+		add("STORAGE_DOWNLOAD_MODE must be proxy or presigned, got %q", c.StorageDownloadMode)
+	}
+	if c.PaperContextTokens < 0 {
+		add("PAPER_CONTEXT_TOKENS must not be negative")
+	}
+
+	if err := applogger.NewLevels(slog.LevelInfo).Configure(c.LogLevels); err != nil {
+		add("LOG_LEVELS %v", err)
+	}
+	if c.LogFilePath != "" {
+		if c.LogFileMaxSizeMB < 1 {
+			add("LOG_FILE_MAX_SIZE_MB must be at least 1")
+		}
+		if c.LogFileMaxBackups < 0 || c.LogFileMaxAge < 0 {
+			add("LOG_FILE_MAX_BACKUPS and LOG_FILE_MAX_AGE must not be negative")
+		}
+	}
+	if c.LogLokiURL != "" {
+		if err := validateHTTPURL(c.LogLokiURL); err != nil {
+			add("LOG_LOKI_URL %v", err)
+		}
+	}
+	if c.LogElasticsearchURL != "" {
+		if err := validateHTTPURL(c.LogElasticsearchURL); err != nil {
+			add("LOG_ELASTICSEARCH_URL %v", err)
+		}
+		if c.LogElasticsearchIndex == "" {
+			add("LOG_ELASTICSEARCH_INDEX is required when LOG_ELASTICSEARCH_URL is set")
+		}
+	}
+
+	switch provider := c.EmailProvider(); provider {
+	case "":
+	case "smtp", "ses", "sendgrid":
+		if _, err := mail.ParseAddress(c.EmailFrom()); err != nil {
+			add("MAIL_FROM must be an email address when email is enabled, got %q", c.EmailFrom())
+		}
+		if c.NotificationEmailInterval <= 0 {
+			add("NOTIFICATION_EMAIL_INTERVAL must be positive")
+		}
+		if c.InvitationURL != "" {
+			if err := validateHTTPURL(c.InvitationURL); err != nil {
+				add("INVITATION_URL %v", err)
+			}
+		}
+		switch provider {
+		case "smtp":
+			if c.SMTPHost == "" {
+				add("SMTP_HOST is required when MAIL_PROVIDER is smtp")
+			}
+			if !validPort(c.SMTPPort) {
+				add("SMTP_PORT must be a number between 1 and 65535, got %q", c.SMTPPort)
+			}
+		case "ses":
+			if c.SESRegion == "" || c.SESAccessKeyID == "" || c.SESSecretAccessKey == "" {
+				add("SES_REGION, SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY are required when MAIL_PROVIDER is ses")
+			}
+		case "sendgrid":
+			if c.SendGridAPIKey == "" {
+				add("SENDGRID_API_KEY is required when MAIL_PROVIDER is sendgrid")
+			}
+		}
+	default:
+		add("MAIL_PROVIDER must be smtp, ses or sendgrid, got %q", provider)
+	}
+
+	if c.WebhookTimeout <= 0 {
+		add("WEBHOOK_TIMEOUT must be positive")
+	}
+	if c.WebhookDeliveryInterval <= 0 {
+		add("WEBHOOK_DELIVERY_INTERVAL must be positive")
+	}
+
+	if c.JobsPollInterval <= 0 || c.JobsRetention <= 0 {
+		add("JOBS_POLL_INTERVAL and JOBS_RETENTION must be positive")
+	}
+	if c.JobsConcurrency < 1 {
+		add("JOBS_CONCURRENCY must be at least 1")
+	}
+
+	if c.StripeSecretKey != "" {
+		if c.StripeWebhookSecret == "" {
+			add("STRIPE_WEBHOOK_SECRET is required when STRIPE_SECRET_KEY is set")
+		}
+		if c.StripePricePro == "" && c.StripePriceInstitutional == "" {
+			add("STRIPE_PRICE_PRO or STRIPE_PRICE_INSTITUTIONAL is required when STRIPE_SECRET_KEY is set")
+		}
+		for key, raw := range map[string]string{
+			"BILLING_SUCCESS_URL":       c.BillingSuccessURL,
+			"BILLING_CANCEL_URL":        c.BillingCancelURL,
+			"BILLING_PORTAL_RETURN_URL": c.BillingPortalReturnURL,
+		} {
+			if err := validateHTTPURL(raw); err != nil {
+				add("%s %v", key, err)
+			}
+		}
+		if c.BillingFreeMaxProjects < 0 || c.BillingFreeAIGenerations < 0 || c.BillingProAIGenerations < 0 {
+			add("BILLING_FREE_MAX_PROJECTS, BILLING_FREE_AI_GENERATIONS and BILLING_PRO_AI_GENERATIONS must not be negative")
+		}
+	}
+	for key, quota := range map[string]int64{
+		"QUOTA_FREE_PROJECTS":           c.QuotaFreeProjects,
+		"QUOTA_FREE_AI_WORDS":           c.QuotaFreeAIWords,
+		"QUOTA_FREE_DOCUMENTS":          c.QuotaFreeDocuments,
+		"QUOTA_PRO_PROJECTS":            c.QuotaProProjects,
+		"QUOTA_PRO_AI_WORDS":            c.QuotaProAIWords,
+		"QUOTA_PRO_DOCUMENTS":           c.QuotaProDocuments,
+		"QUOTA_INSTITUTIONAL_PROJECTS":  c.QuotaInstitutionalProjects,
+		"QUOTA_INSTITUTIONAL_AI_WORDS":  c.QuotaInstitutionalAIWords,
+		"QUOTA_INSTITUTIONAL_DOCUMENTS": c.QuotaInstitutionalDocuments,
+	} {
+		if quota < 0 {
+			add("%s must not be negative", key)
+		}
+	}
+
+	if c.GuestModeEnabled {
+		if c.GuestTTL <= 0 {
+			add("GUEST_TTL must be positive when GUEST_MODE_ENABLED is set")
+		}
+		// 0 would be unlimited, which a trial never is
+		if c.GuestAIWords <= 0 || c.GuestDocuments <= 0 {
+			add("GUEST_AI_WORDS and GUEST_DOCUMENTS must be positive when GUEST_MODE_ENABLED is set")
+		}
+	}
+
+	if strings.TrimSpace(c.IntegrityPolicyVersion) == "" {
+		add("INTEGRITY_POLICY_VERSION is required")
+	} else if len(c.IntegrityPolicyVersion) > 50 {
+		add("INTEGRITY_POLICY_VERSION must be at most 50 characters")
+	}
+	if c.IntegrityPolicyURL != "" {
+		if err := validateHTTPURL(c.IntegrityPolicyURL); err != nil {
+			add("INTEGRITY_POLICY_URL %v", err)
+		}
+	}
+
+	if c.AdminAPIToken != "" && len(c.AdminAPIToken) < 32 {
+		add("ADMIN_API_TOKEN must be at least 32 characters")
+	}
+
+	if c.GRPCEnabled {
+		if !validPort(c.GRPCPort) {
+			add("GRPC_PORT must be a number between 1 and 65535, got %q", c.GRPCPort)
+		} else if c.GRPCPort == c.Port {
+			add("GRPC_PORT must differ from PORT")
+		}
+		if c.GRPCAuthToken == "" {
+			add("GRPC_AUTH_TOKEN is required when GRPC_ENABLED is true")
+		}
+	}
+
+	if c.OTelEnabled && c.OTelExporterEndpoint != "" {
+		if err := validateHTTPURL(c.OTelExporterEndpoint); err != nil {
+			add("OTEL_EXPORTER_OTLP_ENDPOINT %v", err)
+		}
+	}
+	if c.OTelSampleRatio < 0 || c.OTelSampleRatio > 1 {
+		add("OTEL_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// validatePostgresURL accepts postgres:// URLs and libpq key=value connection strings
+func validatePostgresURL(raw string) error {
+	if !strings.Contains(raw, "://") {
+		if !strings.Contains(raw, "=") {
+			return fmt.Errorf("must be a postgres:// URL or a key=value connection string")
+		}
+		return nil
+	}
+	return validateURL(raw, "postgres", "postgresql")
+}
+
+func validateHTTPURL(raw string) error {
+	return validateURL(raw, "http", "https")
+}
+
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		// url.Error embeds the raw URL, which may carry credentials
+		return fmt.Errorf("is not a valid URL")
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("must use one of the schemes %s, got %q", strings.Join(schemes, ", "), u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("is missing a host")
+	}
+	return nil
+}
@@ -3,19 +3,35 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 
 	"github.com/shawgichan/research-service/go-backend/internal/api"
+	"github.com/shawgichan/research-service/go-backend/internal/billing"
+	"github.com/shawgichan/research-service/go-backend/internal/breaker"
 	"github.com/shawgichan/research-service/go-backend/internal/db"
+	"github.com/shawgichan/research-service/go-backend/internal/events"
+	"github.com/shawgichan/research-service/go-backend/internal/flags"
+	"github.com/shawgichan/research-service/go-backend/internal/grpcapi"
+	"github.com/shawgichan/research-service/go-backend/internal/jobs"
 	applogger "github.com/shawgichan/research-service/go-backend/internal/logger" // aliased to avoid conflict
+	"github.com/shawgichan/research-service/go-backend/internal/mail"
+	"github.com/shawgichan/research-service/go-backend/internal/ratelimit"
 	"github.com/shawgichan/research-service/go-backend/internal/services"
+	"github.com/shawgichan/research-service/go-backend/internal/storage"
+	"github.com/shawgichan/research-service/go-backend/internal/telemetry"
 	"github.com/shawgichan/research-service/go-backend/internal/token"
 	"github.com/shawgichan/research-service/go-backend/internal/util"
 )
@@ -37,6 +53,26 @@ func main() {
 	if err != nil {
 		logger.Fatal("Cannot load config:", err)
 	}
+	if err := config.Validate(); err != nil {
+		logger.Fatal("Cannot start with invalid config:", err)
+	}
+	if err := logger.Levels().Configure(config.LogLevels); err != nil {
+		logger.Fatal("Cannot apply LOG_LEVELS:", err)
+	}
+	if err := logger.AddSinks(applogger.SinkConfig{
+		FilePath:            config.LogFilePath,
+		FileMaxSizeMB:       config.LogFileMaxSizeMB,
+		FileMaxBackups:      config.LogFileMaxBackups,
+		FileMaxAge:          config.LogFileMaxAge,
+		LokiURL:             config.LogLokiURL,
+		ElasticsearchURL:    config.LogElasticsearchURL,
+		ElasticsearchIndex:  config.LogElasticsearchIndex,
+		ElasticsearchAPIKey: config.LogElasticsearchAPIKey,
+		Labels:              map[string]string{"service": config.OTelServiceName, "environment": config.Environment},
+	}); err != nil {
+		logger.Fatal("Cannot set up log sinks:", err)
+	}
+	defer logger.Close()
 
 	if config.Environment == "development" {
 		gin.SetMode(gin.DebugMode)
@@ -44,15 +80,62 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Initialize tracing (no-op unless OTEL_ENABLED=true)
+	shutdownTracer, err := telemetry.InitTracer(context.Background(), config)
+	if err != nil {
+		logger.Fatal("Cannot initialize tracer:", err)
+	}
+	defer func() {
+		if err := shutdownTracer(context.Background()); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// Initialize database connection pool
-	connPool, err := db.ConnectDB(config.DatabaseURL)
+	poolConfig := db.PoolConfig{
+		MaxConns:          config.DBMaxConns,
+		MinConns:          config.DBMinConns,
+		MaxConnLifetime:   config.DBMaxConnLifetime,
+		MaxConnIdleTime:   config.DBMaxConnIdleTime,
+		HealthCheckPeriod: config.DBHealthCheckPeriod,
+	}
+	connPool, err := db.ConnectDB(config.DatabaseURL, poolConfig, logger.For("db"))
 	if err != nil {
 		logger.Fatal("Cannot connect to database:", err)
 	}
 	defer connPool.Close()
 
+	// Optional read replica for list/get/search queries
+	var replicaPool *pgxpool.Pool
+	if config.DatabaseReplicaURL != "" {
+		replicaPool, err = db.ConnectDB(config.DatabaseReplicaURL, poolConfig, logger.For("db.replica"))
+		if err != nil {
+			logger.Fatal("Cannot connect to read replica:", err)
+		}
+		defer replicaPool.Close()
+		logger.Info("Routing read-only queries to the read replica")
+	}
+
 	// Create a new store with the connection pool
-	store := db.NewStore(connPool)
+	store := db.NewStore(connPool, replicaPool)
+
+	// Background jobs, persisted in the jobs table; handlers are registered below
+	jobQueue := jobs.New(store, jobs.Config{
+		PollInterval: config.JobsPollInterval,
+		Concurrency:  config.JobsConcurrency,
+		Retention:    config.JobsRetention,
+	}, logger.For("jobs"))
+
+	// Periodically drop expired idempotency keys
+	jobQueue.Register("idempotency_keys.purge", jobs.Options{MaxAttempts: 1}, func(ctx context.Context, _ jobs.Job) error {
+		cutoff := pgtype.Timestamptz{Time: time.Now().Add(-api.IdempotencyKeyTTL), Valid: true}
+		return store.DeleteExpiredIdempotencyKeys(ctx, cutoff)
+	})
+	jobQueue.Periodic("idempotency_keys.purge", time.Hour)
+
+	// Settings that can be reloaded at runtime (app.env changes or SIGHUP)
+	tunables := util.NewLiveTunables(config.Tunables())
+	util.WatchConfig(config, tunables, logger)
 
 	// Initialize token maker
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSecretKey)
@@ -61,12 +144,201 @@ func main() {
 	}
 
 	// Initialize services
-	aiSvc := services.NewAIService(config.OpenAIAPIKey, logger)
-	authSvc := services.NewAuthService(store, tokenMaker, config, logger)
-	researchSvc := services.NewResearchService(store, aiSvc, logger) // Pass logger
+	// Each outside service gets its own circuit; they share the thresholds
+	circuits := breaker.Config{
+		FailureThreshold: config.CircuitBreakerFailures,
+		OpenTimeout:      config.CircuitBreakerOpenTimeout,
+	}
+	generationTimeouts := services.GenerationTimeouts{
+		Call:               config.AICallTimeout,
+		Generation:         config.AIGenerationTimeout,
+		CancelOnDisconnect: config.AICancelOnDisconnect,
+	}
+	aiSvc := services.NewAIService(config.OpenAIAPIKey, services.EmbeddingConfig{
+		APIURL: config.EmbeddingAPIURL,
+		APIKey: config.EmbeddingAPIKey,
+		Model:  config.EmbeddingModel,
+	}, services.ScholarConfig{
+		APIURL:             config.SemanticScholarAPIURL,
+		RecommendationsURL: config.SemanticScholarRecommendationsURL,
+		APIKey:             config.SemanticScholarAPIKey,
+	}, circuits, generationTimeouts, services.ResponseCacheConfig{
+		TTL:        config.AIResponseCacheTTL,
+		MaxEntries: config.AIResponseCacheMaxEntries,
+	}, tunables, logger.For("services.ai"))
+	authSvc := services.NewAuthService(store, tokenMaker, config, logger.For("services.auth"))
+
+	// Progress events pushed to WebSocket clients. The postgres backend relays them
+	// through LISTEN/NOTIFY so events from other processes reach this one too.
+	var eventBus *events.Bus
+	switch config.EventsBackend {
+	case "memory":
+		eventBus = events.NewBus()
+	case "postgres":
+		eventBus = events.NewPostgresBus(context.Background(), connPool, logger.For("events"))
+	default:
+		logger.Fatal("Cannot create event bus:", fmt.Errorf("unknown events backend %q", config.EventsBackend))
+	}
+
+	// Feature flags (FEATURE_FLAGS defaults, runtime overrides from the database)
+	featureFlags, err := flags.New(context.Background(), store, config.FeatureFlags, logger.For("flags"))
+	if err != nil {
+		logger.Fatal("Cannot load feature flags:", err)
+	}
+	go featureFlags.Watch(context.Background())
+
+	docgenConfig := services.DocGenConfig{
+		URL:          strings.TrimRight(config.PythonDocGenURL, "/"),
+		SharedSecret: config.PythonDocGenSecret,
+		OutputDir:    config.DocGenOutputDir,
+		Timeout:      config.DocGenTimeout,
+		Circuit:      circuits,
+	}
+	if config.StorageDownloadMode == "presigned" {
+		docgenConfig.PresignTTL = config.StoragePresignTTL
+	}
+	uploadConfig := services.UploadConfig{
+		Dir:                config.UploadDir,
+		MaxSize:            int64(config.UploadMaxSizeMB) << 20,
+		MaxPDFPages:        config.UploadMaxPDFPages,
+		ResumableMaxSize:   int64(config.UploadResumableMaxSizeMB) << 20,
+		ChunkMaxSize:       int64(config.UploadChunkMaxSizeMB) << 20,
+		SessionTTL:         config.UploadSessionTTL,
+		PaperContextTokens: config.PaperContextTokens,
+	}
+	// Uploads and generated documents; an object store lets several instances share them
+	fileStorage, err := storage.New(storage.Config{
+		Backend:  config.StorageBackend,
+		LocalDir: config.StorageLocalDir,
+		S3: storage.S3Config{
+			Endpoint:        config.StorageS3Endpoint,
+			Region:          config.StorageS3Region,
+			Bucket:          config.StorageS3Bucket,
+			AccessKeyID:     config.StorageS3AccessKeyID,
+			SecretAccessKey: config.StorageS3SecretAccessKey,
+			PathStyle:       config.StorageS3PathStyle,
+		},
+	})
+	if err != nil {
+		logger.Fatal("Cannot create file storage:", err)
+	}
+	// In-app notifications, plus email when a mail provider is configured
+	mailer, err := mail.NewSender(mail.Config{
+		Provider: config.EmailProvider(),
+		From:     config.EmailFrom(),
+		SMTP: mail.SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+		},
+		SES: mail.SESConfig{
+			Region:          config.SESRegion,
+			AccessKeyID:     config.SESAccessKeyID,
+			SecretAccessKey: config.SESSecretAccessKey,
+		},
+		SendGrid: mail.SendGridConfig{APIKey: config.SendGridAPIKey},
+	})
+	if err != nil {
+		logger.Fatal("Cannot create mail sender:", err)
+	}
+	notificationSvc := services.NewNotificationService(store, mailer, logger.For("services.notifications"))
+	notificationSvc.RegisterJobs(jobQueue, config.NotificationEmailInterval)
+
+	webhookSvc := services.NewWebhookService(store, services.WebhookConfig{
+		Timeout:             config.WebhookTimeout,
+		AllowPrivateTargets: config.WebhookAllowPrivateTargets,
+	}, logger.For("services.webhooks"))
+	go deliverWebhooks(webhookSvc, config.WebhookDeliveryInterval, logger)
+
+	// Billing and plan limits, only when Stripe is configured
+	var stripeClient *billing.Client
+	if config.StripeSecretKey != "" {
+		stripeClient = billing.NewClient(config.StripeSecretKey)
+	}
+	billingSvc := services.NewBillingService(store, stripeClient, services.BillingConfig{
+		WebhookSecret: config.StripeWebhookSecret,
+		Prices: map[string]string{
+			services.PlanPro:           config.StripePricePro,
+			services.PlanInstitutional: config.StripePriceInstitutional,
+		},
+		SuccessURL:      config.BillingSuccessURL,
+		CancelURL:       config.BillingCancelURL,
+		PortalReturnURL: config.BillingPortalReturnURL,
+		Limits: map[string]services.PlanLimits{
+			services.PlanFree: {
+				MaxProjects:           config.BillingFreeMaxProjects,
+				AIGenerationsPerMonth: config.BillingFreeAIGenerations,
+			},
+			services.PlanPro:           {AIGenerationsPerMonth: config.BillingProAIGenerations},
+			services.PlanInstitutional: {},
+			services.PlanGuest:         {MaxProjects: 1},
+		},
+	}, logger.For("services.billing"))
+	quotaSvc := services.NewQuotaService(store, billingSvc, map[string]services.QuotaLimits{
+		services.PlanFree: {
+			Projects:  config.QuotaFreeProjects,
+			AIWords:   config.QuotaFreeAIWords,
+			Documents: config.QuotaFreeDocuments,
+		},
+		services.PlanPro: {
+			Projects:  config.QuotaProProjects,
+			AIWords:   config.QuotaProAIWords,
+			Documents: config.QuotaProDocuments,
+		},
+		services.PlanInstitutional: {
+			Projects:  config.QuotaInstitutionalProjects,
+			AIWords:   config.QuotaInstitutionalAIWords,
+			Documents: config.QuotaInstitutionalDocuments,
+		},
+		services.PlanGuest: {
+			Projects:  1,
+			AIWords:   config.GuestAIWords,
+			Documents: config.GuestDocuments,
+		},
+	}, logger.For("services.quotas"))
+
+	orgSvc := services.NewOrganizationService(store, mailer, config.InvitationURL, logger.For("services.organization"))
+	integrityPolicy := services.IntegrityPolicy{
+		RequireAcknowledgment: config.IntegrityRequireAcknowledgment,
+		Version:               config.IntegrityPolicyVersion,
+		URL:                   config.IntegrityPolicyURL,
+		WatermarkExports:      config.IntegrityWatermarkExports,
+		BlockBatchGeneration:  config.IntegrityBlockBatchGeneration,
+	}
+	researchSvc := services.NewResearchService(store, aiSvc, eventBus, notificationSvc, webhookSvc, billingSvc, quotaSvc, jobQueue, featureFlags, docgenConfig, uploadConfig, integrityPolicy, generationTimeouts, fileStorage, logger.For("services.research"))
+
+	if config.DocGenStartupProbe {
+		probeCtx, cancelProbe := context.WithTimeout(context.Background(), 10*time.Second)
+		err := researchSvc.PingDocGen(probeCtx)
+		cancelProbe()
+		if err != nil {
+			logger.Fatal("Document generation service check failed (check PYTHON_DOCGEN_URL and PYTHON_DOCGEN_SECRET, or set DOCGEN_STARTUP_PROBE=false):", err)
+		}
+		logger.Info("Document generation service reachable", "url", docgenConfig.URL)
+	}
+
+	// Document generation, reference embeddings and the hourly trash purge
+	researchSvc.RegisterJobs(config.TrashRetention)
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		jobQueue.Run(jobsCtx)
+		close(jobsDone)
+	}()
+
+	// Initialize rate limiter (memory or Redis backed)
+	rateLimiter, err := ratelimit.New(config)
+	if err != nil {
+		logger.Fatal("Cannot create rate limiter:", err)
+	}
+	tunables.OnChange(func(_, t util.Tunables) {
+		rateLimiter.SetLimits(t.RateLimitRPS, t.RateLimitBurst)
+	})
 
 	// Setup Gin router and server
-	server := api.NewServer(config, store, authSvc, researchSvc, aiSvc, tokenMaker, logger)
+	server := api.NewServer(config, store, authSvc, researchSvc, orgSvc, notificationSvc, billingSvc, quotaSvc, jobQueue, aiSvc, tokenMaker, rateLimiter, eventBus, featureFlags, logger.For("api"))
 
 	// Start server
 	srv := &http.Server{
@@ -74,6 +346,25 @@ func main() {
 		Handler: server.Router, // Assuming Router is a field in api.Server
 	}
 
+	// Internal gRPC server (optional)
+	var grpcServer *grpc.Server
+	if config.GRPCEnabled {
+		grpcServer, err = grpcapi.NewServer(config, researchSvc, aiSvc, logger.For("grpc"))
+		if err != nil {
+			logger.Fatal("Cannot create gRPC server:", err)
+		}
+		grpcListener, err := net.Listen("tcp", ":"+config.GRPCPort)
+		if err != nil {
+			logger.Fatal("Cannot listen on gRPC port:", err)
+		}
+		go func() {
+			logger.Info("gRPC server starting on port " + config.GRPCPort)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				logger.Fatal("Failed to start gRPC server:", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	go func() {
 		logger.Info("Server starting on port " + config.Port)
@@ -92,9 +383,37 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown:", err)
 	}
+	// Running jobs are cancelled; those that don't return in time are retried once their lease runs out
+	stopJobs()
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		logger.Warn("Background jobs did not stop in time")
+	}
 
 	logger.Info("Server exited")
 }
+
+// deliverWebhooks sends due webhook deliveries every interval, batch after batch
+func deliverWebhooks(webhookSvc *services.WebhookService, interval time.Duration, logger *applogger.AppLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for {
+			sent, err := webhookSvc.DeliverDue(context.Background())
+			if err != nil {
+				logger.Error("Failed to deliver webhooks", "error", err)
+				break
+			}
+			if sent == 0 {
+				break
+			}
+		}
+	}
+}